@@ -54,8 +54,10 @@ export interface ForgotPasswordRequest {
   email: string;
 }
 
+// Signed-in users change their password with ChangePasswordRequest instead
 export interface ResetPasswordRequest {
-  token: string;
+  email: string;
+  token: string; // the 6-digit code from the reset email
   new_password: string;
 }
 
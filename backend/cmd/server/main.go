@@ -2,8 +2,13 @@ package main
 
 import (
 	"agro_konnect/config"
+	auditmiddleware "agro_konnect/internal/audit/middleware"
+	orderservice "agro_konnect/internal/order/service"
+	"agro_konnect/internal/scheduler/jobs"
 	"agro_konnect/pkg/routes"
 	"log"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/gin-contrib/cors"
@@ -35,17 +40,48 @@ func main() {
 
 	router := gin.Default()
 
+	// Client IPs drive rate limits and sign-in lockouts, so X-Forwarded-For
+	// is only believed from the proxies in TRUSTED_PROXIES, a comma-separated
+	// list of IPs or CIDRs. By default no proxy is trusted.
+	var trustedProxies []string
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			trustedProxies = append(trustedProxies, proxy)
+		}
+	}
+	if err := router.SetTrustedProxies(trustedProxies); err != nil {
+		log.Fatalf("❌ Invalid TRUSTED_PROXIES: %v", err)
+	}
+
 	// CORS configuration
 	router.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"http://localhost:5173", "http://localhost:5174"},
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS", "HEAD"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Content-Length", "Accept-Encoding", "X-CSRF-Token", "Authorization", "Accept", "Cache-Control", "X-Requested-With"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Content-Length", "Accept-Encoding", "X-CSRF-Token", "Authorization", "Accept", "Cache-Control", "X-Requested-With", auditmiddleware.RequestIDHeader},
+		ExposeHeaders:    []string{"Content-Length", auditmiddleware.RequestIDHeader},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}))
 
-	routes.RegisterRoutes(router, db)
+	// Every request gets an ID that ties it to its audit log entries
+	router.Use(auditmiddleware.RequestID())
+
+	// What orders are placed and settled with is shared by the API and the
+	// background jobs
+	orderDeps := orderservice.NewDependencies(db)
+
+	// Background jobs run on every replica; advisory locks keep each run
+	// to a single server. Set SCHEDULER_ENABLED=false to only serve the API.
+	jobScheduler, err := jobs.NewScheduler(db, orderDeps)
+	if err != nil {
+		log.Fatalf("❌ Failed to set up job scheduler: %v", err)
+	}
+	if os.Getenv("SCHEDULER_ENABLED") != "false" {
+		jobScheduler.Start()
+		defer jobScheduler.Stop()
+	}
+
+	routes.RegisterRoutes(router, db, jobScheduler, orderDeps)
 
 	log.Println("🚀 Server running at http://localhost:8080")
 	log.Println("🌐 CORS enabled for localhost:5173 and localhost:5174")
@@ -1,11 +1,18 @@
 package config
 
 import (
+	auditModel "agro_konnect/internal/audit/model"
 	authModel "agro_konnect/internal/auth/model"
 	buyerModel "agro_konnect/internal/buyer/model"
+	"agro_konnect/internal/common"
+	emailModel "agro_konnect/internal/email/model"
 	farmerModel "agro_konnect/internal/farmer/model"
+	harvestModel "agro_konnect/internal/harvest/model"
+	mediaModel "agro_konnect/internal/media/model"
 	orderModel "agro_konnect/internal/order/model"
 	productModel "agro_konnect/internal/product/model"
+	rfqModel "agro_konnect/internal/rfq/model"
+	schedulerModel "agro_konnect/internal/scheduler/model"
 	transporterModel "agro_konnect/internal/transporter/model"
 	vendorModel "agro_konnect/internal/vendors/model"
 
@@ -59,14 +66,36 @@ func LoadConfig() *Config {
 func autoMigrate(db *gorm.DB) error {
 
 	db.Exec("CREATE EXTENSION IF NOT EXISTS \"uuid-ossp\"")
+
+	if err := migrateOrderSourceIndexes(db); err != nil {
+		return err
+	}
+
 	// Migrate all your models here
 	err := db.AutoMigrate(
 		&authModel.User{},
 		&authModel.VerificationCode{},
+		&authModel.Session{},
+		&authModel.Impersonation{},
+		&authModel.ImpersonationAction{},
+		&authModel.RecoveryCode{},
+		&authModel.TwoFactorPolicy{},
+		&authModel.AttemptCounter{},
+		&auditModel.Entry{},
+		&emailModel.OutboundEmail{},
 		&farmerModel.Farmer{},
 		&farmerModel.FarmerDocument{},
+		&farmerModel.LedgerTransaction{},
+		&farmerModel.LedgerEntry{},
+		&farmerModel.CommissionRule{},
+		&farmerModel.PayoutAccount{},
+		&farmerModel.PayoutBatch{},
+		&farmerModel.Payout{},
 		&productModel.Product{},
+		&productModel.ProductVariant{},
+		&productModel.PriceTier{},
 		&productModel.ProductReview{},
+		&productModel.ReviewHelpfulVote{},
 		&vendorModel.Vendor{},
 		&vendorModel.VendorProduct{},
 		&buyerModel.Buyer{},
@@ -74,10 +103,26 @@ func autoMigrate(db *gorm.DB) error {
 		&transporterModel.TransportCapacity{},
 		&transporterModel.Transporter{},
 		&transporterModel.Vehicle{},
+		&transporterModel.ServiceArea{},
+		&transporterModel.AvailabilitySlot{},
+		&transporterModel.SlotBooking{},
+		&transporterModel.Trip{},
+		&transporterModel.TripStop{},
+		&transporterModel.TransporterEarning{},
+		&transporterModel.SettlementStatement{},
 		&orderModel.Order{},
 		&orderModel.OrderTracking{},
 		&orderModel.OrderItem{},
 		&orderModel.OrderSummary{},
+		&rfqModel.RFQ{},
+		&rfqModel.RFQQuote{},
+		&rfqModel.QuoteOffer{},
+		&harvestModel.Harvest{},
+		&harvestModel.HarvestReservation{},
+		&common.Notification{},
+		&common.FileAttachment{},
+		&mediaModel.Rendition{},
+		&schedulerModel.JobRun{},
 	)
 
 	if err != nil {
@@ -86,6 +131,22 @@ func autoMigrate(db *gorm.DB) error {
 
 	log.Println("✅ Database tables migrated successfully")
 
+	if err := migrateProductSearch(db); err != nil {
+		return err
+	}
+
+	if err := migrateLegacyServiceAreas(db); err != nil {
+		return err
+	}
+
+	if err := migrateUserPhones(db); err != nil {
+		return err
+	}
+
+	if err := migrateTOTPSecrets(db); err != nil {
+		return err
+	}
+
 	// Log the tables that were created
 	var tables []string
 	db.Raw("SELECT table_name FROM information_schema.tables WHERE table_schema = 'public'").Pluck("table_name", &tables)
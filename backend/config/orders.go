@@ -0,0 +1,28 @@
+package config
+
+import (
+	"gorm.io/gorm"
+)
+
+// migrateOrderSourceIndexes drops the plain indexes orders.quote_id and
+// orders.reservation_id used to have, so that AutoMigrate recreates them as
+// unique ones under the same names. A quote or harvest reservation turns
+// into at most one order.
+func migrateOrderSourceIndexes(db *gorm.DB) error {
+	for _, name := range []string{"idx_orders_quote_id", "idx_orders_reservation_id"} {
+		if err := db.Exec(`
+			DO $$
+			BEGIN
+				IF EXISTS (
+					SELECT 1 FROM pg_index JOIN pg_class ON pg_class.oid = pg_index.indexrelid
+					WHERE pg_class.relname = '` + name + `' AND NOT pg_index.indisunique
+				) THEN
+					DROP INDEX ` + name + `;
+				END IF;
+			END
+			$$`).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
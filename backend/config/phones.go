@@ -0,0 +1,54 @@
+package config
+
+import (
+	"log"
+
+	"agro_konnect/internal/auth/utils"
+
+	"gorm.io/gorm"
+)
+
+// migrateUserPhones rewrites users.phone in the E.164 form numbers are now
+// stored and looked up in, so users who signed up before can still sign in
+// by phone. Numbers already in that form are skipped, so it does nothing
+// once every user is done. A number that cannot be read, or whose E.164
+// form another user already has, is left as it was and logged.
+func migrateUserPhones(db *gorm.DB) error {
+	var users []struct {
+		ID    string
+		Phone string
+	}
+	if err := db.Table("users").
+		Select("id, phone").
+		Where("phone <> '' AND phone !~ ?", `^\+[1-9][0-9]{7,14}$`).
+		Scan(&users).Error; err != nil {
+		return err
+	}
+
+	migrated := 0
+	for _, user := range users {
+		phone, err := utils.NormalizePhone(user.Phone)
+		if err != nil {
+			log.Printf("⚠️ User %s has a phone number that cannot be normalized: %v", user.ID, err)
+			continue
+		}
+
+		result := db.Exec(`
+			UPDATE users SET phone = ?
+			WHERE id = ? AND NOT EXISTS (SELECT 1 FROM users WHERE phone = ?)`,
+			phone, user.ID, phone)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			log.Printf("⚠️ User %s has the phone number of another user once normalized; left unchanged", user.ID)
+			continue
+		}
+		migrated++
+	}
+
+	if migrated > 0 {
+		log.Printf("✅ Normalized the phone numbers of %d users", migrated)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// productSearchMigrations keeps products.search_vector in step with the
+// columns it is built from. The farm name lives on farmers, so a rename there
+// touches the farmer's products to rebuild their vectors. Every statement is
+// idempotent and runs on each start-up after AutoMigrate.
+var productSearchMigrations = []string{
+	`CREATE EXTENSION IF NOT EXISTS pg_trgm`,
+
+	`ALTER TABLE products ADD COLUMN IF NOT EXISTS search_vector tsvector`,
+
+	`CREATE OR REPLACE FUNCTION products_search_vector_refresh() RETURNS trigger AS $$
+	BEGIN
+		NEW.search_vector :=
+			setweight(to_tsvector('english', coalesce(NEW.name, '')), 'A') ||
+			setweight(to_tsvector('english', coalesce(NEW.variety, '')), 'B') ||
+			setweight(to_tsvector('english', coalesce(NEW.subcategory, '')), 'B') ||
+			setweight(to_tsvector('english', coalesce((SELECT farm_name FROM farmers WHERE id = NEW.farmer_id), '')), 'C') ||
+			setweight(to_tsvector('english', coalesce(NEW.description, '')), 'D');
+		RETURN NEW;
+	END
+	$$ LANGUAGE plpgsql`,
+
+	`DROP TRIGGER IF EXISTS products_search_vector_trigger ON products`,
+
+	`CREATE TRIGGER products_search_vector_trigger
+		BEFORE INSERT OR UPDATE OF name, variety, subcategory, description, farmer_id ON products
+		FOR EACH ROW EXECUTE FUNCTION products_search_vector_refresh()`,
+
+	`CREATE OR REPLACE FUNCTION farmers_search_vector_refresh() RETURNS trigger AS $$
+	BEGIN
+		UPDATE products SET name = name WHERE farmer_id = NEW.id;
+		RETURN NULL;
+	END
+	$$ LANGUAGE plpgsql`,
+
+	`DROP TRIGGER IF EXISTS farmers_search_vector_trigger ON farmers`,
+
+	`CREATE TRIGGER farmers_search_vector_trigger
+		AFTER UPDATE OF farm_name ON farmers
+		FOR EACH ROW WHEN (OLD.farm_name IS DISTINCT FROM NEW.farm_name)
+		EXECUTE FUNCTION farmers_search_vector_refresh()`,
+
+	// Backfill rows created before the trigger existed
+	`UPDATE products SET name = name WHERE search_vector IS NULL`,
+
+	`CREATE INDEX IF NOT EXISTS idx_products_search_vector ON products USING GIN (search_vector)`,
+	`CREATE INDEX IF NOT EXISTS idx_products_name_trgm ON products USING GIN (name gin_trgm_ops)`,
+	`CREATE INDEX IF NOT EXISTS idx_products_variety_trgm ON products USING GIN (variety gin_trgm_ops)`,
+}
+
+func migrateProductSearch(db *gorm.DB) error {
+	for _, statement := range productSearchMigrations {
+		if err := db.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+
+	log.Println("✅ Product search index ready")
+	return nil
+}
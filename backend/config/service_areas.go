@@ -0,0 +1,45 @@
+package config
+
+import (
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// migrateLegacyServiceAreas moves the place names transporters kept in the
+// old transporters.service_areas jsonb column into service_areas as named
+// areas, then drops the column so it runs only once.
+func migrateLegacyServiceAreas(db *gorm.DB) error {
+	if !db.Migrator().HasColumn("transporters", "service_areas") {
+		return nil
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`
+			INSERT INTO service_areas (
+				id, transporter_id, name, type,
+				center_latitude, center_longitude, radius_km,
+				min_latitude, min_longitude, max_latitude, max_longitude,
+				created_at, updated_at
+			)
+			SELECT uuid_generate_v4(), t.id, TRIM(area.name), 'named',
+				0, 0, 0,
+				0, 0, 0, 0,
+				NOW(), NOW()
+			FROM transporters t
+			CROSS JOIN LATERAL jsonb_array_elements_text(
+				CASE WHEN jsonb_typeof(t.service_areas) = 'array' THEN t.service_areas ELSE '[]'::jsonb END
+			) AS area(name)
+			WHERE TRIM(area.name) <> ''`).Error; err != nil {
+			return err
+		}
+
+		return tx.Exec(`ALTER TABLE transporters DROP COLUMN service_areas`).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Println("✅ Legacy transporter service areas migrated")
+	return nil
+}
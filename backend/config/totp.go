@@ -0,0 +1,46 @@
+package config
+
+import (
+	"log"
+
+	"agro_konnect/internal/auth/utils"
+
+	"gorm.io/gorm"
+)
+
+// migrateTOTPSecrets seals two-factor secrets saved in the clear before
+// secrets were encrypted at rest. Sealed secrets are skipped, so it does
+// nothing once every secret is done. Each update only applies if the secret
+// is still the one read, so a user enrolling meanwhile keeps their new one.
+func migrateTOTPSecrets(db *gorm.DB) error {
+	var users []struct {
+		ID         string
+		TOTPSecret string `gorm:"column:totp_secret"`
+	}
+	if err := db.Table("users").
+		Select("id, totp_secret").
+		Where("totp_secret <> '' AND totp_secret NOT LIKE ?", utils.SealedTOTPPrefix+"%").
+		Scan(&users).Error; err != nil {
+		return err
+	}
+
+	sealed := 0
+	for _, user := range users {
+		secret, err := utils.SealTOTPSecret(user.TOTPSecret)
+		if err != nil {
+			return err
+		}
+		result := db.Exec("UPDATE users SET totp_secret = ? WHERE id = ? AND totp_secret = ?", secret, user.ID, user.TOTPSecret)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			sealed++
+		}
+	}
+
+	if sealed > 0 {
+		log.Printf("✅ Sealed the two-factor secrets of %d users", sealed)
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package dto
+
+import (
+	model "agro_konnect/internal/audit/model"
+	"time"
+)
+
+// Request DTOs
+type AuditFilterRequest struct {
+	ActorID    string     `form:"actor_id" validate:"omitempty,uuid"`
+	ActorRole  string     `form:"actor_role"`
+	Action     string     `form:"action"` // exact, or a prefix ending in "." such as "buyer."
+	EntityType string     `form:"entity_type"`
+	EntityID   string     `form:"entity_id"`
+	RequestID  string     `form:"request_id"`
+	From       *time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To         *time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Page       int        `form:"page" validate:"omitempty,min=1"`
+	PageSize   int        `form:"page_size" validate:"omitempty,min=1,max=100"`
+}
+
+// Response DTOs
+type AuditEntryListResponse struct {
+	Entries []*model.Entry `json:"entries"`
+	Total   int64          `json:"total"`
+	Page    int            `json:"page"`
+	Pages   int            `json:"pages"`
+	HasMore bool           `json:"has_more"`
+}
+
+// ChainVerificationResponse reports whether the audit log is intact. When
+// it is not, FirstInvalidSequence is the first entry that fails to match.
+type ChainVerificationResponse struct {
+	Valid                bool   `json:"valid"`
+	EntriesChecked       int64  `json:"entries_checked"`
+	FirstInvalidSequence *int64 `json:"first_invalid_sequence,omitempty"`
+	Reason               string `json:"reason,omitempty"`
+	LastHash             string `json:"last_hash"`
+}
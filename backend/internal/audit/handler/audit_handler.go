@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	dto "agro_konnect/internal/audit/dto"
+	"agro_konnect/internal/audit/service"
+	"agro_konnect/internal/audit/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+var exportContentTypes = map[string]string{
+	service.FormatCSV:  "text/csv",
+	service.FormatXLSX: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+type AuditHandler struct {
+	auditService service.AuditService
+}
+
+func NewAuditHandler(auditService service.AuditService) *AuditHandler {
+	return &AuditHandler{auditService: auditService}
+}
+
+// GetEntries searches the audit log (admin only)
+// @Summary Search audit log
+// @Description List audit log entries, newest first, filtered by actor, action, entity, request or time (admin only)
+// @Tags audit
+// @Produce json
+// @Security BearerAuth
+// @Param actor_id query string false "User who made the change"
+// @Param actor_role query string false "Role of the user, or system for background jobs"
+// @Param action query string false "Action, or a prefix ending in a dot such as buyer."
+// @Param entity_type query string false "Entity type"
+// @Param entity_id query string false "Entity ID"
+// @Param request_id query string false "Request ID"
+// @Param from query string false "Earliest time (RFC 3339)"
+// @Param to query string false "Latest time, exclusive (RFC 3339)"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(50)
+// @Success 200 {object} utils.SuccessResponse{data=dto.AuditEntryListResponse} "Audit entries retrieved successfully"
+// @Failure 400 {object} utils.ErrorResponse "Invalid filters"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 403 {object} utils.ErrorResponse "Forbidden"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /admin/audit [get]
+func (h *AuditHandler) GetEntries(c *gin.Context) {
+	filters, ok := bindFilters(c)
+	if !ok {
+		return
+	}
+
+	entries, err := h.auditService.GetEntries(c.Request.Context(), filters)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to get audit entries")
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, "Audit entries retrieved successfully", entries)
+}
+
+// ExportEntries downloads the audit log (admin only)
+// @Summary Export audit log
+// @Description Download matching audit log entries, oldest first and with their hashes, as CSV or XLSX (admin only)
+// @Tags audit
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Security BearerAuth
+// @Param format query string false "File format" Enums(csv, xlsx) default(csv)
+// @Param actor_id query string false "User who made the change"
+// @Param actor_role query string false "Role of the user, or system for background jobs"
+// @Param action query string false "Action, or a prefix ending in a dot such as buyer."
+// @Param entity_type query string false "Entity type"
+// @Param entity_id query string false "Entity ID"
+// @Param request_id query string false "Request ID"
+// @Param from query string false "Earliest time (RFC 3339)"
+// @Param to query string false "Latest time, exclusive (RFC 3339)"
+// @Success 200 {file} file "Audit log export"
+// @Failure 400 {object} utils.ErrorResponse "Invalid filters, unsupported format or too many entries"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 403 {object} utils.ErrorResponse "Forbidden"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /admin/audit/export [get]
+func (h *AuditHandler) ExportEntries(c *gin.Context) {
+	filters, ok := bindFilters(c)
+	if !ok {
+		return
+	}
+
+	format := strings.ToLower(c.DefaultQuery("format", service.FormatCSV))
+	data, err := h.auditService.Export(c.Request.Context(), filters, format)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrUnsupportedFormat), errors.Is(err, service.ErrExportTooLarge):
+			utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		default:
+			utils.RespondWithError(c, http.StatusInternalServerError, "Failed to export audit log")
+		}
+		return
+	}
+
+	filename := fmt.Sprintf("audit-log-%s.%s", time.Now().Format("20060102"), format)
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+	c.Data(http.StatusOK, exportContentTypes[format], data)
+}
+
+// VerifyChain checks the audit log for tampering (admin only)
+// @Summary Verify audit log
+// @Description Recompute the hash chain of the whole audit log and report the first entry that was changed, removed or reordered (admin only)
+// @Tags audit
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.SuccessResponse{data=dto.ChainVerificationResponse} "Audit log verified"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 403 {object} utils.ErrorResponse "Forbidden"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /admin/audit/verify [get]
+func (h *AuditHandler) VerifyChain(c *gin.Context) {
+	result, err := h.auditService.VerifyChain(c.Request.Context())
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to verify audit log")
+		return
+	}
+
+	message := "Audit log is intact"
+	if !result.Valid {
+		message = "Audit log has been tampered with"
+	}
+	utils.RespondWithSuccess(c, http.StatusOK, message, result)
+}
+
+func bindFilters(c *gin.Context) (dto.AuditFilterRequest, bool) {
+	var filters dto.AuditFilterRequest
+	if err := c.ShouldBindQuery(&filters); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid query parameters: "+err.Error())
+		return filters, false
+	}
+	if err := utils.ValidateStruct(filters); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		return filters, false
+	}
+	return filters, true
+}
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"agro_konnect/internal/audit/service"
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+// IDs passed in by a proxy are kept only if they look like IDs.
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// RequestID tags every request with an ID, taken from the X-Request-ID
+// header when a proxy has set one. It is echoed in the response and
+// written to the audit log with any change the request makes.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validRequestID.MatchString(requestID) {
+			requestID = uuid.NewString()
+		}
+
+		c.Header(RequestIDHeader, requestID)
+		c.Request = c.Request.WithContext(service.WithRequest(c.Request.Context(), requestID, c.ClientIP()))
+		c.Next()
+	}
+}
@@ -0,0 +1,100 @@
+package model
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// GenesisHash is the previous hash of the first entry in the log.
+const GenesisHash = "0000000000000000000000000000000000000000000000000000000000000000"
+
+// SystemRole is recorded for changes made by background jobs.
+const SystemRole = "system"
+
+// Entry is one change in the audit log. Entries are only ever appended;
+// each one hashes the previous entry's hash together with its own fields,
+// so editing, removing or reordering any entry breaks the chain from that
+// point on.
+type Entry struct {
+	ID             uuid.UUID      `gorm:"type:uuid;primary_key" json:"id"`
+	Sequence       int64          `gorm:"uniqueIndex;not null" json:"sequence"`
+	ActorID        *uuid.UUID     `gorm:"type:uuid;index" json:"actor_id,omitempty"` // nil for background jobs
+	ActorRole      string         `gorm:"type:varchar(20);not null" json:"actor_role"`
+	ImpersonatorID *uuid.UUID     `gorm:"type:uuid" json:"impersonator_id,omitempty"` // admin acting as the actor
+	Action         string         `gorm:"type:varchar(100);not null;index" json:"action"`
+	EntityType     string         `gorm:"type:varchar(50);not null;index:idx_audit_entity" json:"entity_type"`
+	EntityID       string         `gorm:"index:idx_audit_entity" json:"entity_id"`
+	Changes        datatypes.JSON `gorm:"type:jsonb" json:"changes"` // field -> {before, after}
+	RequestID      string         `gorm:"index" json:"request_id,omitempty"`
+	IPAddress      string         `json:"ip_address,omitempty"`
+	CreatedAt      time.Time      `gorm:"index" json:"created_at"`
+	PrevHash       string         `gorm:"type:char(64);not null" json:"prev_hash"`
+	Hash           string         `gorm:"type:char(64);not null" json:"hash"`
+}
+
+func (Entry) TableName() string {
+	return "audit_log"
+}
+
+// Change is the value of one field before and after an action. Either side
+// is null when the entity was created or deleted.
+type Change struct {
+	Before json.RawMessage `json:"before"`
+	After  json.RawMessage `json:"after"`
+}
+
+// ComputeHash returns the hash the entry should have, given its fields and
+// PrevHash. Changes are hashed in a canonical form because the database
+// reformats JSON it stores.
+func (e *Entry) ComputeHash() string {
+	h := sha256.New()
+	field := func(s string) {
+		h.Write([]byte(strconv.Itoa(len(s))))
+		h.Write([]byte{':'})
+		h.Write([]byte(s))
+	}
+	optionalID := func(id *uuid.UUID) string {
+		if id == nil {
+			return ""
+		}
+		return id.String()
+	}
+
+	field(e.PrevHash)
+	field(strconv.FormatInt(e.Sequence, 10))
+	field(e.ID.String())
+	field(optionalID(e.ActorID))
+	field(e.ActorRole)
+	field(optionalID(e.ImpersonatorID))
+	field(e.Action)
+	field(e.EntityType)
+	field(e.EntityID)
+	field(canonicalJSON(e.Changes))
+	field(e.RequestID)
+	field(e.IPAddress)
+	field(e.CreatedAt.UTC().Format(time.RFC3339Nano))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// canonicalJSON re-encodes JSON with sorted keys and no extra whitespace.
+func canonicalJSON(data []byte) string {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return ""
+	}
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return string(data)
+	}
+	out, err := json.Marshal(v)
+	if err != nil {
+		return string(data)
+	}
+	return string(out)
+}
@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	dto "agro_konnect/internal/audit/dto"
+	model "agro_konnect/internal/audit/model"
+
+	"gorm.io/gorm"
+)
+
+// appendLockKey serialises appends so each entry links to the one before.
+const appendLockKey = "audit_log_append"
+
+type AuditRepository interface {
+	Append(ctx context.Context, entry *model.Entry) error
+	FindAll(ctx context.Context, filters dto.AuditFilterRequest) ([]*model.Entry, int64, error)
+	FindEach(ctx context.Context, filters dto.AuditFilterRequest, batchSize int, fn func([]*model.Entry) error) error
+	FindAfterSequence(ctx context.Context, sequence int64, limit int) ([]*model.Entry, error)
+}
+
+type auditRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditRepository(db *gorm.DB) AuditRepository {
+	return &auditRepository{db: db}
+}
+
+// Append links the entry to the end of the chain and stores it. The lock is
+// held until the transaction ends, so concurrent appends queue up instead of
+// forking the chain.
+func (r *auditRepository) Append(ctx context.Context, entry *model.Entry) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", appendLockKey).Error; err != nil {
+			return err
+		}
+
+		var last model.Entry
+		err := tx.Select("sequence", "hash").Order("sequence DESC").Take(&last).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			entry.Sequence = 1
+			entry.PrevHash = model.GenesisHash
+		case err != nil:
+			return err
+		default:
+			entry.Sequence = last.Sequence + 1
+			entry.PrevHash = last.Hash
+		}
+
+		entry.Hash = entry.ComputeHash()
+		return tx.Create(entry).Error
+	})
+}
+
+func (r *auditRepository) filtered(ctx context.Context, filters dto.AuditFilterRequest) *gorm.DB {
+	query := r.db.WithContext(ctx).Model(&model.Entry{})
+	if filters.ActorID != "" {
+		query = query.Where("actor_id = ?", filters.ActorID)
+	}
+	if filters.ActorRole != "" {
+		query = query.Where("actor_role = ?", filters.ActorRole)
+	}
+	if filters.Action != "" {
+		if strings.HasSuffix(filters.Action, ".") {
+			query = query.Where("action LIKE ?", filters.Action+"%")
+		} else {
+			query = query.Where("action = ?", filters.Action)
+		}
+	}
+	if filters.EntityType != "" {
+		query = query.Where("entity_type = ?", filters.EntityType)
+	}
+	if filters.EntityID != "" {
+		query = query.Where("entity_id = ?", filters.EntityID)
+	}
+	if filters.RequestID != "" {
+		query = query.Where("request_id = ?", filters.RequestID)
+	}
+	if filters.From != nil {
+		query = query.Where("created_at >= ?", *filters.From)
+	}
+	if filters.To != nil {
+		query = query.Where("created_at < ?", *filters.To)
+	}
+	return query
+}
+
+// FindAll lists matching entries, newest first.
+func (r *auditRepository) FindAll(ctx context.Context, filters dto.AuditFilterRequest) ([]*model.Entry, int64, error) {
+	query := r.filtered(ctx, filters)
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var entries []*model.Entry
+	err := query.Order("sequence DESC").
+		Offset((filters.Page - 1) * filters.PageSize).
+		Limit(filters.PageSize).
+		Find(&entries).Error
+	return entries, total, err
+}
+
+// FindEach hands every matching entry to fn in batches, oldest first, for
+// exports too large to load at once.
+func (r *auditRepository) FindEach(ctx context.Context, filters dto.AuditFilterRequest, batchSize int, fn func([]*model.Entry) error) error {
+	var after int64
+	for {
+		var entries []*model.Entry
+		err := r.filtered(ctx, filters).
+			Where("sequence > ?", after).
+			Order("sequence ASC").
+			Limit(batchSize).
+			Find(&entries).Error
+		if err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			return nil
+		}
+		if err := fn(entries); err != nil {
+			return err
+		}
+		if len(entries) < batchSize {
+			return nil
+		}
+		after = entries[len(entries)-1].Sequence
+	}
+}
+
+// FindAfterSequence returns the entries that follow a position in the chain.
+func (r *auditRepository) FindAfterSequence(ctx context.Context, sequence int64, limit int) ([]*model.Entry, error) {
+	var entries []*model.Entry
+	err := r.db.WithContext(ctx).
+		Where("sequence > ?", sequence).
+		Order("sequence ASC").
+		Limit(limit).
+		Find(&entries).Error
+	return entries, err
+}
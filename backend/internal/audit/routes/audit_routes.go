@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"agro_konnect/internal/audit/handler"
+	"agro_konnect/internal/audit/service"
+	"agro_konnect/internal/auth/middleware"
+	"agro_konnect/internal/auth/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SetupAuditRoutes(router *gin.RouterGroup, auditService service.AuditService, authMiddleware *middleware.AuthMiddleware) {
+	auditHandler := handler.NewAuditHandler(auditService)
+
+	// Admin routes
+	auditRoutes := router.Group("/admin/audit")
+	auditRoutes.Use(authMiddleware.Authenticate(), authMiddleware.RequireRole(model.RoleAdmin))
+	{
+		auditRoutes.GET("", auditHandler.GetEntries)
+		auditRoutes.GET("/export", auditHandler.ExportEntries)
+		auditRoutes.GET("/verify", auditHandler.VerifyChain)
+	}
+}
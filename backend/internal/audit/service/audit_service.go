@@ -0,0 +1,297 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"time"
+
+	dto "agro_konnect/internal/audit/dto"
+	model "agro_konnect/internal/audit/model"
+	"agro_konnect/internal/audit/repository"
+	"agro_konnect/pkg/xlsx"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+
+	// MaxExportEntries keeps an export to a size a spreadsheet can open
+	MaxExportEntries = 100_000
+
+	verifyBatchSize = 1000
+)
+
+var (
+	ErrUnsupportedFormat = errors.New("export format must be csv or xlsx")
+	ErrExportTooLarge    = fmt.Errorf("too many entries to export; narrow the filters to at most %d", MaxExportEntries)
+)
+
+// Fields left out of diffs; they change on every write.
+var ignoredFields = map[string]bool{
+	"updated_at": true,
+}
+
+// Event describes a change for the audit log. Before and After are the
+// state of the entity, or of just the fields that matter, on either side of
+// the action; only fields that differ are kept. Leave Before nil for
+// creations and After nil for deletions.
+type Event struct {
+	Action     string // <entity>.<verb>, e.g. "buyer.verify"
+	EntityType string
+	EntityID   string
+	Before     interface{}
+	After      interface{}
+}
+
+// Recorder writes to the audit log. Services call it once a change has been
+// made; who made it and in which request are taken from ctx.
+type Recorder interface {
+	Record(ctx context.Context, event Event)
+}
+
+type AuditService interface {
+	Recorder
+	GetEntries(ctx context.Context, filters dto.AuditFilterRequest) (*dto.AuditEntryListResponse, error)
+	Export(ctx context.Context, filters dto.AuditFilterRequest, format string) ([]byte, error)
+	VerifyChain(ctx context.Context) (*dto.ChainVerificationResponse, error)
+}
+
+type auditService struct {
+	auditRepo repository.AuditRepository
+}
+
+func NewAuditService(auditRepo repository.AuditRepository) AuditService {
+	return &auditService{auditRepo: auditRepo}
+}
+
+// Record never fails the action being audited, which has already happened
+// by the time it is called; a write that fails is logged instead.
+func (s *auditService) Record(ctx context.Context, event Event) {
+	changes, err := diff(event.Before, event.After)
+	if err != nil {
+		log.Printf("failed to diff audit event %s on %s %s: %v", event.Action, event.EntityType, event.EntityID, err)
+		return
+	}
+
+	entry := &model.Entry{
+		ID:         uuid.New(),
+		ActorRole:  model.SystemRole,
+		Action:     event.Action,
+		EntityType: event.EntityType,
+		EntityID:   event.EntityID,
+		Changes:    changes,
+		RequestID:  RequestIDFromContext(ctx),
+		IPAddress:  ipAddressFromContext(ctx),
+		CreatedAt:  time.Now().UTC().Truncate(time.Microsecond), // as stored by Postgres
+	}
+	if actor, ok := ActorFromContext(ctx); ok {
+		entry.ActorID = &actor.UserID
+		entry.ActorRole = actor.Role
+		entry.ImpersonatorID = actor.ImpersonatorID
+	}
+
+	// Written even if the client has gone away in the meantime
+	if err := s.auditRepo.Append(context.WithoutCancel(ctx), entry); err != nil {
+		log.Printf("failed to write audit entry %s on %s %s: %v", event.Action, event.EntityType, event.EntityID, err)
+	}
+}
+
+func (s *auditService) GetEntries(ctx context.Context, filters dto.AuditFilterRequest) (*dto.AuditEntryListResponse, error) {
+	if filters.Page < 1 {
+		filters.Page = 1
+	}
+	if filters.PageSize < 1 || filters.PageSize > 100 {
+		filters.PageSize = 50
+	}
+
+	entries, total, err := s.auditRepo.FindAll(ctx, filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audit entries: %w", err)
+	}
+
+	pages := int(math.Ceil(float64(total) / float64(filters.PageSize)))
+	return &dto.AuditEntryListResponse{
+		Entries: entries,
+		Total:   total,
+		Page:    filters.Page,
+		Pages:   pages,
+		HasMore: filters.Page < pages,
+	}, nil
+}
+
+// Export writes the matching entries oldest first, hashes included, so an
+// exported range can be checked against the chain later.
+func (s *auditService) Export(ctx context.Context, filters dto.AuditFilterRequest, format string) ([]byte, error) {
+	if format != FormatCSV && format != FormatXLSX {
+		return nil, ErrUnsupportedFormat
+	}
+
+	filters.Page, filters.PageSize = 1, 1
+	_, total, err := s.auditRepo.FindAll(ctx, filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count audit entries: %w", err)
+	}
+	if total > MaxExportEntries {
+		return nil, ErrExportTooLarge
+	}
+
+	rows := make([][]interface{}, 0, total+1)
+	rows = append(rows, []interface{}{
+		"sequence", "created_at", "actor_id", "actor_role", "impersonator_id", "action",
+		"entity_type", "entity_id", "changes", "request_id", "ip_address", "prev_hash", "hash",
+	})
+	err = s.auditRepo.FindEach(ctx, filters, 1000, func(entries []*model.Entry) error {
+		for _, entry := range entries {
+			rows = append(rows, []interface{}{
+				entry.Sequence,
+				entry.CreatedAt.UTC().Format(time.RFC3339Nano),
+				optionalID(entry.ActorID),
+				entry.ActorRole,
+				optionalID(entry.ImpersonatorID),
+				entry.Action,
+				entry.EntityType,
+				entry.EntityID,
+				string(entry.Changes),
+				entry.RequestID,
+				entry.IPAddress,
+				entry.PrevHash,
+				entry.Hash,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load audit entries: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if format == FormatXLSX {
+		if err := xlsx.Write(&buf, "Audit log", rows); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	w := csv.NewWriter(&buf)
+	for _, row := range rows {
+		record := make([]string, len(row))
+		for i, value := range row {
+			switch v := value.(type) {
+			case int64:
+				record[i] = strconv.FormatInt(v, 10)
+			default:
+				record[i] = fmt.Sprint(v)
+			}
+		}
+		w.Write(record)
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// VerifyChain walks the whole log and recomputes every hash. Removing
+// entries from the end can't be detected from the log alone, so keep the
+// returned LastHash somewhere else to compare against later.
+func (s *auditService) VerifyChain(ctx context.Context) (*dto.ChainVerificationResponse, error) {
+	result := &dto.ChainVerificationResponse{Valid: true, LastHash: model.GenesisHash}
+	fail := func(sequence int64, reason string) (*dto.ChainVerificationResponse, error) {
+		result.Valid = false
+		result.FirstInvalidSequence = &sequence
+		result.Reason = reason
+		return result, nil
+	}
+
+	var position int64
+	for {
+		entries, err := s.auditRepo.FindAfterSequence(ctx, position, verifyBatchSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read audit log: %w", err)
+		}
+
+		for _, entry := range entries {
+			switch {
+			case entry.Sequence != position+1:
+				return fail(position+1, "entry is missing")
+			case entry.PrevHash != result.LastHash:
+				return fail(entry.Sequence, "entry does not link to the one before it")
+			case entry.ComputeHash() != entry.Hash:
+				return fail(entry.Sequence, "entry was changed after it was written")
+			}
+			position = entry.Sequence
+			result.LastHash = entry.Hash
+			result.EntriesChecked++
+		}
+
+		if len(entries) < verifyBatchSize {
+			return result, nil
+		}
+	}
+}
+
+// diff lists the fields that differ between two states, as JSON.
+func diff(before, after interface{}) (datatypes.JSON, error) {
+	beforeFields, err := fields(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := fields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]model.Change)
+	for name, value := range afterFields {
+		if old, ok := beforeFields[name]; !ok || !bytes.Equal(old, value) {
+			changes[name] = model.Change{Before: old, After: value}
+		}
+	}
+	for name, value := range beforeFields {
+		if _, ok := afterFields[name]; !ok {
+			changes[name] = model.Change{Before: value}
+		}
+	}
+	for name := range ignoredFields {
+		delete(changes, name)
+	}
+
+	data, err := json.Marshal(changes)
+	return datatypes.JSON(data), err
+}
+
+// fields splits a value into its JSON fields. Values that aren't objects are
+// kept under "value".
+func fields(v interface{}) (map[string]json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if string(data) == "null" {
+		return nil, nil
+	}
+
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(data, &object); err != nil || object == nil {
+		return map[string]json.RawMessage{"value": data}, nil
+	}
+	return object, nil
+}
+
+func optionalID(id *uuid.UUID) string {
+	if id == nil {
+		return ""
+	}
+	return id.String()
+}
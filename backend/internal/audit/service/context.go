@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Actor is the signed-in user a change is attributed to.
+type Actor struct {
+	UserID         uuid.UUID
+	Role           string
+	ImpersonatorID *uuid.UUID // set when an admin is acting as the user
+}
+
+type requestInfo struct {
+	id        string
+	ipAddress string
+}
+
+type contextKey int
+
+const (
+	actorKey contextKey = iota
+	requestKey
+)
+
+// WithActor attaches the signed-in user to a request context so that
+// services deep in the call chain can attribute their changes.
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey, actor)
+}
+
+// WithRequest attaches the request ID and client address to a context.
+func WithRequest(ctx context.Context, requestID, ipAddress string) context.Context {
+	return context.WithValue(ctx, requestKey, requestInfo{id: requestID, ipAddress: ipAddress})
+}
+
+// ActorFromContext returns the signed-in user, if there is one.
+func ActorFromContext(ctx context.Context) (Actor, bool) {
+	actor, ok := ctx.Value(actorKey).(Actor)
+	return actor, ok
+}
+
+// RequestIDFromContext returns the ID of the request being served, or "".
+func RequestIDFromContext(ctx context.Context) string {
+	info, _ := ctx.Value(requestKey).(requestInfo)
+	return info.id
+}
+
+func ipAddressFromContext(ctx context.Context) string {
+	info, _ := ctx.Value(requestKey).(requestInfo)
+	return info.ipAddress
+}
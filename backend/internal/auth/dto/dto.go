@@ -18,6 +18,7 @@ type RegisterRequest struct {
 	Phone    string         `json:"phone" validate:"required"`
 	Password string         `json:"password" validate:"required,min=6"`
 	Role     model.UserRole `json:"role" validate:"required,oneof=farmer vendor transporter buyer"`
+	Locale   string         `json:"locale,omitempty" binding:"omitempty,oneof=en sw"` // defaults to en
 }
 
 type VerifyEmailRequest struct {
@@ -30,29 +31,191 @@ type ForgotPasswordRequest struct {
 }
 
 type ResetPasswordRequest struct {
-	Token       string `json:"token" validate:"required"`
+	Email       string `json:"email" validate:"required,email"`
+	Token       string `json:"token" validate:"required"` // the 6-digit code from the reset email
 	NewPassword string `json:"new_password" validate:"required,min=6"`
 }
 
+// PhoneRequest asks for a code to be texted to a phone number.
+type PhoneRequest struct {
+	Phone string `json:"phone" binding:"required"`
+}
+
+// PhoneCodeRequest verifies a phone number or signs in with a texted code.
+type PhoneCodeRequest struct {
+	Phone string `json:"phone" binding:"required"`
+	Code  string `json:"code" binding:"required,len=6,numeric"`
+}
+
+type PhoneResetPasswordRequest struct {
+	Phone       string `json:"phone" binding:"required"`
+	Code        string `json:"code" binding:"required,len=6,numeric"`
+	NewPassword string `json:"new_password" binding:"required,min=6"`
+}
+
 type ChangePasswordRequest struct {
 	CurrentPassword string `json:"current_password" validate:"required"`
 	NewPassword     string `json:"new_password" validate:"required,min=6"`
 }
 
+// AdminUpdateUserRequest changes a user's account; fields left out stay
+// as they are.
+type AdminUpdateUserRequest struct {
+	Email    *string         `json:"email,omitempty"`
+	Phone    *string         `json:"phone,omitempty"`
+	Role     *model.UserRole `json:"role,omitempty"`
+	IsActive *bool           `json:"is_active,omitempty"`
+	Locale   *string         `json:"locale,omitempty" binding:"omitempty,oneof=en sw"`
+}
+
+// ImpersonateRequest starts an impersonation. Read-only impersonations
+// can look but not change anything.
+type ImpersonateRequest struct {
+	Reason          string `json:"reason" validate:"required,min=5,max=500"`
+	ReadOnly        bool   `json:"read_only"`
+	DurationMinutes int    `json:"duration_minutes" validate:"omitempty,min=1,max=120"`
+}
+
+// ClientInfo describes the device a session is started from.
+type ClientInfo struct {
+	UserAgent string
+	IPAddress string
+}
+
 // Response DTOs
 type AuthResponse struct {
 	User         model.User `json:"user"`
 	AccessToken  string     `json:"access_token"`
 	RefreshToken string     `json:"refresh_token,omitempty"`
 	ExpiresAt    time.Time  `json:"expires_at"`
+	SessionID    uuid.UUID  `json:"session_id"`
+}
+
+// LoginResponse is either a session or, when the account uses two-factor
+// authentication, the challenge to answer for one.
+type LoginResponse struct {
+	*AuthResponse
+	TwoFactor *TwoFactorChallenge `json:"two_factor,omitempty"`
+}
+
+// TwoFactorChallenge is sent after a correct password. With SetupRequired
+// the user must enrol before they can sign in.
+type TwoFactorChallenge struct {
+	ChallengeToken string    `json:"challenge_token"`
+	ExpiresAt      time.Time `json:"expires_at"`
+	SetupRequired  bool      `json:"setup_required"`
+}
+
+type TwoFactorChallengeRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+}
+
+// TwoFactorLoginRequest answers a challenge with a code from the
+// authenticator app or a recovery code.
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required,min=6,max=20"`
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required,min=6,max=20"`
+}
+
+type DisableTwoFactorRequest struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required,min=6,max=20"`
+}
+
+// TwoFactorSetupResponse is shown once while enrolling; the URI is meant
+// to be rendered as a QR code.
+type TwoFactorSetupResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// TwoFactorEnrolledResponse is the session started by enrolling during
+// sign-in, with the recovery codes to write down.
+type TwoFactorEnrolledResponse struct {
+	*AuthResponse
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type TwoFactorStatusResponse struct {
+	Enabled                bool       `json:"enabled"`
+	EnabledAt              *time.Time `json:"enabled_at,omitempty"`
+	Required               bool       `json:"required"`
+	RecoveryCodesRemaining int64      `json:"recovery_codes_remaining"`
+}
+
+type TwoFactorPolicyRequest struct {
+	RequireForAdmins     bool     `json:"require_for_admins"`
+	BuyerCreditThreshold *float64 `json:"buyer_credit_threshold" validate:"omitempty,gte=0"`
+}
+
+// LockoutResponse is an account or IP address with recent failed sign-in
+// attempts. RetryAt is set while it has to wait, and Locked while that wait
+// is a lockout.
+type LockoutResponse struct {
+	ID            uuid.UUID  `json:"id"`
+	Scope         string     `json:"scope"`
+	Subject       string     `json:"subject"`
+	UserID        *uuid.UUID `json:"user_id,omitempty"`
+	UserEmail     string     `json:"user_email,omitempty"`
+	Failures      int        `json:"failures"`
+	FirstFailedAt time.Time  `json:"first_failed_at"`
+	LastFailedAt  time.Time  `json:"last_failed_at"`
+	Locked        bool       `json:"locked"`
+	RetryAt       *time.Time `json:"retry_at,omitempty"`
+}
+
+type SessionResponse struct {
+	ID         uuid.UUID `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"` // the session making the request
 }
 
 type UserResponse struct {
-	ID         uuid.UUID      `json:"id"`
-	Email      string         `json:"email"`
-	Phone      string         `json:"phone"`
-	Role       model.UserRole `json:"role"`
-	IsVerified bool           `json:"is_verified"`
-	IsActive   bool           `json:"is_active"`
-	CreatedAt  time.Time      `json:"created_at"`
+	ID               uuid.UUID      `json:"id"`
+	Email            string         `json:"email"`
+	Phone            string         `json:"phone"`
+	Role             model.UserRole `json:"role"`
+	IsVerified       bool           `json:"is_verified"`
+	PhoneVerified    bool           `json:"phone_verified"`
+	IsActive         bool           `json:"is_active"`
+	Locale           string         `json:"locale"`
+	TwoFactorEnabled bool           `json:"two_factor_enabled"`
+	CreatedAt        time.Time      `json:"created_at"`
+}
+
+type ImpersonationTokenResponse struct {
+	ImpersonationID uuid.UUID    `json:"impersonation_id"`
+	AccessToken     string       `json:"access_token"`
+	ExpiresAt       time.Time    `json:"expires_at"`
+	ReadOnly        bool         `json:"read_only"`
+	User            UserResponse `json:"user"`
+}
+
+// ImpersonationResponse is one time an admin acted as a user. Users see
+// these for their own account.
+type ImpersonationResponse struct {
+	ID                uuid.UUID  `json:"id"`
+	ImpersonatorID    uuid.UUID  `json:"impersonator_id"`
+	ImpersonatorEmail string     `json:"impersonator_email"`
+	UserID            uuid.UUID  `json:"user_id"`
+	UserEmail         string     `json:"user_email"`
+	Reason            string     `json:"reason"`
+	ReadOnly          bool       `json:"read_only"`
+	StartedAt         time.Time  `json:"started_at"`
+	ExpiresAt         time.Time  `json:"expires_at"`
+	EndedAt           *time.Time `json:"ended_at,omitempty"`
+	Active            bool       `json:"active"`
+	ActionCount       int64      `json:"action_count"` // requests that changed something
 }
@@ -2,29 +2,31 @@ package handler
 
 import (
 	"agro_konnect/internal/auth/dto"
-	"agro_konnect/internal/auth/model"
+	"agro_konnect/internal/auth/middleware"
 	"agro_konnect/internal/auth/repository"
 	"agro_konnect/internal/auth/service"
-	"agro_konnect/internal/auth/utils"
+	"errors"
 	"net/http"
 	"strconv"
-	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
 )
 
+var validate = validator.New()
+
 type AdminHandler struct {
 	authService service.AuthService
 	userRepo    repository.UserRepository
-	jwtManager  *utils.JWTManager
+	throttle    service.Throttle
 }
 
-func NewAdminHandler(authService service.AuthService, userRepo repository.UserRepository, jwtManager *utils.JWTManager) *AdminHandler {
+func NewAdminHandler(authService service.AuthService, userRepo repository.UserRepository, throttle service.Throttle) *AdminHandler {
 	return &AdminHandler{
 		authService: authService,
 		userRepo:    userRepo,
-		jwtManager:  jwtManager,
+		throttle:    throttle,
 	}
 }
 
@@ -53,13 +55,14 @@ func (h *AdminHandler) GetUsers(c *gin.Context) {
 	userResponses := make([]*dto.UserResponse, len(users))
 	for i, user := range users {
 		userResponses[i] = &dto.UserResponse{
-			ID:         user.ID,
-			Email:      user.Email,
-			Phone:      user.Phone,
-			Role:       user.Role,
-			IsVerified: user.IsVerified,
-			IsActive:   user.IsActive,
-			CreatedAt:  user.CreatedAt,
+			ID:               user.ID,
+			Email:            user.Email,
+			Phone:            user.Phone,
+			Role:             user.Role,
+			IsVerified:       user.IsVerified,
+			IsActive:         user.IsActive,
+			TwoFactorEnabled: user.TOTPEnabled,
+			CreatedAt:        user.CreatedAt,
 		}
 	}
 
@@ -89,13 +92,14 @@ func (h *AdminHandler) GetUserByID(c *gin.Context) {
 	}
 
 	userResponse := &dto.UserResponse{
-		ID:         user.ID,
-		Email:      user.Email,
-		Phone:      user.Phone,
-		Role:       user.Role,
-		IsVerified: user.IsVerified,
-		IsActive:   user.IsActive,
-		CreatedAt:  user.CreatedAt,
+		ID:               user.ID,
+		Email:            user.Email,
+		Phone:            user.Phone,
+		Role:             user.Role,
+		IsVerified:       user.IsVerified,
+		IsActive:         user.IsActive,
+		TwoFactorEnabled: user.TOTPEnabled,
+		CreatedAt:        user.CreatedAt,
 	}
 
 	c.JSON(http.StatusOK, userResponse)
@@ -109,57 +113,23 @@ func (h *AdminHandler) UpdateUser(c *gin.Context) {
 		return
 	}
 
-	var req struct {
-		Email    *string         `json:"email,omitempty"`
-		Phone    *string         `json:"phone,omitempty"`
-		Role     *model.UserRole `json:"role,omitempty"`
-		IsActive *bool           `json:"is_active,omitempty"`
-	}
-
+	var req dto.AdminUpdateUserRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	user, err := h.userRepo.FindByID(userID)
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
-		return
-	}
-
-	// Update fields if provided
-	if req.Email != nil {
-		// Check if email is already taken by another user
-		existingUser, _ := h.userRepo.FindByEmail(*req.Email)
-		if existingUser != nil && existingUser.ID != user.ID {
-			c.JSON(http.StatusConflict, gin.H{"error": "email already taken"})
-			return
-		}
-		user.Email = *req.Email
-	}
-
-	if req.Phone != nil {
-		// Check if phone is already taken by another user
-		existingUser, _ := h.userRepo.FindByPhone(*req.Phone)
-		if existingUser != nil && existingUser.ID != user.ID {
-			c.JSON(http.StatusConflict, gin.H{"error": "phone number already taken"})
-			return
+	if err := h.authService.UpdateUser(c.Request.Context(), userID, &req); err != nil {
+		switch {
+		case errors.Is(err, service.ErrUserNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		case errors.Is(err, service.ErrEmailTaken), errors.Is(err, service.ErrPhoneTaken):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrInvalidPhone):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
 		}
-		user.Phone = *req.Phone
-	}
-
-	if req.Role != nil {
-		user.Role = *req.Role
-	}
-
-	if req.IsActive != nil {
-		user.IsActive = *req.IsActive
-	}
-
-	user.UpdatedAt = time.Now()
-
-	if err := h.userRepo.Update(user); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
 		return
 	}
 
@@ -181,7 +151,11 @@ func (h *AdminHandler) DeleteUser(c *gin.Context) {
 		return
 	}
 
-	if err := h.userRepo.Delete(userID); err != nil {
+	if err := h.authService.DeleteUser(c.Request.Context(), userID); err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete user"})
 		return
 	}
@@ -197,16 +171,11 @@ func (h *AdminHandler) ActivateUser(c *gin.Context) {
 		return
 	}
 
-	user, err := h.userRepo.FindByID(userID)
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
-		return
-	}
-
-	user.IsActive = true
-	user.UpdatedAt = time.Now()
-
-	if err := h.userRepo.Update(user); err != nil {
+	if err := h.authService.SetUserActive(c.Request.Context(), userID, true); err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to activate user"})
 		return
 	}
@@ -214,7 +183,7 @@ func (h *AdminHandler) ActivateUser(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "user activated successfully"})
 }
 
-// DeactivateUser deactivates a user account
+// DeactivateUser deactivates a user account and signs them out everywhere
 func (h *AdminHandler) DeactivateUser(c *gin.Context) {
 	userID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
@@ -229,16 +198,11 @@ func (h *AdminHandler) DeactivateUser(c *gin.Context) {
 		return
 	}
 
-	user, err := h.userRepo.FindByID(userID)
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
-		return
-	}
-
-	user.IsActive = false
-	user.UpdatedAt = time.Now()
-
-	if err := h.userRepo.Update(user); err != nil {
+	if err := h.authService.SetUserActive(c.Request.Context(), userID, false); err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to deactivate user"})
 		return
 	}
@@ -257,7 +221,8 @@ func (h *AdminHandler) GetUserStats(c *gin.Context) {
 	c.JSON(http.StatusOK, stats)
 }
 
-// ImpersonateUser allows admin to get access token for another user (for support purposes)
+// ImpersonateUser gives an admin a short-lived token to act as another
+// user for support purposes. It is recorded, and shown to the user.
 func (h *AdminHandler) ImpersonateUser(c *gin.Context) {
 	userID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
@@ -265,39 +230,161 @@ func (h *AdminHandler) ImpersonateUser(c *gin.Context) {
 		return
 	}
 
-	user, err := h.userRepo.FindByID(userID)
+	var req dto.ImpersonateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validate.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "a reason of at least 5 characters is required, and duration_minutes must be between 1 and 120"})
+		return
+	}
+
+	adminID, _ := GetUserIDFromContext(c)
+	result, err := h.authService.Impersonate(c.Request.Context(), adminID, userID, &req, clientInfo(c))
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		switch {
+		case errors.Is(err, service.ErrUserNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrCannotImpersonate):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start impersonation"})
+		}
 		return
 	}
 
-	// Generate token for the target user
-	accessToken, expiresAt, err := h.jwtManager.GenerateAccessToken(user)
+	c.JSON(http.StatusOK, result)
+}
+
+// GetImpersonations lists impersonations, optionally for one user or admin
+func (h *AdminHandler) GetImpersonations(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	filter := repository.ImpersonationFilter{Page: page, PageSize: limit}
+
+	if v := c.Query("user_id"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+			return
+		}
+		filter.UserID = &id
+	}
+	if v := c.Query("impersonator_id"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid impersonator ID"})
+			return
+		}
+		filter.ImpersonatorID = &id
+	}
+
+	impersonations, total, err := h.authService.GetImpersonations(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch impersonations"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"impersonations": impersonations, "total": total})
+}
+
+// GetImpersonationActions lists the changes made during an impersonation
+func (h *AdminHandler) GetImpersonationActions(c *gin.Context) {
+	impersonationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid impersonation ID"})
+		return
+	}
+
+	actions, err := h.authService.GetImpersonationActions(c.Request.Context(), impersonationID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		if errors.Is(err, service.ErrImpersonationNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch impersonation actions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"actions": actions})
+}
+
+// Helper function to get user ID from context
+// GetLockouts lists accounts and IP addresses with recent failed sign-ins,
+// optionally only those locked out
+func (h *AdminHandler) GetLockouts(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	filter := repository.AttemptFilter{
+		Scope:      c.Query("scope"),
+		LockedOnly: c.Query("locked") == "true",
+		Page:       page,
+		PageSize:   limit,
+	}
+
+	if v := c.Query("user_id"); v != "" {
+		userID, err := uuid.Parse(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user_id"})
+			return
+		}
+		filter.UserID = &userID
+	}
+
+	lockouts, total, err := h.throttle.GetLockouts(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch lockouts"})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"access_token": accessToken,
-		"expires_at":   expiresAt,
-		"user": &dto.UserResponse{
-			ID:         user.ID,
-			Email:      user.Email,
-			Phone:      user.Phone,
-			Role:       user.Role,
-			IsVerified: user.IsVerified,
-			IsActive:   user.IsActive,
-			CreatedAt:  user.CreatedAt,
-		},
-		"impersonated": true,
-		"message":      "Use this token to act on behalf of the user",
+		"lockouts": lockouts,
+		"total":    total,
+		"page":     page,
+		"limit":    limit,
 	})
 }
 
-// Helper function to get user ID from context
+// ClearLockout lets a locked out account or IP address sign in again
+func (h *AdminHandler) ClearLockout(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid lockout ID"})
+		return
+	}
+
+	if err := h.throttle.ClearLockout(c.Request.Context(), id); err != nil {
+		if errors.Is(err, service.ErrLockoutNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to clear lockout"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "lockout cleared"})
+}
+
+// UnlockUser clears the failed sign-ins against a user's account
+func (h *AdminHandler) UnlockUser(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	cleared, err := h.throttle.ClearUserLockouts(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unlock user"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "user unlocked", "cleared": cleared})
+}
+
 func GetUserIDFromContext(c *gin.Context) (uuid.UUID, error) {
-	userID, exists := c.Get("user")
+	userID, exists := c.Get(middleware.UserContextKey)
 	if !exists {
 		return uuid.Nil, service.ErrUnauthorized
 	}
@@ -2,8 +2,13 @@ package handler
 
 import (
 	"agro_konnect/internal/auth/dto"
+	"agro_konnect/internal/auth/middleware"
+	"agro_konnect/internal/auth/repository"
 	"agro_konnect/internal/auth/service"
+	"agro_konnect/pkg/ratelimit"
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -30,18 +35,24 @@ func (h *AuthHandler) Register(c *gin.Context) {
 			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
 			return
 		}
+		if err == service.ErrInvalidPhone {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	userResponse := &dto.UserResponse{
-		ID:         user.ID,
-		Email:      user.Email,
-		Phone:      user.Phone,
-		Role:       user.Role,
-		IsVerified: user.IsVerified,
-		IsActive:   user.IsActive,
-		CreatedAt:  user.CreatedAt,
+		ID:            user.ID,
+		Email:         user.Email,
+		Phone:         user.Phone,
+		Role:          user.Role,
+		IsVerified:    user.IsVerified,
+		PhoneVerified: user.PhoneVerified,
+		IsActive:      user.IsActive,
+		Locale:        user.Locale,
+		CreatedAt:     user.CreatedAt,
 	}
 
 	c.JSON(http.StatusCreated, gin.H{
@@ -57,8 +68,11 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	authResponse, err := h.authService.Login(&req)
+	authResponse, err := h.authService.Login(c.Request.Context(), &req, clientInfo(c))
 	if err != nil {
+		if respondIfThrottled(c, err) {
+			return
+		}
 		if err == service.ErrInvalidCredentials {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 			return
@@ -82,6 +96,10 @@ func (h *AuthHandler) VerifyEmail(c *gin.Context) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid verification code"})
 			return
 		}
+		if err == service.ErrTooManyAttempts {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -116,6 +134,10 @@ func (h *AuthHandler) ResetPassword(c *gin.Context) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired token"})
 			return
 		}
+		if err == service.ErrTooManyAttempts || err == service.ErrResetEmailRequired || err == service.ErrResetCodeRequired {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -123,6 +145,120 @@ func (h *AuthHandler) ResetPassword(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Password reset successfully"})
 }
 
+// SendPhoneVerification texts a code to verify the user's phone number
+func (h *AuthHandler) SendPhoneVerification(c *gin.Context) {
+	var req dto.PhoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.authService.SendPhoneVerification(c.Request.Context(), &req); err != nil {
+		respondWithPhoneError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "If the number has an unverified account, a verification code has been sent"})
+}
+
+func (h *AuthHandler) VerifyPhone(c *gin.Context) {
+	var req dto.PhoneCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.authService.VerifyPhone(c.Request.Context(), &req); err != nil {
+		respondWithPhoneError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Phone number verified successfully"})
+}
+
+// RequestPhoneLogin texts a one-time code to sign in with
+func (h *AuthHandler) RequestPhoneLogin(c *gin.Context) {
+	var req dto.PhoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.authService.RequestPhoneLogin(c.Request.Context(), &req); err != nil {
+		respondWithPhoneError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "If the number has an account, a login code has been sent"})
+}
+
+func (h *AuthHandler) LoginWithPhone(c *gin.Context) {
+	var req dto.PhoneCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	authResponse, err := h.authService.LoginWithPhone(c.Request.Context(), &req, clientInfo(c))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidCredentials) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid phone number or code"})
+			return
+		}
+		respondWithPhoneError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, authResponse)
+}
+
+func (h *AuthHandler) ForgotPasswordByPhone(c *gin.Context) {
+	var req dto.PhoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.authService.ForgotPasswordByPhone(c.Request.Context(), &req); err != nil {
+		respondWithPhoneError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "If the number has an account, a password reset code has been sent"})
+}
+
+func (h *AuthHandler) ResetPasswordByPhone(c *gin.Context) {
+	var req dto.PhoneResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.authService.ResetPasswordByPhone(c.Request.Context(), &req); err != nil {
+		respondWithPhoneError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password reset successfully"})
+}
+
+// respondWithPhoneError maps the errors of the phone code flows
+func respondWithPhoneError(c *gin.Context, err error) {
+	if respondIfThrottled(c, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, service.ErrInvalidPhone):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidToken):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired code"})
+	case errors.Is(err, service.ErrTooManyAttempts):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
 func (h *AuthHandler) ChangePassword(c *gin.Context) {
 	userID, err := GetUserIDFromContext(c)
 	if err != nil {
@@ -158,19 +294,129 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 		return
 	}
 
-	authResponse, err := h.authService.RefreshToken(req.RefreshToken)
+	authResponse, err := h.authService.RefreshToken(c.Request.Context(), req.RefreshToken, clientInfo(c))
 	if err != nil {
-		if err == service.ErrInvalidToken {
+		switch {
+		case errors.Is(err, service.ErrInvalidToken), errors.Is(err, service.ErrUserNotFound):
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid refresh token"})
-			return
+		case errors.Is(err, service.ErrTokenExpired), errors.Is(err, service.ErrSessionRevoked), errors.Is(err, service.ErrTokenReused):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, authResponse)
 }
 
+// Logout ends the session the request was made with
+func (h *AuthHandler) Logout(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	sessionID, err := middleware.GetSessionIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.authService.Logout(c.Request.Context(), userID, sessionID); err != nil && !errors.Is(err, service.ErrSessionNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log out"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
+}
+
+// LogoutAll ends every session of the user, on all devices
+func (h *AuthHandler) LogoutAll(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	count, err := h.authService.LogoutAll(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log out"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out on all devices", "sessions_ended": count})
+}
+
+// GetSessions lists the devices the user is signed in on
+func (h *AuthHandler) GetSessions(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	sessionID, _ := middleware.GetSessionIDFromContext(c)
+
+	sessions, err := h.authService.GetSessions(c.Request.Context(), userID, sessionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch sessions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"sessions": sessions})
+}
+
+// RevokeSession signs the user out on one device
+func (h *AuthHandler) RevokeSession(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid session ID"})
+		return
+	}
+
+	if err := h.authService.RevokeSession(c.Request.Context(), userID, sessionID); err != nil {
+		if errors.Is(err, service.ErrSessionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to end session"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Session ended successfully"})
+}
+
+// GetSupportAccess lists the times support signed in as the user. An
+// ongoing one can be ended like any other session.
+func (h *AuthHandler) GetSupportAccess(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	impersonations, total, err := h.authService.GetImpersonations(c.Request.Context(), repository.ImpersonationFilter{
+		UserID:   &userID,
+		Page:     page,
+		PageSize: limit,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch support access history"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"support_access": impersonations, "total": total})
+}
+
 func (h *AuthHandler) GetProfile(c *gin.Context) {
 	userID, err := GetUserIDFromContext(c)
 	if err != nil {
@@ -199,3 +445,23 @@ func (h *AuthHandler) GetUserIDFromContext(c *gin.Context) (uuid.UUID, error) {
 	}
 	return userID.(uuid.UUID), nil
 }
+
+// clientInfo describes the device making the request, for its session
+// respondIfThrottled answers with 429 and when to try again if too many
+// sign-in attempts failed.
+func respondIfThrottled(c *gin.Context, err error) bool {
+	var throttled *service.ThrottledError
+	if !errors.As(err, &throttled) {
+		return false
+	}
+	c.Header("Retry-After", strconv.Itoa(ratelimit.RetryAfterSeconds(throttled.RetryAfter)))
+	c.JSON(http.StatusTooManyRequests, gin.H{"error": throttled.Error()})
+	return true
+}
+
+func clientInfo(c *gin.Context) dto.ClientInfo {
+	return dto.ClientInfo{
+		UserAgent: c.Request.UserAgent(),
+		IPAddress: c.ClientIP(),
+	}
+}
@@ -0,0 +1,247 @@
+package handler
+
+import (
+	"agro_konnect/internal/auth/dto"
+	"agro_konnect/internal/auth/service"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// VerifyTwoFactorLogin completes a sign-in with the code from the
+// authenticator app or a recovery code
+func (h *AuthHandler) VerifyTwoFactorLogin(c *gin.Context) {
+	var req dto.TwoFactorLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	authResponse, err := h.authService.VerifyTwoFactorLogin(c.Request.Context(), &req, clientInfo(c))
+	if err != nil {
+		respondWithTwoFactorError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, authResponse)
+}
+
+// BeginTwoFactorSetupWithChallenge starts enrolment for a user who must
+// have two-factor authentication before they can sign in
+func (h *AuthHandler) BeginTwoFactorSetupWithChallenge(c *gin.Context) {
+	var req dto.TwoFactorChallengeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	setup, err := h.authService.BeginTwoFactorSetupWithChallenge(c.Request.Context(), &req)
+	if err != nil {
+		respondWithTwoFactorError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, setup)
+}
+
+func (h *AuthHandler) EnableTwoFactorWithChallenge(c *gin.Context) {
+	var req dto.TwoFactorLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.authService.EnableTwoFactorWithChallenge(c.Request.Context(), &req, clientInfo(c))
+	if err != nil {
+		respondWithTwoFactorError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *AuthHandler) GetTwoFactorStatus(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	status, err := h.authService.GetTwoFactorStatus(c.Request.Context(), userID)
+	if err != nil {
+		respondWithTwoFactorError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// BeginTwoFactorSetup returns a new secret and its otpauth:// URI to show
+// as a QR code
+func (h *AuthHandler) BeginTwoFactorSetup(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	setup, err := h.authService.BeginTwoFactorSetup(c.Request.Context(), userID)
+	if err != nil {
+		respondWithTwoFactorError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, setup)
+}
+
+func (h *AuthHandler) EnableTwoFactor(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req dto.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	codes, err := h.authService.EnableTwoFactor(c.Request.Context(), userID, &req, clientInfo(c))
+	if err != nil {
+		respondWithTwoFactorError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, codes)
+}
+
+func (h *AuthHandler) DisableTwoFactor(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req dto.DisableTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.authService.DisableTwoFactor(c.Request.Context(), userID, &req, clientInfo(c)); err != nil {
+		if errors.Is(err, service.ErrInvalidCredentials) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Current password is incorrect"})
+			return
+		}
+		respondWithTwoFactorError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+}
+
+func (h *AuthHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req dto.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	codes, err := h.authService.RegenerateRecoveryCodes(c.Request.Context(), userID, &req, clientInfo(c))
+	if err != nil {
+		respondWithTwoFactorError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, codes)
+}
+
+// respondWithTwoFactorError maps the errors of two-factor sign-in and
+// enrolment
+func respondWithTwoFactorError(c *gin.Context, err error) {
+	if respondIfThrottled(c, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, service.ErrInvalidToken):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired challenge, please log in again"})
+	case errors.Is(err, service.ErrInvalidTwoFactorCode):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrTwoFactorAlreadyEnabled), errors.Is(err, service.ErrTwoFactorNotEnabled):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrTwoFactorSetupNotBegun):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrTwoFactorRequired):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// ResetUserTwoFactor turns off two-factor authentication for a user who
+// lost access to it
+func (h *AdminHandler) ResetUserTwoFactor(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	if err := h.authService.ResetTwoFactor(c.Request.Context(), userID); err != nil {
+		switch {
+		case errors.Is(err, service.ErrUserNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		case errors.Is(err, service.ErrTwoFactorNotEnabled):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset two-factor authentication"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "two-factor authentication reset"})
+}
+
+func (h *AdminHandler) GetTwoFactorPolicy(c *gin.Context) {
+	policy, err := h.authService.GetTwoFactorPolicy(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch two-factor policy"})
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+// UpdateTwoFactorPolicy sets whether admins, and buyers above a credit
+// limit, must use two-factor authentication
+func (h *AdminHandler) UpdateTwoFactorPolicy(c *gin.Context) {
+	var req dto.TwoFactorPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validate.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "buyer_credit_threshold must not be negative"})
+		return
+	}
+
+	adminID, _ := GetUserIDFromContext(c)
+	policy, err := h.authService.UpdateTwoFactorPolicy(c.Request.Context(), adminID, &req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update two-factor policy"})
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
@@ -1,28 +1,49 @@
 package middleware
 
 import (
+	auditservice "agro_konnect/internal/audit/service"
 	"agro_konnect/internal/auth/model"
 	"agro_konnect/internal/auth/service"
 	"agro_konnect/internal/auth/utils"
+	"context"
+	"log"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
 const (
-	AuthorizationHeader = "Authorization"
-	UserContextKey      = "userID"   // unified key for userID
-	UserRoleContextKey  = "userRole" // unified key for role
+	AuthorizationHeader    = "Authorization"
+	UserContextKey         = "userID"         // unified key for userID
+	UserRoleContextKey     = "userRole"       // unified key for role
+	SessionContextKey      = "sessionID"      // session the access token belongs to
+	ImpersonatorContextKey = "impersonatorID" // admin acting as the user, if any
 )
 
+// SessionValidator reports whether a session is still signed in, so that
+// logging out or deactivating a user cuts off their access tokens at once.
+type SessionValidator interface {
+	IsActive(ctx context.Context, sessionID uuid.UUID) (bool, error)
+}
+
+// ImpersonationRecorder keeps the trail of changes made while an admin is
+// impersonating a user.
+type ImpersonationRecorder interface {
+	RecordAction(ctx context.Context, action *model.ImpersonationAction) error
+	SetActionStatus(ctx context.Context, actionID uuid.UUID, status int) error
+}
+
 type AuthMiddleware struct {
-	jwtManager *utils.JWTManager
+	jwtManager     *utils.JWTManager
+	sessions       SessionValidator
+	impersonations ImpersonationRecorder
 }
 
-func NewAuthMiddleware(jwtManager *utils.JWTManager) *AuthMiddleware {
-	return &AuthMiddleware{jwtManager: jwtManager}
+func NewAuthMiddleware(jwtManager *utils.JWTManager, sessions SessionValidator, impersonations ImpersonationRecorder) *AuthMiddleware {
+	return &AuthMiddleware{jwtManager: jwtManager, sessions: sessions, impersonations: impersonations}
 }
 
 // Authenticate verifies JWT and injects user info into context
@@ -35,7 +56,7 @@ func (m *AuthMiddleware) Authenticate() gin.HandlerFunc {
 			return
 		}
 
-		claims, err := m.jwtManager.ValidateToken(token)
+		claims, err := m.jwtManager.ValidateTokenOfType(token, utils.TokenTypeAccess)
 		if err != nil {
 			if err == utils.ErrTokenExpired {
 				c.JSON(http.StatusUnauthorized, gin.H{"error": "token expired"})
@@ -46,11 +67,83 @@ func (m *AuthMiddleware) Authenticate() gin.HandlerFunc {
 			return
 		}
 
+		active, err := m.sessions.IsActive(c.Request.Context(), claims.SessionID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to verify session"})
+			c.Abort()
+			return
+		}
+		if !active {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "session has ended"})
+			c.Abort()
+			return
+		}
+
 		// ✅ Use claims.UserID directly
 		userID := claims.UserID
 
 		c.Set(UserContextKey, userID)
 		c.Set(UserRoleContextKey, claims.Role)
+		c.Set(SessionContextKey, claims.SessionID)
+
+		// Services attribute their audit entries from the request context
+		actor := auditservice.Actor{UserID: userID, Role: string(claims.Role)}
+		if claims.Act != nil {
+			actor.ImpersonatorID = &claims.Act.Subject
+		}
+		c.Request = c.Request.WithContext(auditservice.WithActor(c.Request.Context(), actor))
+
+		if claims.Act != nil {
+			c.Set(ImpersonatorContextKey, claims.Act.Subject)
+			if !isSafeMethod(c.Request.Method) {
+				m.recordImpersonated(c, claims)
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+// recordImpersonated runs a request that changes something under
+// impersonation. It is recorded before it runs and refused if that fails,
+// so nothing done as someone else goes unaccounted for.
+func (m *AuthMiddleware) recordImpersonated(c *gin.Context, claims *utils.Claims) {
+	if claims.ReadOnly {
+		c.JSON(http.StatusForbidden, gin.H{"error": "this impersonation session is read-only"})
+		c.Abort()
+		return
+	}
+
+	action := &model.ImpersonationAction{
+		ID:              uuid.New(),
+		ImpersonationID: claims.SessionID,
+		Method:          c.Request.Method,
+		Path:            c.Request.URL.RequestURI(),
+		CreatedAt:       time.Now(),
+	}
+	if err := m.impersonations.RecordAction(c.Request.Context(), action); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to record impersonated request"})
+		c.Abort()
+		return
+	}
+
+	c.Next()
+
+	// The request may have cancelled its context by now
+	if err := m.impersonations.SetActionStatus(context.Background(), action.ID, c.Writer.Status()); err != nil {
+		log.Printf("failed to record status of impersonated request %s: %v", action.ID, err)
+	}
+}
+
+// DenyImpersonation keeps an account's credentials and sign-ins in its
+// owner's hands, even during a full impersonation.
+func (m *AuthMiddleware) DenyImpersonation() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, impersonated := c.Get(ImpersonatorContextKey); impersonated {
+			c.JSON(http.StatusForbidden, gin.H{"error": "not allowed while impersonating a user"})
+			c.Abort()
+			return
+		}
 		c.Next()
 	}
 }
@@ -90,6 +183,10 @@ func (m *AuthMiddleware) RequireRole(roles ...model.UserRole) gin.HandlerFunc {
 	}
 }
 
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
 // Helper to extract bearer token
 func extractToken(r *http.Request) string {
 	bearerToken := r.Header.Get(AuthorizationHeader)
@@ -107,3 +204,12 @@ func GetUserIDFromContext(c *gin.Context) (uuid.UUID, error) {
 	}
 	return userID.(uuid.UUID), nil
 }
+
+// Helper to get the current session ID from context
+func GetSessionIDFromContext(c *gin.Context) (uuid.UUID, error) {
+	sessionID, exists := c.Get(SessionContextKey)
+	if !exists {
+		return uuid.Nil, service.ErrUnauthorized
+	}
+	return sessionID.(uuid.UUID), nil
+}
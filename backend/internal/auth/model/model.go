@@ -17,13 +17,23 @@ const (
 )
 
 type User struct {
-	ID           uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
-	Email        string    `gorm:"uniqueIndex;not null" json:"email"`
-	Phone        string    `gorm:"uniqueIndex;not null" json:"phone"`
-	PasswordHash string    `gorm:"not null" json:"-"`
-	Role         UserRole  `gorm:"type:varchar(20);not null" json:"role"`
-	IsVerified   bool      `gorm:"default:false" json:"is_verified"`
-	IsActive     bool      `gorm:"default:true" json:"is_active"`
+	ID            uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	Email         string    `gorm:"uniqueIndex;not null" json:"email"`
+	Phone         string    `gorm:"uniqueIndex;not null" json:"phone"`
+	PasswordHash  string    `gorm:"not null" json:"-"`
+	Role          UserRole  `gorm:"type:varchar(20);not null" json:"role"`
+	IsVerified    bool      `gorm:"default:false" json:"is_verified"` // email verified
+	PhoneVerified bool      `gorm:"default:false" json:"phone_verified"`
+	IsActive      bool      `gorm:"default:true" json:"is_active"`
+	Locale        string    `gorm:"type:varchar(10);not null;default:'en'" json:"locale"` // language of emails and texts sent to the user
+
+	// Two-factor authentication. The secret is kept while enrolment is
+	// pending and becomes active once a code from it has been confirmed. It
+	// is stored sealed with the server key (see utils.SealTOTPSecret).
+	TOTPSecret       string     `gorm:"column:totp_secret" json:"-"`
+	TOTPEnabled      bool       `gorm:"column:totp_enabled;default:false" json:"totp_enabled"`
+	TOTPEnabledAt    *time.Time `gorm:"column:totp_enabled_at" json:"totp_enabled_at,omitempty"`
+	TOTPLastUsedStep int64      `gorm:"column:totp_last_used_step;default:0" json:"-"` // so a code can't be used twice
 
 	// Profile references
 	FarmerID      *uuid.UUID `gorm:"type:uuid" json:"farmer_id,omitempty"`
@@ -40,12 +50,63 @@ type VerificationCode struct {
 	ID        uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
 	UserID    uuid.UUID `gorm:"type:uuid;not null" json:"user_id"`
 	Code      string    `gorm:"not null;size:6" json:"code"`
-	Type      string    `gorm:"not null" json:"type"` // "email_verification", "password_reset"
+	Type      string    `gorm:"not null" json:"type"`               // one of the CodeType constants
+	Phone     string    `gorm:"index" json:"phone,omitempty"`       // number texted, for codes sent by SMS
+	Attempts  int       `gorm:"not null;default:0" json:"attempts"` // wrong guesses so far
 	ExpiresAt time.Time `gorm:"not null" json:"expires_at"`
 	Used      bool      `gorm:"default:false" json:"used"`
 	CreatedAt time.Time `json:"created_at"`
 }
 
+// What a verification code is for. Phone codes are sent by SMS.
+const (
+	CodeTypeEmailVerification  = "email_verification"
+	CodeTypePasswordReset      = "password_reset"
+	CodeTypePhoneVerification  = "phone_verification"
+	CodeTypePhoneLogin         = "phone_login"
+	CodeTypePhonePasswordReset = "phone_password_reset"
+)
+
+// RecoveryCode is a one-time code to sign in with when the authenticator
+// app is lost. Only a hash of it is stored.
+type RecoveryCode struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	CodeHash  string     `gorm:"not null;size:64" json:"-"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// TwoFactorPolicy says who must use two-factor authentication. There is a
+// single row.
+type TwoFactorPolicy struct {
+	ID                   int        `gorm:"primary_key" json:"-"`
+	RequireForAdmins     bool       `gorm:"not null;default:false" json:"require_for_admins"`
+	BuyerCreditThreshold *float64   `gorm:"type:decimal(12,2)" json:"buyer_credit_threshold"` // buyers with a credit limit above this must use it; nil for none
+	UpdatedBy            *uuid.UUID `gorm:"type:uuid" json:"updated_by,omitempty"`
+	UpdatedAt            time.Time  `json:"updated_at"`
+}
+
+// AttemptCounter counts recent failed sign-in attempts against an account
+// or from an IP address. Enough of them lock it out for a while.
+type AttemptCounter struct {
+	ID            uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
+	Scope         string     `gorm:"type:varchar(20);not null;uniqueIndex:idx_attempt_counters_scope_subject" json:"scope"` // one of the AttemptScope constants
+	Subject       string     `gorm:"not null;uniqueIndex:idx_attempt_counters_scope_subject" json:"subject"`                // user ID, or the email or phone tried, or an IP address
+	UserID        *uuid.UUID `gorm:"type:uuid;index" json:"user_id,omitempty"`                                              // set when the account exists
+	Failures      int        `gorm:"not null;default:0" json:"failures"`
+	FirstFailedAt time.Time  `gorm:"not null" json:"first_failed_at"`
+	LastFailedAt  time.Time  `gorm:"not null;index" json:"last_failed_at"`
+	LockedUntil   *time.Time `json:"locked_until,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+const (
+	AttemptScopeAccount = "account"
+	AttemptScopeIP      = "ip"
+)
+
 type UserStats struct {
 	TotalUsers    int64              `json:"total_users"`
 	ActiveUsers   int64              `json:"active_users"`
@@ -53,3 +114,55 @@ type UserStats struct {
 	UsersByRole   map[UserRole]int64 `json:"users_by_role"`
 	NewUsersToday int64              `json:"new_users_today"`
 }
+
+// Session is one signed-in device. Refreshing replaces the session's refresh
+// token, and only the latest one is accepted: presenting an older one means
+// it was copied, so the session is revoked.
+type Session struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
+	UserID         uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	RefreshTokenID string     `gorm:"not null" json:"-"`                          // ID of the latest refresh token; empty if it can't be refreshed
+	ImpersonatorID *uuid.UUID `gorm:"type:uuid" json:"impersonator_id,omitempty"` // admin acting as the user
+	UserAgent      string     `json:"user_agent"`
+	IPAddress      string     `json:"ip_address"`
+	CreatedAt      time.Time  `json:"created_at"`
+	LastUsedAt     time.Time  `json:"last_used_at"`
+	ExpiresAt      time.Time  `gorm:"index" json:"expires_at"`
+	RevokedAt      *time.Time `json:"revoked_at,omitempty"`
+	RevokeReason   string     `json:"revoke_reason,omitempty"`
+}
+
+// Why a session was revoked
+const (
+	RevokeReasonLogout        = "logout"
+	RevokeReasonTokenReuse    = "token_reuse"
+	RevokeReasonDeactivated   = "user_deactivated"
+	RevokeReasonDeleted       = "user_deleted"
+	RevokeReasonPasswordReset = "password_reset"
+)
+
+// Impersonation is an admin acting as a user, e.g. to reproduce a support
+// issue. It runs on a session of its own, with the same ID, that cannot be
+// refreshed.
+type Impersonation struct {
+	ID             uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	ImpersonatorID uuid.UUID `gorm:"type:uuid;not null;index" json:"impersonator_id"`
+	UserID         uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+	Reason         string    `gorm:"type:text;not null" json:"reason"`
+	ReadOnly       bool      `json:"read_only"`
+	IPAddress      string    `json:"ip_address"`
+	StartedAt      time.Time `gorm:"index" json:"started_at"`
+	ExpiresAt      time.Time `json:"expires_at"`
+}
+
+// ImpersonationAction is a request that changed something, made while
+// impersonating. It is written before the request runs so that nothing
+// goes unrecorded.
+type ImpersonationAction struct {
+	ID              uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	ImpersonationID uuid.UUID `gorm:"type:uuid;not null;index" json:"impersonation_id"`
+	Method          string    `gorm:"type:varchar(10);not null" json:"method"`
+	Path            string    `gorm:"not null" json:"path"`
+	StatusCode      int       `json:"status_code"` // 0 if the request never finished
+	CreatedAt       time.Time `json:"created_at"`
+}
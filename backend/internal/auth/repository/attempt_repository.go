@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	model "agro_konnect/internal/auth/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AttemptCounterRow is a failed-attempt counter with the account's email.
+type AttemptCounterRow struct {
+	model.AttemptCounter
+	UserEmail string
+}
+
+type AttemptFilter struct {
+	Scope      string
+	UserID     *uuid.UUID
+	LockedOnly bool
+	Page       int
+	PageSize   int
+}
+
+type AttemptRepository interface {
+	Find(ctx context.Context, scope, subject string) (*model.AttemptCounter, error)
+	RecordFailure(ctx context.Context, scope, subject string, userID *uuid.UUID, windowStart time.Time) (*model.AttemptCounter, error)
+	Lock(ctx context.Context, id uuid.UUID, until time.Time) error
+	Clear(ctx context.Context, scope, subject string) error
+	DeleteByID(ctx context.Context, id uuid.UUID) (bool, error)
+	DeleteByUserID(ctx context.Context, userID uuid.UUID) (int64, error)
+	FindAll(ctx context.Context, filter AttemptFilter) ([]*AttemptCounterRow, int64, error)
+	DeleteStale(ctx context.Context, before time.Time) (int64, error)
+}
+
+type attemptRepository struct {
+	db *gorm.DB
+}
+
+func NewAttemptRepository(db *gorm.DB) AttemptRepository {
+	return &attemptRepository{db: db}
+}
+
+func (r *attemptRepository) Find(ctx context.Context, scope, subject string) (*model.AttemptCounter, error) {
+	var counters []*model.AttemptCounter
+	err := r.db.WithContext(ctx).
+		Where("scope = ? AND subject = ?", scope, subject).
+		Limit(1).
+		Find(&counters).Error
+	if err != nil || len(counters) == 0 {
+		return nil, err
+	}
+	return counters[0], nil
+}
+
+// RecordFailure counts a failed attempt and returns the counter. Failures
+// from before windowStart are forgotten and counting starts again, along
+// with any lockout that has run out.
+func (r *attemptRepository) RecordFailure(ctx context.Context, scope, subject string, userID *uuid.UUID, windowStart time.Time) (*model.AttemptCounter, error) {
+	now := time.Now()
+	var counter model.AttemptCounter
+	err := r.db.WithContext(ctx).Raw(`
+		INSERT INTO attempt_counters (id, scope, subject, user_id, failures, first_failed_at, last_failed_at, created_at, updated_at)
+		VALUES (?, ?, ?, ?, 1, ?, ?, ?, ?)
+		ON CONFLICT (scope, subject) DO UPDATE SET
+			failures = CASE WHEN attempt_counters.last_failed_at < ? THEN 1 ELSE attempt_counters.failures + 1 END,
+			first_failed_at = CASE WHEN attempt_counters.last_failed_at < ? THEN EXCLUDED.first_failed_at ELSE attempt_counters.first_failed_at END,
+			locked_until = CASE WHEN attempt_counters.locked_until > EXCLUDED.last_failed_at THEN attempt_counters.locked_until END,
+			last_failed_at = EXCLUDED.last_failed_at,
+			user_id = COALESCE(EXCLUDED.user_id, attempt_counters.user_id),
+			updated_at = EXCLUDED.updated_at
+		RETURNING *`,
+		uuid.New(), scope, subject, userID, now, now, now, now,
+		windowStart, windowStart).
+		Scan(&counter).Error
+	if err != nil {
+		return nil, err
+	}
+	return &counter, nil
+}
+
+func (r *attemptRepository) Lock(ctx context.Context, id uuid.UUID, until time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&model.AttemptCounter{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{"locked_until": until, "updated_at": time.Now()}).Error
+}
+
+// Clear forgets the failures of a scope and subject, after a successful
+// sign-in.
+func (r *attemptRepository) Clear(ctx context.Context, scope, subject string) error {
+	return r.db.WithContext(ctx).
+		Where("scope = ? AND subject = ?", scope, subject).
+		Delete(&model.AttemptCounter{}).Error
+}
+
+func (r *attemptRepository) DeleteByID(ctx context.Context, id uuid.UUID) (bool, error) {
+	result := r.db.WithContext(ctx).Where("id = ?", id).Delete(&model.AttemptCounter{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// DeleteByUserID clears every counter against a user's account.
+func (r *attemptRepository) DeleteByUserID(ctx context.Context, userID uuid.UUID) (int64, error) {
+	result := r.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&model.AttemptCounter{})
+	return result.RowsAffected, result.Error
+}
+
+// FindAll lists counters, most recent failure first.
+func (r *attemptRepository) FindAll(ctx context.Context, filter AttemptFilter) ([]*AttemptCounterRow, int64, error) {
+	query := r.db.WithContext(ctx).
+		Table("attempt_counters").
+		Select("attempt_counters.*, users.email AS user_email").
+		Joins("LEFT JOIN users ON users.id = attempt_counters.user_id")
+
+	if filter.Scope != "" {
+		query = query.Where("attempt_counters.scope = ?", filter.Scope)
+	}
+	if filter.UserID != nil {
+		query = query.Where("attempt_counters.user_id = ?", *filter.UserID)
+	}
+	if filter.LockedOnly {
+		query = query.Where("attempt_counters.locked_until > ?", time.Now())
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.PageSize < 1 || filter.PageSize > 100 {
+		filter.PageSize = 20
+	}
+
+	var rows []*AttemptCounterRow
+	err := query.
+		Order("attempt_counters.last_failed_at DESC").
+		Offset((filter.Page - 1) * filter.PageSize).
+		Limit(filter.PageSize).
+		Scan(&rows).Error
+	return rows, total, err
+}
+
+// DeleteStale removes counters with no failures since before and no
+// lockout still running.
+func (r *attemptRepository) DeleteStale(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("last_failed_at < ? AND (locked_until IS NULL OR locked_until < ?)", before, time.Now()).
+		Delete(&model.AttemptCounter{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	model "agro_konnect/internal/auth/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ImpersonationRow is an impersonation with the people involved and how it
+// went.
+type ImpersonationRow struct {
+	model.Impersonation
+	ImpersonatorEmail string
+	UserEmail         string
+	EndedAt           *time.Time // nil while it is still going on
+	ActionCount       int64
+}
+
+type ImpersonationFilter struct {
+	UserID         *uuid.UUID
+	ImpersonatorID *uuid.UUID
+	Page           int
+	PageSize       int
+}
+
+type ImpersonationRepository interface {
+	Create(ctx context.Context, impersonation *model.Impersonation) error
+	FindByID(ctx context.Context, id uuid.UUID) (*ImpersonationRow, error)
+	FindAll(ctx context.Context, filter ImpersonationFilter) ([]*ImpersonationRow, int64, error)
+	RecordAction(ctx context.Context, action *model.ImpersonationAction) error
+	SetActionStatus(ctx context.Context, actionID uuid.UUID, status int) error
+	FindActions(ctx context.Context, impersonationID uuid.UUID) ([]*model.ImpersonationAction, error)
+}
+
+type impersonationRepository struct {
+	db *gorm.DB
+}
+
+func NewImpersonationRepository(db *gorm.DB) ImpersonationRepository {
+	return &impersonationRepository{db: db}
+}
+
+func (r *impersonationRepository) Create(ctx context.Context, impersonation *model.Impersonation) error {
+	return r.db.WithContext(ctx).Create(impersonation).Error
+}
+
+// rows joins in the session to tell when an impersonation ended. Sessions
+// are cleaned up a month after they end; past that, the expiry stands in
+// for the end time.
+func (r *impersonationRepository) rows(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).
+		Table("impersonations").
+		Select(`impersonations.*, admins.email AS impersonator_email, users.email AS user_email,
+			CASE
+				WHEN sessions.revoked_at IS NOT NULL THEN sessions.revoked_at
+				WHEN impersonations.expires_at <= NOW() THEN impersonations.expires_at
+			END AS ended_at,
+			(SELECT COUNT(*) FROM impersonation_actions WHERE impersonation_actions.impersonation_id = impersonations.id) AS action_count`).
+		Joins("JOIN users AS admins ON admins.id = impersonations.impersonator_id").
+		Joins("JOIN users ON users.id = impersonations.user_id").
+		Joins("LEFT JOIN sessions ON sessions.id = impersonations.id")
+}
+
+func (r *impersonationRepository) FindByID(ctx context.Context, id uuid.UUID) (*ImpersonationRow, error) {
+	var row ImpersonationRow
+	err := r.rows(ctx).Where("impersonations.id = ?", id).Take(&row).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &row, err
+}
+
+// FindAll lists impersonations, latest first.
+func (r *impersonationRepository) FindAll(ctx context.Context, filter ImpersonationFilter) ([]*ImpersonationRow, int64, error) {
+	query := r.rows(ctx)
+	if filter.UserID != nil {
+		query = query.Where("impersonations.user_id = ?", *filter.UserID)
+	}
+	if filter.ImpersonatorID != nil {
+		query = query.Where("impersonations.impersonator_id = ?", *filter.ImpersonatorID)
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var rows []*ImpersonationRow
+	err := query.Order("impersonations.started_at DESC").
+		Offset((filter.Page - 1) * filter.PageSize).
+		Limit(filter.PageSize).
+		Find(&rows).Error
+	return rows, total, err
+}
+
+func (r *impersonationRepository) RecordAction(ctx context.Context, action *model.ImpersonationAction) error {
+	return r.db.WithContext(ctx).Create(action).Error
+}
+
+func (r *impersonationRepository) SetActionStatus(ctx context.Context, actionID uuid.UUID, status int) error {
+	return r.db.WithContext(ctx).Model(&model.ImpersonationAction{}).
+		Where("id = ?", actionID).
+		Update("status_code", status).Error
+}
+
+func (r *impersonationRepository) FindActions(ctx context.Context, impersonationID uuid.UUID) ([]*model.ImpersonationAction, error) {
+	var actions []*model.ImpersonationAction
+	err := r.db.WithContext(ctx).
+		Where("impersonation_id = ?", impersonationID).
+		Order("created_at ASC").
+		Find(&actions).Error
+	return actions, err
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	model "agro_konnect/internal/auth/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type SessionRepository interface {
+	Create(ctx context.Context, session *model.Session) error
+	FindByID(ctx context.Context, id uuid.UUID) (*model.Session, error)
+	FindActiveByUserID(ctx context.Context, userID uuid.UUID) ([]*model.Session, error)
+	IsActive(ctx context.Context, id uuid.UUID) (bool, error)
+	Rotate(ctx context.Context, id uuid.UUID, oldTokenID, newTokenID string, expiresAt time.Time, ipAddress string) (bool, error)
+	Revoke(ctx context.Context, id uuid.UUID, reason string) (bool, error)
+	RevokeAllForUser(ctx context.Context, userID uuid.UUID, reason string) (int64, error)
+	DeleteEndedBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+type sessionRepository struct {
+	db *gorm.DB
+}
+
+func NewSessionRepository(db *gorm.DB) SessionRepository {
+	return &sessionRepository{db: db}
+}
+
+func (r *sessionRepository) Create(ctx context.Context, session *model.Session) error {
+	return r.db.WithContext(ctx).Create(session).Error
+}
+
+func (r *sessionRepository) FindByID(ctx context.Context, id uuid.UUID) (*model.Session, error) {
+	var session model.Session
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&session).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &session, err
+}
+
+// FindActiveByUserID returns the devices a user signed in on themselves;
+// impersonation sessions are listed separately.
+func (r *sessionRepository) FindActiveByUserID(ctx context.Context, userID uuid.UUID) ([]*model.Session, error) {
+	var sessions []*model.Session
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Where("impersonator_id IS NULL").
+		Order("last_used_at DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// IsActive is checked on every authenticated request, so it reads a single
+// row by primary key.
+func (r *sessionRepository) IsActive(ctx context.Context, id uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.Session{}).
+		Where("id = ? AND revoked_at IS NULL AND expires_at > ?", id, time.Now()).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// Rotate swaps in a new refresh token only if oldTokenID is still the
+// latest, so two refreshes racing with the same token can't both succeed.
+func (r *sessionRepository) Rotate(ctx context.Context, id uuid.UUID, oldTokenID, newTokenID string, expiresAt time.Time, ipAddress string) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&model.Session{}).
+		Where("id = ? AND refresh_token_id = ? AND revoked_at IS NULL", id, oldTokenID).
+		Updates(map[string]interface{}{
+			"refresh_token_id": newTokenID,
+			"expires_at":       expiresAt,
+			"last_used_at":     time.Now(),
+			"ip_address":       ipAddress,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *sessionRepository) Revoke(ctx context.Context, id uuid.UUID, reason string) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&model.Session{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Updates(map[string]interface{}{"revoked_at": time.Now(), "revoke_reason": reason})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *sessionRepository) RevokeAllForUser(ctx context.Context, userID uuid.UUID, reason string) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&model.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Updates(map[string]interface{}{"revoked_at": time.Now(), "revoke_reason": reason})
+	return result.RowsAffected, result.Error
+}
+
+// DeleteEndedBefore removes sessions that expired or were revoked before
+// the given time.
+func (r *sessionRepository) DeleteEndedBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("expires_at < ? OR revoked_at < ?", before, before).
+		Delete(&model.Session{})
+	return result.RowsAffected, result.Error
+}
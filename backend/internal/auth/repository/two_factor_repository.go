@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	model "agro_konnect/internal/auth/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// twoFactorPolicyID is the id of the single policy row.
+const twoFactorPolicyID = 1
+
+type TwoFactorRepository interface {
+	SetPendingSecret(ctx context.Context, userID uuid.UUID, secret string) (bool, error)
+	Enable(ctx context.Context, userID uuid.UUID, step int64, codes []*model.RecoveryCode) (bool, error)
+	Disable(ctx context.Context, userID uuid.UUID) error
+	UseStep(ctx context.Context, userID uuid.UUID, step int64) (bool, error)
+	ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codes []*model.RecoveryCode) error
+	UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) (bool, error)
+	CountUnusedRecoveryCodes(ctx context.Context, userID uuid.UUID) (int64, error)
+	GetPolicy(ctx context.Context) (*model.TwoFactorPolicy, error)
+	SavePolicy(ctx context.Context, policy *model.TwoFactorPolicy) error
+	BuyerCreditLimit(ctx context.Context, userID uuid.UUID) (float64, error)
+}
+
+type twoFactorRepository struct {
+	db *gorm.DB
+}
+
+func NewTwoFactorRepository(db *gorm.DB) TwoFactorRepository {
+	return &twoFactorRepository{db: db}
+}
+
+// SetPendingSecret starts enrolment, replacing any earlier unconfirmed
+// secret. It does nothing once two-factor authentication is on.
+func (r *twoFactorRepository) SetPendingSecret(ctx context.Context, userID uuid.UUID, secret string) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&model.User{}).
+		Where("id = ? AND totp_enabled = ?", userID, false).
+		Updates(map[string]interface{}{
+			"totp_secret":         secret,
+			"totp_last_used_step": 0,
+			"updated_at":          time.Now(),
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// Enable turns on the pending secret and stores the first recovery codes.
+// step is the time step of the code that confirmed it.
+func (r *twoFactorRepository) Enable(ctx context.Context, userID uuid.UUID, step int64, codes []*model.RecoveryCode) (bool, error) {
+	enabled := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&model.User{}).
+			Where("id = ? AND totp_enabled = ? AND totp_secret <> ''", userID, false).
+			Updates(map[string]interface{}{
+				"totp_enabled":        true,
+				"totp_enabled_at":     now,
+				"totp_last_used_step": step,
+				"updated_at":          now,
+			})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		enabled = true
+		return replaceRecoveryCodes(tx, userID, codes)
+	})
+	return enabled, err
+}
+
+// Disable turns two-factor authentication off and drops the secret and
+// recovery codes.
+func (r *twoFactorRepository) Disable(ctx context.Context, userID uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&model.User{}).
+			Where("id = ?", userID).
+			Updates(map[string]interface{}{
+				"totp_secret":         "",
+				"totp_enabled":        false,
+				"totp_enabled_at":     nil,
+				"totp_last_used_step": 0,
+				"updated_at":          time.Now(),
+			}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&model.RecoveryCode{}).Error
+	})
+}
+
+// UseStep records that the code of a time step was used. It returns false
+// if that step or a later one was used already, so each code works once.
+func (r *twoFactorRepository) UseStep(ctx context.Context, userID uuid.UUID, step int64) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&model.User{}).
+		Where("id = ? AND totp_last_used_step < ?", userID, step).
+		Update("totp_last_used_step", step)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *twoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codes []*model.RecoveryCode) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return replaceRecoveryCodes(tx, userID, codes)
+	})
+}
+
+func replaceRecoveryCodes(tx *gorm.DB, userID uuid.UUID, codes []*model.RecoveryCode) error {
+	if err := tx.Where("user_id = ?", userID).Delete(&model.RecoveryCode{}).Error; err != nil {
+		return err
+	}
+	if len(codes) == 0 {
+		return nil
+	}
+	return tx.Create(&codes).Error
+}
+
+// UseRecoveryCode marks an unused recovery code as used. It returns false
+// if the user has no such code.
+func (r *twoFactorRepository) UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&model.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *twoFactorRepository) CountUnusedRecoveryCodes(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&model.RecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+// GetPolicy returns the two-factor policy; until an admin sets one, nobody
+// is required to use it.
+func (r *twoFactorRepository) GetPolicy(ctx context.Context) (*model.TwoFactorPolicy, error) {
+	var policy model.TwoFactorPolicy
+	err := r.db.WithContext(ctx).Where("id = ?", twoFactorPolicyID).First(&policy).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &model.TwoFactorPolicy{ID: twoFactorPolicyID}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+func (r *twoFactorRepository) SavePolicy(ctx context.Context, policy *model.TwoFactorPolicy) error {
+	policy.ID = twoFactorPolicyID
+	return r.db.WithContext(ctx).Save(policy).Error
+}
+
+// BuyerCreditLimit reads the credit limit of the user's buyer profile, or
+// 0 if they have none.
+func (r *twoFactorRepository) BuyerCreditLimit(ctx context.Context, userID uuid.UUID) (float64, error) {
+	var limits []float64
+	err := r.db.WithContext(ctx).
+		Table("buyers").
+		Where("user_id = ?", userID).
+		Limit(1).
+		Pluck("credit_limit", &limits).Error
+	if err != nil || len(limits) == 0 {
+		return 0, err
+	}
+	return limits[0], nil
+}
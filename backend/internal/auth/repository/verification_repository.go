@@ -2,6 +2,7 @@ package repository
 
 import (
 	model "agro_konnect/internal/auth/model"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,6 +15,13 @@ type VerificationRepository interface {
 	FindValidCodeByCode(code string, codeType string) (*model.VerificationCode, error) // NEW METHOD
 	MarkAsUsed(id uuid.UUID) error
 	DeleteExpiredCodes() error
+	FindLatestActive(userID uuid.UUID, codeType string) (*model.VerificationCode, error)
+	InvalidateActive(userID uuid.UUID, codeType string) error
+	RecordFailedAttempt(id uuid.UUID, maxAttempts int) (int, error)
+	Consume(id uuid.UUID) (bool, error)
+	CountSentToPhoneSince(phone string, since time.Time) (int64, error)
+	CountCreatedSince(userID uuid.UUID, codeType string, since time.Time) (int64, error)
+	LastSentToPhone(phone string) (*time.Time, error)
 }
 
 type verificationRepository struct {
@@ -50,3 +58,81 @@ func (r *verificationRepository) MarkAsUsed(id uuid.UUID) error {
 func (r *verificationRepository) DeleteExpiredCodes() error {
 	return r.db.Where("expires_at < ?", time.Now()).Delete(&model.VerificationCode{}).Error
 }
+
+// FindLatestActive returns the user's current code of a type, or nil. Only
+// the latest code sent is ever active.
+func (r *verificationRepository) FindLatestActive(userID uuid.UUID, codeType string) (*model.VerificationCode, error) {
+	var verificationCode model.VerificationCode
+	err := r.db.Where("user_id = ? AND type = ? AND used = ? AND expires_at > ?",
+		userID, codeType, false, time.Now()).
+		Order("created_at DESC").
+		First(&verificationCode).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &verificationCode, err
+}
+
+// InvalidateActive retires the user's unused codes of a type, before a new
+// one is sent.
+func (r *verificationRepository) InvalidateActive(userID uuid.UUID, codeType string) error {
+	return r.db.Model(&model.VerificationCode{}).
+		Where("user_id = ? AND type = ? AND used = ?", userID, codeType, false).
+		Update("used", true).Error
+}
+
+// RecordFailedAttempt counts a wrong guess at a code and returns the count
+// so far. The code is used up once maxAttempts is reached.
+func (r *verificationRepository) RecordFailedAttempt(id uuid.UUID, maxAttempts int) (int, error) {
+	var attempts int
+	err := r.db.Raw(`
+		UPDATE verification_codes
+		SET attempts = attempts + 1, used = used OR attempts + 1 >= ?
+		WHERE id = ?
+		RETURNING attempts`, maxAttempts, id).
+		Scan(&attempts).Error
+	return attempts, err
+}
+
+// Consume marks a code used, reporting false if it already was, so a code
+// cannot be spent twice by requests racing each other.
+func (r *verificationRepository) Consume(id uuid.UUID) (bool, error) {
+	result := r.db.Model(&model.VerificationCode{}).
+		Where("id = ? AND used = ?", id, false).
+		Update("used", true)
+	return result.RowsAffected > 0, result.Error
+}
+
+// CountSentToPhoneSince counts the codes texted to a number since a time.
+func (r *verificationRepository) CountSentToPhoneSince(phone string, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.VerificationCode{}).
+		Where("phone = ? AND created_at >= ?", phone, since).
+		Count(&count).Error
+	return count, err
+}
+
+// CountCreatedSince counts the user's codes of a type created since a time.
+func (r *verificationRepository) CountCreatedSince(userID uuid.UUID, codeType string, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.VerificationCode{}).
+		Where("user_id = ? AND type = ? AND created_at >= ?", userID, codeType, since).
+		Count(&count).Error
+	return count, err
+}
+
+// LastSentToPhone returns when a code was last texted to a number, or nil.
+func (r *verificationRepository) LastSentToPhone(phone string) (*time.Time, error) {
+	var verificationCode model.VerificationCode
+	err := r.db.Select("created_at").
+		Where("phone = ?", phone).
+		Order("created_at DESC").
+		First(&verificationCode).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &verificationCode.CreatedAt, nil
+}
@@ -1,13 +1,17 @@
 package routes
 
 import (
+	auditservice "agro_konnect/internal/audit/service"
 	"agro_konnect/internal/auth/handler"
 	"agro_konnect/internal/auth/middleware"
 	"agro_konnect/internal/auth/model"
 	"agro_konnect/internal/auth/repository"
 	"agro_konnect/internal/auth/service"
 	"agro_konnect/internal/auth/utils"
+	emailservice "agro_konnect/internal/email/service"
+	"agro_konnect/pkg/ratelimit"
 	"fmt"
+	"log"
 	"net/http"
 	"os"
 	"time"
@@ -17,31 +21,54 @@ import (
 	"gorm.io/gorm"
 )
 
-func UserRoutes(r *gin.Engine, db *gorm.DB) {
+func UserRoutes(r *gin.Engine, db *gorm.DB, auditRecorder auditservice.Recorder, mailer emailservice.Mailer) {
 	// Initialize repositories
 	userRepo := repository.NewUserRepository(db)
 	verificationRepo := repository.NewVerificationRepository(db)
+	sessionRepo := repository.NewSessionRepository(db)
+	impersonationRepo := repository.NewImpersonationRepository(db)
+	twoFactorRepo := repository.NewTwoFactorRepository(db)
+	attemptRepo := repository.NewAttemptRepository(db)
 
 	// Initialize JWT manager
 	jwtSecret := os.Getenv("JWT_SECRET")
 	if jwtSecret == "" {
 		jwtSecret = "your-default-jwt-secret-key-change-in-production"
 	}
+	if os.Getenv("TOTP_ENCRYPTION_KEY") == "" {
+		log.Println("⚠️ TOTP_ENCRYPTION_KEY is not set; two-factor secrets are sealed with a key derived from JWT_SECRET")
+	}
 	tokenDuration := 24 * time.Hour // Token valid for 24 hours
 	jwtManager := utils.NewJWTManager(jwtSecret, tokenDuration)
 
-	// Initialize email service
-	emailService := service.NewMockEmailService()
+	// Codes are texted through the gateway set by SMS_PROVIDER; without one
+	// they are dropped
+	smsConfig := service.LoadSMSConfig()
+	smsProvider, err := service.NewSMSProvider(smsConfig)
+	if err != nil {
+		log.Fatalf("❌ Failed to set up SMS: %v", err)
+	}
+	if smsConfig.Provider == service.SMSProviderNone {
+		log.Println("⚠️ SMS_PROVIDER is not set; phone codes will not be sent")
+	}
+
+	// Failed sign-ins are counted per account and per IP address
+	throttle := service.NewThrottle(attemptRepo, service.LoadThrottleConfig(), auditRecorder)
 
 	// Initialize services
-	authService := service.NewAuthService(userRepo, verificationRepo, jwtManager, emailService)
+	authService := service.NewAuthService(userRepo, verificationRepo, sessionRepo, impersonationRepo, twoFactorRepo, jwtManager, mailer, smsProvider, throttle, auditRecorder)
 
 	// Initialize handlers
 	authHandler := handler.NewAuthHandler(authService)
-	adminHandler := handler.NewAdminHandler(authService, userRepo, jwtManager)
+	adminHandler := handler.NewAdminHandler(authService, userRepo, throttle)
 
 	// Initialize middleware
-	authMiddleware := middleware.NewAuthMiddleware(jwtManager)
+	authMiddleware := middleware.NewAuthMiddleware(jwtManager, sessionRepo, impersonationRepo)
+	// Counted per instance; the failed sign-in throttle above is the limit
+	// that holds across instances
+	authLimiter := ratelimit.NewMemoryLimiter(60, time.Minute)
+	codeLimiter := ratelimit.NewMemoryLimiter(10, time.Minute)
+	codeLimit := ratelimit.Middleware(codeLimiter, "auth-codes", ratelimit.ByIP)
 
 	// Public routes
 	api := r.Group("/api")
@@ -51,15 +78,30 @@ func UserRoutes(r *gin.Engine, db *gorm.DB) {
 			c.JSON(200, gin.H{"status": "ok", "service": "auth"})
 		})
 
-		// Auth routes
+		// Auth routes; each IP address gets a limited number of requests,
+		// fewer for those that send or check codes
 		auth := api.Group("/auth")
+		auth.Use(ratelimit.Middleware(authLimiter, "auth", ratelimit.ByIP))
 		{
 			auth.POST("/register", authHandler.Register)
 			auth.POST("/login", authHandler.Login)
-			auth.POST("/verify-email", authHandler.VerifyEmail)
-			auth.POST("/forgot-password", authHandler.ForgotPassword)
-			auth.POST("/reset-password", authHandler.ResetPassword)
+			auth.POST("/verify-email", codeLimit, authHandler.VerifyEmail)
+			auth.POST("/forgot-password", codeLimit, authHandler.ForgotPassword)
+			auth.POST("/reset-password", codeLimit, authHandler.ResetPassword)
 			auth.POST("/refresh-token", authHandler.RefreshToken)
+
+			// Phone number verification and sign-in with texted codes
+			auth.POST("/phone/send-verification", codeLimit, authHandler.SendPhoneVerification)
+			auth.POST("/phone/verify", codeLimit, authHandler.VerifyPhone)
+			auth.POST("/phone/login/request", codeLimit, authHandler.RequestPhoneLogin)
+			auth.POST("/phone/login", codeLimit, authHandler.LoginWithPhone)
+			auth.POST("/phone/forgot-password", codeLimit, authHandler.ForgotPasswordByPhone)
+			auth.POST("/phone/reset-password", codeLimit, authHandler.ResetPasswordByPhone)
+
+			// Second step of signing in, with the challenge token from login
+			auth.POST("/2fa/verify", codeLimit, authHandler.VerifyTwoFactorLogin)
+			auth.POST("/2fa/enroll/setup", codeLimit, authHandler.BeginTwoFactorSetupWithChallenge)
+			auth.POST("/2fa/enroll/enable", codeLimit, authHandler.EnableTwoFactorWithChallenge)
 		}
 	}
 
@@ -155,7 +197,21 @@ func UserRoutes(r *gin.Engine, db *gorm.DB) {
 			})
 		})
 
-		protected.PUT("/change-password", authHandler.ChangePassword)
+		protected.PUT("/change-password", authMiddleware.DenyImpersonation(), authHandler.ChangePassword)
+
+		// Sessions; an admin impersonating a user can only end their own
+		protected.POST("/auth/logout", authHandler.Logout)
+		protected.POST("/auth/logout-all", authMiddleware.DenyImpersonation(), authHandler.LogoutAll)
+		protected.GET("/auth/sessions", authHandler.GetSessions)
+		protected.DELETE("/auth/sessions/:id", authMiddleware.DenyImpersonation(), authHandler.RevokeSession)
+		protected.GET("/auth/support-access", authHandler.GetSupportAccess)
+
+		// Two-factor authentication; only the user themselves can change it
+		protected.GET("/auth/2fa", authHandler.GetTwoFactorStatus)
+		protected.POST("/auth/2fa/setup", authMiddleware.DenyImpersonation(), codeLimit, authHandler.BeginTwoFactorSetup)
+		protected.POST("/auth/2fa/enable", authMiddleware.DenyImpersonation(), codeLimit, authHandler.EnableTwoFactor)
+		protected.POST("/auth/2fa/disable", authMiddleware.DenyImpersonation(), codeLimit, authHandler.DisableTwoFactor)
+		protected.POST("/auth/2fa/recovery-codes", authMiddleware.DenyImpersonation(), codeLimit, authHandler.RegenerateRecoveryCodes)
 
 		// Admin routes (require admin role)
 		admin := protected.Group("/admin")
@@ -169,6 +225,14 @@ func UserRoutes(r *gin.Engine, db *gorm.DB) {
 			admin.POST("/users/:id/deactivate", adminHandler.DeactivateUser)
 			admin.GET("/stats/users", adminHandler.GetUserStats)
 			admin.POST("/users/:id/impersonate", adminHandler.ImpersonateUser)
+			admin.GET("/impersonations", adminHandler.GetImpersonations)
+			admin.GET("/impersonations/:id/actions", adminHandler.GetImpersonationActions)
+			admin.POST("/users/:id/2fa/reset", adminHandler.ResetUserTwoFactor)
+			admin.GET("/2fa-policy", adminHandler.GetTwoFactorPolicy)
+			admin.PUT("/2fa-policy", adminHandler.UpdateTwoFactorPolicy)
+			admin.GET("/lockouts", adminHandler.GetLockouts)
+			admin.DELETE("/lockouts/:id", adminHandler.ClearLockout)
+			admin.POST("/users/:id/unlock", adminHandler.UnlockUser)
 		}
 
 		// Role-based routes
@@ -1,15 +1,20 @@
 package service
 
 import (
+	auditservice "agro_konnect/internal/audit/service"
 	"agro_konnect/internal/auth/dto"
 	"agro_konnect/internal/auth/model"
 	"agro_konnect/internal/auth/repository"
 	"agro_konnect/internal/auth/utils"
+	emailservice "agro_konnect/internal/email/service"
 	"context"
 	"crypto/rand"
+	"crypto/subtle"
 	"errors"
 	"fmt"
+	"log"
 	"math/big"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -24,16 +29,63 @@ var (
 	ErrTokenExpired        = errors.New("token expired")
 	ErrUnauthorized        = errors.New("unauthorized")
 	ErrVerificationExpired = errors.New("verification code expired")
+	ErrSessionNotFound     = errors.New("session not found")
+	ErrSessionRevoked      = errors.New("session has ended, please log in again")
+	ErrTokenReused         = errors.New("refresh token was already used; the session has been ended")
+
+	ErrEmailTaken            = errors.New("email already taken")
+	ErrPhoneTaken            = errors.New("phone number already taken")
+	ErrCannotImpersonate     = errors.New("this user cannot be impersonated")
+	ErrImpersonationNotFound = errors.New("impersonation not found")
+	ErrResetEmailRequired    = errors.New("email is required with a reset code")
+	ErrResetCodeRequired     = errors.New("the 6-digit code from the reset email is required; signed-in users change their password with PUT /api/change-password")
+)
+
+// MaxEmailCodesPerHour limits the password reset emails one account is sent.
+const MaxEmailCodesPerHour = 5
+
+const (
+	DefaultImpersonationDuration = 30 * time.Minute
+	MaxImpersonationDuration     = 2 * time.Hour
 )
 
 type AuthService interface {
 	Register(req *dto.RegisterRequest) (*model.User, error)
-	Login(req *dto.LoginRequest) (*dto.AuthResponse, error)
+	Login(ctx context.Context, req *dto.LoginRequest, client dto.ClientInfo) (*dto.LoginResponse, error)
+	VerifyTwoFactorLogin(ctx context.Context, req *dto.TwoFactorLoginRequest, client dto.ClientInfo) (*dto.AuthResponse, error)
+	BeginTwoFactorSetupWithChallenge(ctx context.Context, req *dto.TwoFactorChallengeRequest) (*dto.TwoFactorSetupResponse, error)
+	EnableTwoFactorWithChallenge(ctx context.Context, req *dto.TwoFactorLoginRequest, client dto.ClientInfo) (*dto.TwoFactorEnrolledResponse, error)
 	VerifyEmail(req *dto.VerifyEmailRequest) error
+	SendPhoneVerification(ctx context.Context, req *dto.PhoneRequest) error
+	VerifyPhone(ctx context.Context, req *dto.PhoneCodeRequest) error
+	RequestPhoneLogin(ctx context.Context, req *dto.PhoneRequest) error
+	LoginWithPhone(ctx context.Context, req *dto.PhoneCodeRequest, client dto.ClientInfo) (*dto.LoginResponse, error)
+	ForgotPasswordByPhone(ctx context.Context, req *dto.PhoneRequest) error
+	ResetPasswordByPhone(ctx context.Context, req *dto.PhoneResetPasswordRequest) error
 	ForgotPassword(req *dto.ForgotPasswordRequest) error
 	ResetPassword(req *dto.ResetPasswordRequest) error
 	ChangePassword(userID uuid.UUID, req *dto.ChangePasswordRequest) error
-	RefreshToken(refreshToken string) (*dto.AuthResponse, error)
+	RefreshToken(ctx context.Context, refreshToken string, client dto.ClientInfo) (*dto.AuthResponse, error)
+	StartSession(ctx context.Context, user *model.User, client dto.ClientInfo) (*dto.AuthResponse, error)
+	GetTwoFactorStatus(ctx context.Context, userID uuid.UUID) (*dto.TwoFactorStatusResponse, error)
+	BeginTwoFactorSetup(ctx context.Context, userID uuid.UUID) (*dto.TwoFactorSetupResponse, error)
+	EnableTwoFactor(ctx context.Context, userID uuid.UUID, req *dto.TwoFactorCodeRequest, client dto.ClientInfo) (*dto.RecoveryCodesResponse, error)
+	DisableTwoFactor(ctx context.Context, userID uuid.UUID, req *dto.DisableTwoFactorRequest, client dto.ClientInfo) error
+	RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, req *dto.TwoFactorCodeRequest, client dto.ClientInfo) (*dto.RecoveryCodesResponse, error)
+	Logout(ctx context.Context, userID, sessionID uuid.UUID) error
+	LogoutAll(ctx context.Context, userID uuid.UUID) (int64, error)
+	GetSessions(ctx context.Context, userID, currentSessionID uuid.UUID) ([]*dto.SessionResponse, error)
+	RevokeSession(ctx context.Context, userID, sessionID uuid.UUID) error
+	RevokeUserSessions(ctx context.Context, userID uuid.UUID, reason string) error
+	UpdateUser(ctx context.Context, userID uuid.UUID, req *dto.AdminUpdateUserRequest) error
+	SetUserActive(ctx context.Context, userID uuid.UUID, active bool) error
+	DeleteUser(ctx context.Context, userID uuid.UUID) error
+	ResetTwoFactor(ctx context.Context, userID uuid.UUID) error
+	GetTwoFactorPolicy(ctx context.Context) (*model.TwoFactorPolicy, error)
+	UpdateTwoFactorPolicy(ctx context.Context, adminID uuid.UUID, req *dto.TwoFactorPolicyRequest) (*model.TwoFactorPolicy, error)
+	Impersonate(ctx context.Context, adminID, userID uuid.UUID, req *dto.ImpersonateRequest, client dto.ClientInfo) (*dto.ImpersonationTokenResponse, error)
+	GetImpersonations(ctx context.Context, filter repository.ImpersonationFilter) ([]*dto.ImpersonationResponse, int64, error)
+	GetImpersonationActions(ctx context.Context, impersonationID uuid.UUID) ([]*model.ImpersonationAction, error)
 	GetUserByID(ctx context.Context, userID uuid.UUID) (*model.User, error) // ✅ FIXED: Added this method
 	GetUserProfile(userID uuid.UUID) (*dto.UserResponse, error)
 	GenerateVerificationCode(userID uuid.UUID, codeType string) (*model.VerificationCode, error)
@@ -42,21 +94,39 @@ type AuthService interface {
 type authService struct {
 	userRepo         repository.UserRepository
 	verificationRepo repository.VerificationRepository
+	sessionRepo      repository.SessionRepository
+	impersonations   repository.ImpersonationRepository
+	twoFactor        repository.TwoFactorRepository
 	jwtManager       *utils.JWTManager
-	emailService     EmailService
+	mailer           emailservice.Mailer
+	smsProvider      SMSProvider
+	throttle         Throttle
+	audit            auditservice.Recorder
 }
 
 func NewAuthService(
 	userRepo repository.UserRepository,
 	verificationRepo repository.VerificationRepository,
+	sessionRepo repository.SessionRepository,
+	impersonations repository.ImpersonationRepository,
+	twoFactor repository.TwoFactorRepository,
 	jwtManager *utils.JWTManager,
-	emailService EmailService,
+	mailer emailservice.Mailer,
+	smsProvider SMSProvider,
+	throttle Throttle,
+	audit auditservice.Recorder,
 ) AuthService {
 	return &authService{
 		userRepo:         userRepo,
 		verificationRepo: verificationRepo,
+		sessionRepo:      sessionRepo,
+		impersonations:   impersonations,
+		twoFactor:        twoFactor,
 		jwtManager:       jwtManager,
-		emailService:     emailService,
+		mailer:           mailer,
+		smsProvider:      smsProvider,
+		throttle:         throttle,
+		audit:            audit,
 	}
 }
 
@@ -82,7 +152,11 @@ func (s *authService) Register(req *dto.RegisterRequest) (*model.User, error) {
 	}
 
 	// Check if phone already exists
-	existingUser, _ = s.userRepo.FindByPhone(req.Phone)
+	phone, err := utils.NormalizePhone(req.Phone)
+	if err != nil {
+		return nil, ErrInvalidPhone
+	}
+	existingUser, _ = s.userRepo.FindByPhone(phone)
 	if existingUser != nil {
 		return nil, errors.New("phone number already registered")
 	}
@@ -91,15 +165,19 @@ func (s *authService) Register(req *dto.RegisterRequest) (*model.User, error) {
 	if err != nil {
 		return nil, err
 	}
+	if req.Locale == "" {
+		req.Locale = emailservice.DefaultLocale
+	}
 
 	user := &model.User{
 		ID:           uuid.New(),
 		Email:        req.Email,
-		Phone:        req.Phone,
+		Phone:        phone,
 		PasswordHash: string(hashedPassword),
 		Role:         req.Role,
 		IsVerified:   false,
 		IsActive:     true,
+		Locale:       req.Locale,
 		CreatedAt:    time.Now(),
 		UpdatedAt:    time.Now(),
 	}
@@ -109,19 +187,32 @@ func (s *authService) Register(req *dto.RegisterRequest) (*model.User, error) {
 	}
 
 	// Generate and send verification code
-	verificationCode, err := s.GenerateVerificationCode(user.ID, "email_verification")
+	verificationCode, err := s.GenerateVerificationCode(user.ID, model.CodeTypeEmailVerification)
 	if err != nil {
 		return user, nil // Return user even if email fails
 	}
 
-	go s.emailService.SendVerificationEmail(user.Email, verificationCode.Code)
+	if err := s.mailer.SendVerificationCode(context.Background(), user.ID, verificationCode.Code, verificationCode.ExpiresAt); err != nil {
+		log.Printf("failed to send verification email to user %s: %v", user.ID, err)
+	}
+	if err := s.sendPhoneCode(context.Background(), user, model.CodeTypePhoneVerification); err != nil {
+		log.Printf("failed to send verification SMS to user %s: %v", user.ID, err)
+	}
 
 	return user, nil
 }
 
-func (s *authService) Login(req *dto.LoginRequest) (*dto.AuthResponse, error) {
+func (s *authService) Login(ctx context.Context, req *dto.LoginRequest, client dto.ClientInfo) (*dto.LoginResponse, error) {
 	user, err := s.userRepo.FindByEmail(req.Email)
 	if err != nil {
+		user = nil
+	}
+	accountKey, ipKey := AccountKey(user, req.Email), IPKey(client.IPAddress)
+	if err := s.throttle.Check(ctx, accountKey, ipKey); err != nil {
+		return nil, err
+	}
+	if user == nil {
+		s.throttle.Fail(ctx, accountKey, ipKey)
 		return nil, ErrInvalidCredentials
 	}
 
@@ -130,32 +221,48 @@ func (s *authService) Login(req *dto.LoginRequest) (*dto.AuthResponse, error) {
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
+		s.throttle.Fail(ctx, accountKey, ipKey)
 		return nil, ErrInvalidCredentials
 	}
 
-	if err := s.userRepo.UpdateLastLogin(user.ID); err != nil {
-		// Log error but don't fail login
-		fmt.Printf("Failed to update last login: %v\n", err)
+	return s.signIn(ctx, user, client)
+}
+
+// StartSession signs a user in on a new device.
+func (s *authService) StartSession(ctx context.Context, user *model.User, client dto.ClientInfo) (*dto.AuthResponse, error) {
+	now := time.Now()
+	session := &model.Session{
+		ID:             uuid.New(),
+		UserID:         user.ID,
+		RefreshTokenID: uuid.NewString(),
+		UserAgent:      client.UserAgent,
+		IPAddress:      client.IPAddress,
+		CreatedAt:      now,
+		LastUsedAt:     now,
 	}
 
-	accessToken, expiresAt, err := s.jwtManager.GenerateAccessToken(user)
+	refreshToken, refreshExpiresAt, err := s.jwtManager.GenerateRefreshToken(user.ID, session.ID, session.RefreshTokenID)
 	if err != nil {
 		return nil, err
 	}
+	session.ExpiresAt = refreshExpiresAt
+
+	if err := s.sessionRepo.Create(ctx, session); err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
 
-	refreshToken, err := s.jwtManager.GenerateRefreshToken(user.ID)
+	accessToken, expiresAt, err := s.jwtManager.GenerateAccessToken(user, session.ID)
 	if err != nil {
 		return nil, err
 	}
 
-	response := &dto.AuthResponse{
+	return &dto.AuthResponse{
 		User:         *user,
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
 		ExpiresAt:    expiresAt,
-	}
-
-	return response, nil
+		SessionID:    session.ID,
+	}, nil
 }
 
 func (s *authService) VerifyEmail(req *dto.VerifyEmailRequest) error {
@@ -168,23 +275,14 @@ func (s *authService) VerifyEmail(req *dto.VerifyEmailRequest) error {
 		return errors.New("email already verified")
 	}
 
-	verificationCode, err := s.verificationRepo.FindValidCode(user.ID, req.Code, "email_verification")
-	if err != nil {
-		return ErrInvalidToken
-	}
-
-	if time.Now().After(verificationCode.ExpiresAt) {
-		return ErrVerificationExpired
+	if err := s.checkCode(user, model.CodeTypeEmailVerification, req.Code); err != nil {
+		return err
 	}
 
 	user.IsVerified = true
 	user.UpdatedAt = time.Now()
 
-	if err := s.userRepo.Update(user); err != nil {
-		return err
-	}
-
-	return s.verificationRepo.MarkAsUsed(verificationCode.ID)
+	return s.userRepo.Update(user)
 }
 
 func (s *authService) ForgotPassword(req *dto.ForgotPasswordRequest) error {
@@ -193,53 +291,66 @@ func (s *authService) ForgotPassword(req *dto.ForgotPasswordRequest) error {
 		return nil // Don't reveal if email exists or not
 	}
 
-	verificationCode, err := s.GenerateVerificationCode(user.ID, "password_reset")
+	sent, err := s.verificationRepo.CountCreatedSince(user.ID, model.CodeTypePasswordReset, time.Now().Add(-time.Hour))
+	if err != nil {
+		return fmt.Errorf("failed to check codes sent: %w", err)
+	}
+	if sent >= MaxEmailCodesPerHour {
+		log.Printf("not sending another password reset code to user %s this hour", user.ID)
+		return nil
+	}
+
+	verificationCode, err := s.GenerateVerificationCode(user.ID, model.CodeTypePasswordReset)
 	if err != nil {
 		return err
 	}
 
-	go s.emailService.SendPasswordResetEmail(user.Email, verificationCode.Code)
+	if err := s.mailer.SendPasswordReset(context.Background(), user.ID, verificationCode.Code, verificationCode.ExpiresAt); err != nil {
+		log.Printf("failed to send password reset email to user %s: %v", user.ID, err)
+	}
 
 	return nil
 }
 
+// ResetPassword sets a new password with the 6-digit code emailed by
+// ForgotPassword. Tokens issued for anything else are not accepted; a
+// signed-in user changes their password with ChangePassword, which asks for
+// the current one.
 func (s *authService) ResetPassword(req *dto.ResetPasswordRequest) error {
-	// Check if it's a 6-digit code (verification code)
-	if len(req.Token) == 6 {
-		return s.resetPasswordWithCode(req.Token, req.NewPassword)
-	}
-
-	// Otherwise, treat it as JWT token
-	claims, err := s.jwtManager.ValidateToken(req.Token)
-	if err != nil {
-		return ErrInvalidToken
+	if len(req.Token) != 6 {
+		return ErrResetCodeRequired
 	}
+	return s.resetPasswordWithCode(req.Email, req.Token, req.NewPassword)
+}
 
-	userID, err := uuid.Parse(claims.Subject)
-	if err != nil {
-		return ErrInvalidToken
+// endSessionsAfterReset signs a user out everywhere once their password is
+// reset, in case the reset was because someone else had it.
+func (s *authService) endSessionsAfterReset(userID uuid.UUID) {
+	if _, err := s.sessionRepo.RevokeAllForUser(context.Background(), userID, model.RevokeReasonPasswordReset); err != nil {
+		log.Printf("failed to end sessions of %s after password reset: %v", userID, err)
 	}
-
-	return s.updateUserPassword(userID, req.NewPassword)
 }
 
-func (s *authService) resetPasswordWithCode(code, newPassword string) error {
-	verificationCode, err := s.verificationRepo.FindValidCodeByCode(code, "password_reset")
+// resetPasswordWithCode needs the email the code was sent to, so that
+// wrong guesses count against that one code.
+func (s *authService) resetPasswordWithCode(email, code, newPassword string) error {
+	if email == "" {
+		return ErrResetEmailRequired
+	}
+	user, err := s.userRepo.FindByEmail(email)
 	if err != nil {
 		return ErrInvalidToken
 	}
 
-	if time.Now().After(verificationCode.ExpiresAt) {
-		return ErrVerificationExpired
+	if err := s.checkCode(user, model.CodeTypePasswordReset, code); err != nil {
+		return err
 	}
 
-	// Update password
-	if err := s.updateUserPassword(verificationCode.UserID, newPassword); err != nil {
+	if err := s.updateUserPassword(user.ID, newPassword); err != nil {
 		return err
 	}
-
-	// Mark code as used
-	return s.verificationRepo.MarkAsUsed(verificationCode.ID)
+	s.endSessionsAfterReset(user.ID)
+	return nil
 }
 
 func (s *authService) ChangePassword(userID uuid.UUID, req *dto.ChangePasswordRequest) error {
@@ -272,41 +383,405 @@ func (s *authService) updateUserPassword(userID uuid.UUID, newPassword string) e
 	return s.userRepo.Update(user)
 }
 
-func (s *authService) RefreshToken(refreshToken string) (*dto.AuthResponse, error) {
-	claims, err := s.jwtManager.ValidateToken(refreshToken)
+// RefreshToken swaps a session's refresh token for a new one. Each refresh
+// token works once; if an older one turns up again, it was copied, and the
+// session is ended for whoever holds it.
+func (s *authService) RefreshToken(ctx context.Context, refreshToken string, client dto.ClientInfo) (*dto.AuthResponse, error) {
+	claims, err := s.jwtManager.ValidateTokenOfType(refreshToken, utils.TokenTypeRefresh)
 	if err != nil {
+		if errors.Is(err, utils.ErrTokenExpired) {
+			return nil, ErrTokenExpired
+		}
 		return nil, ErrInvalidToken
 	}
 
-	userID, err := uuid.Parse(claims.Subject)
+	session, err := s.sessionRepo.FindByID(ctx, claims.SessionID)
 	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	if session == nil || session.UserID != claims.UserID {
 		return nil, ErrInvalidToken
 	}
+	if session.RevokedAt != nil || time.Now().After(session.ExpiresAt) {
+		return nil, ErrSessionRevoked
+	}
+	if session.RefreshTokenID != claims.ID {
+		s.revokeForReuse(ctx, session.ID)
+		return nil, ErrTokenReused
+	}
 
-	user, err := s.userRepo.FindByID(userID)
+	user, err := s.userRepo.FindByID(claims.UserID)
 	if err != nil {
 		return nil, ErrUserNotFound
 	}
+	if !user.IsActive {
+		if _, err := s.sessionRepo.Revoke(ctx, session.ID, model.RevokeReasonDeactivated); err != nil {
+			log.Printf("failed to end session %s: %v", session.ID, err)
+		}
+		return nil, ErrSessionRevoked
+	}
 
-	accessToken, expiresAt, err := s.jwtManager.GenerateAccessToken(user)
+	newTokenID := uuid.NewString()
+	newRefreshToken, refreshExpiresAt, err := s.jwtManager.GenerateRefreshToken(user.ID, session.ID, newTokenID)
 	if err != nil {
 		return nil, err
 	}
 
-	// Generate new refresh token
-	newRefreshToken, err := s.jwtManager.GenerateRefreshToken(user.ID)
+	rotated, err := s.sessionRepo.Rotate(ctx, session.ID, claims.ID, newTokenID, refreshExpiresAt, client.IPAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
+	if !rotated {
+		// Another refresh with the same token got there first
+		s.revokeForReuse(ctx, session.ID)
+		return nil, ErrTokenReused
+	}
+
+	accessToken, expiresAt, err := s.jwtManager.GenerateAccessToken(user, session.ID)
 	if err != nil {
 		return nil, err
 	}
 
-	response := &dto.AuthResponse{
+	return &dto.AuthResponse{
 		User:         *user,
 		AccessToken:  accessToken,
 		RefreshToken: newRefreshToken,
 		ExpiresAt:    expiresAt,
+		SessionID:    session.ID,
+	}, nil
+}
+
+func (s *authService) revokeForReuse(ctx context.Context, sessionID uuid.UUID) {
+	log.Printf("refresh token reused for session %s; ending it", sessionID)
+	if _, err := s.sessionRepo.Revoke(ctx, sessionID, model.RevokeReasonTokenReuse); err != nil {
+		log.Printf("failed to end session %s: %v", sessionID, err)
+	}
+}
+
+// Logout ends the session the request was made with.
+func (s *authService) Logout(ctx context.Context, userID, sessionID uuid.UUID) error {
+	return s.RevokeSession(ctx, userID, sessionID)
+}
+
+// LogoutAll ends every session of the user, including the current one.
+func (s *authService) LogoutAll(ctx context.Context, userID uuid.UUID) (int64, error) {
+	count, err := s.sessionRepo.RevokeAllForUser(ctx, userID, model.RevokeReasonLogout)
+	if err != nil {
+		return 0, fmt.Errorf("failed to end sessions: %w", err)
+	}
+	return count, nil
+}
+
+// GetSessions lists the devices the user is signed in on.
+func (s *authService) GetSessions(ctx context.Context, userID, currentSessionID uuid.UUID) ([]*dto.SessionResponse, error) {
+	sessions, err := s.sessionRepo.FindActiveByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sessions: %w", err)
+	}
+
+	responses := make([]*dto.SessionResponse, len(sessions))
+	for i, session := range sessions {
+		responses[i] = &dto.SessionResponse{
+			ID:         session.ID,
+			UserAgent:  session.UserAgent,
+			IPAddress:  session.IPAddress,
+			CreatedAt:  session.CreatedAt,
+			LastUsedAt: session.LastUsedAt,
+			ExpiresAt:  session.ExpiresAt,
+			Current:    session.ID == currentSessionID,
+		}
+	}
+	return responses, nil
+}
+
+// RevokeSession ends one of the user's own sessions.
+func (s *authService) RevokeSession(ctx context.Context, userID, sessionID uuid.UUID) error {
+	session, err := s.sessionRepo.FindByID(ctx, sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to get session: %w", err)
+	}
+	if session == nil || session.UserID != userID || session.RevokedAt != nil {
+		return ErrSessionNotFound
+	}
+
+	if _, err := s.sessionRepo.Revoke(ctx, sessionID, model.RevokeReasonLogout); err != nil {
+		return fmt.Errorf("failed to end session: %w", err)
 	}
+	return nil
+}
 
-	return response, nil
+// RevokeUserSessions ends every session of a user at once, e.g. when an
+// admin deactivates them. Their access tokens stop working immediately.
+func (s *authService) RevokeUserSessions(ctx context.Context, userID uuid.UUID, reason string) error {
+	if _, err := s.sessionRepo.RevokeAllForUser(ctx, userID, reason); err != nil {
+		return fmt.Errorf("failed to end sessions: %w", err)
+	}
+	return nil
+}
+
+// UpdateUser applies an admin's changes to a user's account. Deactivating
+// the user signs them out everywhere.
+func (s *authService) UpdateUser(ctx context.Context, userID uuid.UUID, req *dto.AdminUpdateUserRequest) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return ErrUserNotFound
+	}
+	before := auditedUserFields(user)
+
+	if req.Email != nil {
+		existingUser, _ := s.userRepo.FindByEmail(*req.Email)
+		if existingUser != nil && existingUser.ID != user.ID {
+			return ErrEmailTaken
+		}
+		user.Email = *req.Email
+	}
+	if req.Phone != nil {
+		phone, err := utils.NormalizePhone(*req.Phone)
+		if err != nil {
+			return ErrInvalidPhone
+		}
+		existingUser, _ := s.userRepo.FindByPhone(phone)
+		if existingUser != nil && existingUser.ID != user.ID {
+			return ErrPhoneTaken
+		}
+		if phone != user.Phone {
+			user.Phone = phone
+			user.PhoneVerified = false
+		}
+	}
+	if req.Role != nil {
+		user.Role = *req.Role
+	}
+	if req.IsActive != nil {
+		user.IsActive = *req.IsActive
+	}
+	if req.Locale != nil {
+		user.Locale = *req.Locale
+	}
+	user.UpdatedAt = time.Now()
+
+	if err := s.userRepo.Update(user); err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+
+	s.audit.Record(ctx, auditservice.Event{
+		Action:     "user.update",
+		EntityType: "user",
+		EntityID:   user.ID.String(),
+		Before:     before,
+		After:      auditedUserFields(user),
+	})
+
+	if before["is_active"] == true && !user.IsActive {
+		return s.RevokeUserSessions(ctx, user.ID, model.RevokeReasonDeactivated)
+	}
+	return nil
+}
+
+// SetUserActive activates or deactivates a user. Deactivated users are
+// signed out everywhere and their access tokens stop working at once.
+func (s *authService) SetUserActive(ctx context.Context, userID uuid.UUID, active bool) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return ErrUserNotFound
+	}
+
+	wasActive := user.IsActive
+	user.IsActive = active
+	user.UpdatedAt = time.Now()
+
+	if err := s.userRepo.Update(user); err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+
+	action := "user.activate"
+	if !active {
+		action = "user.deactivate"
+	}
+	s.audit.Record(ctx, auditservice.Event{
+		Action:     action,
+		EntityType: "user",
+		EntityID:   user.ID.String(),
+		Before:     map[string]interface{}{"is_active": wasActive},
+		After:      map[string]interface{}{"is_active": active},
+	})
+
+	if !active {
+		return s.RevokeUserSessions(ctx, user.ID, model.RevokeReasonDeactivated)
+	}
+	return nil
+}
+
+func (s *authService) DeleteUser(ctx context.Context, userID uuid.UUID) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return ErrUserNotFound
+	}
+
+	if err := s.userRepo.Delete(userID); err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+
+	s.audit.Record(ctx, auditservice.Event{
+		Action:     "user.delete",
+		EntityType: "user",
+		EntityID:   user.ID.String(),
+		Before:     auditedUserFields(user),
+	})
+
+	return s.RevokeUserSessions(ctx, userID, model.RevokeReasonDeleted)
+}
+
+func auditedUserFields(user *model.User) map[string]interface{} {
+	return map[string]interface{}{
+		"email":     user.Email,
+		"phone":     user.Phone,
+		"role":      user.Role,
+		"is_active": user.IsActive,
+		"locale":    user.Locale,
+	}
+}
+
+// Impersonate lets an admin act as a user for a short while, e.g. to see
+// what they see. The token names the admin, has no refresh token, and the
+// user can see and end it from their account.
+func (s *authService) Impersonate(ctx context.Context, adminID, userID uuid.UUID, req *dto.ImpersonateRequest, client dto.ClientInfo) (*dto.ImpersonationTokenResponse, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	// Admins can't borrow each other's accounts
+	if user.ID == adminID || user.Role == model.RoleAdmin || !user.IsActive {
+		return nil, ErrCannotImpersonate
+	}
+
+	duration := DefaultImpersonationDuration
+	if req.DurationMinutes > 0 {
+		duration = min(time.Duration(req.DurationMinutes)*time.Minute, MaxImpersonationDuration)
+	}
+
+	now := time.Now()
+	impersonation := &model.Impersonation{
+		ID:             uuid.New(),
+		ImpersonatorID: adminID,
+		UserID:         user.ID,
+		Reason:         strings.TrimSpace(req.Reason),
+		ReadOnly:       req.ReadOnly,
+		IPAddress:      client.IPAddress,
+		StartedAt:      now,
+		ExpiresAt:      now.Add(duration),
+	}
+	session := &model.Session{
+		ID:             impersonation.ID,
+		UserID:         user.ID,
+		ImpersonatorID: &adminID,
+		UserAgent:      client.UserAgent,
+		IPAddress:      client.IPAddress,
+		CreatedAt:      now,
+		LastUsedAt:     now,
+		ExpiresAt:      impersonation.ExpiresAt,
+	}
+
+	// The record goes in first so no token exists without one
+	if err := s.impersonations.Create(ctx, impersonation); err != nil {
+		return nil, fmt.Errorf("failed to record impersonation: %w", err)
+	}
+	if err := s.sessionRepo.Create(ctx, session); err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	token, err := s.jwtManager.GenerateImpersonationToken(user, session.ID, adminID, req.ReadOnly, impersonation.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	s.audit.Record(ctx, auditservice.Event{
+		Action:     "user.impersonate",
+		EntityType: "user",
+		EntityID:   user.ID.String(),
+		After: map[string]interface{}{
+			"impersonation_id": impersonation.ID,
+			"reason":           impersonation.Reason,
+			"read_only":        impersonation.ReadOnly,
+			"expires_at":       impersonation.ExpiresAt,
+		},
+	})
+
+	return &dto.ImpersonationTokenResponse{
+		ImpersonationID: impersonation.ID,
+		AccessToken:     token,
+		ExpiresAt:       impersonation.ExpiresAt,
+		ReadOnly:        impersonation.ReadOnly,
+		User:            toUserResponse(user),
+	}, nil
+}
+
+func (s *authService) GetImpersonations(ctx context.Context, filter repository.ImpersonationFilter) ([]*dto.ImpersonationResponse, int64, error) {
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.PageSize < 1 || filter.PageSize > 100 {
+		filter.PageSize = 20
+	}
+
+	rows, total, err := s.impersonations.FindAll(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get impersonations: %w", err)
+	}
+
+	responses := make([]*dto.ImpersonationResponse, len(rows))
+	for i, row := range rows {
+		responses[i] = toImpersonationResponse(row)
+	}
+	return responses, total, nil
+}
+
+func (s *authService) GetImpersonationActions(ctx context.Context, impersonationID uuid.UUID) ([]*model.ImpersonationAction, error) {
+	row, err := s.impersonations.FindByID(ctx, impersonationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get impersonation: %w", err)
+	}
+	if row == nil {
+		return nil, ErrImpersonationNotFound
+	}
+
+	actions, err := s.impersonations.FindActions(ctx, impersonationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get impersonation actions: %w", err)
+	}
+	return actions, nil
+}
+
+func toImpersonationResponse(row *repository.ImpersonationRow) *dto.ImpersonationResponse {
+	return &dto.ImpersonationResponse{
+		ID:                row.ID,
+		ImpersonatorID:    row.ImpersonatorID,
+		ImpersonatorEmail: row.ImpersonatorEmail,
+		UserID:            row.UserID,
+		UserEmail:         row.UserEmail,
+		Reason:            row.Reason,
+		ReadOnly:          row.ReadOnly,
+		StartedAt:         row.StartedAt,
+		ExpiresAt:         row.ExpiresAt,
+		EndedAt:           row.EndedAt,
+		Active:            row.EndedAt == nil,
+		ActionCount:       row.ActionCount,
+	}
+}
+
+func toUserResponse(user *model.User) dto.UserResponse {
+	return dto.UserResponse{
+		ID:               user.ID,
+		Email:            user.Email,
+		Phone:            user.Phone,
+		Role:             user.Role,
+		IsVerified:       user.IsVerified,
+		PhoneVerified:    user.PhoneVerified,
+		IsActive:         user.IsActive,
+		Locale:           user.Locale,
+		TwoFactorEnabled: user.TOTPEnabled,
+		CreatedAt:        user.CreatedAt,
+	}
 }
 
 func (s *authService) GetUserProfile(userID uuid.UUID) (*dto.UserResponse, error) {
@@ -316,17 +791,25 @@ func (s *authService) GetUserProfile(userID uuid.UUID) (*dto.UserResponse, error
 	}
 
 	return &dto.UserResponse{
-		ID:         user.ID,
-		Email:      user.Email,
-		Phone:      user.Phone,
-		Role:       user.Role,
-		IsVerified: user.IsVerified,
-		IsActive:   user.IsActive,
-		CreatedAt:  user.CreatedAt,
+		ID:               user.ID,
+		Email:            user.Email,
+		Phone:            user.Phone,
+		Role:             user.Role,
+		IsVerified:       user.IsVerified,
+		PhoneVerified:    user.PhoneVerified,
+		IsActive:         user.IsActive,
+		Locale:           user.Locale,
+		TwoFactorEnabled: user.TOTPEnabled,
+		CreatedAt:        user.CreatedAt,
 	}, nil
 }
 
+// GenerateVerificationCode creates a code for the user, replacing any of
+// the same type sent before.
 func (s *authService) GenerateVerificationCode(userID uuid.UUID, codeType string) (*model.VerificationCode, error) {
+	if err := s.verificationRepo.InvalidateActive(userID, codeType); err != nil {
+		return nil, fmt.Errorf("failed to retire previous codes: %w", err)
+	}
 	code := generateRandomCode(6)
 
 	verificationCode := &model.VerificationCode{
@@ -346,6 +829,39 @@ func (s *authService) GenerateVerificationCode(userID uuid.UUID, codeType string
 	return verificationCode, nil
 }
 
+// checkCode spends the user's current code of a type if it matches.
+// Each wrong guess is counted, and after MaxCodeAttempts the code is gone,
+// so six digits can't be guessed by trying them all.
+func (s *authService) checkCode(user *model.User, codeType, code string) error {
+	active, err := s.verificationRepo.FindLatestActive(user.ID, codeType)
+	if err != nil {
+		return fmt.Errorf("failed to get code: %w", err)
+	}
+	if active == nil {
+		return ErrInvalidToken
+	}
+
+	if subtle.ConstantTimeCompare([]byte(active.Code), []byte(code)) != 1 {
+		attempts, err := s.verificationRepo.RecordFailedAttempt(active.ID, MaxCodeAttempts)
+		if err != nil {
+			log.Printf("failed to count wrong code for %s: %v", user.ID, err)
+		}
+		if attempts >= MaxCodeAttempts {
+			return ErrTooManyAttempts
+		}
+		return ErrInvalidToken
+	}
+
+	consumed, err := s.verificationRepo.Consume(active.ID)
+	if err != nil {
+		return fmt.Errorf("failed to use code: %w", err)
+	}
+	if !consumed {
+		return ErrInvalidToken
+	}
+	return nil
+}
+
 // Helper function to generate random numeric code
 func generateRandomCode(length int) string {
 	const digits = "0123456789"
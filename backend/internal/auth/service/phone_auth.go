@@ -0,0 +1,241 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"agro_konnect/internal/auth/dto"
+	"agro_konnect/internal/auth/model"
+	"agro_konnect/internal/auth/utils"
+
+	"github.com/google/uuid"
+)
+
+const (
+	PhoneCodeExpiry      = 10 * time.Minute
+	PhoneCodeCooldown    = time.Minute // between codes texted to one number
+	MaxPhoneCodesPerHour = 5
+	MaxCodeAttempts      = 5 // wrong guesses before a code stops working
+)
+
+var (
+	ErrInvalidPhone         = utils.ErrInvalidPhone
+	ErrCodeRequestedTooSoon = errors.New("a code was just sent to this number; wait a minute before asking for another")
+	ErrTooManyCodes         = errors.New("too many codes sent to this number; try again later")
+	ErrTooManyAttempts      = errors.New("too many wrong codes; request a new one")
+)
+
+// smsTexts are the code messages in each language the app speaks.
+var smsTexts = map[string]string{
+	"en": "%s is your Agro Konnect code. It expires in %d minutes. Never share it with anyone.",
+	"sw": "%s ni nambari yako ya Agro Konnect. Itakwisha muda wake baada ya dakika %d. Usimpe mtu yeyote.",
+}
+
+// SendPhoneVerification texts a code that proves the user has their phone.
+// Like RequestPhoneLogin it succeeds whether or not a code was sent, so it
+// reveals neither whether the number has an account nor whether it is
+// verified.
+func (s *authService) SendPhoneVerification(ctx context.Context, req *dto.PhoneRequest) error {
+	user, err := s.findUserByPhone(req.Phone)
+	if err != nil {
+		return err
+	}
+	if user == nil || user.PhoneVerified {
+		return nil
+	}
+	return quietRateLimit(s.sendPhoneCode(ctx, user, model.CodeTypePhoneVerification))
+}
+
+// VerifyPhone checks a code texted by SendPhoneVerification. An unknown or
+// already verified number fails the same way as a wrong code, as does a
+// code that has been guessed at too often.
+func (s *authService) VerifyPhone(ctx context.Context, req *dto.PhoneCodeRequest) error {
+	user, err := s.findUserByPhone(req.Phone)
+	if err != nil {
+		return err
+	}
+	if user == nil || user.PhoneVerified {
+		return ErrInvalidToken
+	}
+
+	if err := s.checkCode(user, model.CodeTypePhoneVerification, req.Code); err != nil {
+		if errors.Is(err, ErrTooManyAttempts) {
+			return ErrInvalidToken
+		}
+		return err
+	}
+	return s.markPhoneVerified(user)
+}
+
+// RequestPhoneLogin texts a one-time code to sign in with instead of a
+// password. Whether the number has an account is not revealed.
+func (s *authService) RequestPhoneLogin(ctx context.Context, req *dto.PhoneRequest) error {
+	user, err := s.findUserByPhone(req.Phone)
+	if err != nil {
+		return err
+	}
+	if user == nil || !user.IsActive {
+		return nil
+	}
+	return quietRateLimit(s.sendPhoneCode(ctx, user, model.CodeTypePhoneLogin))
+}
+
+// LoginWithPhone signs in with a code texted by RequestPhoneLogin. Getting
+// the code proves the user has the phone, so it also verifies the number.
+func (s *authService) LoginWithPhone(ctx context.Context, req *dto.PhoneCodeRequest, client dto.ClientInfo) (*dto.LoginResponse, error) {
+	user, err := s.findUserByPhone(req.Phone)
+	if err != nil {
+		return nil, err
+	}
+	phone, _ := utils.NormalizePhone(req.Phone)
+	accountKey, ipKey := AccountKey(user, phone), IPKey(client.IPAddress)
+	if err := s.throttle.Check(ctx, accountKey, ipKey); err != nil {
+		return nil, err
+	}
+	if user == nil {
+		s.throttle.Fail(ctx, accountKey, ipKey)
+		return nil, ErrInvalidCredentials
+	}
+	if !user.IsActive {
+		return nil, errors.New("account is deactivated")
+	}
+
+	if err := s.checkCode(user, model.CodeTypePhoneLogin, req.Code); err != nil {
+		if errors.Is(err, ErrInvalidToken) || errors.Is(err, ErrTooManyAttempts) {
+			s.throttle.Fail(ctx, accountKey, ipKey)
+		}
+		if errors.Is(err, ErrInvalidToken) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, err
+	}
+
+	if !user.PhoneVerified {
+		if err := s.markPhoneVerified(user); err != nil {
+			log.Printf("failed to mark phone of %s verified: %v", user.ID, err)
+		}
+	}
+
+	return s.signIn(ctx, user, client)
+}
+
+// ForgotPasswordByPhone texts a password reset code. Whether the number
+// has an account is not revealed.
+func (s *authService) ForgotPasswordByPhone(ctx context.Context, req *dto.PhoneRequest) error {
+	user, err := s.findUserByPhone(req.Phone)
+	if err != nil {
+		return err
+	}
+	if user == nil || !user.IsActive {
+		return nil
+	}
+	return quietRateLimit(s.sendPhoneCode(ctx, user, model.CodeTypePhonePasswordReset))
+}
+
+// quietRateLimit hides that no code was sent because the number was sent
+// too many; only a registered number can be, so saying so would reveal it.
+func quietRateLimit(err error) error {
+	if errors.Is(err, ErrCodeRequestedTooSoon) || errors.Is(err, ErrTooManyCodes) {
+		return nil
+	}
+	return err
+}
+
+func (s *authService) ResetPasswordByPhone(ctx context.Context, req *dto.PhoneResetPasswordRequest) error {
+	user, err := s.findUserByPhone(req.Phone)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrInvalidToken
+	}
+
+	if err := s.checkCode(user, model.CodeTypePhonePasswordReset, req.Code); err != nil {
+		return err
+	}
+
+	if err := s.updateUserPassword(user.ID, req.NewPassword); err != nil {
+		return err
+	}
+	s.endSessionsAfterReset(user.ID)
+	return nil
+}
+
+// findUserByPhone returns the user with a number, however it was written,
+// or nil.
+func (s *authService) findUserByPhone(phone string) (*model.User, error) {
+	normalized, err := utils.NormalizePhone(phone)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.userRepo.FindByPhone(normalized)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return user, nil
+}
+
+// sendPhoneCode texts the user a new code, replacing any of the same type
+// sent before.
+func (s *authService) sendPhoneCode(ctx context.Context, user *model.User, codeType string) error {
+	if err := s.checkPhoneRateLimit(user.Phone); err != nil {
+		return err
+	}
+	if err := s.verificationRepo.InvalidateActive(user.ID, codeType); err != nil {
+		return fmt.Errorf("failed to retire previous codes: %w", err)
+	}
+
+	now := time.Now()
+	verificationCode := &model.VerificationCode{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		Code:      generateRandomCode(6),
+		Type:      codeType,
+		Phone:     user.Phone,
+		ExpiresAt: now.Add(PhoneCodeExpiry),
+		CreatedAt: now,
+	}
+	if err := s.verificationRepo.Create(verificationCode); err != nil {
+		return fmt.Errorf("failed to create code: %w", err)
+	}
+
+	text, ok := smsTexts[user.Locale]
+	if !ok {
+		text = smsTexts["en"]
+	}
+	message := fmt.Sprintf(text, verificationCode.Code, int(PhoneCodeExpiry.Minutes()))
+	if err := s.smsProvider.SendSMS(ctx, user.Phone, message); err != nil {
+		return fmt.Errorf("failed to send SMS: %w", err)
+	}
+	return nil
+}
+
+// checkPhoneRateLimit stops a number being flooded with texts, whoever is
+// asking for them.
+func (s *authService) checkPhoneRateLimit(phone string) error {
+	lastSent, err := s.verificationRepo.LastSentToPhone(phone)
+	if err != nil {
+		return fmt.Errorf("failed to check codes sent: %w", err)
+	}
+	if lastSent != nil && time.Since(*lastSent) < PhoneCodeCooldown {
+		return ErrCodeRequestedTooSoon
+	}
+
+	sent, err := s.verificationRepo.CountSentToPhoneSince(phone, time.Now().Add(-time.Hour))
+	if err != nil {
+		return fmt.Errorf("failed to check codes sent: %w", err)
+	}
+	if sent >= MaxPhoneCodesPerHour {
+		return ErrTooManyCodes
+	}
+	return nil
+}
+
+func (s *authService) markPhoneVerified(user *model.User) error {
+	user.PhoneVerified = true
+	user.UpdatedAt = time.Now()
+	return s.userRepo.Update(user)
+}
@@ -0,0 +1,505 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	auditservice "agro_konnect/internal/audit/service"
+	"agro_konnect/internal/auth/dto"
+	"agro_konnect/internal/auth/model"
+	"agro_konnect/internal/auth/repository"
+	"agro_konnect/internal/auth/utils"
+
+	"github.com/google/uuid"
+)
+
+const testPhone = "+254700000001"
+
+// The fakes embed the repository interfaces, so a test that reaches a
+// method they don't implement panics instead of passing by accident.
+
+type fakeUsers struct {
+	repository.UserRepository
+	users map[uuid.UUID]*model.User
+}
+
+func (r *fakeUsers) FindByID(id uuid.UUID) (*model.User, error) {
+	if user, ok := r.users[id]; ok {
+		return user, nil
+	}
+	return nil, errors.New("record not found")
+}
+
+func (r *fakeUsers) FindByPhone(phone string) (*model.User, error) {
+	for _, user := range r.users {
+		if user.Phone == phone {
+			return user, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeUsers) Update(user *model.User) error {
+	r.users[user.ID] = user
+	return nil
+}
+
+func (r *fakeUsers) UpdateLastLogin(id uuid.UUID) error {
+	now := time.Now()
+	r.users[id].LastLoginAt = &now
+	return nil
+}
+
+type fakeCodes struct {
+	repository.VerificationRepository
+	mu    sync.Mutex
+	codes []*model.VerificationCode
+}
+
+func (r *fakeCodes) Create(code *model.VerificationCode) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.codes = append(r.codes, code)
+	return nil
+}
+
+func (r *fakeCodes) FindLatestActive(userID uuid.UUID, codeType string) (*model.VerificationCode, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := len(r.codes) - 1; i >= 0; i-- {
+		c := r.codes[i]
+		if c.UserID == userID && c.Type == codeType && !c.Used && c.ExpiresAt.After(time.Now()) {
+			return c, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeCodes) InvalidateActive(userID uuid.UUID, codeType string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, c := range r.codes {
+		if c.UserID == userID && c.Type == codeType {
+			c.Used = true
+		}
+	}
+	return nil
+}
+
+func (r *fakeCodes) RecordFailedAttempt(id uuid.UUID, maxAttempts int) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, c := range r.codes {
+		if c.ID == id {
+			c.Attempts++
+			c.Used = c.Used || c.Attempts >= maxAttempts
+			return c.Attempts, nil
+		}
+	}
+	return 0, errors.New("record not found")
+}
+
+func (r *fakeCodes) Consume(id uuid.UUID) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, c := range r.codes {
+		if c.ID == id && !c.Used {
+			c.Used = true
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *fakeCodes) CountSentToPhoneSince(phone string, since time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var count int64
+	for _, c := range r.codes {
+		if c.Phone == phone && !c.CreatedAt.Before(since) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *fakeCodes) LastSentToPhone(phone string) (*time.Time, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var last *time.Time
+	for _, c := range r.codes {
+		if c.Phone == phone && (last == nil || c.CreatedAt.After(*last)) {
+			createdAt := c.CreatedAt
+			last = &createdAt
+		}
+	}
+	return last, nil
+}
+
+// age moves every code back in time, as if d had passed since it was sent.
+func (r *fakeCodes) age(d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, c := range r.codes {
+		c.CreatedAt = c.CreatedAt.Add(-d)
+	}
+}
+
+type fakeSessions struct {
+	repository.SessionRepository
+	sessions []*model.Session
+}
+
+func (r *fakeSessions) Create(ctx context.Context, session *model.Session) error {
+	r.sessions = append(r.sessions, session)
+	return nil
+}
+
+type fakeTwoFactor struct {
+	repository.TwoFactorRepository
+}
+
+func (r *fakeTwoFactor) GetPolicy(ctx context.Context) (*model.TwoFactorPolicy, error) {
+	return &model.TwoFactorPolicy{}, nil
+}
+
+type fakeThrottle struct {
+	Throttle
+	failures int
+	resets   int
+}
+
+func (t *fakeThrottle) Check(ctx context.Context, keys ...AttemptKey) error { return nil }
+func (t *fakeThrottle) Fail(ctx context.Context, keys ...AttemptKey)        { t.failures++ }
+func (t *fakeThrottle) Reset(ctx context.Context, key AttemptKey)           { t.resets++ }
+
+// fakeSMS keeps the messages it is asked to send, so tests can read the
+// code that was texted.
+type fakeSMS struct {
+	mu   sync.Mutex
+	sent []sentSMS
+}
+
+type sentSMS struct {
+	Phone   string
+	Message string
+}
+
+func (p *fakeSMS) SendSMS(ctx context.Context, phone, message string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sent = append(p.sent, sentSMS{Phone: phone, Message: message})
+	return nil
+}
+
+func (p *fakeSMS) Sent() []sentSMS {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]sentSMS(nil), p.sent...)
+}
+
+// LastTo returns the latest message sent to a number.
+func (p *fakeSMS) LastTo(phone string) (sentSMS, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i := len(p.sent) - 1; i >= 0; i-- {
+		if p.sent[i].Phone == phone {
+			return p.sent[i], true
+		}
+	}
+	return sentSMS{}, false
+}
+
+type nopRecorder struct{}
+
+func (nopRecorder) Record(ctx context.Context, event auditservice.Event) {}
+
+type phoneAuthFixture struct {
+	service  *authService
+	user     *model.User
+	codes    *fakeCodes
+	sessions *fakeSessions
+	sms      *fakeSMS
+	throttle *fakeThrottle
+}
+
+func newPhoneAuthFixture(t *testing.T) *phoneAuthFixture {
+	t.Helper()
+	user := &model.User{
+		ID:       uuid.New(),
+		Email:    "farmer@example.com",
+		Phone:    testPhone,
+		Role:     model.RoleFarmer,
+		IsActive: true,
+		Locale:   "en",
+	}
+	f := &phoneAuthFixture{
+		user:     user,
+		codes:    &fakeCodes{},
+		sessions: &fakeSessions{},
+		sms:      &fakeSMS{},
+		throttle: &fakeThrottle{},
+	}
+	f.service = NewAuthService(
+		&fakeUsers{users: map[uuid.UUID]*model.User{user.ID: user}},
+		f.codes,
+		f.sessions,
+		nil,
+		&fakeTwoFactor{},
+		utils.NewJWTManager("test-secret", 15*time.Minute),
+		nil,
+		f.sms,
+		f.throttle,
+		nopRecorder{},
+	).(*authService)
+	return f
+}
+
+// lastCode returns the code in the latest text to the test number.
+func (f *phoneAuthFixture) lastCode(t *testing.T) string {
+	t.Helper()
+	sms, ok := f.sms.LastTo(testPhone)
+	if !ok {
+		t.Fatal("no SMS sent")
+	}
+	return strings.Fields(sms.Message)[0]
+}
+
+func TestSendPhoneCodeCooldown(t *testing.T) {
+	f := newPhoneAuthFixture(t)
+	ctx := context.Background()
+
+	if err := f.service.sendPhoneCode(ctx, f.user, model.CodeTypePhoneVerification); err != nil {
+		t.Fatalf("first code: %v", err)
+	}
+	if err := f.service.sendPhoneCode(ctx, f.user, model.CodeTypePhoneVerification); !errors.Is(err, ErrCodeRequestedTooSoon) {
+		t.Fatalf("code within the cooldown: got %v, want ErrCodeRequestedTooSoon", err)
+	}
+
+	f.codes.age(PhoneCodeCooldown)
+	if err := f.service.sendPhoneCode(ctx, f.user, model.CodeTypePhoneVerification); err != nil {
+		t.Fatalf("code after the cooldown: %v", err)
+	}
+	if got := len(f.sms.Sent()); got != 2 {
+		t.Fatalf("sent %d texts, want 2", got)
+	}
+}
+
+func TestSendPhoneCodeHourlyCap(t *testing.T) {
+	f := newPhoneAuthFixture(t)
+	ctx := context.Background()
+
+	for i := 0; i < MaxPhoneCodesPerHour; i++ {
+		if err := f.service.sendPhoneCode(ctx, f.user, model.CodeTypePhoneVerification); err != nil {
+			t.Fatalf("code %d: %v", i+1, err)
+		}
+		f.codes.age(PhoneCodeCooldown)
+	}
+	if err := f.service.sendPhoneCode(ctx, f.user, model.CodeTypePhoneVerification); !errors.Is(err, ErrTooManyCodes) {
+		t.Fatalf("code over the cap: got %v, want ErrTooManyCodes", err)
+	}
+
+	f.codes.age(time.Hour)
+	if err := f.service.sendPhoneCode(ctx, f.user, model.CodeTypePhoneVerification); err != nil {
+		t.Fatalf("code an hour later: %v", err)
+	}
+}
+
+// Only a registered number can be rate limited, so the public requests
+// must not say when one is.
+func TestPublicPhoneRequestsHideRateLimits(t *testing.T) {
+	requests := map[string]func(*authService, context.Context, *dto.PhoneRequest) error{
+		"RequestPhoneLogin":     (*authService).RequestPhoneLogin,
+		"ForgotPasswordByPhone": (*authService).ForgotPasswordByPhone,
+		"SendPhoneVerification": (*authService).SendPhoneVerification,
+	}
+	for name, request := range requests {
+		t.Run(name, func(t *testing.T) {
+			f := newPhoneAuthFixture(t)
+			ctx := context.Background()
+			req := &dto.PhoneRequest{Phone: testPhone}
+
+			for i := 0; i < 3; i++ {
+				if err := request(f.service, ctx, req); err != nil {
+					t.Fatalf("request %d: got %v, want nil", i+1, err)
+				}
+			}
+			if got := len(f.sms.Sent()); got != 1 {
+				t.Fatalf("sent %d texts, want 1", got)
+			}
+
+			if err := request(f.service, ctx, &dto.PhoneRequest{Phone: "+254799999999"}); err != nil {
+				t.Fatalf("unknown number: got %v, want nil", err)
+			}
+		})
+	}
+}
+
+// An unknown number, a verified one and a wrong code all fail alike, so
+// the public verification requests can't be used to find accounts.
+func TestPhoneVerificationHidesAccounts(t *testing.T) {
+	f := newPhoneAuthFixture(t)
+	ctx := context.Background()
+
+	if err := f.service.SendPhoneVerification(ctx, &dto.PhoneRequest{Phone: "+254799999999"}); err != nil {
+		t.Fatalf("send to an unknown number: got %v, want nil", err)
+	}
+	if err := f.service.VerifyPhone(ctx, &dto.PhoneCodeRequest{Phone: "+254799999999", Code: "123456"}); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("verify an unknown number: got %v, want ErrInvalidToken", err)
+	}
+
+	if err := f.service.SendPhoneVerification(ctx, &dto.PhoneRequest{Phone: testPhone}); err != nil {
+		t.Fatal(err)
+	}
+	code := f.lastCode(t)
+	wrong := "000000"
+	if code == wrong {
+		wrong = "111111"
+	}
+	for i := 0; i < MaxCodeAttempts+1; i++ {
+		if err := f.service.VerifyPhone(ctx, &dto.PhoneCodeRequest{Phone: testPhone, Code: wrong}); !errors.Is(err, ErrInvalidToken) {
+			t.Fatalf("wrong code %d: got %v, want ErrInvalidToken", i+1, err)
+		}
+	}
+
+	f.codes.age(PhoneCodeCooldown)
+	if err := f.service.SendPhoneVerification(ctx, &dto.PhoneRequest{Phone: testPhone}); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.service.VerifyPhone(ctx, &dto.PhoneCodeRequest{Phone: testPhone, Code: f.lastCode(t)}); err != nil {
+		t.Fatalf("right code: %v", err)
+	}
+	if !f.user.PhoneVerified {
+		t.Fatal("phone not verified")
+	}
+
+	sent := len(f.sms.Sent())
+	f.codes.age(PhoneCodeCooldown)
+	if err := f.service.SendPhoneVerification(ctx, &dto.PhoneRequest{Phone: testPhone}); err != nil {
+		t.Fatalf("send to a verified number: got %v, want nil", err)
+	}
+	if len(f.sms.Sent()) != sent {
+		t.Error("a code was texted to a verified number")
+	}
+	if err := f.service.VerifyPhone(ctx, &dto.PhoneCodeRequest{Phone: testPhone, Code: "123456"}); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("verify a verified number: got %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestLoginWithPhoneBurnsCodeAfterMaxAttempts(t *testing.T) {
+	f := newPhoneAuthFixture(t)
+	ctx := context.Background()
+
+	if err := f.service.RequestPhoneLogin(ctx, &dto.PhoneRequest{Phone: testPhone}); err != nil {
+		t.Fatal(err)
+	}
+	code := f.lastCode(t)
+	wrong := "000000"
+	if code == wrong {
+		wrong = "111111"
+	}
+
+	for i := 1; i < MaxCodeAttempts; i++ {
+		_, err := f.service.LoginWithPhone(ctx, &dto.PhoneCodeRequest{Phone: testPhone, Code: wrong}, dto.ClientInfo{})
+		if !errors.Is(err, ErrInvalidCredentials) {
+			t.Fatalf("wrong code %d: got %v, want ErrInvalidCredentials", i, err)
+		}
+	}
+	_, err := f.service.LoginWithPhone(ctx, &dto.PhoneCodeRequest{Phone: testPhone, Code: wrong}, dto.ClientInfo{})
+	if !errors.Is(err, ErrTooManyAttempts) {
+		t.Fatalf("last wrong code: got %v, want ErrTooManyAttempts", err)
+	}
+
+	// The code is burnt, so even the right one no longer works
+	_, err = f.service.LoginWithPhone(ctx, &dto.PhoneCodeRequest{Phone: testPhone, Code: code}, dto.ClientInfo{})
+	if !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("burnt code: got %v, want ErrInvalidCredentials", err)
+	}
+	if f.throttle.failures != MaxCodeAttempts+1 {
+		t.Fatalf("counted %d failures, want %d", f.throttle.failures, MaxCodeAttempts+1)
+	}
+	if len(f.sessions.sessions) != 0 {
+		t.Fatal("a session was started")
+	}
+}
+
+func TestLoginWithPhoneSignsIn(t *testing.T) {
+	f := newPhoneAuthFixture(t)
+	ctx := context.Background()
+
+	if err := f.service.RequestPhoneLogin(ctx, &dto.PhoneRequest{Phone: testPhone}); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := f.service.LoginWithPhone(ctx, &dto.PhoneCodeRequest{Phone: testPhone, Code: f.lastCode(t)}, dto.ClientInfo{IPAddress: "192.0.2.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.AuthResponse == nil || resp.TwoFactor != nil {
+		t.Fatalf("got %+v, want a signed-in session", resp)
+	}
+	if len(f.sessions.sessions) != 1 || f.sessions.sessions[0].IPAddress != "192.0.2.1" {
+		t.Fatalf("sessions: %+v", f.sessions.sessions)
+	}
+	if !f.user.PhoneVerified {
+		t.Error("phone not marked verified")
+	}
+	if f.throttle.resets != 1 {
+		t.Errorf("failed attempts reset %d times, want 1", f.throttle.resets)
+	}
+
+	// The code can't be used twice
+	f.codes.age(PhoneCodeCooldown)
+	_, err = f.service.LoginWithPhone(ctx, &dto.PhoneCodeRequest{Phone: testPhone, Code: f.lastCode(t)}, dto.ClientInfo{})
+	if !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("reused code: got %v, want ErrInvalidCredentials", err)
+	}
+}
+
+func TestLoginWithPhoneAsksForSecondFactor(t *testing.T) {
+	f := newPhoneAuthFixture(t)
+	f.user.TOTPEnabled = true
+	ctx := context.Background()
+
+	if err := f.service.RequestPhoneLogin(ctx, &dto.PhoneRequest{Phone: testPhone}); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := f.service.LoginWithPhone(ctx, &dto.PhoneCodeRequest{Phone: testPhone, Code: f.lastCode(t)}, dto.ClientInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.TwoFactor == nil || resp.AuthResponse != nil {
+		t.Fatalf("got %+v, want a two-factor challenge", resp)
+	}
+	if len(f.sessions.sessions) != 0 {
+		t.Fatal("a session was started before the second factor")
+	}
+}
+
+func TestResetPasswordTakesOnlyTheEmailedCode(t *testing.T) {
+	f := newPhoneAuthFixture(t)
+	accessToken, _, err := f.service.jwtManager.GenerateAccessToken(f.user, uuid.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		req  dto.ResetPasswordRequest
+		want error
+	}{
+		{"access token", dto.ResetPasswordRequest{Email: f.user.Email, Token: accessToken, NewPassword: "new-secret"}, ErrResetCodeRequired},
+		{"access token without email", dto.ResetPasswordRequest{Token: accessToken, NewPassword: "new-secret"}, ErrResetCodeRequired},
+		{"code without email", dto.ResetPasswordRequest{Token: "123456", NewPassword: "new-secret"}, ErrResetEmailRequired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := f.service.ResetPassword(&tt.req); !errors.Is(err, tt.want) {
+				t.Errorf("ResetPassword = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// SMSProvider texts a message to a phone number through an SMS gateway.
+type SMSProvider interface {
+	SendSMS(ctx context.Context, phone, message string) error
+}
+
+const (
+	SMSProviderNone = "none"
+	SMSProviderHTTP = "http"
+)
+
+// SMSConfig picks the SMS gateway. The HTTP gateway is POSTed a JSON body
+// {"to", "from", "message"} with the token as a bearer credential.
+type SMSConfig struct {
+	Provider string // "none" (the default) or "http"
+	URL      string
+	Token    string
+	Sender   string // sender ID or number, if the gateway needs one
+}
+
+// LoadSMSConfig reads the gateway settings from SMS_* environment variables.
+func LoadSMSConfig() SMSConfig {
+	config := SMSConfig{
+		Provider: strings.ToLower(os.Getenv("SMS_PROVIDER")),
+		URL:      os.Getenv("SMS_GATEWAY_URL"),
+		Token:    os.Getenv("SMS_GATEWAY_TOKEN"),
+		Sender:   os.Getenv("SMS_SENDER"),
+	}
+	if config.Provider == "" {
+		config.Provider = SMSProviderNone
+	}
+	return config
+}
+
+// NewSMSProvider returns the configured gateway.
+func NewSMSProvider(config SMSConfig) (SMSProvider, error) {
+	switch config.Provider {
+	case SMSProviderNone:
+		return noopSMSProvider{}, nil
+	case SMSProviderHTTP:
+		if config.URL == "" {
+			return nil, fmt.Errorf("SMS_GATEWAY_URL is required for the %s SMS provider", config.Provider)
+		}
+		return &httpSMSProvider{config: config, client: &http.Client{Timeout: 10 * time.Second}}, nil
+	default:
+		return nil, fmt.Errorf("unknown SMS provider %q", config.Provider)
+	}
+}
+
+// noopSMSProvider is used when no gateway is configured. It drops messages,
+// noting only that it did: they carry sign-in codes, so their text is never
+// logged.
+type noopSMSProvider struct{}
+
+func (noopSMSProvider) SendSMS(ctx context.Context, phone, message string) error {
+	log.Printf("SMS to %s not sent: no SMS gateway configured", maskPhone(phone))
+	return nil
+}
+
+type httpSMSProvider struct {
+	config SMSConfig
+	client *http.Client
+}
+
+func (p *httpSMSProvider) SendSMS(ctx context.Context, phone, message string) error {
+	body, err := json.Marshal(map[string]string{"to": phone, "from": p.config.Sender, "message": message})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.config.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.config.Token)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("SMS gateway request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("SMS gateway returned %s", resp.Status)
+	}
+	return nil
+}
+
+// maskPhone keeps only the last two digits of a number, for logs.
+func maskPhone(phone string) string {
+	if len(phone) <= 2 {
+		return phone
+	}
+	return strings.Repeat("*", len(phone)-2) + phone[len(phone)-2:]
+}
@@ -0,0 +1,300 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	auditservice "agro_konnect/internal/audit/service"
+	"agro_konnect/internal/auth/dto"
+	"agro_konnect/internal/auth/model"
+	"agro_konnect/internal/auth/repository"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrTooManyFailedAttempts = errors.New("too many failed attempts")
+	ErrLockoutNotFound       = errors.New("lockout not found")
+)
+
+// ThrottledError says when a throttled account or IP address may try
+// again. It matches ErrTooManyFailedAttempts.
+type ThrottledError struct {
+	RetryAfter time.Duration
+	Locked     bool // locked out, rather than slowed down
+}
+
+func (e *ThrottledError) Error() string {
+	if e.Locked {
+		return "too many failed attempts; try again later"
+	}
+	return "too many failed attempts; wait a moment before trying again"
+}
+
+func (e *ThrottledError) Is(target error) bool {
+	return target == ErrTooManyFailedAttempts
+}
+
+// ThrottlePolicy is how failed attempts are punished. The first
+// FreeAttempts failures cost nothing; each one after that makes the next
+// attempt wait twice as long, starting at a second; at MaxFailures the
+// account or address is locked out for LockoutDuration, doubling with each
+// further failure up to MaxLockoutDuration. Failures are forgotten after a
+// quiet Window, or for an account when it signs in.
+type ThrottlePolicy struct {
+	FreeAttempts       int
+	MaxFailures        int
+	LockoutDuration    time.Duration
+	MaxLockoutDuration time.Duration
+	Window             time.Duration
+}
+
+type ThrottleConfig struct {
+	Account ThrottlePolicy
+	IP      ThrottlePolicy // looser, as many users can share an address
+}
+
+// LoadThrottleConfig reads LOGIN_MAX_FAILURES, LOGIN_IP_MAX_FAILURES and
+// LOGIN_LOCKOUT_MINUTES from the environment, falling back to the defaults.
+func LoadThrottleConfig() ThrottleConfig {
+	config := ThrottleConfig{
+		Account: ThrottlePolicy{
+			FreeAttempts:       3,
+			MaxFailures:        10,
+			LockoutDuration:    15 * time.Minute,
+			MaxLockoutDuration: 24 * time.Hour,
+			Window:             24 * time.Hour,
+		},
+		IP: ThrottlePolicy{
+			FreeAttempts:       20,
+			MaxFailures:        100,
+			LockoutDuration:    15 * time.Minute,
+			MaxLockoutDuration: 24 * time.Hour,
+			Window:             24 * time.Hour,
+		},
+	}
+	if v, err := strconv.Atoi(os.Getenv("LOGIN_MAX_FAILURES")); err == nil && v > 0 {
+		config.Account.MaxFailures = v
+		if config.Account.FreeAttempts >= v {
+			config.Account.FreeAttempts = v - 1
+		}
+	}
+	if v, err := strconv.Atoi(os.Getenv("LOGIN_IP_MAX_FAILURES")); err == nil && v > 0 {
+		config.IP.MaxFailures = v
+		if config.IP.FreeAttempts >= v {
+			config.IP.FreeAttempts = v - 1
+		}
+	}
+	if v, err := strconv.Atoi(os.Getenv("LOGIN_LOCKOUT_MINUTES")); err == nil && v > 0 {
+		config.Account.LockoutDuration = time.Duration(v) * time.Minute
+		config.IP.LockoutDuration = time.Duration(v) * time.Minute
+	}
+	return config
+}
+
+// AttemptKey is what failed attempts are counted against.
+type AttemptKey struct {
+	Scope   string
+	Subject string
+	UserID  *uuid.UUID
+}
+
+// AccountKey counts failures against a user. identifier is what was tried,
+// an email or phone number, and is used when no account has it, so unknown
+// accounts are throttled just like real ones.
+func AccountKey(user *model.User, identifier string) AttemptKey {
+	if user == nil {
+		return AttemptKey{Scope: model.AttemptScopeAccount, Subject: strings.ToLower(strings.TrimSpace(identifier))}
+	}
+	return AttemptKey{Scope: model.AttemptScopeAccount, Subject: user.ID.String(), UserID: &user.ID}
+}
+
+func IPKey(ip string) AttemptKey {
+	return AttemptKey{Scope: model.AttemptScopeIP, Subject: ip}
+}
+
+// Throttle slows down and locks out repeated failed sign-ins, per account
+// and per IP address.
+type Throttle interface {
+	Check(ctx context.Context, keys ...AttemptKey) error
+	Fail(ctx context.Context, keys ...AttemptKey)
+	Reset(ctx context.Context, key AttemptKey)
+	GetLockouts(ctx context.Context, filter repository.AttemptFilter) ([]*dto.LockoutResponse, int64, error)
+	ClearLockout(ctx context.Context, id uuid.UUID) error
+	ClearUserLockouts(ctx context.Context, userID uuid.UUID) (int64, error)
+}
+
+type throttle struct {
+	attempts repository.AttemptRepository
+	config   ThrottleConfig
+	audit    auditservice.Recorder
+	now      func() time.Time
+}
+
+func NewThrottle(attempts repository.AttemptRepository, config ThrottleConfig, audit auditservice.Recorder) Throttle {
+	return &throttle{
+		attempts: attempts,
+		config:   config,
+		audit:    audit,
+		now:      time.Now,
+	}
+}
+
+func (t *throttle) policy(scope string) ThrottlePolicy {
+	if scope == model.AttemptScopeIP {
+		return t.config.IP
+	}
+	return t.config.Account
+}
+
+// Check returns a ThrottledError if any of the keys must wait. Keys with no
+// subject, such as a missing IP address, are skipped.
+func (t *throttle) Check(ctx context.Context, keys ...AttemptKey) error {
+	now := t.now()
+	for _, key := range keys {
+		if key.Subject == "" {
+			continue
+		}
+		counter, err := t.attempts.Find(ctx, key.Scope, key.Subject)
+		if err != nil {
+			return fmt.Errorf("failed to check failed attempts: %w", err)
+		}
+		if counter == nil {
+			continue
+		}
+		if retryAt, locked := t.retryAt(counter); now.Before(retryAt) {
+			return &ThrottledError{RetryAfter: retryAt.Sub(now), Locked: locked}
+		}
+	}
+	return nil
+}
+
+// retryAt is when the next attempt against a counter is allowed, and
+// whether it is locked out until then.
+func (t *throttle) retryAt(counter *model.AttemptCounter) (time.Time, bool) {
+	now := t.now()
+	if counter.LockedUntil != nil && counter.LockedUntil.After(now) {
+		return *counter.LockedUntil, true
+	}
+	policy := t.policy(counter.Scope)
+	if now.Sub(counter.LastFailedAt) > policy.Window || counter.Failures <= policy.FreeAttempts {
+		return time.Time{}, false
+	}
+	return counter.LastFailedAt.Add(backoff(time.Second, counter.Failures-policy.FreeAttempts-1, policy.LockoutDuration)), false
+}
+
+// Fail counts a failed attempt against each key, locking out those that
+// reach the limit. Errors are logged, so a failed sign-in still fails
+// normally.
+func (t *throttle) Fail(ctx context.Context, keys ...AttemptKey) {
+	for _, key := range keys {
+		if key.Subject == "" {
+			continue
+		}
+		policy := t.policy(key.Scope)
+		counter, err := t.attempts.RecordFailure(ctx, key.Scope, key.Subject, key.UserID, t.now().Add(-policy.Window))
+		if err != nil {
+			log.Printf("failed to count failed attempt for %s %s: %v", key.Scope, key.Subject, err)
+			continue
+		}
+		if counter.Failures < policy.MaxFailures {
+			continue
+		}
+
+		until := t.now().Add(backoff(policy.LockoutDuration, counter.Failures-policy.MaxFailures, policy.MaxLockoutDuration))
+		if err := t.attempts.Lock(ctx, counter.ID, until); err != nil {
+			log.Printf("failed to lock out %s %s: %v", key.Scope, key.Subject, err)
+			continue
+		}
+		log.Printf("locked out %s %s until %s after %d failed attempts", key.Scope, key.Subject, until.Format(time.RFC3339), counter.Failures)
+	}
+}
+
+// Reset forgets the failures against a key after it signs in successfully.
+func (t *throttle) Reset(ctx context.Context, key AttemptKey) {
+	if err := t.attempts.Clear(ctx, key.Scope, key.Subject); err != nil {
+		log.Printf("failed to reset failed attempts for %s %s: %v", key.Scope, key.Subject, err)
+	}
+}
+
+func (t *throttle) GetLockouts(ctx context.Context, filter repository.AttemptFilter) ([]*dto.LockoutResponse, int64, error) {
+	rows, total, err := t.attempts.FindAll(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get lockouts: %w", err)
+	}
+
+	lockouts := make([]*dto.LockoutResponse, len(rows))
+	for i, row := range rows {
+		retryAt, locked := t.retryAt(&row.AttemptCounter)
+		lockout := &dto.LockoutResponse{
+			ID:            row.ID,
+			Scope:         row.Scope,
+			Subject:       row.Subject,
+			UserID:        row.UserID,
+			UserEmail:     row.UserEmail,
+			Failures:      row.Failures,
+			FirstFailedAt: row.FirstFailedAt,
+			LastFailedAt:  row.LastFailedAt,
+			Locked:        locked,
+		}
+		if retryAt.After(t.now()) {
+			lockout.RetryAt = &retryAt
+		}
+		lockouts[i] = lockout
+	}
+	return lockouts, total, nil
+}
+
+// ClearLockout lets an account or address try again straight away.
+func (t *throttle) ClearLockout(ctx context.Context, id uuid.UUID) error {
+	deleted, err := t.attempts.DeleteByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to clear lockout: %w", err)
+	}
+	if !deleted {
+		return ErrLockoutNotFound
+	}
+
+	t.audit.Record(ctx, auditservice.Event{
+		Action:     "lockout.clear",
+		EntityType: "attempt_counter",
+		EntityID:   id.String(),
+	})
+	return nil
+}
+
+// ClearUserLockouts clears the failures against a user's account.
+func (t *throttle) ClearUserLockouts(ctx context.Context, userID uuid.UUID) (int64, error) {
+	count, err := t.attempts.DeleteByUserID(ctx, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to clear lockouts: %w", err)
+	}
+
+	if count > 0 {
+		t.audit.Record(ctx, auditservice.Event{
+			Action:     "user.unlock",
+			EntityType: "user",
+			EntityID:   userID.String(),
+			After:      map[string]interface{}{"cleared": count},
+		})
+	}
+	return count, nil
+}
+
+// backoff doubles base n times, up to max.
+func backoff(base time.Duration, n int, max time.Duration) time.Duration {
+	d := base
+	for i := 0; i < n && d < max; i++ {
+		d *= 2
+	}
+	if d > max {
+		return max
+	}
+	return d
+}
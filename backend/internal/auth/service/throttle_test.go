@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"agro_konnect/internal/auth/model"
+	"agro_konnect/internal/auth/repository"
+
+	"github.com/google/uuid"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+// fakeAttempts counts failures as the attempt repository does, on the
+// test's clock.
+type fakeAttempts struct {
+	repository.AttemptRepository
+	clock    *fakeClock
+	counters map[string]*model.AttemptCounter
+}
+
+func (r *fakeAttempts) Find(ctx context.Context, scope, subject string) (*model.AttemptCounter, error) {
+	return r.counters[scope+":"+subject], nil
+}
+
+func (r *fakeAttempts) RecordFailure(ctx context.Context, scope, subject string, userID *uuid.UUID, windowStart time.Time) (*model.AttemptCounter, error) {
+	now := r.clock.Now()
+	counter, ok := r.counters[scope+":"+subject]
+	if !ok {
+		counter = &model.AttemptCounter{ID: uuid.New(), Scope: scope, Subject: subject, UserID: userID, FirstFailedAt: now}
+		r.counters[scope+":"+subject] = counter
+	}
+	if counter.LastFailedAt.Before(windowStart) {
+		counter.Failures = 0
+		counter.FirstFailedAt = now
+	}
+	if counter.LockedUntil != nil && !counter.LockedUntil.After(now) {
+		counter.LockedUntil = nil
+	}
+	counter.Failures++
+	counter.LastFailedAt = now
+	return counter, nil
+}
+
+func (r *fakeAttempts) Lock(ctx context.Context, id uuid.UUID, until time.Time) error {
+	for _, counter := range r.counters {
+		if counter.ID == id {
+			counter.LockedUntil = &until
+		}
+	}
+	return nil
+}
+
+func (r *fakeAttempts) Clear(ctx context.Context, scope, subject string) error {
+	delete(r.counters, scope+":"+subject)
+	return nil
+}
+
+func newTestThrottle(clock *fakeClock) *throttle {
+	policy := ThrottlePolicy{
+		FreeAttempts:       3,
+		MaxFailures:        6,
+		LockoutDuration:    time.Minute,
+		MaxLockoutDuration: 4 * time.Minute,
+		Window:             time.Hour,
+	}
+	return &throttle{
+		attempts: &fakeAttempts{clock: clock, counters: make(map[string]*model.AttemptCounter)},
+		config:   ThrottleConfig{Account: policy, IP: policy},
+		audit:    nopRecorder{},
+		now:      clock.Now,
+	}
+}
+
+func TestThrottle(t *testing.T) {
+	tests := []struct {
+		name       string
+		failures   int           // failed attempts in a row
+		wait       time.Duration // then the clock moves on
+		more       int           // and more attempts fail
+		wantRetry  time.Duration // zero if the next attempt is allowed
+		wantLocked bool
+	}{
+		{"free attempts", 3, 0, 0, 0, false},
+		{"first backoff", 4, 0, 0, time.Second, false},
+		{"backoff doubles", 5, 0, 0, 2 * time.Second, false},
+		{"backoff served", 5, 2 * time.Second, 0, 0, false},
+		{"backoff partly served", 5, 500 * time.Millisecond, 0, 1500 * time.Millisecond, false},
+		{"locked out", 6, 0, 0, time.Minute, true},
+		{"lockout doubles", 7, 0, 0, 2 * time.Minute, true},
+		{"lockout capped", 10, 0, 0, 4 * time.Minute, true},
+		{"lockout served", 6, time.Minute, 0, 0, false},
+		{"failure after lockout locks again", 6, time.Minute, 1, 2 * time.Minute, true},
+		{"window passed", 5, time.Hour + time.Second, 0, 0, false},
+		{"counting restarts after the window", 5, time.Hour + time.Second, 3, 0, false},
+		{"restarted count backs off again", 5, time.Hour + time.Second, 4, time.Second, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := &fakeClock{now: time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)}
+			th := newTestThrottle(clock)
+			ctx := context.Background()
+			key := AccountKey(nil, "farmer@example.com")
+
+			for i := 0; i < tt.failures; i++ {
+				th.Fail(ctx, key)
+			}
+			clock.Advance(tt.wait)
+			for i := 0; i < tt.more; i++ {
+				th.Fail(ctx, key)
+			}
+
+			err := th.Check(ctx, key)
+			if tt.wantRetry == 0 {
+				if err != nil {
+					t.Fatalf("Check = %v, want nil", err)
+				}
+				return
+			}
+			var throttled *ThrottledError
+			if !errors.As(err, &throttled) || !errors.Is(err, ErrTooManyFailedAttempts) {
+				t.Fatalf("Check = %v, want a ThrottledError", err)
+			}
+			if throttled.RetryAfter != tt.wantRetry || throttled.Locked != tt.wantLocked {
+				t.Errorf("Check = retry after %s locked %v, want %s locked %v", throttled.RetryAfter, throttled.Locked, tt.wantRetry, tt.wantLocked)
+			}
+		})
+	}
+}
+
+func TestThrottleKeysAreSeparate(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)}
+	th := newTestThrottle(clock)
+	ctx := context.Background()
+	account, ip := AccountKey(nil, "farmer@example.com"), IPKey("203.0.113.7")
+
+	for i := 0; i < 6; i++ {
+		th.Fail(ctx, account)
+	}
+	if err := th.Check(ctx, ip); err != nil {
+		t.Fatalf("IP checked alone = %v, want nil", err)
+	}
+	if err := th.Check(ctx, ip, account); !errors.Is(err, ErrTooManyFailedAttempts) {
+		t.Fatalf("IP with the locked account = %v, want ErrTooManyFailedAttempts", err)
+	}
+	// Keys without a subject are skipped
+	if err := th.Check(ctx, IPKey("")); err != nil {
+		t.Fatalf("empty key = %v, want nil", err)
+	}
+
+	th.Reset(ctx, account)
+	if err := th.Check(ctx, account); err != nil {
+		t.Fatalf("after reset = %v, want nil", err)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		base time.Duration
+		n    int
+		max  time.Duration
+		want time.Duration
+	}{
+		{time.Second, 0, time.Minute, time.Second},
+		{time.Second, 3, time.Minute, 8 * time.Second},
+		{time.Second, 10, time.Minute, time.Minute},
+		{time.Second, 1000, time.Minute, time.Minute},
+		{time.Hour, 0, time.Minute, time.Minute},
+	}
+	for _, tt := range tests {
+		if got := backoff(tt.base, tt.n, tt.max); got != tt.want {
+			t.Errorf("backoff(%s, %d, %s) = %s, want %s", tt.base, tt.n, tt.max, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,504 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	auditservice "agro_konnect/internal/audit/service"
+	"agro_konnect/internal/auth/dto"
+	"agro_konnect/internal/auth/model"
+	"agro_konnect/internal/auth/utils"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// RecoveryCodeCount is how many recovery codes a user gets at a time.
+const RecoveryCodeCount = 10
+
+var (
+	ErrTwoFactorNotEnabled     = errors.New("two-factor authentication is not enabled")
+	ErrTwoFactorAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorSetupNotBegun  = errors.New("start two-factor setup first")
+	ErrTwoFactorRequired       = errors.New("two-factor authentication is required for this account")
+	ErrInvalidTwoFactorCode    = errors.New("invalid two-factor code")
+)
+
+// totpIssuer is the account name shown in authenticator apps.
+func totpIssuer() string {
+	if issuer := os.Getenv("TOTP_ISSUER"); issuer != "" {
+		return issuer
+	}
+	return "Agro Konnect"
+}
+
+// signIn finishes a sign-in once the first factor has been checked: users
+// with two-factor authentication get a challenge, and so do users who are
+// required to have it but haven't enrolled yet.
+func (s *authService) signIn(ctx context.Context, user *model.User, client dto.ClientInfo) (*dto.LoginResponse, error) {
+	challenge := ""
+	if user.TOTPEnabled {
+		challenge = utils.ChallengeVerify
+	} else {
+		required, err := s.twoFactorRequired(ctx, user)
+		if err != nil {
+			return nil, err
+		}
+		if required {
+			challenge = utils.ChallengeEnroll
+		}
+	}
+
+	if challenge != "" {
+		token, expiresAt, err := s.jwtManager.GenerateChallengeToken(user.ID, challenge)
+		if err != nil {
+			return nil, err
+		}
+		return &dto.LoginResponse{TwoFactor: &dto.TwoFactorChallenge{
+			ChallengeToken: token,
+			ExpiresAt:      expiresAt,
+			SetupRequired:  challenge == utils.ChallengeEnroll,
+		}}, nil
+	}
+
+	authResponse, err := s.startSignedInSession(ctx, user, client)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.LoginResponse{AuthResponse: authResponse}, nil
+}
+
+// startSignedInSession starts the session once every factor has been
+// checked, and only then forgets the account's failed attempts.
+func (s *authService) startSignedInSession(ctx context.Context, user *model.User, client dto.ClientInfo) (*dto.AuthResponse, error) {
+	s.throttle.Reset(ctx, AccountKey(user, ""))
+	if err := s.userRepo.UpdateLastLogin(user.ID); err != nil {
+		log.Printf("failed to update last login of %s: %v", user.ID, err)
+	}
+	return s.StartSession(ctx, user, client)
+}
+
+// twoFactorRequired applies the policy set by admins to a user.
+func (s *authService) twoFactorRequired(ctx context.Context, user *model.User) (bool, error) {
+	policy, err := s.twoFactor.GetPolicy(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to get two-factor policy: %w", err)
+	}
+
+	switch user.Role {
+	case model.RoleAdmin:
+		return policy.RequireForAdmins, nil
+	case model.RoleBuyer:
+		if policy.BuyerCreditThreshold == nil {
+			return false, nil
+		}
+		limit, err := s.twoFactor.BuyerCreditLimit(ctx, user.ID)
+		if err != nil {
+			return false, fmt.Errorf("failed to get buyer credit limit: %w", err)
+		}
+		return limit > *policy.BuyerCreditThreshold, nil
+	}
+	return false, nil
+}
+
+// userForChallenge loads the user a challenge token was issued to.
+func (s *authService) userForChallenge(token, challenge string) (*model.User, error) {
+	claims, err := s.jwtManager.ValidateChallengeToken(token, challenge)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	user, err := s.userRepo.FindByID(claims.UserID)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	if !user.IsActive {
+		return nil, errors.New("account is deactivated")
+	}
+	return user, nil
+}
+
+// VerifyTwoFactorLogin answers a sign-in challenge with a code from the
+// authenticator app or a recovery code.
+func (s *authService) VerifyTwoFactorLogin(ctx context.Context, req *dto.TwoFactorLoginRequest, client dto.ClientInfo) (*dto.AuthResponse, error) {
+	user, err := s.userForChallenge(req.ChallengeToken, utils.ChallengeVerify)
+	if err != nil {
+		return nil, err
+	}
+	if !user.TOTPEnabled {
+		return nil, ErrInvalidToken
+	}
+
+	err = s.throttled(ctx, user, client, func() error {
+		return s.checkSecondFactor(ctx, user, req.Code)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.startSignedInSession(ctx, user, client)
+}
+
+// throttled runs a check of the user's password or second factor, refusing
+// while the account or address is throttled and counting wrong answers.
+func (s *authService) throttled(ctx context.Context, user *model.User, client dto.ClientInfo, check func() error) error {
+	accountKey, ipKey := AccountKey(user, ""), IPKey(client.IPAddress)
+	if err := s.throttle.Check(ctx, accountKey, ipKey); err != nil {
+		return err
+	}
+	err := check()
+	if errors.Is(err, ErrInvalidTwoFactorCode) || errors.Is(err, ErrInvalidCredentials) {
+		s.throttle.Fail(ctx, accountKey, ipKey)
+	}
+	return err
+}
+
+// checkSecondFactor accepts a current TOTP code, once, or an unused
+// recovery code.
+func (s *authService) checkSecondFactor(ctx context.Context, user *model.User, code string) error {
+	if len(code) == utils.TOTPDigits {
+		secret, err := utils.OpenTOTPSecret(user.TOTPSecret)
+		if err != nil {
+			return fmt.Errorf("failed to read two-factor secret: %w", err)
+		}
+		step, ok := utils.ValidateTOTP(secret, code, time.Now())
+		if !ok {
+			return ErrInvalidTwoFactorCode
+		}
+		fresh, err := s.twoFactor.UseStep(ctx, user.ID, step)
+		if err != nil {
+			return fmt.Errorf("failed to record two-factor code: %w", err)
+		}
+		if !fresh {
+			return ErrInvalidTwoFactorCode
+		}
+		return nil
+	}
+
+	used, err := s.twoFactor.UseRecoveryCode(ctx, user.ID, utils.HashRecoveryCode(code))
+	if err != nil {
+		return fmt.Errorf("failed to check recovery code: %w", err)
+	}
+	if !used {
+		return ErrInvalidTwoFactorCode
+	}
+	log.Printf("user %s signed in with a recovery code", user.ID)
+	return nil
+}
+
+// BeginTwoFactorSetup creates a new secret for the user to add to their
+// authenticator app. It isn't used until a code from it is confirmed.
+func (s *authService) BeginTwoFactorSetup(ctx context.Context, userID uuid.UUID) (*dto.TwoFactorSetupResponse, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	return s.beginTwoFactorSetup(ctx, user)
+}
+
+func (s *authService) beginTwoFactorSetup(ctx context.Context, user *model.User) (*dto.TwoFactorSetupResponse, error) {
+	if user.TOTPEnabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate two-factor secret: %w", err)
+	}
+	sealed, err := utils.SealTOTPSecret(secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to seal two-factor secret: %w", err)
+	}
+	saved, err := s.twoFactor.SetPendingSecret(ctx, user.ID, sealed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save two-factor secret: %w", err)
+	}
+	if !saved {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	return &dto.TwoFactorSetupResponse{
+		Secret:          secret,
+		ProvisioningURI: utils.TOTPProvisioningURI(totpIssuer(), user.Email, secret),
+	}, nil
+}
+
+// EnableTwoFactor confirms setup with a code from the new secret and
+// returns the recovery codes, which are shown only this once.
+func (s *authService) EnableTwoFactor(ctx context.Context, userID uuid.UUID, req *dto.TwoFactorCodeRequest, client dto.ClientInfo) (*dto.RecoveryCodesResponse, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	codes, err := s.enableTwoFactor(ctx, user, req.Code, client)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+func (s *authService) enableTwoFactor(ctx context.Context, user *model.User, code string, client dto.ClientInfo) ([]string, error) {
+	if user.TOTPEnabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+	if user.TOTPSecret == "" {
+		return nil, ErrTwoFactorSetupNotBegun
+	}
+
+	secret, err := utils.OpenTOTPSecret(user.TOTPSecret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read two-factor secret: %w", err)
+	}
+
+	var step int64
+	err = s.throttled(ctx, user, client, func() error {
+		var ok bool
+		if step, ok = utils.ValidateTOTP(secret, code, time.Now()); !ok {
+			return ErrInvalidTwoFactorCode
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	codes, records, err := newRecoveryCodes(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	enabled, err := s.twoFactor.Enable(ctx, user.ID, step, records)
+	if err != nil {
+		return nil, fmt.Errorf("failed to enable two-factor authentication: %w", err)
+	}
+	if !enabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	s.audit.Record(ctx, auditservice.Event{
+		Action:     "user.2fa_enable",
+		EntityType: "user",
+		EntityID:   user.ID.String(),
+		After:      map[string]interface{}{"totp_enabled": true},
+	})
+	return codes, nil
+}
+
+// BeginTwoFactorSetupWithChallenge lets a user who must enrol before
+// signing in start setup with the token from their password step.
+func (s *authService) BeginTwoFactorSetupWithChallenge(ctx context.Context, req *dto.TwoFactorChallengeRequest) (*dto.TwoFactorSetupResponse, error) {
+	user, err := s.userForChallenge(req.ChallengeToken, utils.ChallengeEnroll)
+	if err != nil {
+		return nil, err
+	}
+	return s.beginTwoFactorSetup(ctx, user)
+}
+
+// EnableTwoFactorWithChallenge confirms setup started with a challenge and
+// signs the user in.
+func (s *authService) EnableTwoFactorWithChallenge(ctx context.Context, req *dto.TwoFactorLoginRequest, client dto.ClientInfo) (*dto.TwoFactorEnrolledResponse, error) {
+	user, err := s.userForChallenge(req.ChallengeToken, utils.ChallengeEnroll)
+	if err != nil {
+		return nil, err
+	}
+	codes, err := s.enableTwoFactor(ctx, user, req.Code, client)
+	if err != nil {
+		return nil, err
+	}
+
+	authResponse, err := s.startSignedInSession(ctx, user, client)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.TwoFactorEnrolledResponse{AuthResponse: authResponse, RecoveryCodes: codes}, nil
+}
+
+// DisableTwoFactor needs both the password and a second factor, and isn't
+// allowed for users the policy requires it of.
+func (s *authService) DisableTwoFactor(ctx context.Context, userID uuid.UUID, req *dto.DisableTwoFactorRequest, client dto.ClientInfo) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return ErrUserNotFound
+	}
+	if !user.TOTPEnabled {
+		return ErrTwoFactorNotEnabled
+	}
+
+	required, err := s.twoFactorRequired(ctx, user)
+	if err != nil {
+		return err
+	}
+	if required {
+		return ErrTwoFactorRequired
+	}
+
+	err = s.throttled(ctx, user, client, func() error {
+		if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
+			return ErrInvalidCredentials
+		}
+		return s.checkSecondFactor(ctx, user, req.Code)
+	})
+	if err != nil {
+		return err
+	}
+	if err := s.twoFactor.Disable(ctx, user.ID); err != nil {
+		return fmt.Errorf("failed to disable two-factor authentication: %w", err)
+	}
+
+	s.audit.Record(ctx, auditservice.Event{
+		Action:     "user.2fa_disable",
+		EntityType: "user",
+		EntityID:   user.ID.String(),
+		Before:     map[string]interface{}{"totp_enabled": true},
+		After:      map[string]interface{}{"totp_enabled": false},
+	})
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces all of the user's recovery codes.
+func (s *authService) RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, req *dto.TwoFactorCodeRequest, client dto.ClientInfo) (*dto.RecoveryCodesResponse, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	if !user.TOTPEnabled {
+		return nil, ErrTwoFactorNotEnabled
+	}
+	err = s.throttled(ctx, user, client, func() error {
+		return s.checkSecondFactor(ctx, user, req.Code)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	codes, records, err := newRecoveryCodes(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.twoFactor.ReplaceRecoveryCodes(ctx, user.ID, records); err != nil {
+		return nil, fmt.Errorf("failed to save recovery codes: %w", err)
+	}
+
+	s.audit.Record(ctx, auditservice.Event{
+		Action:     "user.2fa_recovery_codes_regenerate",
+		EntityType: "user",
+		EntityID:   user.ID.String(),
+		After:      map[string]interface{}{"recovery_codes": len(codes)},
+	})
+	return &dto.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+func (s *authService) GetTwoFactorStatus(ctx context.Context, userID uuid.UUID) (*dto.TwoFactorStatusResponse, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	required, err := s.twoFactorRequired(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	status := &dto.TwoFactorStatusResponse{
+		Enabled:   user.TOTPEnabled,
+		EnabledAt: user.TOTPEnabledAt,
+		Required:  required,
+	}
+	if user.TOTPEnabled {
+		status.RecoveryCodesRemaining, err = s.twoFactor.CountUnusedRecoveryCodes(ctx, user.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count recovery codes: %w", err)
+		}
+	}
+	return status, nil
+}
+
+// ResetTwoFactor turns off two-factor authentication for a user who lost
+// their authenticator app and recovery codes. If the policy requires it
+// they enrol again at their next sign-in.
+func (s *authService) ResetTwoFactor(ctx context.Context, userID uuid.UUID) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return ErrUserNotFound
+	}
+	if !user.TOTPEnabled && user.TOTPSecret == "" {
+		return ErrTwoFactorNotEnabled
+	}
+
+	if err := s.twoFactor.Disable(ctx, user.ID); err != nil {
+		return fmt.Errorf("failed to reset two-factor authentication: %w", err)
+	}
+
+	s.audit.Record(ctx, auditservice.Event{
+		Action:     "user.2fa_reset",
+		EntityType: "user",
+		EntityID:   user.ID.String(),
+		Before:     map[string]interface{}{"totp_enabled": user.TOTPEnabled},
+		After:      map[string]interface{}{"totp_enabled": false},
+	})
+	return nil
+}
+
+func (s *authService) GetTwoFactorPolicy(ctx context.Context) (*model.TwoFactorPolicy, error) {
+	policy, err := s.twoFactor.GetPolicy(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get two-factor policy: %w", err)
+	}
+	return policy, nil
+}
+
+// UpdateTwoFactorPolicy sets who must use two-factor authentication. It
+// applies from their next sign-in.
+func (s *authService) UpdateTwoFactorPolicy(ctx context.Context, adminID uuid.UUID, req *dto.TwoFactorPolicyRequest) (*model.TwoFactorPolicy, error) {
+	policy, err := s.GetTwoFactorPolicy(ctx)
+	if err != nil {
+		return nil, err
+	}
+	before := auditedPolicyFields(policy)
+
+	policy.RequireForAdmins = req.RequireForAdmins
+	policy.BuyerCreditThreshold = req.BuyerCreditThreshold
+	policy.UpdatedBy = &adminID
+	policy.UpdatedAt = time.Now()
+	if err := s.twoFactor.SavePolicy(ctx, policy); err != nil {
+		return nil, fmt.Errorf("failed to save two-factor policy: %w", err)
+	}
+
+	s.audit.Record(ctx, auditservice.Event{
+		Action:     "two_factor_policy.update",
+		EntityType: "two_factor_policy",
+		EntityID:   fmt.Sprint(policy.ID),
+		Before:     before,
+		After:      auditedPolicyFields(policy),
+	})
+	return policy, nil
+}
+
+func auditedPolicyFields(policy *model.TwoFactorPolicy) map[string]interface{} {
+	return map[string]interface{}{
+		"require_for_admins":     policy.RequireForAdmins,
+		"buyer_credit_threshold": policy.BuyerCreditThreshold,
+	}
+}
+
+// newRecoveryCodes returns a fresh set of recovery codes to show the user
+// and their hashes to store.
+func newRecoveryCodes(userID uuid.UUID) ([]string, []*model.RecoveryCode, error) {
+	codes := make([]string, RecoveryCodeCount)
+	records := make([]*model.RecoveryCode, RecoveryCodeCount)
+	now := time.Now()
+	for i := range codes {
+		code, err := utils.GenerateRecoveryCode()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+		codes[i] = code
+		records[i] = &model.RecoveryCode{
+			ID:        uuid.New(),
+			UserID:    userID,
+			CodeHash:  utils.HashRecoveryCode(code),
+			CreatedAt: now,
+		}
+	}
+	return codes, records, nil
+}
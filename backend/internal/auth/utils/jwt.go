@@ -15,6 +15,28 @@ var (
 	ErrTokenExpired = errors.New("token expired")
 )
 
+// Token types, so that a refresh token can't be used to call the API and an
+// access token can't be used to refresh.
+const (
+	TokenTypeAccess    = "access"
+	TokenTypeRefresh   = "refresh"
+	TokenTypeChallenge = "2fa_challenge"
+)
+
+// What a two-factor challenge token lets the holder do: sign in with a
+// code, or enrol first when two-factor authentication is required of them.
+const (
+	ChallengeVerify = "verify"
+	ChallengeEnroll = "enroll"
+)
+
+// ChallengeTokenDuration is how long a user has to enter their code after
+// their password.
+const ChallengeTokenDuration = 5 * time.Minute
+
+// RefreshTokenDuration is how long a session lasts without being refreshed.
+const RefreshTokenDuration = 7 * 24 * time.Hour
+
 type JWTManager struct {
 	secretKey     string
 	tokenDuration time.Duration
@@ -22,9 +44,24 @@ type JWTManager struct {
 
 type Claims struct {
 	jwt.RegisteredClaims
-	UserID uuid.UUID      `json:"user_id"`
-	Email  string         `json:"email"`
-	Role   model.UserRole `json:"role"`
+	UserID    uuid.UUID      `json:"user_id"`
+	Email     string         `json:"email"`
+	Role      model.UserRole `json:"role"`
+	TokenType string         `json:"typ"`
+	SessionID uuid.UUID      `json:"sid"`
+
+	// Set on impersonation tokens only
+	Act      *Actor `json:"act,omitempty"`
+	ReadOnly bool   `json:"read_only,omitempty"`
+
+	// Set on two-factor challenge tokens only
+	Challenge string `json:"chl,omitempty"`
+}
+
+// Actor is the admin behind an impersonation token, after the "act" claim
+// of RFC 8693.
+type Actor struct {
+	Subject uuid.UUID `json:"sub"`
 }
 
 func NewJWTManager(secretKey string, tokenDuration time.Duration) *JWTManager {
@@ -34,7 +71,7 @@ func NewJWTManager(secretKey string, tokenDuration time.Duration) *JWTManager {
 	}
 }
 
-func (m *JWTManager) GenerateAccessToken(user *model.User) (string, time.Time, error) {
+func (m *JWTManager) GenerateAccessToken(user *model.User, sessionID uuid.UUID) (string, time.Time, error) {
 	expiresAt := time.Now().Add(m.tokenDuration)
 
 	claims := &Claims{
@@ -43,9 +80,11 @@ func (m *JWTManager) GenerateAccessToken(user *model.User) (string, time.Time, e
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			Subject:   user.ID.String(),
 		},
-		UserID: user.ID,
-		Email:  user.Email,
-		Role:   user.Role,
+		UserID:    user.ID,
+		Email:     user.Email,
+		Role:      user.Role,
+		TokenType: TokenTypeAccess,
+		SessionID: sessionID,
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -57,28 +96,98 @@ func (m *JWTManager) GenerateAccessToken(user *model.User) (string, time.Time, e
 	return tokenString, expiresAt, nil
 }
 
-func (m *JWTManager) GenerateRefreshToken(userID uuid.UUID) (string, error) {
-	expiresAt := time.Now().Add(24 * 7 * time.Hour) // 7 days
+// GenerateImpersonationToken issues an access token for a user that names
+// the admin acting as them. It lasts until expiresAt rather than the usual
+// token duration.
+func (m *JWTManager) GenerateImpersonationToken(user *model.User, sessionID, impersonatorID uuid.UUID, readOnly bool, expiresAt time.Time) (string, error) {
+	claims := &Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			Subject:   user.ID.String(),
+		},
+		UserID:    user.ID,
+		Email:     user.Email,
+		Role:      user.Role,
+		TokenType: TokenTypeAccess,
+		SessionID: sessionID,
+		Act:       &Actor{Subject: impersonatorID},
+		ReadOnly:  readOnly,
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(m.secretKey))
+}
+
+// GenerateRefreshToken issues a refresh token for a session. tokenID is
+// recorded on the session so that only its latest refresh token is accepted.
+func (m *JWTManager) GenerateRefreshToken(userID, sessionID uuid.UUID, tokenID string) (string, time.Time, error) {
+	expiresAt := time.Now().Add(RefreshTokenDuration)
 
 	claims := &Claims{
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenID,
 			ExpiresAt: jwt.NewNumericDate(expiresAt),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			Subject:   userID.String(),
 		},
-		UserID: userID,
+		UserID:    userID,
+		TokenType: TokenTypeRefresh,
+		SessionID: sessionID,
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	tokenString, err := token.SignedString([]byte(m.secretKey))
 	if err != nil {
-		return "", err
+		return "", time.Time{}, err
 	}
 
-	return tokenString, nil
+	return tokenString, expiresAt, nil
 }
 
-func (m *JWTManager) ValidateToken(tokenString string) (*Claims, error) {
+// GenerateChallengeToken issues the token a user trades for a session once
+// they pass the second factor. It can't be used to call the API.
+func (m *JWTManager) GenerateChallengeToken(userID uuid.UUID, challenge string) (string, time.Time, error) {
+	expiresAt := time.Now().Add(ChallengeTokenDuration)
+
+	claims := &Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			Subject:   userID.String(),
+		},
+		UserID:    userID,
+		TokenType: TokenTypeChallenge,
+		Challenge: challenge,
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	tokenString, err := token.SignedString([]byte(m.secretKey))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return tokenString, expiresAt, nil
+}
+
+// ValidateChallengeToken validates a two-factor challenge token issued for
+// the given step.
+func (m *JWTManager) ValidateChallengeToken(tokenString, challenge string) (*Claims, error) {
+	claims, err := m.validateToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.TokenType != TokenTypeChallenge || claims.Challenge != challenge || claims.UserID == uuid.Nil || claims.Act != nil {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// validateToken checks the signature and expiry only. Callers go through
+// ValidateTokenOfType or ValidateChallengeToken, so a token is never
+// accepted for a use it wasn't issued for.
+func (m *JWTManager) validateToken(tokenString string) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
@@ -102,3 +211,20 @@ func (m *JWTManager) ValidateToken(tokenString string) (*Claims, error) {
 
 	return nil, ErrInvalidToken
 }
+
+// ValidateTokenOfType validates a token and checks it was issued for the
+// given use. Tokens from before token types existed have none and fail.
+// Only access tokens can name an impersonating admin.
+func (m *JWTManager) ValidateTokenOfType(tokenString, tokenType string) (*Claims, error) {
+	claims, err := m.validateToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.TokenType != tokenType || claims.SessionID == uuid.Nil {
+		return nil, ErrInvalidToken
+	}
+	if claims.Act != nil && tokenType != TokenTypeAccess {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"errors"
+	"os"
+	"regexp"
+	"strings"
+)
+
+var ErrInvalidPhone = errors.New("invalid phone number")
+
+var (
+	phonePattern       = regexp.MustCompile(`^\+?[0-9]{7,15}$`)
+	e164Pattern        = regexp.MustCompile(`^\+[1-9][0-9]{7,14}$`)
+	countryCodePattern = regexp.MustCompile(`^[1-9][0-9]{0,2}$`)
+)
+
+// DefaultCountryCode is the calling code assumed for numbers written
+// without one, set with PHONE_COUNTRY_CODE.
+func DefaultCountryCode() string {
+	if code := strings.TrimPrefix(os.Getenv("PHONE_COUNTRY_CODE"), "+"); countryCodePattern.MatchString(code) {
+		return code
+	}
+	return "254"
+}
+
+// NormalizePhone turns a phone number into E.164 form, e.g. +254712345678,
+// so the same number is always stored and looked up the same way. It strips
+// the spaces, dashes, dots and brackets people write numbers with, reads a
+// leading 00 as +, and puts numbers written nationally ("0712 345 678" or
+// "712345678") under the default country code.
+func NormalizePhone(phone string) (string, error) {
+	normalized := strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '.', '(', ')':
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(phone))
+
+	if !phonePattern.MatchString(normalized) {
+		return "", ErrInvalidPhone
+	}
+
+	countryCode := DefaultCountryCode()
+	switch {
+	case strings.HasPrefix(normalized, "+"):
+	case strings.HasPrefix(normalized, "00"):
+		normalized = "+" + normalized[2:]
+	case strings.HasPrefix(normalized, "0"):
+		normalized = "+" + countryCode + normalized[1:]
+	case strings.HasPrefix(normalized, countryCode) && len(normalized) > 10:
+		// Written in full without the +
+		normalized = "+" + normalized
+	default:
+		normalized = "+" + countryCode + normalized
+	}
+
+	if !e164Pattern.MatchString(normalized) {
+		return "", ErrInvalidPhone
+	}
+	return normalized, nil
+}
@@ -0,0 +1,39 @@
+package utils
+
+import "testing"
+
+func TestNormalizePhone(t *testing.T) {
+	t.Setenv("PHONE_COUNTRY_CODE", "254")
+
+	tests := []struct {
+		in, want string
+	}{
+		{"+254 712 345 678", "+254712345678"},
+		{"0712-345-678", "+254712345678"},
+		{"712345678", "+254712345678"},
+		{"254712345678", "+254712345678"},
+		{"00254712345678", "+254712345678"},
+		{"(+977) 98-1234-5678", "+9779812345678"},
+	}
+	for _, tt := range tests {
+		got, err := NormalizePhone(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("NormalizePhone(%q) = %q, %v; want %q", tt.in, got, err, tt.want)
+		}
+	}
+
+	for _, in := range []string{"", "12345", "+0712345678", "07123a5678", "+1234567890123456"} {
+		if got, err := NormalizePhone(in); err == nil {
+			t.Errorf("NormalizePhone(%q) = %q; want an error", in, got)
+		}
+	}
+}
+
+func TestNormalizePhoneDefaultCountryCode(t *testing.T) {
+	t.Setenv("PHONE_COUNTRY_CODE", "+977")
+
+	got, err := NormalizePhone("098 1234 5678")
+	if err != nil || got != "+9779812345678" {
+		t.Errorf("NormalizePhone = %q, %v; want +9779812345678", got, err)
+	}
+}
@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSealTOTPSecret(t *testing.T) {
+	t.Setenv("TOTP_ENCRYPTION_KEY", "server key")
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sealed, err := SealTOTPSecret(secret)
+	if err != nil {
+		t.Fatalf("SealTOTPSecret: %v", err)
+	}
+	if !strings.HasPrefix(sealed, SealedTOTPPrefix) || strings.Contains(sealed, secret) {
+		t.Fatalf("sealed = %q", sealed)
+	}
+	// Each sealing uses a fresh nonce
+	if again, _ := SealTOTPSecret(secret); again == sealed {
+		t.Error("sealing twice gave the same value")
+	}
+
+	opened, err := OpenTOTPSecret(sealed)
+	if err != nil || opened != secret {
+		t.Fatalf("OpenTOTPSecret = %q, %v, want %q", opened, err, secret)
+	}
+
+	t.Setenv("TOTP_ENCRYPTION_KEY", "another key")
+	if _, err := OpenTOTPSecret(sealed); !errors.Is(err, ErrSealedTOTPSecret) {
+		t.Errorf("open with the wrong key = %v, want ErrSealedTOTPSecret", err)
+	}
+}
+
+func TestOpenTOTPSecretInTheClear(t *testing.T) {
+	// Secrets saved before sealing still work until they are migrated
+	if opened, err := OpenTOTPSecret("JBSWY3DPEHPK3PXP"); err != nil || opened != "JBSWY3DPEHPK3PXP" {
+		t.Errorf("OpenTOTPSecret = %q, %v", opened, err)
+	}
+	if _, err := OpenTOTPSecret(SealedTOTPPrefix + "!!"); !errors.Is(err, ErrSealedTOTPSecret) {
+		t.Errorf("open of a damaged value = %v, want ErrSealedTOTPSecret", err)
+	}
+}
@@ -1,19 +1,21 @@
 package routes
 
 import (
+	auditservice "agro_konnect/internal/audit/service"
 	"agro_konnect/internal/auth/middleware"
 	"agro_konnect/internal/buyer/handler"
 	"agro_konnect/internal/buyer/repository"
 	"agro_konnect/internal/buyer/service"
+	emailservice "agro_konnect/internal/email/service"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
-func SetupBuyerRoutes(router *gin.RouterGroup, db *gorm.DB, authMiddleware *middleware.AuthMiddleware) {
+func SetupBuyerRoutes(router *gin.RouterGroup, db *gorm.DB, authMiddleware *middleware.AuthMiddleware, auditRecorder auditservice.Recorder, mailer emailservice.Mailer) {
 	// Initialize buyer dependencies
 	buyerRepo := repository.NewBuyerRepository(db)
-	buyerService := service.NewBuyerService(buyerRepo)
+	buyerService := service.NewBuyerService(buyerRepo, auditRecorder, mailer)
 	buyerHandler := handler.NewBuyerHandler(buyerService)
 
 	buyerRoutes := router.Group("/buyers")
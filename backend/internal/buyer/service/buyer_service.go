@@ -4,11 +4,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log"
 	"time"
 
+	auditservice "agro_konnect/internal/audit/service"
 	dto "agro_konnect/internal/buyer/dto"
 	model "agro_konnect/internal/buyer/model"
 	"agro_konnect/internal/buyer/repository"
+	emailservice "agro_konnect/internal/email/service"
 	"errors"
 
 	"github.com/google/uuid"
@@ -38,11 +41,15 @@ type BuyerService interface {
 
 type buyerService struct {
 	buyerRepo repository.BuyerRepository
+	audit     auditservice.Recorder
+	mailer    emailservice.Mailer
 }
 
-func NewBuyerService(buyerRepo repository.BuyerRepository) BuyerService {
+func NewBuyerService(buyerRepo repository.BuyerRepository, audit auditservice.Recorder, mailer emailservice.Mailer) BuyerService {
 	return &buyerService{
 		buyerRepo: buyerRepo,
+		audit:     audit,
+		mailer:    mailer,
 	}
 }
 
@@ -256,11 +263,55 @@ func (s *buyerService) GetAllBuyers(ctx context.Context, filters dto.BuyerFilter
 }
 
 func (s *buyerService) VerifyBuyer(ctx context.Context, buyerID uuid.UUID) error {
-	return s.buyerRepo.UpdateVerificationStatus(ctx, buyerID, true)
+	buyer, err := s.buyerRepo.FindByID(ctx, buyerID)
+	if err != nil {
+		return err
+	}
+	if buyer == nil {
+		return ErrBuyerNotFound
+	}
+
+	if err := s.buyerRepo.UpdateVerificationStatus(ctx, buyerID, true); err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, auditservice.Event{
+		Action:     "buyer.verify",
+		EntityType: "buyer",
+		EntityID:   buyerID.String(),
+		Before:     map[string]interface{}{"is_verified": buyer.IsVerified},
+		After:      map[string]interface{}{"is_verified": true},
+	})
+
+	if !buyer.IsVerified {
+		if err := s.mailer.SendProfileVerified(ctx, buyer.UserID, emailservice.ProfileBuyer, buyer.ContactPerson); err != nil {
+			log.Printf("failed to send verification email for buyer %s: %v", buyerID, err)
+		}
+	}
+	return nil
 }
 
 func (s *buyerService) UpdatePremiumStatus(ctx context.Context, buyerID uuid.UUID, premium bool) error {
-	return s.buyerRepo.UpdatePremiumStatus(ctx, buyerID, premium)
+	buyer, err := s.buyerRepo.FindByID(ctx, buyerID)
+	if err != nil {
+		return err
+	}
+	if buyer == nil {
+		return ErrBuyerNotFound
+	}
+
+	if err := s.buyerRepo.UpdatePremiumStatus(ctx, buyerID, premium); err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, auditservice.Event{
+		Action:     "buyer.premium",
+		EntityType: "buyer",
+		EntityID:   buyerID.String(),
+		Before:     map[string]interface{}{"is_premium": buyer.IsPremium},
+		After:      map[string]interface{}{"is_premium": premium},
+	})
+	return nil
 }
 
 func (s *buyerService) UpdateBuyerRating(ctx context.Context, buyerID uuid.UUID, rating float64) error {
@@ -274,7 +325,27 @@ func (s *buyerService) UpdateCreditLimit(ctx context.Context, buyerID uuid.UUID,
 	if creditLimit < 0 {
 		return errors.New("credit limit cannot be negative")
 	}
-	return s.buyerRepo.UpdateCreditLimit(ctx, buyerID, creditLimit)
+
+	buyer, err := s.buyerRepo.FindByID(ctx, buyerID)
+	if err != nil {
+		return err
+	}
+	if buyer == nil {
+		return ErrBuyerNotFound
+	}
+
+	if err := s.buyerRepo.UpdateCreditLimit(ctx, buyerID, creditLimit); err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, auditservice.Event{
+		Action:     "buyer.credit_limit",
+		EntityType: "buyer",
+		EntityID:   buyerID.String(),
+		Before:     map[string]interface{}{"credit_limit": buyer.CreditLimit},
+		After:      map[string]interface{}{"credit_limit": creditLimit},
+	})
+	return nil
 }
 
 func (s *buyerService) GetBuyerStats(ctx context.Context, buyerID uuid.UUID) (*dto.BuyerStatsResponse, error) {
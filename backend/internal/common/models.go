@@ -24,15 +24,21 @@ type Address struct {
 	Longitude float64 `json:"longitude"`
 }
 
-// File attachment model
+// File attachment model; one row per upload. Identical files share a
+// storage key.
 type FileAttachment struct {
 	ID         uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
 	EntityType string    `gorm:"not null" json:"entity_type"` // user, farmer, product, etc.
-	EntityID   uuid.UUID `gorm:"not null" json:"entity_id"`
+	EntityID   uuid.UUID `gorm:"not null" json:"entity_id"`   // uuid.Nil until a record is known at upload
 	FileName   string    `gorm:"not null" json:"file_name"`
-	FileURL    string    `gorm:"not null" json:"file_url"`
+	FileURL    string    `gorm:"not null" json:"file_url"` // stable API URL; redirects to a signed one
 	FileType   string    `gorm:"not null" json:"file_type"`
 	FileSize   int64     `json:"file_size"`
+	Width      int       `json:"width,omitempty"` // images only
+	Height     int       `json:"height,omitempty"`
+	StorageKey string    `gorm:"index" json:"-"`
+	Checksum   string    `json:"checksum"` // SHA-256 of the content
+	Public     bool      `json:"public"`   // anyone may fetch it, e.g. product images
 	UploadedBy uuid.UUID `gorm:"not null" json:"uploaded_by"`
 	CreatedAt  time.Time `json:"created_at"`
 }
@@ -0,0 +1,24 @@
+package dto
+
+import (
+	model "agro_konnect/internal/email/model"
+)
+
+// Request DTOs
+type EmailFilterRequest struct {
+	Status    string `form:"status" validate:"omitempty,oneof=pending sending sent failed"`
+	Template  string `form:"template"`
+	ToAddress string `form:"to"`
+	UserID    string `form:"user_id" validate:"omitempty,uuid"`
+	Page      int    `form:"page" validate:"omitempty,min=1"`
+	PageSize  int    `form:"page_size" validate:"omitempty,min=1,max=100"`
+}
+
+// Response DTOs
+type EmailListResponse struct {
+	Emails  []*model.OutboundEmail `json:"emails"`
+	Total   int64                  `json:"total"`
+	Page    int                    `json:"page"`
+	Pages   int                    `json:"pages"`
+	HasMore bool                   `json:"has_more"`
+}
@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	dto "agro_konnect/internal/email/dto"
+	"agro_konnect/internal/email/service"
+	"agro_konnect/internal/email/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type EmailHandler struct {
+	emailService service.EmailService
+}
+
+func NewEmailHandler(emailService service.EmailService) *EmailHandler {
+	return &EmailHandler{emailService: emailService}
+}
+
+// GetEmails lists outgoing emails and their delivery status (admin only)
+// @Summary List emails
+// @Description List emails in the outbox, latest first, filtered by delivery status, template, recipient or user (admin only)
+// @Tags email
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "Delivery status" Enums(pending, sending, sent, failed)
+// @Param template query string false "Template, e.g. order_confirmation"
+// @Param to query string false "Recipient address"
+// @Param user_id query string false "Recipient user ID"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Success 200 {object} utils.SuccessResponse{data=dto.EmailListResponse} "Emails retrieved successfully"
+// @Failure 400 {object} utils.ErrorResponse "Invalid filters"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 403 {object} utils.ErrorResponse "Forbidden"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /admin/emails [get]
+func (h *EmailHandler) GetEmails(c *gin.Context) {
+	var filters dto.EmailFilterRequest
+	if err := c.ShouldBindQuery(&filters); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid query parameters: "+err.Error())
+		return
+	}
+	if err := utils.ValidateStruct(filters); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	emails, err := h.emailService.GetEmails(c.Request.Context(), filters)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to get emails")
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, "Emails retrieved successfully", emails)
+}
+
+// GetEmail shows one outgoing email with its content (admin only)
+// @Summary Get email
+// @Description Get an email from the outbox with its rendered content, attempts and last error (admin only)
+// @Tags email
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Email ID"
+// @Success 200 {object} utils.SuccessResponse{data=model.OutboundEmail} "Email retrieved successfully"
+// @Failure 400 {object} utils.ErrorResponse "Invalid email ID"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 403 {object} utils.ErrorResponse "Forbidden"
+// @Failure 404 {object} utils.ErrorResponse "Email not found"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /admin/emails/{id} [get]
+func (h *EmailHandler) GetEmail(c *gin.Context) {
+	emailID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid email ID")
+		return
+	}
+
+	email, err := h.emailService.GetEmail(c.Request.Context(), emailID)
+	if err != nil {
+		if errors.Is(err, service.ErrEmailNotFound) {
+			utils.RespondWithError(c, http.StatusNotFound, err.Error())
+			return
+		}
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to get email")
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, "Email retrieved successfully", email)
+}
+
+// RetryEmail sends a failed email again (admin only)
+// @Summary Retry email
+// @Description Queue an email that failed for another round of delivery attempts (admin only)
+// @Tags email
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Email ID"
+// @Success 200 {object} utils.SuccessResponse "Email queued for delivery"
+// @Failure 400 {object} utils.ErrorResponse "Invalid email ID"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 403 {object} utils.ErrorResponse "Forbidden"
+// @Failure 404 {object} utils.ErrorResponse "Email not found"
+// @Failure 409 {object} utils.ErrorResponse "Email has not failed"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /admin/emails/{id}/retry [post]
+func (h *EmailHandler) RetryEmail(c *gin.Context) {
+	emailID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid email ID")
+		return
+	}
+
+	if err := h.emailService.Retry(c.Request.Context(), emailID); err != nil {
+		switch {
+		case errors.Is(err, service.ErrEmailNotFound):
+			utils.RespondWithError(c, http.StatusNotFound, err.Error())
+		case errors.Is(err, service.ErrEmailNotFailed):
+			utils.RespondWithError(c, http.StatusConflict, err.Error())
+		default:
+			utils.RespondWithError(c, http.StatusInternalServerError, "Failed to retry email")
+		}
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, "Email queued for delivery", nil)
+}
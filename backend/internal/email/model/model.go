@@ -0,0 +1,42 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Status string
+
+const (
+	StatusPending Status = "pending"
+	StatusSending Status = "sending" // claimed by the dispatcher
+	StatusSent    Status = "sent"
+	StatusFailed  Status = "failed" // gave up; an admin can retry it
+)
+
+// OutboundEmail is an email in the outbox. It is rendered when queued, so
+// what is sent is what was true at the time, and the dispatcher delivers it
+// in the background, retrying with backoff until it is sent or gives up.
+type OutboundEmail struct {
+	ID            uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
+	UserID        *uuid.UUID `gorm:"type:uuid;index" json:"user_id,omitempty"`
+	ToAddress     string     `gorm:"not null" json:"to_address"`
+	Template      string     `gorm:"type:varchar(50);not null;index" json:"template"`
+	Locale        string     `gorm:"type:varchar(10);not null" json:"locale"`
+	Subject       string     `gorm:"not null" json:"subject"`
+	TextBody      string     `gorm:"type:text;not null" json:"text_body"`
+	HTMLBody      string     `gorm:"type:text" json:"html_body"`
+	MessageID     string     `gorm:"not null" json:"message_id"`
+	Status        Status     `gorm:"type:varchar(20);not null;default:'pending';index:idx_email_outbox_due" json:"status"`
+	Attempts      int        `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt time.Time  `gorm:"index:idx_email_outbox_due" json:"next_attempt_at"`
+	LastError     string     `gorm:"type:text" json:"last_error,omitempty"`
+	SentAt        *time.Time `json:"sent_at,omitempty"`
+	CreatedAt     time.Time  `gorm:"index" json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+func (OutboundEmail) TableName() string {
+	return "email_outbox"
+}
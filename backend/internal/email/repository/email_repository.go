@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	dto "agro_konnect/internal/email/dto"
+	model "agro_konnect/internal/email/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Recipient is where a user's email goes and the language it is written in.
+type Recipient struct {
+	Email  string
+	Locale string
+}
+
+type EmailRepository interface {
+	Create(ctx context.Context, email *model.OutboundEmail) error
+	FindByID(ctx context.Context, id uuid.UUID) (*model.OutboundEmail, error)
+	FindAll(ctx context.Context, filters dto.EmailFilterRequest) ([]*model.OutboundEmail, int64, error)
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*model.OutboundEmail, error)
+	MarkSent(ctx context.Context, id uuid.UUID, sentAt time.Time) error
+	MarkRetry(ctx context.Context, id uuid.UUID, nextAttemptAt time.Time, lastError string) error
+	MarkFailed(ctx context.Context, id uuid.UUID, lastError string) error
+	Requeue(ctx context.Context, id uuid.UUID) (bool, error)
+	DeleteSentBefore(ctx context.Context, before time.Time) (int64, error)
+	FindRecipient(ctx context.Context, userID uuid.UUID) (*Recipient, error)
+}
+
+type emailRepository struct {
+	db *gorm.DB
+}
+
+func NewEmailRepository(db *gorm.DB) EmailRepository {
+	return &emailRepository{db: db}
+}
+
+func (r *emailRepository) Create(ctx context.Context, email *model.OutboundEmail) error {
+	return r.db.WithContext(ctx).Create(email).Error
+}
+
+func (r *emailRepository) FindByID(ctx context.Context, id uuid.UUID) (*model.OutboundEmail, error) {
+	var email model.OutboundEmail
+	err := r.db.WithContext(ctx).Where("id = ?", id).Take(&email).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &email, err
+}
+
+// FindAll lists matching emails, latest first.
+func (r *emailRepository) FindAll(ctx context.Context, filters dto.EmailFilterRequest) ([]*model.OutboundEmail, int64, error) {
+	query := r.db.WithContext(ctx).Model(&model.OutboundEmail{})
+	if filters.Status != "" {
+		query = query.Where("status = ?", filters.Status)
+	}
+	if filters.Template != "" {
+		query = query.Where("template = ?", filters.Template)
+	}
+	if filters.ToAddress != "" {
+		query = query.Where("LOWER(to_address) = LOWER(?)", filters.ToAddress)
+	}
+	if filters.UserID != "" {
+		query = query.Where("user_id = ?", filters.UserID)
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var emails []*model.OutboundEmail
+	err := query.Order("created_at DESC").
+		Offset((filters.Page - 1) * filters.PageSize).
+		Limit(filters.PageSize).
+		Find(&emails).Error
+	return emails, total, err
+}
+
+// ClaimDue takes up to limit emails that are due and counts the attempt.
+// Each claim is a lease: an email still sending when it runs out, because
+// the server stopped mid-send, is due again. Rows locked by another
+// dispatcher are skipped rather than waited for.
+func (r *emailRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*model.OutboundEmail, error) {
+	var emails []*model.OutboundEmail
+	err := r.db.WithContext(ctx).Raw(`
+		UPDATE email_outbox
+		SET status = ?, attempts = attempts + 1, next_attempt_at = ?, updated_at = NOW()
+		WHERE id IN (
+			SELECT id FROM email_outbox
+			WHERE status IN (?, ?) AND next_attempt_at <= NOW()
+			ORDER BY next_attempt_at
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		model.StatusSending, time.Now().Add(lease), model.StatusPending, model.StatusSending, limit,
+	).Scan(&emails).Error
+	return emails, err
+}
+
+func (r *emailRepository) MarkSent(ctx context.Context, id uuid.UUID, sentAt time.Time) error {
+	return r.db.WithContext(ctx).Model(&model.OutboundEmail{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":     model.StatusSent,
+			"sent_at":    sentAt,
+			"last_error": "",
+			"updated_at": time.Now(),
+		}).Error
+}
+
+func (r *emailRepository) MarkRetry(ctx context.Context, id uuid.UUID, nextAttemptAt time.Time, lastError string) error {
+	return r.db.WithContext(ctx).Model(&model.OutboundEmail{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":          model.StatusPending,
+			"next_attempt_at": nextAttemptAt,
+			"last_error":      lastError,
+			"updated_at":      time.Now(),
+		}).Error
+}
+
+func (r *emailRepository) MarkFailed(ctx context.Context, id uuid.UUID, lastError string) error {
+	return r.db.WithContext(ctx).Model(&model.OutboundEmail{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":     model.StatusFailed,
+			"last_error": lastError,
+			"updated_at": time.Now(),
+		}).Error
+}
+
+// Requeue gives a failed email a fresh set of attempts, due now. It reports
+// false if the email has not failed.
+func (r *emailRepository) Requeue(ctx context.Context, id uuid.UUID) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&model.OutboundEmail{}).
+		Where("id = ? AND status = ?", id, model.StatusFailed).
+		Updates(map[string]interface{}{
+			"status":          model.StatusPending,
+			"attempts":        0,
+			"next_attempt_at": time.Now(),
+			"updated_at":      time.Now(),
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// DeleteSentBefore removes emails delivered before a cutoff; failed ones
+// are kept for admins to look into.
+func (r *emailRepository) DeleteSentBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("status = ? AND sent_at < ?", model.StatusSent, before).
+		Delete(&model.OutboundEmail{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *emailRepository) FindRecipient(ctx context.Context, userID uuid.UUID) (*Recipient, error) {
+	var recipient Recipient
+	err := r.db.WithContext(ctx).
+		Table("users").
+		Select("email, locale").
+		Where("id = ?", userID).
+		Take(&recipient).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &recipient, err
+}
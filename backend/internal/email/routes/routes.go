@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"agro_konnect/internal/auth/middleware"
+	"agro_konnect/internal/auth/model"
+	"agro_konnect/internal/email/handler"
+	"agro_konnect/internal/email/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SetupEmailRoutes(router *gin.RouterGroup, emailService service.EmailService, authMiddleware *middleware.AuthMiddleware) {
+	emailHandler := handler.NewEmailHandler(emailService)
+
+	// Admin routes
+	emailRoutes := router.Group("/admin/emails")
+	emailRoutes.Use(authMiddleware.Authenticate(), authMiddleware.RequireRole(model.RoleAdmin))
+	{
+		emailRoutes.GET("", emailHandler.GetEmails)
+		emailRoutes.GET("/:id", emailHandler.GetEmail)
+		emailRoutes.POST("/:id/retry", emailHandler.RetryEmail)
+	}
+}
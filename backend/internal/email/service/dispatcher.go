@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	model "agro_konnect/internal/email/model"
+	"agro_konnect/internal/email/repository"
+	"agro_konnect/pkg/mailer"
+)
+
+const (
+	DefaultPollInterval = 10 * time.Second
+	DefaultMaxAttempts  = 8
+
+	dispatchBatchSize = 20
+	maxBackoff        = 6 * time.Hour
+
+	// sendLease is how long a claimed email is left alone; longer than an
+	// SMTP session may take
+	sendLease = 5 * time.Minute
+)
+
+type DispatcherConfig struct {
+	PollInterval time.Duration
+	MaxAttempts  int // attempts before an email is marked failed
+}
+
+// LoadDispatcherConfig reads EMAIL_POLL_INTERVAL_SECONDS and
+// EMAIL_MAX_ATTEMPTS from the environment, falling back to the defaults.
+func LoadDispatcherConfig() DispatcherConfig {
+	config := DispatcherConfig{
+		PollInterval: DefaultPollInterval,
+		MaxAttempts:  DefaultMaxAttempts,
+	}
+	if v, err := strconv.Atoi(os.Getenv("EMAIL_POLL_INTERVAL_SECONDS")); err == nil && v > 0 {
+		config.PollInterval = time.Duration(v) * time.Second
+	}
+	if v, err := strconv.Atoi(os.Getenv("EMAIL_MAX_ATTEMPTS")); err == nil && v > 0 {
+		config.MaxAttempts = v
+	}
+	return config
+}
+
+// Dispatcher delivers the outbox. It sends whatever is due each poll, and
+// straight away when told an email was queued. A failed attempt is retried
+// with exponential backoff until MaxAttempts; a rejection the server says
+// is permanent is not retried.
+type Dispatcher struct {
+	emailRepo repository.EmailRepository
+	sender    mailer.Sender
+	config    DispatcherConfig
+	wake      chan struct{}
+}
+
+func NewDispatcher(emailRepo repository.EmailRepository, sender mailer.Sender, config DispatcherConfig) *Dispatcher {
+	return &Dispatcher{
+		emailRepo: emailRepo,
+		sender:    sender,
+		config:    config,
+		wake:      make(chan struct{}, 1),
+	}
+}
+
+func (d *Dispatcher) Start() {
+	go d.run()
+}
+
+// Notify never blocks; a wake-up already pending covers this one too.
+func (d *Dispatcher) Notify() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (d *Dispatcher) run() {
+	ticker := time.NewTicker(d.config.PollInterval)
+	defer ticker.Stop()
+	for {
+		d.dispatch()
+		select {
+		case <-ticker.C:
+		case <-d.wake:
+		}
+	}
+}
+
+// dispatch sends batches until nothing more is due.
+func (d *Dispatcher) dispatch() {
+	ctx := context.Background()
+	for {
+		emails, err := d.emailRepo.ClaimDue(ctx, dispatchBatchSize, sendLease)
+		if err != nil {
+			log.Printf("failed to claim emails to send: %v", err)
+			return
+		}
+		for _, email := range emails {
+			d.deliver(ctx, email)
+		}
+		if len(emails) < dispatchBatchSize {
+			return
+		}
+	}
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, email *model.OutboundEmail) {
+	sendCtx, cancel := context.WithTimeout(ctx, sendLease)
+	err := d.sender.Send(sendCtx, &mailer.Message{
+		MessageID: email.MessageID,
+		To:        email.ToAddress,
+		Subject:   email.Subject,
+		Text:      email.TextBody,
+		HTML:      email.HTMLBody,
+	})
+	cancel()
+
+	switch {
+	case err == nil:
+		err = d.emailRepo.MarkSent(ctx, email.ID, time.Now())
+	case mailer.IsPermanent(err) || email.Attempts >= d.config.MaxAttempts:
+		log.Printf("giving up on %s email %s to %s after %d attempts: %v", email.Template, email.ID, email.ToAddress, email.Attempts, err)
+		err = d.emailRepo.MarkFailed(ctx, email.ID, err.Error())
+	default:
+		err = d.emailRepo.MarkRetry(ctx, email.ID, time.Now().Add(backoff(email.Attempts)), err.Error())
+	}
+	if err != nil {
+		log.Printf("failed to update status of email %s: %v", email.ID, err)
+	}
+}
+
+// backoff is the wait after a failed attempt: a minute after the first,
+// doubling each time up to maxBackoff.
+func backoff(attempts int) time.Duration {
+	if attempts > 16 {
+		return maxBackoff
+	}
+	return min(time.Minute<<max(attempts-1, 0), maxBackoff)
+}
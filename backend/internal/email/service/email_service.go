@@ -0,0 +1,237 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	dto "agro_konnect/internal/email/dto"
+	model "agro_konnect/internal/email/model"
+	"agro_konnect/internal/email/repository"
+	"agro_konnect/pkg/mailer"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrEmailNotFound     = errors.New("email not found")
+	ErrEmailNotFailed    = errors.New("only failed emails can be retried")
+	ErrRecipientNotFound = errors.New("recipient not found")
+)
+
+// Profile types for SendProfileVerified
+const (
+	ProfileFarmer      = "farmer"
+	ProfileVendor      = "vendor"
+	ProfileBuyer       = "buyer"
+	ProfileTransporter = "transporter"
+)
+
+type OrderEmailItem struct {
+	Name      string
+	Quantity  float64
+	Unit      string
+	UnitPrice float64
+	Total     float64
+}
+
+// OrderEmail is what order emails show of an order.
+type OrderEmail struct {
+	OrderNumber       string
+	PlacedAt          time.Time
+	Items             []OrderEmailItem
+	SubTotal          float64
+	Discount          float64
+	Tax               float64
+	Shipping          float64
+	Total             float64
+	ShippingAddress   string
+	EstimatedDelivery time.Time
+}
+
+type ShipmentEmail struct {
+	OrderNumber       string
+	Status            string // shipped, in_transit or delivered
+	TrackingNumber    string
+	TrackingURL       string
+	EstimatedDelivery time.Time
+	Notes             string
+}
+
+type InvoiceEmail struct {
+	OrderEmail
+	InvoiceNumber string
+	PaidAt        time.Time
+	PaymentMethod string
+	PaymentID     string
+	DepositPaid   float64 // paid before the order, and part of Total
+}
+
+// Mailer queues emails to users, in their language. Emails are rendered
+// and stored straight away and sent in the background, so a slow or
+// unreachable mail server never holds up the caller.
+type Mailer interface {
+	SendVerificationCode(ctx context.Context, userID uuid.UUID, code string, expiresAt time.Time) error
+	SendPasswordReset(ctx context.Context, userID uuid.UUID, code string, expiresAt time.Time) error
+	SendProfileVerified(ctx context.Context, userID uuid.UUID, profileType, name string) error
+	SendOrderConfirmation(ctx context.Context, userID uuid.UUID, order *OrderEmail) error
+	SendShipmentUpdate(ctx context.Context, userID uuid.UUID, shipment *ShipmentEmail) error
+	SendInvoice(ctx context.Context, userID uuid.UUID, invoice *InvoiceEmail) error
+}
+
+type EmailService interface {
+	Mailer
+	GetEmails(ctx context.Context, filters dto.EmailFilterRequest) (*dto.EmailListResponse, error)
+	GetEmail(ctx context.Context, id uuid.UUID) (*model.OutboundEmail, error)
+	Retry(ctx context.Context, id uuid.UUID) error
+}
+
+// Notifier is told when an email is queued, so it can be sent without
+// waiting for the next poll.
+type Notifier interface {
+	Notify()
+}
+
+type emailService struct {
+	emailRepo   repository.EmailRepository
+	renderer    *Renderer
+	notifier    Notifier
+	fromAddress string
+}
+
+func NewEmailService(emailRepo repository.EmailRepository, renderer *Renderer, notifier Notifier, fromAddress string) EmailService {
+	return &emailService{
+		emailRepo:   emailRepo,
+		renderer:    renderer,
+		notifier:    notifier,
+		fromAddress: fromAddress,
+	}
+}
+
+func (s *emailService) SendVerificationCode(ctx context.Context, userID uuid.UUID, code string, expiresAt time.Time) error {
+	return s.send(ctx, userID, TemplateVerificationCode, map[string]interface{}{
+		"Code":      code,
+		"ExpiresAt": expiresAt,
+	})
+}
+
+func (s *emailService) SendPasswordReset(ctx context.Context, userID uuid.UUID, code string, expiresAt time.Time) error {
+	return s.send(ctx, userID, TemplatePasswordReset, map[string]interface{}{
+		"Code":      code,
+		"ExpiresAt": expiresAt,
+	})
+}
+
+func (s *emailService) SendProfileVerified(ctx context.Context, userID uuid.UUID, profileType, name string) error {
+	return s.send(ctx, userID, TemplateProfileVerified, map[string]interface{}{
+		"ProfileType": profileType,
+		"Name":        name,
+	})
+}
+
+func (s *emailService) SendOrderConfirmation(ctx context.Context, userID uuid.UUID, order *OrderEmail) error {
+	return s.send(ctx, userID, TemplateOrderConfirmation, order)
+}
+
+func (s *emailService) SendShipmentUpdate(ctx context.Context, userID uuid.UUID, shipment *ShipmentEmail) error {
+	return s.send(ctx, userID, TemplateShipmentUpdate, shipment)
+}
+
+func (s *emailService) SendInvoice(ctx context.Context, userID uuid.UUID, invoice *InvoiceEmail) error {
+	return s.send(ctx, userID, TemplateInvoice, invoice)
+}
+
+// send renders an email in the user's language and puts it in the outbox.
+func (s *emailService) send(ctx context.Context, userID uuid.UUID, template string, data interface{}) error {
+	// Queued even if the client has gone away in the meantime
+	ctx = context.WithoutCancel(ctx)
+
+	recipient, err := s.emailRepo.FindRecipient(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get recipient: %w", err)
+	}
+	if recipient == nil {
+		return ErrRecipientNotFound
+	}
+
+	message, err := s.renderer.render(template, recipient.Locale, data)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	email := &model.OutboundEmail{
+		ID:            uuid.New(),
+		UserID:        &userID,
+		ToAddress:     recipient.Email,
+		Template:      template,
+		Locale:        message.locale,
+		Subject:       message.subject,
+		TextBody:      message.text,
+		HTMLBody:      message.html,
+		MessageID:     mailer.NewMessageID(s.fromAddress),
+		Status:        model.StatusPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if err := s.emailRepo.Create(ctx, email); err != nil {
+		return fmt.Errorf("failed to queue %s email: %w", template, err)
+	}
+
+	s.notifier.Notify()
+	return nil
+}
+
+func (s *emailService) GetEmails(ctx context.Context, filters dto.EmailFilterRequest) (*dto.EmailListResponse, error) {
+	if filters.Page < 1 {
+		filters.Page = 1
+	}
+	if filters.PageSize < 1 || filters.PageSize > 100 {
+		filters.PageSize = 20
+	}
+
+	emails, total, err := s.emailRepo.FindAll(ctx, filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get emails: %w", err)
+	}
+
+	pages := int(math.Ceil(float64(total) / float64(filters.PageSize)))
+	return &dto.EmailListResponse{
+		Emails:  emails,
+		Total:   total,
+		Page:    filters.Page,
+		Pages:   pages,
+		HasMore: filters.Page < pages,
+	}, nil
+}
+
+func (s *emailService) GetEmail(ctx context.Context, id uuid.UUID) (*model.OutboundEmail, error) {
+	email, err := s.emailRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get email: %w", err)
+	}
+	if email == nil {
+		return nil, ErrEmailNotFound
+	}
+	return email, nil
+}
+
+// Retry sends a failed email again, with a fresh set of attempts.
+func (s *emailService) Retry(ctx context.Context, id uuid.UUID) error {
+	requeued, err := s.emailRepo.Requeue(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to requeue email: %w", err)
+	}
+	if !requeued {
+		if _, err := s.GetEmail(ctx, id); err != nil {
+			return err
+		}
+		return ErrEmailNotFailed
+	}
+
+	s.notifier.Notify()
+	return nil
+}
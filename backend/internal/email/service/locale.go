@@ -0,0 +1,120 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const DefaultLocale = "en"
+
+// localeStrings are the words templates get from helper functions rather
+// than spelling out themselves.
+type localeStrings struct {
+	months   [12]string
+	at       string // between date and time
+	profiles map[string]string
+	statuses map[string]string
+	payments map[string]string
+}
+
+var locales = map[string]localeStrings{
+	"en": {
+		months: [12]string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"},
+		at:     "at",
+		profiles: map[string]string{
+			ProfileFarmer:      "farmer",
+			ProfileVendor:      "vendor",
+			ProfileBuyer:       "buyer",
+			ProfileTransporter: "transporter",
+		},
+		statuses: map[string]string{
+			"shipped":    "shipped",
+			"in_transit": "on its way",
+			"delivered":  "delivered",
+		},
+		payments: map[string]string{
+			"bank_transfer":    "Bank transfer",
+			"credit_card":      "Credit card",
+			"digital_wallet":   "Digital wallet",
+			"upi":              "UPI",
+			"cash_on_delivery": "Cash on delivery",
+		},
+	},
+	"sw": {
+		months: [12]string{"Januari", "Februari", "Machi", "Aprili", "Mei", "Juni", "Julai", "Agosti", "Septemba", "Oktoba", "Novemba", "Desemba"},
+		at:     "saa",
+		profiles: map[string]string{
+			ProfileFarmer:      "mkulima",
+			ProfileVendor:      "muuzaji",
+			ProfileBuyer:       "mnunuzi",
+			ProfileTransporter: "msafirishaji",
+		},
+		statuses: map[string]string{
+			"shipped":    "imesafirishwa",
+			"in_transit": "iko njiani",
+			"delivered":  "imewasilishwa",
+		},
+		payments: map[string]string{
+			"bank_transfer":    "Uhamisho wa benki",
+			"credit_card":      "Kadi ya mkopo",
+			"digital_wallet":   "Pochi ya kidijitali",
+			"upi":              "UPI",
+			"cash_on_delivery": "Malipo wakati wa kupokea",
+		},
+	},
+}
+
+// SupportedLocale reports whether emails can be written in a locale.
+func SupportedLocale(locale string) bool {
+	_, ok := locales[locale]
+	return ok
+}
+
+// funcs are the helpers available to a locale's templates.
+func (l localeStrings) funcs() map[string]interface{} {
+	lookup := func(m map[string]string) func(string) string {
+		return func(key string) string {
+			if word, ok := m[key]; ok {
+				return word
+			}
+			return strings.ReplaceAll(key, "_", " ")
+		}
+	}
+	date := func(t time.Time) string {
+		return fmt.Sprintf("%d %s %d", t.Day(), l.months[t.Month()-1], t.Year())
+	}
+	return map[string]interface{}{
+		"date": date,
+		"datetime": func(t time.Time) string {
+			return fmt.Sprintf("%s %s %s", date(t), l.at, t.Format("15:04 MST"))
+		},
+		"money":    formatMoney,
+		"quantity": func(q float64) string { return strconv.FormatFloat(q, 'f', -1, 64) },
+		"profile":  lookup(l.profiles),
+		"status":   lookup(l.statuses),
+		"payment":  lookup(l.payments),
+	}
+}
+
+// formatMoney writes an amount with two decimals and thousands separated,
+// e.g. 12,500.00.
+func formatMoney(amount float64) string {
+	sign := ""
+	if amount < 0 {
+		sign, amount = "-", -amount
+	}
+	cents := int64(math.Round(amount * 100))
+	whole := strconv.FormatInt(cents/100, 10)
+
+	var grouped strings.Builder
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			grouped.WriteByte(',')
+		}
+		grouped.WriteRune(digit)
+	}
+	return fmt.Sprintf("%s%s.%02d", sign, grouped.String(), cents%100)
+}
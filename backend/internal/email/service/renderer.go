@@ -0,0 +1,108 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"strings"
+	texttemplate "text/template"
+
+	"agro_konnect/internal/email/templates"
+)
+
+// Emails that can be sent
+const (
+	TemplateVerificationCode  = "verification_code"
+	TemplatePasswordReset     = "password_reset"
+	TemplateProfileVerified   = "profile_verified"
+	TemplateOrderConfirmation = "order_confirmation"
+	TemplateShipmentUpdate    = "shipment_update"
+	TemplateInvoice           = "invoice"
+)
+
+var templateNames = []string{
+	TemplateVerificationCode,
+	TemplatePasswordReset,
+	TemplateProfileVerified,
+	TemplateOrderConfirmation,
+	TemplateShipmentUpdate,
+	TemplateInvoice,
+}
+
+type emailTemplate struct {
+	text *texttemplate.Template
+	html *htmltemplate.Template
+}
+
+// rendered is an email ready to go in the outbox.
+type rendered struct {
+	locale  string
+	subject string
+	text    string
+	html    string
+}
+
+// Renderer fills in the email templates. Every template must exist in the
+// default locale; other locales may leave some out, and fall back to it.
+type Renderer struct {
+	templates map[string]map[string]*emailTemplate // locale -> name -> template
+}
+
+// NewRenderer parses every template up front, so a broken one stops the
+// server starting rather than an email going out.
+func NewRenderer() (*Renderer, error) {
+	r := &Renderer{templates: make(map[string]map[string]*emailTemplate)}
+	for locale, words := range locales {
+		r.templates[locale] = make(map[string]*emailTemplate)
+		for _, name := range templateNames {
+			if _, err := fs.Stat(templates.FS, locale+"/"+name+".txt"); err != nil {
+				if locale == DefaultLocale {
+					return nil, fmt.Errorf("email template %s is missing", name)
+				}
+				continue
+			}
+
+			text, err := texttemplate.New(name).Funcs(words.funcs()).
+				ParseFS(templates.FS, locale+"/footer.txt", locale+"/"+name+".txt")
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse %s/%s.txt: %w", locale, name, err)
+			}
+			html, err := htmltemplate.New("layout.html").Funcs(words.funcs()).
+				ParseFS(templates.FS, "layout.html", locale+"/footer.html", locale+"/"+name+".html")
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse %s/%s.html: %w", locale, name, err)
+			}
+			r.templates[locale][name] = &emailTemplate{text: text, html: html}
+		}
+	}
+	return r, nil
+}
+
+func (r *Renderer) render(name, locale string, data interface{}) (*rendered, error) {
+	tmpl, ok := r.templates[locale][name]
+	if !ok {
+		locale = DefaultLocale
+		if tmpl, ok = r.templates[locale][name]; !ok {
+			return nil, fmt.Errorf("unknown email template %s", name)
+		}
+	}
+
+	var subject, text, html bytes.Buffer
+	if err := tmpl.text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return nil, fmt.Errorf("failed to render %s subject: %w", name, err)
+	}
+	if err := tmpl.text.ExecuteTemplate(&text, "text", data); err != nil {
+		return nil, fmt.Errorf("failed to render %s text: %w", name, err)
+	}
+	if err := tmpl.html.Execute(&html, data); err != nil {
+		return nil, fmt.Errorf("failed to render %s html: %w", name, err)
+	}
+
+	return &rendered{
+		locale:  locale,
+		subject: strings.Join(strings.Fields(subject.String()), " "),
+		text:    strings.TrimSpace(text.String()) + "\n",
+		html:    html.String(),
+	}, nil
+}
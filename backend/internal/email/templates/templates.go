@@ -0,0 +1,10 @@
+// Package templates holds the email templates. Each locale has a directory
+// with, for every email, a .txt file defining "subject" and "text" and an
+// .html file defining "content", which layout.html wraps. Both formats also
+// get the locale's "footer".
+package templates
+
+import "embed"
+
+//go:embed layout.html en sw
+var FS embed.FS
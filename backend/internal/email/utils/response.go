@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type SuccessResponse struct {
+	Success bool        `json:"success"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+type ErrorResponse struct {
+	Success bool        `json:"success"`
+	Message string      `json:"message"`
+	Error   string      `json:"error,omitempty"`
+	Details interface{} `json:"details,omitempty"`
+}
+
+func RespondWithSuccess(c *gin.Context, statusCode int, message string, data interface{}) {
+	c.JSON(statusCode, SuccessResponse{
+		Success: true,
+		Message: message,
+		Data:    data,
+	})
+}
+
+func RespondWithError(c *gin.Context, statusCode int, message string) {
+	c.JSON(statusCode, ErrorResponse{
+		Success: false,
+		Message: message,
+		Error:   http.StatusText(statusCode),
+	})
+}
+
+// ValidateStruct validates a struct using go-playground/validator
+func ValidateStruct(s interface{}) error {
+	validate := validator.New()
+	return validate.Struct(s)
+}
@@ -0,0 +1,23 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Request DTOs
+type UploadDocumentRequest struct {
+	DocumentType string `form:"document_type" validate:"required,oneof=license certification land_record identity other"`
+}
+
+// Response DTOs
+type DocumentResponse struct {
+	ID           uuid.UUID `json:"id"`
+	DocumentType string    `json:"document_type"`
+	FileName     string    `json:"file_name"`
+	URL          string    `json:"url"` // signed; fetch a fresh one after url_expires_at
+	URLExpiresAt time.Time `json:"url_expires_at"`
+	Verified     bool      `json:"verified"`
+	UploadedAt   time.Time `json:"uploaded_at"`
+}
@@ -0,0 +1,150 @@
+package dto
+
+import (
+	model "agro_konnect/internal/farmer/model"
+	product "agro_konnect/internal/product/model"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Request DTOs
+
+// RecordPaymentRequest carries a paid order into the ledger. It is built by
+// the order service, not bound from a request body.
+type RecordPaymentRequest struct {
+	OrderID        uuid.UUID
+	OrderNumber    string
+	FarmerID       uuid.UUID
+	TotalAmount    float64
+	SubTotal       float64
+	DiscountAmount float64
+	Items          []LedgerOrderItem
+}
+
+type LedgerOrderItem struct {
+	ProductID  uuid.UUID
+	TotalPrice float64
+}
+
+type CommissionRuleRequest struct {
+	Category   product.ProductCategory `json:"category" validate:"omitempty,oneof=fruits vegetables grains dairy poultry livestock spices herbs"`
+	FarmerTier string                  `json:"farmer_tier" validate:"omitempty,oneof=standard premium"`
+	Rate       float64                 `json:"rate" validate:"min=0,lt=1"`
+}
+
+type CreatePayoutAccountRequest struct {
+	AccountHolderName string `json:"account_holder_name" validate:"required,min=2,max=100"`
+	BankName          string `json:"bank_name" validate:"required,min=2,max=100"`
+	AccountNumber     string `json:"account_number" validate:"required,alphanum,min=4,max=34"`
+	RoutingCode       string `json:"routing_code" validate:"required,alphanum,min=4,max=20"`
+	IsDefault         bool   `json:"is_default"`
+}
+
+type UpdatePayoutStatusRequest struct {
+	Status        model.PayoutStatus `json:"status" validate:"required,oneof=paid failed"`
+	Reference     string             `json:"reference" validate:"required_if=Status paid,max=100"`
+	FailureReason string             `json:"failure_reason" validate:"max=255"`
+}
+
+// Response DTOs
+type LedgerBalanceResponse struct {
+	FarmerID         uuid.UUID `json:"farmer_id"`
+	AvailableBalance float64   `json:"available_balance"`
+	PendingPayouts   float64   `json:"pending_payouts"`
+	GrossSales       float64   `json:"gross_sales"`
+	CommissionPaid   float64   `json:"commission_paid"`
+	Refunded         float64   `json:"refunded"`
+	PaidOut          float64   `json:"paid_out"`
+	MinimumPayout    float64   `json:"minimum_payout"`
+}
+
+type StatementLineResponse struct {
+	ID            uuid.UUID                   `json:"id"`
+	TransactionID uuid.UUID                   `json:"transaction_id"`
+	Type          model.LedgerTransactionType `json:"type"`
+	OrderID       *uuid.UUID                  `json:"order_id,omitempty"`
+	OrderNumber   string                      `json:"order_number,omitempty"`
+	PayoutID      *uuid.UUID                  `json:"payout_id,omitempty"`
+	Description   string                      `json:"description"`
+	Debit         float64                     `json:"debit"`
+	Credit        float64                     `json:"credit"`
+	Balance       float64                     `json:"balance"`
+	CreatedAt     time.Time                   `json:"created_at"`
+}
+
+type LedgerStatementResponse struct {
+	From           time.Time                `json:"from"`
+	To             time.Time                `json:"to"`
+	OpeningBalance float64                  `json:"opening_balance"`
+	ClosingBalance float64                  `json:"closing_balance"`
+	Lines          []*StatementLineResponse `json:"lines"`
+	Total          int64                    `json:"total"`
+	Page           int                      `json:"page"`
+	Pages          int                      `json:"pages"`
+	HasMore        bool                     `json:"has_more"`
+}
+
+type CommissionRuleResponse struct {
+	ID         uuid.UUID               `json:"id"`
+	Category   product.ProductCategory `json:"category"`
+	FarmerTier string                  `json:"farmer_tier"`
+	Rate       float64                 `json:"rate"`
+	UpdatedAt  time.Time               `json:"updated_at"`
+}
+
+type CommissionRulesResponse struct {
+	DefaultRate float64                   `json:"default_rate"`
+	Rules       []*CommissionRuleResponse `json:"rules"`
+}
+
+type PayoutAccountResponse struct {
+	ID                uuid.UUID `json:"id"`
+	AccountHolderName string    `json:"account_holder_name"`
+	BankName          string    `json:"bank_name"`
+	AccountNumber     string    `json:"account_number"` // masked
+	RoutingCode       string    `json:"routing_code"`
+	IsDefault         bool      `json:"is_default"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+type PayoutResponse struct {
+	ID              uuid.UUID          `json:"id"`
+	BatchID         uuid.UUID          `json:"batch_id"`
+	FarmerID        uuid.UUID          `json:"farmer_id"`
+	PayoutAccountID uuid.UUID          `json:"payout_account_id"`
+	Amount          float64            `json:"amount"`
+	Status          model.PayoutStatus `json:"status"`
+	Reference       string             `json:"reference"`
+	FailureReason   string             `json:"failure_reason,omitempty"`
+	PaidAt          *time.Time         `json:"paid_at"`
+	CreatedAt       time.Time          `json:"created_at"`
+}
+
+type PayoutListResponse struct {
+	Payouts []*PayoutResponse `json:"payouts"`
+	Total   int64             `json:"total"`
+	Page    int               `json:"page"`
+	Pages   int               `json:"pages"`
+	HasMore bool              `json:"has_more"`
+}
+
+type PayoutBatchResponse struct {
+	ID          uuid.UUID               `json:"id"`
+	Status      model.PayoutBatchStatus `json:"status"`
+	PayoutCount int                     `json:"payout_count"`
+	TotalAmount float64                 `json:"total_amount"`
+	// Farmers with a payable balance but no default payout account
+	SkippedFarmers []uuid.UUID       `json:"skipped_farmers,omitempty"`
+	Payouts        []*PayoutResponse `json:"payouts,omitempty"`
+	CreatedAt      time.Time         `json:"created_at"`
+	CompletedAt    *time.Time        `json:"completed_at"`
+}
+
+type PayoutBatchListResponse struct {
+	Batches []*PayoutBatchResponse `json:"batches"`
+	Total   int64                  `json:"total"`
+	Page    int                    `json:"page"`
+	Pages   int                    `json:"pages"`
+	HasMore bool                   `json:"has_more"`
+}
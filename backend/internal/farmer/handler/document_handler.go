@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	dto "agro_konnect/internal/farmer/dto"
+	"agro_konnect/internal/farmer/service"
+	"agro_konnect/internal/farmer/utils"
+	mediaService "agro_konnect/internal/media/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type DocumentHandler struct {
+	documentService service.DocumentService
+}
+
+func NewDocumentHandler(documentService service.DocumentService) *DocumentHandler {
+	return &DocumentHandler{documentService: documentService}
+}
+
+// UploadDocument uploads a verification document
+// @Summary Upload farmer document
+// @Description Upload a licence, certificate or other document for verification. Files are private to the farmer and admins
+// @Tags farmers
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param document_type formData string true "Document type" Enums(license, certification, land_record, identity, other)
+// @Param file formData file true "Document (PDF, JPEG or PNG, max 10MB)"
+// @Success 201 {object} utils.SuccessResponse{data=dto.DocumentResponse} "Document uploaded successfully"
+// @Failure 400 {object} utils.ErrorResponse "Invalid input data"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 404 {object} utils.ErrorResponse "Farmer profile not found"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /farmers/me/documents [post]
+func (h *DocumentHandler) UploadDocument(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	var req dto.UploadDocumentRequest
+	if err := c.ShouldBind(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid input data: "+err.Error())
+		return
+	}
+
+	if err := utils.ValidateStruct(req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Failed to get file from form data")
+		return
+	}
+
+	document, err := h.documentService.UploadDocument(c.Request.Context(), userID, &req, file)
+	if err != nil {
+		respondWithDocumentError(c, err, "Failed to upload document")
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusCreated, "Document uploaded successfully", document)
+}
+
+// GetMyDocuments lists the farmer's documents
+// @Summary Get my documents
+// @Description List the authenticated farmer's documents, newest first, with signed URLs to view them
+// @Tags farmers
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.SuccessResponse{data=[]dto.DocumentResponse} "Documents retrieved successfully"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 404 {object} utils.ErrorResponse "Farmer profile not found"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /farmers/me/documents [get]
+func (h *DocumentHandler) GetMyDocuments(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	documents, err := h.documentService.GetMyDocuments(c.Request.Context(), userID)
+	if err != nil {
+		respondWithDocumentError(c, err, "Failed to retrieve documents")
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, "Documents retrieved successfully", documents)
+}
+
+// DeleteDocument deletes one of the farmer's documents
+// @Summary Delete document
+// @Description Delete one of the authenticated farmer's documents and its file
+// @Tags farmers
+// @Produce json
+// @Security BearerAuth
+// @Param documentId path string true "Document ID"
+// @Success 200 {object} utils.SuccessResponse "Document deleted successfully"
+// @Failure 400 {object} utils.ErrorResponse "Invalid document ID"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 404 {object} utils.ErrorResponse "Document not found"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /farmers/me/documents/{documentId} [delete]
+func (h *DocumentHandler) DeleteDocument(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	documentID, err := uuid.Parse(c.Param("documentId"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid document ID")
+		return
+	}
+
+	if err := h.documentService.DeleteDocument(c.Request.Context(), userID, documentID); err != nil {
+		respondWithDocumentError(c, err, "Failed to delete document")
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, "Document deleted successfully", nil)
+}
+
+// GetFarmerDocuments lists a farmer's documents (admin only)
+// @Summary Get farmer documents
+// @Description List a farmer's documents with signed URLs, to review before verifying the farmer
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Farmer ID"
+// @Success 200 {object} utils.SuccessResponse{data=[]dto.DocumentResponse} "Documents retrieved successfully"
+// @Failure 400 {object} utils.ErrorResponse "Invalid farmer ID"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 403 {object} utils.ErrorResponse "Forbidden"
+// @Failure 404 {object} utils.ErrorResponse "Farmer not found"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /farmers/{id}/documents [get]
+func (h *DocumentHandler) GetFarmerDocuments(c *gin.Context) {
+	farmerID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid farmer ID")
+		return
+	}
+
+	documents, err := h.documentService.GetFarmerDocuments(c.Request.Context(), farmerID)
+	if err != nil {
+		respondWithDocumentError(c, err, "Failed to retrieve documents")
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, "Documents retrieved successfully", documents)
+}
+
+func respondWithDocumentError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrFarmerNotFound), errors.Is(err, service.ErrDocumentNotFound):
+		utils.RespondWithError(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, mediaService.ErrFileTooLarge), errors.Is(err, mediaService.ErrUnsupportedFileType),
+		errors.Is(err, mediaService.ErrInvalidImage), errors.Is(err, mediaService.ErrImageTooLarge):
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+	default:
+		utils.RespondWithError(c, http.StatusInternalServerError, fallback)
+	}
+}
@@ -0,0 +1,545 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	dto "agro_konnect/internal/farmer/dto"
+	"agro_konnect/internal/farmer/service"
+	"agro_konnect/internal/farmer/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type LedgerHandler struct {
+	farmerService service.FarmerService
+	ledgerService service.LedgerService
+	payoutService service.PayoutService
+}
+
+func NewLedgerHandler(
+	farmerService service.FarmerService,
+	ledgerService service.LedgerService,
+	payoutService service.PayoutService,
+) *LedgerHandler {
+	return &LedgerHandler{
+		farmerService: farmerService,
+		ledgerService: ledgerService,
+		payoutService: payoutService,
+	}
+}
+
+// GetMyBalance gets the current farmer's ledger balance
+// @Summary Get my balance
+// @Description Get the payable balance, pending payouts and lifetime totals for the authenticated farmer
+// @Tags farmer-payouts
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.SuccessResponse{data=dto.LedgerBalanceResponse} "Balance retrieved successfully"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 404 {object} utils.ErrorResponse "Farmer profile not found"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /farmers/me/balance [get]
+func (h *LedgerHandler) GetMyBalance(c *gin.Context) {
+	farmerID, ok := h.currentFarmerID(c)
+	if !ok {
+		return
+	}
+
+	balance, err := h.ledgerService.GetBalance(c.Request.Context(), farmerID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to retrieve balance")
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, "Balance retrieved successfully", balance)
+}
+
+// GetMyStatement gets the current farmer's ledger statement
+// @Summary Get my statement
+// @Description Get payable account postings with running balance for the authenticated farmer
+// @Tags farmer-payouts
+// @Produce json
+// @Security BearerAuth
+// @Param from query string false "Posted on or after (RFC3339)"
+// @Param to query string false "Posted before (RFC3339)"
+// @Param page query integer false "Page number" default(1)
+// @Param page_size query integer false "Page size" default(20)
+// @Success 200 {object} utils.SuccessResponse{data=dto.LedgerStatementResponse} "Statement retrieved successfully"
+// @Failure 400 {object} utils.ErrorResponse "Invalid date parameters"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 404 {object} utils.ErrorResponse "Farmer profile not found"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /farmers/me/statement [get]
+func (h *LedgerHandler) GetMyStatement(c *gin.Context) {
+	farmerID, ok := h.currentFarmerID(c)
+	if !ok {
+		return
+	}
+
+	var from, to time.Time
+	var err error
+	if c.Query("from") != "" {
+		from, err = time.Parse(time.RFC3339, c.Query("from"))
+		if err != nil {
+			utils.RespondWithError(c, http.StatusBadRequest, "Invalid from parameter")
+			return
+		}
+	}
+	if c.Query("to") != "" {
+		to, err = time.Parse(time.RFC3339, c.Query("to"))
+		if err != nil {
+			utils.RespondWithError(c, http.StatusBadRequest, "Invalid to parameter")
+			return
+		}
+	}
+
+	page, pageSize := parsePageParams(c, 20)
+	statement, err := h.ledgerService.GetStatement(c.Request.Context(), farmerID, from, to, page, pageSize)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to retrieve statement")
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, "Statement retrieved successfully", statement)
+}
+
+// GetMyPayouts lists the current farmer's payouts
+// @Summary Get my payouts
+// @Description Get payouts made to the authenticated farmer
+// @Tags farmer-payouts
+// @Produce json
+// @Security BearerAuth
+// @Param page query integer false "Page number" default(1)
+// @Param page_size query integer false "Page size" default(10)
+// @Success 200 {object} utils.SuccessResponse{data=dto.PayoutListResponse} "Payouts retrieved successfully"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 404 {object} utils.ErrorResponse "Farmer profile not found"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /farmers/me/payouts [get]
+func (h *LedgerHandler) GetMyPayouts(c *gin.Context) {
+	farmerID, ok := h.currentFarmerID(c)
+	if !ok {
+		return
+	}
+
+	page, pageSize := parsePageParams(c, 10)
+	payouts, err := h.payoutService.GetFarmerPayouts(c.Request.Context(), farmerID, page, pageSize)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to retrieve payouts")
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, "Payouts retrieved successfully", payouts)
+}
+
+// GetMyPayoutAccounts lists the current farmer's bank accounts
+// @Summary Get my payout accounts
+// @Description Get the bank accounts the authenticated farmer can be paid to
+// @Tags farmer-payouts
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.SuccessResponse{data=[]dto.PayoutAccountResponse} "Payout accounts retrieved successfully"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 404 {object} utils.ErrorResponse "Farmer profile not found"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /farmers/me/payout-accounts [get]
+func (h *LedgerHandler) GetMyPayoutAccounts(c *gin.Context) {
+	farmerID, ok := h.currentFarmerID(c)
+	if !ok {
+		return
+	}
+
+	accounts, err := h.payoutService.GetPayoutAccounts(c.Request.Context(), farmerID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to retrieve payout accounts")
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, "Payout accounts retrieved successfully", accounts)
+}
+
+// AddPayoutAccount adds a bank account for the current farmer
+// @Summary Add payout account
+// @Description Add a bank account to receive payouts; the first account becomes the default
+// @Tags farmer-payouts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.CreatePayoutAccountRequest true "Bank account details"
+// @Success 201 {object} utils.SuccessResponse{data=dto.PayoutAccountResponse} "Payout account added successfully"
+// @Failure 400 {object} utils.ErrorResponse "Invalid input data"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 404 {object} utils.ErrorResponse "Farmer profile not found"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /farmers/me/payout-accounts [post]
+func (h *LedgerHandler) AddPayoutAccount(c *gin.Context) {
+	farmerID, ok := h.currentFarmerID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.CreatePayoutAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid input data: "+err.Error())
+		return
+	}
+
+	if err := utils.ValidateStruct(req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	account, err := h.payoutService.AddPayoutAccount(c.Request.Context(), farmerID, &req)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to add payout account")
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusCreated, "Payout account added successfully", account)
+}
+
+// SetDefaultPayoutAccount makes a bank account the current farmer's default
+// @Summary Set default payout account
+// @Description Choose which bank account future payouts go to
+// @Tags farmer-payouts
+// @Produce json
+// @Security BearerAuth
+// @Param accountId path string true "Payout account ID"
+// @Success 200 {object} utils.SuccessResponse "Default payout account updated successfully"
+// @Failure 400 {object} utils.ErrorResponse "Invalid payout account ID"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 403 {object} utils.ErrorResponse "Forbidden"
+// @Failure 404 {object} utils.ErrorResponse "Payout account not found"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /farmers/me/payout-accounts/{accountId}/default [put]
+func (h *LedgerHandler) SetDefaultPayoutAccount(c *gin.Context) {
+	accountID, err := uuid.Parse(c.Param("accountId"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid payout account ID")
+		return
+	}
+
+	farmerID, ok := h.currentFarmerID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.payoutService.SetDefaultPayoutAccount(c.Request.Context(), farmerID, accountID); err != nil {
+		switch err {
+		case service.ErrPayoutAccountNotFound:
+			utils.RespondWithError(c, http.StatusNotFound, err.Error())
+		case service.ErrUnauthorized:
+			utils.RespondWithError(c, http.StatusForbidden, err.Error())
+		default:
+			utils.RespondWithError(c, http.StatusInternalServerError, "Failed to update default payout account")
+		}
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, "Default payout account updated successfully", nil)
+}
+
+// DeletePayoutAccount removes one of the current farmer's bank accounts
+// @Summary Delete payout account
+// @Description Remove a bank account
+// @Tags farmer-payouts
+// @Produce json
+// @Security BearerAuth
+// @Param accountId path string true "Payout account ID"
+// @Success 200 {object} utils.SuccessResponse "Payout account deleted successfully"
+// @Failure 400 {object} utils.ErrorResponse "Invalid payout account ID"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 403 {object} utils.ErrorResponse "Forbidden"
+// @Failure 404 {object} utils.ErrorResponse "Payout account not found"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /farmers/me/payout-accounts/{accountId} [delete]
+func (h *LedgerHandler) DeletePayoutAccount(c *gin.Context) {
+	accountID, err := uuid.Parse(c.Param("accountId"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid payout account ID")
+		return
+	}
+
+	farmerID, ok := h.currentFarmerID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.payoutService.DeletePayoutAccount(c.Request.Context(), farmerID, accountID); err != nil {
+		switch err {
+		case service.ErrPayoutAccountNotFound:
+			utils.RespondWithError(c, http.StatusNotFound, err.Error())
+		case service.ErrUnauthorized:
+			utils.RespondWithError(c, http.StatusForbidden, err.Error())
+		default:
+			utils.RespondWithError(c, http.StatusInternalServerError, "Failed to delete payout account")
+		}
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, "Payout account deleted successfully", nil)
+}
+
+// GetCommissionRules lists commission rules (admin only)
+// @Summary Get commission rules
+// @Description Get the default commission rate and the per-category and per-tier overrides (admin only)
+// @Tags farmer-payouts
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.SuccessResponse{data=dto.CommissionRulesResponse} "Commission rules retrieved successfully"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 403 {object} utils.ErrorResponse "Forbidden"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /farmers/commission-rules [get]
+func (h *LedgerHandler) GetCommissionRules(c *gin.Context) {
+	rules, err := h.ledgerService.GetCommissionRules(c.Request.Context())
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to retrieve commission rules")
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, "Commission rules retrieved successfully", rules)
+}
+
+// SetCommissionRule creates or updates a commission rule (admin only)
+// @Summary Set commission rule
+// @Description Set the commission rate for a product category, a farmer tier, or both (admin only)
+// @Tags farmer-payouts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.CommissionRuleRequest true "Commission rule"
+// @Success 200 {object} utils.SuccessResponse{data=dto.CommissionRuleResponse} "Commission rule saved successfully"
+// @Failure 400 {object} utils.ErrorResponse "Invalid input data"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 403 {object} utils.ErrorResponse "Forbidden"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /farmers/commission-rules [put]
+func (h *LedgerHandler) SetCommissionRule(c *gin.Context) {
+	var req dto.CommissionRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid input data: "+err.Error())
+		return
+	}
+
+	if err := utils.ValidateStruct(req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	rule, err := h.ledgerService.SetCommissionRule(c.Request.Context(), &req)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to save commission rule")
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, "Commission rule saved successfully", rule)
+}
+
+// DeleteCommissionRule removes a commission rule (admin only)
+// @Summary Delete commission rule
+// @Description Remove a commission override (admin only)
+// @Tags farmer-payouts
+// @Produce json
+// @Security BearerAuth
+// @Param ruleId path string true "Commission rule ID"
+// @Success 200 {object} utils.SuccessResponse "Commission rule deleted successfully"
+// @Failure 400 {object} utils.ErrorResponse "Invalid commission rule ID"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 403 {object} utils.ErrorResponse "Forbidden"
+// @Failure 404 {object} utils.ErrorResponse "Commission rule not found"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /farmers/commission-rules/{ruleId} [delete]
+func (h *LedgerHandler) DeleteCommissionRule(c *gin.Context) {
+	ruleID, err := uuid.Parse(c.Param("ruleId"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid commission rule ID")
+		return
+	}
+
+	if err := h.ledgerService.DeleteCommissionRule(c.Request.Context(), ruleID); err != nil {
+		switch err {
+		case service.ErrCommissionRuleNotFound:
+			utils.RespondWithError(c, http.StatusNotFound, err.Error())
+		default:
+			utils.RespondWithError(c, http.StatusInternalServerError, "Failed to delete commission rule")
+		}
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, "Commission rule deleted successfully", nil)
+}
+
+// CreatePayoutBatch pays out all eligible farmer balances (admin only)
+// @Summary Create payout batch
+// @Description Create payouts for every farmer whose payable balance has reached the minimum and who has a default payout account (admin only)
+// @Tags farmer-payouts
+// @Produce json
+// @Security BearerAuth
+// @Success 201 {object} utils.SuccessResponse{data=dto.PayoutBatchResponse} "Payout batch created successfully"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 403 {object} utils.ErrorResponse "Forbidden"
+// @Failure 409 {object} utils.ErrorResponse "Balances changed during batch creation"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /farmers/payouts/batches [post]
+func (h *LedgerHandler) CreatePayoutBatch(c *gin.Context) {
+	batch, err := h.payoutService.CreatePayoutBatch(c.Request.Context())
+	if err != nil {
+		switch err {
+		case service.ErrPayoutBatchConflict:
+			utils.RespondWithError(c, http.StatusConflict, err.Error())
+		default:
+			utils.RespondWithError(c, http.StatusInternalServerError, "Failed to create payout batch")
+		}
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusCreated, "Payout batch created successfully", batch)
+}
+
+// GetPayoutBatches lists payout batches (admin only)
+// @Summary Get payout batches
+// @Description Get payout batches, newest first (admin only)
+// @Tags farmer-payouts
+// @Produce json
+// @Security BearerAuth
+// @Param page query integer false "Page number" default(1)
+// @Param page_size query integer false "Page size" default(10)
+// @Success 200 {object} utils.SuccessResponse{data=dto.PayoutBatchListResponse} "Payout batches retrieved successfully"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 403 {object} utils.ErrorResponse "Forbidden"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /farmers/payouts/batches [get]
+func (h *LedgerHandler) GetPayoutBatches(c *gin.Context) {
+	page, pageSize := parsePageParams(c, 10)
+	batches, err := h.payoutService.GetPayoutBatches(c.Request.Context(), page, pageSize)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to retrieve payout batches")
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, "Payout batches retrieved successfully", batches)
+}
+
+// GetPayoutBatch gets a payout batch with its payouts (admin only)
+// @Summary Get payout batch
+// @Description Get a payout batch and every payout in it (admin only)
+// @Tags farmer-payouts
+// @Produce json
+// @Security BearerAuth
+// @Param batchId path string true "Payout batch ID"
+// @Success 200 {object} utils.SuccessResponse{data=dto.PayoutBatchResponse} "Payout batch retrieved successfully"
+// @Failure 400 {object} utils.ErrorResponse "Invalid payout batch ID"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 403 {object} utils.ErrorResponse "Forbidden"
+// @Failure 404 {object} utils.ErrorResponse "Payout batch not found"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /farmers/payouts/batches/{batchId} [get]
+func (h *LedgerHandler) GetPayoutBatch(c *gin.Context) {
+	batchID, err := uuid.Parse(c.Param("batchId"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid payout batch ID")
+		return
+	}
+
+	batch, err := h.payoutService.GetPayoutBatch(c.Request.Context(), batchID)
+	if err != nil {
+		switch err {
+		case service.ErrPayoutBatchNotFound:
+			utils.RespondWithError(c, http.StatusNotFound, err.Error())
+		default:
+			utils.RespondWithError(c, http.StatusInternalServerError, "Failed to retrieve payout batch")
+		}
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, "Payout batch retrieved successfully", batch)
+}
+
+// UpdatePayoutStatus marks a payout paid or failed (admin only)
+// @Summary Update payout status
+// @Description Record whether a pending payout was paid or failed; failed payouts return the money to the farmer's balance (admin only)
+// @Tags farmer-payouts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param payoutId path string true "Payout ID"
+// @Param request body dto.UpdatePayoutStatusRequest true "Payout result"
+// @Success 200 {object} utils.SuccessResponse{data=dto.PayoutResponse} "Payout updated successfully"
+// @Failure 400 {object} utils.ErrorResponse "Invalid input data"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 403 {object} utils.ErrorResponse "Forbidden"
+// @Failure 404 {object} utils.ErrorResponse "Payout not found"
+// @Failure 409 {object} utils.ErrorResponse "Payout already settled"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /farmers/payouts/{payoutId}/status [put]
+func (h *LedgerHandler) UpdatePayoutStatus(c *gin.Context) {
+	payoutID, err := uuid.Parse(c.Param("payoutId"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid payout ID")
+		return
+	}
+
+	var req dto.UpdatePayoutStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid input data: "+err.Error())
+		return
+	}
+
+	if err := utils.ValidateStruct(req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	payout, err := h.payoutService.UpdatePayoutStatus(c.Request.Context(), payoutID, &req)
+	if err != nil {
+		switch err {
+		case service.ErrPayoutNotFound:
+			utils.RespondWithError(c, http.StatusNotFound, err.Error())
+		case service.ErrPayoutAlreadySettled:
+			utils.RespondWithError(c, http.StatusConflict, err.Error())
+		default:
+			utils.RespondWithError(c, http.StatusInternalServerError, "Failed to update payout")
+		}
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, "Payout updated successfully", payout)
+}
+
+// currentFarmerID resolves the authenticated user's farmer profile, writing
+// the error response itself when that fails.
+func (h *LedgerHandler) currentFarmerID(c *gin.Context) (uuid.UUID, bool) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return uuid.Nil, false
+	}
+
+	farmer, err := h.farmerService.GetFarmerByUserID(c.Request.Context(), userID)
+	if err != nil {
+		switch err {
+		case service.ErrFarmerNotFound:
+			utils.RespondWithError(c, http.StatusNotFound, err.Error())
+		default:
+			utils.RespondWithError(c, http.StatusInternalServerError, "Failed to retrieve farmer profile")
+		}
+		return uuid.Nil, false
+	}
+	return farmer.ID, true
+}
+
+// parsePageParams reads page and page_size with the given default size.
+func parsePageParams(c *gin.Context, defaultSize int) (int, int) {
+	page, pageSize := 1, defaultSize
+	if v, err := strconv.Atoi(c.Query("page")); err == nil && v > 0 {
+		page = v
+	}
+	if v, err := strconv.Atoi(c.Query("page_size")); err == nil && v > 0 && v <= 100 {
+		pageSize = v
+	}
+	return page, pageSize
+}
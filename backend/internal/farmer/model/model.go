@@ -2,6 +2,7 @@ package model
 
 import (
 	product "agro_konnect/internal/product/model"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -79,6 +80,8 @@ type FarmerDocument struct {
 	FarmerID     uuid.UUID `gorm:"not null" json:"farmer_id"`
 	DocumentType string    `gorm:"not null" json:"document_type"` // license, certification, etc.
 	DocumentURL  string    `gorm:"not null" json:"document_url"`
+	AttachmentID uuid.UUID `gorm:"type:uuid;index" json:"attachment_id"` // private upload holding the file
+	FileName     string    `json:"file_name"`
 	Verified     bool      `gorm:"default:false" json:"verified"`
 	UploadedAt   time.Time `json:"uploaded_at"`
 }
@@ -120,3 +123,176 @@ func (fd *FarmerDocument) BeforeCreate(tx *gorm.DB) error {
 	}
 	return nil
 }
+
+// LedgerAccount names one side of a double-entry posting.
+type LedgerAccount string
+
+const (
+	// AccountCash is money held by the platform (payment gateway and bank)
+	AccountCash LedgerAccount = "cash"
+	// AccountFarmerPayable is what the platform owes the farmer
+	AccountFarmerPayable LedgerAccount = "farmer_payable"
+	// AccountCommissionRevenue is the platform's cut of product sales
+	AccountCommissionRevenue LedgerAccount = "commission_revenue"
+	// AccountPassThrough holds shipping and tax collected on behalf of others
+	AccountPassThrough LedgerAccount = "pass_through"
+)
+
+type LedgerTransactionType string
+
+const (
+	LedgerTransactionPayment        LedgerTransactionType = "payment"
+	LedgerTransactionRefund         LedgerTransactionType = "refund"
+	LedgerTransactionPayout         LedgerTransactionType = "payout"
+	LedgerTransactionPayoutReversal LedgerTransactionType = "payout_reversal"
+)
+
+// LedgerTransaction groups balanced entries. An order gets at most one
+// transaction of each type, and so does a payout.
+type LedgerTransaction struct {
+	ID          uuid.UUID             `gorm:"type:uuid;primary_key" json:"id"`
+	FarmerID    uuid.UUID             `gorm:"type:uuid;not null;index" json:"farmer_id"`
+	Type        LedgerTransactionType `gorm:"type:varchar(30);not null;uniqueIndex:idx_ledger_order_type;uniqueIndex:idx_ledger_payout_type" json:"type"`
+	OrderID     *uuid.UUID            `gorm:"type:uuid;uniqueIndex:idx_ledger_order_type" json:"order_id"`
+	OrderNumber string                `json:"order_number"`
+	PayoutID    *uuid.UUID            `gorm:"type:uuid;uniqueIndex:idx_ledger_payout_type" json:"payout_id"`
+	Description string                `json:"description"`
+
+	Entries []LedgerEntry `gorm:"foreignKey:TransactionID" json:"entries"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type LedgerEntry struct {
+	ID            uuid.UUID     `gorm:"type:uuid;primary_key" json:"id"`
+	TransactionID uuid.UUID     `gorm:"type:uuid;not null;index" json:"transaction_id"`
+	FarmerID      uuid.UUID     `gorm:"type:uuid;not null;index:idx_ledger_entry_farmer_account" json:"farmer_id"`
+	Account       LedgerAccount `gorm:"type:varchar(30);not null;index:idx_ledger_entry_farmer_account" json:"account"`
+	Debit         float64       `gorm:"type:decimal(12,2);not null;default:0" json:"debit"`
+	Credit        float64       `gorm:"type:decimal(12,2);not null;default:0" json:"credit"`
+	CreatedAt     time.Time     `json:"created_at"`
+}
+
+// Farmer tiers a commission rule can target
+const (
+	FarmerTierStandard = "standard"
+	FarmerTierPremium  = "premium"
+)
+
+// CommissionRule sets the platform commission for a product category, a
+// farmer tier, or both. Empty fields match anything; the most specific rule
+// wins.
+type CommissionRule struct {
+	ID         uuid.UUID               `gorm:"type:uuid;primary_key" json:"id"`
+	Category   product.ProductCategory `gorm:"type:varchar(50);uniqueIndex:idx_commission_rule_scope" json:"category"`
+	FarmerTier string                  `gorm:"type:varchar(20);uniqueIndex:idx_commission_rule_scope" json:"farmer_tier"`
+	Rate       float64                 `gorm:"type:decimal(5,4);not null" json:"rate"`
+	CreatedAt  time.Time               `json:"created_at"`
+	UpdatedAt  time.Time               `json:"updated_at"`
+}
+
+// Matches reports whether the rule applies to the category and tier.
+func (r *CommissionRule) Matches(category product.ProductCategory, tier string) bool {
+	return (r.Category == "" || r.Category == category) && (r.FarmerTier == "" || r.FarmerTier == tier)
+}
+
+// Specificity ranks rules so category+tier beats category beats tier.
+func (r *CommissionRule) Specificity() int {
+	score := 0
+	if r.Category != "" {
+		score += 2
+	}
+	if r.FarmerTier != "" {
+		score++
+	}
+	return score
+}
+
+type PayoutAccount struct {
+	ID                uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	FarmerID          uuid.UUID `gorm:"type:uuid;not null;index" json:"farmer_id"`
+	AccountHolderName string    `gorm:"not null" json:"account_holder_name"`
+	BankName          string    `gorm:"not null" json:"bank_name"`
+	AccountNumber     string    `gorm:"not null" json:"-"`
+	RoutingCode       string    `gorm:"not null" json:"routing_code"` // IFSC, sort code, routing number
+	IsDefault         bool      `gorm:"default:false" json:"is_default"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// MaskedAccountNumber shows only the last four digits.
+func (a *PayoutAccount) MaskedAccountNumber() string {
+	if len(a.AccountNumber) <= 4 {
+		return a.AccountNumber
+	}
+	return strings.Repeat("*", len(a.AccountNumber)-4) + a.AccountNumber[len(a.AccountNumber)-4:]
+}
+
+type PayoutBatchStatus string
+
+const (
+	PayoutBatchStatusProcessing PayoutBatchStatus = "processing"
+	PayoutBatchStatusCompleted  PayoutBatchStatus = "completed"
+)
+
+type PayoutStatus string
+
+const (
+	PayoutStatusPending PayoutStatus = "pending"
+	PayoutStatusPaid    PayoutStatus = "paid"
+	PayoutStatusFailed  PayoutStatus = "failed"
+)
+
+type PayoutBatch struct {
+	ID          uuid.UUID         `gorm:"type:uuid;primary_key" json:"id"`
+	Status      PayoutBatchStatus `gorm:"type:varchar(20);default:'processing'" json:"status"`
+	PayoutCount int               `json:"payout_count"`
+	TotalAmount float64           `gorm:"type:decimal(12,2)" json:"total_amount"`
+	Payouts     []Payout          `gorm:"foreignKey:BatchID" json:"payouts,omitempty"`
+	CreatedAt   time.Time         `json:"created_at"`
+	CompletedAt *time.Time        `json:"completed_at"`
+}
+
+type Payout struct {
+	ID              uuid.UUID    `gorm:"type:uuid;primary_key" json:"id"`
+	BatchID         uuid.UUID    `gorm:"type:uuid;not null;index" json:"batch_id"`
+	FarmerID        uuid.UUID    `gorm:"type:uuid;not null;index" json:"farmer_id"`
+	PayoutAccountID uuid.UUID    `gorm:"type:uuid;not null" json:"payout_account_id"`
+	Amount          float64      `gorm:"type:decimal(12,2);not null" json:"amount"`
+	Status          PayoutStatus `gorm:"type:varchar(20);default:'pending'" json:"status"`
+	Reference       string       `json:"reference"`
+	FailureReason   string       `json:"failure_reason"`
+	PaidAt          *time.Time   `json:"paid_at"`
+	CreatedAt       time.Time    `json:"created_at"`
+	UpdatedAt       time.Time    `json:"updated_at"`
+}
+
+// TableName specifies the table name for LedgerTransaction
+func (LedgerTransaction) TableName() string {
+	return "ledger_transactions"
+}
+
+// TableName specifies the table name for LedgerEntry
+func (LedgerEntry) TableName() string {
+	return "ledger_entries"
+}
+
+// TableName specifies the table name for CommissionRule
+func (CommissionRule) TableName() string {
+	return "commission_rules"
+}
+
+// TableName specifies the table name for PayoutAccount
+func (PayoutAccount) TableName() string {
+	return "payout_accounts"
+}
+
+// TableName specifies the table name for PayoutBatch
+func (PayoutBatch) TableName() string {
+	return "payout_batches"
+}
+
+// TableName specifies the table name for Payout
+func (Payout) TableName() string {
+	return "payouts"
+}
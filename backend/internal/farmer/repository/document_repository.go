@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	model "agro_konnect/internal/farmer/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type DocumentRepository interface {
+	Create(ctx context.Context, document *model.FarmerDocument) error
+	FindByID(ctx context.Context, id uuid.UUID) (*model.FarmerDocument, error)
+	FindByFarmerID(ctx context.Context, farmerID uuid.UUID) ([]*model.FarmerDocument, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type documentRepository struct {
+	db *gorm.DB
+}
+
+func NewDocumentRepository(db *gorm.DB) DocumentRepository {
+	return &documentRepository{db: db}
+}
+
+func (r *documentRepository) Create(ctx context.Context, document *model.FarmerDocument) error {
+	return r.db.WithContext(ctx).Create(document).Error
+}
+
+func (r *documentRepository) FindByID(ctx context.Context, id uuid.UUID) (*model.FarmerDocument, error) {
+	var document model.FarmerDocument
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&document).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &document, err
+}
+
+func (r *documentRepository) FindByFarmerID(ctx context.Context, farmerID uuid.UUID) ([]*model.FarmerDocument, error) {
+	var documents []*model.FarmerDocument
+	err := r.db.WithContext(ctx).
+		Where("farmer_id = ?", farmerID).
+		Order("uploaded_at DESC").
+		Find(&documents).Error
+	return documents, err
+}
+
+func (r *documentRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Where("id = ?", id).Delete(&model.FarmerDocument{}).Error
+}
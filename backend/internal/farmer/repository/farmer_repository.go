@@ -7,6 +7,7 @@ import (
 
 	dto "agro_konnect/internal/farmer/dto"
 	model "agro_konnect/internal/farmer/model"
+	"agro_konnect/pkg/geo"
 	"errors"
 
 	"github.com/google/uuid"
@@ -165,26 +166,23 @@ func (r *farmerRepository) UpdateStats(ctx context.Context, farmerID uuid.UUID,
 	return r.db.WithContext(ctx).Model(&model.Farmer{}).Where("id = ?", farmerID).Updates(stats).Error
 }
 
+// FindNearby returns the farmers within radius km of the point, nearest first.
+// The bounding box narrows the scan before the exact haversine check.
 func (r *farmerRepository) FindNearby(ctx context.Context, lat, lng, radius float64) ([]*model.Farmer, error) {
-	// Simple bounding box query for compatibility
-	// In production, use PostGIS or database-specific spatial functions
-	latRange := radius / 111.0              // approx km per degree latitude
-	lngRange := radius / (111.0 * COS(lat)) // approx km per degree longitude
+	center := geo.Point{Lat: lat, Lng: lng}
+	bounds := geo.RadiusBounds(center, radius)
+	distance := geo.DistanceSQL(center, "latitude", "longitude")
 
 	var farmers []*model.Farmer
 	err := r.db.WithContext(ctx).
-		Where("latitude BETWEEN ? AND ?", lat-latRange, lat+latRange).
-		Where("longitude BETWEEN ? AND ?", lng-lngRange, lng+lngRange).
+		Where("latitude BETWEEN ? AND ?", bounds.MinLat, bounds.MaxLat).
+		Where("longitude BETWEEN ? AND ?", bounds.MinLng, bounds.MaxLng).
+		Where(distance+" <= ?", radius).
+		Order(distance).
 		Find(&farmers).Error
 	return farmers, err
 }
 
-// COS calculates cosine (simplified for the example)
-func COS(deg float64) float64 {
-	rad := deg * 0.017453292519943295
-	return (1 - rad*rad/2 + rad*rad*rad*rad/24 - rad*rad*rad*rad*rad*rad/720)
-}
-
 func (r *farmerRepository) GetProductCount(ctx context.Context, farmerID uuid.UUID) (int, error) {
 	var count int64
 	err := r.db.WithContext(ctx).Model(&model.Farmer{}).Where("id = ?", farmerID).
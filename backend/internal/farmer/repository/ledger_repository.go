@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	model "agro_konnect/internal/farmer/model"
+	product "agro_konnect/internal/product/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LedgerTotal is the sum of one account's postings for one transaction type.
+type LedgerTotal struct {
+	Type    model.LedgerTransactionType
+	Account model.LedgerAccount
+	Debit   float64
+	Credit  float64
+}
+
+// StatementLine is a farmer_payable entry with its transaction details and the
+// running balance after it.
+type StatementLine struct {
+	ID            uuid.UUID
+	TransactionID uuid.UUID
+	Type          model.LedgerTransactionType
+	OrderID       *uuid.UUID
+	OrderNumber   string
+	PayoutID      *uuid.UUID
+	Description   string
+	Debit         float64
+	Credit        float64
+	Balance       float64
+	CreatedAt     time.Time
+}
+
+// PayableBalance is a farmer's outstanding farmer_payable balance.
+type PayableBalance struct {
+	FarmerID uuid.UUID
+	Balance  float64
+}
+
+type LedgerRepository interface {
+	CreateTransaction(ctx context.Context, txn *model.LedgerTransaction) error
+	FindOrderTransaction(ctx context.Context, orderID uuid.UUID, txnType model.LedgerTransactionType) (*model.LedgerTransaction, error)
+	GetTotals(ctx context.Context, farmerID uuid.UUID) ([]LedgerTotal, error)
+	GetPayableBalance(ctx context.Context, farmerID uuid.UUID, before time.Time) (float64, error)
+	FindStatementLines(ctx context.Context, farmerID uuid.UUID, from, to time.Time, page, pageSize int) ([]StatementLine, int64, error)
+	FindPayableBalances(ctx context.Context, minBalance float64) ([]PayableBalance, error)
+	FindProductCategories(ctx context.Context, productIDs []uuid.UUID) (map[uuid.UUID]product.ProductCategory, error)
+
+	// Commission rules
+	FindCommissionRules(ctx context.Context) ([]*model.CommissionRule, error)
+	UpsertCommissionRule(ctx context.Context, rule *model.CommissionRule) error
+	DeleteCommissionRule(ctx context.Context, id uuid.UUID) (bool, error)
+}
+
+type ledgerRepository struct {
+	db *gorm.DB
+}
+
+func NewLedgerRepository(db *gorm.DB) LedgerRepository {
+	return &ledgerRepository{db: db}
+}
+
+// CreateTransaction saves the transaction and its entries together.
+func (r *ledgerRepository) CreateTransaction(ctx context.Context, txn *model.LedgerTransaction) error {
+	return r.db.WithContext(ctx).Create(txn).Error
+}
+
+func (r *ledgerRepository) FindOrderTransaction(ctx context.Context, orderID uuid.UUID, txnType model.LedgerTransactionType) (*model.LedgerTransaction, error) {
+	var txn model.LedgerTransaction
+	err := r.db.WithContext(ctx).Preload("Entries").
+		Where("order_id = ? AND type = ?", orderID, txnType).
+		First(&txn).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &txn, err
+}
+
+func (r *ledgerRepository) GetTotals(ctx context.Context, farmerID uuid.UUID) ([]LedgerTotal, error) {
+	var totals []LedgerTotal
+	err := r.db.WithContext(ctx).
+		Table("ledger_entries e").
+		Select("t.type, e.account, SUM(e.debit) AS debit, SUM(e.credit) AS credit").
+		Joins("JOIN ledger_transactions t ON t.id = e.transaction_id").
+		Where("e.farmer_id = ?", farmerID).
+		Group("t.type, e.account").
+		Scan(&totals).Error
+	return totals, err
+}
+
+// GetPayableBalance sums the farmer_payable account up to before. A zero
+// before means all time.
+func (r *ledgerRepository) GetPayableBalance(ctx context.Context, farmerID uuid.UUID, before time.Time) (float64, error) {
+	query := r.db.WithContext(ctx).
+		Model(&model.LedgerEntry{}).
+		Select("COALESCE(SUM(credit - debit), 0)").
+		Where("farmer_id = ? AND account = ?", farmerID, model.AccountFarmerPayable)
+	if !before.IsZero() {
+		query = query.Where("created_at < ?", before)
+	}
+
+	var balance float64
+	err := query.Scan(&balance).Error
+	return balance, err
+}
+
+func (r *ledgerRepository) FindStatementLines(ctx context.Context, farmerID uuid.UUID, from, to time.Time, page, pageSize int) ([]StatementLine, int64, error) {
+	// The running balance is computed over the whole history before the
+	// period filter is applied, so every page carries true balances.
+	running := r.db.WithContext(ctx).
+		Table("ledger_entries e").
+		Select(`e.id, e.transaction_id, t.type, t.order_id, t.order_number, t.payout_id, t.description,
+			e.debit, e.credit, e.created_at,
+			SUM(e.credit - e.debit) OVER (ORDER BY e.created_at, e.id) AS balance`).
+		Joins("JOIN ledger_transactions t ON t.id = e.transaction_id").
+		Where("e.farmer_id = ? AND e.account = ?", farmerID, model.AccountFarmerPayable)
+
+	query := r.db.WithContext(ctx).Table("(?) AS s", running)
+	if !from.IsZero() {
+		query = query.Where("s.created_at >= ?", from)
+	}
+	if !to.IsZero() {
+		query = query.Where("s.created_at < ?", to)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var lines []StatementLine
+	offset := (page - 1) * pageSize
+	err := query.Order("s.created_at DESC, s.id DESC").Offset(offset).Limit(pageSize).Scan(&lines).Error
+	return lines, total, err
+}
+
+func (r *ledgerRepository) FindPayableBalances(ctx context.Context, minBalance float64) ([]PayableBalance, error) {
+	var balances []PayableBalance
+	err := r.db.WithContext(ctx).
+		Model(&model.LedgerEntry{}).
+		Select("farmer_id, SUM(credit - debit) AS balance").
+		Where("account = ?", model.AccountFarmerPayable).
+		Group("farmer_id").
+		Having("SUM(credit - debit) >= ?", minBalance).
+		Scan(&balances).Error
+	return balances, err
+}
+
+func (r *ledgerRepository) FindProductCategories(ctx context.Context, productIDs []uuid.UUID) (map[uuid.UUID]product.ProductCategory, error) {
+	var rows []struct {
+		ID       uuid.UUID
+		Category product.ProductCategory
+	}
+	err := r.db.WithContext(ctx).
+		Model(&product.Product{}).
+		Select("id, category").
+		Where("id IN ?", productIDs).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	categories := make(map[uuid.UUID]product.ProductCategory, len(rows))
+	for _, row := range rows {
+		categories[row.ID] = row.Category
+	}
+	return categories, nil
+}
+
+func (r *ledgerRepository) FindCommissionRules(ctx context.Context) ([]*model.CommissionRule, error) {
+	var rules []*model.CommissionRule
+	err := r.db.WithContext(ctx).Order("category, farmer_tier").Find(&rules).Error
+	return rules, err
+}
+
+// UpsertCommissionRule replaces the rate of an existing rule with the same
+// category and tier, or creates one.
+func (r *ledgerRepository) UpsertCommissionRule(ctx context.Context, rule *model.CommissionRule) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "category"}, {Name: "farmer_tier"}},
+			DoUpdates: clause.AssignmentColumns([]string{"rate", "updated_at"}),
+		}).
+		Create(rule).Error
+}
+
+func (r *ledgerRepository) DeleteCommissionRule(ctx context.Context, id uuid.UUID) (bool, error) {
+	result := r.db.WithContext(ctx).Where("id = ?", id).Delete(&model.CommissionRule{})
+	return result.RowsAffected > 0, result.Error
+}
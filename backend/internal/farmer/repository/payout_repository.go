@@ -0,0 +1,256 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	model "agro_konnect/internal/farmer/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrPayableBalanceChanged = errors.New("payable balance changed while the batch was being prepared")
+	ErrPayoutNotPending      = errors.New("payout is not pending")
+)
+
+// payoutBatchLockKey serialises batch creation across instances.
+const payoutBatchLockKey = "farmer_payout_batch"
+
+type PayoutRepository interface {
+	// Payout accounts
+	CreateAccount(ctx context.Context, account *model.PayoutAccount) error
+	FindAccountByID(ctx context.Context, id uuid.UUID) (*model.PayoutAccount, error)
+	FindAccountsByFarmer(ctx context.Context, farmerID uuid.UUID) ([]*model.PayoutAccount, error)
+	FindDefaultAccounts(ctx context.Context, farmerIDs []uuid.UUID) (map[uuid.UUID]*model.PayoutAccount, error)
+	SetDefaultAccount(ctx context.Context, farmerID, accountID uuid.UUID) error
+	DeleteAccount(ctx context.Context, id uuid.UUID) error
+
+	// Batches and payouts
+	CreateBatch(ctx context.Context, batch *model.PayoutBatch, txns []*model.LedgerTransaction) error
+	FindBatchByID(ctx context.Context, id uuid.UUID) (*model.PayoutBatch, error)
+	FindBatches(ctx context.Context, page, pageSize int) ([]*model.PayoutBatch, int64, error)
+	FindPayoutByID(ctx context.Context, id uuid.UUID) (*model.Payout, error)
+	FindPayoutsByFarmer(ctx context.Context, farmerID uuid.UUID, page, pageSize int) ([]*model.Payout, int64, error)
+	GetPendingPayoutTotal(ctx context.Context, farmerID uuid.UUID) (float64, error)
+	CompletePayout(ctx context.Context, payout *model.Payout, reversal *model.LedgerTransaction) error
+}
+
+type payoutRepository struct {
+	db *gorm.DB
+}
+
+func NewPayoutRepository(db *gorm.DB) PayoutRepository {
+	return &payoutRepository{db: db}
+}
+
+func (r *payoutRepository) CreateAccount(ctx context.Context, account *model.PayoutAccount) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if account.IsDefault {
+			if err := clearDefaultAccount(tx, account.FarmerID); err != nil {
+				return err
+			}
+		}
+		return tx.Create(account).Error
+	})
+}
+
+func (r *payoutRepository) FindAccountByID(ctx context.Context, id uuid.UUID) (*model.PayoutAccount, error) {
+	var account model.PayoutAccount
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&account).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &account, err
+}
+
+func (r *payoutRepository) FindAccountsByFarmer(ctx context.Context, farmerID uuid.UUID) ([]*model.PayoutAccount, error) {
+	var accounts []*model.PayoutAccount
+	err := r.db.WithContext(ctx).
+		Where("farmer_id = ?", farmerID).
+		Order("is_default DESC, created_at ASC").
+		Find(&accounts).Error
+	return accounts, err
+}
+
+func (r *payoutRepository) FindDefaultAccounts(ctx context.Context, farmerIDs []uuid.UUID) (map[uuid.UUID]*model.PayoutAccount, error) {
+	var accounts []*model.PayoutAccount
+	err := r.db.WithContext(ctx).
+		Where("farmer_id IN ? AND is_default = ?", farmerIDs, true).
+		Find(&accounts).Error
+	if err != nil {
+		return nil, err
+	}
+
+	byFarmer := make(map[uuid.UUID]*model.PayoutAccount, len(accounts))
+	for _, account := range accounts {
+		byFarmer[account.FarmerID] = account
+	}
+	return byFarmer, nil
+}
+
+func (r *payoutRepository) SetDefaultAccount(ctx context.Context, farmerID, accountID uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := clearDefaultAccount(tx, farmerID); err != nil {
+			return err
+		}
+		return tx.Model(&model.PayoutAccount{}).
+			Where("id = ? AND farmer_id = ?", accountID, farmerID).
+			Updates(map[string]interface{}{
+				"is_default": true,
+				"updated_at": time.Now(),
+			}).Error
+	})
+}
+
+func (r *payoutRepository) DeleteAccount(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Where("id = ?", id).Delete(&model.PayoutAccount{}).Error
+}
+
+// CreateBatch saves the batch, its payouts and their ledger postings in one
+// transaction. Concurrent runs are serialised with an advisory lock, and each
+// farmer's payable balance is re-checked under the lock so nobody is paid
+// twice.
+func (r *payoutRepository) CreateBatch(ctx context.Context, batch *model.PayoutBatch, txns []*model.LedgerTransaction) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", payoutBatchLockKey).Error; err != nil {
+			return err
+		}
+
+		for _, payout := range batch.Payouts {
+			var balance float64
+			err := tx.Model(&model.LedgerEntry{}).
+				Select("COALESCE(SUM(credit - debit), 0)").
+				Where("farmer_id = ? AND account = ?", payout.FarmerID, model.AccountFarmerPayable).
+				Scan(&balance).Error
+			if err != nil {
+				return err
+			}
+			if math.Round(balance*100) < math.Round(payout.Amount*100) {
+				return ErrPayableBalanceChanged
+			}
+		}
+
+		if err := tx.Create(batch).Error; err != nil {
+			return err
+		}
+		if len(txns) == 0 {
+			return nil
+		}
+		return tx.Create(&txns).Error
+	})
+}
+
+func (r *payoutRepository) FindBatchByID(ctx context.Context, id uuid.UUID) (*model.PayoutBatch, error) {
+	var batch model.PayoutBatch
+	err := r.db.WithContext(ctx).Preload("Payouts").Where("id = ?", id).First(&batch).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &batch, err
+}
+
+func (r *payoutRepository) FindBatches(ctx context.Context, page, pageSize int) ([]*model.PayoutBatch, int64, error) {
+	query := r.db.WithContext(ctx).Model(&model.PayoutBatch{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var batches []*model.PayoutBatch
+	offset := (page - 1) * pageSize
+	err := query.Order("created_at DESC").Offset(offset).Limit(pageSize).Find(&batches).Error
+	return batches, total, err
+}
+
+func (r *payoutRepository) FindPayoutByID(ctx context.Context, id uuid.UUID) (*model.Payout, error) {
+	var payout model.Payout
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&payout).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &payout, err
+}
+
+func (r *payoutRepository) FindPayoutsByFarmer(ctx context.Context, farmerID uuid.UUID, page, pageSize int) ([]*model.Payout, int64, error) {
+	query := r.db.WithContext(ctx).Model(&model.Payout{}).Where("farmer_id = ?", farmerID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var payouts []*model.Payout
+	offset := (page - 1) * pageSize
+	err := query.Order("created_at DESC").Offset(offset).Limit(pageSize).Find(&payouts).Error
+	return payouts, total, err
+}
+
+func (r *payoutRepository) GetPendingPayoutTotal(ctx context.Context, farmerID uuid.UUID) (float64, error) {
+	var total float64
+	err := r.db.WithContext(ctx).
+		Model(&model.Payout{}).
+		Select("COALESCE(SUM(amount), 0)").
+		Where("farmer_id = ? AND status = ?", farmerID, model.PayoutStatusPending).
+		Scan(&total).Error
+	return total, err
+}
+
+// CompletePayout records the payout's final status, posts the reversal for a
+// failed payout, and closes the batch once nothing in it is pending.
+func (r *payoutRepository) CompletePayout(ctx context.Context, payout *model.Payout, reversal *model.LedgerTransaction) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Payout{}).
+			Where("id = ? AND status = ?", payout.ID, model.PayoutStatusPending).
+			Updates(map[string]interface{}{
+				"status":         payout.Status,
+				"reference":      payout.Reference,
+				"failure_reason": payout.FailureReason,
+				"paid_at":        payout.PaidAt,
+				"updated_at":     time.Now(),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrPayoutNotPending
+		}
+
+		if reversal != nil {
+			if err := tx.Create(reversal).Error; err != nil {
+				return err
+			}
+		}
+
+		var pending int64
+		if err := tx.Model(&model.Payout{}).
+			Where("batch_id = ? AND status = ?", payout.BatchID, model.PayoutStatusPending).
+			Count(&pending).Error; err != nil {
+			return err
+		}
+		if pending > 0 {
+			return nil
+		}
+
+		return tx.Model(&model.PayoutBatch{}).
+			Where("id = ?", payout.BatchID).
+			Updates(map[string]interface{}{
+				"status":       model.PayoutBatchStatusCompleted,
+				"completed_at": time.Now(),
+			}).Error
+	})
+}
+
+// Helper functions
+func clearDefaultAccount(tx *gorm.DB, farmerID uuid.UUID) error {
+	return tx.Model(&model.PayoutAccount{}).
+		Where("farmer_id = ? AND is_default = ?", farmerID, true).
+		Updates(map[string]interface{}{
+			"is_default": false,
+			"updated_at": time.Now(),
+		}).Error
+}
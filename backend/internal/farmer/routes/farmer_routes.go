@@ -1,22 +1,37 @@
 package routes
 
 import (
+	auditservice "agro_konnect/internal/audit/service"
 	"agro_konnect/internal/auth/middleware"
 	"agro_konnect/internal/auth/model"
+	emailservice "agro_konnect/internal/email/service"
 	"agro_konnect/internal/farmer/handler"
 	"agro_konnect/internal/farmer/repository"
 	"agro_konnect/internal/farmer/service"
+	mediaService "agro_konnect/internal/media/service"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
-func SetupFarmerRoutes(router *gin.RouterGroup, db *gorm.DB, authMiddleware *middleware.AuthMiddleware) {
+func SetupFarmerRoutes(router *gin.RouterGroup, db *gorm.DB, authMiddleware *middleware.AuthMiddleware, mediaService mediaService.MediaService, auditRecorder auditservice.Recorder, mailer emailservice.Mailer) {
 	// Initialize farmer dependencies
 	farmerRepo := repository.NewFarmerRepository(db)
-	farmerService := service.NewFarmerService(farmerRepo)
+	farmerService := service.NewFarmerService(farmerRepo, auditRecorder, mailer)
 	farmerHandler := handler.NewFarmerHandler(farmerService)
 
+	// Ledger and payout dependencies
+	ledgerRepo := repository.NewLedgerRepository(db)
+	payoutRepo := repository.NewPayoutRepository(db)
+	ledgerConfig := service.LoadLedgerConfig()
+	ledgerService := service.NewLedgerService(ledgerRepo, payoutRepo, farmerRepo, ledgerConfig)
+	payoutService := service.NewPayoutService(payoutRepo, ledgerRepo, ledgerConfig)
+	ledgerHandler := handler.NewLedgerHandler(farmerService, ledgerService, payoutService)
+
+	// Verification documents
+	documentService := service.NewDocumentService(repository.NewDocumentRepository(db), farmerRepo, mediaService)
+	documentHandler := handler.NewDocumentHandler(documentService)
+
 	farmerRoutes := router.Group("/farmers")
 	{
 		// Public routes
@@ -33,12 +48,34 @@ func SetupFarmerRoutes(router *gin.RouterGroup, db *gorm.DB, authMiddleware *mid
 			authRequired.GET("/me/stats", farmerHandler.GetFarmerStats)
 			authRequired.PUT("/me", farmerHandler.UpdateFarmer)
 			authRequired.DELETE("/me", farmerHandler.DeleteFarmer)
+
+			// Ledger and payout routes
+			authRequired.GET("/me/balance", ledgerHandler.GetMyBalance)
+			authRequired.GET("/me/statement", ledgerHandler.GetMyStatement)
+			authRequired.GET("/me/payouts", ledgerHandler.GetMyPayouts)
+			authRequired.GET("/me/payout-accounts", ledgerHandler.GetMyPayoutAccounts)
+			authRequired.POST("/me/payout-accounts", ledgerHandler.AddPayoutAccount)
+			authRequired.PUT("/me/payout-accounts/:accountId/default", ledgerHandler.SetDefaultPayoutAccount)
+			authRequired.DELETE("/me/payout-accounts/:accountId", ledgerHandler.DeletePayoutAccount)
+
+			// Verification documents
+			authRequired.POST("/me/documents", documentHandler.UploadDocument)
+			authRequired.GET("/me/documents", documentHandler.GetMyDocuments)
+			authRequired.DELETE("/me/documents/:documentId", documentHandler.DeleteDocument)
 		}
 
 		// Admin only routes
 		adminRoutes := farmerRoutes.Use(authMiddleware.Authenticate(), authMiddleware.RequireRole(model.RoleAdmin))
 		{
 			adminRoutes.PUT("/:id/verify", farmerHandler.VerifyFarmer)
+			adminRoutes.GET("/:id/documents", documentHandler.GetFarmerDocuments)
+			adminRoutes.GET("/commission-rules", ledgerHandler.GetCommissionRules)
+			adminRoutes.PUT("/commission-rules", ledgerHandler.SetCommissionRule)
+			adminRoutes.DELETE("/commission-rules/:ruleId", ledgerHandler.DeleteCommissionRule)
+			adminRoutes.POST("/payouts/batches", ledgerHandler.CreatePayoutBatch)
+			adminRoutes.GET("/payouts/batches", ledgerHandler.GetPayoutBatches)
+			adminRoutes.GET("/payouts/batches/:batchId", ledgerHandler.GetPayoutBatch)
+			adminRoutes.PUT("/payouts/:payoutId/status", ledgerHandler.UpdatePayoutStatus)
 		}
 	}
 }
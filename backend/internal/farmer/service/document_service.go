@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"mime/multipart"
+	"time"
+
+	dto "agro_konnect/internal/farmer/dto"
+	model "agro_konnect/internal/farmer/model"
+	"agro_konnect/internal/farmer/repository"
+	mediaService "agro_konnect/internal/media/service"
+
+	"github.com/google/uuid"
+)
+
+var ErrDocumentNotFound = errors.New("document not found")
+
+// DocumentService keeps the licences and certificates farmers upload for
+// verification. The files are private; responses carry short-lived signed
+// URLs.
+type DocumentService interface {
+	UploadDocument(ctx context.Context, userID uuid.UUID, req *dto.UploadDocumentRequest, file *multipart.FileHeader) (*dto.DocumentResponse, error)
+	GetMyDocuments(ctx context.Context, userID uuid.UUID) ([]*dto.DocumentResponse, error)
+	GetFarmerDocuments(ctx context.Context, farmerID uuid.UUID) ([]*dto.DocumentResponse, error)
+	DeleteDocument(ctx context.Context, userID, documentID uuid.UUID) error
+}
+
+type documentService struct {
+	documentRepo repository.DocumentRepository
+	farmerRepo   repository.FarmerRepository
+	mediaService mediaService.MediaService
+}
+
+func NewDocumentService(
+	documentRepo repository.DocumentRepository,
+	farmerRepo repository.FarmerRepository,
+	mediaService mediaService.MediaService,
+) DocumentService {
+	return &documentService{
+		documentRepo: documentRepo,
+		farmerRepo:   farmerRepo,
+		mediaService: mediaService,
+	}
+}
+
+func (s *documentService) UploadDocument(ctx context.Context, userID uuid.UUID, req *dto.UploadDocumentRequest, file *multipart.FileHeader) (*dto.DocumentResponse, error) {
+	farmer, err := s.farmerRepo.FindByUserID(ctx, userID)
+	if err != nil || farmer == nil {
+		return nil, ErrFarmerNotFound
+	}
+
+	attachment, err := s.mediaService.Upload(ctx, mediaService.UploadRequest{
+		Policy:     mediaService.FarmerDocumentPolicy,
+		EntityID:   farmer.ID,
+		UploadedBy: userID,
+		File:       file,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	document := &model.FarmerDocument{
+		ID:           uuid.New(),
+		FarmerID:     farmer.ID,
+		DocumentType: req.DocumentType,
+		DocumentURL:  attachment.FileURL,
+		AttachmentID: attachment.ID,
+		FileName:     attachment.FileName,
+		UploadedAt:   time.Now(),
+	}
+	if err := s.documentRepo.Create(ctx, document); err != nil {
+		return nil, fmt.Errorf("failed to save document: %w", err)
+	}
+	return s.toDocumentResponse(ctx, document, attachment.UploadedBy)
+}
+
+func (s *documentService) GetMyDocuments(ctx context.Context, userID uuid.UUID) ([]*dto.DocumentResponse, error) {
+	farmer, err := s.farmerRepo.FindByUserID(ctx, userID)
+	if err != nil || farmer == nil {
+		return nil, ErrFarmerNotFound
+	}
+	return s.documentsOf(ctx, farmer.ID, farmer.UserID)
+}
+
+// GetFarmerDocuments lists a farmer's documents for an admin reviewing the
+// farmer's verification.
+func (s *documentService) GetFarmerDocuments(ctx context.Context, farmerID uuid.UUID) ([]*dto.DocumentResponse, error) {
+	farmer, err := s.farmerRepo.FindByID(ctx, farmerID)
+	if err != nil || farmer == nil {
+		return nil, ErrFarmerNotFound
+	}
+	return s.documentsOf(ctx, farmer.ID, farmer.UserID)
+}
+
+func (s *documentService) DeleteDocument(ctx context.Context, userID, documentID uuid.UUID) error {
+	farmer, err := s.farmerRepo.FindByUserID(ctx, userID)
+	if err != nil || farmer == nil {
+		return ErrFarmerNotFound
+	}
+
+	document, err := s.documentRepo.FindByID(ctx, documentID)
+	if err != nil {
+		return fmt.Errorf("failed to get document: %w", err)
+	}
+	if document == nil || document.FarmerID != farmer.ID {
+		return ErrDocumentNotFound
+	}
+
+	if err := s.documentRepo.Delete(ctx, documentID); err != nil {
+		return fmt.Errorf("failed to delete document: %w", err)
+	}
+	// The farmer uploaded the file, so no role is needed to remove it
+	if err := s.mediaService.Delete(ctx, document.AttachmentID, userID, ""); err != nil {
+		log.Printf("failed to delete file of farmer document %s: %v", documentID, err)
+	}
+	return nil
+}
+
+// Helper methods
+func (s *documentService) documentsOf(ctx context.Context, farmerID, farmerUserID uuid.UUID) ([]*dto.DocumentResponse, error) {
+	documents, err := s.documentRepo.FindByFarmerID(ctx, farmerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get documents: %w", err)
+	}
+
+	responses := make([]*dto.DocumentResponse, 0, len(documents))
+	for _, document := range documents {
+		response, err := s.toDocumentResponse(ctx, document, farmerUserID)
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, response)
+	}
+	return responses, nil
+}
+
+func (s *documentService) toDocumentResponse(ctx context.Context, document *model.FarmerDocument, farmerUserID uuid.UUID) (*dto.DocumentResponse, error) {
+	response := &dto.DocumentResponse{
+		ID:           document.ID,
+		DocumentType: document.DocumentType,
+		FileName:     document.FileName,
+		Verified:     document.Verified,
+		UploadedAt:   document.UploadedAt,
+	}
+
+	// Documents from before media storage have no file to sign
+	if document.AttachmentID == uuid.Nil {
+		response.URL = document.DocumentURL
+		return response, nil
+	}
+
+	signed, err := s.mediaService.GetSignedURL(ctx, document.AttachmentID, farmerUserID, "", "")
+	if errors.Is(err, mediaService.ErrAttachmentNotFound) {
+		return response, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	response.URL = signed.URL
+	response.URLExpiresAt = signed.ExpiresAt
+	return response, nil
+}
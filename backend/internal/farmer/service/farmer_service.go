@@ -8,6 +8,8 @@ import (
 	"math"
 	"time"
 
+	auditservice "agro_konnect/internal/audit/service"
+	emailservice "agro_konnect/internal/email/service"
 	dto "agro_konnect/internal/farmer/dto"
 	model "agro_konnect/internal/farmer/model"
 	"agro_konnect/internal/farmer/repository"
@@ -43,11 +45,15 @@ type FarmerService interface {
 
 type farmerService struct {
 	farmerRepo repository.FarmerRepository
+	audit      auditservice.Recorder
+	mailer     emailservice.Mailer
 }
 
-func NewFarmerService(farmerRepo repository.FarmerRepository) FarmerService {
+func NewFarmerService(farmerRepo repository.FarmerRepository, audit auditservice.Recorder, mailer emailservice.Mailer) FarmerService {
 	return &farmerService{
 		farmerRepo: farmerRepo,
+		audit:      audit,
+		mailer:     mailer,
 	}
 }
 
@@ -291,10 +297,28 @@ func (s *farmerService) VerifyFarmer(ctx context.Context, farmerID uuid.UUID) er
 		return ErrFarmerNotFound
 	}
 
+	wasVerified := farmer.IsVerified
 	farmer.IsVerified = true
 	farmer.UpdatedAt = time.Now()
 
-	return s.farmerRepo.Update(ctx, farmer)
+	if err := s.farmerRepo.Update(ctx, farmer); err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, auditservice.Event{
+		Action:     "farmer.verify",
+		EntityType: "farmer",
+		EntityID:   farmerID.String(),
+		Before:     map[string]interface{}{"is_verified": wasVerified},
+		After:      map[string]interface{}{"is_verified": true},
+	})
+
+	if !wasVerified {
+		if err := s.mailer.SendProfileVerified(ctx, farmer.UserID, emailservice.ProfileFarmer, farmer.FullName); err != nil {
+			log.Printf("failed to send verification email for farmer %s: %v", farmerID, err)
+		}
+	}
+	return nil
 }
 
 func (s *farmerService) GetNearbyFarmers(ctx context.Context, lat, lng, radius float64) ([]*dto.FarmerResponse, error) {
@@ -0,0 +1,430 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"time"
+
+	dto "agro_konnect/internal/farmer/dto"
+	model "agro_konnect/internal/farmer/model"
+	"agro_konnect/internal/farmer/repository"
+	product "agro_konnect/internal/product/model"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// DefaultFarmerCommissionRate applies when no commission rule matches and
+	// FARMER_COMMISSION_RATE is not set.
+	DefaultFarmerCommissionRate = 0.05
+	// DefaultMinimumPayout is the smallest balance paid out when
+	// FARMER_MINIMUM_PAYOUT is not set.
+	DefaultMinimumPayout = 10.0
+)
+
+var (
+	ErrCommissionRuleNotFound = errors.New("commission rule not found")
+	ErrUnbalancedTransaction  = errors.New("ledger transaction does not balance")
+)
+
+type LedgerConfig struct {
+	DefaultCommissionRate float64
+	MinimumPayout         float64
+}
+
+// LoadLedgerConfig reads the default commission rate and payout threshold
+// from the environment.
+func LoadLedgerConfig() LedgerConfig {
+	config := LedgerConfig{
+		DefaultCommissionRate: DefaultFarmerCommissionRate,
+		MinimumPayout:         DefaultMinimumPayout,
+	}
+	if v, err := strconv.ParseFloat(os.Getenv("FARMER_COMMISSION_RATE"), 64); err == nil && v >= 0 && v < 1 {
+		config.DefaultCommissionRate = v
+	}
+	if v, err := strconv.ParseFloat(os.Getenv("FARMER_MINIMUM_PAYOUT"), 64); err == nil && v > 0 {
+		config.MinimumPayout = v
+	}
+	return config
+}
+
+type LedgerService interface {
+	RecordPayment(ctx context.Context, req *dto.RecordPaymentRequest) error
+	RecordRefund(ctx context.Context, orderID uuid.UUID) error
+	GetBalance(ctx context.Context, farmerID uuid.UUID) (*dto.LedgerBalanceResponse, error)
+	GetStatement(ctx context.Context, farmerID uuid.UUID, from, to time.Time, page, pageSize int) (*dto.LedgerStatementResponse, error)
+	GetCommissionRules(ctx context.Context) (*dto.CommissionRulesResponse, error)
+	SetCommissionRule(ctx context.Context, req *dto.CommissionRuleRequest) (*dto.CommissionRuleResponse, error)
+	DeleteCommissionRule(ctx context.Context, ruleID uuid.UUID) error
+}
+
+type ledgerService struct {
+	ledgerRepo repository.LedgerRepository
+	payoutRepo repository.PayoutRepository
+	farmerRepo repository.FarmerRepository
+	config     LedgerConfig
+}
+
+func NewLedgerService(
+	ledgerRepo repository.LedgerRepository,
+	payoutRepo repository.PayoutRepository,
+	farmerRepo repository.FarmerRepository,
+	config LedgerConfig,
+) LedgerService {
+	return &ledgerService{
+		ledgerRepo: ledgerRepo,
+		payoutRepo: payoutRepo,
+		farmerRepo: farmerRepo,
+		config:     config,
+	}
+}
+
+// RecordPayment posts a buyer payment: the full amount comes into cash, the
+// platform keeps its commission on the goods, the farmer is owed the rest of
+// the goods value, and shipping and tax are held as pass-through. Recording
+// the same order twice has no effect.
+func (s *ledgerService) RecordPayment(ctx context.Context, req *dto.RecordPaymentRequest) error {
+	existing, err := s.ledgerRepo.FindOrderTransaction(ctx, req.OrderID, model.LedgerTransactionPayment)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return nil
+	}
+
+	farmer, err := s.farmerRepo.FindByID(ctx, req.FarmerID)
+	if err != nil {
+		return err
+	}
+	if farmer == nil {
+		return ErrFarmerNotFound
+	}
+
+	commission, err := s.calculateCommission(ctx, farmer, req)
+	if err != nil {
+		return err
+	}
+
+	total := roundAmount(req.TotalAmount)
+	goods := roundAmount(math.Max(req.SubTotal-req.DiscountAmount, 0))
+	payable := roundAmount(goods - commission)
+	passThrough := roundAmount(total - goods)
+
+	orderID := req.OrderID
+	txn := newLedgerTransaction(req.FarmerID, model.LedgerTransactionPayment, fmt.Sprintf("Payment for order %s", req.OrderNumber))
+	txn.OrderID = &orderID
+	txn.OrderNumber = req.OrderNumber
+	txn.Entries = []model.LedgerEntry{
+		ledgerEntry(req.FarmerID, model.AccountCash, total, 0),
+		ledgerEntry(req.FarmerID, model.AccountFarmerPayable, 0, payable),
+		ledgerEntry(req.FarmerID, model.AccountCommissionRevenue, 0, commission),
+		signedCredit(req.FarmerID, model.AccountPassThrough, passThrough),
+	}
+
+	return s.saveTransaction(ctx, txn)
+}
+
+// RecordRefund reverses every posting of the order's payment. Orders that
+// were never recorded, or were already refunded, are left alone. A refund
+// after the farmer was paid out leaves the payable balance negative; later
+// sales pay it off before anything more is paid out.
+func (s *ledgerService) RecordRefund(ctx context.Context, orderID uuid.UUID) error {
+	payment, err := s.ledgerRepo.FindOrderTransaction(ctx, orderID, model.LedgerTransactionPayment)
+	if err != nil {
+		return err
+	}
+	if payment == nil {
+		return nil
+	}
+
+	refund, err := s.ledgerRepo.FindOrderTransaction(ctx, orderID, model.LedgerTransactionRefund)
+	if err != nil {
+		return err
+	}
+	if refund != nil {
+		return nil
+	}
+
+	txn := newLedgerTransaction(payment.FarmerID, model.LedgerTransactionRefund, fmt.Sprintf("Refund for order %s", payment.OrderNumber))
+	txn.OrderID = payment.OrderID
+	txn.OrderNumber = payment.OrderNumber
+	for _, entry := range payment.Entries {
+		txn.Entries = append(txn.Entries, ledgerEntry(entry.FarmerID, entry.Account, entry.Credit, entry.Debit))
+	}
+
+	return s.saveTransaction(ctx, txn)
+}
+
+func (s *ledgerService) GetBalance(ctx context.Context, farmerID uuid.UUID) (*dto.LedgerBalanceResponse, error) {
+	totals, err := s.ledgerRepo.GetTotals(ctx, farmerID)
+	if err != nil {
+		return nil, err
+	}
+
+	pending, err := s.payoutRepo.GetPendingPayoutTotal(ctx, farmerID)
+	if err != nil {
+		return nil, err
+	}
+
+	balance := &dto.LedgerBalanceResponse{
+		FarmerID:       farmerID,
+		PendingPayouts: roundAmount(pending),
+		MinimumPayout:  s.config.MinimumPayout,
+	}
+
+	var disbursed float64
+	for _, total := range totals {
+		switch total.Account {
+		case model.AccountFarmerPayable:
+			balance.AvailableBalance += total.Credit - total.Debit
+			switch total.Type {
+			case model.LedgerTransactionPayment:
+				balance.GrossSales += total.Credit
+			case model.LedgerTransactionRefund:
+				balance.Refunded += total.Debit
+			case model.LedgerTransactionPayout, model.LedgerTransactionPayoutReversal:
+				disbursed += total.Debit - total.Credit
+			}
+		case model.AccountCommissionRevenue:
+			balance.CommissionPaid += total.Credit - total.Debit
+		}
+	}
+
+	balance.AvailableBalance = roundAmount(balance.AvailableBalance)
+	balance.GrossSales = roundAmount(balance.GrossSales)
+	balance.Refunded = roundAmount(balance.Refunded)
+	balance.CommissionPaid = roundAmount(balance.CommissionPaid)
+	balance.PaidOut = roundAmount(disbursed - pending)
+
+	return balance, nil
+}
+
+func (s *ledgerService) GetStatement(ctx context.Context, farmerID uuid.UUID, from, to time.Time, page, pageSize int) (*dto.LedgerStatementResponse, error) {
+	lines, total, err := s.ledgerRepo.FindStatementLines(ctx, farmerID, from, to, page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	var opening float64
+	if !from.IsZero() {
+		opening, err = s.ledgerRepo.GetPayableBalance(ctx, farmerID, from)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	closing, err := s.ledgerRepo.GetPayableBalance(ctx, farmerID, to)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*dto.StatementLineResponse, len(lines))
+	for i, line := range lines {
+		responses[i] = &dto.StatementLineResponse{
+			ID:            line.ID,
+			TransactionID: line.TransactionID,
+			Type:          line.Type,
+			OrderID:       line.OrderID,
+			OrderNumber:   line.OrderNumber,
+			PayoutID:      line.PayoutID,
+			Description:   line.Description,
+			Debit:         line.Debit,
+			Credit:        line.Credit,
+			Balance:       roundAmount(line.Balance),
+			CreatedAt:     line.CreatedAt,
+		}
+	}
+
+	pages := int((total + int64(pageSize) - 1) / int64(pageSize))
+
+	return &dto.LedgerStatementResponse{
+		From:           from,
+		To:             to,
+		OpeningBalance: roundAmount(opening),
+		ClosingBalance: roundAmount(closing),
+		Lines:          responses,
+		Total:          total,
+		Page:           page,
+		Pages:          pages,
+		HasMore:        page < pages,
+	}, nil
+}
+
+func (s *ledgerService) GetCommissionRules(ctx context.Context) (*dto.CommissionRulesResponse, error) {
+	rules, err := s.ledgerRepo.FindCommissionRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*dto.CommissionRuleResponse, len(rules))
+	for i, rule := range rules {
+		responses[i] = toCommissionRuleResponse(rule)
+	}
+
+	return &dto.CommissionRulesResponse{
+		DefaultRate: s.config.DefaultCommissionRate,
+		Rules:       responses,
+	}, nil
+}
+
+// SetCommissionRule creates the rule for the category and tier, or changes
+// its rate if one exists.
+func (s *ledgerService) SetCommissionRule(ctx context.Context, req *dto.CommissionRuleRequest) (*dto.CommissionRuleResponse, error) {
+	rule := &model.CommissionRule{
+		ID:         uuid.New(),
+		Category:   req.Category,
+		FarmerTier: req.FarmerTier,
+		Rate:       req.Rate,
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+	}
+	if err := s.ledgerRepo.UpsertCommissionRule(ctx, rule); err != nil {
+		return nil, fmt.Errorf("failed to save commission rule: %w", err)
+	}
+
+	// Read back so an updated rule reports its original ID
+	rules, err := s.ledgerRepo.FindCommissionRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, saved := range rules {
+		if saved.Category == rule.Category && saved.FarmerTier == rule.FarmerTier {
+			return toCommissionRuleResponse(saved), nil
+		}
+	}
+	return toCommissionRuleResponse(rule), nil
+}
+
+func (s *ledgerService) DeleteCommissionRule(ctx context.Context, ruleID uuid.UUID) error {
+	deleted, err := s.ledgerRepo.DeleteCommissionRule(ctx, ruleID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrCommissionRuleNotFound
+	}
+	return nil
+}
+
+// Helper methods
+
+// calculateCommission applies the best-matching rule to each item. Any
+// order discount is spread across items in proportion to their price.
+func (s *ledgerService) calculateCommission(ctx context.Context, farmer *model.Farmer, req *dto.RecordPaymentRequest) (float64, error) {
+	if req.SubTotal <= 0 {
+		return 0, nil
+	}
+
+	rules, err := s.ledgerRepo.FindCommissionRules(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	productIDs := make([]uuid.UUID, len(req.Items))
+	for i, item := range req.Items {
+		productIDs[i] = item.ProductID
+	}
+	categories, err := s.ledgerRepo.FindProductCategories(ctx, productIDs)
+	if err != nil {
+		return 0, err
+	}
+
+	tier := model.FarmerTierStandard
+	if farmer.IsPremium {
+		tier = model.FarmerTierPremium
+	}
+
+	scale := math.Max(req.SubTotal-req.DiscountAmount, 0) / req.SubTotal
+	var commission float64
+	for _, item := range req.Items {
+		rate := s.commissionRate(rules, categories[item.ProductID], tier)
+		commission += item.TotalPrice * scale * rate
+	}
+	return roundAmount(commission), nil
+}
+
+func (s *ledgerService) commissionRate(rules []*model.CommissionRule, category product.ProductCategory, tier string) float64 {
+	var best *model.CommissionRule
+	for _, rule := range rules {
+		if rule.Matches(category, tier) && (best == nil || rule.Specificity() > best.Specificity()) {
+			best = rule
+		}
+	}
+	if best == nil {
+		return s.config.DefaultCommissionRate
+	}
+	return best.Rate
+}
+
+// saveTransaction drops empty lines and refuses anything that does not
+// balance before writing.
+func (s *ledgerService) saveTransaction(ctx context.Context, txn *model.LedgerTransaction) error {
+	entries := txn.Entries[:0]
+	var debits, credits float64
+	for _, entry := range txn.Entries {
+		if entry.Debit == 0 && entry.Credit == 0 {
+			continue
+		}
+		debits += entry.Debit
+		credits += entry.Credit
+		entries = append(entries, entry)
+	}
+	txn.Entries = entries
+
+	if math.Round(debits*100) != math.Round(credits*100) {
+		return ErrUnbalancedTransaction
+	}
+
+	if err := s.ledgerRepo.CreateTransaction(ctx, txn); err != nil {
+		return fmt.Errorf("failed to record %s: %w", txn.Type, err)
+	}
+	return nil
+}
+
+// Helper functions
+func newLedgerTransaction(farmerID uuid.UUID, txnType model.LedgerTransactionType, description string) *model.LedgerTransaction {
+	return &model.LedgerTransaction{
+		ID:          uuid.New(),
+		FarmerID:    farmerID,
+		Type:        txnType,
+		Description: description,
+		CreatedAt:   time.Now(),
+	}
+}
+
+func ledgerEntry(farmerID uuid.UUID, account model.LedgerAccount, debit, credit float64) model.LedgerEntry {
+	return model.LedgerEntry{
+		ID:        uuid.New(),
+		FarmerID:  farmerID,
+		Account:   account,
+		Debit:     debit,
+		Credit:    credit,
+		CreatedAt: time.Now(),
+	}
+}
+
+// signedCredit credits a positive amount and debits a negative one.
+func signedCredit(farmerID uuid.UUID, account model.LedgerAccount, amount float64) model.LedgerEntry {
+	if amount < 0 {
+		return ledgerEntry(farmerID, account, -amount, 0)
+	}
+	return ledgerEntry(farmerID, account, 0, amount)
+}
+
+func roundAmount(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+func toCommissionRuleResponse(rule *model.CommissionRule) *dto.CommissionRuleResponse {
+	return &dto.CommissionRuleResponse{
+		ID:         rule.ID,
+		Category:   rule.Category,
+		FarmerTier: rule.FarmerTier,
+		Rate:       rule.Rate,
+		UpdatedAt:  rule.UpdatedAt,
+	}
+}
@@ -0,0 +1,351 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	dto "agro_konnect/internal/farmer/dto"
+	model "agro_konnect/internal/farmer/model"
+	"agro_konnect/internal/farmer/repository"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrPayoutAccountNotFound = errors.New("payout account not found")
+	ErrPayoutBatchNotFound   = errors.New("payout batch not found")
+	ErrPayoutNotFound        = errors.New("payout not found")
+	ErrPayoutAlreadySettled  = errors.New("payout has already been marked paid or failed")
+	ErrPayoutBatchConflict   = errors.New("balances changed while the batch was being created; try again")
+)
+
+type PayoutService interface {
+	GetPayoutAccounts(ctx context.Context, farmerID uuid.UUID) ([]*dto.PayoutAccountResponse, error)
+	AddPayoutAccount(ctx context.Context, farmerID uuid.UUID, req *dto.CreatePayoutAccountRequest) (*dto.PayoutAccountResponse, error)
+	SetDefaultPayoutAccount(ctx context.Context, farmerID, accountID uuid.UUID) error
+	DeletePayoutAccount(ctx context.Context, farmerID, accountID uuid.UUID) error
+	GetFarmerPayouts(ctx context.Context, farmerID uuid.UUID, page, pageSize int) (*dto.PayoutListResponse, error)
+	CreatePayoutBatch(ctx context.Context) (*dto.PayoutBatchResponse, error)
+	GetPayoutBatches(ctx context.Context, page, pageSize int) (*dto.PayoutBatchListResponse, error)
+	GetPayoutBatch(ctx context.Context, batchID uuid.UUID) (*dto.PayoutBatchResponse, error)
+	UpdatePayoutStatus(ctx context.Context, payoutID uuid.UUID, req *dto.UpdatePayoutStatusRequest) (*dto.PayoutResponse, error)
+}
+
+type payoutService struct {
+	payoutRepo repository.PayoutRepository
+	ledgerRepo repository.LedgerRepository
+	config     LedgerConfig
+}
+
+func NewPayoutService(
+	payoutRepo repository.PayoutRepository,
+	ledgerRepo repository.LedgerRepository,
+	config LedgerConfig,
+) PayoutService {
+	return &payoutService{
+		payoutRepo: payoutRepo,
+		ledgerRepo: ledgerRepo,
+		config:     config,
+	}
+}
+
+func (s *payoutService) GetPayoutAccounts(ctx context.Context, farmerID uuid.UUID) ([]*dto.PayoutAccountResponse, error) {
+	accounts, err := s.payoutRepo.FindAccountsByFarmer(ctx, farmerID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*dto.PayoutAccountResponse, len(accounts))
+	for i, account := range accounts {
+		responses[i] = toPayoutAccountResponse(account)
+	}
+	return responses, nil
+}
+
+// AddPayoutAccount saves a bank account. A farmer's first account always
+// becomes the default.
+func (s *payoutService) AddPayoutAccount(ctx context.Context, farmerID uuid.UUID, req *dto.CreatePayoutAccountRequest) (*dto.PayoutAccountResponse, error) {
+	existing, err := s.payoutRepo.FindAccountsByFarmer(ctx, farmerID)
+	if err != nil {
+		return nil, err
+	}
+
+	account := &model.PayoutAccount{
+		ID:                uuid.New(),
+		FarmerID:          farmerID,
+		AccountHolderName: strings.TrimSpace(req.AccountHolderName),
+		BankName:          strings.TrimSpace(req.BankName),
+		AccountNumber:     req.AccountNumber,
+		RoutingCode:       strings.ToUpper(req.RoutingCode),
+		IsDefault:         req.IsDefault || len(existing) == 0,
+		CreatedAt:         time.Now(),
+		UpdatedAt:         time.Now(),
+	}
+
+	if err := s.payoutRepo.CreateAccount(ctx, account); err != nil {
+		return nil, fmt.Errorf("failed to create payout account: %w", err)
+	}
+	return toPayoutAccountResponse(account), nil
+}
+
+func (s *payoutService) SetDefaultPayoutAccount(ctx context.Context, farmerID, accountID uuid.UUID) error {
+	if _, err := s.findOwnedAccount(ctx, farmerID, accountID); err != nil {
+		return err
+	}
+	return s.payoutRepo.SetDefaultAccount(ctx, farmerID, accountID)
+}
+
+func (s *payoutService) DeletePayoutAccount(ctx context.Context, farmerID, accountID uuid.UUID) error {
+	if _, err := s.findOwnedAccount(ctx, farmerID, accountID); err != nil {
+		return err
+	}
+	return s.payoutRepo.DeleteAccount(ctx, accountID)
+}
+
+func (s *payoutService) GetFarmerPayouts(ctx context.Context, farmerID uuid.UUID, page, pageSize int) (*dto.PayoutListResponse, error) {
+	payouts, total, err := s.payoutRepo.FindPayoutsByFarmer(ctx, farmerID, page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*dto.PayoutResponse, len(payouts))
+	for i, payout := range payouts {
+		responses[i] = toPayoutResponse(payout)
+	}
+
+	pages := int((total + int64(pageSize) - 1) / int64(pageSize))
+
+	return &dto.PayoutListResponse{
+		Payouts: responses,
+		Total:   total,
+		Page:    page,
+		Pages:   pages,
+		HasMore: page < pages,
+	}, nil
+}
+
+// CreatePayoutBatch pays out every farmer whose payable balance has reached
+// the minimum and who has a default payout account. The payable account is
+// debited as soon as the batch is created; a failed payout credits it back.
+// The balance nets refunds against sales, so money refunded after it was
+// paid out is held back from the farmer's next payout.
+func (s *payoutService) CreatePayoutBatch(ctx context.Context) (*dto.PayoutBatchResponse, error) {
+	balances, err := s.ledgerRepo.FindPayableBalances(ctx, s.config.MinimumPayout)
+	if err != nil {
+		return nil, err
+	}
+
+	farmerIDs := make([]uuid.UUID, len(balances))
+	for i, balance := range balances {
+		farmerIDs[i] = balance.FarmerID
+	}
+
+	accounts := map[uuid.UUID]*model.PayoutAccount{}
+	if len(farmerIDs) > 0 {
+		accounts, err = s.payoutRepo.FindDefaultAccounts(ctx, farmerIDs)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	batch := &model.PayoutBatch{
+		ID:        uuid.New(),
+		Status:    model.PayoutBatchStatusProcessing,
+		CreatedAt: time.Now(),
+	}
+
+	var txns []*model.LedgerTransaction
+	var skipped []uuid.UUID
+	for _, balance := range balances {
+		account, ok := accounts[balance.FarmerID]
+		if !ok {
+			skipped = append(skipped, balance.FarmerID)
+			continue
+		}
+
+		amount := roundAmount(balance.Balance)
+		payout := model.Payout{
+			ID:              uuid.New(),
+			BatchID:         batch.ID,
+			FarmerID:        balance.FarmerID,
+			PayoutAccountID: account.ID,
+			Amount:          amount,
+			Status:          model.PayoutStatusPending,
+			CreatedAt:       time.Now(),
+			UpdatedAt:       time.Now(),
+		}
+		batch.Payouts = append(batch.Payouts, payout)
+		batch.PayoutCount++
+		batch.TotalAmount += amount
+
+		payoutID := payout.ID
+		txn := newLedgerTransaction(balance.FarmerID, model.LedgerTransactionPayout, fmt.Sprintf("Payout to %s %s", account.BankName, account.MaskedAccountNumber()))
+		txn.PayoutID = &payoutID
+		txn.Entries = []model.LedgerEntry{
+			ledgerEntry(balance.FarmerID, model.AccountFarmerPayable, amount, 0),
+			ledgerEntry(balance.FarmerID, model.AccountCash, 0, amount),
+		}
+		txns = append(txns, txn)
+	}
+	batch.TotalAmount = roundAmount(batch.TotalAmount)
+
+	// Nothing to pay still produces a closed, empty batch for the record
+	if batch.PayoutCount == 0 {
+		now := time.Now()
+		batch.Status = model.PayoutBatchStatusCompleted
+		batch.CompletedAt = &now
+	}
+
+	err = s.payoutRepo.CreateBatch(ctx, batch, txns)
+	if errors.Is(err, repository.ErrPayableBalanceChanged) {
+		return nil, ErrPayoutBatchConflict
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create payout batch: %w", err)
+	}
+
+	response := toPayoutBatchResponse(batch)
+	response.SkippedFarmers = skipped
+	return response, nil
+}
+
+func (s *payoutService) GetPayoutBatches(ctx context.Context, page, pageSize int) (*dto.PayoutBatchListResponse, error) {
+	batches, total, err := s.payoutRepo.FindBatches(ctx, page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*dto.PayoutBatchResponse, len(batches))
+	for i, batch := range batches {
+		responses[i] = toPayoutBatchResponse(batch)
+	}
+
+	pages := int((total + int64(pageSize) - 1) / int64(pageSize))
+
+	return &dto.PayoutBatchListResponse{
+		Batches: responses,
+		Total:   total,
+		Page:    page,
+		Pages:   pages,
+		HasMore: page < pages,
+	}, nil
+}
+
+func (s *payoutService) GetPayoutBatch(ctx context.Context, batchID uuid.UUID) (*dto.PayoutBatchResponse, error) {
+	batch, err := s.payoutRepo.FindBatchByID(ctx, batchID)
+	if err != nil {
+		return nil, err
+	}
+	if batch == nil {
+		return nil, ErrPayoutBatchNotFound
+	}
+	return toPayoutBatchResponse(batch), nil
+}
+
+// UpdatePayoutStatus records the bank's answer for a pending payout. A failed
+// payout returns the money to the farmer's payable balance.
+func (s *payoutService) UpdatePayoutStatus(ctx context.Context, payoutID uuid.UUID, req *dto.UpdatePayoutStatusRequest) (*dto.PayoutResponse, error) {
+	payout, err := s.payoutRepo.FindPayoutByID(ctx, payoutID)
+	if err != nil {
+		return nil, err
+	}
+	if payout == nil {
+		return nil, ErrPayoutNotFound
+	}
+	if payout.Status != model.PayoutStatusPending {
+		return nil, ErrPayoutAlreadySettled
+	}
+
+	payout.Status = req.Status
+	payout.Reference = req.Reference
+
+	var reversal *model.LedgerTransaction
+	switch req.Status {
+	case model.PayoutStatusPaid:
+		now := time.Now()
+		payout.PaidAt = &now
+	case model.PayoutStatusFailed:
+		payout.FailureReason = req.FailureReason
+		description := "Payout failed"
+		if req.FailureReason != "" {
+			description += ": " + req.FailureReason
+		}
+		reversal = newLedgerTransaction(payout.FarmerID, model.LedgerTransactionPayoutReversal, description)
+		reversal.PayoutID = &payout.ID
+		reversal.Entries = []model.LedgerEntry{
+			ledgerEntry(payout.FarmerID, model.AccountCash, payout.Amount, 0),
+			ledgerEntry(payout.FarmerID, model.AccountFarmerPayable, 0, payout.Amount),
+		}
+	}
+
+	err = s.payoutRepo.CompletePayout(ctx, payout, reversal)
+	if errors.Is(err, repository.ErrPayoutNotPending) {
+		return nil, ErrPayoutAlreadySettled
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update payout: %w", err)
+	}
+
+	return toPayoutResponse(payout), nil
+}
+
+// Helper methods
+func (s *payoutService) findOwnedAccount(ctx context.Context, farmerID, accountID uuid.UUID) (*model.PayoutAccount, error) {
+	account, err := s.payoutRepo.FindAccountByID(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if account == nil {
+		return nil, ErrPayoutAccountNotFound
+	}
+	if account.FarmerID != farmerID {
+		return nil, ErrUnauthorized
+	}
+	return account, nil
+}
+
+// Helper functions
+func toPayoutAccountResponse(account *model.PayoutAccount) *dto.PayoutAccountResponse {
+	return &dto.PayoutAccountResponse{
+		ID:                account.ID,
+		AccountHolderName: account.AccountHolderName,
+		BankName:          account.BankName,
+		AccountNumber:     account.MaskedAccountNumber(),
+		RoutingCode:       account.RoutingCode,
+		IsDefault:         account.IsDefault,
+		CreatedAt:         account.CreatedAt,
+	}
+}
+
+func toPayoutResponse(payout *model.Payout) *dto.PayoutResponse {
+	return &dto.PayoutResponse{
+		ID:              payout.ID,
+		BatchID:         payout.BatchID,
+		FarmerID:        payout.FarmerID,
+		PayoutAccountID: payout.PayoutAccountID,
+		Amount:          payout.Amount,
+		Status:          payout.Status,
+		Reference:       payout.Reference,
+		FailureReason:   payout.FailureReason,
+		PaidAt:          payout.PaidAt,
+		CreatedAt:       payout.CreatedAt,
+	}
+}
+
+func toPayoutBatchResponse(batch *model.PayoutBatch) *dto.PayoutBatchResponse {
+	response := &dto.PayoutBatchResponse{
+		ID:          batch.ID,
+		Status:      batch.Status,
+		PayoutCount: batch.PayoutCount,
+		TotalAmount: batch.TotalAmount,
+		CreatedAt:   batch.CreatedAt,
+		CompletedAt: batch.CompletedAt,
+	}
+	for i := range batch.Payouts {
+		response.Payouts = append(response.Payouts, toPayoutResponse(&batch.Payouts[i]))
+	}
+	return response
+}
@@ -0,0 +1,133 @@
+package dto
+
+import (
+	model "agro_konnect/internal/harvest/model"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Request DTOs
+type CreateHarvestRequest struct {
+	ProductID      uuid.UUID  `json:"product_id" validate:"required"`
+	VariantID      *uuid.UUID `json:"variant_id"` // required for products with packaging options
+	ExpectedDate   time.Time  `json:"expected_date" validate:"required"`
+	ExpectedYield  float64    `json:"expected_yield" validate:"required,gt=0"`
+	PricePerUnit   float64    `json:"price_per_unit" validate:"required,min=0.01"`
+	DepositPercent float64    `json:"deposit_percent" validate:"min=0,max=100"`
+	MinReservation float64    `json:"min_reservation" validate:"min=0"`
+	Notes          string     `json:"notes" validate:"max=2000"`
+
+	// Defaults to the expected harvest date
+	ReservationDeadline *time.Time `json:"reservation_deadline"`
+}
+
+// UpdateHarvestRequest changes only the fields that are set. Reservations
+// already made keep their price and deposit.
+type UpdateHarvestRequest struct {
+	ExpectedDate        *time.Time `json:"expected_date"`
+	ExpectedYield       *float64   `json:"expected_yield" validate:"omitempty,gt=0"`
+	PricePerUnit        *float64   `json:"price_per_unit" validate:"omitempty,min=0.01"`
+	DepositPercent      *float64   `json:"deposit_percent" validate:"omitempty,min=0,max=100"`
+	MinReservation      *float64   `json:"min_reservation" validate:"omitempty,min=0"`
+	ReservationDeadline *time.Time `json:"reservation_deadline"`
+	Notes               *string    `json:"notes" validate:"omitempty,max=2000"`
+}
+
+type CompleteHarvestRequest struct {
+	ActualYield float64 `json:"actual_yield" validate:"min=0"`
+}
+
+type ReserveRequest struct {
+	Quantity          float64 `json:"quantity" validate:"required,gt=0"`
+	ShippingAddress   string  `json:"shipping_address" validate:"required"`
+	ShippingCity      string  `json:"shipping_city" validate:"required"`
+	ShippingState     string  `json:"shipping_state" validate:"required"`
+	ShippingZipCode   string  `json:"shipping_zip_code"`
+	ShippingLatitude  float64 `json:"shipping_latitude" validate:"omitempty,latitude"`
+	ShippingLongitude float64 `json:"shipping_longitude" validate:"omitempty,longitude"`
+	PaymentMethod     string  `json:"payment_method" validate:"required,oneof=bank_transfer credit_card digital_wallet upi cash_on_delivery"`
+}
+
+type DepositPaymentRequest struct {
+	PaymentMethod  string      `json:"payment_method" validate:"required,oneof=bank_transfer credit_card digital_wallet upi"`
+	PaymentDetails interface{} `json:"payment_details"`
+}
+
+type HarvestFilterRequest struct {
+	Category string `form:"category"`
+	Page     int    `form:"page" validate:"omitempty,min=1"`
+	PageSize int    `form:"page_size" validate:"omitempty,min=1,max=100"`
+}
+
+// Response DTOs
+type HarvestResponse struct {
+	ID          uuid.UUID  `json:"id"`
+	FarmerID    uuid.UUID  `json:"farmer_id"`
+	ProductID   uuid.UUID  `json:"product_id"`
+	ProductName string     `json:"product_name"`
+	VariantID   *uuid.UUID `json:"variant_id,omitempty"`
+	VariantName string     `json:"variant_name,omitempty"`
+	Category    string     `json:"category"`
+	Unit        string     `json:"unit"`
+
+	ExpectedDate        time.Time `json:"expected_date"`
+	ExpectedYield       float64   `json:"expected_yield"`
+	ReservedQuantity    float64   `json:"reserved_quantity"`
+	AvailableToReserve  float64   `json:"available_to_reserve"`
+	PricePerUnit        float64   `json:"price_per_unit"`
+	DepositPercent      float64   `json:"deposit_percent"`
+	MinReservation      float64   `json:"min_reservation"`
+	ReservationDeadline time.Time `json:"reservation_deadline"`
+	Notes               string    `json:"notes"`
+
+	Status      model.HarvestStatus `json:"status"`
+	ActualYield *float64            `json:"actual_yield,omitempty"`
+	CompletedAt *time.Time          `json:"completed_at,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ReservationResponse struct {
+	ID        uuid.UUID `json:"id"`
+	HarvestID uuid.UUID `json:"harvest_id"`
+	BuyerID   uuid.UUID `json:"buyer_id"`
+
+	Quantity          float64 `json:"quantity"`
+	AllocatedQuantity float64 `json:"allocated_quantity"`
+	UnitPrice         float64 `json:"unit_price"`
+	TotalPrice        float64 `json:"total_price"` // of the allocated quantity once allocated
+
+	DepositAmount float64             `json:"deposit_amount"`
+	DepositStatus model.DepositStatus `json:"deposit_status"`
+	DepositRefund float64             `json:"deposit_refund,omitempty"`
+
+	ShippingAddress string `json:"shipping_address"`
+	ShippingCity    string `json:"shipping_city"`
+	ShippingState   string `json:"shipping_state"`
+	PaymentMethod   string `json:"payment_method"`
+
+	Status  model.ReservationStatus `json:"status"`
+	OrderID *uuid.UUID              `json:"order_id,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CompleteHarvestResponse summarises turning a harvest's reservations into
+// orders.
+type CompleteHarvestResponse struct {
+	Harvest      *HarvestResponse       `json:"harvest"`
+	FillRatio    float64                `json:"fill_ratio"` // share of each reservation that could be filled
+	Converted    int                    `json:"converted"`
+	Unfilled     int                    `json:"unfilled"`
+	Failed       int                    `json:"failed"` // still allocated; completing again retries them
+	Reservations []*ReservationResponse `json:"reservations"`
+}
+
+type HarvestListResponse struct {
+	Harvests []*HarvestResponse `json:"harvests"`
+	Total    int64              `json:"total"`
+	Page     int                `json:"page"`
+	Pages    int                `json:"pages"`
+	HasMore  bool               `json:"has_more"`
+}
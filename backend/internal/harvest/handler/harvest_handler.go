@@ -0,0 +1,513 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	dto "agro_konnect/internal/harvest/dto"
+	"agro_konnect/internal/harvest/service"
+	"agro_konnect/internal/harvest/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type HarvestHandler struct {
+	harvestService service.HarvestService
+}
+
+func NewHarvestHandler(harvestService service.HarvestService) *HarvestHandler {
+	return &HarvestHandler{harvestService: harvestService}
+}
+
+// GetOpenHarvests lists harvests open for reservation
+// @Summary Get open harvests
+// @Description List upcoming harvests still taking reservations, soonest harvest first
+// @Tags harvests
+// @Produce json
+// @Param category query string false "Product category"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Success 200 {object} utils.SuccessResponse{data=dto.HarvestListResponse} "Harvests retrieved successfully"
+// @Failure 400 {object} utils.ErrorResponse "Invalid query parameters"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /harvests [get]
+func (h *HarvestHandler) GetOpenHarvests(c *gin.Context) {
+	filters, ok := bindFilters(c)
+	if !ok {
+		return
+	}
+
+	harvests, err := h.harvestService.GetOpenHarvests(c.Request.Context(), filters)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to retrieve harvests")
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, "Harvests retrieved successfully", harvests)
+}
+
+// GetHarvest gets a harvest
+// @Summary Get harvest
+// @Description Get an upcoming or past harvest with how much of it is still available to reserve
+// @Tags harvests
+// @Produce json
+// @Param id path string true "Harvest ID"
+// @Success 200 {object} utils.SuccessResponse{data=dto.HarvestResponse} "Harvest retrieved successfully"
+// @Failure 400 {object} utils.ErrorResponse "Invalid harvest ID"
+// @Failure 404 {object} utils.ErrorResponse "Harvest not found"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /harvests/{id} [get]
+func (h *HarvestHandler) GetHarvest(c *gin.Context) {
+	harvestID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid harvest ID")
+		return
+	}
+
+	harvest, err := h.harvestService.GetHarvest(c.Request.Context(), harvestID)
+	if err != nil {
+		respondWithHarvestError(c, err, "Failed to retrieve harvest")
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, "Harvest retrieved successfully", harvest)
+}
+
+// CreateHarvest lists an upcoming harvest
+// @Summary Create harvest
+// @Description List an upcoming crop of one of the farmer's products so buyers can reserve part of the expected yield, optionally against a deposit
+// @Tags harvests
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.CreateHarvestRequest true "Harvest data"
+// @Success 201 {object} utils.SuccessResponse{data=dto.HarvestResponse} "Harvest created successfully"
+// @Failure 400 {object} utils.ErrorResponse "Invalid input data"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 403 {object} utils.ErrorResponse "Forbidden"
+// @Failure 404 {object} utils.ErrorResponse "Farmer profile not found"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /harvests [post]
+func (h *HarvestHandler) CreateHarvest(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	var req dto.CreateHarvestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid input data: "+err.Error())
+		return
+	}
+
+	if err := utils.ValidateStruct(req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	harvest, err := h.harvestService.CreateHarvest(c.Request.Context(), userID, &req)
+	if err != nil {
+		respondWithHarvestError(c, err, "Failed to create harvest")
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusCreated, "Harvest created successfully", harvest)
+}
+
+// GetMyHarvests lists the farmer's harvests
+// @Summary Get my harvests
+// @Description List the farmer's harvests in any status, latest expected date first
+// @Tags harvests
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Success 200 {object} utils.SuccessResponse{data=dto.HarvestListResponse} "Harvests retrieved successfully"
+// @Failure 400 {object} utils.ErrorResponse "Invalid query parameters"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 404 {object} utils.ErrorResponse "Farmer profile not found"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /harvests/me [get]
+func (h *HarvestHandler) GetMyHarvests(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	filters, ok := bindFilters(c)
+	if !ok {
+		return
+	}
+
+	harvests, err := h.harvestService.GetMyHarvests(c.Request.Context(), userID, filters)
+	if err != nil {
+		respondWithHarvestError(c, err, "Failed to retrieve harvests")
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, "Harvests retrieved successfully", harvests)
+}
+
+// UpdateHarvest changes an open harvest
+// @Summary Update harvest
+// @Description Update the fields that are set on an open harvest. The expected yield cannot drop below what is already reserved
+// @Tags harvests
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Harvest ID"
+// @Param request body dto.UpdateHarvestRequest true "Harvest changes"
+// @Success 200 {object} utils.SuccessResponse{data=dto.HarvestResponse} "Harvest updated successfully"
+// @Failure 400 {object} utils.ErrorResponse "Invalid input data"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 403 {object} utils.ErrorResponse "Forbidden"
+// @Failure 404 {object} utils.ErrorResponse "Harvest not found"
+// @Failure 409 {object} utils.ErrorResponse "Harvest no longer open"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /harvests/{id} [put]
+func (h *HarvestHandler) UpdateHarvest(c *gin.Context) {
+	userID, harvestID, ok := pathParams(c, "id", "Invalid harvest ID")
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateHarvestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid input data: "+err.Error())
+		return
+	}
+
+	if err := utils.ValidateStruct(req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	harvest, err := h.harvestService.UpdateHarvest(c.Request.Context(), harvestID, userID, &req)
+	if err != nil {
+		respondWithHarvestError(c, err, "Failed to update harvest")
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, "Harvest updated successfully", harvest)
+}
+
+// CompleteHarvest records the actual yield and places the orders
+// @Summary Complete harvest
+// @Description Record the actual yield, put it on sale and turn reservations into orders at the reserved price. A short harvest is shared pro rata and unpaid deposits drop their reservation. Completing again retries orders that failed
+// @Tags harvests
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Harvest ID"
+// @Param request body dto.CompleteHarvestRequest true "Actual yield"
+// @Success 200 {object} utils.SuccessResponse{data=dto.CompleteHarvestResponse} "Harvest completed successfully"
+// @Failure 400 {object} utils.ErrorResponse "Invalid input data"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 403 {object} utils.ErrorResponse "Forbidden"
+// @Failure 404 {object} utils.ErrorResponse "Harvest not found"
+// @Failure 409 {object} utils.ErrorResponse "Harvest cancelled"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /harvests/{id}/complete [post]
+func (h *HarvestHandler) CompleteHarvest(c *gin.Context) {
+	userID, harvestID, ok := pathParams(c, "id", "Invalid harvest ID")
+	if !ok {
+		return
+	}
+
+	var req dto.CompleteHarvestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid input data: "+err.Error())
+		return
+	}
+
+	if err := utils.ValidateStruct(req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := h.harvestService.CompleteHarvest(c.Request.Context(), harvestID, userID, &req)
+	if err != nil {
+		respondWithHarvestError(c, err, "Failed to complete harvest")
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, "Harvest completed successfully", result)
+}
+
+// CancelHarvest withdraws an open harvest
+// @Summary Cancel harvest
+// @Description Withdraw an open harvest, for example after a crop failure. Reservations are cancelled and paid deposits refunded
+// @Tags harvests
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Harvest ID"
+// @Success 200 {object} utils.SuccessResponse "Harvest cancelled successfully"
+// @Failure 400 {object} utils.ErrorResponse "Invalid harvest ID"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 403 {object} utils.ErrorResponse "Forbidden"
+// @Failure 404 {object} utils.ErrorResponse "Harvest not found"
+// @Failure 409 {object} utils.ErrorResponse "Harvest no longer open"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /harvests/{id}/cancel [post]
+func (h *HarvestHandler) CancelHarvest(c *gin.Context) {
+	userID, harvestID, ok := pathParams(c, "id", "Invalid harvest ID")
+	if !ok {
+		return
+	}
+
+	if err := h.harvestService.CancelHarvest(c.Request.Context(), harvestID, userID); err != nil {
+		respondWithHarvestError(c, err, "Failed to cancel harvest")
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, "Harvest cancelled successfully", nil)
+}
+
+// GetHarvestReservations lists the reservations on a harvest
+// @Summary Get harvest reservations
+// @Description List the reservations on one of the farmer's harvests, first come first
+// @Tags harvests
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Harvest ID"
+// @Success 200 {object} utils.SuccessResponse{data=[]dto.ReservationResponse} "Reservations retrieved successfully"
+// @Failure 400 {object} utils.ErrorResponse "Invalid harvest ID"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 403 {object} utils.ErrorResponse "Forbidden"
+// @Failure 404 {object} utils.ErrorResponse "Harvest not found"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /harvests/{id}/reservations [get]
+func (h *HarvestHandler) GetHarvestReservations(c *gin.Context) {
+	userID, harvestID, ok := pathParams(c, "id", "Invalid harvest ID")
+	if !ok {
+		return
+	}
+
+	reservations, err := h.harvestService.GetHarvestReservations(c.Request.Context(), harvestID, userID)
+	if err != nil {
+		respondWithHarvestError(c, err, "Failed to retrieve reservations")
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, "Reservations retrieved successfully", reservations)
+}
+
+// Reserve reserves part of a harvest
+// @Summary Reserve harvest
+// @Description Reserve part of a harvest's expected yield at its listed price, with the delivery details for the order placed at harvest. Any deposit is due straight away
+// @Tags harvests
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Harvest ID"
+// @Param request body dto.ReserveRequest true "Reservation data"
+// @Success 201 {object} utils.SuccessResponse{data=dto.ReservationResponse} "Harvest reserved successfully"
+// @Failure 400 {object} utils.ErrorResponse "Invalid input data"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 403 {object} utils.ErrorResponse "Forbidden"
+// @Failure 404 {object} utils.ErrorResponse "Harvest not found"
+// @Failure 409 {object} utils.ErrorResponse "Harvest closed or fully reserved"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /harvests/{id}/reservations [post]
+func (h *HarvestHandler) Reserve(c *gin.Context) {
+	userID, harvestID, ok := pathParams(c, "id", "Invalid harvest ID")
+	if !ok {
+		return
+	}
+
+	var req dto.ReserveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid input data: "+err.Error())
+		return
+	}
+
+	if err := utils.ValidateStruct(req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	reservation, err := h.harvestService.Reserve(c.Request.Context(), harvestID, userID, &req)
+	if err != nil {
+		respondWithHarvestError(c, err, "Failed to reserve harvest")
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusCreated, "Harvest reserved successfully", reservation)
+}
+
+// GetMyReservations lists the buyer's harvest reservations
+// @Summary Get my reservations
+// @Description List the buyer's harvest reservations, latest first
+// @Tags harvests
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.SuccessResponse{data=[]dto.ReservationResponse} "Reservations retrieved successfully"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /harvests/reservations/me [get]
+func (h *HarvestHandler) GetMyReservations(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	reservations, err := h.harvestService.GetMyReservations(c.Request.Context(), userID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to retrieve reservations")
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, "Reservations retrieved successfully", reservations)
+}
+
+// PayDeposit pays the deposit on a reservation
+// @Summary Pay reservation deposit
+// @Description Pay the deposit asked for on a harvest reservation. Reservations with an unpaid deposit are dropped at harvest
+// @Tags harvests
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param reservationId path string true "Reservation ID"
+// @Param request body dto.DepositPaymentRequest true "Payment data"
+// @Success 200 {object} utils.SuccessResponse{data=dto.ReservationResponse} "Deposit paid successfully"
+// @Failure 400 {object} utils.ErrorResponse "Invalid input data"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 403 {object} utils.ErrorResponse "Forbidden"
+// @Failure 404 {object} utils.ErrorResponse "Reservation not found"
+// @Failure 409 {object} utils.ErrorResponse "No deposit due"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /harvests/reservations/{reservationId}/deposit [post]
+func (h *HarvestHandler) PayDeposit(c *gin.Context) {
+	userID, reservationID, ok := pathParams(c, "reservationId", "Invalid reservation ID")
+	if !ok {
+		return
+	}
+
+	var req dto.DepositPaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid input data: "+err.Error())
+		return
+	}
+
+	if err := utils.ValidateStruct(req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	reservation, err := h.harvestService.PayDeposit(c.Request.Context(), reservationID, userID, &req)
+	if err != nil {
+		respondWithHarvestError(c, err, "Failed to pay deposit")
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, "Deposit paid successfully", reservation)
+}
+
+// CancelReservation cancels a harvest reservation
+// @Summary Cancel reservation
+// @Description Cancel a reservation while the harvest is still taking reservations. A paid deposit is refunded in full
+// @Tags harvests
+// @Produce json
+// @Security BearerAuth
+// @Param reservationId path string true "Reservation ID"
+// @Success 200 {object} utils.SuccessResponse{data=dto.ReservationResponse} "Reservation cancelled successfully"
+// @Failure 400 {object} utils.ErrorResponse "Invalid reservation ID"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 403 {object} utils.ErrorResponse "Forbidden"
+// @Failure 404 {object} utils.ErrorResponse "Reservation not found"
+// @Failure 409 {object} utils.ErrorResponse "Reservation can no longer be changed"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /harvests/reservations/{reservationId}/cancel [post]
+func (h *HarvestHandler) CancelReservation(c *gin.Context) {
+	userID, reservationID, ok := pathParams(c, "reservationId", "Invalid reservation ID")
+	if !ok {
+		return
+	}
+
+	reservation, err := h.harvestService.CancelReservation(c.Request.Context(), reservationID, userID)
+	if err != nil {
+		respondWithHarvestError(c, err, "Failed to cancel reservation")
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, "Reservation cancelled successfully", reservation)
+}
+
+func bindFilters(c *gin.Context) (dto.HarvestFilterRequest, bool) {
+	var filters dto.HarvestFilterRequest
+	if err := c.ShouldBindQuery(&filters); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid query parameters: "+err.Error())
+		return filters, false
+	}
+	if err := utils.ValidateStruct(filters); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		return filters, false
+	}
+	return filters, true
+}
+
+func pathParams(c *gin.Context, param, invalidMessage string) (userID, id uuid.UUID, ok bool) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	id, err = uuid.Parse(c.Param(param))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, invalidMessage)
+		return
+	}
+	return userID, id, true
+}
+
+func respondWithHarvestError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrHarvestNotFound), errors.Is(err, service.ErrReservationNotFound):
+		utils.RespondWithError(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrFarmerNotFound):
+		utils.RespondWithError(c, http.StatusNotFound, "Farmer profile not found. Please create a farmer profile first")
+	case errors.Is(err, service.ErrUnauthorizedAccess):
+		utils.RespondWithError(c, http.StatusForbidden, err.Error())
+	case errors.Is(err, service.ErrHarvestNotOpen),
+		errors.Is(err, service.ErrHarvestNotCompletable),
+		errors.Is(err, service.ErrInsufficientYield),
+		errors.Is(err, service.ErrReservationLocked),
+		errors.Is(err, service.ErrNoDepositDue):
+		utils.RespondWithError(c, http.StatusConflict, err.Error())
+	case errors.Is(err, service.ErrInvalidHarvestProduct),
+		errors.Is(err, service.ErrVariantRequired),
+		errors.Is(err, service.ErrInvalidHarvestDates),
+		errors.Is(err, service.ErrYieldBelowReserved),
+		errors.Is(err, service.ErrBelowMinReservation):
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+	default:
+		utils.RespondWithError(c, http.StatusInternalServerError, fallback)
+	}
+}
+
+// GetUserIDFromContext extracts user ID from Gin context
+func GetUserIDFromContext(c *gin.Context) (uuid.UUID, error) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		return uuid.Nil, errors.New("user ID not found in context")
+	}
+
+	switch v := userID.(type) {
+	case uuid.UUID:
+		return v, nil
+	case string:
+		parsedUUID, err := uuid.Parse(v)
+		if err != nil {
+			return uuid.Nil, fmt.Errorf("invalid user ID format: %w", err)
+		}
+		return parsedUUID, nil
+	default:
+		return uuid.Nil, errors.New("invalid user ID type in context")
+	}
+}
@@ -0,0 +1,137 @@
+package model
+
+import (
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type HarvestStatus string
+
+const (
+	HarvestStatusOpen      HarvestStatus = "open"      // taking reservations
+	HarvestStatusCompleted HarvestStatus = "completed" // harvested; reservations turned into orders
+	HarvestStatusCancelled HarvestStatus = "cancelled" // crop lost or listing withdrawn
+)
+
+type ReservationStatus string
+
+const (
+	ReservationStatusReserved   ReservationStatus = "reserved"
+	ReservationStatusAllocated  ReservationStatus = "allocated"  // share of the actual yield fixed, order not yet placed
+	ReservationStatusConverting ReservationStatus = "converting" // order being placed
+	ReservationStatusConverted  ReservationStatus = "converted"  // order placed
+	ReservationStatusUnfilled   ReservationStatus = "unfilled"   // nothing left for it after pro-rating
+	ReservationStatusCancelled  ReservationStatus = "cancelled"
+)
+
+type DepositStatus string
+
+const (
+	DepositStatusNone     DepositStatus = "none" // no deposit asked
+	DepositStatusPending  DepositStatus = "pending"
+	DepositStatusPaid     DepositStatus = "paid"
+	DepositStatusRefunded DepositStatus = "refunded" // fully or, after pro-rating, in part
+)
+
+// Harvest is an upcoming crop of one of a farmer's products. Buyers reserve
+// part of the expected yield at the listed price; once the farmer records
+// the actual yield the reservations become orders.
+type Harvest struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	FarmerID  uuid.UUID `gorm:"type:uuid;not null;index" json:"farmer_id"`
+	ProductID uuid.UUID `gorm:"type:uuid;not null;index" json:"product_id"`
+	// Packaging option the crop is sold in, for products that have them.
+	// Yields, reservations and prices are then in its packs.
+	VariantID *uuid.UUID `gorm:"type:uuid" json:"variant_id"`
+
+	ExpectedDate     time.Time `gorm:"not null" json:"expected_date"`
+	ExpectedYield    float64   `gorm:"type:decimal(10,2);not null" json:"expected_yield"` // in the product's unit
+	ReservedQuantity float64   `gorm:"type:decimal(10,2);default:0" json:"reserved_quantity"`
+	PricePerUnit     float64   `gorm:"type:decimal(10,2);not null" json:"price_per_unit"`
+	DepositPercent   float64   `gorm:"type:decimal(5,2);default:0" json:"deposit_percent"`
+	MinReservation   float64   `gorm:"type:decimal(10,2);default:0" json:"min_reservation"`
+
+	// Reservations close at this time, by default the expected harvest date
+	ReservationDeadline time.Time `gorm:"not null" json:"reservation_deadline"`
+	Notes               string    `gorm:"type:text" json:"notes"`
+
+	Status      HarvestStatus `gorm:"type:varchar(20);default:'open';index" json:"status"`
+	ActualYield *float64      `gorm:"type:decimal(10,2)" json:"actual_yield"`
+	CompletedAt *time.Time    `json:"completed_at"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// IsOpen reports whether the harvest still takes reservations.
+func (h *Harvest) IsOpen(at time.Time) bool {
+	return h.Status == HarvestStatusOpen && at.Before(h.ReservationDeadline)
+}
+
+// HarvestReservation is a buyer's claim on part of a harvest. It carries the
+// delivery details needed to place the order later.
+type HarvestReservation struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	HarvestID uuid.UUID `gorm:"type:uuid;not null;index" json:"harvest_id"`
+	BuyerID   uuid.UUID `gorm:"type:uuid;not null;index" json:"buyer_id"` // buyer's user ID
+
+	Quantity          float64 `gorm:"type:decimal(10,2);not null" json:"quantity"`
+	AllocatedQuantity float64 `gorm:"type:decimal(10,2);default:0" json:"allocated_quantity"`
+	UnitPrice         float64 `gorm:"type:decimal(10,2);not null" json:"unit_price"`
+
+	DepositAmount    float64       `gorm:"type:decimal(10,2);default:0" json:"deposit_amount"`
+	DepositStatus    DepositStatus `gorm:"type:varchar(20);not null" json:"deposit_status"`
+	DepositPaymentID string        `json:"deposit_payment_id"`
+	DepositRefund    float64       `gorm:"type:decimal(10,2);default:0" json:"deposit_refund"`
+
+	ShippingAddress   string  `gorm:"not null" json:"shipping_address"`
+	ShippingCity      string  `gorm:"not null" json:"shipping_city"`
+	ShippingState     string  `gorm:"not null" json:"shipping_state"`
+	ShippingZipCode   string  `json:"shipping_zip_code"`
+	ShippingLatitude  float64 `json:"shipping_latitude"`
+	ShippingLongitude float64 `json:"shipping_longitude"`
+	PaymentMethod     string  `gorm:"type:varchar(30);not null" json:"payment_method"`
+
+	Status  ReservationStatus `gorm:"type:varchar(20);not null;index" json:"status"`
+	OrderID *uuid.UUID        `gorm:"type:uuid" json:"order_id"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Allocate fixes the reservation's share of a short harvest. Ratio is the
+// actual yield over the total reserved, capped at 1. Shares are rounded down
+// to the cent so that they never add up to more than was harvested. The
+// part of the deposit no longer covered by the allocation is marked for
+// refund.
+func (r *HarvestReservation) Allocate(ratio float64) {
+	r.AllocatedQuantity = r.Quantity
+	if ratio < 1 {
+		// The small epsilon keeps float error from costing a whole cent
+		r.AllocatedQuantity = math.Floor(r.Quantity*ratio*100+1e-6) / 100
+	}
+
+	if r.AllocatedQuantity <= 0 {
+		r.AllocatedQuantity = 0
+		r.Status = ReservationStatusUnfilled
+	} else {
+		r.Status = ReservationStatusAllocated
+	}
+
+	if r.DepositStatus == DepositStatusPaid {
+		if excess := r.DepositAmount - r.AllocatedQuantity*r.UnitPrice; excess > 0 {
+			r.DepositRefund = math.Round(excess*100) / 100
+			r.DepositStatus = DepositStatusRefunded
+		}
+	}
+}
+
+// DepositApplied is the part of the deposit that counts towards the order.
+func (r *HarvestReservation) DepositApplied() float64 {
+	if r.DepositStatus != DepositStatusPaid && r.DepositStatus != DepositStatusRefunded {
+		return 0
+	}
+	return r.DepositAmount - r.DepositRefund
+}
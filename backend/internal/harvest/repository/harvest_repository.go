@@ -0,0 +1,351 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"agro_konnect/internal/common"
+	dto "agro_konnect/internal/harvest/dto"
+	model "agro_konnect/internal/harvest/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// HarvestRow is a harvest with the product and farmer details shown with it.
+// Unit is the variant's, for harvests sold in packs.
+type HarvestRow struct {
+	model.Harvest
+	ProductName  string
+	VariantName  string
+	Category     string
+	Unit         string
+	FarmerUserID uuid.UUID
+}
+
+// Allocation settles a harvest's reservations on completion. It is given
+// them locked, first come first, and returns the ones it changed.
+type Allocation func(reservations []*model.HarvestReservation) []*model.HarvestReservation
+
+type HarvestRepository interface {
+	Create(ctx context.Context, harvest *model.Harvest) error
+	FindByID(ctx context.Context, id uuid.UUID) (*HarvestRow, error)
+	FindOpen(ctx context.Context, filters dto.HarvestFilterRequest, now time.Time) ([]*HarvestRow, int64, error)
+	FindByFarmerID(ctx context.Context, farmerID uuid.UUID, page, pageSize int) ([]*HarvestRow, int64, error)
+	Update(ctx context.Context, harvest *model.Harvest) error
+	Complete(ctx context.Context, harvest *model.Harvest, allocate Allocation) (bool, error)
+	Cancel(ctx context.Context, harvestID uuid.UUID) (bool, error)
+
+	Reserve(ctx context.Context, reservation *model.HarvestReservation, now time.Time) (bool, error)
+	CancelReservation(ctx context.Context, reservation *model.HarvestReservation) (bool, error)
+	MarkDepositPaid(ctx context.Context, reservationID uuid.UUID, paymentID string) (bool, error)
+	ClaimForConversion(ctx context.Context, reservationID uuid.UUID) (bool, error)
+	ReleaseConversion(ctx context.Context, reservationID uuid.UUID) error
+	MarkConverted(ctx context.Context, reservationID, orderID uuid.UUID) error
+	FindReservationByID(ctx context.Context, id uuid.UUID) (*model.HarvestReservation, error)
+	FindReservationsByHarvestID(ctx context.Context, harvestID uuid.UUID) ([]*model.HarvestReservation, error)
+	FindReservationsByBuyerID(ctx context.Context, buyerID uuid.UUID) ([]*model.HarvestReservation, error)
+
+	CreateNotifications(ctx context.Context, notifications []*common.Notification) error
+}
+
+type harvestRepository struct {
+	db *gorm.DB
+}
+
+func NewHarvestRepository(db *gorm.DB) HarvestRepository {
+	return &harvestRepository{db: db}
+}
+
+func (r *harvestRepository) Create(ctx context.Context, harvest *model.Harvest) error {
+	return r.db.WithContext(ctx).Create(harvest).Error
+}
+
+func (r *harvestRepository) rows(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).
+		Table("harvests").
+		Select("harvests.*, products.name AS product_name, COALESCE(product_variants.name, '') AS variant_name, products.category, COALESCE(product_variants.unit, products.unit) AS unit, farmers.user_id AS farmer_user_id").
+		Joins("JOIN products ON products.id = harvests.product_id").
+		Joins("LEFT JOIN product_variants ON product_variants.id = harvests.variant_id").
+		Joins("JOIN farmers ON farmers.id = harvests.farmer_id")
+}
+
+func (r *harvestRepository) FindByID(ctx context.Context, id uuid.UUID) (*HarvestRow, error) {
+	var row HarvestRow
+	err := r.rows(ctx).Where("harvests.id = ?", id).Take(&row).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &row, err
+}
+
+// FindOpen lists harvests taking reservations, soonest harvest first.
+func (r *harvestRepository) FindOpen(ctx context.Context, filters dto.HarvestFilterRequest, now time.Time) ([]*HarvestRow, int64, error) {
+	query := r.rows(ctx).
+		Where("harvests.status = ? AND harvests.reservation_deadline > ?", model.HarvestStatusOpen, now)
+	if filters.Category != "" {
+		query = query.Where("products.category = ?", filters.Category)
+	}
+	return paginate(query.Order("harvests.expected_date ASC"), filters.Page, filters.PageSize)
+}
+
+func (r *harvestRepository) FindByFarmerID(ctx context.Context, farmerID uuid.UUID, page, pageSize int) ([]*HarvestRow, int64, error) {
+	query := r.rows(ctx).Where("harvests.farmer_id = ?", farmerID).Order("harvests.expected_date DESC")
+	return paginate(query, page, pageSize)
+}
+
+func paginate(query *gorm.DB, page, pageSize int) ([]*HarvestRow, int64, error) {
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var rows []*HarvestRow
+	err := query.Offset((page - 1) * pageSize).Limit(pageSize).Find(&rows).Error
+	return rows, total, err
+}
+
+func (r *harvestRepository) Update(ctx context.Context, harvest *model.Harvest) error {
+	return r.db.WithContext(ctx).Save(harvest).Error
+}
+
+// Complete records the actual yield, puts it on sale as the stock of the
+// product or the harvest's variant, and stores each reservation's
+// allocation, all at once. The reservations are locked before they are
+// allocated, so a deposit paid meanwhile is either seen or turned away. It
+// reports false if the harvest was no longer open.
+func (r *harvestRepository) Complete(ctx context.Context, harvest *model.Harvest, allocate Allocation) (bool, error) {
+	completed := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Harvest{}).
+			Where("id = ? AND status = ?", harvest.ID, model.HarvestStatusOpen).
+			Updates(map[string]interface{}{
+				"status":       model.HarvestStatusCompleted,
+				"actual_yield": harvest.ActualYield,
+				"completed_at": harvest.CompletedAt,
+				"updated_at":   time.Now(),
+			})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		// A product with variants holds its stock in them, and its own is
+		// their total
+		productStock := *harvest.ActualYield
+		if harvest.VariantID != nil {
+			productStock = 0
+			if err := tx.Exec(`
+				UPDATE product_variants SET available_stock = available_stock + ?, updated_at = ?
+				WHERE id = ?`,
+				*harvest.ActualYield, time.Now(), *harvest.VariantID).Error; err != nil {
+				return err
+			}
+		}
+
+		// Fresh stock restarts the product's shelf life
+		if err := tx.Exec(`
+			UPDATE products SET
+				available_stock = available_stock + ?,
+				status = 'active',
+				harvest_date = ?,
+				expiry_date = CASE WHEN shelf_life > 0 THEN ?::timestamptz + shelf_life * INTERVAL '1 day' ELSE expiry_date END,
+				updated_at = ?
+			WHERE id = ?`,
+			productStock, harvest.CompletedAt, harvest.CompletedAt, time.Now(), harvest.ProductID).Error; err != nil {
+			return err
+		}
+		if harvest.VariantID != nil {
+			if err := tx.Exec(`
+				UPDATE products SET available_stock = v.total
+				FROM (
+					SELECT COALESCE(SUM(available_stock * pack_size), 0) AS total
+					FROM product_variants WHERE product_id = ? AND is_active
+				) v
+				WHERE products.id = ?`,
+				harvest.ProductID, harvest.ProductID).Error; err != nil {
+				return err
+			}
+		}
+
+		var reservations []*model.HarvestReservation
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("harvest_id = ?", harvest.ID).
+			Order("created_at ASC").
+			Find(&reservations).Error; err != nil {
+			return err
+		}
+
+		for _, reservation := range allocate(reservations) {
+			if err := tx.Model(&model.HarvestReservation{}).
+				Where("id = ?", reservation.ID).
+				Updates(map[string]interface{}{
+					"status":             reservation.Status,
+					"allocated_quantity": reservation.AllocatedQuantity,
+					"deposit_status":     reservation.DepositStatus,
+					"deposit_refund":     reservation.DepositRefund,
+					"updated_at":         time.Now(),
+				}).Error; err != nil {
+				return err
+			}
+		}
+
+		completed = true
+		return nil
+	})
+	return completed && err == nil, err
+}
+
+// Cancel withdraws an open harvest and cancels its reservations, refunding
+// any deposits paid.
+func (r *harvestRepository) Cancel(ctx context.Context, harvestID uuid.UUID) (bool, error) {
+	cancelled := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Harvest{}).
+			Where("id = ? AND status = ?", harvestID, model.HarvestStatusOpen).
+			Updates(map[string]interface{}{"status": model.HarvestStatusCancelled, "updated_at": time.Now()})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		cancelled = true
+		return tx.Exec(`
+			UPDATE harvest_reservations SET
+				status = ?,
+				deposit_refund = CASE WHEN deposit_status = ? THEN deposit_amount ELSE deposit_refund END,
+				deposit_status = CASE WHEN deposit_status = ? THEN ? ELSE deposit_status END,
+				updated_at = ?
+			WHERE harvest_id = ? AND status = ?`,
+			model.ReservationStatusCancelled,
+			model.DepositStatusPaid,
+			model.DepositStatusPaid, model.DepositStatusRefunded,
+			time.Now(), harvestID, model.ReservationStatusReserved).Error
+	})
+	return cancelled && err == nil, err
+}
+
+// Reserve takes the reservation's quantity out of what is left of the
+// expected yield, if enough is left and reservations are still open.
+func (r *harvestRepository) Reserve(ctx context.Context, reservation *model.HarvestReservation, now time.Time) (bool, error) {
+	reserved := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Harvest{}).
+			Where("id = ? AND status = ? AND reservation_deadline > ? AND reserved_quantity + ? <= expected_yield",
+				reservation.HarvestID, model.HarvestStatusOpen, now, reservation.Quantity).
+			Updates(map[string]interface{}{
+				"reserved_quantity": gorm.Expr("reserved_quantity + ?", reservation.Quantity),
+				"updated_at":        now,
+			})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		reserved = true
+		return tx.Create(reservation).Error
+	})
+	return reserved && err == nil, err
+}
+
+// CancelReservation cancels a reservation that has not been allocated yet
+// and gives its quantity back to the harvest.
+func (r *harvestRepository) CancelReservation(ctx context.Context, reservation *model.HarvestReservation) (bool, error) {
+	cancelled := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.HarvestReservation{}).
+			Where("id = ? AND status = ?", reservation.ID, model.ReservationStatusReserved).
+			Updates(map[string]interface{}{
+				"status":         model.ReservationStatusCancelled,
+				"deposit_status": reservation.DepositStatus,
+				"deposit_refund": reservation.DepositRefund,
+				"updated_at":     time.Now(),
+			})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		cancelled = true
+		return tx.Model(&model.Harvest{}).
+			Where("id = ?", reservation.HarvestID).
+			Updates(map[string]interface{}{
+				"reserved_quantity": gorm.Expr("reserved_quantity - ?", reservation.Quantity),
+				"updated_at":        time.Now(),
+			}).Error
+	})
+	return cancelled && err == nil, err
+}
+
+func (r *harvestRepository) MarkDepositPaid(ctx context.Context, reservationID uuid.UUID, paymentID string) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&model.HarvestReservation{}).
+		Where("id = ? AND status = ? AND deposit_status = ?", reservationID, model.ReservationStatusReserved, model.DepositStatusPending).
+		Updates(map[string]interface{}{
+			"deposit_status":     model.DepositStatusPaid,
+			"deposit_payment_id": paymentID,
+			"updated_at":         time.Now(),
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// ClaimForConversion marks an allocated reservation as having its order
+// placed. It reports false if it was not allocated, for example because
+// another request is already converting it.
+func (r *harvestRepository) ClaimForConversion(ctx context.Context, reservationID uuid.UUID) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&model.HarvestReservation{}).
+		Where("id = ? AND status = ?", reservationID, model.ReservationStatusAllocated).
+		Updates(map[string]interface{}{"status": model.ReservationStatusConverting, "updated_at": time.Now()})
+	return result.RowsAffected > 0, result.Error
+}
+
+// ReleaseConversion hands a claimed reservation back when its order could
+// not be placed, so that it is retried.
+func (r *harvestRepository) ReleaseConversion(ctx context.Context, reservationID uuid.UUID) error {
+	return r.db.WithContext(ctx).Model(&model.HarvestReservation{}).
+		Where("id = ? AND status = ?", reservationID, model.ReservationStatusConverting).
+		Updates(map[string]interface{}{"status": model.ReservationStatusAllocated, "updated_at": time.Now()}).Error
+}
+
+func (r *harvestRepository) MarkConverted(ctx context.Context, reservationID, orderID uuid.UUID) error {
+	return r.db.WithContext(ctx).Model(&model.HarvestReservation{}).
+		Where("id = ? AND status = ?", reservationID, model.ReservationStatusConverting).
+		Updates(map[string]interface{}{
+			"status":     model.ReservationStatusConverted,
+			"order_id":   orderID,
+			"updated_at": time.Now(),
+		}).Error
+}
+
+func (r *harvestRepository) FindReservationByID(ctx context.Context, id uuid.UUID) (*model.HarvestReservation, error) {
+	var reservation model.HarvestReservation
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&reservation).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &reservation, err
+}
+
+// FindReservationsByHarvestID returns a harvest's reservations, first come
+// first.
+func (r *harvestRepository) FindReservationsByHarvestID(ctx context.Context, harvestID uuid.UUID) ([]*model.HarvestReservation, error) {
+	var reservations []*model.HarvestReservation
+	err := r.db.WithContext(ctx).
+		Where("harvest_id = ?", harvestID).
+		Order("created_at ASC").
+		Find(&reservations).Error
+	return reservations, err
+}
+
+func (r *harvestRepository) FindReservationsByBuyerID(ctx context.Context, buyerID uuid.UUID) ([]*model.HarvestReservation, error) {
+	var reservations []*model.HarvestReservation
+	err := r.db.WithContext(ctx).
+		Where("buyer_id = ?", buyerID).
+		Order("created_at DESC").
+		Find(&reservations).Error
+	return reservations, err
+}
+
+func (r *harvestRepository) CreateNotifications(ctx context.Context, notifications []*common.Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).CreateInBatches(notifications, 100).Error
+}
@@ -0,0 +1,46 @@
+package routes
+
+import (
+	"agro_konnect/internal/auth/middleware"
+	"agro_konnect/internal/auth/model"
+	farmerRepo "agro_konnect/internal/farmer/repository"
+	"agro_konnect/internal/harvest/handler"
+	"agro_konnect/internal/harvest/repository"
+	"agro_konnect/internal/harvest/service"
+	orderservice "agro_konnect/internal/order/service"
+	productRepo "agro_konnect/internal/product/repository"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func SetupHarvestRoutes(router *gin.RouterGroup, db *gorm.DB, authMiddleware *middleware.AuthMiddleware, orderService orderservice.OrderService) {
+	// Reservations are placed as orders at harvest, through orderService
+	harvestRepo := repository.NewHarvestRepository(db)
+	harvestService := service.NewHarvestService(harvestRepo, farmerRepo.NewFarmerRepository(db), productRepo.NewProductRepository(db), orderService)
+	harvestHandler := handler.NewHarvestHandler(harvestService)
+
+	harvestRoutes := router.Group("/harvests")
+	{
+		// Public routes
+		harvestRoutes.GET("", harvestHandler.GetOpenHarvests)
+		harvestRoutes.GET("/:id", harvestHandler.GetHarvest)
+
+		authRequired := harvestRoutes.Use(authMiddleware.Authenticate())
+		{
+			// Farmers
+			authRequired.POST("", authMiddleware.RequireRole(model.RoleFarmer), harvestHandler.CreateHarvest)
+			authRequired.GET("/me", authMiddleware.RequireRole(model.RoleFarmer), harvestHandler.GetMyHarvests)
+			authRequired.PUT("/:id", authMiddleware.RequireRole(model.RoleFarmer), harvestHandler.UpdateHarvest)
+			authRequired.POST("/:id/complete", authMiddleware.RequireRole(model.RoleFarmer), harvestHandler.CompleteHarvest)
+			authRequired.POST("/:id/cancel", authMiddleware.RequireRole(model.RoleFarmer), harvestHandler.CancelHarvest)
+			authRequired.GET("/:id/reservations", authMiddleware.RequireRole(model.RoleFarmer), harvestHandler.GetHarvestReservations)
+
+			// Buyers
+			authRequired.POST("/:id/reservations", authMiddleware.RequireRole(model.RoleBuyer), harvestHandler.Reserve)
+			authRequired.GET("/reservations/me", authMiddleware.RequireRole(model.RoleBuyer), harvestHandler.GetMyReservations)
+			authRequired.POST("/reservations/:reservationId/deposit", authMiddleware.RequireRole(model.RoleBuyer), harvestHandler.PayDeposit)
+			authRequired.POST("/reservations/:reservationId/cancel", authMiddleware.RequireRole(model.RoleBuyer), harvestHandler.CancelReservation)
+		}
+	}
+}
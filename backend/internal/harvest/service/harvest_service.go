@@ -0,0 +1,706 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"agro_konnect/internal/common"
+	farmerRepo "agro_konnect/internal/farmer/repository"
+	dto "agro_konnect/internal/harvest/dto"
+	model "agro_konnect/internal/harvest/model"
+	"agro_konnect/internal/harvest/repository"
+	orderDto "agro_konnect/internal/order/dto"
+	orderModel "agro_konnect/internal/order/model"
+	orderService "agro_konnect/internal/order/service"
+	productRepo "agro_konnect/internal/product/repository"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrHarvestNotFound       = errors.New("harvest not found")
+	ErrReservationNotFound   = errors.New("reservation not found")
+	ErrFarmerNotFound        = errors.New("farmer not found")
+	ErrUnauthorizedAccess    = errors.New("unauthorized access to harvest")
+	ErrInvalidHarvestProduct = errors.New("harvests can only be listed for your own products and their active packaging options")
+	ErrVariantRequired       = errors.New("choose a packaging option for this product")
+	ErrInvalidHarvestDates   = errors.New("expected date must be in the future and reservations must close before it")
+	ErrYieldBelowReserved    = errors.New("expected yield cannot be less than what is already reserved")
+	ErrHarvestNotOpen        = errors.New("harvest is not taking reservations")
+	ErrHarvestNotCompletable = errors.New("harvest has been cancelled")
+	ErrBelowMinReservation   = errors.New("quantity is below the minimum reservation")
+	ErrInsufficientYield     = errors.New("not enough of the expected yield is left to reserve")
+	ErrReservationLocked     = errors.New("reservation can no longer be changed")
+	ErrNoDepositDue          = errors.New("no deposit is due on this reservation")
+)
+
+type HarvestService interface {
+	CreateHarvest(ctx context.Context, userID uuid.UUID, req *dto.CreateHarvestRequest) (*dto.HarvestResponse, error)
+	UpdateHarvest(ctx context.Context, harvestID, userID uuid.UUID, req *dto.UpdateHarvestRequest) (*dto.HarvestResponse, error)
+	GetHarvest(ctx context.Context, harvestID uuid.UUID) (*dto.HarvestResponse, error)
+	GetOpenHarvests(ctx context.Context, filters dto.HarvestFilterRequest) (*dto.HarvestListResponse, error)
+	GetMyHarvests(ctx context.Context, userID uuid.UUID, filters dto.HarvestFilterRequest) (*dto.HarvestListResponse, error)
+	GetHarvestReservations(ctx context.Context, harvestID, userID uuid.UUID) ([]*dto.ReservationResponse, error)
+	CompleteHarvest(ctx context.Context, harvestID, userID uuid.UUID, req *dto.CompleteHarvestRequest) (*dto.CompleteHarvestResponse, error)
+	CancelHarvest(ctx context.Context, harvestID, userID uuid.UUID) error
+
+	Reserve(ctx context.Context, harvestID, buyerID uuid.UUID, req *dto.ReserveRequest) (*dto.ReservationResponse, error)
+	GetMyReservations(ctx context.Context, buyerID uuid.UUID) ([]*dto.ReservationResponse, error)
+	PayDeposit(ctx context.Context, reservationID, buyerID uuid.UUID, req *dto.DepositPaymentRequest) (*dto.ReservationResponse, error)
+	CancelReservation(ctx context.Context, reservationID, buyerID uuid.UUID) (*dto.ReservationResponse, error)
+}
+
+type harvestService struct {
+	harvestRepo  repository.HarvestRepository
+	farmerRepo   farmerRepo.FarmerRepository
+	productRepo  productRepo.ProductRepository
+	orderService orderService.OrderService
+}
+
+func NewHarvestService(
+	harvestRepo repository.HarvestRepository,
+	farmerRepo farmerRepo.FarmerRepository,
+	productRepo productRepo.ProductRepository,
+	orderService orderService.OrderService,
+) HarvestService {
+	return &harvestService{
+		harvestRepo:  harvestRepo,
+		farmerRepo:   farmerRepo,
+		productRepo:  productRepo,
+		orderService: orderService,
+	}
+}
+
+// CreateHarvest lists an upcoming crop of one of the farmer's products for
+// reservation.
+func (s *harvestService) CreateHarvest(ctx context.Context, userID uuid.UUID, req *dto.CreateHarvestRequest) (*dto.HarvestResponse, error) {
+	farmer, err := s.farmerRepo.FindByUserID(ctx, userID)
+	if err != nil || farmer == nil {
+		return nil, ErrFarmerNotFound
+	}
+
+	product, err := s.productRepo.FindByID(ctx, req.ProductID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product: %w", err)
+	}
+	if product == nil || product.FarmerID != farmer.ID {
+		return nil, ErrInvalidHarvestProduct
+	}
+	// A packaged product is sold by the pack, so its harvest is listed in
+	// one of its packs
+	variants, err := s.productRepo.FindVariantsByProductIDs(ctx, []uuid.UUID{product.ID}, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get variants: %w", err)
+	}
+	variantName, unit := "", product.Unit
+	if req.VariantID == nil {
+		if len(variants) > 0 {
+			return nil, ErrVariantRequired
+		}
+	} else {
+		found := false
+		for _, variant := range variants {
+			if variant.ID == *req.VariantID {
+				variantName, unit, found = variant.Name, variant.Unit, true
+				break
+			}
+		}
+		if !found {
+			return nil, ErrInvalidHarvestProduct
+		}
+	}
+
+	now := time.Now()
+	deadline := req.ExpectedDate
+	if req.ReservationDeadline != nil {
+		deadline = *req.ReservationDeadline
+	}
+	if !validDates(req.ExpectedDate, deadline, now) {
+		return nil, ErrInvalidHarvestDates
+	}
+
+	harvest := &model.Harvest{
+		ID:                  uuid.New(),
+		FarmerID:            farmer.ID,
+		ProductID:           product.ID,
+		VariantID:           req.VariantID,
+		ExpectedDate:        req.ExpectedDate,
+		ExpectedYield:       req.ExpectedYield,
+		PricePerUnit:        req.PricePerUnit,
+		DepositPercent:      req.DepositPercent,
+		MinReservation:      req.MinReservation,
+		ReservationDeadline: deadline,
+		Notes:               req.Notes,
+		Status:              model.HarvestStatusOpen,
+		CreatedAt:           now,
+		UpdatedAt:           now,
+	}
+	if err := s.harvestRepo.Create(ctx, harvest); err != nil {
+		return nil, fmt.Errorf("failed to create harvest: %w", err)
+	}
+
+	return toHarvestResponse(&repository.HarvestRow{
+		Harvest:      *harvest,
+		ProductName:  product.Name,
+		VariantName:  variantName,
+		Category:     string(product.Category),
+		Unit:         unit,
+		FarmerUserID: userID,
+	}), nil
+}
+
+// UpdateHarvest changes an open harvest. The expected yield may go down, but
+// not below what buyers have already reserved.
+func (s *harvestService) UpdateHarvest(ctx context.Context, harvestID, userID uuid.UUID, req *dto.UpdateHarvestRequest) (*dto.HarvestResponse, error) {
+	harvest, err := s.findOwnHarvest(ctx, harvestID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if harvest.Status != model.HarvestStatusOpen {
+		return nil, ErrHarvestNotOpen
+	}
+
+	if req.ExpectedDate != nil {
+		harvest.ExpectedDate = *req.ExpectedDate
+	}
+	if req.ReservationDeadline != nil {
+		harvest.ReservationDeadline = *req.ReservationDeadline
+	}
+	if req.ExpectedDate != nil || req.ReservationDeadline != nil {
+		if !validDates(harvest.ExpectedDate, harvest.ReservationDeadline, time.Now()) {
+			return nil, ErrInvalidHarvestDates
+		}
+	}
+	if req.ExpectedYield != nil {
+		if *req.ExpectedYield < harvest.ReservedQuantity {
+			return nil, ErrYieldBelowReserved
+		}
+		harvest.ExpectedYield = *req.ExpectedYield
+	}
+	if req.PricePerUnit != nil {
+		harvest.PricePerUnit = *req.PricePerUnit
+	}
+	if req.DepositPercent != nil {
+		harvest.DepositPercent = *req.DepositPercent
+	}
+	if req.MinReservation != nil {
+		harvest.MinReservation = *req.MinReservation
+	}
+	if req.Notes != nil {
+		harvest.Notes = *req.Notes
+	}
+	harvest.UpdatedAt = time.Now()
+
+	if err := s.harvestRepo.Update(ctx, &harvest.Harvest); err != nil {
+		return nil, fmt.Errorf("failed to update harvest: %w", err)
+	}
+	return toHarvestResponse(harvest), nil
+}
+
+func (s *harvestService) GetHarvest(ctx context.Context, harvestID uuid.UUID) (*dto.HarvestResponse, error) {
+	harvest, err := s.harvestRepo.FindByID(ctx, harvestID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get harvest: %w", err)
+	}
+	if harvest == nil {
+		return nil, ErrHarvestNotFound
+	}
+	return toHarvestResponse(harvest), nil
+}
+
+// GetOpenHarvests lists the harvests buyers can still reserve from.
+func (s *harvestService) GetOpenHarvests(ctx context.Context, filters dto.HarvestFilterRequest) (*dto.HarvestListResponse, error) {
+	setPaginationDefaults(&filters)
+	harvests, total, err := s.harvestRepo.FindOpen(ctx, filters, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get harvests: %w", err)
+	}
+	return toHarvestListResponse(harvests, total, filters), nil
+}
+
+func (s *harvestService) GetMyHarvests(ctx context.Context, userID uuid.UUID, filters dto.HarvestFilterRequest) (*dto.HarvestListResponse, error) {
+	farmer, err := s.farmerRepo.FindByUserID(ctx, userID)
+	if err != nil || farmer == nil {
+		return nil, ErrFarmerNotFound
+	}
+
+	setPaginationDefaults(&filters)
+	harvests, total, err := s.harvestRepo.FindByFarmerID(ctx, farmer.ID, filters.Page, filters.PageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get harvests: %w", err)
+	}
+	return toHarvestListResponse(harvests, total, filters), nil
+}
+
+func (s *harvestService) GetHarvestReservations(ctx context.Context, harvestID, userID uuid.UUID) ([]*dto.ReservationResponse, error) {
+	if _, err := s.findOwnHarvest(ctx, harvestID, userID); err != nil {
+		return nil, err
+	}
+
+	reservations, err := s.harvestRepo.FindReservationsByHarvestID(ctx, harvestID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reservations: %w", err)
+	}
+	return toReservationResponses(reservations), nil
+}
+
+// CompleteHarvest records the actual yield and turns the reservations into
+// orders. If less was harvested than reserved, every reservation gets the
+// same share of what it asked for. Reservations whose deposit was never
+// paid are dropped first. Calling it again on a completed harvest retries
+// the reservations whose order could not be placed.
+func (s *harvestService) CompleteHarvest(ctx context.Context, harvestID, userID uuid.UUID, req *dto.CompleteHarvestRequest) (*dto.CompleteHarvestResponse, error) {
+	harvest, err := s.findOwnHarvest(ctx, harvestID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if harvest.Status == model.HarvestStatusCancelled {
+		return nil, ErrHarvestNotCompletable
+	}
+
+	var notifications []*common.Notification
+	if harvest.Status == model.HarvestStatusOpen {
+		now := time.Now()
+		actualYield := req.ActualYield
+		harvest.ActualYield = &actualYield
+		harvest.CompletedAt = &now
+
+		completed, err := s.harvestRepo.Complete(ctx, &harvest.Harvest, func(reservations []*model.HarvestReservation) []*model.HarvestReservation {
+			var changed []*model.HarvestReservation
+			var reserved float64
+			for _, reservation := range reservations {
+				if reservation.Status != model.ReservationStatusReserved {
+					continue
+				}
+				if reservation.DepositStatus == model.DepositStatusPending {
+					reservation.Status = model.ReservationStatusCancelled
+					changed = append(changed, reservation)
+					notifications = append(notifications, newNotification(reservation.BuyerID, "Reservation cancelled",
+						fmt.Sprintf("Your reservation of %s was cancelled because its deposit was not paid before harvest.", harvest.ProductName), harvest.ID))
+					continue
+				}
+				changed = append(changed, reservation)
+				reserved += reservation.Quantity
+			}
+
+			ratio := 1.0
+			if reserved > actualYield {
+				ratio = actualYield / reserved
+			}
+			for _, reservation := range changed {
+				if reservation.Status == model.ReservationStatusReserved {
+					reservation.Allocate(ratio)
+				}
+				if reservation.Status == model.ReservationStatusUnfilled {
+					notifications = append(notifications, newNotification(reservation.BuyerID, "Reservation unfilled",
+						fmt.Sprintf("The %s harvest came in short and none of it could be allocated to your reservation. Any deposit paid will be refunded.", harvest.ProductName), harvest.ID))
+				}
+			}
+			return changed
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to complete harvest: %w", err)
+		}
+		if !completed {
+			return nil, ErrHarvestNotOpen
+		}
+		harvest.Status = model.HarvestStatusCompleted
+	}
+
+	reservations, err := s.harvestRepo.FindReservationsByHarvestID(ctx, harvestID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reservations: %w", err)
+	}
+
+	response := &dto.CompleteHarvestResponse{FillRatio: 1}
+	var allocated float64
+	for _, reservation := range reservations {
+		if reservation.Status != model.ReservationStatusAllocated {
+			continue
+		}
+		// Claim the reservation first so that a repeated call cannot place
+		// a second order for it
+		claimed, err := s.harvestRepo.ClaimForConversion(ctx, reservation.ID)
+		if err != nil {
+			log.Printf("failed to claim harvest reservation %s: %v", reservation.ID, err)
+			response.Failed++
+			continue
+		}
+		if !claimed {
+			continue
+		}
+		orderID, orderNumber, err := s.convert(ctx, harvest, reservation)
+		if err != nil {
+			log.Printf("failed to place order for harvest reservation %s: %v", reservation.ID, err)
+			response.Failed++
+			continue
+		}
+		reservation.Status = model.ReservationStatusConverted
+		reservation.OrderID = &orderID
+		notifications = append(notifications, newNotification(reservation.BuyerID, "Harvest order placed",
+			fmt.Sprintf("Order %s has been placed for %g %s of %s from your reservation.",
+				orderNumber, reservation.AllocatedQuantity, harvest.Unit, harvest.ProductName), harvest.ID))
+	}
+	s.notify(ctx, notifications...)
+
+	var requested float64
+	for _, reservation := range reservations {
+		switch reservation.Status {
+		case model.ReservationStatusConverted:
+			response.Converted++
+		case model.ReservationStatusUnfilled:
+			response.Unfilled++
+		}
+		if reservation.Status != model.ReservationStatusCancelled {
+			requested += reservation.Quantity
+			allocated += reservation.AllocatedQuantity
+		}
+	}
+	if requested > 0 {
+		response.FillRatio = math.Round(allocated/requested*10000) / 10000
+	}
+	response.Harvest = toHarvestResponse(harvest)
+	response.Reservations = toReservationResponses(reservations)
+	return response, nil
+}
+
+// CancelHarvest withdraws an open harvest, for example after a crop
+// failure. Deposits paid are refunded in full.
+func (s *harvestService) CancelHarvest(ctx context.Context, harvestID, userID uuid.UUID) error {
+	harvest, err := s.findOwnHarvest(ctx, harvestID, userID)
+	if err != nil {
+		return err
+	}
+
+	reservations, err := s.harvestRepo.FindReservationsByHarvestID(ctx, harvestID)
+	if err != nil {
+		return fmt.Errorf("failed to get reservations: %w", err)
+	}
+
+	cancelled, err := s.harvestRepo.Cancel(ctx, harvestID)
+	if err != nil {
+		return fmt.Errorf("failed to cancel harvest: %w", err)
+	}
+	if !cancelled {
+		return ErrHarvestNotOpen
+	}
+
+	var notifications []*common.Notification
+	for _, reservation := range reservations {
+		if reservation.Status == model.ReservationStatusReserved {
+			notifications = append(notifications, newNotification(reservation.BuyerID, "Harvest cancelled",
+				fmt.Sprintf("The farmer cancelled the %s harvest you reserved from. Any deposit paid will be refunded.", harvest.ProductName), harvest.ID))
+		}
+	}
+	s.notify(ctx, notifications...)
+	return nil
+}
+
+// Reserve claims part of a harvest's expected yield at its listed price.
+// Any deposit is due straight away.
+func (s *harvestService) Reserve(ctx context.Context, harvestID, buyerID uuid.UUID, req *dto.ReserveRequest) (*dto.ReservationResponse, error) {
+	harvest, err := s.harvestRepo.FindByID(ctx, harvestID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get harvest: %w", err)
+	}
+	if harvest == nil {
+		return nil, ErrHarvestNotFound
+	}
+	now := time.Now()
+	if !harvest.IsOpen(now) {
+		return nil, ErrHarvestNotOpen
+	}
+	if harvest.FarmerUserID == buyerID {
+		return nil, ErrUnauthorizedAccess
+	}
+	if req.Quantity < harvest.MinReservation {
+		return nil, fmt.Errorf("%w of %g %s", ErrBelowMinReservation, harvest.MinReservation, harvest.Unit)
+	}
+
+	reservation := &model.HarvestReservation{
+		ID:                uuid.New(),
+		HarvestID:         harvestID,
+		BuyerID:           buyerID,
+		Quantity:          req.Quantity,
+		UnitPrice:         harvest.PricePerUnit,
+		DepositAmount:     math.Round(req.Quantity*harvest.PricePerUnit*harvest.DepositPercent) / 100,
+		DepositStatus:     model.DepositStatusNone,
+		ShippingAddress:   req.ShippingAddress,
+		ShippingCity:      req.ShippingCity,
+		ShippingState:     req.ShippingState,
+		ShippingZipCode:   req.ShippingZipCode,
+		ShippingLatitude:  req.ShippingLatitude,
+		ShippingLongitude: req.ShippingLongitude,
+		PaymentMethod:     req.PaymentMethod,
+		Status:            model.ReservationStatusReserved,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+	if reservation.DepositAmount > 0 {
+		reservation.DepositStatus = model.DepositStatusPending
+	}
+
+	reserved, err := s.harvestRepo.Reserve(ctx, reservation, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reserve harvest: %w", err)
+	}
+	if !reserved {
+		return nil, ErrInsufficientYield
+	}
+
+	s.notify(ctx, newNotification(harvest.FarmerUserID, "New harvest reservation",
+		fmt.Sprintf("A buyer reserved %g %s of your upcoming %s harvest.", reservation.Quantity, harvest.Unit, harvest.ProductName), harvest.ID))
+	return toReservationResponse(reservation), nil
+}
+
+func (s *harvestService) GetMyReservations(ctx context.Context, buyerID uuid.UUID) ([]*dto.ReservationResponse, error) {
+	reservations, err := s.harvestRepo.FindReservationsByBuyerID(ctx, buyerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reservations: %w", err)
+	}
+	return toReservationResponses(reservations), nil
+}
+
+// PayDeposit takes the deposit asked for on a reservation.
+func (s *harvestService) PayDeposit(ctx context.Context, reservationID, buyerID uuid.UUID, req *dto.DepositPaymentRequest) (*dto.ReservationResponse, error) {
+	reservation, err := s.findOwnReservation(ctx, reservationID, buyerID)
+	if err != nil {
+		return nil, err
+	}
+	if reservation.DepositStatus != model.DepositStatusPending {
+		return nil, ErrNoDepositDue
+	}
+	if reservation.Status != model.ReservationStatusReserved {
+		return nil, ErrReservationLocked
+	}
+
+	// Integrate with actual payment gateway, as for order payments.
+	// For now, simulate successful payment
+	paymentID := fmt.Sprintf("dep_%s", uuid.New().String())
+
+	paid, err := s.harvestRepo.MarkDepositPaid(ctx, reservationID, paymentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record deposit: %w", err)
+	}
+	if !paid {
+		return nil, ErrReservationLocked
+	}
+
+	reservation.DepositStatus = model.DepositStatusPaid
+	reservation.DepositPaymentID = paymentID
+	return toReservationResponse(reservation), nil
+}
+
+// CancelReservation lets a buyer back out while the harvest is still taking
+// reservations. A paid deposit is refunded in full.
+func (s *harvestService) CancelReservation(ctx context.Context, reservationID, buyerID uuid.UUID) (*dto.ReservationResponse, error) {
+	reservation, err := s.findOwnReservation(ctx, reservationID, buyerID)
+	if err != nil {
+		return nil, err
+	}
+
+	harvest, err := s.harvestRepo.FindByID(ctx, reservation.HarvestID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get harvest: %w", err)
+	}
+	if harvest == nil || !harvest.IsOpen(time.Now()) || reservation.Status != model.ReservationStatusReserved {
+		return nil, ErrReservationLocked
+	}
+
+	if reservation.DepositStatus == model.DepositStatusPaid {
+		reservation.DepositStatus = model.DepositStatusRefunded
+		reservation.DepositRefund = reservation.DepositAmount
+	}
+
+	cancelled, err := s.harvestRepo.CancelReservation(ctx, reservation)
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel reservation: %w", err)
+	}
+	if !cancelled {
+		return nil, ErrReservationLocked
+	}
+	reservation.Status = model.ReservationStatusCancelled
+
+	s.notify(ctx, newNotification(harvest.FarmerUserID, "Harvest reservation cancelled",
+		fmt.Sprintf("A buyer cancelled their reservation of %g %s of your %s harvest.", reservation.Quantity, harvest.Unit, harvest.ProductName), harvest.ID))
+	return toReservationResponse(reservation), nil
+}
+
+// Helper methods
+
+// convert places the order for a reservation's allocated quantity at the
+// reserved price, with the deposit counted towards it.
+func (s *harvestService) convert(ctx context.Context, harvest *repository.HarvestRow, reservation *model.HarvestReservation) (uuid.UUID, string, error) {
+	order, err := s.orderService.CreateNegotiatedOrder(ctx, reservation.BuyerID, &orderDto.CreateOrderRequest{
+		ShippingAddress:   reservation.ShippingAddress,
+		ShippingCity:      reservation.ShippingCity,
+		ShippingState:     reservation.ShippingState,
+		ShippingZipCode:   reservation.ShippingZipCode,
+		ShippingLatitude:  reservation.ShippingLatitude,
+		ShippingLongitude: reservation.ShippingLongitude,
+		PaymentMethod:     orderModel.PaymentMethod(reservation.PaymentMethod),
+		Items: []orderDto.OrderItemRequest{{
+			ProductID: harvest.ProductID,
+			VariantID: harvest.VariantID,
+			Quantity:  reservation.AllocatedQuantity,
+		}},
+	}, orderService.NegotiatedTerms{
+		ReservationID: &reservation.ID,
+		UnitPrice:     reservation.UnitPrice,
+		DepositPaid:   reservation.DepositApplied(),
+	})
+	if err != nil {
+		if releaseErr := s.harvestRepo.ReleaseConversion(ctx, reservation.ID); releaseErr != nil {
+			log.Printf("failed to release harvest reservation %s: %v", reservation.ID, releaseErr)
+		}
+		return uuid.Nil, "", err
+	}
+
+	if err := s.harvestRepo.MarkConverted(ctx, reservation.ID, order.ID); err != nil {
+		// The order stands; only the reservation bookkeeping is behind
+		log.Printf("failed to mark harvest reservation %s as ordered in %s: %v", reservation.ID, order.OrderNumber, err)
+	}
+	return order.ID, order.OrderNumber, nil
+}
+
+func (s *harvestService) findOwnHarvest(ctx context.Context, harvestID, userID uuid.UUID) (*repository.HarvestRow, error) {
+	harvest, err := s.harvestRepo.FindByID(ctx, harvestID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get harvest: %w", err)
+	}
+	if harvest == nil {
+		return nil, ErrHarvestNotFound
+	}
+	if harvest.FarmerUserID != userID {
+		return nil, ErrUnauthorizedAccess
+	}
+	return harvest, nil
+}
+
+func (s *harvestService) findOwnReservation(ctx context.Context, reservationID, buyerID uuid.UUID) (*model.HarvestReservation, error) {
+	reservation, err := s.harvestRepo.FindReservationByID(ctx, reservationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reservation: %w", err)
+	}
+	if reservation == nil {
+		return nil, ErrReservationNotFound
+	}
+	if reservation.BuyerID != buyerID {
+		return nil, ErrUnauthorizedAccess
+	}
+	return reservation, nil
+}
+
+// notify stores notifications, logging rather than failing on errors.
+func (s *harvestService) notify(ctx context.Context, notifications ...*common.Notification) {
+	if err := s.harvestRepo.CreateNotifications(ctx, notifications); err != nil {
+		log.Printf("failed to create harvest notifications: %v", err)
+	}
+}
+
+func validDates(expected, deadline, now time.Time) bool {
+	return expected.After(now) && deadline.After(now) && !deadline.After(expected)
+}
+
+func newNotification(userID uuid.UUID, title, message string, harvestID uuid.UUID) *common.Notification {
+	return &common.Notification{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Title:     title,
+		Message:   message,
+		Type:      "harvest",
+		ActionURL: "/harvests/" + harvestID.String(),
+		CreatedAt: time.Now(),
+	}
+}
+
+func setPaginationDefaults(filters *dto.HarvestFilterRequest) {
+	if filters.Page < 1 {
+		filters.Page = 1
+	}
+	if filters.PageSize < 1 || filters.PageSize > 100 {
+		filters.PageSize = 20
+	}
+}
+
+func toHarvestListResponse(harvests []*repository.HarvestRow, total int64, filters dto.HarvestFilterRequest) *dto.HarvestListResponse {
+	responses := make([]*dto.HarvestResponse, len(harvests))
+	for i, harvest := range harvests {
+		responses[i] = toHarvestResponse(harvest)
+	}
+
+	pages := int(math.Ceil(float64(total) / float64(filters.PageSize)))
+	return &dto.HarvestListResponse{
+		Harvests: responses,
+		Total:    total,
+		Page:     filters.Page,
+		Pages:    pages,
+		HasMore:  filters.Page < pages,
+	}
+}
+
+func toHarvestResponse(harvest *repository.HarvestRow) *dto.HarvestResponse {
+	return &dto.HarvestResponse{
+		ID:                  harvest.ID,
+		FarmerID:            harvest.FarmerID,
+		ProductID:           harvest.ProductID,
+		ProductName:         harvest.ProductName,
+		VariantID:           harvest.VariantID,
+		VariantName:         harvest.VariantName,
+		Category:            harvest.Category,
+		Unit:                harvest.Unit,
+		ExpectedDate:        harvest.ExpectedDate,
+		ExpectedYield:       harvest.ExpectedYield,
+		ReservedQuantity:    harvest.ReservedQuantity,
+		AvailableToReserve:  math.Max(harvest.ExpectedYield-harvest.ReservedQuantity, 0),
+		PricePerUnit:        harvest.PricePerUnit,
+		DepositPercent:      harvest.DepositPercent,
+		MinReservation:      harvest.MinReservation,
+		ReservationDeadline: harvest.ReservationDeadline,
+		Notes:               harvest.Notes,
+		Status:              harvest.Status,
+		ActualYield:         harvest.ActualYield,
+		CompletedAt:         harvest.CompletedAt,
+		CreatedAt:           harvest.CreatedAt,
+	}
+}
+
+func toReservationResponses(reservations []*model.HarvestReservation) []*dto.ReservationResponse {
+	responses := make([]*dto.ReservationResponse, len(reservations))
+	for i, reservation := range reservations {
+		responses[i] = toReservationResponse(reservation)
+	}
+	return responses
+}
+
+func toReservationResponse(reservation *model.HarvestReservation) *dto.ReservationResponse {
+	quantity := reservation.Quantity
+	switch reservation.Status {
+	case model.ReservationStatusAllocated, model.ReservationStatusConverting, model.ReservationStatusConverted:
+		quantity = reservation.AllocatedQuantity
+	}
+
+	return &dto.ReservationResponse{
+		ID:                reservation.ID,
+		HarvestID:         reservation.HarvestID,
+		BuyerID:           reservation.BuyerID,
+		Quantity:          reservation.Quantity,
+		AllocatedQuantity: reservation.AllocatedQuantity,
+		UnitPrice:         reservation.UnitPrice,
+		TotalPrice:        math.Round(quantity*reservation.UnitPrice*100) / 100,
+		DepositAmount:     reservation.DepositAmount,
+		DepositStatus:     reservation.DepositStatus,
+		DepositRefund:     reservation.DepositRefund,
+		ShippingAddress:   reservation.ShippingAddress,
+		ShippingCity:      reservation.ShippingCity,
+		ShippingState:     reservation.ShippingState,
+		PaymentMethod:     reservation.PaymentMethod,
+		Status:            reservation.Status,
+		OrderID:           reservation.OrderID,
+		CreatedAt:         reservation.CreatedAt,
+	}
+}
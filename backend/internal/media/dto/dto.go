@@ -0,0 +1,9 @@
+package dto
+
+import "time"
+
+// Response DTOs
+type SignedURLResponse struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
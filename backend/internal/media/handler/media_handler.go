@@ -0,0 +1,187 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"agro_konnect/internal/auth/middleware"
+	"agro_konnect/internal/media/service"
+	"agro_konnect/internal/media/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type MediaHandler struct {
+	mediaService service.MediaService
+}
+
+func NewMediaHandler(mediaService service.MediaService) *MediaHandler {
+	return &MediaHandler{mediaService: mediaService}
+}
+
+// GetFile redirects to a public upload
+// @Summary Get file
+// @Description Redirect to a short-lived signed URL for a public upload such as a product image. This is the URL stored on products, vendors and reviews. Images can be fetched resized; until a rendition is ready the next larger one is served
+// @Tags media
+// @Param id path string true "File ID"
+// @Param size query string false "Image size" Enums(thumb, medium, large, original)
+// @Success 302 "Redirect to the file"
+// @Failure 400 {object} utils.ErrorResponse "Invalid file ID or size"
+// @Failure 404 {object} utils.ErrorResponse "File not found"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /media/{id} [get]
+func (h *MediaHandler) GetFile(c *gin.Context) {
+	attachmentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid file ID")
+		return
+	}
+
+	signedURL, err := h.mediaService.GetPublicURL(c.Request.Context(), attachmentID, c.Query("size"))
+	if err != nil {
+		respondWithMediaError(c, err, "Failed to retrieve file")
+		return
+	}
+
+	c.Redirect(http.StatusFound, signedURL)
+}
+
+// GetFileURL signs a URL for an upload
+// @Summary Get signed file URL
+// @Description Get a short-lived signed URL for an upload the user may see: a public one, their own, or any for admins
+// @Tags media
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "File ID"
+// @Param size query string false "Image size" Enums(thumb, medium, large, original)
+// @Success 200 {object} utils.SuccessResponse{data=dto.SignedURLResponse} "URL created successfully"
+// @Failure 400 {object} utils.ErrorResponse "Invalid file ID or size"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 404 {object} utils.ErrorResponse "File not found"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /media/{id}/url [get]
+func (h *MediaHandler) GetFileURL(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+	userRole := fmt.Sprintf("%v", c.MustGet(middleware.UserRoleContextKey))
+
+	attachmentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid file ID")
+		return
+	}
+
+	signed, err := h.mediaService.GetSignedURL(c.Request.Context(), attachmentID, userID, userRole, c.Query("size"))
+	if err != nil {
+		respondWithMediaError(c, err, "Failed to create URL")
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, "URL created successfully", signed)
+}
+
+// ServeFile serves a file behind a signed URL
+// @Summary Serve signed file
+// @Description Serve a stored file for a signed URL made by the local storage backend
+// @Tags media
+// @Produce octet-stream
+// @Param key path string true "Storage key"
+// @Param expires query int true "Expiry as a Unix timestamp"
+// @Param signature query string true "URL signature"
+// @Success 200 {file} file "File content"
+// @Failure 403 {object} utils.ErrorResponse "Link invalid or expired"
+// @Failure 404 {object} utils.ErrorResponse "File not found"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /media/files/{key} [get]
+func (h *MediaHandler) ServeFile(c *gin.Context) {
+	key := strings.TrimPrefix(c.Param("key"), "/")
+
+	object, err := h.mediaService.OpenSigned(c.Request.Context(), key, c.Request.URL.Query())
+	if err != nil {
+		respondWithMediaError(c, err, "Failed to serve file")
+		return
+	}
+	defer object.Body.Close()
+
+	// Content never changes under a key, so it can be cached until the link expires
+	c.DataFromReader(http.StatusOK, object.Size, object.ContentType, object.Body, map[string]string{
+		"Cache-Control":          "private, max-age=3600",
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+// DeleteFile deletes an upload
+// @Summary Delete file
+// @Description Delete one of the user's uploads, or any upload for admins
+// @Tags media
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "File ID"
+// @Success 200 {object} utils.SuccessResponse "File deleted successfully"
+// @Failure 400 {object} utils.ErrorResponse "Invalid file ID"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 403 {object} utils.ErrorResponse "Forbidden"
+// @Failure 404 {object} utils.ErrorResponse "File not found"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /media/{id} [delete]
+func (h *MediaHandler) DeleteFile(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+	userRole := fmt.Sprintf("%v", c.MustGet(middleware.UserRoleContextKey))
+
+	attachmentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid file ID")
+		return
+	}
+
+	if err := h.mediaService.Delete(c.Request.Context(), attachmentID, userID, userRole); err != nil {
+		respondWithMediaError(c, err, "Failed to delete file")
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, "File deleted successfully", nil)
+}
+
+func respondWithMediaError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrInvalidSize):
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrAttachmentNotFound):
+		utils.RespondWithError(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrUnauthorizedAccess), errors.Is(err, service.ErrInvalidSignature):
+		utils.RespondWithError(c, http.StatusForbidden, err.Error())
+	default:
+		utils.RespondWithError(c, http.StatusInternalServerError, fallback)
+	}
+}
+
+// GetUserIDFromContext extracts user ID from Gin context
+func GetUserIDFromContext(c *gin.Context) (uuid.UUID, error) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		return uuid.Nil, errors.New("user ID not found in context")
+	}
+
+	switch v := userID.(type) {
+	case uuid.UUID:
+		return v, nil
+	case string:
+		parsedUUID, err := uuid.Parse(v)
+		if err != nil {
+			return uuid.Nil, fmt.Errorf("invalid user ID format: %w", err)
+		}
+		return parsedUUID, nil
+	default:
+		return uuid.Nil, errors.New("invalid user ID type in context")
+	}
+}
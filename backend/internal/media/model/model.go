@@ -0,0 +1,43 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type RenditionSize string
+
+const (
+	RenditionThumb  RenditionSize = "thumb"
+	RenditionMedium RenditionSize = "medium"
+	RenditionLarge  RenditionSize = "large"
+)
+
+// RenditionSides is the longest side, in pixels, of each rendition.
+var RenditionSides = map[RenditionSize]int{
+	RenditionThumb:  200,
+	RenditionMedium: 800,
+	RenditionLarge:  1600,
+}
+
+// RenditionSizes lists the renditions from smallest to largest.
+var RenditionSizes = []RenditionSize{RenditionThumb, RenditionMedium, RenditionLarge}
+
+// Rendition is a resized copy of an uploaded image. When the upload is
+// already smaller than the rendition it shares the upload's stored file.
+type Rendition struct {
+	ID           uuid.UUID     `gorm:"type:uuid;primary_key" json:"id"`
+	AttachmentID uuid.UUID     `gorm:"type:uuid;not null;uniqueIndex:idx_rendition_size" json:"attachment_id"`
+	Size         RenditionSize `gorm:"type:varchar(10);not null;uniqueIndex:idx_rendition_size" json:"size"`
+	StorageKey   string        `gorm:"not null;index" json:"-"`
+	ContentType  string        `gorm:"not null" json:"content_type"`
+	Width        int           `json:"width"`
+	Height       int           `json:"height"`
+	FileSize     int64         `json:"file_size"`
+	CreatedAt    time.Time     `json:"created_at"`
+}
+
+func (Rendition) TableName() string {
+	return "media_renditions"
+}
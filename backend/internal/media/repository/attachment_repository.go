@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"agro_konnect/internal/common"
+	"agro_konnect/internal/media/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type AttachmentRepository interface {
+	Create(ctx context.Context, attachment *common.FileAttachment) error
+	FindByID(ctx context.Context, id uuid.UUID) (*common.FileAttachment, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	CountByStorageKey(ctx context.Context, key string) (int64, error)
+	WithStorageKeyLock(ctx context.Context, key string, fn func(attachments AttachmentRepository, renditions RenditionRepository) error) error
+	FindWithoutRenditions(ctx context.Context, entityTypes, fileTypes []string, limit int) ([]uuid.UUID, error)
+	FindCreatedBefore(ctx context.Context, entityTypes []string, before time.Time) ([]*common.FileAttachment, error)
+}
+
+type attachmentRepository struct {
+	db *gorm.DB
+}
+
+func NewAttachmentRepository(db *gorm.DB) AttachmentRepository {
+	return &attachmentRepository{db: db}
+}
+
+func (r *attachmentRepository) Create(ctx context.Context, attachment *common.FileAttachment) error {
+	return r.db.WithContext(ctx).Create(attachment).Error
+}
+
+func (r *attachmentRepository) FindByID(ctx context.Context, id uuid.UUID) (*common.FileAttachment, error) {
+	var attachment common.FileAttachment
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&attachment).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &attachment, err
+}
+
+func (r *attachmentRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Where("id = ?", id).Delete(&common.FileAttachment{}).Error
+}
+
+// CountByStorageKey counts the uploads and renditions sharing a stored file.
+func (r *attachmentRepository) CountByStorageKey(ctx context.Context, key string) (int64, error) {
+	var uploads, renditions int64
+	if err := r.db.WithContext(ctx).Model(&common.FileAttachment{}).Where("storage_key = ?", key).Count(&uploads).Error; err != nil {
+		return 0, err
+	}
+	err := r.db.WithContext(ctx).Model(&model.Rendition{}).Where("storage_key = ?", key).Count(&renditions).Error
+	return uploads + renditions, err
+}
+
+// WithStorageKeyLock runs fn in a transaction holding a lock on a stored
+// file's key, with repositories bound to that transaction. Identical files
+// share a key, so storing a file and recording its use happen under the lock,
+// as do counting its uses and deleting it; a sweep then never deletes a file
+// an upload has just stored again.
+func (r *attachmentRepository) WithStorageKeyLock(ctx context.Context, key string, fn func(attachments AttachmentRepository, renditions RenditionRepository) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "storage:"+key).Error; err != nil {
+			return err
+		}
+		return fn(NewAttachmentRepository(tx), NewRenditionRepository(tx))
+	})
+}
+
+// FindWithoutRenditions returns the oldest uploads of the given kinds that
+// have no renditions yet.
+func (r *attachmentRepository) FindWithoutRenditions(ctx context.Context, entityTypes, fileTypes []string, limit int) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.WithContext(ctx).Model(&common.FileAttachment{}).
+		Where("entity_type IN ? AND file_type IN ?", entityTypes, fileTypes).
+		Where("NOT EXISTS (SELECT 1 FROM media_renditions mr WHERE mr.attachment_id = file_attachments.id)").
+		Order("created_at").
+		Limit(limit).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+func (r *attachmentRepository) FindCreatedBefore(ctx context.Context, entityTypes []string, before time.Time) ([]*common.FileAttachment, error) {
+	var attachments []*common.FileAttachment
+	err := r.db.WithContext(ctx).
+		Where("entity_type IN ? AND created_at < ?", entityTypes, before).
+		Order("created_at").
+		Find(&attachments).Error
+	return attachments, err
+}
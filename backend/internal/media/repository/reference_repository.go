@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ReferenceRepository reads the places other domains keep links to
+// uploads, so that uploads nothing links to can be found.
+type ReferenceRepository interface {
+	FindImageURLs(ctx context.Context) ([]string, error)
+	FindDocumentAttachmentIDs(ctx context.Context) ([]uuid.UUID, error)
+}
+
+type referenceRepository struct {
+	db *gorm.DB
+}
+
+func NewReferenceRepository(db *gorm.DB) ReferenceRepository {
+	return &referenceRepository{db: db}
+}
+
+// FindImageURLs returns every image URL held by products, reviews, vendor
+// products and vendor logos.
+func (r *referenceRepository) FindImageURLs(ctx context.Context) ([]string, error) {
+	var urls []string
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT json_array_elements_text(images) AS url FROM products WHERE json_typeof(images) = 'array'
+		UNION ALL
+		SELECT json_array_elements_text(images) FROM product_reviews WHERE json_typeof(images) = 'array'
+		UNION ALL
+		SELECT unnest(images) FROM vendor_products
+		UNION ALL
+		SELECT logo_url FROM vendors WHERE logo_url <> ''`).
+		Scan(&urls).Error
+	return urls, err
+}
+
+func (r *referenceRepository) FindDocumentAttachmentIDs(ctx context.Context) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.WithContext(ctx).
+		Table("farmer_documents").
+		Where("attachment_id IS NOT NULL").
+		Pluck("attachment_id", &ids).Error
+	return ids, err
+}
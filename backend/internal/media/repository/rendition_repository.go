@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+
+	"agro_konnect/internal/media/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RenditionRepository interface {
+	Create(ctx context.Context, rendition *model.Rendition) error
+	FindByAttachmentID(ctx context.Context, attachmentID uuid.UUID) ([]*model.Rendition, error)
+	DeleteByAttachmentID(ctx context.Context, attachmentID uuid.UUID) error
+	FindOrphaned(ctx context.Context) ([]*model.Rendition, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type renditionRepository struct {
+	db *gorm.DB
+}
+
+func NewRenditionRepository(db *gorm.DB) RenditionRepository {
+	return &renditionRepository{db: db}
+}
+
+// Create stores a rendition unless the attachment already has one of that
+// size, so regenerating renditions is harmless.
+func (r *renditionRepository) Create(ctx context.Context, rendition *model.Rendition) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "attachment_id"}, {Name: "size"}},
+			DoNothing: true,
+		}).
+		Create(rendition).Error
+}
+
+func (r *renditionRepository) FindByAttachmentID(ctx context.Context, attachmentID uuid.UUID) ([]*model.Rendition, error) {
+	var renditions []*model.Rendition
+	err := r.db.WithContext(ctx).Where("attachment_id = ?", attachmentID).Find(&renditions).Error
+	return renditions, err
+}
+
+func (r *renditionRepository) DeleteByAttachmentID(ctx context.Context, attachmentID uuid.UUID) error {
+	return r.db.WithContext(ctx).Where("attachment_id = ?", attachmentID).Delete(&model.Rendition{}).Error
+}
+
+// FindOrphaned returns renditions whose upload is gone, which happens when
+// an upload is deleted while its renditions are being made.
+func (r *renditionRepository) FindOrphaned(ctx context.Context) ([]*model.Rendition, error) {
+	var renditions []*model.Rendition
+	err := r.db.WithContext(ctx).
+		Where("NOT EXISTS (SELECT 1 FROM file_attachments fa WHERE fa.id = media_renditions.attachment_id)").
+		Find(&renditions).Error
+	return renditions, err
+}
+
+func (r *renditionRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Where("id = ?", id).Delete(&model.Rendition{}).Error
+}
@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"agro_konnect/internal/auth/middleware"
+	"agro_konnect/internal/media/handler"
+	"agro_konnect/internal/media/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SetupMediaRoutes(router *gin.RouterGroup, mediaService service.MediaService, authMiddleware *middleware.AuthMiddleware) {
+	mediaHandler := handler.NewMediaHandler(mediaService)
+
+	mediaRoutes := router.Group("/media")
+	{
+		// Public routes; private files need a signed URL
+		mediaRoutes.GET("/:id", mediaHandler.GetFile)
+		mediaRoutes.GET("/files/*key", mediaHandler.ServeFile)
+
+		authRequired := mediaRoutes.Use(authMiddleware.Authenticate())
+		{
+			authRequired.GET("/:id/url", mediaHandler.GetFileURL)
+			authRequired.DELETE("/:id", mediaHandler.DeleteFile)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"agro_konnect/internal/media/model"
+	"agro_konnect/internal/media/repository"
+	"agro_konnect/pkg/imaging"
+	"agro_konnect/pkg/storage"
+
+	"github.com/google/uuid"
+)
+
+// RenditionQueue hands uploaded images over to be resized in the background.
+type RenditionQueue interface {
+	Enqueue(attachmentID uuid.UUID)
+}
+
+const (
+	imageQueueSize = 256
+	backlogLimit   = 1000
+)
+
+// ImageWorkerPool makes the renditions of uploaded images on a fixed number
+// of goroutines, so uploads return as soon as the original is stored.
+type ImageWorkerPool struct {
+	attachmentRepo repository.AttachmentRepository
+	renditionRepo  repository.RenditionRepository
+	storage        storage.Storage
+	workers        int
+	queue          chan uuid.UUID
+}
+
+func NewImageWorkerPool(attachmentRepo repository.AttachmentRepository, renditionRepo repository.RenditionRepository, store storage.Storage, workers int) *ImageWorkerPool {
+	return &ImageWorkerPool{
+		attachmentRepo: attachmentRepo,
+		renditionRepo:  renditionRepo,
+		storage:        store,
+		workers:        max(1, workers),
+		queue:          make(chan uuid.UUID, imageQueueSize),
+	}
+}
+
+// Start launches the workers and queues images left without renditions,
+// e.g. because the server stopped before they were made.
+func (p *ImageWorkerPool) Start() {
+	for i := 0; i < p.workers; i++ {
+		go p.work()
+	}
+	go p.queueBacklog()
+}
+
+// Enqueue never blocks an upload. When the queue is full the image is left
+// for the backlog scan at the next start, and its original is served for
+// every size until then.
+func (p *ImageWorkerPool) Enqueue(attachmentID uuid.UUID) {
+	select {
+	case p.queue <- attachmentID:
+	default:
+		log.Printf("image queue is full; renditions of %s are deferred", attachmentID)
+	}
+}
+
+func (p *ImageWorkerPool) work() {
+	for attachmentID := range p.queue {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		if err := p.makeRenditions(ctx, attachmentID); err != nil {
+			log.Printf("failed to make renditions of %s: %v", attachmentID, err)
+		}
+		cancel()
+	}
+}
+
+func (p *ImageWorkerPool) queueBacklog() {
+	ids, err := p.attachmentRepo.FindWithoutRenditions(context.Background(), renditionEntityTypes(), resizableTypes, backlogLimit)
+	if err != nil {
+		log.Printf("failed to find images without renditions: %v", err)
+		return
+	}
+	if len(ids) > 0 {
+		log.Printf("queueing renditions for %d images", len(ids))
+	}
+	for _, id := range ids {
+		p.queue <- id
+	}
+}
+
+// makeRenditions resizes an upload into each missing rendition, largest
+// first so every step shrinks the previous one rather than the original.
+func (p *ImageWorkerPool) makeRenditions(ctx context.Context, attachmentID uuid.UUID) error {
+	attachment, err := p.attachmentRepo.FindByID(ctx, attachmentID)
+	if err != nil {
+		return fmt.Errorf("failed to get upload: %w", err)
+	}
+	if attachment == nil {
+		return nil // deleted while queued
+	}
+
+	existing, err := p.renditionRepo.FindByAttachmentID(ctx, attachmentID)
+	if err != nil {
+		return fmt.Errorf("failed to get renditions: %w", err)
+	}
+	done := make(map[model.RenditionSize]bool, len(existing))
+	for _, rendition := range existing {
+		done[rendition.Size] = true
+	}
+	if len(done) == len(model.RenditionSizes) {
+		return nil
+	}
+
+	object, err := p.storage.Get(ctx, attachment.StorageKey)
+	if err != nil {
+		return fmt.Errorf("failed to open upload: %w", err)
+	}
+	data, err := io.ReadAll(object.Body)
+	object.Body.Close()
+	if err != nil {
+		return fmt.Errorf("failed to read upload: %w", err)
+	}
+
+	original, _, err := imaging.Decode(data)
+	if err != nil {
+		return err
+	}
+
+	img := original
+	for i := len(model.RenditionSizes) - 1; i >= 0; i-- {
+		size := model.RenditionSizes[i]
+		img = imaging.Fit(img, model.RenditionSides[size])
+		if done[size] {
+			continue
+		}
+
+		rendition := &model.Rendition{
+			ID:           uuid.New(),
+			AttachmentID: attachmentID,
+			Size:         size,
+			Width:        img.Bounds().Dx(),
+			Height:       img.Bounds().Dy(),
+			CreatedAt:    time.Now(),
+		}
+
+		var encoded []byte
+		if img == original {
+			// Already small enough; share the upload's file
+			rendition.StorageKey = attachment.StorageKey
+			rendition.ContentType = attachment.FileType
+			rendition.FileSize = attachment.FileSize
+		} else {
+			// JPEG is much smaller unless there is transparency to keep
+			format := "jpeg"
+			if !img.Opaque() {
+				format = "png"
+			}
+			out, contentType, err := imaging.Encode(img, format)
+			if err != nil {
+				return fmt.Errorf("failed to encode %s rendition: %w", size, err)
+			}
+			encoded = out
+
+			rendition.StorageKey = storage.ContentKey(encoded, imageTypes[contentType])
+			rendition.ContentType = contentType
+			rendition.FileSize = int64(len(encoded))
+		}
+
+		// Stored and recorded under the key's lock, like uploads
+		err := p.attachmentRepo.WithStorageKeyLock(ctx, rendition.StorageKey, func(_ repository.AttachmentRepository, renditions repository.RenditionRepository) error {
+			if encoded != nil {
+				if err := p.storage.Put(ctx, rendition.StorageKey, encoded, rendition.ContentType); err != nil {
+					return fmt.Errorf("failed to store %s rendition: %w", size, err)
+				}
+			}
+			if err := renditions.Create(ctx, rendition); err != nil {
+				return fmt.Errorf("failed to record %s rendition: %w", size, err)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
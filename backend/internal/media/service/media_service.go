@@ -0,0 +1,429 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"agro_konnect/internal/common"
+	dto "agro_konnect/internal/media/dto"
+	"agro_konnect/internal/media/model"
+	"agro_konnect/internal/media/repository"
+	"agro_konnect/pkg/imaging"
+	"agro_konnect/pkg/storage"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrAttachmentNotFound  = errors.New("file not found")
+	ErrUnauthorizedAccess  = errors.New("unauthorized access to file")
+	ErrFileTooLarge        = errors.New("file is too large")
+	ErrUnsupportedFileType = errors.New("file type is not allowed")
+	ErrInvalidSignature    = errors.New("link is invalid or has expired")
+	ErrInvalidSize         = errors.New("size must be one of thumb, medium, large or original")
+	ErrInvalidImage        = imaging.ErrInvalidImage
+	ErrImageTooLarge       = imaging.ErrTooManyPixels
+)
+
+// AttachmentURLPrefix is where uploads are served from. The URL stays the
+// same for the life of the upload and redirects to a short-lived signed one.
+const AttachmentURLPrefix = "/api/media/"
+
+var imageTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
+}
+
+// resizableTypes are the image types the pipeline can decode and resize.
+var resizableTypes = []string{"image/jpeg", "image/png"}
+
+// Policy says what may be uploaded for one purpose and who may see it.
+type Policy struct {
+	EntityType string
+	MaxSize    int64
+	Types      map[string]string // allowed sniffed content type -> file extension
+	Public     bool
+	Renditions bool // make thumb, medium and large copies of images
+}
+
+var (
+	ProductImagePolicy       = Policy{EntityType: "product", MaxSize: 5 << 20, Types: imageTypes, Public: true, Renditions: true}
+	VendorProductImagePolicy = Policy{EntityType: "vendor_product", MaxSize: 5 << 20, Types: imageTypes, Public: true, Renditions: true}
+	VendorLogoPolicy         = Policy{EntityType: "vendor", MaxSize: 2 << 20, Types: imageTypes, Public: true, Renditions: true}
+	ReviewImagePolicy        = Policy{EntityType: "review", MaxSize: 5 << 20, Types: imageTypes, Public: true, Renditions: true}
+	FarmerDocumentPolicy     = Policy{
+		EntityType: "farmer",
+		MaxSize:    10 << 20,
+		Types:      map[string]string{"image/jpeg": ".jpg", "image/png": ".png", "application/pdf": ".pdf"},
+	}
+)
+
+var policies = []Policy{ProductImagePolicy, VendorProductImagePolicy, VendorLogoPolicy, ReviewImagePolicy, FarmerDocumentPolicy}
+
+// renditionEntityTypes lists the entity types whose images get renditions.
+func renditionEntityTypes() []string {
+	var types []string
+	for _, p := range policies {
+		if p.Renditions {
+			types = append(types, p.EntityType)
+		}
+	}
+	return types
+}
+
+func (p Policy) allowedTypes() string {
+	exts := make([]string, 0, len(p.Types))
+	for _, ext := range p.Types {
+		exts = append(exts, strings.ToUpper(strings.TrimPrefix(ext, ".")))
+	}
+	sort.Strings(exts)
+	return strings.Join(exts, ", ")
+}
+
+type UploadRequest struct {
+	Policy     Policy
+	EntityID   uuid.UUID // uuid.Nil when the record does not exist yet
+	UploadedBy uuid.UUID
+	File       *multipart.FileHeader
+}
+
+type MediaService interface {
+	Upload(ctx context.Context, req UploadRequest) (*common.FileAttachment, error)
+	SignedURL(ctx context.Context, attachment *common.FileAttachment) (*dto.SignedURLResponse, error)
+	GetPublicURL(ctx context.Context, attachmentID uuid.UUID, size string) (string, error)
+	GetSignedURL(ctx context.Context, attachmentID, userID uuid.UUID, userRole, size string) (*dto.SignedURLResponse, error)
+	OpenSigned(ctx context.Context, key string, query url.Values) (*storage.Object, error)
+	Delete(ctx context.Context, attachmentID, userID uuid.UUID, userRole string) error
+}
+
+type mediaService struct {
+	attachmentRepo repository.AttachmentRepository
+	renditionRepo  repository.RenditionRepository
+	storage        storage.Storage
+	urlExpiry      time.Duration
+	renditions     RenditionQueue
+}
+
+func NewMediaService(attachmentRepo repository.AttachmentRepository, renditionRepo repository.RenditionRepository, store storage.Storage, urlExpiry time.Duration, renditions RenditionQueue) MediaService {
+	return &mediaService{
+		attachmentRepo: attachmentRepo,
+		renditionRepo:  renditionRepo,
+		storage:        store,
+		urlExpiry:      urlExpiry,
+		renditions:     renditions,
+	}
+}
+
+// Upload checks a file against the policy by its content rather than its
+// name, strips any metadata, stores it under a key derived from the result
+// and records the upload. Renditions are made in the background.
+func (s *mediaService) Upload(ctx context.Context, req UploadRequest) (*common.FileAttachment, error) {
+	if req.File.Size > req.Policy.MaxSize {
+		return nil, fmt.Errorf("%w. Maximum size is %dMB", ErrFileTooLarge, req.Policy.MaxSize>>20)
+	}
+
+	file, err := req.File.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	// The header size comes from the client, so enforce the limit on read too
+	data, err := io.ReadAll(io.LimitReader(file, req.Policy.MaxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	if int64(len(data)) > req.Policy.MaxSize {
+		return nil, fmt.Errorf("%w. Maximum size is %dMB", ErrFileTooLarge, req.Policy.MaxSize>>20)
+	}
+
+	contentType := http.DetectContentType(data)
+	ext, ok := req.Policy.Types[contentType]
+	if !ok {
+		return nil, fmt.Errorf("%w. Allowed types: %s", ErrUnsupportedFileType, req.Policy.allowedTypes())
+	}
+
+	data, width, height, err := sanitize(data, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	key := storage.ContentKey(data, ext)
+	sum := sha256.Sum256(data)
+	attachment := &common.FileAttachment{
+		ID:         uuid.New(),
+		EntityType: req.Policy.EntityType,
+		EntityID:   req.EntityID,
+		FileName:   filepath.Base(req.File.Filename),
+		FileType:   contentType,
+		FileSize:   int64(len(data)),
+		Width:      width,
+		Height:     height,
+		StorageKey: key,
+		Checksum:   hex.EncodeToString(sum[:]),
+		Public:     req.Policy.Public,
+		UploadedBy: req.UploadedBy,
+		CreatedAt:  time.Now(),
+	}
+	attachment.FileURL = AttachmentURLPrefix + attachment.ID.String()
+
+	// A sweep may be deleting an earlier copy of the same file, so store it
+	// and record it under the key's lock
+	err = s.attachmentRepo.WithStorageKeyLock(ctx, key, func(attachments repository.AttachmentRepository, _ repository.RenditionRepository) error {
+		if err := s.storage.Put(ctx, key, data, contentType); err != nil {
+			return fmt.Errorf("failed to store file: %w", err)
+		}
+		if err := attachments.Create(ctx, attachment); err != nil {
+			return fmt.Errorf("failed to record upload: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Policy.Renditions && width > 0 {
+		s.renditions.Enqueue(attachment.ID)
+	}
+	return attachment, nil
+}
+
+func (s *mediaService) SignedURL(ctx context.Context, attachment *common.FileAttachment) (*dto.SignedURLResponse, error) {
+	return s.signKey(ctx, attachment.StorageKey)
+}
+
+// GetPublicURL signs a URL for a public upload, or one of its renditions.
+// Private uploads are reported as not found so their existence isn't given
+// away.
+func (s *mediaService) GetPublicURL(ctx context.Context, attachmentID uuid.UUID, size string) (string, error) {
+	renditionSize, err := parseSize(size)
+	if err != nil {
+		return "", err
+	}
+
+	attachment, err := s.attachmentRepo.FindByID(ctx, attachmentID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get file: %w", err)
+	}
+	if attachment == nil || !attachment.Public {
+		return "", ErrAttachmentNotFound
+	}
+
+	key, err := s.keyFor(ctx, attachment, renditionSize)
+	if err != nil {
+		return "", err
+	}
+	signed, err := s.signKey(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	return signed.URL, nil
+}
+
+// GetSignedURL signs a URL for any upload the user may see: public ones,
+// their own, or any at all for admins.
+func (s *mediaService) GetSignedURL(ctx context.Context, attachmentID, userID uuid.UUID, userRole, size string) (*dto.SignedURLResponse, error) {
+	renditionSize, err := parseSize(size)
+	if err != nil {
+		return nil, err
+	}
+
+	attachment, err := s.findVisible(ctx, attachmentID, userID, userRole)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := s.keyFor(ctx, attachment, renditionSize)
+	if err != nil {
+		return nil, err
+	}
+	return s.signKey(ctx, key)
+}
+
+// OpenSigned opens a file for a signed URL made by a backend that relies on
+// the API to serve its files.
+func (s *mediaService) OpenSigned(ctx context.Context, key string, query url.Values) (*storage.Object, error) {
+	verifier, ok := s.storage.(interface {
+		VerifySignedURL(key string, query url.Values) bool
+	})
+	if !ok || !storage.ValidKey(key) || !verifier.VerifySignedURL(key, query) {
+		return nil, ErrInvalidSignature
+	}
+
+	object, err := s.storage.Get(ctx, key)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, ErrAttachmentNotFound
+	}
+	return object, err
+}
+
+// Delete removes an upload and its renditions. Stored files go too once
+// nothing else shares them.
+func (s *mediaService) Delete(ctx context.Context, attachmentID, userID uuid.UUID, userRole string) error {
+	attachment, err := s.findVisible(ctx, attachmentID, userID, userRole)
+	if err != nil {
+		return err
+	}
+	if attachment.UploadedBy != userID && userRole != "admin" {
+		return ErrUnauthorizedAccess
+	}
+
+	_, err = removeAttachment(ctx, s.attachmentRepo, s.renditionRepo, s.storage, attachment)
+	return err
+}
+
+// Helper methods
+func (s *mediaService) signKey(ctx context.Context, key string) (*dto.SignedURLResponse, error) {
+	expiresAt := time.Now().Add(s.urlExpiry)
+	signed, err := s.storage.SignedURL(ctx, key, s.urlExpiry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign url: %w", err)
+	}
+	return &dto.SignedURLResponse{URL: signed, ExpiresAt: expiresAt}, nil
+}
+
+// keyFor picks the stored file for a requested size: that rendition if it
+// is ready, otherwise the next larger one, otherwise the upload itself.
+func (s *mediaService) keyFor(ctx context.Context, attachment *common.FileAttachment, size model.RenditionSize) (string, error) {
+	if size == "" {
+		return attachment.StorageKey, nil
+	}
+
+	renditions, err := s.renditionRepo.FindByAttachmentID(ctx, attachment.ID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get renditions: %w", err)
+	}
+	bySize := make(map[model.RenditionSize]string, len(renditions))
+	for _, rendition := range renditions {
+		bySize[rendition.Size] = rendition.StorageKey
+	}
+
+	for _, candidate := range model.RenditionSizes {
+		if model.RenditionSides[candidate] < model.RenditionSides[size] {
+			continue
+		}
+		if key, ok := bySize[candidate]; ok {
+			return key, nil
+		}
+	}
+	return attachment.StorageKey, nil
+}
+
+func (s *mediaService) findVisible(ctx context.Context, attachmentID, userID uuid.UUID, userRole string) (*common.FileAttachment, error) {
+	attachment, err := s.attachmentRepo.FindByID(ctx, attachmentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file: %w", err)
+	}
+	if attachment == nil {
+		return nil, ErrAttachmentNotFound
+	}
+	if !attachment.Public && attachment.UploadedBy != userID && userRole != "admin" {
+		return nil, ErrAttachmentNotFound
+	}
+	return attachment, nil
+}
+
+// removeAttachment deletes an upload and its renditions, then every stored
+// file nothing else shares. It returns the bytes freed in storage.
+func removeAttachment(ctx context.Context, attachmentRepo repository.AttachmentRepository, renditionRepo repository.RenditionRepository, store storage.Storage, attachment *common.FileAttachment) (int64, error) {
+	renditions, err := renditionRepo.FindByAttachmentID(ctx, attachment.ID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get renditions: %w", err)
+	}
+
+	if err := attachmentRepo.Delete(ctx, attachment.ID); err != nil {
+		return 0, fmt.Errorf("failed to delete file: %w", err)
+	}
+	if err := renditionRepo.DeleteByAttachmentID(ctx, attachment.ID); err != nil {
+		log.Printf("failed to delete renditions of %s: %v", attachment.ID, err)
+		return 0, nil
+	}
+
+	files := map[string]int64{attachment.StorageKey: attachment.FileSize}
+	for _, rendition := range renditions {
+		files[rendition.StorageKey] = rendition.FileSize
+	}
+
+	var freed int64
+	for key, size := range files {
+		if deleteIfUnused(ctx, attachmentRepo, store, key) {
+			freed += size
+		}
+	}
+	return freed, nil
+}
+
+// deleteIfUnused deletes a stored file once no upload or rendition uses it,
+// and reports whether it did. The key stays locked meanwhile, so an upload
+// of the same file waits rather than losing it.
+func deleteIfUnused(ctx context.Context, attachmentRepo repository.AttachmentRepository, store storage.Storage, key string) bool {
+	deleted := false
+	err := attachmentRepo.WithStorageKeyLock(ctx, key, func(attachments repository.AttachmentRepository, _ repository.RenditionRepository) error {
+		remaining, err := attachments.CountByStorageKey(ctx, key)
+		if err != nil {
+			return fmt.Errorf("failed to count uses of %s: %w", key, err)
+		}
+		if remaining > 0 {
+			return nil
+		}
+		if err := store.Delete(ctx, key); err != nil {
+			return fmt.Errorf("failed to delete stored file %s: %w", key, err)
+		}
+		deleted = true
+		return nil
+	})
+	if err != nil {
+		log.Print(err)
+		return false
+	}
+	return deleted
+}
+
+// parseSize reads the size asked for when fetching a file; empty or
+// "original" means the upload itself.
+func parseSize(size string) (model.RenditionSize, error) {
+	if size == "" || size == "original" {
+		return "", nil
+	}
+	if _, ok := model.RenditionSides[model.RenditionSize(size)]; !ok {
+		return "", ErrInvalidSize
+	}
+	return model.RenditionSize(size), nil
+}
+
+// sanitize re-encodes JPEG and PNG images so nothing from the upload's
+// metadata survives, turning them upright first since the orientation tag
+// goes too. WebP can only have its metadata chunks cut out. Other files are
+// kept as they are.
+func sanitize(data []byte, contentType string) ([]byte, int, int, error) {
+	switch contentType {
+	case "image/jpeg", "image/png":
+		img, format, err := imaging.Decode(data)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		encoded, _, err := imaging.Encode(img, format)
+		if err != nil {
+			return nil, 0, 0, fmt.Errorf("failed to encode image: %w", err)
+		}
+		return encoded, img.Bounds().Dx(), img.Bounds().Dy(), nil
+	case "image/webp":
+		stripped, err := imaging.StripWebPMetadata(data)
+		return stripped, 0, 0, err
+	default:
+		return data, 0, 0, nil
+	}
+}
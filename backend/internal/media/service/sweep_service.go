@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"agro_konnect/internal/media/repository"
+	"agro_konnect/pkg/storage"
+
+	"github.com/google/uuid"
+)
+
+// SweepResult reports what an orphan sweep deleted.
+type SweepResult struct {
+	Uploads     int
+	Renditions  int
+	LegacyFiles int
+	BytesFreed  int64
+}
+
+// OrphanSweeper deletes uploads that nothing links to. Uploads are stored
+// before the record using them is saved, and records drop images without
+// deleting them, so these pile up otherwise.
+type OrphanSweeper interface {
+	Sweep(ctx context.Context, uploadedBefore time.Time) (*SweepResult, error)
+}
+
+type orphanSweeper struct {
+	attachmentRepo repository.AttachmentRepository
+	renditionRepo  repository.RenditionRepository
+	referenceRepo  repository.ReferenceRepository
+	storage        storage.Storage
+	legacyDirs     []string
+}
+
+// NewOrphanSweeper builds a sweeper. legacyDirs are the directories images
+// were saved to before media storage; their files are swept by name.
+func NewOrphanSweeper(attachmentRepo repository.AttachmentRepository, renditionRepo repository.RenditionRepository, referenceRepo repository.ReferenceRepository, store storage.Storage, legacyDirs []string) OrphanSweeper {
+	return &orphanSweeper{
+		attachmentRepo: attachmentRepo,
+		renditionRepo:  renditionRepo,
+		referenceRepo:  referenceRepo,
+		storage:        store,
+		legacyDirs:     legacyDirs,
+	}
+}
+
+// Sweep deletes uploads made before the cutoff that no product, review,
+// vendor product, vendor logo or farmer document links to. The cutoff
+// gives an upload time to be attached to the record it was made for.
+func (s *orphanSweeper) Sweep(ctx context.Context, uploadedBefore time.Time) (*SweepResult, error) {
+	// References are read before candidates so that an upload attached in
+	// between is at worst left for the next sweep
+	urls, err := s.referenceRepo.FindImageURLs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find image references: %w", err)
+	}
+	documentIDs, err := s.referenceRepo.FindDocumentAttachmentIDs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find document references: %w", err)
+	}
+
+	referenced := make(map[uuid.UUID]bool, len(urls)+len(documentIDs))
+	legacyNames := make(map[string]bool)
+	for _, raw := range urls {
+		if id, ok := attachmentIDFromURL(raw); ok {
+			referenced[id] = true
+		} else if name := legacyFileName(raw); name != "" {
+			legacyNames[name] = true
+		}
+	}
+	for _, id := range documentIDs {
+		referenced[id] = true
+	}
+
+	entityTypes := make([]string, len(policies))
+	for i, p := range policies {
+		entityTypes[i] = p.EntityType
+	}
+	candidates, err := s.attachmentRepo.FindCreatedBefore(ctx, entityTypes, uploadedBefore)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find uploads: %w", err)
+	}
+
+	result := &SweepResult{}
+	for _, attachment := range candidates {
+		if referenced[attachment.ID] {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		freed, err := removeAttachment(ctx, s.attachmentRepo, s.renditionRepo, s.storage, attachment)
+		if err != nil {
+			log.Printf("failed to delete orphaned upload %s: %v", attachment.ID, err)
+			continue
+		}
+		result.Uploads++
+		result.BytesFreed += freed
+	}
+
+	renditions, err := s.renditionRepo.FindOrphaned(ctx)
+	if err != nil {
+		return result, fmt.Errorf("failed to find orphaned renditions: %w", err)
+	}
+	for _, rendition := range renditions {
+		if err := s.renditionRepo.Delete(ctx, rendition.ID); err != nil {
+			log.Printf("failed to delete orphaned rendition %s: %v", rendition.ID, err)
+			continue
+		}
+		result.Renditions++
+		if deleteIfUnused(ctx, s.attachmentRepo, s.storage, rendition.StorageKey) {
+			result.BytesFreed += rendition.FileSize
+		}
+	}
+
+	for _, dir := range s.legacyDirs {
+		if err := s.sweepLegacyDir(ctx, dir, legacyNames, uploadedBefore, result); err != nil {
+			return result, fmt.Errorf("failed to sweep %s: %w", dir, err)
+		}
+	}
+	return result, nil
+}
+
+// sweepLegacyDir deletes files saved before media storage that are older
+// than the cutoff and whose name no image URL ends in.
+func (s *orphanSweeper) sweepLegacyDir(ctx context.Context, dir string, referenced map[string]bool, before time.Time, result *SweepResult) error {
+	err := filepath.WalkDir(dir, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if !entry.Type().IsRegular() || referenced[entry.Name()] {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		if !info.ModTime().Before(before) {
+			return nil
+		}
+		if err := os.Remove(p); err != nil {
+			log.Printf("failed to delete orphaned file %s: %v", p, err)
+			return nil
+		}
+		result.LegacyFiles++
+		result.BytesFreed += info.Size()
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// attachmentIDFromURL reads the upload ID from a media URL, relative or
+// absolute and with or without a size.
+func attachmentIDFromURL(raw string) (uuid.UUID, bool) {
+	i := strings.Index(raw, AttachmentURLPrefix)
+	if i < 0 {
+		return uuid.Nil, false
+	}
+	rest := raw[i+len(AttachmentURLPrefix):]
+	if end := strings.IndexAny(rest, "/?#"); end >= 0 {
+		rest = rest[:end]
+	}
+	id, err := uuid.Parse(rest)
+	return id, err == nil
+}
+
+// legacyFileName returns the file name an image URL from before media
+// storage ends in.
+func legacyFileName(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Path == "" {
+		return ""
+	}
+	name := path.Base(u.Path)
+	if name == "." || name == "/" {
+		return ""
+	}
+	return name
+}
@@ -9,18 +9,22 @@ import (
 
 // Request DTOs
 type CreateOrderRequest struct {
-	ShippingAddress string              `json:"shipping_address" validate:"required"`
-	ShippingCity    string              `json:"shipping_city" validate:"required"`
-	ShippingState   string              `json:"shipping_state" validate:"required"`
-	ShippingZipCode string              `json:"shipping_zip_code"`
-	ShippingNotes   string              `json:"shipping_notes"`
-	PaymentMethod   model.PaymentMethod `json:"payment_method" validate:"required,oneof=bank_transfer credit_card digital_wallet upi cash_on_delivery"`
-	Items           []OrderItemRequest  `json:"items" validate:"required,min=1"`
+	ShippingAddress   string              `json:"shipping_address" validate:"required"`
+	ShippingCity      string              `json:"shipping_city" validate:"required"`
+	ShippingState     string              `json:"shipping_state" validate:"required"`
+	ShippingZipCode   string              `json:"shipping_zip_code"`
+	ShippingNotes     string              `json:"shipping_notes"`
+	ShippingLatitude  float64             `json:"shipping_latitude" validate:"omitempty,latitude"`
+	ShippingLongitude float64             `json:"shipping_longitude" validate:"omitempty,longitude"`
+	PaymentMethod     model.PaymentMethod `json:"payment_method" validate:"required,oneof=bank_transfer credit_card digital_wallet upi cash_on_delivery"`
+	Items             []OrderItemRequest  `json:"items" validate:"required,min=1"`
 }
 
 type OrderItemRequest struct {
 	ProductID uuid.UUID `json:"product_id" validate:"required"`
-	Quantity  float64   `json:"quantity" validate:"min=0.1"`
+	// Required for products with packaging options; quantity is then in packs
+	VariantID *uuid.UUID `json:"variant_id"`
+	Quantity  float64    `json:"quantity" validate:"min=0.1"`
 }
 
 type UpdateOrderStatusRequest struct {
@@ -31,7 +35,12 @@ type UpdateOrderStatusRequest struct {
 type AssignTransporterRequest struct {
 	TransporterID     uuid.UUID `json:"transporter_id" validate:"required"`
 	VehicleID         uuid.UUID `json:"vehicle_id"`
+	PickupTime        string    `json:"pickup_time"` // defaults to now
 	EstimatedDelivery string    `json:"estimated_delivery" validate:"required"`
+
+	// Load to reserve on the vehicle; estimated from order items when omitted
+	LoadWeight float64 `json:"load_weight" validate:"min=0"` // in tons
+	LoadVolume float64 `json:"load_volume" validate:"min=0"` // in cubic meters
 }
 
 type PaymentRequest struct {
@@ -69,12 +78,19 @@ type OrderResponse struct {
 	ShippingState   string `json:"shipping_state"`
 	ShippingZipCode string `json:"shipping_zip_code"`
 
+	ShippingLatitude  float64 `json:"shipping_latitude,omitempty"`
+	ShippingLongitude float64 `json:"shipping_longitude,omitempty"`
+
 	EstimatedDelivery time.Time  `json:"estimated_delivery"`
 	ActualDelivery    *time.Time `json:"actual_delivery,omitempty"`
 
 	TrackingNumber string `json:"tracking_number,omitempty"`
 	TrackingURL    string `json:"tracking_url,omitempty"`
 
+	QuoteID       *uuid.UUID `json:"quote_id,omitempty"`       // RFQ quote the order was negotiated from
+	ReservationID *uuid.UUID `json:"reservation_id,omitempty"` // harvest reservation the order was placed from
+	DepositPaid   float64    `json:"deposit_paid,omitempty"`
+
 	OrderItems      []OrderItemResponse `json:"order_items"`
 	TrackingHistory []TrackingResponse  `json:"tracking_history,omitempty"`
 
@@ -82,16 +98,23 @@ type OrderResponse struct {
 }
 
 type OrderItemResponse struct {
-	ID           uuid.UUID `json:"id"`
-	ProductID    uuid.UUID `json:"product_id"`
-	ProductName  string    `json:"product_name"`
-	ProductImage string    `json:"product_image"`
-	UnitPrice    float64   `json:"unit_price"`
-	Quantity     float64   `json:"quantity"`
-	Unit         string    `json:"unit"`
-	TotalPrice   float64   `json:"total_price"`
-	QualityGrade string    `json:"quality_grade"`
-	Organic      bool      `json:"organic"`
+	ID            uuid.UUID  `json:"id"`
+	ProductID     uuid.UUID  `json:"product_id"`
+	ProductName   string     `json:"product_name"`
+	ProductImage  string     `json:"product_image"`
+	VariantID     *uuid.UUID `json:"variant_id,omitempty"`
+	VariantName   string     `json:"variant_name,omitempty"`
+	PackSize      float64    `json:"pack_size,omitempty"`
+	PackUnit      string     `json:"pack_unit,omitempty"`
+	ListPrice     float64    `json:"list_price"`
+	UnitPrice     float64    `json:"unit_price"`
+	PriceTierID   *uuid.UUID `json:"price_tier_id,omitempty"`
+	PriceTierName string     `json:"price_tier_name,omitempty"`
+	Quantity      float64    `json:"quantity"`
+	Unit          string     `json:"unit"`
+	TotalPrice    float64    `json:"total_price"`
+	QualityGrade  string     `json:"quality_grade"`
+	Organic       bool       `json:"organic"`
 }
 
 type TrackingResponse struct {
@@ -52,8 +52,10 @@ func (h *OrderHandler) CreateOrder(c *gin.Context) {
 
 	order, err := h.orderService.CreateOrder(c.Request.Context(), userID, &req)
 	if err != nil {
-		switch err {
-		case service.ErrInvalidOrderData, service.ErrInsufficientStock:
+		switch {
+		case errors.Is(err, service.ErrInvalidOrderData),
+			errors.Is(err, service.ErrInsufficientStock),
+			errors.Is(err, service.ErrInvalidVariant):
 			utils.RespondWithError(c, http.StatusBadRequest, err.Error())
 		default:
 			utils.RespondWithError(c, http.StatusInternalServerError, "Failed to create order")
@@ -276,12 +278,23 @@ func (h *OrderHandler) AssignTransporter(c *gin.Context) {
 		return
 	}
 
-	if err := h.orderService.AssignTransporter(c.Request.Context(), orderID, userID, &req); err != nil {
+	// Orders reference the farmer profile, not the user
+	farmer, err := h.farmerRepo.FindByUserID(c.Request.Context(), userID)
+	if err != nil || farmer == nil {
+		utils.RespondWithError(c, http.StatusNotFound, "Farmer profile not found")
+		return
+	}
+
+	if err := h.orderService.AssignTransporter(c.Request.Context(), orderID, farmer.ID, &req); err != nil {
 		switch err {
 		case service.ErrOrderNotFound:
 			utils.RespondWithError(c, http.StatusNotFound, err.Error())
 		case service.ErrUnauthorizedAccess:
 			utils.RespondWithError(c, http.StatusForbidden, err.Error())
+		case service.ErrInvalidVehicle:
+			utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		case service.ErrVehicleUnavailable:
+			utils.RespondWithError(c, http.StatusConflict, err.Error())
 		default:
 			utils.RespondWithError(c, http.StatusInternalServerError, "Failed to assign transporter")
 		}
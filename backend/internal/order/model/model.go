@@ -1,6 +1,7 @@
 package order
 
 import (
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -71,6 +72,10 @@ type Order struct {
 	ShippingZipCode string `json:"shipping_zip_code"`
 	ShippingNotes   string `json:"shipping_notes"`
 
+	// Drop coordinates used for trip planning; zero when not supplied
+	ShippingLatitude  float64 `json:"shipping_latitude"`
+	ShippingLongitude float64 `json:"shipping_longitude"`
+
 	// Delivery Information
 	EstimatedDelivery time.Time  `json:"estimated_delivery"`
 	ActualDelivery    *time.Time `json:"actual_delivery"`
@@ -84,6 +89,16 @@ type Order struct {
 	UpdatedAt   time.Time  `json:"updated_at"`
 	CancelledAt *time.Time `json:"cancelled_at"`
 
+	// Last time the farmer was reminded about this order sitting unprocessed
+	ReminderSentAt *time.Time `json:"reminder_sent_at"`
+
+	// RFQ quote or harvest reservation the order was placed from, if any
+	QuoteID       *uuid.UUID `gorm:"type:uuid;uniqueIndex" json:"quote_id"`
+	ReservationID *uuid.UUID `gorm:"type:uuid;uniqueIndex" json:"reservation_id"`
+
+	// Deposit paid with a harvest reservation; part of TotalAmount
+	DepositPaid float64 `gorm:"type:decimal(10,2);default:0" json:"deposit_paid"`
+
 	// Relationships
 	OrderItems []OrderItem `gorm:"foreignKey:OrderID" json:"order_items"`
 }
@@ -93,6 +108,13 @@ type OrderItem struct {
 	OrderID   uuid.UUID `gorm:"not null" json:"order_id"`
 	ProductID uuid.UUID `gorm:"not null" json:"product_id"`
 
+	// Packaging option ordered, if the product has variants. Quantity is
+	// then a number of packs, each holding PackSize of PackUnit.
+	VariantID   *uuid.UUID `gorm:"type:uuid;index" json:"variant_id"`
+	VariantName string     `json:"variant_name"`
+	PackSize    float64    `gorm:"type:decimal(10,3)" json:"pack_size"`
+	PackUnit    string     `json:"pack_unit"`
+
 	ProductName  string  `gorm:"not null" json:"product_name"`
 	ProductImage string  `json:"product_image"`
 	UnitPrice    float64 `gorm:"type:decimal(10,2);not null" json:"unit_price"`
@@ -100,12 +122,46 @@ type OrderItem struct {
 	Unit         string  `gorm:"not null" json:"unit"`
 	TotalPrice   float64 `gorm:"type:decimal(10,2);not null" json:"total_price"`
 
+	// Pricing applied: the list price, and the price tier that replaced it
+	ListPrice     float64    `gorm:"type:decimal(10,2)" json:"list_price"`
+	PriceTierID   *uuid.UUID `gorm:"type:uuid" json:"price_tier_id"`
+	PriceTierName string     `json:"price_tier_name"`
+
 	// Product details at time of order
 	QualityGrade string    `json:"quality_grade"`
 	Organic      bool      `json:"organic"`
 	HarvestDate  time.Time `json:"harvest_date"`
 }
 
+// EstimatedWeight converts the order's item quantities into tons. Items sold
+// in units that carry no weight (piece, dozen, ...) are not counted.
+func (o *Order) EstimatedWeight() float64 {
+	var tons float64
+	for _, item := range o.OrderItems {
+		quantity, unit := item.Quantity, item.Unit
+		if item.PackSize > 0 {
+			quantity, unit = item.Quantity*item.PackSize, item.PackUnit
+		}
+
+		switch strings.ToLower(strings.TrimSpace(unit)) {
+		case "g", "gram", "grams":
+			tons += quantity / 1000000
+		case "kg", "kgs", "kilogram", "kilograms":
+			tons += quantity / 1000
+		case "quintal", "quintals":
+			tons += quantity / 10
+		case "t", "ton", "tons", "tonne", "tonnes":
+			tons += quantity
+		}
+	}
+	return tons
+}
+
+// HasDropLocation reports whether drop coordinates were supplied.
+func (o *Order) HasDropLocation() bool {
+	return o.ShippingLatitude != 0 || o.ShippingLongitude != 0
+}
+
 type OrderTracking struct {
 	ID          uuid.UUID   `gorm:"type:uuid;primary_key" json:"id"`
 	OrderID     uuid.UUID   `gorm:"not null" json:"order_id"`
@@ -24,6 +24,7 @@ type OrderRepository interface {
 	GetTrackingHistory(ctx context.Context, orderID uuid.UUID) ([]*model.OrderTracking, error)
 	GetOrderSummary(ctx context.Context, userID uuid.UUID, userType string) (*model.OrderSummary, error)
 	FindOrdersWithFilters(ctx context.Context, filters OrderFilter) ([]*model.Order, int64, error)
+	FindUnpostedLedgerOrders(ctx context.Context, since time.Time, limit int) ([]*model.Order, error)
 }
 
 type OrderFilter struct {
@@ -138,12 +139,18 @@ func (r *orderRepository) Update(ctx context.Context, order *model.Order) error
 }
 
 func (r *orderRepository) UpdateStatus(ctx context.Context, orderID uuid.UUID, status model.OrderStatus) error {
+	updates := map[string]interface{}{
+		"status":     status,
+		"updated_at": time.Now(),
+	}
+
+	if status == model.OrderStatusDelivered {
+		updates["actual_delivery"] = time.Now()
+	}
+
 	return r.db.WithContext(ctx).Model(&model.Order{}).
 		Where("id = ?", orderID).
-		Updates(map[string]interface{}{
-			"status":     status,
-			"updated_at": time.Now(),
-		}).Error
+		Updates(updates).Error
 }
 
 func (r *orderRepository) UpdatePaymentStatus(ctx context.Context, orderID uuid.UUID, paymentStatus model.PaymentStatus, paymentID string) error {
@@ -249,3 +256,24 @@ func (r *orderRepository) FindOrdersWithFilters(ctx context.Context, filters Ord
 
 	return orders, total, err
 }
+
+// FindUnpostedLedgerOrders returns orders changed since a time whose farmer
+// ledger is behind: paid orders with no payment posted, and refunded ones
+// whose payment was posted but not reversed.
+func (r *orderRepository) FindUnpostedLedgerOrders(ctx context.Context, since time.Time, limit int) ([]*model.Order, error) {
+	var orders []*model.Order
+	err := r.db.WithContext(ctx).
+		Preload("OrderItems").
+		Where("updated_at >= ?", since).
+		Where(`(payment_status = ? AND NOT EXISTS (
+				SELECT 1 FROM ledger_transactions t WHERE t.order_id = orders.id AND t.type = 'payment'))
+			OR (payment_status = ? AND EXISTS (
+				SELECT 1 FROM ledger_transactions t WHERE t.order_id = orders.id AND t.type = 'payment')
+			AND NOT EXISTS (
+				SELECT 1 FROM ledger_transactions t WHERE t.order_id = orders.id AND t.type = 'refund'))`,
+			model.PaymentStatusPaid, model.PaymentStatusRefunded).
+		Order("updated_at ASC").
+		Limit(limit).
+		Find(&orders).Error
+	return orders, err
+}
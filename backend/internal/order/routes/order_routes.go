@@ -5,21 +5,14 @@ import (
 	"agro_konnect/internal/auth/model"
 	farmerRepo "agro_konnect/internal/farmer/repository"
 	"agro_konnect/internal/order/handler"
-	"agro_konnect/internal/order/repository"
 	"agro_konnect/internal/order/service"
-	productRepo "agro_konnect/internal/product/repository"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
-func SetupOrderRoutes(router *gin.RouterGroup, db *gorm.DB, authMiddleware *middleware.AuthMiddleware) {
-	// Initialize order dependencies
-	orderRepo := repository.NewOrderRepository(db)
-	productRepo := productRepo.NewProductRepository(db)
-	farmerRepo := farmerRepo.NewFarmerRepository(db)
-	orderService := service.NewOrderService(orderRepo, productRepo)
-	orderHandler := handler.NewOrderHandler(orderService, farmerRepo)
+func SetupOrderRoutes(router *gin.RouterGroup, db *gorm.DB, authMiddleware *middleware.AuthMiddleware, orderService service.OrderService) {
+	orderHandler := handler.NewOrderHandler(orderService, farmerRepo.NewFarmerRepository(db))
 
 	orderRoutes := router.Group("/orders")
 	{
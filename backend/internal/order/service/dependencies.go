@@ -0,0 +1,56 @@
+package service
+
+import (
+	buyerRepo "agro_konnect/internal/buyer/repository"
+	emailService "agro_konnect/internal/email/service"
+	farmerRepo "agro_konnect/internal/farmer/repository"
+	farmerService "agro_konnect/internal/farmer/service"
+	"agro_konnect/internal/order/repository"
+	productRepo "agro_konnect/internal/product/repository"
+	productService "agro_konnect/internal/product/service"
+	transporterRepo "agro_konnect/internal/transporter/repository"
+	transporterService "agro_konnect/internal/transporter/service"
+
+	"gorm.io/gorm"
+)
+
+// Dependencies are what orders are placed, shipped and settled with. They
+// are built once at start-up and shared by the route groups that place
+// orders and by the background jobs that settle them.
+type Dependencies struct {
+	Orders       repository.OrderRepository
+	Products     productRepo.ProductRepository
+	Availability transporterService.AvailabilityService
+	Settlements  transporterService.SettlementService
+	Ledger       farmerService.LedgerService
+	Pricing      productService.PricingService
+}
+
+func NewDependencies(db *gorm.DB) *Dependencies {
+	products := productRepo.NewProductRepository(db)
+	farmers := farmerRepo.NewFarmerRepository(db)
+	return &Dependencies{
+		Orders:   repository.NewOrderRepository(db),
+		Products: products,
+		Availability: transporterService.NewAvailabilityService(
+			transporterRepo.NewAvailabilityRepository(db),
+			transporterRepo.NewVehicleRepository(db),
+		),
+		Settlements: transporterService.NewSettlementService(
+			transporterRepo.NewSettlementRepository(db),
+			transporterService.LoadSettlementConfig(),
+		),
+		Ledger: farmerService.NewLedgerService(
+			farmerRepo.NewLedgerRepository(db),
+			farmerRepo.NewPayoutRepository(db),
+			farmers,
+			farmerService.LoadLedgerConfig(),
+		),
+		Pricing: productService.NewPricingService(products, farmers, buyerRepo.NewBuyerRepository(db)),
+	}
+}
+
+// NewOrderService builds the order service on the shared dependencies.
+func (d *Dependencies) NewOrderService(mailer emailService.Mailer) OrderService {
+	return NewOrderService(d.Orders, d.Products, d.Availability, d.Settlements, d.Ledger, d.Pricing, mailer)
+}
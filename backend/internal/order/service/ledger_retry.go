@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	farmerService "agro_konnect/internal/farmer/service"
+	model "agro_konnect/internal/order/model"
+	"agro_konnect/internal/order/repository"
+)
+
+// ledgerRetryBatchSize caps how many orders one retry run posts.
+const ledgerRetryBatchSize = 200
+
+// LedgerRetrier posts the farmer ledger entries that failed when an order
+// was paid or refunded. Payments and refunds are only ever posted once, so
+// an order that was posted in the meantime is left alone.
+type LedgerRetrier interface {
+	PostMissing(ctx context.Context, since time.Time) (*LedgerRetryResult, error)
+}
+
+type LedgerRetryResult struct {
+	Payments int
+	Refunds  int
+	Failed   int
+}
+
+type ledgerRetrier struct {
+	orderRepo     repository.OrderRepository
+	ledgerService farmerService.LedgerService
+}
+
+func NewLedgerRetrier(orderRepo repository.OrderRepository, ledgerService farmerService.LedgerService) LedgerRetrier {
+	return &ledgerRetrier{
+		orderRepo:     orderRepo,
+		ledgerService: ledgerService,
+	}
+}
+
+// PostMissing retries orders changed since a time. A refund whose payment
+// was never posted needs nothing, as there is nothing to reverse.
+func (r *ledgerRetrier) PostMissing(ctx context.Context, since time.Time) (*LedgerRetryResult, error) {
+	orders, err := r.orderRepo.FindUnpostedLedgerOrders(ctx, since, ledgerRetryBatchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &LedgerRetryResult{}
+	for _, order := range orders {
+		switch order.PaymentStatus {
+		case model.PaymentStatusPaid:
+			if err := r.ledgerService.RecordPayment(ctx, toLedgerPayment(order)); err != nil {
+				log.Printf("failed to record ledger payment for order %s: %v", order.OrderNumber, err)
+				result.Failed++
+				continue
+			}
+			result.Payments++
+		case model.PaymentStatusRefunded:
+			if err := r.ledgerService.RecordRefund(ctx, order.ID); err != nil {
+				log.Printf("failed to record ledger refund for order %s: %v", order.OrderNumber, err)
+				result.Failed++
+				continue
+			}
+			result.Refunds++
+		}
+	}
+	return result, nil
+}
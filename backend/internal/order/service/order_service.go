@@ -4,15 +4,24 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log"
+	"math"
 	"strings"
 	"time"
 
+	emailService "agro_konnect/internal/email/service"
+	farmerDto "agro_konnect/internal/farmer/dto"
+	farmerService "agro_konnect/internal/farmer/service"
 	dto "agro_konnect/internal/order/dto"
 	model "agro_konnect/internal/order/model"
 	"agro_konnect/internal/order/repository"
 	"agro_konnect/internal/order/utils"
 	productModel "agro_konnect/internal/product/model"
 	productRepo "agro_konnect/internal/product/repository"
+	productService "agro_konnect/internal/product/service"
+	transporterDto "agro_konnect/internal/transporter/dto"
+	transporterModel "agro_konnect/internal/transporter/model"
+	transporterService "agro_konnect/internal/transporter/service"
 
 	"github.com/google/uuid"
 )
@@ -26,10 +35,14 @@ var (
 	ErrPaymentRequired    = errors.New("payment required")
 	ErrOrderAlreadyPaid   = errors.New("order already paid")
 	ErrInvalidPayment     = errors.New("invalid payment")
+	ErrInvalidVehicle     = errors.New("vehicle not found for this transporter")
+	ErrVehicleUnavailable = errors.New("vehicle is not available for the requested window and load")
+	ErrInvalidVariant     = errors.New("invalid packaging option")
 )
 
 type OrderService interface {
 	CreateOrder(ctx context.Context, buyerID uuid.UUID, req *dto.CreateOrderRequest) (*model.Order, error)
+	CreateNegotiatedOrder(ctx context.Context, buyerID uuid.UUID, req *dto.CreateOrderRequest, terms NegotiatedTerms) (*model.Order, error)
 	GetOrderByID(ctx context.Context, orderID uuid.UUID, userID uuid.UUID, userRole string) (*dto.OrderResponse, error)
 	GetOrderByNumber(ctx context.Context, orderNumber string, userID uuid.UUID, userRole string) (*dto.OrderResponse, error)
 	GetBuyerOrders(ctx context.Context, buyerID uuid.UUID, page, pageSize int) (*dto.OrderListResponse, error)
@@ -44,19 +57,58 @@ type OrderService interface {
 }
 
 type orderService struct {
-	orderRepo   repository.OrderRepository
-	productRepo productRepo.ProductRepository
+	orderRepo           repository.OrderRepository
+	productRepo         productRepo.ProductRepository
+	availabilityService transporterService.AvailabilityService
+	settlementService   transporterService.SettlementService
+	ledgerService       farmerService.LedgerService
+	pricingService      productService.PricingService
+	mailer              emailService.Mailer
 }
 
-func NewOrderService(orderRepo repository.OrderRepository, productRepo productRepo.ProductRepository) OrderService {
+func NewOrderService(
+	orderRepo repository.OrderRepository,
+	productRepo productRepo.ProductRepository,
+	availabilityService transporterService.AvailabilityService,
+	settlementService transporterService.SettlementService,
+	ledgerService farmerService.LedgerService,
+	pricingService productService.PricingService,
+	mailer emailService.Mailer,
+) OrderService {
 	return &orderService{
-		orderRepo:   orderRepo,
-		productRepo: productRepo,
+		orderRepo:           orderRepo,
+		productRepo:         productRepo,
+		availabilityService: availabilityService,
+		settlementService:   settlementService,
+		ledgerService:       ledgerService,
+		pricingService:      pricingService,
+		mailer:              mailer,
 	}
 }
 
+// NegotiatedTerms are terms agreed before the order, from an accepted RFQ
+// quote or a harvest reservation. They replace the catalogue price of every
+// item and the estimated delivery date.
+type NegotiatedTerms struct {
+	QuoteID       *uuid.UUID
+	ReservationID *uuid.UUID
+	UnitPrice     float64
+	DeliveryDate  time.Time
+	DepositPaid   float64 // already paid towards the order
+}
+
 // internal/order/service/order_service.go
 func (s *orderService) CreateOrder(ctx context.Context, buyerID uuid.UUID, req *dto.CreateOrderRequest) (*model.Order, error) {
+	return s.createOrder(ctx, buyerID, req, nil)
+}
+
+// CreateNegotiatedOrder places an order at a price agreed with the farmer
+// rather than the catalogue price. Stock is reserved as for any order.
+func (s *orderService) CreateNegotiatedOrder(ctx context.Context, buyerID uuid.UUID, req *dto.CreateOrderRequest, terms NegotiatedTerms) (*model.Order, error) {
+	return s.createOrder(ctx, buyerID, req, &terms)
+}
+
+func (s *orderService) createOrder(ctx context.Context, buyerID uuid.UUID, req *dto.CreateOrderRequest, terms *NegotiatedTerms) (*model.Order, error) {
 	// Generate order number
 	orderNumber, err := utils.GenerateOrderNumber()
 	if err != nil {
@@ -69,6 +121,15 @@ func (s *orderService) CreateOrder(ctx context.Context, buyerID uuid.UUID, req *
 	var farmerID uuid.UUID
 	var vendorID uuid.UUID
 
+	// Stock is reserved item by item; give it all back if the order is
+	// not saved in the end
+	saved := false
+	defer func() {
+		if !saved {
+			s.releaseStock(ctx, orderItems)
+		}
+	}()
+
 	for _, itemReq := range req.Items {
 		// Get product details
 		product, err := s.productRepo.FindByID(ctx, itemReq.ProductID)
@@ -81,10 +142,6 @@ func (s *orderService) CreateOrder(ctx context.Context, buyerID uuid.UUID, req *
 		if product.Status != productModel.StatusActive {
 			return nil, fmt.Errorf("product is not available for purchase: %s", product.Name)
 		}
-		if product.AvailableStock < itemReq.Quantity {
-			return nil, fmt.Errorf("insufficient stock for product %s. Available: %.2f, Requested: %.2f",
-				product.Name, product.AvailableStock, itemReq.Quantity)
-		}
 
 		// For first item, set farmer and vendor IDs
 		if farmerID == uuid.Nil {
@@ -98,31 +155,69 @@ func (s *orderService) CreateOrder(ctx context.Context, buyerID uuid.UUID, req *
 			return nil, errors.New("all products in order must be from the same farmer")
 		}
 
-		// Calculate item total
-		itemTotal := product.PricePerUnit * itemReq.Quantity
-
 		orderItem := model.OrderItem{
 			ID:           uuid.New(),
 			ProductID:    product.ID,
 			ProductName:  product.Name,
 			ProductImage: getFirstImage(product.Images),
-			UnitPrice:    product.PricePerUnit,
 			Quantity:     itemReq.Quantity,
 			Unit:         product.Unit,
-			TotalPrice:   itemTotal,
 			QualityGrade: string(product.QualityGrade),
 			Organic:      product.Organic,
 			HarvestDate:  product.HarvestDate,
 		}
+		available := product.AvailableStock
 
-		orderItems = append(orderItems, orderItem)
-		subTotal += itemTotal
+		variants, err := s.productRepo.FindVariantsByProductIDs(ctx, []uuid.UUID{product.ID}, true)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get variants: %w", err)
+		}
+		var variant *productModel.ProductVariant
+		if itemReq.VariantID != nil || len(variants) > 0 {
+			variant, err = selectVariant(product, variants, itemReq)
+			if err != nil {
+				return nil, err
+			}
+
+			orderItem.VariantID = &variant.ID
+			orderItem.VariantName = variant.Name
+			orderItem.PackSize = variant.PackSize
+			orderItem.PackUnit = product.Unit
+			orderItem.Unit = variant.Unit
+			available = variant.AvailableStock
+		}
 
-		// Update product stock (in real scenario, this should be in transaction)
-		newStock := product.AvailableStock - itemReq.Quantity
-		if err := s.productRepo.UpdateStock(ctx, product.ID, newStock); err != nil {
-			return nil, fmt.Errorf("failed to update stock for product %s: %w", product.Name, err)
+		// Price the item for this buyer and quantity
+		quote, err := s.pricingService.Price(ctx, buyerID, product, variant, itemReq.Quantity)
+		if err != nil {
+			return nil, fmt.Errorf("failed to price product %s: %w", product.Name, err)
+		}
+		orderItem.ListPrice = quote.ListPrice
+		orderItem.UnitPrice = quote.UnitPrice
+		if quote.Tier != nil {
+			orderItem.PriceTierID = &quote.Tier.ID
+			orderItem.PriceTierName = quote.Tier.Name
 		}
+		if terms != nil {
+			orderItem.UnitPrice = terms.UnitPrice
+			orderItem.PriceTierID = nil
+			orderItem.PriceTierName = ""
+		}
+
+		reserved, err := s.productRepo.ReserveStock(ctx, product.ID, orderItem.VariantID, itemReq.Quantity)
+		if err != nil {
+			return nil, fmt.Errorf("failed to reserve stock for product %s: %w", product.Name, err)
+		}
+		if !reserved {
+			return nil, fmt.Errorf("%w for product %s. Available: %.2f, Requested: %.2f",
+				ErrInsufficientStock, product.Name, available, itemReq.Quantity)
+		}
+
+		// Calculate item total
+		orderItem.TotalPrice = orderItem.UnitPrice * itemReq.Quantity
+
+		orderItems = append(orderItems, orderItem)
+		subTotal += orderItem.TotalPrice
 	}
 
 	// Calculate totals
@@ -133,6 +228,14 @@ func (s *orderService) CreateOrder(ctx context.Context, buyerID uuid.UUID, req *
 
 	// Calculate estimated delivery (3-7 days from now)
 	estimatedDelivery := s.calculateEstimatedDelivery()
+	var quoteID, reservationID *uuid.UUID
+	var depositPaid float64
+	if terms != nil {
+		quoteID, reservationID, depositPaid = terms.QuoteID, terms.ReservationID, terms.DepositPaid
+		if !terms.DeliveryDate.IsZero() {
+			estimatedDelivery = terms.DeliveryDate
+		}
+	}
 
 	// Create order
 	order := &model.Order{
@@ -158,7 +261,13 @@ func (s *orderService) CreateOrder(ctx context.Context, buyerID uuid.UUID, req *
 		ShippingZipCode: req.ShippingZipCode,
 		ShippingNotes:   req.ShippingNotes,
 
+		ShippingLatitude:  req.ShippingLatitude,
+		ShippingLongitude: req.ShippingLongitude,
+
 		EstimatedDelivery: estimatedDelivery,
+		QuoteID:           quoteID,
+		ReservationID:     reservationID,
+		DepositPaid:       depositPaid,
 		OrderItems:        orderItems,
 		CreatedAt:         time.Now(),
 		UpdatedAt:         time.Now(),
@@ -168,6 +277,7 @@ func (s *orderService) CreateOrder(ctx context.Context, buyerID uuid.UUID, req *
 	if err := s.orderRepo.Create(ctx, order); err != nil {
 		return nil, fmt.Errorf("failed to create order: %w", err)
 	}
+	saved = true
 
 	// Add initial tracking event
 	tracking := &model.OrderTracking{
@@ -183,9 +293,53 @@ func (s *orderService) CreateOrder(ctx context.Context, buyerID uuid.UUID, req *
 		fmt.Printf("Failed to add tracking event: %v\n", err)
 	}
 
+	if err := s.mailer.SendOrderConfirmation(ctx, buyerID, toOrderEmail(order)); err != nil {
+		log.Printf("failed to send confirmation of order %s: %v", order.OrderNumber, err)
+	}
+
 	return order, nil
 }
 
+// selectVariant checks the requested packaging option of a product. Packs are
+// sold whole and within the variant's order limits.
+func selectVariant(product *productModel.Product, variants []*productModel.ProductVariant, itemReq dto.OrderItemRequest) (*productModel.ProductVariant, error) {
+	if itemReq.VariantID == nil {
+		return nil, fmt.Errorf("%w: choose a packaging option for product %s", ErrInvalidVariant, product.Name)
+	}
+
+	var variant *productModel.ProductVariant
+	for _, v := range variants {
+		if v.ID == *itemReq.VariantID {
+			variant = v
+			break
+		}
+	}
+	if variant == nil {
+		return nil, fmt.Errorf("%w: packaging option not available for product %s", ErrInvalidVariant, product.Name)
+	}
+
+	if itemReq.Quantity != math.Trunc(itemReq.Quantity) {
+		return nil, fmt.Errorf("%w: %s of %s must be ordered in whole packs", ErrInvalidVariant, variant.Name, product.Name)
+	}
+	if itemReq.Quantity < variant.MinOrder {
+		return nil, fmt.Errorf("%w: minimum order for %s of %s is %g packs", ErrInvalidVariant, variant.Name, product.Name, variant.MinOrder)
+	}
+	if variant.MaxOrder > 0 && itemReq.Quantity > variant.MaxOrder {
+		return nil, fmt.Errorf("%w: maximum order for %s of %s is %g packs", ErrInvalidVariant, variant.Name, product.Name, variant.MaxOrder)
+	}
+	return variant, nil
+}
+
+// releaseStock gives back the stock reserved for order items. Failures are
+// logged so that one bad item does not keep the rest reserved.
+func (s *orderService) releaseStock(ctx context.Context, items []model.OrderItem) {
+	for _, item := range items {
+		if err := s.productRepo.ReleaseStock(ctx, item.ProductID, item.VariantID, item.Quantity); err != nil {
+			log.Printf("failed to release stock for product %s: %v", item.ProductID, err)
+		}
+	}
+}
+
 // Helper function to get vendor for farmer
 func (s *orderService) getVendorForFarmer(ctx context.Context, farmerID uuid.UUID) uuid.UUID {
 	// In real implementation, this would query farmer-vendor relationship table
@@ -322,6 +476,34 @@ func (s *orderService) UpdateOrderStatus(ctx context.Context, orderID uuid.UUID,
 		return err
 	}
 
+	// Credit the transporter; the weekly statement run picks up anything missed here
+	if req.Status == model.OrderStatusDelivered && order.TransporterID != uuid.Nil {
+		if err := s.settlementService.AccrueDelivery(ctx, &transporterDto.AccrueEarningRequest{
+			OrderID:        order.ID,
+			OrderNumber:    order.OrderNumber,
+			TransporterID:  order.TransporterID,
+			ShippingCharge: order.ShippingCost,
+			DeliveredAt:    time.Now(),
+		}); err != nil {
+			log.Printf("failed to accrue transporter earning for order %s: %v", order.OrderNumber, err)
+		}
+	}
+
+	// Let the buyer know their order is on its way
+	switch req.Status {
+	case model.OrderStatusShipped, model.OrderStatusInTransit, model.OrderStatusDelivered:
+		if err := s.mailer.SendShipmentUpdate(ctx, order.BuyerID, &emailService.ShipmentEmail{
+			OrderNumber:       order.OrderNumber,
+			Status:            string(req.Status),
+			TrackingNumber:    order.TrackingNumber,
+			TrackingURL:       order.TrackingURL,
+			EstimatedDelivery: order.EstimatedDelivery,
+			Notes:             req.Notes,
+		}); err != nil {
+			log.Printf("failed to send shipment update for order %s: %v", order.OrderNumber, err)
+		}
+	}
+
 	// Add tracking event
 	tracking := &model.OrderTracking{
 		ID:          uuid.New(),
@@ -354,15 +536,73 @@ func (s *orderService) AssignTransporter(ctx context.Context, orderID uuid.UUID,
 		return errors.New("invalid estimated delivery format")
 	}
 
+	pickupTime := time.Now()
+	if req.PickupTime != "" {
+		pickupTime, err = time.Parse(time.RFC3339, req.PickupTime)
+		if err != nil {
+			return errors.New("invalid pickup time format")
+		}
+	}
+
+	// Reserve capacity on the vehicle's calendar. This comes before freeing
+	// what a previous vehicle had reserved, so a failed reassignment leaves
+	// the order with its old booking.
+	var booking *transporterDto.BookingResponse
+	if req.VehicleID != uuid.Nil {
+		load := transporterModel.TransportCapacity{
+			Weight: req.LoadWeight,
+			Volume: req.LoadVolume,
+		}
+		if load.Weight == 0 {
+			load.Weight = order.EstimatedWeight()
+		}
+
+		booking, err = s.availabilityService.BookVehicle(ctx, &transporterDto.BookVehicleRequest{
+			VehicleID:     req.VehicleID,
+			TransporterID: req.TransporterID,
+			OrderID:       orderID,
+			StartTime:     pickupTime,
+			EndTime:       estimatedDelivery,
+			Load:          load,
+		})
+		if err != nil {
+			switch err {
+			case transporterService.ErrVehicleNotFound, transporterService.ErrUnauthorizedAccess:
+				return ErrInvalidVehicle
+			case transporterService.ErrVehicleUnavailable:
+				return ErrVehicleUnavailable
+			case transporterService.ErrInvalidSlotWindow:
+				return errors.New("estimated delivery must be after pickup time")
+			default:
+				return err
+			}
+		}
+	}
+
 	// Update order with transporter info
 	order.TransporterID = req.TransporterID
 	order.EstimatedDelivery = estimatedDelivery
 	order.UpdatedAt = time.Now()
 
 	if err := s.orderRepo.Update(ctx, order); err != nil {
+		if booking != nil {
+			if err := s.availabilityService.CancelBooking(ctx, booking.ID); err != nil {
+				log.Printf("failed to release vehicle booking %s: %v", booking.ID, err)
+			}
+		}
 		return err
 	}
 
+	// The order is now on the new vehicle, so the previous one's bookings
+	// can go
+	var keep []uuid.UUID
+	if booking != nil {
+		keep = append(keep, booking.ID)
+	}
+	if err := s.availabilityService.ReleaseOrderBookings(ctx, orderID, keep...); err != nil {
+		log.Printf("failed to release previous vehicle bookings for order %s: %v", order.OrderNumber, err)
+	}
+
 	// Add tracking event
 	tracking := &model.OrderTracking{
 		ID:          uuid.New(),
@@ -407,11 +647,28 @@ func (s *orderService) ProcessPayment(ctx context.Context, orderID uuid.UUID, bu
 		return err
 	}
 
+	// Post the payment to the farmer ledger. If that fails the
+	// order-ledger-retry job posts it later.
+	if err := s.ledgerService.RecordPayment(ctx, toLedgerPayment(order)); err != nil {
+		log.Printf("failed to record ledger payment for order %s: %v", order.OrderNumber, err)
+	}
+
 	// Update order status to confirmed
 	if err := s.orderRepo.UpdateStatus(ctx, orderID, model.OrderStatusConfirmed); err != nil {
 		return err
 	}
 
+	if err := s.mailer.SendInvoice(ctx, buyerID, &emailService.InvoiceEmail{
+		OrderEmail:    *toOrderEmail(order),
+		InvoiceNumber: invoiceNumber(order),
+		PaidAt:        time.Now(),
+		PaymentMethod: string(req.PaymentMethod),
+		PaymentID:     paymentID,
+		DepositPaid:   order.DepositPaid,
+	}); err != nil {
+		log.Printf("failed to send invoice for order %s: %v", order.OrderNumber, err)
+	}
+
 	// Add tracking event
 	tracking := &model.OrderTracking{
 		ID:          uuid.New(),
@@ -451,8 +708,22 @@ func (s *orderService) CancelOrder(ctx context.Context, orderID uuid.UUID, userI
 	// Update payment status to refunded if paid
 	if order.PaymentStatus == model.PaymentStatusPaid {
 		s.orderRepo.UpdatePaymentStatus(ctx, orderID, model.PaymentStatusRefunded, "")
+
+		// Reverse the farmer ledger postings for the payment, or leave it to
+		// the order-ledger-retry job if that fails
+		if err := s.ledgerService.RecordRefund(ctx, orderID); err != nil {
+			log.Printf("failed to record ledger refund for order %s: %v", order.OrderNumber, err)
+		}
 	}
 
+	// Free any vehicle capacity reserved for this order
+	if err := s.availabilityService.ReleaseOrderBookings(ctx, orderID); err != nil {
+		log.Printf("failed to release vehicle bookings for order %s: %v", order.OrderNumber, err)
+	}
+
+	// Return the reserved stock
+	s.releaseStock(ctx, order.OrderItems)
+
 	// Add tracking event
 	now := time.Now()
 	tracking := &model.OrderTracking{
@@ -662,16 +933,23 @@ func (s *orderService) toOrderResponse(order *model.Order) *dto.OrderResponse {
 	orderItems := make([]dto.OrderItemResponse, len(order.OrderItems))
 	for i, item := range order.OrderItems {
 		orderItems[i] = dto.OrderItemResponse{
-			ID:           item.ID,
-			ProductID:    item.ProductID,
-			ProductName:  item.ProductName,
-			ProductImage: item.ProductImage,
-			UnitPrice:    item.UnitPrice,
-			Quantity:     item.Quantity,
-			Unit:         item.Unit,
-			TotalPrice:   item.TotalPrice,
-			QualityGrade: item.QualityGrade,
-			Organic:      item.Organic,
+			ID:            item.ID,
+			ProductID:     item.ProductID,
+			ProductName:   item.ProductName,
+			ProductImage:  item.ProductImage,
+			VariantID:     item.VariantID,
+			VariantName:   item.VariantName,
+			PackSize:      item.PackSize,
+			PackUnit:      item.PackUnit,
+			ListPrice:     item.ListPrice,
+			UnitPrice:     item.UnitPrice,
+			PriceTierID:   item.PriceTierID,
+			PriceTierName: item.PriceTierName,
+			Quantity:      item.Quantity,
+			Unit:          item.Unit,
+			TotalPrice:    item.TotalPrice,
+			QualityGrade:  item.QualityGrade,
+			Organic:       item.Organic,
 		}
 	}
 
@@ -699,18 +977,88 @@ func (s *orderService) toOrderResponse(order *model.Order) *dto.OrderResponse {
 		ShippingState:   order.ShippingState,
 		ShippingZipCode: order.ShippingZipCode,
 
+		ShippingLatitude:  order.ShippingLatitude,
+		ShippingLongitude: order.ShippingLongitude,
+
 		EstimatedDelivery: order.EstimatedDelivery,
 		ActualDelivery:    order.ActualDelivery,
 
 		TrackingNumber: order.TrackingNumber,
 		TrackingURL:    order.TrackingURL,
 
+		QuoteID:       order.QuoteID,
+		ReservationID: order.ReservationID,
+		DepositPaid:   order.DepositPaid,
+
 		OrderItems: orderItems,
 
 		CreatedAt: order.CreatedAt,
 	}
 }
 
+// toLedgerPayment describes a paid order for the farmer ledger.
+func toLedgerPayment(order *model.Order) *farmerDto.RecordPaymentRequest {
+	items := make([]farmerDto.LedgerOrderItem, len(order.OrderItems))
+	for i, item := range order.OrderItems {
+		items[i] = farmerDto.LedgerOrderItem{
+			ProductID:  item.ProductID,
+			TotalPrice: item.TotalPrice,
+		}
+	}
+
+	return &farmerDto.RecordPaymentRequest{
+		OrderID:        order.ID,
+		OrderNumber:    order.OrderNumber,
+		FarmerID:       order.FarmerID,
+		TotalAmount:    order.TotalAmount,
+		SubTotal:       order.SubTotal,
+		DiscountAmount: order.DiscountAmount,
+		Items:          items,
+	}
+}
+
+// toOrderEmail is what the buyer is shown of an order in emails.
+func toOrderEmail(order *model.Order) *emailService.OrderEmail {
+	items := make([]emailService.OrderEmailItem, len(order.OrderItems))
+	for i, item := range order.OrderItems {
+		name := item.ProductName
+		if item.VariantName != "" {
+			name += " (" + item.VariantName + ")"
+		}
+		items[i] = emailService.OrderEmailItem{
+			Name:      name,
+			Quantity:  item.Quantity,
+			Unit:      item.Unit,
+			UnitPrice: item.UnitPrice,
+			Total:     item.TotalPrice,
+		}
+	}
+
+	address := []string{order.ShippingAddress, order.ShippingCity, order.ShippingState}
+	if order.ShippingZipCode != "" {
+		address = append(address, order.ShippingZipCode)
+	}
+
+	return &emailService.OrderEmail{
+		OrderNumber:       order.OrderNumber,
+		PlacedAt:          order.CreatedAt,
+		Items:             items,
+		SubTotal:          order.SubTotal,
+		Discount:          order.DiscountAmount,
+		Tax:               order.TaxAmount,
+		Shipping:          order.ShippingCost,
+		Total:             order.TotalAmount,
+		ShippingAddress:   strings.Join(address, ", "),
+		EstimatedDelivery: order.EstimatedDelivery,
+	}
+}
+
+// invoiceNumber derives an order's invoice number from its order number;
+// an order is paid, and invoiced, once.
+func invoiceNumber(order *model.Order) string {
+	return "INV-" + strings.TrimPrefix(order.OrderNumber, "ORD-")
+}
+
 func getFirstImage(images productModel.JSONSlice) string {
 	if len(images) > 0 {
 		return images[0]
@@ -8,6 +8,7 @@ import (
 )
 
 type CreateProductRequest struct {
+	SKU         string                `json:"sku" validate:"omitempty,max=64"`
 	Name        string                `json:"name" validate:"required,min=2,max=100"`
 	Category    model.ProductCategory `json:"category" validate:"required,oneof=fruits vegetables grains dairy poultry livestock spices herbs"`
 	Subcategory string                `json:"subcategory" validate:"max=50"`
@@ -53,28 +54,115 @@ type UpdateProductRequest struct {
 	Status      model.ProductStatus `json:"status" validate:"oneof=draft active inactive sold_out expired"`
 }
 
+// ProductFilterRequest filters the public catalogue. Facet filters are
+// multi-select: repeat the parameter or separate values with commas, and a
+// product matches if it has any of the selected values.
 type ProductFilterRequest struct {
-	Category     model.ProductCategory `query:"category"`
-	FarmerID     uuid.UUID             `query:"farmer_id"`
-	MinPrice     float64               `query:"min_price" validate:"min=0"`
-	MaxPrice     float64               `query:"max_price" validate:"min=0"`
-	Organic      bool                  `query:"organic"`
-	Certified    bool                  `query:"certified"`
-	QualityGrade model.QualityGrade    `query:"quality_grade"`
-	City         string                `query:"city"`
-	State        string                `query:"state"`
-	MinRating    float64               `query:"min_rating" validate:"min=0,max=5"`
-	Page         int                   `query:"page" validate:"min=1"`
-	PageSize     int                   `query:"page_size" validate:"min=1,max=100"`
+	Categories    []model.ProductCategory `form:"category" validate:"omitempty,dive,oneof=fruits vegetables grains dairy poultry livestock spices herbs"`
+	Subcategories []string                `form:"subcategory" validate:"omitempty,dive,max=50"`
+	QualityGrades []model.QualityGrade    `form:"quality_grade" validate:"omitempty,dive,oneof=premium standard economy"`
+	States        []string                `form:"state" validate:"omitempty,dive,max=100"`
+	Cities        []string                `form:"city" validate:"omitempty,dive,max=100"`
+	PriceRanges   []string                `form:"price_range" validate:"omitempty,dive,oneof=under_50 50_100 100_250 250_500 500_1000 over_1000"`
+	HarvestRanges []string                `form:"harvest_range" validate:"omitempty,dive,oneof=0_7_days 8_30_days 31_90_days over_90_days"`
+
+	FarmerID  uuid.UUID `form:"-"`
+	MinPrice  float64   `form:"min_price" validate:"min=0"`
+	MaxPrice  float64   `form:"max_price" validate:"min=0"`
+	Organic   bool      `form:"organic"`
+	Certified bool      `form:"certified"`
+	MinRating float64   `form:"min_rating" validate:"min=0,max=5"`
+
+	// Search location; radius_km and sort=distance need both coordinates
+	Latitude  *float64 `form:"lat" validate:"omitempty,latitude"`
+	Longitude *float64 `form:"lng" validate:"omitempty,longitude"`
+	RadiusKm  float64  `form:"radius_km" validate:"min=0,max=1000"`
+	Sort      string   `form:"sort" validate:"omitempty,oneof=newest price_asc price_desc rating distance"`
+
+	Page     int `form:"page" validate:"omitempty,min=1"`
+	PageSize int `form:"page_size" validate:"omitempty,min=1,max=100"`
+
+	// Include facet counts in the response
+	Facets bool `form:"facets"`
+}
+
+type CreateVariantRequest struct {
+	Name           string  `json:"name" validate:"required,min=1,max=100"`
+	SKU            string  `json:"sku" validate:"omitempty,max=64"`
+	Barcode        string  `json:"barcode" validate:"omitempty,numeric,min=8,max=14"`
+	Unit           string  `json:"unit" validate:"required,min=1,max=20"`
+	PackSize       float64 `json:"pack_size" validate:"required,gt=0"`
+	Price          float64 `json:"price" validate:"required,min=0.01"`
+	AvailableStock float64 `json:"available_stock" validate:"min=0"`
+	MinOrder       float64 `json:"min_order" validate:"min=0"`
+	MaxOrder       float64 `json:"max_order" validate:"min=0"`
+}
+
+// UpdateVariantRequest changes only the fields that are set.
+type UpdateVariantRequest struct {
+	Name           *string  `json:"name" validate:"omitempty,min=1,max=100"`
+	SKU            *string  `json:"sku" validate:"omitempty,max=64"`
+	Barcode        *string  `json:"barcode" validate:"omitempty,numeric,min=8,max=14"`
+	Unit           *string  `json:"unit" validate:"omitempty,min=1,max=20"`
+	PackSize       *float64 `json:"pack_size" validate:"omitempty,gt=0"`
+	Price          *float64 `json:"price" validate:"omitempty,min=0.01"`
+	AvailableStock *float64 `json:"available_stock" validate:"omitempty,min=0"`
+	MinOrder       *float64 `json:"min_order" validate:"omitempty,min=0"`
+	MaxOrder       *float64 `json:"max_order" validate:"omitempty,min=0"`
+	IsActive       *bool    `json:"is_active"`
+}
+
+type CreatePriceTierRequest struct {
+	Name        string     `json:"name" validate:"required,min=1,max=100"`
+	VariantID   *uuid.UUID `json:"variant_id"`
+	MinQuantity float64    `json:"min_quantity" validate:"min=0"`
+	Price       float64    `json:"price" validate:"required,min=0.01"`
+
+	// Optional price list restrictions
+	BuyerType     string     `json:"buyer_type" validate:"omitempty,oneof=retailer wholesaler exporter processor restaurant supermarket"`
+	BusinessScale string     `json:"business_scale" validate:"omitempty,oneof=small medium large enterprise"`
+	BuyerID       *uuid.UUID `json:"buyer_id"` // buyer's user ID
+
+	ValidFrom  *time.Time `json:"valid_from"`
+	ValidUntil *time.Time `json:"valid_until"`
+}
+
+// UpdatePriceTierRequest changes only the fields that are set. An empty
+// buyer_type or business_scale removes that restriction.
+type UpdatePriceTierRequest struct {
+	Name          *string    `json:"name" validate:"omitempty,min=1,max=100"`
+	MinQuantity   *float64   `json:"min_quantity" validate:"omitempty,min=0"`
+	Price         *float64   `json:"price" validate:"omitempty,min=0.01"`
+	BuyerType     *string    `json:"buyer_type" validate:"omitempty,oneof='' retailer wholesaler exporter processor restaurant supermarket"`
+	BusinessScale *string    `json:"business_scale" validate:"omitempty,oneof='' small medium large enterprise"`
+	ValidFrom     *time.Time `json:"valid_from"`
+	ValidUntil    *time.Time `json:"valid_until"`
+	IsActive      *bool      `json:"is_active"`
+}
+
+type PriceQuoteRequest struct {
+	VariantID *uuid.UUID `json:"variant_id"`
+	Quantity  float64    `json:"quantity" validate:"required,gt=0"`
 }
 
 type AddReviewRequest struct {
-	Rating        int      `json:"rating" validate:"required,min=1,max=5"`
-	Title         string   `json:"title"`
-	Comment       string   `json:"comment"`
-	Images        []string `json:"images"`
-	QualityRating int      `json:"quality_rating" validate:"min=1,max=5"`
-	ValueRating   int      `json:"value_rating" validate:"min=1,max=5"`
+	// Delivered order the review is for; defaults to the most recent one
+	OrderID       *uuid.UUID `json:"order_id"`
+	Rating        int        `json:"rating" validate:"required,min=1,max=5"`
+	Title         string     `json:"title" validate:"max=100"`
+	Comment       string     `json:"comment" validate:"max=2000"`
+	Images        []string   `json:"images" validate:"omitempty,max=5,dive,max=500"`
+	QualityRating int        `json:"quality_rating" validate:"omitempty,min=1,max=5"`
+	ValueRating   int        `json:"value_rating" validate:"omitempty,min=1,max=5"`
+}
+
+type ReviewReplyRequest struct {
+	Reply string `json:"reply" validate:"required,min=2,max=1000"`
+}
+
+type ModerateReviewRequest struct {
+	Status model.ReviewStatus `json:"status" validate:"required,oneof=published hidden"`
+	Reason string             `json:"reason" validate:"max=255"`
 }
 
 // Response DTOs
@@ -84,6 +172,7 @@ type ProductResponse struct {
 	FarmerName string    `json:"farmer_name"`
 	FarmName   string    `json:"farm_name"`
 
+	SKU         string                `json:"sku"`
 	Name        string                `json:"name"`
 	Category    model.ProductCategory `json:"category"`
 	Subcategory string                `json:"subcategory"`
@@ -113,9 +202,54 @@ type ProductResponse struct {
 	FarmLocation string  `json:"farm_location"`
 	Distance     float64 `json:"distance,omitempty"` // from search location
 
+	Variants []*VariantResponse `json:"variants,omitempty"`
+
 	CreatedAt time.Time `json:"created_at"`
 }
 
+type VariantResponse struct {
+	ID             uuid.UUID `json:"id"`
+	ProductID      uuid.UUID `json:"product_id"`
+	Name           string    `json:"name"`
+	SKU            string    `json:"sku"`
+	Barcode        string    `json:"barcode"`
+	Unit           string    `json:"unit"`
+	PackSize       float64   `json:"pack_size"`
+	Price          float64   `json:"price"`
+	PricePerUnit   float64   `json:"price_per_unit"` // price per unit of the product, for comparing packs
+	AvailableStock float64   `json:"available_stock"`
+	MinOrder       float64   `json:"min_order"`
+	MaxOrder       float64   `json:"max_order"`
+	IsActive       bool      `json:"is_active"`
+}
+
+type PriceTierResponse struct {
+	ID            uuid.UUID  `json:"id"`
+	ProductID     uuid.UUID  `json:"product_id"`
+	VariantID     *uuid.UUID `json:"variant_id,omitempty"`
+	Name          string     `json:"name"`
+	MinQuantity   float64    `json:"min_quantity"`
+	Price         float64    `json:"price"`
+	BuyerType     string     `json:"buyer_type,omitempty"`
+	BusinessScale string     `json:"business_scale,omitempty"`
+	BuyerID       *uuid.UUID `json:"buyer_id,omitempty"`
+	ValidFrom     *time.Time `json:"valid_from,omitempty"`
+	ValidUntil    *time.Time `json:"valid_until,omitempty"`
+	IsActive      bool       `json:"is_active"`
+}
+
+// PriceQuoteResponse is the price a buyer would pay for a quantity.
+type PriceQuoteResponse struct {
+	ProductID  uuid.UUID  `json:"product_id"`
+	VariantID  *uuid.UUID `json:"variant_id,omitempty"`
+	Quantity   float64    `json:"quantity"`
+	ListPrice  float64    `json:"list_price"`
+	UnitPrice  float64    `json:"unit_price"`
+	TotalPrice float64    `json:"total_price"`
+	TierID     *uuid.UUID `json:"tier_id,omitempty"`
+	TierName   string     `json:"tier_name,omitempty"`
+}
+
 type ProductReviewResponse struct {
 	ID        uuid.UUID `json:"id"`
 	ProductID uuid.UUID `json:"product_id"`
@@ -131,12 +265,32 @@ type ProductReviewResponse struct {
 	QualityRating int `json:"quality_rating"`
 	ValueRating   int `json:"value_rating"`
 
-	IsVerified bool `json:"is_verified"`
-	Helpful    int  `json:"helpful"`
+	IsVerified bool               `json:"is_verified"`
+	Helpful    int                `json:"helpful"`
+	Status     model.ReviewStatus `json:"status"`
+
+	FarmerReply string     `json:"farmer_reply,omitempty"`
+	RepliedAt   *time.Time `json:"replied_at,omitempty"`
 
 	CreatedAt time.Time `json:"created_at"`
 }
 
+type RatingSummaryResponse struct {
+	Average float64 `json:"average"`
+	Count   int64   `json:"count"`
+	// Number of published reviews per star rating, keyed "1" to "5"
+	Distribution map[string]int64 `json:"distribution"`
+}
+
+type ReviewListResponse struct {
+	Reviews []*ProductReviewResponse `json:"reviews"`
+	Summary *RatingSummaryResponse   `json:"summary,omitempty"`
+	Total   int64                    `json:"total"`
+	Page    int                      `json:"page"`
+	Pages   int                      `json:"pages"`
+	HasMore bool                     `json:"has_more"`
+}
+
 type UpdateStockRequest struct {
 	Quantity float64 `json:"quantity" validate:"required,min=0"`
 }
@@ -145,10 +299,67 @@ type UpdateStatusRequest struct {
 	Status string `json:"status" validate:"required,oneof=draft active inactive sold_out expired"`
 }
 
+// ProductSearchResult is a product matched by full-text search. Highlight
+// fields are HTML-escaped with matched terms wrapped in <mark> tags.
+type ProductSearchResult struct {
+	*ProductResponse
+	Score                float64 `json:"score"`
+	NameHighlight        string  `json:"name_highlight"`
+	DescriptionHighlight string  `json:"description_highlight"`
+}
+
+type ProductSearchResponse struct {
+	Query   string                 `json:"query"`
+	Results []*ProductSearchResult `json:"results"`
+	Total   int64                  `json:"total"`
+	Page    int                    `json:"page"`
+	Pages   int                    `json:"pages"`
+	HasMore bool                   `json:"has_more"`
+}
+
+type FacetValue struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
 type ProductListResponse struct {
 	Products []*ProductResponse `json:"products"`
 	Total    int64              `json:"total"`
 	Page     int                `json:"page"`
 	Pages    int                `json:"pages"`
 	HasMore  bool               `json:"has_more"`
+	// Counts per facet value, keyed by facet name. Each facet is counted
+	// with every filter applied except its own, so sibling values stay
+	// selectable.
+	Facets map[string][]*FacetValue `json:"facets,omitempty"`
+}
+
+type ProductImportAction string
+
+const (
+	ImportActionCreate ProductImportAction = "create"
+	ImportActionUpdate ProductImportAction = "update"
+	ImportActionError  ProductImportAction = "error"
+)
+
+// ProductImportRowResult reports what an import did, or would do in a dry
+// run, with one row of the file. Row is the spreadsheet row number, counting
+// the header as row 1.
+type ProductImportRowResult struct {
+	Row       int                 `json:"row"`
+	SKU       string              `json:"sku"`
+	Action    ProductImportAction `json:"action"`
+	ProductID *uuid.UUID          `json:"product_id,omitempty"`
+	Errors    []string            `json:"errors,omitempty"`
+}
+
+type ProductImportResponse struct {
+	DryRun  bool `json:"dry_run"`
+	Applied bool `json:"applied"` // false when any row failed or in a dry run
+	Total   int  `json:"total"`
+	Created int  `json:"created"`
+	Updated int  `json:"updated"`
+	Failed  int  `json:"failed"`
+
+	Rows []*ProductImportRowResult `json:"rows"`
 }
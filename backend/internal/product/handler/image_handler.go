@@ -1,12 +1,16 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
+	"mime/multipart"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
 
+	"agro_konnect/internal/auth/middleware"
+	mediaService "agro_konnect/internal/media/service"
 	"agro_konnect/internal/product/utils"
 
 	"github.com/gin-gonic/gin"
@@ -14,108 +18,52 @@ import (
 )
 
 type ImageHandler struct {
-	uploadDir string
+	mediaService mediaService.MediaService
+	legacyDir    string // images uploaded before media storage
 }
 
-func NewImageHandler(uploadDir string) *ImageHandler {
-	// Create upload directory if it doesn't exist
-	if err := os.MkdirAll(uploadDir, 0755); err != nil {
-		panic(fmt.Sprintf("Failed to create upload directory: %v", err))
-	}
-
+func NewImageHandler(mediaService mediaService.MediaService, legacyDir string) *ImageHandler {
 	return &ImageHandler{
-		uploadDir: uploadDir,
+		mediaService: mediaService,
+		legacyDir:    legacyDir,
 	}
 }
 
 // UploadProductImage handles product image upload
 // @Summary Upload product image
-// @Description Upload an image for a product
+// @Description Upload an image for a product. Metadata such as GPS location is stripped. Returns the URL to put in the product's images; add ?size=thumb, medium or large to fetch it resized
 // @Tags products
 // @Accept multipart/form-data
 // @Produce json
 // @Security BearerAuth
-// @Param file formData file true "Image file"
+// @Param file formData file true "Image file (JPEG, PNG or WebP, max 5MB)"
 // @Success 200 {object} utils.SuccessResponse{data=string} "Image uploaded successfully"
 // @Failure 400 {object} utils.ErrorResponse "Invalid file"
 // @Failure 500 {object} utils.ErrorResponse "Failed to upload image"
 // @Router /products/images/upload [post]
 func (h *ImageHandler) UploadProductImage(c *gin.Context) {
-	// Get the file from the form data
-	file, err := c.FormFile("file")
-	if err != nil {
-		utils.RespondWithError(c, http.StatusBadRequest, "Failed to get file from form data")
-		return
-	}
-
-	// Validate file size (max 5MB)
-	if file.Size > 5<<20 {
-		utils.RespondWithError(c, http.StatusBadRequest, "File size too large. Maximum size is 5MB")
-		return
-	}
-
-	// Validate file type
-	allowedTypes := map[string]bool{
-		"image/jpeg": true,
-		"image/jpg":  true,
-		"image/png":  true,
-		"image/webp": true,
-	}
-
-	fileHeader, err := file.Open()
-	if err != nil {
-		utils.RespondWithError(c, http.StatusBadRequest, "Failed to open file")
-		return
-	}
-	defer fileHeader.Close()
-
-	// Read first 512 bytes to detect content type
-	buffer := make([]byte, 512)
-	_, err = fileHeader.Read(buffer)
-	if err != nil {
-		utils.RespondWithError(c, http.StatusBadRequest, "Failed to read file")
-		return
-	}
-
-	contentType := http.DetectContentType(buffer)
-	if !allowedTypes[contentType] {
-		utils.RespondWithError(c, http.StatusBadRequest, "Invalid file type. Only JPEG, PNG, and WebP are allowed")
-		return
-	}
-
-	// Generate unique filename
-	fileExt := filepath.Ext(file.Filename)
-	if fileExt == "" {
-		// Determine extension from content type
-		switch contentType {
-		case "image/jpeg", "image/jpg":
-			fileExt = ".jpg"
-		case "image/png":
-			fileExt = ".png"
-		case "image/webp":
-			fileExt = ".webp"
-		default:
-			fileExt = ".jpg"
-		}
-	}
-
-	filename := fmt.Sprintf("%s%s", uuid.New().String(), strings.ToLower(fileExt))
-	filePath := filepath.Join(h.uploadDir, filename)
-
-	// Save the file
-	if err := c.SaveUploadedFile(file, filePath); err != nil {
-		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to save file")
-		return
-	}
+	h.uploadOne(c, mediaService.ProductImagePolicy)
+}
 
-	// Return the file path or URL
-	imageURL := fmt.Sprintf("/api/v1/products/images/%s", filename)
-	utils.RespondWithSuccess(c, http.StatusOK, "Image uploaded successfully", imageURL)
+// UploadReviewImage handles review photo upload
+// @Summary Upload review image
+// @Description Upload a photo to attach to a product review. Returns the URL to put in the review's images
+// @Tags reviews
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param file formData file true "Image file (JPEG, PNG or WebP, max 5MB)"
+// @Success 200 {object} utils.SuccessResponse{data=string} "Image uploaded successfully"
+// @Failure 400 {object} utils.ErrorResponse "Invalid file"
+// @Failure 500 {object} utils.ErrorResponse "Failed to upload image"
+// @Router /products/reviews/images/upload [post]
+func (h *ImageHandler) UploadReviewImage(c *gin.Context) {
+	h.uploadOne(c, mediaService.ReviewImagePolicy)
 }
 
-// ServeProductImage serves uploaded product images
+// ServeProductImage serves product images uploaded before media storage
 // @Summary Serve product image
-// @Description Serve uploaded product images
+// @Description Serve a product image uploaded before media storage. Newer images are served from /media
 // @Tags products
 // @Produce image/*
 // @Param filename path string true "Image filename"
@@ -124,77 +72,58 @@ func (h *ImageHandler) UploadProductImage(c *gin.Context) {
 // @Router /products/images/{filename} [get]
 func (h *ImageHandler) ServeProductImage(c *gin.Context) {
 	filename := c.Param("filename")
-	if filename == "" {
-		utils.RespondWithError(c, http.StatusBadRequest, "Filename is required")
-		return
-	}
 
 	// Security check: prevent directory traversal
-	if strings.Contains(filename, "..") || strings.Contains(filename, "/") {
+	if filename == "" || strings.Contains(filename, "..") || strings.Contains(filename, "/") {
 		utils.RespondWithError(c, http.StatusBadRequest, "Invalid filename")
 		return
 	}
 
-	filePath := filepath.Join(h.uploadDir, filename)
-
-	// Check if file exists
+	filePath := filepath.Join(h.legacyDir, filename)
 	if _, err := os.Stat(filePath); os.IsNotExist(err) {
 		utils.RespondWithError(c, http.StatusNotFound, "Image not found")
 		return
 	}
 
-	// Set appropriate content type based on file extension
-	ext := strings.ToLower(filepath.Ext(filename))
-	switch ext {
-	case ".jpg", ".jpeg":
-		c.Header("Content-Type", "image/jpeg")
-	case ".png":
-		c.Header("Content-Type", "image/png")
-	case ".webp":
-		c.Header("Content-Type", "image/webp")
-	default:
-		c.Header("Content-Type", "application/octet-stream")
-	}
-
 	c.File(filePath)
 }
 
 // DeleteProductImage deletes a product image
 // @Summary Delete product image
-// @Description Delete an uploaded product image
+// @Description Delete an image the user uploaded, by the ID at the end of its URL
 // @Tags products
 // @Produce json
 // @Security BearerAuth
-// @Param filename path string true "Image filename"
+// @Param id path string true "Image ID"
 // @Success 200 {object} utils.SuccessResponse "Image deleted successfully"
-// @Failure 400 {object} utils.ErrorResponse "Invalid filename"
+// @Failure 400 {object} utils.ErrorResponse "Invalid image ID"
+// @Failure 403 {object} utils.ErrorResponse "Forbidden"
 // @Failure 404 {object} utils.ErrorResponse "Image not found"
 // @Failure 500 {object} utils.ErrorResponse "Failed to delete image"
-// @Router /products/images/{filename} [delete]
+// @Router /products/images/{id} [delete]
 func (h *ImageHandler) DeleteProductImage(c *gin.Context) {
-	filename := c.Param("filename")
-	if filename == "" {
-		utils.RespondWithError(c, http.StatusBadRequest, "Filename is required")
-		return
-	}
-
-	// Security check: prevent directory traversal
-	if strings.Contains(filename, "..") || strings.Contains(filename, "/") {
-		utils.RespondWithError(c, http.StatusBadRequest, "Invalid filename")
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
 		return
 	}
+	userRole := fmt.Sprintf("%v", c.MustGet(middleware.UserRoleContextKey))
 
-	filePath := filepath.Join(h.uploadDir, filename)
-
-	// Check if file exists
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		utils.RespondWithError(c, http.StatusNotFound, "Image not found")
+	imageID, err := uuid.Parse(c.Param("filename"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid image ID")
 		return
 	}
 
-	// Delete the file
-	if err := os.Remove(filePath); err != nil {
-		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to delete image")
+	if err := h.mediaService.Delete(c.Request.Context(), imageID, userID, userRole); err != nil {
+		switch {
+		case errors.Is(err, mediaService.ErrAttachmentNotFound):
+			utils.RespondWithError(c, http.StatusNotFound, "Image not found")
+		case errors.Is(err, mediaService.ErrUnauthorizedAccess):
+			utils.RespondWithError(c, http.StatusForbidden, err.Error())
+		default:
+			utils.RespondWithError(c, http.StatusInternalServerError, "Failed to delete image")
+		}
 		return
 	}
 
@@ -203,17 +132,23 @@ func (h *ImageHandler) DeleteProductImage(c *gin.Context) {
 
 // UploadMultipleProductImages handles multiple product image uploads
 // @Summary Upload multiple product images
-// @Description Upload multiple images for a product
+// @Description Upload up to 5 images for a product. Returns their URLs in order
 // @Tags products
 // @Accept multipart/form-data
 // @Produce json
 // @Security BearerAuth
-// @Param files formData file true "Image files" collectionFormat(multi)
+// @Param files formData file true "Image files (JPEG, PNG or WebP, max 5MB each)" collectionFormat(multi)
 // @Success 200 {object} utils.SuccessResponse{data=[]string} "Images uploaded successfully"
 // @Failure 400 {object} utils.ErrorResponse "Invalid files"
 // @Failure 500 {object} utils.ErrorResponse "Failed to upload images"
 // @Router /products/images/upload-multiple [post]
 func (h *ImageHandler) UploadMultipleProductImages(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
 	form, err := c.MultipartForm()
 	if err != nil {
 		utils.RespondWithError(c, http.StatusBadRequest, "Failed to parse form data")
@@ -233,32 +168,58 @@ func (h *ImageHandler) UploadMultipleProductImages(c *gin.Context) {
 	}
 
 	var uploadedURLs []string
-
 	for _, file := range files {
-		// Validate file size (max 5MB)
-		if file.Size > 5<<20 {
-			utils.RespondWithError(c, http.StatusBadRequest, fmt.Sprintf("File %s is too large. Maximum size is 5MB", file.Filename))
+		url, err := h.upload(c, mediaService.ProductImagePolicy, userID, file)
+		if err != nil {
+			respondWithUploadError(c, fmt.Errorf("%s: %w", file.Filename, err), "Failed to upload images")
 			return
 		}
+		uploadedURLs = append(uploadedURLs, url)
+	}
 
-		// Generate unique filename
-		fileExt := filepath.Ext(file.Filename)
-		if fileExt == "" {
-			fileExt = ".jpg"
-		}
+	utils.RespondWithSuccess(c, http.StatusOK, "Images uploaded successfully", uploadedURLs)
+}
 
-		filename := fmt.Sprintf("%s%s", uuid.New().String(), strings.ToLower(fileExt))
-		filePath := filepath.Join(h.uploadDir, filename)
+func (h *ImageHandler) uploadOne(c *gin.Context, policy mediaService.Policy) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
 
-		// Save the file
-		if err := c.SaveUploadedFile(file, filePath); err != nil {
-			utils.RespondWithError(c, http.StatusInternalServerError, "Failed to save files")
-			return
-		}
+	file, err := c.FormFile("file")
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Failed to get file from form data")
+		return
+	}
 
-		imageURL := fmt.Sprintf("/api/v1/products/images/%s", filename)
-		uploadedURLs = append(uploadedURLs, imageURL)
+	url, err := h.upload(c, policy, userID, file)
+	if err != nil {
+		respondWithUploadError(c, err, "Failed to upload image")
+		return
 	}
 
-	utils.RespondWithSuccess(c, http.StatusOK, "Images uploaded successfully", uploadedURLs)
+	utils.RespondWithSuccess(c, http.StatusOK, "Image uploaded successfully", url)
+}
+
+func (h *ImageHandler) upload(c *gin.Context, policy mediaService.Policy, userID uuid.UUID, file *multipart.FileHeader) (string, error) {
+	attachment, err := h.mediaService.Upload(c.Request.Context(), mediaService.UploadRequest{
+		Policy:     policy,
+		UploadedBy: userID,
+		File:       file,
+	})
+	if err != nil {
+		return "", err
+	}
+	return attachment.FileURL, nil
+}
+
+func respondWithUploadError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, mediaService.ErrFileTooLarge), errors.Is(err, mediaService.ErrUnsupportedFileType),
+		errors.Is(err, mediaService.ErrInvalidImage), errors.Is(err, mediaService.ErrImageTooLarge):
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+	default:
+		utils.RespondWithError(c, http.StatusInternalServerError, fallback)
+	}
 }
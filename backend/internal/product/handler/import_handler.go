@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"agro_konnect/internal/product/service"
+	"agro_konnect/internal/product/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxImportFileSize caps uploaded import files at 5MB
+const maxImportFileSize = 5 << 20
+
+var exportContentTypes = map[string]string{
+	service.FormatCSV:  "text/csv",
+	service.FormatXLSX: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+// ImportProducts creates or updates the farmer's products from a spreadsheet
+// @Summary Import products
+// @Description Create or update products from a CSV or XLSX file whose header row uses the product field names (sku, name, category, price_per_unit, ...). Rows are matched to existing products by sku; empty cells keep the current value. Nothing is saved unless every row is valid, and dry_run=true only reports what would happen.
+// @Tags products
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param file formData file true "CSV or XLSX file"
+// @Param format query string false "File format, detected from the file name when omitted" Enums(csv, xlsx)
+// @Param dry_run query bool false "Validate without saving"
+// @Success 200 {object} utils.SuccessResponse{data=dto.ProductImportResponse} "Import processed"
+// @Failure 400 {object} utils.ErrorResponse "Invalid file"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 404 {object} utils.ErrorResponse "Farmer profile not found"
+// @Failure 422 {object} utils.ErrorResponse{details=dto.ProductImportResponse} "Some rows are invalid; nothing was saved"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /products/import [post]
+func (h *ProductHandler) ImportProducts(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Failed to get file from form data")
+		return
+	}
+	if fileHeader.Size > maxImportFileSize {
+		utils.RespondWithError(c, http.StatusBadRequest, "File size too large. Maximum size is 5MB")
+		return
+	}
+
+	format := strings.ToLower(c.Query("format"))
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(fileHeader.Filename)), ".")
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Failed to open file")
+		return
+	}
+	defer file.Close()
+
+	dryRun := c.Query("dry_run") == "true"
+	result, err := h.productService.ImportProducts(c.Request.Context(), userID, file, fileHeader.Size, format, dryRun)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrUnsupportedFormat),
+			errors.Is(err, service.ErrInvalidImportFile),
+			errors.Is(err, service.ErrImportEmpty),
+			errors.Is(err, service.ErrImportTooLarge):
+			utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		case errors.Is(err, service.ErrFarmerNotFound):
+			utils.RespondWithError(c, http.StatusNotFound, "Farmer profile not found. Please create a farmer profile first")
+		default:
+			utils.RespondWithError(c, http.StatusInternalServerError, "Failed to import products")
+		}
+		return
+	}
+
+	if result.Failed > 0 && !dryRun {
+		c.JSON(http.StatusUnprocessableEntity, utils.ErrorResponse{
+			Success: false,
+			Message: fmt.Sprintf("%d of %d rows are invalid; no products were saved", result.Failed, result.Total),
+			Error:   http.StatusText(http.StatusUnprocessableEntity),
+			Details: result,
+		})
+		return
+	}
+
+	message := "Products imported successfully"
+	if dryRun {
+		message = "Import checked; no products were saved"
+	}
+	utils.RespondWithSuccess(c, http.StatusOK, message, result)
+}
+
+// ExportMyProducts downloads the farmer's catalogue
+// @Summary Export my products
+// @Description Download the authenticated farmer's products as CSV or XLSX in the import layout
+// @Tags products
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Security BearerAuth
+// @Param format query string false "File format" Enums(csv, xlsx) default(csv)
+// @Success 200 {file} file "Product export"
+// @Failure 400 {object} utils.ErrorResponse "Unsupported format"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 404 {object} utils.ErrorResponse "Farmer profile not found"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /products/export [get]
+func (h *ProductHandler) ExportMyProducts(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	format := strings.ToLower(c.DefaultQuery("format", service.FormatCSV))
+	data, err := h.productService.ExportProducts(c.Request.Context(), userID, format)
+	if err != nil {
+		switch err {
+		case service.ErrUnsupportedFormat:
+			utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		case service.ErrFarmerNotFound:
+			utils.RespondWithError(c, http.StatusNotFound, "Farmer profile not found. Please create a farmer profile first")
+		default:
+			utils.RespondWithError(c, http.StatusInternalServerError, "Failed to export products")
+		}
+		return
+	}
+
+	sendExport(c, "my-products", format, data)
+}
+
+// ExportAllProducts downloads the full catalogue (admin only)
+// @Summary Export all products
+// @Description Download every product with its farm as CSV or XLSX (admin only)
+// @Tags admin-products
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Security BearerAuth
+// @Param format query string false "File format" Enums(csv, xlsx) default(csv)
+// @Success 200 {file} file "Product export"
+// @Failure 400 {object} utils.ErrorResponse "Unsupported format"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 403 {object} utils.ErrorResponse "Forbidden"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /admin/products/export [get]
+func (h *ProductHandler) ExportAllProducts(c *gin.Context) {
+	format := strings.ToLower(c.DefaultQuery("format", service.FormatCSV))
+	data, err := h.productService.ExportAllProducts(c.Request.Context(), format)
+	if err != nil {
+		switch err {
+		case service.ErrUnsupportedFormat:
+			utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		default:
+			utils.RespondWithError(c, http.StatusInternalServerError, "Failed to export products")
+		}
+		return
+	}
+
+	sendExport(c, "products", format, data)
+}
+
+func sendExport(c *gin.Context, name, format string, data []byte) {
+	filename := fmt.Sprintf("%s-%s.%s", name, time.Now().Format("20060102"), format)
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+	c.Data(http.StatusOK, exportContentTypes[format], data)
+}
@@ -0,0 +1,272 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	dto "agro_konnect/internal/product/dto"
+	"agro_konnect/internal/product/service"
+	"agro_konnect/internal/product/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type PricingHandler struct {
+	pricingService service.PricingService
+}
+
+func NewPricingHandler(pricingService service.PricingService) *PricingHandler {
+	return &PricingHandler{
+		pricingService: pricingService,
+	}
+}
+
+// GetPriceTiers lists a product's price tiers
+// @Summary Get product price tiers
+// @Description Get every price tier and price list entry of the farmer's product, including inactive ones
+// @Tags pricing
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Success 200 {object} utils.SuccessResponse{data=[]dto.PriceTierResponse} "Price tiers retrieved successfully"
+// @Failure 400 {object} utils.ErrorResponse "Invalid product ID"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 403 {object} utils.ErrorResponse "Forbidden"
+// @Failure 404 {object} utils.ErrorResponse "Product not found"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /products/{id}/price-tiers [get]
+func (h *PricingHandler) GetPriceTiers(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	productID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid product ID")
+		return
+	}
+
+	tiers, err := h.pricingService.GetPriceTiers(c.Request.Context(), productID, userID)
+	if err != nil {
+		respondWithPricingError(c, err, "Failed to retrieve price tiers")
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, "Price tiers retrieved successfully", tiers)
+}
+
+// CreatePriceTier adds a price tier to a product
+// @Summary Create price tier
+// @Description Add a quantity breakpoint price to the farmer's product or one of its variants, optionally only for a buyer type, business scale or single buyer
+// @Tags pricing
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Param request body dto.CreatePriceTierRequest true "Price tier data"
+// @Success 201 {object} utils.SuccessResponse{data=dto.PriceTierResponse} "Price tier created successfully"
+// @Failure 400 {object} utils.ErrorResponse "Invalid input data"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 403 {object} utils.ErrorResponse "Forbidden"
+// @Failure 404 {object} utils.ErrorResponse "Product, variant or buyer not found"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /products/{id}/price-tiers [post]
+func (h *PricingHandler) CreatePriceTier(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	productID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid product ID")
+		return
+	}
+
+	var req dto.CreatePriceTierRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid input data: "+err.Error())
+		return
+	}
+
+	if err := utils.ValidateStruct(req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	tier, err := h.pricingService.CreatePriceTier(c.Request.Context(), productID, userID, &req)
+	if err != nil {
+		respondWithPricingError(c, err, "Failed to create price tier")
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusCreated, "Price tier created successfully", tier)
+}
+
+// UpdatePriceTier changes a price tier
+// @Summary Update price tier
+// @Description Update the fields that are set on one of the farmer's price tiers
+// @Tags pricing
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Param tierId path string true "Price tier ID"
+// @Param request body dto.UpdatePriceTierRequest true "Price tier changes"
+// @Success 200 {object} utils.SuccessResponse{data=dto.PriceTierResponse} "Price tier updated successfully"
+// @Failure 400 {object} utils.ErrorResponse "Invalid input data"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 403 {object} utils.ErrorResponse "Forbidden"
+// @Failure 404 {object} utils.ErrorResponse "Price tier not found"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /products/{id}/price-tiers/{tierId} [put]
+func (h *PricingHandler) UpdatePriceTier(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	productID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid product ID")
+		return
+	}
+	tierID, err := uuid.Parse(c.Param("tierId"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid price tier ID")
+		return
+	}
+
+	var req dto.UpdatePriceTierRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid input data: "+err.Error())
+		return
+	}
+
+	if err := utils.ValidateStruct(req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	tier, err := h.pricingService.UpdatePriceTier(c.Request.Context(), productID, tierID, userID, &req)
+	if err != nil {
+		respondWithPricingError(c, err, "Failed to update price tier")
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, "Price tier updated successfully", tier)
+}
+
+// DeletePriceTier removes a price tier
+// @Summary Delete price tier
+// @Description Delete one of the farmer's price tiers. Orders keep the price they were placed at
+// @Tags pricing
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Param tierId path string true "Price tier ID"
+// @Success 200 {object} utils.SuccessResponse "Price tier deleted successfully"
+// @Failure 400 {object} utils.ErrorResponse "Invalid ID"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 403 {object} utils.ErrorResponse "Forbidden"
+// @Failure 404 {object} utils.ErrorResponse "Price tier not found"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /products/{id}/price-tiers/{tierId} [delete]
+func (h *PricingHandler) DeletePriceTier(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	productID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid product ID")
+		return
+	}
+	tierID, err := uuid.Parse(c.Param("tierId"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid price tier ID")
+		return
+	}
+
+	if err := h.pricingService.DeletePriceTier(c.Request.Context(), productID, tierID, userID); err != nil {
+		respondWithPricingError(c, err, "Failed to delete price tier")
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, "Price tier deleted successfully", nil)
+}
+
+// GetQuote prices a quantity for the current buyer
+// @Summary Get price quote
+// @Description Get the unit and total price the authenticated buyer would pay for a quantity, with the price tier that applies
+// @Tags pricing
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Param quantity query number true "Quantity, in packs when variant_id is set"
+// @Param variant_id query string false "Variant ID"
+// @Success 200 {object} utils.SuccessResponse{data=dto.PriceQuoteResponse} "Price quote retrieved successfully"
+// @Failure 400 {object} utils.ErrorResponse "Invalid input data"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 404 {object} utils.ErrorResponse "Product or variant not found"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /products/{id}/quote [get]
+func (h *PricingHandler) GetQuote(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	productID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid product ID")
+		return
+	}
+
+	var req dto.PriceQuoteRequest
+	req.Quantity, _ = strconv.ParseFloat(c.Query("quantity"), 64)
+	if variantParam := c.Query("variant_id"); variantParam != "" {
+		variantID, err := uuid.Parse(variantParam)
+		if err != nil {
+			utils.RespondWithError(c, http.StatusBadRequest, "Invalid variant ID")
+			return
+		}
+		req.VariantID = &variantID
+	}
+
+	if err := utils.ValidateStruct(req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	quote, err := h.pricingService.GetQuote(c.Request.Context(), productID, userID, &req)
+	if err != nil {
+		respondWithPricingError(c, err, "Failed to get price quote")
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, "Price quote retrieved successfully", quote)
+}
+
+func respondWithPricingError(c *gin.Context, err error, fallback string) {
+	switch err {
+	case service.ErrProductNotFound, service.ErrVariantNotFound, service.ErrPriceTierNotFound, service.ErrBuyerNotFound:
+		utils.RespondWithError(c, http.StatusNotFound, err.Error())
+	case service.ErrFarmerNotFound:
+		utils.RespondWithError(c, http.StatusNotFound, "Farmer profile not found")
+	case service.ErrUnauthorizedAccess:
+		utils.RespondWithError(c, http.StatusForbidden, err.Error())
+	case service.ErrInvalidPriceTier:
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+	default:
+		utils.RespondWithError(c, http.StatusInternalServerError, fallback)
+	}
+}
@@ -36,6 +36,7 @@ func NewProductHandler(productService service.ProductService) *ProductHandler {
 // @Failure 400 {object} utils.ErrorResponse "Invalid input data"
 // @Failure 401 {object} utils.ErrorResponse "Unauthorized"
 // @Failure 404 {object} utils.ErrorResponse "Farmer profile not found"
+// @Failure 409 {object} utils.ErrorResponse "SKU already in use"
 // @Failure 500 {object} utils.ErrorResponse "Internal server error"
 // @Router /products [post]
 func (h *ProductHandler) CreateProduct(c *gin.Context) {
@@ -64,6 +65,8 @@ func (h *ProductHandler) CreateProduct(c *gin.Context) {
 			utils.RespondWithError(c, http.StatusBadRequest, err.Error())
 		case service.ErrFarmerNotFound:
 			utils.RespondWithError(c, http.StatusNotFound, "Farmer profile not found. Please create a farmer profile first")
+		case service.ErrDuplicateSKU:
+			utils.RespondWithError(c, http.StatusConflict, err.Error())
 		default:
 			utils.RespondWithError(c, http.StatusInternalServerError, "Failed to create product")
 		}
@@ -242,19 +245,27 @@ func (h *ProductHandler) DeleteProduct(c *gin.Context) {
 
 // GetAllProducts gets all products with optional filtering and pagination
 // @Summary Get all products
-// @Description Get a list of products with filtering and pagination
+// @Description Get a list of products with filtering and pagination. Facet filters accept several values, repeated or comma-separated.
 // @Tags products
 // @Produce json
-// @Param category query string false "Category filter"
+// @Param category query []string false "Category filter" collectionFormat(multi)
+// @Param subcategory query []string false "Subcategory filter" collectionFormat(multi)
+// @Param quality_grade query []string false "Quality grade filter" collectionFormat(multi)
+// @Param state query []string false "Farm state filter" collectionFormat(multi)
+// @Param city query []string false "Farm city filter" collectionFormat(multi)
+// @Param price_range query []string false "Price bucket: under_50, 50_100, 100_250, 250_500, 500_1000, over_1000" collectionFormat(multi)
+// @Param harvest_range query []string false "Harvest age: 0_7_days, 8_30_days, 31_90_days, over_90_days" collectionFormat(multi)
 // @Param farmer_id query string false "Farmer ID filter"
 // @Param min_price query number false "Minimum price filter"
 // @Param max_price query number false "Maximum price filter"
 // @Param organic query boolean false "Organic filter"
 // @Param certified query boolean false "Certified filter"
-// @Param quality_grade query string false "Quality grade filter"
-// @Param city query string false "City filter"
-// @Param state query string false "State filter"
 // @Param min_rating query number false "Minimum rating filter"
+// @Param lat query number false "Search latitude"
+// @Param lng query number false "Search longitude"
+// @Param radius_km query number false "Only products within this many km of lat/lng"
+// @Param sort query string false "Sort: newest, price_asc, price_desc, rating, distance" default(newest)
+// @Param facets query boolean false "Include facet counts"
 // @Param page query integer false "Page number" default(1)
 // @Param page_size query integer false "Page size" default(10)
 // @Success 200 {object} utils.SuccessResponse{data=dto.ProductListResponse} "Products retrieved successfully"
@@ -280,23 +291,28 @@ func (h *ProductHandler) GetAllProducts(c *gin.Context) {
 		filters.FarmerID = farmerID
 	}
 
-	// Parse boolean parameters
-	if c.Query("organic") != "" {
-		organic, err := strconv.ParseBool(c.Query("organic"))
-		if err != nil {
-			utils.RespondWithError(c, http.StatusBadRequest, "Invalid organic parameter")
-			return
-		}
-		filters.Organic = organic
+	// Accept comma-separated values for multi-select filters
+	filters.Categories = splitMultiValue(filters.Categories)
+	filters.Subcategories = splitMultiValue(filters.Subcategories)
+	filters.QualityGrades = splitMultiValue(filters.QualityGrades)
+	filters.States = splitMultiValue(filters.States)
+	filters.Cities = splitMultiValue(filters.Cities)
+	filters.PriceRanges = splitMultiValue(filters.PriceRanges)
+	filters.HarvestRanges = splitMultiValue(filters.HarvestRanges)
+
+	if err := utils.ValidateStruct(filters); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		return
 	}
 
-	if c.Query("certified") != "" {
-		certified, err := strconv.ParseBool(c.Query("certified"))
-		if err != nil {
-			utils.RespondWithError(c, http.StatusBadRequest, "Invalid certified parameter")
-			return
-		}
-		filters.Certified = certified
+	hasLocation := filters.Latitude != nil && filters.Longitude != nil
+	if (filters.Latitude == nil) != (filters.Longitude == nil) {
+		utils.RespondWithError(c, http.StatusBadRequest, "lat and lng must be provided together")
+		return
+	}
+	if !hasLocation && (filters.RadiusKm > 0 || filters.Sort == "distance") {
+		utils.RespondWithError(c, http.StatusBadRequest, "lat and lng are required for radius_km and sort=distance")
+		return
 	}
 
 	response, err := h.productService.GetAllProducts(c.Request.Context(), filters)
@@ -322,6 +338,7 @@ func (h *ProductHandler) GetAllProducts(c *gin.Context) {
 // @Failure 401 {object} utils.ErrorResponse "Unauthorized"
 // @Failure 403 {object} utils.ErrorResponse "Forbidden"
 // @Failure 404 {object} utils.ErrorResponse "Product not found"
+// @Failure 409 {object} utils.ErrorResponse "Stock is managed on the product's variants"
 // @Failure 500 {object} utils.ErrorResponse "Internal server error"
 // @Router /products/{id}/stock [put]
 func (h *ProductHandler) UpdateStock(c *gin.Context) {
@@ -353,6 +370,8 @@ func (h *ProductHandler) UpdateStock(c *gin.Context) {
 			utils.RespondWithError(c, http.StatusForbidden, err.Error())
 		case service.ErrFarmerNotFound:
 			utils.RespondWithError(c, http.StatusNotFound, "Farmer profile not found")
+		case service.ErrProductHasVariants:
+			utils.RespondWithError(c, http.StatusConflict, err.Error())
 		default:
 			utils.RespondWithError(c, http.StatusInternalServerError, "Failed to update stock")
 		}
@@ -478,13 +497,13 @@ func (h *ProductHandler) GetProductsByCategory(c *gin.Context) {
 
 // SearchProducts searches products by query string
 // @Summary Search products
-// @Description Search products by name, description, or category
+// @Description Full-text search over name, variety, subcategory, farm name and description, ranked by relevance and tolerant of misspellings
 // @Tags products
 // @Produce json
 // @Param q query string true "Search query"
 // @Param page query integer false "Page number" default(1)
 // @Param size query integer false "Page size" default(10)
-// @Success 200 {object} utils.SuccessResponse{data=dto.ProductListResponse} "Products retrieved successfully"
+// @Success 200 {object} utils.SuccessResponse{data=dto.ProductSearchResponse} "Products retrieved successfully"
 // @Failure 400 {object} utils.ErrorResponse "Invalid query parameters"
 // @Failure 500 {object} utils.ErrorResponse "Internal server error"
 // @Router /products/search [get]
@@ -507,6 +526,20 @@ func (h *ProductHandler) SearchProducts(c *gin.Context) {
 	utils.RespondWithSuccess(c, http.StatusOK, "Products retrieved successfully", response)
 }
 
+// splitMultiValue splits comma-separated query values and drops blanks, so
+// ?category=fruits,grains and ?category=fruits&category=grains agree.
+func splitMultiValue[T ~string](values []T) []T {
+	var result []T
+	for _, value := range values {
+		for _, part := range strings.Split(string(value), ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				result = append(result, T(part))
+			}
+		}
+	}
+	return result
+}
+
 // GetUserIDFromContext extracts user ID from Gin context
 func GetUserIDFromContext(c *gin.Context) (uuid.UUID, error) {
 	userID, exists := c.Get("userID")
@@ -0,0 +1,345 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	dto "agro_konnect/internal/product/dto"
+	model "agro_konnect/internal/product/model"
+	"agro_konnect/internal/product/service"
+	"agro_konnect/internal/product/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ReviewHandler struct {
+	reviewService service.ReviewService
+}
+
+func NewReviewHandler(reviewService service.ReviewService) *ReviewHandler {
+	return &ReviewHandler{
+		reviewService: reviewService,
+	}
+}
+
+// AddReview adds a verified-purchase review
+// @Summary Review a product
+// @Description Review a product from one of the buyer's delivered orders
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Param request body dto.AddReviewRequest true "Review data"
+// @Success 201 {object} utils.SuccessResponse{data=dto.ProductReviewResponse} "Review added successfully"
+// @Failure 400 {object} utils.ErrorResponse "Invalid input data"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 403 {object} utils.ErrorResponse "No delivered order for this product"
+// @Failure 404 {object} utils.ErrorResponse "Product not found"
+// @Failure 409 {object} utils.ErrorResponse "Order already reviewed"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /products/{id}/reviews [post]
+func (h *ReviewHandler) AddReview(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	productID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid product ID")
+		return
+	}
+
+	var req dto.AddReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid input data: "+err.Error())
+		return
+	}
+
+	if err := utils.ValidateStruct(req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	review, err := h.reviewService.AddReview(c.Request.Context(), productID, userID, &req)
+	if err != nil {
+		switch err {
+		case service.ErrProductNotFound:
+			utils.RespondWithError(c, http.StatusNotFound, err.Error())
+		case service.ErrReviewNotEligible:
+			utils.RespondWithError(c, http.StatusForbidden, err.Error())
+		case service.ErrReviewAlreadyExists:
+			utils.RespondWithError(c, http.StatusConflict, err.Error())
+		default:
+			utils.RespondWithError(c, http.StatusInternalServerError, "Failed to add review")
+		}
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusCreated, "Review added successfully", review)
+}
+
+// GetProductReviews lists a product's published reviews
+// @Summary Get product reviews
+// @Description Get published reviews for a product with a rating summary
+// @Tags reviews
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param sort query string false "Sort order: recent, helpful, rating_high, rating_low" default(recent)
+// @Param page query integer false "Page number" default(1)
+// @Param size query integer false "Page size" default(10)
+// @Success 200 {object} utils.SuccessResponse{data=dto.ReviewListResponse} "Reviews retrieved successfully"
+// @Failure 400 {object} utils.ErrorResponse "Invalid product ID"
+// @Failure 404 {object} utils.ErrorResponse "Product not found"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /products/{id}/reviews [get]
+func (h *ReviewHandler) GetProductReviews(c *gin.Context) {
+	productID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid product ID")
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	size, _ := strconv.Atoi(c.DefaultQuery("size", "10"))
+
+	response, err := h.reviewService.GetProductReviews(c.Request.Context(), productID, c.Query("sort"), page, size)
+	if err != nil {
+		switch err {
+		case service.ErrProductNotFound:
+			utils.RespondWithError(c, http.StatusNotFound, err.Error())
+		default:
+			utils.RespondWithError(c, http.StatusInternalServerError, "Failed to retrieve reviews")
+		}
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, "Reviews retrieved successfully", response)
+}
+
+// MarkHelpful marks a review as helpful
+// @Summary Mark review helpful
+// @Description Vote a review as helpful; one vote per user
+// @Tags reviews
+// @Produce json
+// @Security BearerAuth
+// @Param reviewId path string true "Review ID"
+// @Success 200 {object} utils.SuccessResponse "Review marked as helpful"
+// @Failure 400 {object} utils.ErrorResponse "Invalid review ID"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 403 {object} utils.ErrorResponse "Cannot vote on own review"
+// @Failure 404 {object} utils.ErrorResponse "Review not found"
+// @Failure 409 {object} utils.ErrorResponse "Already voted"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /products/reviews/{reviewId}/helpful [post]
+func (h *ReviewHandler) MarkHelpful(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	reviewID, err := uuid.Parse(c.Param("reviewId"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid review ID")
+		return
+	}
+
+	if err := h.reviewService.MarkHelpful(c.Request.Context(), reviewID, userID); err != nil {
+		switch err {
+		case service.ErrReviewNotFound:
+			utils.RespondWithError(c, http.StatusNotFound, err.Error())
+		case service.ErrCannotVoteOwnReview:
+			utils.RespondWithError(c, http.StatusForbidden, err.Error())
+		case service.ErrHelpfulVoteExists:
+			utils.RespondWithError(c, http.StatusConflict, err.Error())
+		default:
+			utils.RespondWithError(c, http.StatusInternalServerError, "Failed to mark review as helpful")
+		}
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, "Review marked as helpful", nil)
+}
+
+// RemoveHelpful withdraws a helpful vote
+// @Summary Remove helpful vote
+// @Description Withdraw the current user's helpful vote on a review
+// @Tags reviews
+// @Produce json
+// @Security BearerAuth
+// @Param reviewId path string true "Review ID"
+// @Success 200 {object} utils.SuccessResponse "Helpful vote removed"
+// @Failure 400 {object} utils.ErrorResponse "Invalid review ID"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 404 {object} utils.ErrorResponse "Review or vote not found"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /products/reviews/{reviewId}/helpful [delete]
+func (h *ReviewHandler) RemoveHelpful(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	reviewID, err := uuid.Parse(c.Param("reviewId"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid review ID")
+		return
+	}
+
+	if err := h.reviewService.RemoveHelpful(c.Request.Context(), reviewID, userID); err != nil {
+		switch err {
+		case service.ErrReviewNotFound, service.ErrHelpfulVoteNotFound:
+			utils.RespondWithError(c, http.StatusNotFound, err.Error())
+		default:
+			utils.RespondWithError(c, http.StatusInternalServerError, "Failed to remove helpful vote")
+		}
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, "Helpful vote removed", nil)
+}
+
+// ReplyToReview sets the farmer's reply to a review
+// @Summary Reply to review
+// @Description Reply publicly to a review of one of the farmer's products
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param reviewId path string true "Review ID"
+// @Param request body dto.ReviewReplyRequest true "Reply"
+// @Success 200 {object} utils.SuccessResponse{data=dto.ProductReviewResponse} "Reply saved successfully"
+// @Failure 400 {object} utils.ErrorResponse "Invalid input data"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 403 {object} utils.ErrorResponse "Forbidden"
+// @Failure 404 {object} utils.ErrorResponse "Review not found"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /products/reviews/{reviewId}/reply [put]
+func (h *ReviewHandler) ReplyToReview(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	reviewID, err := uuid.Parse(c.Param("reviewId"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid review ID")
+		return
+	}
+
+	var req dto.ReviewReplyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid input data: "+err.Error())
+		return
+	}
+
+	if err := utils.ValidateStruct(req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	review, err := h.reviewService.ReplyToReview(c.Request.Context(), reviewID, userID, &req)
+	if err != nil {
+		switch err {
+		case service.ErrReviewNotFound, service.ErrProductNotFound:
+			utils.RespondWithError(c, http.StatusNotFound, err.Error())
+		case service.ErrFarmerNotFound:
+			utils.RespondWithError(c, http.StatusNotFound, "Farmer profile not found")
+		case service.ErrUnauthorizedAccess:
+			utils.RespondWithError(c, http.StatusForbidden, err.Error())
+		default:
+			utils.RespondWithError(c, http.StatusInternalServerError, "Failed to save reply")
+		}
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, "Reply saved successfully", review)
+}
+
+// GetReviewsForModeration lists reviews for admins
+// @Summary Get reviews for moderation
+// @Description Get reviews across all products, optionally filtered by status
+// @Tags admin-reviews
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "Review status: published, hidden"
+// @Param page query integer false "Page number" default(1)
+// @Param size query integer false "Page size" default(20)
+// @Success 200 {object} utils.SuccessResponse{data=dto.ReviewListResponse} "Reviews retrieved successfully"
+// @Failure 400 {object} utils.ErrorResponse "Invalid status"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 403 {object} utils.ErrorResponse "Forbidden"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /admin/products/reviews [get]
+func (h *ReviewHandler) GetReviewsForModeration(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	size, _ := strconv.Atoi(c.DefaultQuery("size", "20"))
+	status := model.ReviewStatus(c.Query("status"))
+
+	response, err := h.reviewService.GetReviewsForModeration(c.Request.Context(), status, page, size)
+	if err != nil {
+		switch err {
+		case service.ErrInvalidReviewStatus:
+			utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		default:
+			utils.RespondWithError(c, http.StatusInternalServerError, "Failed to retrieve reviews")
+		}
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, "Reviews retrieved successfully", response)
+}
+
+// ModerateReview publishes or hides a review
+// @Summary Moderate review
+// @Description Publish or hide a review; hidden reviews do not count towards ratings
+// @Tags admin-reviews
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param reviewId path string true "Review ID"
+// @Param request body dto.ModerateReviewRequest true "Moderation decision"
+// @Success 200 {object} utils.SuccessResponse{data=dto.ProductReviewResponse} "Review moderated successfully"
+// @Failure 400 {object} utils.ErrorResponse "Invalid input data"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 403 {object} utils.ErrorResponse "Forbidden"
+// @Failure 404 {object} utils.ErrorResponse "Review not found"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /admin/products/reviews/{reviewId}/moderation [put]
+func (h *ReviewHandler) ModerateReview(c *gin.Context) {
+	reviewID, err := uuid.Parse(c.Param("reviewId"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid review ID")
+		return
+	}
+
+	var req dto.ModerateReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid input data: "+err.Error())
+		return
+	}
+
+	if err := utils.ValidateStruct(req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	review, err := h.reviewService.ModerateReview(c.Request.Context(), reviewID, &req)
+	if err != nil {
+		switch err {
+		case service.ErrReviewNotFound:
+			utils.RespondWithError(c, http.StatusNotFound, err.Error())
+		default:
+			utils.RespondWithError(c, http.StatusInternalServerError, "Failed to moderate review")
+		}
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, "Review moderated successfully", review)
+}
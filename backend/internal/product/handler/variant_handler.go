@@ -0,0 +1,208 @@
+package handler
+
+import (
+	"net/http"
+
+	dto "agro_konnect/internal/product/dto"
+	"agro_konnect/internal/product/service"
+	"agro_konnect/internal/product/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// GetVariants lists a product's packaging options
+// @Summary Get product variants
+// @Description Get the active packaging options of a product, smallest pack first
+// @Tags products
+// @Produce json
+// @Param id path string true "Product ID"
+// @Success 200 {object} utils.SuccessResponse{data=[]dto.VariantResponse} "Variants retrieved successfully"
+// @Failure 400 {object} utils.ErrorResponse "Invalid product ID"
+// @Failure 404 {object} utils.ErrorResponse "Product not found"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /products/{id}/variants [get]
+func (h *ProductHandler) GetVariants(c *gin.Context) {
+	productID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid product ID")
+		return
+	}
+
+	variants, err := h.productService.GetVariants(c.Request.Context(), productID)
+	if err != nil {
+		switch err {
+		case service.ErrProductNotFound:
+			utils.RespondWithError(c, http.StatusNotFound, err.Error())
+		default:
+			utils.RespondWithError(c, http.StatusInternalServerError, "Failed to retrieve variants")
+		}
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, "Variants retrieved successfully", variants)
+}
+
+// CreateVariant adds a packaging option to a product
+// @Summary Create product variant
+// @Description Add a packaging option with its own unit, pack size, price, stock and barcode to the farmer's product
+// @Tags products
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Param request body dto.CreateVariantRequest true "Variant data"
+// @Success 201 {object} utils.SuccessResponse{data=dto.VariantResponse} "Variant created successfully"
+// @Failure 400 {object} utils.ErrorResponse "Invalid input data"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 403 {object} utils.ErrorResponse "Forbidden"
+// @Failure 404 {object} utils.ErrorResponse "Product not found"
+// @Failure 409 {object} utils.ErrorResponse "SKU already in use"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /products/{id}/variants [post]
+func (h *ProductHandler) CreateVariant(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	productID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid product ID")
+		return
+	}
+
+	var req dto.CreateVariantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid input data: "+err.Error())
+		return
+	}
+
+	if err := utils.ValidateStruct(req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	variant, err := h.productService.CreateVariant(c.Request.Context(), productID, userID, &req)
+	if err != nil {
+		respondWithVariantError(c, err, "Failed to create variant")
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusCreated, "Variant created successfully", variant)
+}
+
+// UpdateVariant changes a packaging option
+// @Summary Update product variant
+// @Description Update the fields that are set on one of the farmer's product variants
+// @Tags products
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Param variantId path string true "Variant ID"
+// @Param request body dto.UpdateVariantRequest true "Variant changes"
+// @Success 200 {object} utils.SuccessResponse{data=dto.VariantResponse} "Variant updated successfully"
+// @Failure 400 {object} utils.ErrorResponse "Invalid input data"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 403 {object} utils.ErrorResponse "Forbidden"
+// @Failure 404 {object} utils.ErrorResponse "Variant not found"
+// @Failure 409 {object} utils.ErrorResponse "SKU already in use"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /products/{id}/variants/{variantId} [put]
+func (h *ProductHandler) UpdateVariant(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	productID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid product ID")
+		return
+	}
+	variantID, err := uuid.Parse(c.Param("variantId"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid variant ID")
+		return
+	}
+
+	var req dto.UpdateVariantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid input data: "+err.Error())
+		return
+	}
+
+	if err := utils.ValidateStruct(req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	variant, err := h.productService.UpdateVariant(c.Request.Context(), productID, variantID, userID, &req)
+	if err != nil {
+		respondWithVariantError(c, err, "Failed to update variant")
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, "Variant updated successfully", variant)
+}
+
+// DeleteVariant removes a packaging option
+// @Summary Delete product variant
+// @Description Delete one of the farmer's product variants. Existing orders keep their copy of it
+// @Tags products
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Param variantId path string true "Variant ID"
+// @Success 200 {object} utils.SuccessResponse "Variant deleted successfully"
+// @Failure 400 {object} utils.ErrorResponse "Invalid ID"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 403 {object} utils.ErrorResponse "Forbidden"
+// @Failure 404 {object} utils.ErrorResponse "Variant not found"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /products/{id}/variants/{variantId} [delete]
+func (h *ProductHandler) DeleteVariant(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	productID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid product ID")
+		return
+	}
+	variantID, err := uuid.Parse(c.Param("variantId"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid variant ID")
+		return
+	}
+
+	if err := h.productService.DeleteVariant(c.Request.Context(), productID, variantID, userID); err != nil {
+		respondWithVariantError(c, err, "Failed to delete variant")
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, "Variant deleted successfully", nil)
+}
+
+func respondWithVariantError(c *gin.Context, err error, fallback string) {
+	switch err {
+	case service.ErrProductNotFound, service.ErrVariantNotFound:
+		utils.RespondWithError(c, http.StatusNotFound, err.Error())
+	case service.ErrFarmerNotFound:
+		utils.RespondWithError(c, http.StatusNotFound, "Farmer profile not found")
+	case service.ErrUnauthorizedAccess:
+		utils.RespondWithError(c, http.StatusForbidden, err.Error())
+	case service.ErrDuplicateVariantSKU:
+		utils.RespondWithError(c, http.StatusConflict, err.Error())
+	case service.ErrInvalidVariantOrder:
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+	default:
+		utils.RespondWithError(c, http.StatusInternalServerError, fallback)
+	}
+}
@@ -68,9 +68,10 @@ func (j JSONSlice) Value() (driver.Value, error) {
 
 type Product struct {
 	ID       uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
-	FarmerID uuid.UUID `gorm:"not null" json:"farmer_id"`
+	FarmerID uuid.UUID `gorm:"not null;uniqueIndex:idx_product_farmer_sku,where:sku <> ''" json:"farmer_id"`
 
 	// Basic Information
+	SKU         string          `gorm:"type:varchar(64);uniqueIndex:idx_product_farmer_sku,where:sku <> ''" json:"sku"` // farmer-defined, unique per farmer
 	Name        string          `gorm:"not null" json:"name"`
 	Category    ProductCategory `gorm:"type:varchar(50);not null" json:"category"`
 	Subcategory string          `json:"subcategory"`
@@ -118,11 +119,98 @@ type Product struct {
 	ExpiryDate time.Time `json:"expiry_date"`
 }
 
+// ProductVariant is one way a product is packed and sold, such as a 5kg
+// crate of apples. When a product has variants, buyers order variants and
+// the product's stock is the total of theirs.
+type ProductVariant struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	ProductID uuid.UUID `gorm:"not null;index;uniqueIndex:idx_variant_product_sku,where:sku <> ''" json:"product_id"`
+
+	Name    string `gorm:"not null" json:"name"` // e.g. "5kg crate"
+	SKU     string `gorm:"type:varchar(64);uniqueIndex:idx_variant_product_sku,where:sku <> ''" json:"sku"`
+	Barcode string `gorm:"type:varchar(32);index" json:"barcode"`
+
+	// Each pack holds PackSize of the product's unit, sold in Unit
+	Unit           string  `gorm:"not null" json:"unit"`
+	PackSize       float64 `gorm:"type:decimal(10,3);not null" json:"pack_size"`
+	Price          float64 `gorm:"type:decimal(10,2);not null" json:"price"`
+	AvailableStock float64 `gorm:"type:decimal(10,2);not null" json:"available_stock"` // in packs
+	MinOrder       float64 `gorm:"type:decimal(10,2);default:1" json:"min_order"`
+	MaxOrder       float64 `gorm:"type:decimal(10,2)" json:"max_order"`
+
+	IsActive bool `gorm:"default:true" json:"is_active"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// PriceTier is a price a product sells at from a quantity breakpoint, such
+// as a wholesale rate from 100kg. A tier can be limited to a kind of buyer
+// or to a single buyer, which makes it a price list. Tiers for a variant are
+// priced per pack; tiers without one apply to the product itself.
+type PriceTier struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
+	ProductID uuid.UUID  `gorm:"not null;index" json:"product_id"`
+	VariantID *uuid.UUID `gorm:"type:uuid;index" json:"variant_id"`
+
+	Name        string  `gorm:"not null" json:"name"` // e.g. "Wholesale 100kg+"
+	MinQuantity float64 `gorm:"type:decimal(10,2);not null" json:"min_quantity"`
+	Price       float64 `gorm:"type:decimal(10,2);not null" json:"price"`
+
+	// Who the tier is for; empty means every buyer
+	BuyerType     string     `gorm:"type:varchar(50)" json:"buyer_type"`
+	BusinessScale string     `gorm:"type:varchar(20)" json:"business_scale"`
+	BuyerID       *uuid.UUID `gorm:"type:uuid;index" json:"buyer_id"` // buyer's user ID
+
+	ValidFrom  *time.Time `json:"valid_from"`
+	ValidUntil *time.Time `json:"valid_until"`
+	IsActive   bool       `gorm:"default:true" json:"is_active"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// AppliesTo reports whether the tier is on offer to the buyer for the given
+// quantity of the product or variant at time t.
+func (t *PriceTier) AppliesTo(variantID *uuid.UUID, quantity float64, buyerID uuid.UUID, buyerType, businessScale string, at time.Time) bool {
+	if !t.IsActive || quantity < t.MinQuantity {
+		return false
+	}
+	if (t.VariantID == nil) != (variantID == nil) || (t.VariantID != nil && *t.VariantID != *variantID) {
+		return false
+	}
+	if t.BuyerID != nil && *t.BuyerID != buyerID {
+		return false
+	}
+	if t.BuyerType != "" && t.BuyerType != buyerType {
+		return false
+	}
+	if t.BusinessScale != "" && t.BusinessScale != businessScale {
+		return false
+	}
+	if t.ValidFrom != nil && at.Before(*t.ValidFrom) {
+		return false
+	}
+	if t.ValidUntil != nil && !at.Before(*t.ValidUntil) {
+		return false
+	}
+	return true
+}
+
+type ReviewStatus string
+
+const (
+	ReviewStatusPublished ReviewStatus = "published"
+	ReviewStatusHidden    ReviewStatus = "hidden"
+)
+
+// ProductReview is left by a buyer for a product in one of their delivered
+// orders; each order can review a product once.
 type ProductReview struct {
 	ID        uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
-	ProductID uuid.UUID `gorm:"not null" json:"product_id"`
-	BuyerID   uuid.UUID `gorm:"not null" json:"buyer_id"`
-	OrderID   uuid.UUID `gorm:"not null" json:"order_id"`
+	ProductID uuid.UUID `gorm:"not null;uniqueIndex:idx_review_product_order" json:"product_id"`
+	BuyerID   uuid.UUID `gorm:"not null;index" json:"buyer_id"`
+	OrderID   uuid.UUID `gorm:"not null;uniqueIndex:idx_review_product_order" json:"order_id"`
 
 	Rating  int       `gorm:"not null" json:"rating" validate:"min=1,max=5"`
 	Title   string    `json:"title"`
@@ -135,6 +223,23 @@ type ProductReview struct {
 	IsVerified bool `gorm:"default:false" json:"is_verified"` // from actual purchase
 	Helpful    int  `gorm:"default:0" json:"helpful"`
 
+	// Moderation; only published reviews count towards ratings
+	Status           ReviewStatus `gorm:"type:varchar(20);default:'published';index" json:"status"`
+	ModerationReason string       `json:"moderation_reason"`
+	ModeratedAt      *time.Time   `json:"moderated_at"`
+
+	// Farmer's public response
+	FarmerReply string     `json:"farmer_reply"`
+	RepliedAt   *time.Time `json:"replied_at"`
+
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
+
+// ReviewHelpfulVote records that a user found a review helpful. The
+// composite key allows one vote per user per review.
+type ReviewHelpfulVote struct {
+	ReviewID  uuid.UUID `gorm:"type:uuid;primaryKey" json:"review_id"`
+	UserID    uuid.UUID `gorm:"type:uuid;primaryKey" json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -14,6 +14,7 @@ import (
 type AdminProductRepository interface {
 	GetAllProducts(ctx context.Context, page, pageSize int, search, status, category string) ([]*model.Product, int64, error)
 	GetProductByID(ctx context.Context, id uuid.UUID) (*model.Product, error)
+	GetProductsByIDs(ctx context.Context, ids []uuid.UUID) ([]*model.Product, error)
 	UpdateProductStatus(ctx context.Context, productID uuid.UUID, status model.ProductStatus) error
 	BulkUpdateProductStatus(ctx context.Context, productIDs []uuid.UUID, status model.ProductStatus) error
 	DeleteProduct(ctx context.Context, productID uuid.UUID) error
@@ -95,6 +96,12 @@ func (r *adminProductRepository) GetProductByID(ctx context.Context, id uuid.UUI
 	return &product, nil
 }
 
+func (r *adminProductRepository) GetProductsByIDs(ctx context.Context, ids []uuid.UUID) ([]*model.Product, error) {
+	var products []*model.Product
+	err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&products).Error
+	return products, err
+}
+
 func (r *adminProductRepository) UpdateProductStatus(ctx context.Context, productID uuid.UUID, status model.ProductStatus) error {
 	return r.db.WithContext(ctx).Model(&model.Product{}).
 		Where("id = ?", productID).
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	model "agro_konnect/internal/product/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func (r *productRepository) CreatePriceTier(ctx context.Context, tier *model.PriceTier) error {
+	return r.db.WithContext(ctx).Create(tier).Error
+}
+
+func (r *productRepository) FindPriceTierByID(ctx context.Context, id uuid.UUID) (*model.PriceTier, error) {
+	var tier model.PriceTier
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&tier).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &tier, err
+}
+
+// FindPriceTiersByProductID returns a product's tiers, including those of its
+// variants, ordered by quantity breakpoint.
+func (r *productRepository) FindPriceTiersByProductID(ctx context.Context, productID uuid.UUID, activeOnly bool) ([]*model.PriceTier, error) {
+	var tiers []*model.PriceTier
+	query := r.db.WithContext(ctx).Where("product_id = ?", productID)
+	if activeOnly {
+		query = query.Where("is_active = ?", true)
+	}
+	err := query.Order("min_quantity ASC, price ASC").Find(&tiers).Error
+	return tiers, err
+}
+
+func (r *productRepository) UpdatePriceTier(ctx context.Context, tier *model.PriceTier) error {
+	return r.db.WithContext(ctx).Save(tier).Error
+}
+
+func (r *productRepository) DeletePriceTier(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Where("id = ?", id).Delete(&model.PriceTier{}).Error
+}
@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	dto "agro_konnect/internal/product/dto"
+	model "agro_konnect/internal/product/model"
+	"agro_konnect/pkg/geo"
+
+	"github.com/google/uuid"
+)
+
+// FacetCount is the number of catalogue products sharing one facet value.
+type FacetCount struct {
+	Facet string
+	Value string
+	Count int64
+}
+
+// priceRanges are the price_range facet buckets in ascending order, each
+// holding unit prices below its max. The last bucket is unbounded.
+var priceRanges = []struct {
+	key string
+	max float64
+}{
+	{"under_50", 50},
+	{"50_100", 100},
+	{"100_250", 250},
+	{"250_500", 500},
+	{"500_1000", 1000},
+	{"over_1000", 0},
+}
+
+var priceRangeExpr = buildPriceRangeExpr()
+
+const harvestRangeExpr = `CASE
+	WHEN products.harvest_date >= NOW() - INTERVAL '7 days' THEN '0_7_days'
+	WHEN products.harvest_date >= NOW() - INTERVAL '30 days' THEN '8_30_days'
+	WHEN products.harvest_date >= NOW() - INTERVAL '90 days' THEN '31_90_days'
+	ELSE 'over_90_days' END`
+
+// productFacets are the facets counted for the catalogue and the expression
+// each groups by. The facet query joins farmers for location.
+var productFacets = []struct {
+	name string
+	expr string
+}{
+	{"category", "products.category"},
+	{"subcategory", "products.subcategory"},
+	{"quality_grade", "products.quality_grade"},
+	{"organic", "products.organic"},
+	{"certified", "products.certified"},
+	{"state", "farmers.state"},
+	{"city", "farmers.city"},
+	{"price_range", priceRangeExpr},
+	{"harvest_range", harvestRangeExpr},
+}
+
+func buildPriceRangeExpr() string {
+	var b strings.Builder
+	b.WriteString("CASE")
+	for _, bucket := range priceRanges {
+		if bucket.max == 0 {
+			fmt.Fprintf(&b, " ELSE '%s'", bucket.key)
+			continue
+		}
+		fmt.Fprintf(&b, " WHEN products.price_per_unit < %g THEN '%s'", bucket.max, bucket.key)
+	}
+	b.WriteString(" END")
+	return b.String()
+}
+
+// A product without coordinates of its own is placed at its farm. Queries
+// using these must join farmers.
+const (
+	productLatitudeSQL  = "CASE WHEN products.latitude = 0 AND products.longitude = 0 THEN farmers.latitude ELSE products.latitude END"
+	productLongitudeSQL = "CASE WHEN products.latitude = 0 AND products.longitude = 0 THEN farmers.longitude ELSE products.longitude END"
+)
+
+func productDistanceSQL(origin geo.Point) string {
+	return geo.DistanceSQL(origin, productLatitudeSQL, productLongitudeSQL)
+}
+
+// filterOrigin returns the search location, or nil if the filter has none.
+func filterOrigin(filters dto.ProductFilterRequest) *geo.Point {
+	if filters.Latitude == nil || filters.Longitude == nil {
+		return nil
+	}
+	return &geo.Point{Lat: *filters.Latitude, Lng: *filters.Longitude}
+}
+
+// filterCondition is one WHERE predicate of a catalogue query. Conditions
+// that narrow a facet carry its name so that facet's own counts can leave
+// them out.
+type filterCondition struct {
+	facet string
+	sql   string
+	args  []interface{}
+}
+
+func productFilterConditions(filters dto.ProductFilterRequest) []filterCondition {
+	conditions := []filterCondition{
+		{sql: "products.status = ?", args: []interface{}{model.StatusActive}},
+	}
+	add := func(facet, sql string, args ...interface{}) {
+		conditions = append(conditions, filterCondition{facet: facet, sql: sql, args: args})
+	}
+
+	if filters.FarmerID != uuid.Nil {
+		add("", "products.farmer_id = ?", filters.FarmerID)
+	}
+	if filters.MinPrice > 0 {
+		add("", "products.price_per_unit >= ?", filters.MinPrice)
+	}
+	if filters.MaxPrice > 0 {
+		add("", "products.price_per_unit <= ?", filters.MaxPrice)
+	}
+	if filters.MinRating > 0 {
+		add("", "products.rating >= ?", filters.MinRating)
+	}
+	if origin := filterOrigin(filters); origin != nil && filters.RadiusKm > 0 {
+		add("", productDistanceSQL(*origin)+" <= ?", filters.RadiusKm)
+	}
+
+	if len(filters.Categories) > 0 {
+		add("category", "products.category IN ?", filters.Categories)
+	}
+	if len(filters.Subcategories) > 0 {
+		add("subcategory", "products.subcategory IN ?", filters.Subcategories)
+	}
+	if len(filters.QualityGrades) > 0 {
+		add("quality_grade", "products.quality_grade IN ?", filters.QualityGrades)
+	}
+	if filters.Organic {
+		add("organic", "products.organic = ?", true)
+	}
+	if filters.Certified {
+		add("certified", "products.certified = ?", true)
+	}
+	if len(filters.States) > 0 {
+		add("state", "products.farmer_id IN (SELECT id FROM farmers WHERE state IN ?)", filters.States)
+	}
+	if len(filters.Cities) > 0 {
+		add("city", "products.farmer_id IN (SELECT id FROM farmers WHERE city IN ?)", filters.Cities)
+	}
+	if len(filters.PriceRanges) > 0 {
+		add("price_range", priceRangeExpr+" IN ?", filters.PriceRanges)
+	}
+	if len(filters.HarvestRanges) > 0 {
+		add("harvest_range", harvestRangeExpr+" IN ?", filters.HarvestRanges)
+	}
+
+	return conditions
+}
+
+// FindFacetCounts counts active products per facet value in one query. Each
+// facet applies every filter except its own, so selecting "fruits" still
+// shows how many vegetables there are.
+func (r *productRepository) FindFacetCounts(ctx context.Context, filters dto.ProductFilterRequest) ([]FacetCount, error) {
+	conditions := productFilterConditions(filters)
+
+	parts := make([]string, 0, len(productFacets))
+	var args []interface{}
+	for _, facet := range productFacets {
+		var where []string
+		for _, condition := range conditions {
+			if condition.facet == facet.name {
+				continue
+			}
+			where = append(where, "("+condition.sql+")")
+			args = append(args, condition.args...)
+		}
+
+		parts = append(parts, fmt.Sprintf(`SELECT '%s' AS facet, COALESCE((%s)::text, '') AS value, COUNT(*) AS count
+			FROM products LEFT JOIN farmers ON farmers.id = products.farmer_id
+			WHERE %s
+			GROUP BY 2`, facet.name, facet.expr, strings.Join(where, " AND ")))
+	}
+
+	var counts []FacetCount
+	err := r.db.WithContext(ctx).
+		Raw(strings.Join(parts, " UNION ALL ")+" ORDER BY facet, count DESC, value", args...).
+		Scan(&counts).Error
+	return counts, err
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+
+	model "agro_konnect/internal/product/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ProductExportRow is a product with the farm it belongs to, for catalogue
+// exports.
+type ProductExportRow struct {
+	model.Product `gorm:"embedded"`
+	FarmName      string
+}
+
+func (r *productRepository) FindByFarmerSKUs(ctx context.Context, farmerID uuid.UUID, skus []string) ([]*model.Product, error) {
+	var products []*model.Product
+	if len(skus) == 0 {
+		return products, nil
+	}
+	err := r.db.WithContext(ctx).
+		Where("farmer_id = ? AND sku IN ?", farmerID, skus).
+		Find(&products).Error
+	return products, err
+}
+
+// ProductUpdate is an imported change to an existing product. Only Columns
+// are written, so stock reserved or edited since the product was read is
+// kept unless the import sets it.
+type ProductUpdate struct {
+	Product *model.Product
+	Columns []string
+}
+
+// SaveImport writes an import in one transaction, so a failure part way
+// leaves the catalogue as it was.
+func (r *productRepository) SaveImport(ctx context.Context, creates []*model.Product, updates []*ProductUpdate) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if len(creates) > 0 {
+			if err := tx.CreateInBatches(creates, 100).Error; err != nil {
+				return err
+			}
+		}
+		for _, update := range updates {
+			if err := tx.Model(update.Product).Select(update.Columns).Updates(update.Product).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// FindForExport returns a farmer's products, or the whole catalogue when
+// farmerID is nil, ordered for stable exports.
+func (r *productRepository) FindForExport(ctx context.Context, farmerID uuid.UUID) ([]*ProductExportRow, error) {
+	query := r.db.WithContext(ctx).
+		Table("products").
+		Select("products.*, COALESCE(farmers.farm_name, '') AS farm_name").
+		Joins("LEFT JOIN farmers ON farmers.id = products.farmer_id")
+	if farmerID != uuid.Nil {
+		query = query.Where("products.farmer_id = ?", farmerID)
+	}
+
+	var rows []*ProductExportRow
+	err := query.Order("farm_name, products.sku, products.name").Scan(&rows).Error
+	return rows, err
+}
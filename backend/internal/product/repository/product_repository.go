@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"log"
 	"strings"
@@ -21,14 +22,32 @@ type ProductRepository interface {
 	FindByFarmerID(ctx context.Context, farmerID uuid.UUID) ([]*model.Product, error)
 	Update(ctx context.Context, product *model.Product) error
 	Delete(ctx context.Context, id uuid.UUID) error
-	FindAllWithFilters(ctx context.Context, filters dto.ProductFilterRequest) ([]*model.Product, int64, error)
+	FindAllWithFilters(ctx context.Context, filters dto.ProductFilterRequest) ([]*ProductWithDistance, int64, error)
 	UpdateStock(ctx context.Context, productID uuid.UUID, quantity float64) error
 	UpdateStatus(ctx context.Context, productID uuid.UUID, status model.ProductStatus) error
 	FindFeaturedProducts(ctx context.Context, limit int) ([]*model.Product, error)
 	FindByCategory(ctx context.Context, category model.ProductCategory, page, pageSize int) ([]*model.Product, int64, error)
 	BulkUpdateStatus(ctx context.Context, productIDs []uuid.UUID, status model.ProductStatus) error
 	GetExpiredProducts(ctx context.Context) ([]*model.Product, error)
-	UpdateRating(ctx context.Context, productID uuid.UUID, rating float64, reviewCount int) error
+	UpdateRating(ctx context.Context, productID uuid.UUID) error
+	Search(ctx context.Context, query string, page, pageSize int) ([]*ProductSearchHit, int64, error)
+	FindFacetCounts(ctx context.Context, filters dto.ProductFilterRequest) ([]FacetCount, error)
+	FindByFarmerSKUs(ctx context.Context, farmerID uuid.UUID, skus []string) ([]*model.Product, error)
+	SaveImport(ctx context.Context, creates []*model.Product, updates []*ProductUpdate) error
+	FindForExport(ctx context.Context, farmerID uuid.UUID) ([]*ProductExportRow, error)
+	CreateVariant(ctx context.Context, variant *model.ProductVariant) error
+	FindVariantByID(ctx context.Context, id uuid.UUID) (*model.ProductVariant, error)
+	FindVariantsByProductIDs(ctx context.Context, productIDs []uuid.UUID, activeOnly bool) ([]*model.ProductVariant, error)
+	UpdateVariant(ctx context.Context, variant *model.ProductVariant) error
+	DeleteVariant(ctx context.Context, variant *model.ProductVariant) error
+	VariantSKUExists(ctx context.Context, productID uuid.UUID, sku string, excludeID uuid.UUID) (bool, error)
+	ReserveStock(ctx context.Context, productID uuid.UUID, variantID *uuid.UUID, quantity float64) (bool, error)
+	ReleaseStock(ctx context.Context, productID uuid.UUID, variantID *uuid.UUID, quantity float64) error
+	CreatePriceTier(ctx context.Context, tier *model.PriceTier) error
+	FindPriceTierByID(ctx context.Context, id uuid.UUID) (*model.PriceTier, error)
+	FindPriceTiersByProductID(ctx context.Context, productID uuid.UUID, activeOnly bool) ([]*model.PriceTier, error)
+	UpdatePriceTier(ctx context.Context, tier *model.PriceTier) error
+	DeletePriceTier(ctx context.Context, id uuid.UUID) error
 }
 
 type productRepository struct {
@@ -102,11 +121,19 @@ func (r *productRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return r.db.WithContext(ctx).Where("id = ?", id).Delete(&model.Product{}).Error
 }
 
-func (r *productRepository) FindAllWithFilters(ctx context.Context, filters dto.ProductFilterRequest) ([]*model.Product, int64, error) {
-	query := r.db.WithContext(ctx).Model(&model.Product{}).Where("status = ?", model.StatusActive)
+// ProductWithDistance is a catalogue product with its distance in km from
+// the search location, when the filter has one.
+type ProductWithDistance struct {
+	model.Product `gorm:"embedded"`
+	Distance      *float64
+}
 
-	// Apply filters
-	query = r.applyFilters(query, filters)
+func (r *productRepository) FindAllWithFilters(ctx context.Context, filters dto.ProductFilterRequest) ([]*ProductWithDistance, int64, error) {
+	query := r.db.WithContext(ctx).Model(&model.Product{}).
+		Joins("LEFT JOIN farmers ON farmers.id = products.farmer_id")
+	for _, condition := range productFilterConditions(filters) {
+		query = query.Where(condition.sql, condition.args...)
+	}
 
 	// Get total count
 	var total int64
@@ -114,10 +141,16 @@ func (r *productRepository) FindAllWithFilters(ctx context.Context, filters dto.
 		return nil, 0, err
 	}
 
+	if origin := filterOrigin(filters); origin != nil {
+		query = query.Select("products.*, " + productDistanceSQL(*origin) + " AS distance")
+	} else {
+		query = query.Select("products.*")
+	}
+
 	// Apply pagination and sorting
 	query = r.applyPaginationAndSorting(query, filters)
 
-	var products []*model.Product
+	var products []*ProductWithDistance
 	err := query.Find(&products).Error
 	if err != nil {
 		return nil, 0, err
@@ -126,57 +159,21 @@ func (r *productRepository) FindAllWithFilters(ctx context.Context, filters dto.
 	return products, total, nil
 }
 
-func (r *productRepository) applyFilters(query *gorm.DB, filters dto.ProductFilterRequest) *gorm.DB {
-	if filters.Category != "" {
-		query = query.Where("category = ?", filters.Category)
-	}
-
-	if filters.FarmerID != uuid.Nil {
-		query = query.Where("farmer_id = ?", filters.FarmerID)
-	}
-
-	if filters.MinPrice > 0 {
-		query = query.Where("price_per_unit >= ?", filters.MinPrice)
-	}
-
-	if filters.MaxPrice > 0 {
-		query = query.Where("price_per_unit <= ?", filters.MaxPrice)
-	}
-
-	if filters.Organic {
-		query = query.Where("organic = ?", true)
-	}
-
-	if filters.Certified {
-		query = query.Where("certified = ?", true)
-	}
-
-	if filters.QualityGrade != "" {
-		query = query.Where("quality_grade = ?", filters.QualityGrade)
-	}
-
-	if filters.MinRating > 0 {
-		query = query.Where("rating >= ?", filters.MinRating)
-	}
-
-	// For location-based filtering, you would need to join with farmer table
-	if filters.City != "" || filters.State != "" {
-		query = query.Joins("JOIN farmers ON farmers.id = products.farmer_id")
-		if filters.City != "" {
-			query = query.Where("farmers.city LIKE ?", fmt.Sprintf("%%%s%%", filters.City))
-		}
-		if filters.State != "" {
-			query = query.Where("farmers.state LIKE ?", fmt.Sprintf("%%%s%%", filters.State))
-		}
-	}
-
-	return query
-}
-
 func (r *productRepository) applyPaginationAndSorting(query *gorm.DB, filters dto.ProductFilterRequest) *gorm.DB {
 	// Apply sorting
-	sortField := "created_at"
-	sortOrder := "desc"
+	switch filters.Sort {
+	case "price_asc":
+		query = query.Order("products.price_per_unit ASC")
+	case "price_desc":
+		query = query.Order("products.price_per_unit DESC")
+	case "rating":
+		query = query.Order("products.rating DESC")
+	case "distance":
+		if filterOrigin(filters) != nil {
+			query = query.Order("distance ASC")
+		}
+	}
+	query = query.Order("products.created_at DESC")
 
 	if filters.PageSize == 0 {
 		filters.PageSize = 10
@@ -185,8 +182,6 @@ func (r *productRepository) applyPaginationAndSorting(query *gorm.DB, filters dt
 		filters.Page = 1
 	}
 
-	query = query.Order(fmt.Sprintf("%s %s", sortField, sortOrder))
-
 	// Apply pagination
 	offset := (filters.Page - 1) * filters.PageSize
 	return query.Offset(offset).Limit(filters.PageSize)
@@ -252,12 +247,40 @@ func (r *productRepository) GetExpiredProducts(ctx context.Context) ([]*model.Pr
 	return products, err
 }
 
-func (r *productRepository) UpdateRating(ctx context.Context, productID uuid.UUID, rating float64, reviewCount int) error {
-	return r.db.WithContext(ctx).Model(&model.Product{}).
-		Where("id = ?", productID).
-		Updates(map[string]interface{}{
-			"rating":       rating,
-			"review_count": reviewCount,
-			"updated_at":   time.Now(),
-		}).Error
+// UpdateRating recomputes the product's rating and review count from its
+// published reviews, then does the same for the farmer across all of their
+// products.
+func (r *productRepository) UpdateRating(ctx context.Context, productID uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`
+			UPDATE products SET
+				rating = COALESCE((SELECT ROUND(AVG(r.rating)::numeric, 2) FROM product_reviews r
+					WHERE r.product_id = products.id AND r.status = @published), 0),
+				review_count = (SELECT COUNT(*) FROM product_reviews r
+					WHERE r.product_id = products.id AND r.status = @published),
+				updated_at = @now
+			WHERE id = @product`,
+			sql.Named("published", model.ReviewStatusPublished),
+			sql.Named("now", time.Now()),
+			sql.Named("product", productID),
+		).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Exec(`
+			UPDATE farmers SET
+				rating = COALESCE((SELECT ROUND(AVG(r.rating)::numeric, 2) FROM product_reviews r
+					JOIN products p ON p.id = r.product_id
+					WHERE p.farmer_id = farmers.id AND r.status = @published), 0),
+				review_count = (SELECT COUNT(*) FROM product_reviews r
+					JOIN products p ON p.id = r.product_id
+					WHERE p.farmer_id = farmers.id AND r.status = @published),
+				updated_at = @now
+			WHERE id = (SELECT farmer_id FROM products WHERE id = @product)`,
+			sql.Named("published", model.ReviewStatusPublished),
+			sql.Named("now", time.Now()),
+			sql.Named("product", productID),
+		).Error
+	})
 }
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	model "agro_konnect/internal/product/model"
+)
+
+// Snippets mark matched terms with control characters rather than tags, so
+// the raw column text around them can be HTML-escaped before the markers are
+// turned into tags; product text cannot smuggle in markup of its own.
+const (
+	HighlightStart = "\x01"
+	HighlightStop  = "\x02"
+)
+
+var headlineSelectors = fmt.Sprintf(`StartSel="%s", StopSel="%s"`, HighlightStart, HighlightStop)
+
+// ProductSearchHit is an active product matching a search query, with its
+// relevance score and highlighted snippets. Snippets wrap matched terms in
+// HighlightStart and HighlightStop but are otherwise raw column text.
+type ProductSearchHit struct {
+	model.Product        `gorm:"embedded"`
+	FarmName             string
+	Score                float64
+	NameHighlight        string
+	DescriptionHighlight string
+}
+
+// searchMatch selects active products whose search vector matches the query,
+// or whose name or variety is a close trigram match so that misspellings such
+// as "tomatoe" still find something.
+const searchMatch = `
+	FROM products p
+	CROSS JOIN (SELECT websearch_to_tsquery('english', @query) AS tsq) q
+	LEFT JOIN farmers f ON f.id = p.farmer_id
+	WHERE p.status = @status
+		AND (p.search_vector @@ q.tsq
+			OR @query <% p.name
+			OR @query <% COALESCE(p.variety, ''))`
+
+// Search ranks full-text matches by weighted ts_rank_cd, topped up by trigram
+// similarity so exact and near-miss spellings sort together.
+func (r *productRepository) Search(ctx context.Context, query string, page, pageSize int) ([]*ProductSearchHit, int64, error) {
+	var total int64
+	err := r.db.WithContext(ctx).Raw(`SELECT COUNT(*)`+searchMatch,
+		sql.Named("query", query),
+		sql.Named("status", model.StatusActive),
+	).Scan(&total).Error
+	if err != nil || total == 0 {
+		return nil, total, err
+	}
+
+	var hits []*ProductSearchHit
+	err = r.db.WithContext(ctx).Raw(`
+		SELECT p.*, COALESCE(f.farm_name, '') AS farm_name,
+			ts_rank_cd(p.search_vector, q.tsq, 32)
+				+ 0.5 * GREATEST(word_similarity(@query, p.name), word_similarity(@query, COALESCE(p.variety, ''))) AS score,
+			ts_headline('english', p.name, q.tsq, @name_options) AS name_highlight,
+			ts_headline('english', COALESCE(p.description, ''), q.tsq, @description_options) AS description_highlight`+
+		searchMatch+`
+		ORDER BY score DESC, p.rating DESC, p.created_at DESC
+		LIMIT @limit OFFSET @offset`,
+		sql.Named("query", query),
+		sql.Named("status", model.StatusActive),
+		sql.Named("limit", pageSize),
+		sql.Named("offset", (page-1)*pageSize),
+		sql.Named("name_options", headlineSelectors+", HighlightAll=true"),
+		sql.Named("description_options", headlineSelectors+", MinWords=10, MaxWords=30, MaxFragments=2"),
+	).Scan(&hits).Error
+	return hits, total, err
+}
@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	model "agro_konnect/internal/product/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// syncVariantStockSQL sets a product's stock to the total held in its
+// variants, in the product's own unit. Products without variants keep the
+// stock set on them directly.
+const syncVariantStockSQL = `
+	UPDATE products SET available_stock = v.total, updated_at = ?
+	FROM (
+		SELECT COALESCE(SUM(available_stock * pack_size), 0) AS total
+		FROM product_variants WHERE product_id = ? AND is_active
+	) v
+	WHERE products.id = ?
+		AND EXISTS (SELECT 1 FROM product_variants WHERE product_id = ?)`
+
+func (r *productRepository) CreateVariant(ctx context.Context, variant *model.ProductVariant) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(variant).Error; err != nil {
+			return err
+		}
+		return syncVariantStock(tx, variant.ProductID)
+	})
+}
+
+func (r *productRepository) FindVariantByID(ctx context.Context, id uuid.UUID) (*model.ProductVariant, error) {
+	var variant model.ProductVariant
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&variant).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &variant, err
+}
+
+// FindVariantsByProductIDs returns the variants of the given products,
+// smallest pack first.
+func (r *productRepository) FindVariantsByProductIDs(ctx context.Context, productIDs []uuid.UUID, activeOnly bool) ([]*model.ProductVariant, error) {
+	var variants []*model.ProductVariant
+	if len(productIDs) == 0 {
+		return variants, nil
+	}
+
+	query := r.db.WithContext(ctx).Where("product_id IN ?", productIDs)
+	if activeOnly {
+		query = query.Where("is_active = ?", true)
+	}
+	err := query.Order("pack_size ASC, created_at ASC").Find(&variants).Error
+	return variants, err
+}
+
+func (r *productRepository) UpdateVariant(ctx context.Context, variant *model.ProductVariant) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(variant).Error; err != nil {
+			return err
+		}
+		return syncVariantStock(tx, variant.ProductID)
+	})
+}
+
+func (r *productRepository) DeleteVariant(ctx context.Context, variant *model.ProductVariant) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ?", variant.ID).Delete(&model.ProductVariant{}).Error; err != nil {
+			return err
+		}
+		return syncVariantStock(tx, variant.ProductID)
+	})
+}
+
+func (r *productRepository) VariantSKUExists(ctx context.Context, productID uuid.UUID, sku string, excludeID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.ProductVariant{}).
+		Where("product_id = ? AND sku = ? AND id <> ?", productID, sku, excludeID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// ReserveStock takes quantity out of a variant's stock, or the product's when
+// variantID is nil, only if enough is left. It reports false when there is
+// not, so two buyers can never take the same last unit.
+func (r *productRepository) ReserveStock(ctx context.Context, productID uuid.UUID, variantID *uuid.UUID, quantity float64) (bool, error) {
+	reserved := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var result *gorm.DB
+		if variantID != nil {
+			result = tx.Model(&model.ProductVariant{}).
+				Where("id = ? AND product_id = ? AND available_stock >= ?", *variantID, productID, quantity).
+				Updates(map[string]interface{}{
+					"available_stock": gorm.Expr("available_stock - ?", quantity),
+					"updated_at":      time.Now(),
+				})
+		} else {
+			result = tx.Model(&model.Product{}).
+				Where("id = ? AND available_stock >= ?", productID, quantity).
+				Updates(map[string]interface{}{
+					"available_stock": gorm.Expr("available_stock - ?", quantity),
+					"updated_at":      time.Now(),
+				})
+		}
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		reserved = true
+		if variantID != nil {
+			return syncVariantStock(tx, productID)
+		}
+		return nil
+	})
+	return reserved && err == nil, err
+}
+
+// ReleaseStock puts reserved quantity back, for example when an order is
+// cancelled. A variant deleted since the reservation is ignored.
+func (r *productRepository) ReleaseStock(ctx context.Context, productID uuid.UUID, variantID *uuid.UUID, quantity float64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if variantID == nil {
+			return tx.Model(&model.Product{}).
+				Where("id = ?", productID).
+				Updates(map[string]interface{}{
+					"available_stock": gorm.Expr("available_stock + ?", quantity),
+					"updated_at":      time.Now(),
+				}).Error
+		}
+
+		if err := tx.Model(&model.ProductVariant{}).
+			Where("id = ?", *variantID).
+			Updates(map[string]interface{}{
+				"available_stock": gorm.Expr("available_stock + ?", quantity),
+				"updated_at":      time.Now(),
+			}).Error; err != nil {
+			return err
+		}
+		return syncVariantStock(tx, productID)
+	})
+}
+
+func syncVariantStock(tx *gorm.DB, productID uuid.UUID) error {
+	return tx.Exec(syncVariantStockSQL, time.Now(), productID, productID, productID).Error
+}
@@ -0,0 +1,226 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	model "agro_konnect/internal/product/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RatingBucket is the number of published reviews with a given star rating.
+type RatingBucket struct {
+	Rating int
+	Count  int64
+}
+
+type ReviewRepository interface {
+	Create(ctx context.Context, review *model.ProductReview) error
+	FindByID(ctx context.Context, id uuid.UUID) (*model.ProductReview, error)
+	FindByProduct(ctx context.Context, productID uuid.UUID, sortBy string, page, pageSize int) ([]*model.ProductReview, int64, error)
+	FindByStatus(ctx context.Context, status model.ReviewStatus, page, pageSize int) ([]*model.ProductReview, int64, error)
+	GetRatingDistribution(ctx context.Context, productID uuid.UUID) ([]RatingBucket, error)
+	FindBuyerNames(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID]string, error)
+	FindReviewableOrder(ctx context.Context, buyerID, productID uuid.UUID, orderID *uuid.UUID) (uuid.UUID, error)
+	ExistsForOrder(ctx context.Context, productID, orderID uuid.UUID) (bool, error)
+	AddHelpfulVote(ctx context.Context, reviewID, userID uuid.UUID) (bool, error)
+	RemoveHelpfulVote(ctx context.Context, reviewID, userID uuid.UUID) (bool, error)
+	UpdateReply(ctx context.Context, id uuid.UUID, reply string, repliedAt time.Time) error
+	UpdateStatus(ctx context.Context, id uuid.UUID, status model.ReviewStatus, reason string, moderatedAt time.Time) error
+}
+
+type reviewRepository struct {
+	db *gorm.DB
+}
+
+func NewReviewRepository(db *gorm.DB) ReviewRepository {
+	return &reviewRepository{db: db}
+}
+
+func (r *reviewRepository) Create(ctx context.Context, review *model.ProductReview) error {
+	return r.db.WithContext(ctx).Create(review).Error
+}
+
+func (r *reviewRepository) FindByID(ctx context.Context, id uuid.UUID) (*model.ProductReview, error) {
+	var review model.ProductReview
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&review).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &review, err
+}
+
+// FindByProduct lists a product's published reviews. sortBy is one of
+// recent, helpful, rating_high or rating_low; anything else means recent.
+func (r *reviewRepository) FindByProduct(ctx context.Context, productID uuid.UUID, sortBy string, page, pageSize int) ([]*model.ProductReview, int64, error) {
+	query := r.db.WithContext(ctx).Model(&model.ProductReview{}).
+		Where("product_id = ? AND status = ?", productID, model.ReviewStatusPublished)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	switch sortBy {
+	case "helpful":
+		query = query.Order("helpful DESC, created_at DESC")
+	case "rating_high":
+		query = query.Order("rating DESC, created_at DESC")
+	case "rating_low":
+		query = query.Order("rating ASC, created_at DESC")
+	default:
+		query = query.Order("created_at DESC")
+	}
+
+	var reviews []*model.ProductReview
+	offset := (page - 1) * pageSize
+	err := query.Offset(offset).Limit(pageSize).Find(&reviews).Error
+	return reviews, total, err
+}
+
+func (r *reviewRepository) FindByStatus(ctx context.Context, status model.ReviewStatus, page, pageSize int) ([]*model.ProductReview, int64, error) {
+	query := r.db.WithContext(ctx).Model(&model.ProductReview{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var reviews []*model.ProductReview
+	offset := (page - 1) * pageSize
+	err := query.Order("created_at DESC").Offset(offset).Limit(pageSize).Find(&reviews).Error
+	return reviews, total, err
+}
+
+func (r *reviewRepository) GetRatingDistribution(ctx context.Context, productID uuid.UUID) ([]RatingBucket, error) {
+	var buckets []RatingBucket
+	err := r.db.WithContext(ctx).Model(&model.ProductReview{}).
+		Select("rating, COUNT(*) AS count").
+		Where("product_id = ? AND status = ?", productID, model.ReviewStatusPublished).
+		Group("rating").
+		Scan(&buckets).Error
+	return buckets, err
+}
+
+// FindBuyerNames maps user IDs to the business name on their buyer profile.
+func (r *reviewRepository) FindBuyerNames(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID]string, error) {
+	names := make(map[uuid.UUID]string, len(userIDs))
+	if len(userIDs) == 0 {
+		return names, nil
+	}
+
+	var rows []struct {
+		UserID       uuid.UUID
+		BusinessName string
+	}
+	err := r.db.WithContext(ctx).
+		Table("buyers").
+		Select("user_id, business_name").
+		Where("user_id IN ?", userIDs).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		names[row.UserID] = row.BusinessName
+	}
+	return names, nil
+}
+
+// FindReviewableOrder returns the buyer's most recent delivered order that
+// contains the product and has not reviewed it yet, or uuid.Nil if there is
+// none. When orderID is given only that order is considered.
+func (r *reviewRepository) FindReviewableOrder(ctx context.Context, buyerID, productID uuid.UUID, orderID *uuid.UUID) (uuid.UUID, error) {
+	query := r.db.WithContext(ctx).
+		Table("orders o").
+		Select("o.id").
+		Joins("JOIN order_items i ON i.order_id = o.id AND i.product_id = ?", productID).
+		Where("o.buyer_id = ? AND o.status = ?", buyerID, "delivered").
+		Where("NOT EXISTS (SELECT 1 FROM product_reviews pr WHERE pr.order_id = o.id AND pr.product_id = ?)", productID)
+	if orderID != nil {
+		query = query.Where("o.id = ?", *orderID)
+	}
+
+	var ids []uuid.UUID
+	err := query.Order("COALESCE(o.actual_delivery, o.updated_at) DESC").Limit(1).Pluck("o.id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return uuid.Nil, err
+	}
+	return ids[0], nil
+}
+
+func (r *reviewRepository) ExistsForOrder(ctx context.Context, productID, orderID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.ProductReview{}).
+		Where("product_id = ? AND order_id = ?", productID, orderID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// AddHelpfulVote records the vote and bumps the counter. It reports false if
+// the user had already voted.
+func (r *reviewRepository) AddHelpfulVote(ctx context.Context, reviewID, userID uuid.UUID) (bool, error) {
+	added := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&model.ReviewHelpfulVote{
+			ReviewID:  reviewID,
+			UserID:    userID,
+			CreatedAt: time.Now(),
+		})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		added = true
+		return tx.Model(&model.ProductReview{}).
+			Where("id = ?", reviewID).
+			UpdateColumn("helpful", gorm.Expr("helpful + 1")).Error
+	})
+	return added, err
+}
+
+// RemoveHelpfulVote withdraws the vote. It reports false if there was none.
+func (r *reviewRepository) RemoveHelpfulVote(ctx context.Context, reviewID, userID uuid.UUID) (bool, error) {
+	removed := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("review_id = ? AND user_id = ?", reviewID, userID).Delete(&model.ReviewHelpfulVote{})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		removed = true
+		return tx.Model(&model.ProductReview{}).
+			Where("id = ? AND helpful > 0", reviewID).
+			UpdateColumn("helpful", gorm.Expr("helpful - 1")).Error
+	})
+	return removed, err
+}
+
+func (r *reviewRepository) UpdateReply(ctx context.Context, id uuid.UUID, reply string, repliedAt time.Time) error {
+	return r.db.WithContext(ctx).Model(&model.ProductReview{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"farmer_reply": reply,
+			"replied_at":   &repliedAt,
+			"updated_at":   time.Now(),
+		}).Error
+}
+
+func (r *reviewRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status model.ReviewStatus, reason string, moderatedAt time.Time) error {
+	return r.db.WithContext(ctx).Model(&model.ProductReview{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":            status,
+			"moderation_reason": reason,
+			"moderated_at":      &moderatedAt,
+			"updated_at":        time.Now(),
+		}).Error
+}
@@ -1,8 +1,10 @@
 package routes
 
 import (
+	auditservice "agro_konnect/internal/audit/service"
 	"agro_konnect/internal/auth/middleware"
 	"agro_konnect/internal/auth/model"
+	farmerRepository "agro_konnect/internal/farmer/repository"
 	"agro_konnect/internal/product/handler"
 	"agro_konnect/internal/product/repository"
 	"agro_konnect/internal/product/service"
@@ -11,19 +13,29 @@ import (
 	"gorm.io/gorm"
 )
 
-func SetupAdminProductRoutes(router *gin.RouterGroup, db *gorm.DB, authMiddleware *middleware.AuthMiddleware) {
+func SetupAdminProductRoutes(router *gin.RouterGroup, db *gorm.DB, authMiddleware *middleware.AuthMiddleware, auditRecorder auditservice.Recorder) {
 	// Initialize admin product dependencies
 	adminRepo := repository.NewAdminProductRepository(db)
-	adminService := service.NewAdminProductService(adminRepo)
+	adminService := service.NewAdminProductService(adminRepo, auditRecorder)
 	adminHandler := handler.NewAdminProductHandler(adminService)
 
+	productRepo := repository.NewProductRepository(db)
+	farmerRepo := farmerRepository.NewFarmerRepository(db)
+	productHandler := handler.NewProductHandler(service.NewProductService(productRepo, farmerRepo))
+
+	reviewRepo := repository.NewReviewRepository(db)
+	reviewService := service.NewReviewService(reviewRepo, productRepo, farmerRepo)
+	reviewHandler := handler.NewReviewHandler(reviewService)
+
 	adminRoutes := router.Group("/admin/products")
 	adminRoutes.Use(authMiddleware.Authenticate(), authMiddleware.RequireRole(model.RoleAdmin))
 	{
 		// Product management
 		adminRoutes.GET("", adminHandler.GetProductsAdmin)
 		adminRoutes.GET("/stats", adminHandler.GetProductStats)
+		adminRoutes.GET("/export", productHandler.ExportAllProducts)
 		adminRoutes.GET("/status/:status", adminHandler.GetProductsByStatus)
+		adminRoutes.GET("/reviews", reviewHandler.GetReviewsForModeration)
 		adminRoutes.GET("/:id", adminHandler.GetProductAdmin)
 
 		// Status management
@@ -37,6 +49,9 @@ func SetupAdminProductRoutes(router *gin.RouterGroup, db *gorm.DB, authMiddlewar
 		adminRoutes.DELETE("/:id", adminHandler.DeleteProduct)
 		adminRoutes.DELETE("/bulk-delete", adminHandler.BulkDeleteProducts)
 
+		// Review moderation
+		adminRoutes.PUT("/reviews/:reviewId/moderation", reviewHandler.ModerateReview)
+
 		// Alerts and monitoring
 		adminRoutes.GET("/alerts/expiring", adminHandler.GetExpiringProducts)
 		adminRoutes.GET("/alerts/low-stock", adminHandler.GetLowStockProducts)
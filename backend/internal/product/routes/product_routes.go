@@ -1,8 +1,11 @@
 package routes
 
 import (
+	auditservice "agro_konnect/internal/audit/service"
 	"agro_konnect/internal/auth/middleware"
+	buyerRepository "agro_konnect/internal/buyer/repository"
 	farmerRepository "agro_konnect/internal/farmer/repository"
+	mediaService "agro_konnect/internal/media/service"
 	"agro_konnect/internal/product/handler"
 	"agro_konnect/internal/product/repository"
 	"agro_konnect/internal/product/service"
@@ -11,15 +14,25 @@ import (
 	"gorm.io/gorm"
 )
 
-func SetupProductRoutes(router *gin.RouterGroup, db *gorm.DB, authMiddleware *middleware.AuthMiddleware, uploadDir string) {
+func SetupProductRoutes(router *gin.RouterGroup, db *gorm.DB, authMiddleware *middleware.AuthMiddleware, mediaService mediaService.MediaService, auditRecorder auditservice.Recorder, legacyUploadDir string) {
 	// Initialize product dependencies
 	productRepo := repository.NewProductRepository(db)
 	farmerRepo := farmerRepository.NewFarmerRepository(db)               // Add farmer repository
 	productService := service.NewProductService(productRepo, farmerRepo) // Update service initialization
 	productHandler := handler.NewProductHandler(productService)
 
-	// Initialize image handler
-	imageHandler := handler.NewImageHandler(uploadDir)
+	// Initialize review dependencies
+	reviewRepo := repository.NewReviewRepository(db)
+	reviewService := service.NewReviewService(reviewRepo, productRepo, farmerRepo)
+	reviewHandler := handler.NewReviewHandler(reviewService)
+
+	// Initialize pricing dependencies
+	buyerRepo := buyerRepository.NewBuyerRepository(db)
+	pricingService := service.NewPricingService(productRepo, farmerRepo, buyerRepo)
+	pricingHandler := handler.NewPricingHandler(pricingService)
+
+	// Initialize image handler; uploads go to media storage
+	imageHandler := handler.NewImageHandler(mediaService, legacyUploadDir)
 
 	productRoutes := router.Group("/products")
 	{
@@ -29,8 +42,10 @@ func SetupProductRoutes(router *gin.RouterGroup, db *gorm.DB, authMiddleware *mi
 		productRoutes.GET("/featured", productHandler.GetFeaturedProducts)
 		productRoutes.GET("/category/:category", productHandler.GetProductsByCategory)
 		productRoutes.GET("/:id", productHandler.GetProductByID)
+		productRoutes.GET("/:id/reviews", reviewHandler.GetProductReviews)
+		productRoutes.GET("/:id/variants", productHandler.GetVariants)
 
-		// Image serving (public)
+		// Images uploaded before media storage (public)
 		productRoutes.GET("/images/:filename", imageHandler.ServeProductImage)
 
 		// Protected routes - requires authentication
@@ -38,11 +53,32 @@ func SetupProductRoutes(router *gin.RouterGroup, db *gorm.DB, authMiddleware *mi
 		{
 			authRequired.POST("", productHandler.CreateProduct)
 			authRequired.GET("/me", productHandler.GetMyProducts)
+			authRequired.POST("/import", productHandler.ImportProducts)
+			authRequired.GET("/export", productHandler.ExportMyProducts)
 			authRequired.PUT("/:id", productHandler.UpdateProduct)
 			authRequired.DELETE("/:id", productHandler.DeleteProduct)
 			authRequired.PUT("/:id/stock", productHandler.UpdateStock)
 			authRequired.PUT("/:id/status", productHandler.UpdateStatus)
 
+			// Variants
+			authRequired.POST("/:id/variants", productHandler.CreateVariant)
+			authRequired.PUT("/:id/variants/:variantId", productHandler.UpdateVariant)
+			authRequired.DELETE("/:id/variants/:variantId", productHandler.DeleteVariant)
+
+			// Pricing
+			authRequired.GET("/:id/quote", pricingHandler.GetQuote)
+			authRequired.GET("/:id/price-tiers", pricingHandler.GetPriceTiers)
+			authRequired.POST("/:id/price-tiers", pricingHandler.CreatePriceTier)
+			authRequired.PUT("/:id/price-tiers/:tierId", pricingHandler.UpdatePriceTier)
+			authRequired.DELETE("/:id/price-tiers/:tierId", pricingHandler.DeletePriceTier)
+
+			// Reviews
+			authRequired.POST("/:id/reviews", reviewHandler.AddReview)
+			authRequired.POST("/reviews/:reviewId/helpful", reviewHandler.MarkHelpful)
+			authRequired.DELETE("/reviews/:reviewId/helpful", reviewHandler.RemoveHelpful)
+			authRequired.PUT("/reviews/:reviewId/reply", reviewHandler.ReplyToReview)
+			authRequired.POST("/reviews/images/upload", imageHandler.UploadReviewImage)
+
 			// Image upload routes
 			authRequired.POST("/images/upload", imageHandler.UploadProductImage)
 			authRequired.POST("/images/upload-multiple", imageHandler.UploadMultipleProductImages)
@@ -51,5 +87,5 @@ func SetupProductRoutes(router *gin.RouterGroup, db *gorm.DB, authMiddleware *mi
 	}
 
 	// Setup admin routes
-	SetupAdminProductRoutes(router, db, authMiddleware)
+	SetupAdminProductRoutes(router, db, authMiddleware, auditRecorder)
 }
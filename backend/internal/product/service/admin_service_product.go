@@ -5,6 +5,7 @@ import (
 	"log"
 	"time"
 
+	auditservice "agro_konnect/internal/audit/service"
 	dto "agro_konnect/internal/product/dto"
 	model "agro_konnect/internal/product/model"
 	"agro_konnect/internal/product/repository"
@@ -29,11 +30,13 @@ type AdminProductService interface {
 
 type adminProductService struct {
 	adminRepo repository.AdminProductRepository
+	audit     auditservice.Recorder
 }
 
-func NewAdminProductService(adminRepo repository.AdminProductRepository) AdminProductService {
+func NewAdminProductService(adminRepo repository.AdminProductRepository, audit auditservice.Recorder) AdminProductService {
 	return &adminProductService{
 		adminRepo: adminRepo,
+		audit:     audit,
 	}
 }
 
@@ -93,7 +96,20 @@ func (s *adminProductService) UpdateProductStatus(ctx context.Context, productID
 		return errors.New("invalid product status")
 	}
 
-	return s.adminRepo.UpdateProductStatus(ctx, productID, status)
+	product, err := s.adminRepo.GetProductByID(ctx, productID)
+	if err != nil {
+		return err
+	}
+	if product == nil {
+		return ErrProductNotFound
+	}
+
+	if err := s.adminRepo.UpdateProductStatus(ctx, productID, status); err != nil {
+		return err
+	}
+
+	s.recordStatusChange(ctx, product, status)
+	return nil
 }
 
 func (s *adminProductService) BulkUpdateProductStatus(ctx context.Context, req *dto.BulkUpdateStatusRequest) error {
@@ -114,7 +130,43 @@ func (s *adminProductService) BulkUpdateProductStatus(ctx context.Context, req *
 		return errors.New("invalid product status")
 	}
 
-	return s.adminRepo.BulkUpdateProductStatus(ctx, req.ProductIDs, req.Status)
+	products, err := s.adminRepo.GetProductsByIDs(ctx, req.ProductIDs)
+	if err != nil {
+		return err
+	}
+
+	if err := s.adminRepo.BulkUpdateProductStatus(ctx, req.ProductIDs, req.Status); err != nil {
+		return err
+	}
+
+	// One entry per product, so each product's history is complete
+	for _, product := range products {
+		s.recordStatusChange(ctx, product, req.Status)
+	}
+	return nil
+}
+
+func (s *adminProductService) recordStatusChange(ctx context.Context, product *model.Product, status model.ProductStatus) {
+	s.audit.Record(ctx, auditservice.Event{
+		Action:     "product.status",
+		EntityType: "product",
+		EntityID:   product.ID.String(),
+		Before:     map[string]interface{}{"status": product.Status},
+		After:      map[string]interface{}{"status": status},
+	})
+}
+
+func (s *adminProductService) recordDeletion(ctx context.Context, product *model.Product) {
+	s.audit.Record(ctx, auditservice.Event{
+		Action:     "product.delete",
+		EntityType: "product",
+		EntityID:   product.ID.String(),
+		Before: map[string]interface{}{
+			"name":      product.Name,
+			"farmer_id": product.FarmerID,
+			"status":    product.Status,
+		},
+	})
 }
 
 func (s *adminProductService) DeleteProduct(ctx context.Context, productID uuid.UUID) error {
@@ -127,7 +179,12 @@ func (s *adminProductService) DeleteProduct(ctx context.Context, productID uuid.
 		return ErrProductNotFound
 	}
 
-	return s.adminRepo.DeleteProduct(ctx, productID)
+	if err := s.adminRepo.DeleteProduct(ctx, productID); err != nil {
+		return err
+	}
+
+	s.recordDeletion(ctx, product)
+	return nil
 }
 
 func (s *adminProductService) BulkDeleteProducts(ctx context.Context, productIDs []uuid.UUID) error {
@@ -135,7 +192,19 @@ func (s *adminProductService) BulkDeleteProducts(ctx context.Context, productIDs
 		return errors.New("no product IDs provided")
 	}
 
-	return s.adminRepo.BulkDeleteProducts(ctx, productIDs)
+	products, err := s.adminRepo.GetProductsByIDs(ctx, productIDs)
+	if err != nil {
+		return err
+	}
+
+	if err := s.adminRepo.BulkDeleteProducts(ctx, productIDs); err != nil {
+		return err
+	}
+
+	for _, product := range products {
+		s.recordDeletion(ctx, product)
+	}
+	return nil
 }
 
 func (s *adminProductService) GetProductStats(ctx context.Context) (*dto.ProductStatsResponse, error) {
@@ -200,7 +269,18 @@ func (s *adminProductService) UpdateProductFeaturedStatus(ctx context.Context, p
 		return ErrProductNotFound
 	}
 
-	return s.adminRepo.UpdateProductFeaturedStatus(ctx, productID, isFeatured)
+	if err := s.adminRepo.UpdateProductFeaturedStatus(ctx, productID, isFeatured); err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, auditservice.Event{
+		Action:     "product.featured",
+		EntityType: "product",
+		EntityID:   productID.String(),
+		Before:     map[string]interface{}{"is_featured": product.IsFeatured},
+		After:      map[string]interface{}{"is_featured": isFeatured},
+	})
+	return nil
 }
 
 func (s *adminProductService) GetExpiringProducts(ctx context.Context, days int) ([]*dto.AdminProductResponse, error) {
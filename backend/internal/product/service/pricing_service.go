@@ -0,0 +1,292 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	buyerRepo "agro_konnect/internal/buyer/repository"
+	farmerRepo "agro_konnect/internal/farmer/repository"
+	dto "agro_konnect/internal/product/dto"
+	model "agro_konnect/internal/product/model"
+	"agro_konnect/internal/product/repository"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrPriceTierNotFound = errors.New("price tier not found")
+	ErrInvalidPriceTier  = errors.New("price tier must end after it starts")
+	ErrBuyerNotFound     = errors.New("buyer not found")
+)
+
+// PriceQuote is the price picked for a quantity of a product or variant.
+// Tier is nil when the list price applies.
+type PriceQuote struct {
+	ListPrice float64
+	UnitPrice float64
+	Tier      *model.PriceTier
+}
+
+type PricingService interface {
+	GetPriceTiers(ctx context.Context, productID uuid.UUID, userID uuid.UUID) ([]*dto.PriceTierResponse, error)
+	CreatePriceTier(ctx context.Context, productID uuid.UUID, userID uuid.UUID, req *dto.CreatePriceTierRequest) (*dto.PriceTierResponse, error)
+	UpdatePriceTier(ctx context.Context, productID, tierID uuid.UUID, userID uuid.UUID, req *dto.UpdatePriceTierRequest) (*dto.PriceTierResponse, error)
+	DeletePriceTier(ctx context.Context, productID, tierID uuid.UUID, userID uuid.UUID) error
+	GetQuote(ctx context.Context, productID uuid.UUID, userID uuid.UUID, req *dto.PriceQuoteRequest) (*dto.PriceQuoteResponse, error)
+	Price(ctx context.Context, buyerID uuid.UUID, product *model.Product, variant *model.ProductVariant, quantity float64) (*PriceQuote, error)
+}
+
+type pricingService struct {
+	productRepo repository.ProductRepository
+	farmerRepo  farmerRepo.FarmerRepository
+	buyerRepo   buyerRepo.BuyerRepository
+}
+
+func NewPricingService(productRepo repository.ProductRepository, farmerRepo farmerRepo.FarmerRepository, buyerRepo buyerRepo.BuyerRepository) PricingService {
+	return &pricingService{
+		productRepo: productRepo,
+		farmerRepo:  farmerRepo,
+		buyerRepo:   buyerRepo,
+	}
+}
+
+func (s *pricingService) GetPriceTiers(ctx context.Context, productID uuid.UUID, userID uuid.UUID) ([]*dto.PriceTierResponse, error) {
+	if _, err := findOwnedProduct(ctx, s.productRepo, s.farmerRepo, productID, userID); err != nil {
+		return nil, err
+	}
+
+	tiers, err := s.productRepo.FindPriceTiersByProductID(ctx, productID, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get price tiers: %w", err)
+	}
+
+	responses := make([]*dto.PriceTierResponse, len(tiers))
+	for i, tier := range tiers {
+		responses[i] = toPriceTierResponse(tier)
+	}
+	return responses, nil
+}
+
+func (s *pricingService) CreatePriceTier(ctx context.Context, productID uuid.UUID, userID uuid.UUID, req *dto.CreatePriceTierRequest) (*dto.PriceTierResponse, error) {
+	if _, err := findOwnedProduct(ctx, s.productRepo, s.farmerRepo, productID, userID); err != nil {
+		return nil, err
+	}
+
+	if req.VariantID != nil {
+		variant, err := s.productRepo.FindVariantByID(ctx, *req.VariantID)
+		if err != nil {
+			return nil, err
+		}
+		if variant == nil || variant.ProductID != productID {
+			return nil, ErrVariantNotFound
+		}
+	}
+	if req.BuyerID != nil {
+		buyer, err := s.buyerRepo.FindByUserID(ctx, *req.BuyerID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get buyer: %w", err)
+		}
+		if buyer == nil {
+			return nil, ErrBuyerNotFound
+		}
+	}
+	if req.ValidFrom != nil && req.ValidUntil != nil && !req.ValidUntil.After(*req.ValidFrom) {
+		return nil, ErrInvalidPriceTier
+	}
+
+	tier := &model.PriceTier{
+		ID:            uuid.New(),
+		ProductID:     productID,
+		VariantID:     req.VariantID,
+		Name:          strings.TrimSpace(req.Name),
+		MinQuantity:   req.MinQuantity,
+		Price:         req.Price,
+		BuyerType:     req.BuyerType,
+		BusinessScale: req.BusinessScale,
+		BuyerID:       req.BuyerID,
+		ValidFrom:     req.ValidFrom,
+		ValidUntil:    req.ValidUntil,
+		IsActive:      true,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+
+	if err := s.productRepo.CreatePriceTier(ctx, tier); err != nil {
+		return nil, fmt.Errorf("failed to create price tier: %w", err)
+	}
+	return toPriceTierResponse(tier), nil
+}
+
+func (s *pricingService) UpdatePriceTier(ctx context.Context, productID, tierID uuid.UUID, userID uuid.UUID, req *dto.UpdatePriceTierRequest) (*dto.PriceTierResponse, error) {
+	tier, err := s.findOwnedTier(ctx, productID, tierID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		tier.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.MinQuantity != nil {
+		tier.MinQuantity = *req.MinQuantity
+	}
+	if req.Price != nil {
+		tier.Price = *req.Price
+	}
+	if req.BuyerType != nil {
+		tier.BuyerType = *req.BuyerType
+	}
+	if req.BusinessScale != nil {
+		tier.BusinessScale = *req.BusinessScale
+	}
+	if req.ValidFrom != nil {
+		tier.ValidFrom = req.ValidFrom
+	}
+	if req.ValidUntil != nil {
+		tier.ValidUntil = req.ValidUntil
+	}
+	if req.IsActive != nil {
+		tier.IsActive = *req.IsActive
+	}
+
+	if tier.ValidFrom != nil && tier.ValidUntil != nil && !tier.ValidUntil.After(*tier.ValidFrom) {
+		return nil, ErrInvalidPriceTier
+	}
+
+	tier.UpdatedAt = time.Now()
+	if err := s.productRepo.UpdatePriceTier(ctx, tier); err != nil {
+		return nil, fmt.Errorf("failed to update price tier: %w", err)
+	}
+	return toPriceTierResponse(tier), nil
+}
+
+func (s *pricingService) DeletePriceTier(ctx context.Context, productID, tierID uuid.UUID, userID uuid.UUID) error {
+	tier, err := s.findOwnedTier(ctx, productID, tierID, userID)
+	if err != nil {
+		return err
+	}
+	return s.productRepo.DeletePriceTier(ctx, tier.ID)
+}
+
+// GetQuote tells a buyer what they would pay for a quantity, so that tier
+// prices can be shown before ordering.
+func (s *pricingService) GetQuote(ctx context.Context, productID uuid.UUID, userID uuid.UUID, req *dto.PriceQuoteRequest) (*dto.PriceQuoteResponse, error) {
+	product, err := s.productRepo.FindByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, ErrProductNotFound
+	}
+
+	var variant *model.ProductVariant
+	if req.VariantID != nil {
+		variant, err = s.productRepo.FindVariantByID(ctx, *req.VariantID)
+		if err != nil {
+			return nil, err
+		}
+		if variant == nil || variant.ProductID != productID || !variant.IsActive {
+			return nil, ErrVariantNotFound
+		}
+	}
+
+	quote, err := s.Price(ctx, userID, product, variant, req.Quantity)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.PriceQuoteResponse{
+		ProductID:  productID,
+		VariantID:  req.VariantID,
+		Quantity:   req.Quantity,
+		ListPrice:  quote.ListPrice,
+		UnitPrice:  quote.UnitPrice,
+		TotalPrice: math.Round(quote.UnitPrice*req.Quantity*100) / 100,
+	}
+	if quote.Tier != nil {
+		response.TierID = &quote.Tier.ID
+		response.TierName = quote.Tier.Name
+	}
+	return response, nil
+}
+
+// Price picks the lowest price on offer to the buyer for the quantity: the
+// list price of the product or variant, or a cheaper tier that applies.
+func (s *pricingService) Price(ctx context.Context, buyerID uuid.UUID, product *model.Product, variant *model.ProductVariant, quantity float64) (*PriceQuote, error) {
+	quote := &PriceQuote{ListPrice: product.PricePerUnit}
+	var variantID *uuid.UUID
+	if variant != nil {
+		quote.ListPrice = variant.Price
+		variantID = &variant.ID
+	}
+	quote.UnitPrice = quote.ListPrice
+
+	tiers, err := s.productRepo.FindPriceTiersByProductID(ctx, product.ID, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get price tiers: %w", err)
+	}
+	if len(tiers) == 0 {
+		return quote, nil
+	}
+
+	// The buyer profile is only needed for tiers limited to a kind of buyer
+	var buyerType, businessScale string
+	for _, tier := range tiers {
+		if tier.BuyerType == "" && tier.BusinessScale == "" {
+			continue
+		}
+		buyer, err := s.buyerRepo.FindByUserID(ctx, buyerID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get buyer: %w", err)
+		}
+		if buyer != nil {
+			buyerType, businessScale = string(buyer.BusinessType), string(buyer.BusinessScale)
+		}
+		break
+	}
+
+	now := time.Now()
+	for _, tier := range tiers {
+		if tier.Price < quote.UnitPrice && tier.AppliesTo(variantID, quantity, buyerID, buyerType, businessScale, now) {
+			quote.UnitPrice = tier.Price
+			quote.Tier = tier
+		}
+	}
+	return quote, nil
+}
+
+// Helper methods
+func (s *pricingService) findOwnedTier(ctx context.Context, productID, tierID uuid.UUID, userID uuid.UUID) (*model.PriceTier, error) {
+	if _, err := findOwnedProduct(ctx, s.productRepo, s.farmerRepo, productID, userID); err != nil {
+		return nil, err
+	}
+
+	tier, err := s.productRepo.FindPriceTierByID(ctx, tierID)
+	if err != nil {
+		return nil, err
+	}
+	if tier == nil || tier.ProductID != productID {
+		return nil, ErrPriceTierNotFound
+	}
+	return tier, nil
+}
+
+func toPriceTierResponse(tier *model.PriceTier) *dto.PriceTierResponse {
+	return &dto.PriceTierResponse{
+		ID:            tier.ID,
+		ProductID:     tier.ProductID,
+		VariantID:     tier.VariantID,
+		Name:          tier.Name,
+		MinQuantity:   tier.MinQuantity,
+		Price:         tier.Price,
+		BuyerType:     tier.BuyerType,
+		BusinessScale: tier.BusinessScale,
+		BuyerID:       tier.BuyerID,
+		ValidFrom:     tier.ValidFrom,
+		ValidUntil:    tier.ValidUntil,
+		IsActive:      tier.IsActive,
+	}
+}
@@ -0,0 +1,560 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	dto "agro_konnect/internal/product/dto"
+	model "agro_konnect/internal/product/model"
+	"agro_konnect/internal/product/repository"
+	"agro_konnect/internal/product/utils"
+	"agro_konnect/pkg/xlsx"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+
+	// MaxImportRows bounds one import so it fits in a single transaction.
+	MaxImportRows = 1000
+)
+
+var (
+	ErrUnsupportedFormat = errors.New("file format must be csv or xlsx")
+	ErrInvalidImportFile = errors.New("invalid import file")
+	ErrImportEmpty       = errors.New("import file has no product rows")
+	ErrImportTooLarge    = fmt.Errorf("import is limited to %d rows", MaxImportRows)
+)
+
+// productColumn maps a spreadsheet column to a CreateProductRequest field.
+// Column names match the JSON field names so a file reads like the API.
+type productColumn struct {
+	name string
+	get  func(req *dto.CreateProductRequest) interface{}
+	set  func(req *dto.CreateProductRequest, value string) error
+}
+
+var productColumns = []productColumn{
+	{"sku", func(r *dto.CreateProductRequest) interface{} { return r.SKU }, func(r *dto.CreateProductRequest, v string) error { r.SKU = v; return nil }},
+	{"name", func(r *dto.CreateProductRequest) interface{} { return r.Name }, func(r *dto.CreateProductRequest, v string) error { r.Name = v; return nil }},
+	{"category", func(r *dto.CreateProductRequest) interface{} { return string(r.Category) }, func(r *dto.CreateProductRequest, v string) error {
+		r.Category = model.ProductCategory(strings.ToLower(v))
+		return nil
+	}},
+	{"subcategory", func(r *dto.CreateProductRequest) interface{} { return r.Subcategory }, func(r *dto.CreateProductRequest, v string) error { r.Subcategory = v; return nil }},
+	{"description", func(r *dto.CreateProductRequest) interface{} { return r.Description }, func(r *dto.CreateProductRequest, v string) error { r.Description = v; return nil }},
+	{"images", func(r *dto.CreateProductRequest) interface{} { return strings.Join(r.Images, "|") }, func(r *dto.CreateProductRequest, v string) error {
+		r.Images = nil
+		for _, image := range strings.Split(v, "|") {
+			if image = strings.TrimSpace(image); image != "" {
+				r.Images = append(r.Images, image)
+			}
+		}
+		return nil
+	}},
+	{"price_per_unit", func(r *dto.CreateProductRequest) interface{} { return r.PricePerUnit }, floatSetter(func(r *dto.CreateProductRequest) *float64 { return &r.PricePerUnit })},
+	{"unit", func(r *dto.CreateProductRequest) interface{} { return r.Unit }, func(r *dto.CreateProductRequest, v string) error { r.Unit = v; return nil }},
+	{"available_stock", func(r *dto.CreateProductRequest) interface{} { return r.AvailableStock }, floatSetter(func(r *dto.CreateProductRequest) *float64 { return &r.AvailableStock })},
+	{"min_order", func(r *dto.CreateProductRequest) interface{} { return r.MinOrder }, floatSetter(func(r *dto.CreateProductRequest) *float64 { return &r.MinOrder })},
+	{"max_order", func(r *dto.CreateProductRequest) interface{} { return r.MaxOrder }, floatSetter(func(r *dto.CreateProductRequest) *float64 { return &r.MaxOrder })},
+	{"quality_grade", func(r *dto.CreateProductRequest) interface{} { return string(r.QualityGrade) }, func(r *dto.CreateProductRequest, v string) error {
+		r.QualityGrade = model.QualityGrade(strings.ToLower(v))
+		return nil
+	}},
+	{"organic", func(r *dto.CreateProductRequest) interface{} { return r.Organic }, boolSetter(func(r *dto.CreateProductRequest) *bool { return &r.Organic })},
+	{"certified", func(r *dto.CreateProductRequest) interface{} { return r.Certified }, boolSetter(func(r *dto.CreateProductRequest) *bool { return &r.Certified })},
+	{"certification_details", func(r *dto.CreateProductRequest) interface{} { return r.CertificationDetails }, func(r *dto.CreateProductRequest, v string) error { r.CertificationDetails = v; return nil }},
+	{"harvest_date", func(r *dto.CreateProductRequest) interface{} { return r.HarvestDate }, func(r *dto.CreateProductRequest, v string) error {
+		date, err := parseDateCell(v)
+		if err != nil {
+			return err
+		}
+		r.HarvestDate = date
+		return nil
+	}},
+	{"shelf_life", func(r *dto.CreateProductRequest) interface{} { return r.ShelfLife }, func(r *dto.CreateProductRequest, v string) error {
+		days, err := strconv.ParseFloat(v, 64)
+		if err != nil || days != float64(int(days)) {
+			return errors.New("must be a whole number of days")
+		}
+		r.ShelfLife = int(days)
+		return nil
+	}},
+	{"storage_tips", func(r *dto.CreateProductRequest) interface{} { return r.StorageTips }, func(r *dto.CreateProductRequest, v string) error { r.StorageTips = v; return nil }},
+	{"weight_range", func(r *dto.CreateProductRequest) interface{} { return r.WeightRange }, func(r *dto.CreateProductRequest, v string) error { r.WeightRange = v; return nil }},
+	{"color", func(r *dto.CreateProductRequest) interface{} { return r.Color }, func(r *dto.CreateProductRequest, v string) error { r.Color = v; return nil }},
+	{"size", func(r *dto.CreateProductRequest) interface{} { return r.Size }, func(r *dto.CreateProductRequest, v string) error { r.Size = v; return nil }},
+	{"variety", func(r *dto.CreateProductRequest) interface{} { return r.Variety }, func(r *dto.CreateProductRequest, v string) error { r.Variety = v; return nil }},
+	{"farm_location", func(r *dto.CreateProductRequest) interface{} { return r.FarmLocation }, func(r *dto.CreateProductRequest, v string) error { r.FarmLocation = v; return nil }},
+	{"latitude", func(r *dto.CreateProductRequest) interface{} { return r.Latitude }, floatSetter(func(r *dto.CreateProductRequest) *float64 { return &r.Latitude })},
+	{"longitude", func(r *dto.CreateProductRequest) interface{} { return r.Longitude }, floatSetter(func(r *dto.CreateProductRequest) *float64 { return &r.Longitude })},
+}
+
+// stockColumn is the available_stock column, which products with variants
+// cannot set.
+var stockColumn = columnByName("available_stock")
+
+func columnByName(name string) *productColumn {
+	for i := range productColumns {
+		if productColumns[i].name == name {
+			return &productColumns[i]
+		}
+	}
+	panic("unknown product column " + name)
+}
+
+func floatSetter(field func(*dto.CreateProductRequest) *float64) func(*dto.CreateProductRequest, string) error {
+	return func(r *dto.CreateProductRequest, v string) error {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return errors.New("must be a number")
+		}
+		*field(r) = f
+		return nil
+	}
+}
+
+func boolSetter(field func(*dto.CreateProductRequest) *bool) func(*dto.CreateProductRequest, string) error {
+	return func(r *dto.CreateProductRequest, v string) error {
+		switch strings.ToLower(v) {
+		case "true", "yes", "y", "1":
+			*field(r) = true
+		case "false", "no", "n", "0":
+			*field(r) = false
+		default:
+			return errors.New("must be true or false")
+		}
+		return nil
+	}
+}
+
+// parseDateCell accepts YYYY-MM-DD text or the day serial a spreadsheet
+// stores for a date-formatted cell.
+func parseDateCell(v string) (string, error) {
+	if _, err := time.Parse("2006-01-02", v); err == nil {
+		return v, nil
+	}
+	if serial, err := strconv.ParseFloat(v, 64); err == nil && serial > 0 {
+		epoch := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+		return epoch.AddDate(0, 0, int(serial)).Format("2006-01-02"), nil
+	}
+	return "", errors.New("must be a date in YYYY-MM-DD format")
+}
+
+// ImportProducts creates or updates the farmer's products from a CSV or XLSX
+// file, matching rows to existing products by SKU. Every row is checked
+// before anything is written, and nothing is written unless every row passes;
+// a dry run only reports what would happen. Empty cells leave the existing
+// value of an updated product unchanged.
+func (s *productService) ImportProducts(ctx context.Context, userID uuid.UUID, file io.ReaderAt, size int64, format string, dryRun bool) (*dto.ProductImportResponse, error) {
+	farmer, err := s.farmerRepo.FindByUserID(ctx, userID)
+	if err != nil || farmer == nil {
+		return nil, ErrFarmerNotFound
+	}
+
+	rows, err := readImportRows(file, size, format)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, ErrImportEmpty
+	}
+
+	columns, err := importColumns(rows[0])
+	if err != nil {
+		return nil, err
+	}
+
+	// Skip blank lines but keep the sheet's own row numbers for the report
+	type importRow struct {
+		number int
+		values map[*productColumn]string
+	}
+	var dataRows []importRow
+	var skus []string
+	for i, row := range rows[1:] {
+		values := make(map[*productColumn]string)
+		for index, column := range columns {
+			if index < len(row) {
+				if value := cleanImportCell(row[index]); value != "" {
+					values[column] = value
+				}
+			}
+		}
+		if len(values) == 0 {
+			continue
+		}
+		dataRows = append(dataRows, importRow{number: i + 2, values: values})
+		if sku := values[&productColumns[0]]; sku != "" {
+			skus = append(skus, sku)
+		}
+	}
+	if len(dataRows) == 0 {
+		return nil, ErrImportEmpty
+	}
+	if len(dataRows) > MaxImportRows {
+		return nil, ErrImportTooLarge
+	}
+
+	existing, err := s.productRepo.FindByFarmerSKUs(ctx, farmer.ID, skus)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load existing products: %w", err)
+	}
+	bySKU := make(map[string]*model.Product, len(existing))
+	existingIDs := make([]uuid.UUID, len(existing))
+	for i, product := range existing {
+		bySKU[product.SKU] = product
+		existingIDs[i] = product.ID
+	}
+
+	// Products with variants keep their stock on them
+	variants, err := s.productRepo.FindVariantsByProductIDs(ctx, existingIDs, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load variants: %w", err)
+	}
+	hasVariants := make(map[uuid.UUID]bool, len(variants))
+	for _, variant := range variants {
+		hasVariants[variant.ProductID] = true
+	}
+
+	response := &dto.ProductImportResponse{
+		DryRun: dryRun,
+		Total:  len(dataRows),
+		Rows:   make([]*dto.ProductImportRowResult, 0, len(dataRows)),
+	}
+	var creates []*model.Product
+	var updates []*repository.ProductUpdate
+	seen := make(map[string]int)
+
+	for _, row := range dataRows {
+		sku := row.values[&productColumns[0]]
+		result := &dto.ProductImportRowResult{Row: row.number, SKU: sku}
+		response.Rows = append(response.Rows, result)
+
+		current := bySKU[sku]
+		product, errs := s.buildImportedProduct(farmer.ID, current, row.values)
+		switch {
+		case sku == "":
+			errs = append([]string{"sku: required"}, errs...)
+		case seen[sku] > 0:
+			errs = append([]string{fmt.Sprintf("sku: duplicate of row %d", seen[sku])}, errs...)
+		default:
+			seen[sku] = row.number
+		}
+		if _, ok := row.values[stockColumn]; ok && current != nil && hasVariants[current.ID] {
+			errs = append(errs, "available_stock: "+ErrProductHasVariants.Error())
+		}
+
+		if len(errs) > 0 {
+			result.Action = dto.ImportActionError
+			result.Errors = errs
+			response.Failed++
+			continue
+		}
+
+		if current != nil {
+			result.Action = dto.ImportActionUpdate
+			updates = append(updates, &repository.ProductUpdate{Product: product, Columns: importedColumns(row.values)})
+			response.Updated++
+		} else {
+			result.Action = dto.ImportActionCreate
+			creates = append(creates, product)
+			response.Created++
+		}
+		result.ProductID = &product.ID
+	}
+
+	if dryRun || response.Failed > 0 {
+		return response, nil
+	}
+
+	if err := s.productRepo.SaveImport(ctx, creates, updates); err != nil {
+		return nil, fmt.Errorf("failed to save import: %w", err)
+	}
+	response.Applied = true
+	return response, nil
+}
+
+// importedColumns lists the product columns a row writes. Column names match
+// the database ones; the expiry date follows the harvest date and shelf life.
+func importedColumns(values map[*productColumn]string) []string {
+	columns := []string{"updated_at"}
+	for column := range values {
+		columns = append(columns, column.name)
+		if column.name == "harvest_date" || column.name == "shelf_life" {
+			columns = append(columns, "expiry_date")
+		}
+	}
+	return columns
+}
+
+// buildImportedProduct applies a row on top of the current product, or on
+// an empty request for a new one, and runs the same checks as CreateProduct.
+func (s *productService) buildImportedProduct(farmerID uuid.UUID, current *model.Product, values map[*productColumn]string) (*model.Product, []string) {
+	req := &dto.CreateProductRequest{}
+	if current != nil {
+		req = toCreateProductRequest(current)
+	}
+
+	var errs []string
+	for i := range productColumns {
+		column := &productColumns[i]
+		if value, ok := values[column]; ok {
+			if err := column.set(req, value); err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %v", column.name, err))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	if err := utils.ValidateStruct(req); err != nil {
+		return nil, validationMessages(err)
+	}
+
+	product, err := newProductFromRequest(farmerID, req)
+	if err != nil {
+		return nil, []string{err.Error()}
+	}
+
+	if current != nil {
+		// Keep the listing's identity and marketplace state
+		product.ID = current.ID
+		product.Status = current.Status
+		product.IsFeatured = current.IsFeatured
+		product.Rating = current.Rating
+		product.ReviewCount = current.ReviewCount
+		product.CreatedAt = current.CreatedAt
+		if product.HarvestDate.Format("2006-01-02") == current.HarvestDate.Format("2006-01-02") && product.ShelfLife == current.ShelfLife {
+			product.ExpiryDate = current.ExpiryDate
+		}
+	}
+	return product, nil
+}
+
+// ExportProducts returns the farmer's catalogue in the import layout, so the
+// file can be edited and imported back.
+func (s *productService) ExportProducts(ctx context.Context, userID uuid.UUID, format string) ([]byte, error) {
+	farmer, err := s.farmerRepo.FindByUserID(ctx, userID)
+	if err != nil || farmer == nil {
+		return nil, ErrFarmerNotFound
+	}
+	return s.exportProducts(ctx, farmer.ID, format)
+}
+
+// ExportAllProducts returns the full catalogue with each product's farm.
+func (s *productService) ExportAllProducts(ctx context.Context, format string) ([]byte, error) {
+	return s.exportProducts(ctx, uuid.Nil, format)
+}
+
+func (s *productService) exportProducts(ctx context.Context, farmerID uuid.UUID, format string) ([]byte, error) {
+	if format != FormatCSV && format != FormatXLSX {
+		return nil, ErrUnsupportedFormat
+	}
+
+	products, err := s.productRepo.FindForExport(ctx, farmerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load products: %w", err)
+	}
+
+	// Columns after the import ones are ignored when the file comes back
+	header := make([]interface{}, 0, len(productColumns)+5)
+	for _, column := range productColumns {
+		header = append(header, column.name)
+	}
+	header = append(header, "status", "rating", "id")
+	if farmerID == uuid.Nil {
+		header = append(header, "farmer_id", "farm_name")
+	}
+
+	rows := [][]interface{}{header}
+	for _, product := range products {
+		req := toCreateProductRequest(&product.Product)
+		row := make([]interface{}, 0, len(header))
+		for _, column := range productColumns {
+			row = append(row, column.get(req))
+		}
+		row = append(row, string(product.Status), product.Rating, product.ID.String())
+		if farmerID == uuid.Nil {
+			row = append(row, product.FarmerID.String(), product.FarmName)
+		}
+		rows = append(rows, row)
+	}
+
+	var buf bytes.Buffer
+	if format == FormatXLSX {
+		if err := xlsx.Write(&buf, "Products", rows); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	w := csv.NewWriter(&buf)
+	for _, row := range rows {
+		record := make([]string, len(row))
+		for i, value := range row {
+			record[i] = formatCSVCell(value)
+		}
+		w.Write(record)
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// Helper functions
+func readImportRows(file io.ReaderAt, size int64, format string) ([][]string, error) {
+	switch format {
+	case FormatXLSX:
+		// One more row for the header
+		rows, err := xlsx.ReadRows(file, size, MaxImportRows+1)
+		if errors.Is(err, xlsx.ErrTooManyRows) {
+			return nil, ErrImportTooLarge
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidImportFile, err)
+		}
+		return rows, nil
+	case FormatCSV:
+		r := csv.NewReader(io.NewSectionReader(file, 0, size))
+		r.FieldsPerRecord = -1
+		rows, err := r.ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidImportFile, err)
+		}
+		// Spreadsheet programs often save CSV with a byte order mark
+		if len(rows) > 0 && len(rows[0]) > 0 {
+			rows[0][0] = strings.TrimPrefix(rows[0][0], "\ufeff")
+		}
+		return rows, nil
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
+
+// importColumns maps header positions to known columns. Unknown columns are
+// ignored so exported files, which carry extra read-only columns, import
+// cleanly.
+func importColumns(header []string) (map[int]*productColumn, error) {
+	byName := make(map[string]*productColumn, len(productColumns))
+	for i := range productColumns {
+		byName[productColumns[i].name] = &productColumns[i]
+	}
+
+	columns := make(map[int]*productColumn)
+	found := make(map[string]bool)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		column, ok := byName[name]
+		if !ok {
+			continue
+		}
+		if found[name] {
+			return nil, fmt.Errorf("%w: column %q appears more than once", ErrInvalidImportFile, name)
+		}
+		found[name] = true
+		columns[i] = column
+	}
+
+	if !found["sku"] {
+		return nil, fmt.Errorf("%w: a sku column is required", ErrInvalidImportFile)
+	}
+	return columns, nil
+}
+
+// cleanImportCell trims a cell and undoes the quote that exports put in front
+// of text that a spreadsheet would otherwise run as a formula.
+func cleanImportCell(value string) string {
+	value = strings.TrimSpace(value)
+	if len(value) > 1 && value[0] == '\'' && strings.ContainsRune("=+-@", rune(value[1])) {
+		value = value[1:]
+	}
+	return value
+}
+
+func formatCSVCell(value interface{}) string {
+	switch v := value.(type) {
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case int:
+		return strconv.Itoa(v)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		s := fmt.Sprint(v)
+		if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+			return "'" + s
+		}
+		return s
+	}
+}
+
+// validationMessages turns validator errors into "column: rule" messages
+// using the JSON field names, which are also the column names.
+func validationMessages(err error) []string {
+	var fieldErrors validator.ValidationErrors
+	if !errors.As(err, &fieldErrors) {
+		return []string{err.Error()}
+	}
+
+	requestType := reflect.TypeOf(dto.CreateProductRequest{})
+	messages := make([]string, len(fieldErrors))
+	for i, fe := range fieldErrors {
+		name := fe.Field()
+		if field, ok := requestType.FieldByName(fe.StructField()); ok {
+			name = strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		}
+		if fe.Param() != "" {
+			messages[i] = fmt.Sprintf("%s: must satisfy %s=%s", name, fe.Tag(), fe.Param())
+		} else {
+			messages[i] = fmt.Sprintf("%s: %s", name, fe.Tag())
+		}
+	}
+	return messages
+}
+
+func toCreateProductRequest(product *model.Product) *dto.CreateProductRequest {
+	harvestDate := ""
+	if !product.HarvestDate.IsZero() {
+		harvestDate = product.HarvestDate.Format("2006-01-02")
+	}
+
+	return &dto.CreateProductRequest{
+		SKU:                  product.SKU,
+		Name:                 product.Name,
+		Category:             product.Category,
+		Subcategory:          product.Subcategory,
+		Description:          product.Description,
+		Images:               []string(product.Images),
+		PricePerUnit:         product.PricePerUnit,
+		Unit:                 product.Unit,
+		AvailableStock:       product.AvailableStock,
+		MinOrder:             product.MinOrder,
+		MaxOrder:             product.MaxOrder,
+		QualityGrade:         product.QualityGrade,
+		Organic:              product.Organic,
+		Certified:            product.Certified,
+		CertificationDetails: product.CertificationDetails,
+		HarvestDate:          harvestDate,
+		ShelfLife:            product.ShelfLife,
+		StorageTips:          product.StorageTips,
+		WeightRange:          product.WeightRange,
+		Color:                product.Color,
+		Size:                 product.Size,
+		Variety:              product.Variety,
+		FarmLocation:         product.FarmLocation,
+		Latitude:             product.Latitude,
+		Longitude:            product.Longitude,
+	}
+}
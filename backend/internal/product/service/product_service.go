@@ -3,6 +3,8 @@ package service
 import (
 	"context"
 	"fmt"
+	"html"
+	"io"
 	"log"
 	"math"
 	"strings"
@@ -25,6 +27,7 @@ var (
 	ErrInvalidHarvestDate = errors.New("invalid harvest date")
 	ErrProductNotActive   = errors.New("product is not active")
 	ErrFarmerNotFound     = errors.New("farmer profile not found")
+	ErrDuplicateSKU       = errors.New("a product with this sku already exists")
 )
 
 type ProductService interface {
@@ -38,8 +41,15 @@ type ProductService interface {
 	UpdateProductStatus(ctx context.Context, productID uuid.UUID, userID uuid.UUID, status model.ProductStatus) error
 	GetFeaturedProducts(ctx context.Context, limit int) ([]*dto.ProductResponse, error)
 	GetProductsByCategory(ctx context.Context, category model.ProductCategory, page, pageSize int) (*dto.ProductListResponse, error)
-	SearchProducts(ctx context.Context, query string, page, pageSize int) (*dto.ProductListResponse, error)
-	BulkUpdateExpiredProducts(ctx context.Context) error
+	SearchProducts(ctx context.Context, query string, page, pageSize int) (*dto.ProductSearchResponse, error)
+	BulkUpdateExpiredProducts(ctx context.Context) (int, error)
+	ImportProducts(ctx context.Context, userID uuid.UUID, file io.ReaderAt, size int64, format string, dryRun bool) (*dto.ProductImportResponse, error)
+	ExportProducts(ctx context.Context, userID uuid.UUID, format string) ([]byte, error)
+	ExportAllProducts(ctx context.Context, format string) ([]byte, error)
+	GetVariants(ctx context.Context, productID uuid.UUID) ([]*dto.VariantResponse, error)
+	CreateVariant(ctx context.Context, productID uuid.UUID, userID uuid.UUID, req *dto.CreateVariantRequest) (*dto.VariantResponse, error)
+	UpdateVariant(ctx context.Context, productID, variantID uuid.UUID, userID uuid.UUID, req *dto.UpdateVariantRequest) (*dto.VariantResponse, error)
+	DeleteVariant(ctx context.Context, productID, variantID uuid.UUID, userID uuid.UUID) error
 }
 
 type productService struct {
@@ -71,9 +81,37 @@ func (s *productService) CreateProduct(ctx context.Context, userID uuid.UUID, re
 	// Use the actual farmer ID, not the user ID
 	farmerID := farmer.ID
 
+	if req.SKU != "" {
+		existing, err := s.productRepo.FindByFarmerSKUs(ctx, farmerID, []string{strings.TrimSpace(req.SKU)})
+		if err != nil {
+			return nil, fmt.Errorf("failed to check sku: %w", err)
+		}
+		if len(existing) > 0 {
+			return nil, ErrDuplicateSKU
+		}
+	}
+
+	product, err := newProductFromRequest(farmerID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.productRepo.Create(ctx, product); err != nil {
+		log.Printf("Error creating product: %v", err)
+		return nil, fmt.Errorf("failed to create product: %w", err)
+	}
+
+	log.Printf("Successfully created product with ID: %s for farmer: %s", product.ID, farmerID)
+	return product, nil
+}
+
+// newProductFromRequest builds a draft product from a create request,
+// applying the defaults and the checks the validator cannot express.
+func newProductFromRequest(farmerID uuid.UUID, req *dto.CreateProductRequest) (*model.Product, error) {
 	// Parse harvest date
 	var harvestDate time.Time
 	if req.HarvestDate != "" {
+		var err error
 		harvestDate, err = time.Parse("2006-01-02", req.HarvestDate)
 		if err != nil {
 			return nil, ErrInvalidHarvestDate
@@ -136,6 +174,7 @@ func (s *productService) CreateProduct(ctx context.Context, userID uuid.UUID, re
 
 	product := &model.Product{
 		ID:                   uuid.New(),
+		SKU:                  strings.TrimSpace(req.SKU),
 		FarmerID:             farmerID,
 		Name:                 strings.TrimSpace(req.Name),
 		Category:             req.Category,
@@ -169,13 +208,6 @@ func (s *productService) CreateProduct(ctx context.Context, userID uuid.UUID, re
 		UpdatedAt:            time.Now(),
 		ExpiryDate:           expiryDate,
 	}
-
-	if err := s.productRepo.Create(ctx, product); err != nil {
-		log.Printf("Error creating product: %v", err)
-		return nil, fmt.Errorf("failed to create product: %w", err)
-	}
-
-	log.Printf("Successfully created product with ID: %s for farmer: %s", product.ID, farmerID)
 	return product, nil
 }
 
@@ -188,7 +220,9 @@ func (s *productService) GetProductByID(ctx context.Context, id uuid.UUID) (*dto
 		return nil, ErrProductNotFound
 	}
 
-	return s.toProductResponse(product), nil
+	response := s.toProductResponse(product)
+	s.attachVariants(ctx, []*dto.ProductResponse{response}, true)
+	return response, nil
 }
 
 func (s *productService) GetProductsByFarmer(ctx context.Context, userID uuid.UUID) ([]*dto.ProductResponse, error) {
@@ -207,6 +241,7 @@ func (s *productService) GetProductsByFarmer(ctx context.Context, userID uuid.UU
 	for i, product := range products {
 		responses[i] = s.toProductResponse(product)
 	}
+	s.attachVariants(ctx, responses, false)
 
 	return responses, nil
 }
@@ -308,19 +343,43 @@ func (s *productService) GetAllProducts(ctx context.Context, filters dto.Product
 
 	responses := make([]*dto.ProductResponse, len(products))
 	for i, product := range products {
-		responses[i] = s.toProductResponse(product)
+		responses[i] = s.toProductResponse(&product.Product)
+		if product.Distance != nil {
+			responses[i].Distance = math.Round(*product.Distance*100) / 100
+		}
 	}
+	s.attachVariants(ctx, responses, true)
 
 	pages := int(math.Ceil(float64(total) / float64(filters.PageSize)))
 	hasMore := filters.Page < pages
 
-	return &dto.ProductListResponse{
+	response := &dto.ProductListResponse{
 		Products: responses,
 		Total:    total,
 		Page:     filters.Page,
 		Pages:    pages,
 		HasMore:  hasMore,
-	}, nil
+	}
+
+	if filters.Facets {
+		counts, err := s.productRepo.FindFacetCounts(ctx, filters)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count facets: %w", err)
+		}
+
+		response.Facets = make(map[string][]*dto.FacetValue)
+		for _, count := range counts {
+			if count.Value == "" {
+				continue
+			}
+			response.Facets[count.Facet] = append(response.Facets[count.Facet], &dto.FacetValue{
+				Value: count.Value,
+				Count: count.Count,
+			})
+		}
+	}
+
+	return response, nil
 }
 
 func (s *productService) UpdateStock(ctx context.Context, productID uuid.UUID, userID uuid.UUID, quantity float64) error {
@@ -347,6 +406,14 @@ func (s *productService) UpdateStock(ctx context.Context, productID uuid.UUID, u
 		return errors.New("stock quantity cannot be negative")
 	}
 
+	variants, err := s.productRepo.FindVariantsByProductIDs(ctx, []uuid.UUID{productID}, false)
+	if err != nil {
+		return err
+	}
+	if len(variants) > 0 {
+		return ErrProductHasVariants
+	}
+
 	return s.productRepo.UpdateStock(ctx, productID, quantity)
 }
 
@@ -387,6 +454,7 @@ func (s *productService) GetFeaturedProducts(ctx context.Context, limit int) ([]
 	for i, product := range products {
 		responses[i] = s.toProductResponse(product)
 	}
+	s.attachVariants(ctx, responses, true)
 
 	return responses, nil
 }
@@ -408,6 +476,7 @@ func (s *productService) GetProductsByCategory(ctx context.Context, category mod
 	for i, product := range products {
 		responses[i] = s.toProductResponse(product)
 	}
+	s.attachVariants(ctx, responses, true)
 
 	pages := int(math.Ceil(float64(total) / float64(pageSize)))
 	hasMore := page < pages
@@ -421,25 +490,56 @@ func (s *productService) GetProductsByCategory(ctx context.Context, category mod
 	}, nil
 }
 
-func (s *productService) SearchProducts(ctx context.Context, query string, page, pageSize int) (*dto.ProductListResponse, error) {
-	// For now, use GetAllProducts with search filter
-	// In a real implementation, you'd have a dedicated search method
-	filters := dto.ProductFilterRequest{
-		Page:     page,
-		PageSize: pageSize,
+func (s *productService) SearchProducts(ctx context.Context, query string, page, pageSize int) (*dto.ProductSearchResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 10
 	}
 
-	return s.GetAllProducts(ctx, filters)
+	hits, total, err := s.productRepo.Search(ctx, query, page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*dto.ProductSearchResult, len(hits))
+	products := make([]*dto.ProductResponse, len(hits))
+	for i, hit := range hits {
+		product := s.toProductResponse(&hit.Product)
+		product.FarmName = hit.FarmName
+		results[i] = &dto.ProductSearchResult{
+			ProductResponse:      product,
+			Score:                math.Round(hit.Score*10000) / 10000,
+			NameHighlight:        escapeHighlight(hit.NameHighlight),
+			DescriptionHighlight: escapeHighlight(hit.DescriptionHighlight),
+		}
+		products[i] = product
+	}
+	s.attachVariants(ctx, products, true)
+
+	pages := int(math.Ceil(float64(total) / float64(pageSize)))
+
+	return &dto.ProductSearchResponse{
+		Query:   query,
+		Results: results,
+		Total:   total,
+		Page:    page,
+		Pages:   pages,
+		HasMore: page < pages,
+	}, nil
 }
 
-func (s *productService) BulkUpdateExpiredProducts(ctx context.Context) error {
+// BulkUpdateExpiredProducts marks products past their expiry date as expired
+// and returns how many it changed.
+func (s *productService) BulkUpdateExpiredProducts(ctx context.Context) (int, error) {
 	expiredProducts, err := s.productRepo.GetExpiredProducts(ctx)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	if len(expiredProducts) == 0 {
-		return nil
+		return 0, nil
 	}
 
 	var productIDs []uuid.UUID
@@ -447,10 +547,23 @@ func (s *productService) BulkUpdateExpiredProducts(ctx context.Context) error {
 		productIDs = append(productIDs, product.ID)
 	}
 
-	return s.productRepo.BulkUpdateStatus(ctx, productIDs, model.StatusExpired)
+	if err := s.productRepo.BulkUpdateStatus(ctx, productIDs, model.StatusExpired); err != nil {
+		return 0, err
+	}
+	return len(productIDs), nil
 }
 
 // Helper methods
+
+// escapeHighlight HTML-escapes a search snippet, then wraps the terms the
+// database marked as matches in <mark> tags.
+func escapeHighlight(snippet string) string {
+	escaped := html.EscapeString(snippet)
+	return highlightTags.Replace(escaped)
+}
+
+var highlightTags = strings.NewReplacer(repository.HighlightStart, "<mark>", repository.HighlightStop, "</mark>")
+
 func (s *productService) toProductResponse(product *model.Product) *dto.ProductResponse {
 	// Convert JSONSlice back to []string for response
 	var images []string
@@ -463,6 +576,7 @@ func (s *productService) toProductResponse(product *model.Product) *dto.ProductR
 		FarmerID:             product.FarmerID,
 		FarmerName:           "", // Would come from farmer service
 		FarmName:             "", // Would come from farmer service
+		SKU:                  product.SKU,
 		Name:                 product.Name,
 		Category:             product.Category,
 		Subcategory:          product.Subcategory,
@@ -0,0 +1,246 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	farmerRepo "agro_konnect/internal/farmer/repository"
+	dto "agro_konnect/internal/product/dto"
+	model "agro_konnect/internal/product/model"
+	"agro_konnect/internal/product/repository"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrVariantNotFound     = errors.New("product variant not found")
+	ErrDuplicateVariantSKU = errors.New("another variant of this product already uses this sku")
+	ErrInvalidVariantOrder = errors.New("maximum order must be greater than minimum order")
+	ErrProductHasVariants  = errors.New("stock for this product is managed on its variants")
+)
+
+func (s *productService) GetVariants(ctx context.Context, productID uuid.UUID) ([]*dto.VariantResponse, error) {
+	product, err := s.productRepo.FindByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, ErrProductNotFound
+	}
+
+	variants, err := s.productRepo.FindVariantsByProductIDs(ctx, []uuid.UUID{productID}, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get variants: %w", err)
+	}
+
+	responses := make([]*dto.VariantResponse, len(variants))
+	for i, variant := range variants {
+		responses[i] = toVariantResponse(variant)
+	}
+	return responses, nil
+}
+
+func (s *productService) CreateVariant(ctx context.Context, productID uuid.UUID, userID uuid.UUID, req *dto.CreateVariantRequest) (*dto.VariantResponse, error) {
+	if _, err := s.findOwnedProduct(ctx, productID, userID); err != nil {
+		return nil, err
+	}
+
+	if req.MinOrder == 0 {
+		req.MinOrder = 1
+	}
+	if req.MaxOrder > 0 && req.MaxOrder < req.MinOrder {
+		return nil, ErrInvalidVariantOrder
+	}
+
+	sku := strings.TrimSpace(req.SKU)
+	if err := s.checkVariantSKU(ctx, productID, sku, uuid.Nil); err != nil {
+		return nil, err
+	}
+
+	variant := &model.ProductVariant{
+		ID:             uuid.New(),
+		ProductID:      productID,
+		Name:           strings.TrimSpace(req.Name),
+		SKU:            sku,
+		Barcode:        strings.TrimSpace(req.Barcode),
+		Unit:           strings.TrimSpace(req.Unit),
+		PackSize:       req.PackSize,
+		Price:          req.Price,
+		AvailableStock: req.AvailableStock,
+		MinOrder:       req.MinOrder,
+		MaxOrder:       req.MaxOrder,
+		IsActive:       true,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+
+	if err := s.productRepo.CreateVariant(ctx, variant); err != nil {
+		return nil, fmt.Errorf("failed to create variant: %w", err)
+	}
+	return toVariantResponse(variant), nil
+}
+
+func (s *productService) UpdateVariant(ctx context.Context, productID, variantID uuid.UUID, userID uuid.UUID, req *dto.UpdateVariantRequest) (*dto.VariantResponse, error) {
+	variant, err := s.findOwnedVariant(ctx, productID, variantID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		variant.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.SKU != nil {
+		sku := strings.TrimSpace(*req.SKU)
+		if err := s.checkVariantSKU(ctx, productID, sku, variant.ID); err != nil {
+			return nil, err
+		}
+		variant.SKU = sku
+	}
+	if req.Barcode != nil {
+		variant.Barcode = strings.TrimSpace(*req.Barcode)
+	}
+	if req.Unit != nil {
+		variant.Unit = strings.TrimSpace(*req.Unit)
+	}
+	if req.PackSize != nil {
+		variant.PackSize = *req.PackSize
+	}
+	if req.Price != nil {
+		variant.Price = *req.Price
+	}
+	if req.AvailableStock != nil {
+		variant.AvailableStock = *req.AvailableStock
+	}
+	if req.MinOrder != nil {
+		variant.MinOrder = *req.MinOrder
+	}
+	if req.MaxOrder != nil {
+		variant.MaxOrder = *req.MaxOrder
+	}
+	if req.IsActive != nil {
+		variant.IsActive = *req.IsActive
+	}
+
+	if variant.MaxOrder > 0 && variant.MaxOrder < variant.MinOrder {
+		return nil, ErrInvalidVariantOrder
+	}
+
+	variant.UpdatedAt = time.Now()
+	if err := s.productRepo.UpdateVariant(ctx, variant); err != nil {
+		return nil, fmt.Errorf("failed to update variant: %w", err)
+	}
+	return toVariantResponse(variant), nil
+}
+
+// DeleteVariant removes a variant. Orders keep their own copy of the
+// variant's name, pack size and price, so past orders are unaffected.
+func (s *productService) DeleteVariant(ctx context.Context, productID, variantID uuid.UUID, userID uuid.UUID) error {
+	variant, err := s.findOwnedVariant(ctx, productID, variantID, userID)
+	if err != nil {
+		return err
+	}
+	return s.productRepo.DeleteVariant(ctx, variant)
+}
+
+// Helper methods
+func (s *productService) findOwnedProduct(ctx context.Context, productID uuid.UUID, userID uuid.UUID) (*model.Product, error) {
+	return findOwnedProduct(ctx, s.productRepo, s.farmerRepo, productID, userID)
+}
+
+// findOwnedProduct loads a product that belongs to the user's farm.
+func findOwnedProduct(ctx context.Context, productRepo repository.ProductRepository, farmerRepo farmerRepo.FarmerRepository, productID uuid.UUID, userID uuid.UUID) (*model.Product, error) {
+	farmer, err := farmerRepo.FindByUserID(ctx, userID)
+	if err != nil || farmer == nil {
+		return nil, ErrFarmerNotFound
+	}
+
+	product, err := productRepo.FindByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, ErrProductNotFound
+	}
+	if product.FarmerID != farmer.ID {
+		return nil, ErrUnauthorizedAccess
+	}
+	return product, nil
+}
+
+func (s *productService) findOwnedVariant(ctx context.Context, productID, variantID uuid.UUID, userID uuid.UUID) (*model.ProductVariant, error) {
+	if _, err := s.findOwnedProduct(ctx, productID, userID); err != nil {
+		return nil, err
+	}
+
+	variant, err := s.productRepo.FindVariantByID(ctx, variantID)
+	if err != nil {
+		return nil, err
+	}
+	if variant == nil || variant.ProductID != productID {
+		return nil, ErrVariantNotFound
+	}
+	return variant, nil
+}
+
+func (s *productService) checkVariantSKU(ctx context.Context, productID uuid.UUID, sku string, excludeID uuid.UUID) error {
+	if sku == "" {
+		return nil
+	}
+	exists, err := s.productRepo.VariantSKUExists(ctx, productID, sku, excludeID)
+	if err != nil {
+		return fmt.Errorf("failed to check sku: %w", err)
+	}
+	if exists {
+		return ErrDuplicateVariantSKU
+	}
+	return nil
+}
+
+// attachVariants loads the variants of every listed product in one query.
+// Products still show without them if the lookup fails.
+func (s *productService) attachVariants(ctx context.Context, products []*dto.ProductResponse, activeOnly bool) {
+	if len(products) == 0 {
+		return
+	}
+
+	ids := make([]uuid.UUID, len(products))
+	byID := make(map[uuid.UUID]*dto.ProductResponse, len(products))
+	for i, product := range products {
+		ids[i] = product.ID
+		byID[product.ID] = product
+	}
+
+	variants, err := s.productRepo.FindVariantsByProductIDs(ctx, ids, activeOnly)
+	if err != nil {
+		log.Printf("failed to load product variants: %v", err)
+		return
+	}
+	for _, variant := range variants {
+		if product, ok := byID[variant.ProductID]; ok {
+			product.Variants = append(product.Variants, toVariantResponse(variant))
+		}
+	}
+}
+
+func toVariantResponse(variant *model.ProductVariant) *dto.VariantResponse {
+	return &dto.VariantResponse{
+		ID:             variant.ID,
+		ProductID:      variant.ProductID,
+		Name:           variant.Name,
+		SKU:            variant.SKU,
+		Barcode:        variant.Barcode,
+		Unit:           variant.Unit,
+		PackSize:       variant.PackSize,
+		Price:          variant.Price,
+		PricePerUnit:   math.Round(variant.Price/variant.PackSize*100) / 100,
+		AvailableStock: variant.AvailableStock,
+		MinOrder:       variant.MinOrder,
+		MaxOrder:       variant.MaxOrder,
+		IsActive:       variant.IsActive,
+	}
+}
@@ -0,0 +1,366 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"time"
+
+	farmerRepo "agro_konnect/internal/farmer/repository"
+	dto "agro_konnect/internal/product/dto"
+	model "agro_konnect/internal/product/model"
+	"agro_konnect/internal/product/repository"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrReviewNotFound      = errors.New("review not found")
+	ErrReviewNotEligible   = errors.New("no delivered order containing this product is awaiting a review")
+	ErrReviewAlreadyExists = errors.New("this order has already been reviewed for this product")
+	ErrCannotVoteOwnReview = errors.New("cannot vote on your own review")
+	ErrHelpfulVoteExists   = errors.New("review already marked as helpful")
+	ErrHelpfulVoteNotFound = errors.New("review has not been marked as helpful")
+	ErrInvalidReviewStatus = errors.New("invalid review status")
+)
+
+type ReviewService interface {
+	AddReview(ctx context.Context, productID, userID uuid.UUID, req *dto.AddReviewRequest) (*dto.ProductReviewResponse, error)
+	GetProductReviews(ctx context.Context, productID uuid.UUID, sortBy string, page, pageSize int) (*dto.ReviewListResponse, error)
+	MarkHelpful(ctx context.Context, reviewID, userID uuid.UUID) error
+	RemoveHelpful(ctx context.Context, reviewID, userID uuid.UUID) error
+	ReplyToReview(ctx context.Context, reviewID, userID uuid.UUID, req *dto.ReviewReplyRequest) (*dto.ProductReviewResponse, error)
+	ModerateReview(ctx context.Context, reviewID uuid.UUID, req *dto.ModerateReviewRequest) (*dto.ProductReviewResponse, error)
+	GetReviewsForModeration(ctx context.Context, status model.ReviewStatus, page, pageSize int) (*dto.ReviewListResponse, error)
+}
+
+type reviewService struct {
+	reviewRepo  repository.ReviewRepository
+	productRepo repository.ProductRepository
+	farmerRepo  farmerRepo.FarmerRepository
+}
+
+func NewReviewService(reviewRepo repository.ReviewRepository, productRepo repository.ProductRepository, farmerRepo farmerRepo.FarmerRepository) ReviewService {
+	return &reviewService{
+		reviewRepo:  reviewRepo,
+		productRepo: productRepo,
+		farmerRepo:  farmerRepo,
+	}
+}
+
+// AddReview accepts a review only against a delivered order of the buyer's
+// that contains the product, which is what makes it verified.
+func (s *reviewService) AddReview(ctx context.Context, productID, userID uuid.UUID, req *dto.AddReviewRequest) (*dto.ProductReviewResponse, error) {
+	product, err := s.productRepo.FindByID(ctx, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find product: %w", err)
+	}
+	if product == nil {
+		return nil, ErrProductNotFound
+	}
+
+	orderID, err := s.reviewRepo.FindReviewableOrder(ctx, userID, productID, req.OrderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find delivered order: %w", err)
+	}
+	if orderID == uuid.Nil {
+		// Tell a buyer who names an already-reviewed order why it was refused
+		if req.OrderID != nil {
+			exists, err := s.reviewRepo.ExistsForOrder(ctx, productID, *req.OrderID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to check existing review: %w", err)
+			}
+			if exists {
+				return nil, ErrReviewAlreadyExists
+			}
+		}
+		return nil, ErrReviewNotEligible
+	}
+
+	review := &model.ProductReview{
+		ID:            uuid.New(),
+		ProductID:     productID,
+		BuyerID:       userID,
+		OrderID:       orderID,
+		Rating:        req.Rating,
+		Title:         req.Title,
+		Comment:       req.Comment,
+		Images:        model.JSONSlice(req.Images),
+		QualityRating: req.QualityRating,
+		ValueRating:   req.ValueRating,
+		IsVerified:    true,
+		Status:        model.ReviewStatusPublished,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+
+	if err := s.reviewRepo.Create(ctx, review); err != nil {
+		return nil, fmt.Errorf("failed to create review: %w", err)
+	}
+
+	if err := s.productRepo.UpdateRating(ctx, productID); err != nil {
+		log.Printf("Failed to update rating for product %s: %v", productID, err)
+	}
+
+	names, err := s.reviewRepo.FindBuyerNames(ctx, []uuid.UUID{userID})
+	if err != nil {
+		log.Printf("Failed to load buyer name for review %s: %v", review.ID, err)
+	}
+
+	return toReviewResponse(review, names), nil
+}
+
+func (s *reviewService) GetProductReviews(ctx context.Context, productID uuid.UUID, sortBy string, page, pageSize int) (*dto.ReviewListResponse, error) {
+	page, pageSize = normalizeReviewPage(page, pageSize)
+
+	product, err := s.productRepo.FindByID(ctx, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find product: %w", err)
+	}
+	if product == nil {
+		return nil, ErrProductNotFound
+	}
+
+	reviews, total, err := s.reviewRepo.FindByProduct(ctx, productID, sortBy, page, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reviews: %w", err)
+	}
+
+	buckets, err := s.reviewRepo.GetRatingDistribution(ctx, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rating distribution: %w", err)
+	}
+
+	response, err := s.toReviewListResponse(ctx, reviews, total, page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	response.Summary = toRatingSummary(buckets)
+	return response, nil
+}
+
+func (s *reviewService) MarkHelpful(ctx context.Context, reviewID, userID uuid.UUID) error {
+	review, err := s.findPublishedReview(ctx, reviewID)
+	if err != nil {
+		return err
+	}
+	if review.BuyerID == userID {
+		return ErrCannotVoteOwnReview
+	}
+
+	added, err := s.reviewRepo.AddHelpfulVote(ctx, reviewID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to add helpful vote: %w", err)
+	}
+	if !added {
+		return ErrHelpfulVoteExists
+	}
+	return nil
+}
+
+func (s *reviewService) RemoveHelpful(ctx context.Context, reviewID, userID uuid.UUID) error {
+	if _, err := s.findPublishedReview(ctx, reviewID); err != nil {
+		return err
+	}
+
+	removed, err := s.reviewRepo.RemoveHelpfulVote(ctx, reviewID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to remove helpful vote: %w", err)
+	}
+	if !removed {
+		return ErrHelpfulVoteNotFound
+	}
+	return nil
+}
+
+// ReplyToReview sets or replaces the farmer's public reply. Only the farmer
+// who owns the reviewed product may reply.
+func (s *reviewService) ReplyToReview(ctx context.Context, reviewID, userID uuid.UUID, req *dto.ReviewReplyRequest) (*dto.ProductReviewResponse, error) {
+	review, err := s.reviewRepo.FindByID(ctx, reviewID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find review: %w", err)
+	}
+	if review == nil {
+		return nil, ErrReviewNotFound
+	}
+
+	farmer, err := s.farmerRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find farmer profile: %w", err)
+	}
+	if farmer == nil {
+		return nil, ErrFarmerNotFound
+	}
+
+	product, err := s.productRepo.FindByID(ctx, review.ProductID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find product: %w", err)
+	}
+	if product == nil {
+		return nil, ErrProductNotFound
+	}
+	if product.FarmerID != farmer.ID {
+		return nil, ErrUnauthorizedAccess
+	}
+
+	now := time.Now()
+	if err := s.reviewRepo.UpdateReply(ctx, reviewID, req.Reply, now); err != nil {
+		return nil, fmt.Errorf("failed to save reply: %w", err)
+	}
+	review.FarmerReply = req.Reply
+	review.RepliedAt = &now
+
+	names, err := s.reviewRepo.FindBuyerNames(ctx, []uuid.UUID{review.BuyerID})
+	if err != nil {
+		log.Printf("Failed to load buyer name for review %s: %v", review.ID, err)
+	}
+
+	return toReviewResponse(review, names), nil
+}
+
+// ModerateReview publishes or hides a review and refreshes the ratings it
+// contributes to.
+func (s *reviewService) ModerateReview(ctx context.Context, reviewID uuid.UUID, req *dto.ModerateReviewRequest) (*dto.ProductReviewResponse, error) {
+	review, err := s.reviewRepo.FindByID(ctx, reviewID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find review: %w", err)
+	}
+	if review == nil {
+		return nil, ErrReviewNotFound
+	}
+
+	now := time.Now()
+	if err := s.reviewRepo.UpdateStatus(ctx, reviewID, req.Status, req.Reason, now); err != nil {
+		return nil, fmt.Errorf("failed to update review status: %w", err)
+	}
+
+	if review.Status != req.Status {
+		if err := s.productRepo.UpdateRating(ctx, review.ProductID); err != nil {
+			log.Printf("Failed to update rating for product %s: %v", review.ProductID, err)
+		}
+	}
+
+	review.Status = req.Status
+	review.ModerationReason = req.Reason
+	review.ModeratedAt = &now
+
+	names, err := s.reviewRepo.FindBuyerNames(ctx, []uuid.UUID{review.BuyerID})
+	if err != nil {
+		log.Printf("Failed to load buyer name for review %s: %v", review.ID, err)
+	}
+
+	return toReviewResponse(review, names), nil
+}
+
+func (s *reviewService) GetReviewsForModeration(ctx context.Context, status model.ReviewStatus, page, pageSize int) (*dto.ReviewListResponse, error) {
+	if status != "" && status != model.ReviewStatusPublished && status != model.ReviewStatusHidden {
+		return nil, ErrInvalidReviewStatus
+	}
+	page, pageSize = normalizeReviewPage(page, pageSize)
+
+	reviews, total, err := s.reviewRepo.FindByStatus(ctx, status, page, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reviews: %w", err)
+	}
+
+	return s.toReviewListResponse(ctx, reviews, total, page, pageSize)
+}
+
+// Helper methods
+func (s *reviewService) findPublishedReview(ctx context.Context, reviewID uuid.UUID) (*model.ProductReview, error) {
+	review, err := s.reviewRepo.FindByID(ctx, reviewID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find review: %w", err)
+	}
+	if review == nil || review.Status != model.ReviewStatusPublished {
+		return nil, ErrReviewNotFound
+	}
+	return review, nil
+}
+
+func (s *reviewService) toReviewListResponse(ctx context.Context, reviews []*model.ProductReview, total int64, page, pageSize int) (*dto.ReviewListResponse, error) {
+	buyerIDs := make([]uuid.UUID, 0, len(reviews))
+	for _, review := range reviews {
+		buyerIDs = append(buyerIDs, review.BuyerID)
+	}
+
+	names, err := s.reviewRepo.FindBuyerNames(ctx, buyerIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load buyer names: %w", err)
+	}
+
+	responses := make([]*dto.ProductReviewResponse, len(reviews))
+	for i, review := range reviews {
+		responses[i] = toReviewResponse(review, names)
+	}
+
+	pages := int(math.Ceil(float64(total) / float64(pageSize)))
+
+	return &dto.ReviewListResponse{
+		Reviews: responses,
+		Total:   total,
+		Page:    page,
+		Pages:   pages,
+		HasMore: page < pages,
+	}, nil
+}
+
+func normalizeReviewPage(page, pageSize int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+	return page, pageSize
+}
+
+func toRatingSummary(buckets []repository.RatingBucket) *dto.RatingSummaryResponse {
+	summary := &dto.RatingSummaryResponse{
+		Distribution: make(map[string]int64, 5),
+	}
+	for star := 1; star <= 5; star++ {
+		summary.Distribution[strconv.Itoa(star)] = 0
+	}
+
+	var sum int64
+	for _, bucket := range buckets {
+		summary.Distribution[strconv.Itoa(bucket.Rating)] = bucket.Count
+		summary.Count += bucket.Count
+		sum += int64(bucket.Rating) * bucket.Count
+	}
+	if summary.Count > 0 {
+		summary.Average = math.Round(float64(sum)/float64(summary.Count)*100) / 100
+	}
+	return summary
+}
+
+func toReviewResponse(review *model.ProductReview, buyerNames map[uuid.UUID]string) *dto.ProductReviewResponse {
+	var images []string
+	if review.Images != nil {
+		images = []string(review.Images)
+	}
+
+	return &dto.ProductReviewResponse{
+		ID:            review.ID,
+		ProductID:     review.ProductID,
+		BuyerID:       review.BuyerID,
+		BuyerName:     buyerNames[review.BuyerID],
+		OrderID:       review.OrderID,
+		Rating:        review.Rating,
+		Title:         review.Title,
+		Comment:       review.Comment,
+		Images:        images,
+		QualityRating: review.QualityRating,
+		ValueRating:   review.ValueRating,
+		IsVerified:    review.IsVerified,
+		Helpful:       review.Helpful,
+		Status:        review.Status,
+		FarmerReply:   review.FarmerReply,
+		RepliedAt:     review.RepliedAt,
+		CreatedAt:     review.CreatedAt,
+	}
+}
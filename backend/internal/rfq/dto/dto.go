@@ -0,0 +1,153 @@
+package dto
+
+import (
+	model "agro_konnect/internal/rfq/model"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Request DTOs
+type CreateRFQRequest struct {
+	Title        string  `json:"title" validate:"required,min=3,max=200"`
+	Category     string  `json:"category" validate:"required,oneof=fruits vegetables grains dairy poultry livestock spices herbs"`
+	ProductName  string  `json:"product_name" validate:"max=100"`
+	Quantity     float64 `json:"quantity" validate:"required,gt=0"`
+	Unit         string  `json:"unit" validate:"required,min=1,max=20"`
+	QualityGrade string  `json:"quality_grade" validate:"omitempty,oneof=premium standard economy"`
+	OrganicOnly  bool    `json:"organic_only"`
+	TargetPrice  float64 `json:"target_price" validate:"min=0"`
+	Notes        string  `json:"notes" validate:"max=2000"`
+
+	DeliveryFrom    time.Time `json:"delivery_from" validate:"required"`
+	DeliveryTo      time.Time `json:"delivery_to" validate:"required,gtfield=DeliveryFrom"`
+	DeliveryAddress string    `json:"delivery_address" validate:"required"`
+	DeliveryCity    string    `json:"delivery_city" validate:"required"`
+	DeliveryState   string    `json:"delivery_state" validate:"required"`
+	DeliveryZipCode string    `json:"delivery_zip_code"`
+	Latitude        float64   `json:"latitude" validate:"omitempty,latitude"`
+	Longitude       float64   `json:"longitude" validate:"omitempty,longitude"`
+	RadiusKm        float64   `json:"radius_km" validate:"min=0,max=2000"`
+
+	PaymentMethod string `json:"payment_method" validate:"required,oneof=bank_transfer credit_card digital_wallet upi cash_on_delivery"`
+
+	// Quotes are taken until then; defaults to the start of the delivery window
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type SubmitQuoteRequest struct {
+	ProductID    uuid.UUID  `json:"product_id" validate:"required"`
+	VariantID    *uuid.UUID `json:"variant_id"` // required for products with packaging options
+	UnitPrice    float64    `json:"unit_price" validate:"required,min=0.01"`
+	Quantity     float64    `json:"quantity" validate:"required,gt=0"`
+	DeliveryDate time.Time  `json:"delivery_date" validate:"required"`
+	Notes        string     `json:"notes" validate:"max=2000"`
+}
+
+// CounterOfferRequest changes the terms of a quote. Fields left out keep
+// their current value.
+type CounterOfferRequest struct {
+	UnitPrice    *float64   `json:"unit_price" validate:"omitempty,min=0.01"`
+	Quantity     *float64   `json:"quantity" validate:"omitempty,gt=0"`
+	DeliveryDate *time.Time `json:"delivery_date"`
+	Message      string     `json:"message" validate:"max=2000"`
+}
+
+type RFQFilterRequest struct {
+	Status   model.RFQStatus `form:"status" validate:"omitempty,oneof=open awarded closed expired"`
+	Category string          `form:"category"`
+	Page     int             `form:"page" validate:"omitempty,min=1"`
+	PageSize int             `form:"page_size" validate:"omitempty,min=1,max=100"`
+}
+
+// Response DTOs
+type RFQResponse struct {
+	ID      uuid.UUID `json:"id"`
+	BuyerID uuid.UUID `json:"buyer_id"`
+
+	Title        string  `json:"title"`
+	Category     string  `json:"category"`
+	ProductName  string  `json:"product_name"`
+	Quantity     float64 `json:"quantity"`
+	Unit         string  `json:"unit"`
+	QualityGrade string  `json:"quality_grade"`
+	OrganicOnly  bool    `json:"organic_only"`
+	TargetPrice  float64 `json:"target_price,omitempty"`
+	Notes        string  `json:"notes"`
+
+	DeliveryFrom    time.Time `json:"delivery_from"`
+	DeliveryTo      time.Time `json:"delivery_to"`
+	DeliveryAddress string    `json:"delivery_address"`
+	DeliveryCity    string    `json:"delivery_city"`
+	DeliveryState   string    `json:"delivery_state"`
+	DeliveryZipCode string    `json:"delivery_zip_code"`
+	Latitude        float64   `json:"latitude,omitempty"`
+	Longitude       float64   `json:"longitude,omitempty"`
+	RadiusKm        float64   `json:"radius_km,omitempty"`
+
+	PaymentMethod  string          `json:"payment_method"`
+	Status         model.RFQStatus `json:"status"`
+	ExpiresAt      time.Time       `json:"expires_at"`
+	AwardedQuoteID *uuid.UUID      `json:"awarded_quote_id,omitempty"`
+	OrderID        *uuid.UUID      `json:"order_id,omitempty"`
+	QuoteCount     int             `json:"quote_count"`
+
+	// The buyer sees every quote, a farmer only their own
+	Quotes []*QuoteResponse `json:"quotes,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type QuoteResponse struct {
+	ID        uuid.UUID  `json:"id"`
+	RFQID     uuid.UUID  `json:"rfq_id"`
+	FarmerID  uuid.UUID  `json:"farmer_id"`
+	ProductID uuid.UUID  `json:"product_id"`
+	VariantID *uuid.UUID `json:"variant_id,omitempty"`
+
+	UnitPrice    float64   `json:"unit_price"`
+	Quantity     float64   `json:"quantity"`
+	TotalPrice   float64   `json:"total_price"`
+	DeliveryDate time.Time `json:"delivery_date"`
+	Notes        string    `json:"notes"`
+
+	Status      model.QuoteStatus `json:"status"`
+	LastOfferBy model.Party       `json:"last_offer_by"`
+
+	Offers []*OfferResponse `json:"offers"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type OfferResponse struct {
+	OfferedBy    model.Party `json:"offered_by"`
+	UnitPrice    float64     `json:"unit_price"`
+	Quantity     float64     `json:"quantity"`
+	DeliveryDate time.Time   `json:"delivery_date"`
+	Message      string      `json:"message,omitempty"`
+	CreatedAt    time.Time   `json:"created_at"`
+}
+
+// AcceptQuoteResponse is the accepted quote and the order placed for it.
+type AcceptQuoteResponse struct {
+	Quote       *QuoteResponse `json:"quote"`
+	OrderID     uuid.UUID      `json:"order_id"`
+	OrderNumber string         `json:"order_number"`
+}
+
+type RFQListResponse struct {
+	RFQs    []*RFQResponse `json:"rfqs"`
+	Total   int64          `json:"total"`
+	Page    int            `json:"page"`
+	Pages   int            `json:"pages"`
+	HasMore bool           `json:"has_more"`
+}
+
+type QuoteListResponse struct {
+	Quotes  []*QuoteResponse `json:"quotes"`
+	Total   int64            `json:"total"`
+	Page    int              `json:"page"`
+	Pages   int              `json:"pages"`
+	HasMore bool             `json:"has_more"`
+}
@@ -0,0 +1,471 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"agro_konnect/internal/auth/middleware"
+	orderService "agro_konnect/internal/order/service"
+	dto "agro_konnect/internal/rfq/dto"
+	"agro_konnect/internal/rfq/service"
+	"agro_konnect/internal/rfq/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type RFQHandler struct {
+	rfqService service.RFQService
+}
+
+func NewRFQHandler(rfqService service.RFQService) *RFQHandler {
+	return &RFQHandler{rfqService: rfqService}
+}
+
+// CreateRFQ posts a request for quotation
+// @Summary Create RFQ
+// @Description Post a requirement (category, quantity, grade, delivery window and place) for farmers to quote on. Farmers with matching products nearby are notified
+// @Tags rfqs
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.CreateRFQRequest true "RFQ data"
+// @Success 201 {object} utils.SuccessResponse{data=dto.RFQResponse} "RFQ created successfully"
+// @Failure 400 {object} utils.ErrorResponse "Invalid input data"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 403 {object} utils.ErrorResponse "Forbidden"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /rfqs [post]
+func (h *RFQHandler) CreateRFQ(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	var req dto.CreateRFQRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid input data: "+err.Error())
+		return
+	}
+
+	if err := utils.ValidateStruct(req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	rfq, err := h.rfqService.CreateRFQ(c.Request.Context(), userID, &req)
+	if err != nil {
+		respondWithRFQError(c, err, "Failed to create RFQ")
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusCreated, "RFQ created successfully", rfq)
+}
+
+// GetMyRFQs lists the buyer's RFQs
+// @Summary Get my RFQs
+// @Description List the authenticated buyer's RFQs with their number of quotes
+// @Tags rfqs
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "RFQ status" Enums(open, awarded, closed, expired)
+// @Param category query string false "Product category"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Success 200 {object} utils.SuccessResponse{data=dto.RFQListResponse} "RFQs retrieved successfully"
+// @Failure 400 {object} utils.ErrorResponse "Invalid query parameters"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /rfqs/me [get]
+func (h *RFQHandler) GetMyRFQs(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	filters, ok := bindFilters(c)
+	if !ok {
+		return
+	}
+
+	rfqs, err := h.rfqService.GetMyRFQs(c.Request.Context(), userID, filters)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to retrieve RFQs")
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, "RFQs retrieved successfully", rfqs)
+}
+
+// GetOpenRFQs lists RFQs farmers can quote on
+// @Summary Get open RFQs
+// @Description List RFQs still taking quotes, those closing soonest first
+// @Tags rfqs
+// @Produce json
+// @Security BearerAuth
+// @Param category query string false "Product category"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Success 200 {object} utils.SuccessResponse{data=dto.RFQListResponse} "RFQs retrieved successfully"
+// @Failure 400 {object} utils.ErrorResponse "Invalid query parameters"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /rfqs/open [get]
+func (h *RFQHandler) GetOpenRFQs(c *gin.Context) {
+	filters, ok := bindFilters(c)
+	if !ok {
+		return
+	}
+
+	rfqs, err := h.rfqService.GetOpenRFQs(c.Request.Context(), filters)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to retrieve RFQs")
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, "RFQs retrieved successfully", rfqs)
+}
+
+// GetRFQ gets an RFQ with its quotes
+// @Summary Get RFQ
+// @Description Get an RFQ. The buyer sees every quote with its negotiation history; a farmer sees only their own
+// @Tags rfqs
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "RFQ ID"
+// @Success 200 {object} utils.SuccessResponse{data=dto.RFQResponse} "RFQ retrieved successfully"
+// @Failure 400 {object} utils.ErrorResponse "Invalid RFQ ID"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 403 {object} utils.ErrorResponse "Forbidden"
+// @Failure 404 {object} utils.ErrorResponse "RFQ not found"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /rfqs/{id} [get]
+func (h *RFQHandler) GetRFQ(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+	userRole := fmt.Sprintf("%v", c.MustGet(middleware.UserRoleContextKey))
+
+	rfqID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid RFQ ID")
+		return
+	}
+
+	rfq, err := h.rfqService.GetRFQ(c.Request.Context(), rfqID, userID, userRole)
+	if err != nil {
+		respondWithRFQError(c, err, "Failed to retrieve RFQ")
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, "RFQ retrieved successfully", rfq)
+}
+
+// CloseRFQ withdraws an RFQ
+// @Summary Close RFQ
+// @Description Stop taking quotes on one of the buyer's RFQs without accepting any
+// @Tags rfqs
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "RFQ ID"
+// @Success 200 {object} utils.SuccessResponse "RFQ closed successfully"
+// @Failure 400 {object} utils.ErrorResponse "Invalid RFQ ID"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 403 {object} utils.ErrorResponse "Forbidden"
+// @Failure 404 {object} utils.ErrorResponse "RFQ not found"
+// @Failure 409 {object} utils.ErrorResponse "RFQ is not open"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /rfqs/{id}/close [post]
+func (h *RFQHandler) CloseRFQ(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	rfqID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid RFQ ID")
+		return
+	}
+
+	if err := h.rfqService.CloseRFQ(c.Request.Context(), rfqID, userID); err != nil {
+		respondWithRFQError(c, err, "Failed to close RFQ")
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, "RFQ closed successfully", nil)
+}
+
+// SubmitQuote quotes on an RFQ
+// @Summary Submit quote
+// @Description Offer one of the farmer's products against an open RFQ. One quote per farmer and RFQ; change it with a counter-offer
+// @Tags rfqs
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "RFQ ID"
+// @Param request body dto.SubmitQuoteRequest true "Quote data"
+// @Success 201 {object} utils.SuccessResponse{data=dto.QuoteResponse} "Quote submitted successfully"
+// @Failure 400 {object} utils.ErrorResponse "Invalid input data"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 404 {object} utils.ErrorResponse "RFQ not found"
+// @Failure 409 {object} utils.ErrorResponse "Already quoted or RFQ not open"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /rfqs/{id}/quotes [post]
+func (h *RFQHandler) SubmitQuote(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	rfqID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid RFQ ID")
+		return
+	}
+
+	var req dto.SubmitQuoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid input data: "+err.Error())
+		return
+	}
+
+	if err := utils.ValidateStruct(req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	quote, err := h.rfqService.SubmitQuote(c.Request.Context(), rfqID, userID, &req)
+	if err != nil {
+		respondWithRFQError(c, err, "Failed to submit quote")
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusCreated, "Quote submitted successfully", quote)
+}
+
+// GetMyQuotes lists the farmer's quotes
+// @Summary Get my quotes
+// @Description List the authenticated farmer's quotes with their negotiation history, most recently active first
+// @Tags rfqs
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Success 200 {object} utils.SuccessResponse{data=dto.QuoteListResponse} "Quotes retrieved successfully"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 404 {object} utils.ErrorResponse "Farmer profile not found"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /rfqs/quotes/me [get]
+func (h *RFQHandler) GetMyQuotes(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	quotes, err := h.rfqService.GetMyQuotes(c.Request.Context(), userID, page, pageSize)
+	if err != nil {
+		respondWithRFQError(c, err, "Failed to retrieve quotes")
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, "Quotes retrieved successfully", quotes)
+}
+
+// CounterOffer answers the last offer on a quote with new terms
+// @Summary Counter-offer
+// @Description Propose new price, quantity or delivery date on a quote. Buyer and farmer take turns; only the party that did not make the last offer can counter
+// @Tags rfqs
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "RFQ ID"
+// @Param quoteId path string true "Quote ID"
+// @Param request body dto.CounterOfferRequest true "New terms"
+// @Success 200 {object} utils.SuccessResponse{data=dto.QuoteResponse} "Counter-offer sent successfully"
+// @Failure 400 {object} utils.ErrorResponse "Invalid input data"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 403 {object} utils.ErrorResponse "Forbidden"
+// @Failure 404 {object} utils.ErrorResponse "Quote not found"
+// @Failure 409 {object} utils.ErrorResponse "Not your turn or quote closed"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /rfqs/{id}/quotes/{quoteId}/counter [post]
+func (h *RFQHandler) CounterOffer(c *gin.Context) {
+	userID, rfqID, quoteID, ok := quoteParams(c)
+	if !ok {
+		return
+	}
+
+	var req dto.CounterOfferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid input data: "+err.Error())
+		return
+	}
+
+	if err := utils.ValidateStruct(req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	quote, err := h.rfqService.CounterOffer(c.Request.Context(), rfqID, quoteID, userID, &req)
+	if err != nil {
+		respondWithRFQError(c, err, "Failed to send counter-offer")
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, "Counter-offer sent successfully", quote)
+}
+
+// AcceptQuote accepts the last offer on a quote and places the order
+// @Summary Accept quote
+// @Description Accept the other party's last offer. An order for the buyer is placed at the negotiated price and the RFQ's other quotes are turned down
+// @Tags rfqs
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "RFQ ID"
+// @Param quoteId path string true "Quote ID"
+// @Success 201 {object} utils.SuccessResponse{data=dto.AcceptQuoteResponse} "Quote accepted and order placed"
+// @Failure 400 {object} utils.ErrorResponse "Order could not be placed"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 403 {object} utils.ErrorResponse "Forbidden"
+// @Failure 404 {object} utils.ErrorResponse "Quote not found"
+// @Failure 409 {object} utils.ErrorResponse "Not your turn, quote closed or RFQ already awarded"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /rfqs/{id}/quotes/{quoteId}/accept [post]
+func (h *RFQHandler) AcceptQuote(c *gin.Context) {
+	userID, rfqID, quoteID, ok := quoteParams(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.rfqService.AcceptQuote(c.Request.Context(), rfqID, quoteID, userID)
+	if err != nil {
+		respondWithRFQError(c, err, "Failed to accept quote")
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusCreated, "Quote accepted and order placed", result)
+}
+
+// RejectQuote ends the negotiation on a quote
+// @Summary Reject or withdraw quote
+// @Description The buyer rejects a quote, or the farmer withdraws their own
+// @Tags rfqs
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "RFQ ID"
+// @Param quoteId path string true "Quote ID"
+// @Success 200 {object} utils.SuccessResponse "Quote closed successfully"
+// @Failure 400 {object} utils.ErrorResponse "Invalid ID"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 403 {object} utils.ErrorResponse "Forbidden"
+// @Failure 404 {object} utils.ErrorResponse "Quote not found"
+// @Failure 409 {object} utils.ErrorResponse "Quote already closed"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /rfqs/{id}/quotes/{quoteId}/reject [post]
+func (h *RFQHandler) RejectQuote(c *gin.Context) {
+	userID, rfqID, quoteID, ok := quoteParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.rfqService.RejectQuote(c.Request.Context(), rfqID, quoteID, userID); err != nil {
+		respondWithRFQError(c, err, "Failed to close quote")
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, "Quote closed successfully", nil)
+}
+
+func bindFilters(c *gin.Context) (dto.RFQFilterRequest, bool) {
+	var filters dto.RFQFilterRequest
+	if err := c.ShouldBindQuery(&filters); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid query parameters: "+err.Error())
+		return filters, false
+	}
+	if err := utils.ValidateStruct(filters); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		return filters, false
+	}
+	return filters, true
+}
+
+func quoteParams(c *gin.Context) (userID, rfqID, quoteID uuid.UUID, ok bool) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	rfqID, err = uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid RFQ ID")
+		return
+	}
+	quoteID, err = uuid.Parse(c.Param("quoteId"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid quote ID")
+		return
+	}
+	return userID, rfqID, quoteID, true
+}
+
+func respondWithRFQError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrRFQNotFound), errors.Is(err, service.ErrQuoteNotFound):
+		utils.RespondWithError(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrFarmerNotFound):
+		utils.RespondWithError(c, http.StatusNotFound, "Farmer profile not found. Please create a farmer profile first")
+	case errors.Is(err, service.ErrUnauthorizedAccess):
+		utils.RespondWithError(c, http.StatusForbidden, err.Error())
+	case errors.Is(err, service.ErrRFQNotOpen),
+		errors.Is(err, service.ErrQuoteExists),
+		errors.Is(err, service.ErrQuoteNotNegotiable),
+		errors.Is(err, service.ErrNotYourTurn):
+		utils.RespondWithError(c, http.StatusConflict, err.Error())
+	case errors.Is(err, service.ErrInvalidExpiry),
+		errors.Is(err, service.ErrInvalidDeliveryDate),
+		errors.Is(err, service.ErrInvalidQuoteProduct),
+		errors.Is(err, service.ErrVariantRequired):
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, orderService.ErrInsufficientStock),
+		errors.Is(err, orderService.ErrInvalidVariant):
+		// The RFQ is reopened so the parties can adjust the quote
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+	default:
+		utils.RespondWithError(c, http.StatusInternalServerError, fallback)
+	}
+}
+
+// GetUserIDFromContext extracts user ID from Gin context
+func GetUserIDFromContext(c *gin.Context) (uuid.UUID, error) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		return uuid.Nil, errors.New("user ID not found in context")
+	}
+
+	switch v := userID.(type) {
+	case uuid.UUID:
+		return v, nil
+	case string:
+		parsedUUID, err := uuid.Parse(v)
+		if err != nil {
+			return uuid.Nil, fmt.Errorf("invalid user ID format: %w", err)
+		}
+		return parsedUUID, nil
+	default:
+		return uuid.Nil, errors.New("invalid user ID type in context")
+	}
+}
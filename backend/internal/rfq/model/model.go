@@ -0,0 +1,136 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type RFQStatus string
+
+const (
+	RFQStatusOpen    RFQStatus = "open"
+	RFQStatusAwarded RFQStatus = "awarded" // a quote was accepted and ordered
+	RFQStatusClosed  RFQStatus = "closed"  // withdrawn by the buyer
+	RFQStatusExpired RFQStatus = "expired"
+)
+
+type QuoteStatus string
+
+const (
+	QuoteStatusPending   QuoteStatus = "pending"   // offer from the farmer awaits the buyer
+	QuoteStatusCountered QuoteStatus = "countered" // counter-offer from the buyer awaits the farmer
+	QuoteStatusAccepted  QuoteStatus = "accepted"
+	QuoteStatusRejected  QuoteStatus = "rejected"
+	QuoteStatusWithdrawn QuoteStatus = "withdrawn"
+)
+
+type Party string
+
+const (
+	PartyBuyer  Party = "buyer"
+	PartyFarmer Party = "farmer"
+)
+
+// RFQ is a buyer's request for quotation: what they need, how much and when.
+// Farmers with matching products answer it with quotes.
+type RFQ struct {
+	ID      uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	BuyerID uuid.UUID `gorm:"type:uuid;not null;index" json:"buyer_id"` // buyer's user ID
+
+	Title        string  `gorm:"not null" json:"title"`
+	Category     string  `gorm:"type:varchar(50);not null;index" json:"category"`
+	ProductName  string  `json:"product_name"` // e.g. "Roma tomatoes"; any product of the category when empty
+	Quantity     float64 `gorm:"type:decimal(10,2);not null" json:"quantity"`
+	Unit         string  `gorm:"not null" json:"unit"`
+	QualityGrade string  `gorm:"type:varchar(20)" json:"quality_grade"` // any grade when empty
+	OrganicOnly  bool    `gorm:"default:false" json:"organic_only"`
+	TargetPrice  float64 `gorm:"type:decimal(10,2)" json:"target_price"` // per unit, optional
+	Notes        string  `gorm:"type:text" json:"notes"`
+
+	// Delivery window and place
+	DeliveryFrom    time.Time `gorm:"not null" json:"delivery_from"`
+	DeliveryTo      time.Time `gorm:"not null" json:"delivery_to"`
+	DeliveryAddress string    `gorm:"not null" json:"delivery_address"`
+	DeliveryCity    string    `gorm:"not null" json:"delivery_city"`
+	DeliveryState   string    `gorm:"not null" json:"delivery_state"`
+	DeliveryZipCode string    `json:"delivery_zip_code"`
+	Latitude        float64   `json:"latitude"`
+	Longitude       float64   `json:"longitude"`
+	RadiusKm        float64   `json:"radius_km"` // farmers further away are not notified; 0 for anywhere
+
+	PaymentMethod string `gorm:"type:varchar(30);not null" json:"payment_method"`
+
+	Status         RFQStatus  `gorm:"type:varchar(20);default:'open';index" json:"status"`
+	ExpiresAt      time.Time  `gorm:"not null;index" json:"expires_at"` // quotes are accepted until then
+	AwardedQuoteID *uuid.UUID `gorm:"type:uuid" json:"awarded_quote_id"`
+	OrderID        *uuid.UUID `gorm:"type:uuid" json:"order_id"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Quotes []RFQQuote `gorm:"foreignKey:RFQID" json:"quotes,omitempty"`
+}
+
+// IsOpen reports whether the RFQ still takes quotes and counter-offers.
+func (r *RFQ) IsOpen(at time.Time) bool {
+	return r.Status == RFQStatusOpen && at.Before(r.ExpiresAt)
+}
+
+// RFQQuote is a farmer's offer against an RFQ. The current terms are kept on
+// the quote; every offer and counter-offer is also kept in Offers. The party
+// that did not make the last offer is the one that can accept it.
+type RFQQuote struct {
+	ID           uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
+	RFQID        uuid.UUID  `gorm:"type:uuid;not null;index;uniqueIndex:idx_rfq_quote_farmer" json:"rfq_id"`
+	FarmerID     uuid.UUID  `gorm:"type:uuid;not null;index;uniqueIndex:idx_rfq_quote_farmer" json:"farmer_id"`
+	FarmerUserID uuid.UUID  `gorm:"type:uuid;not null" json:"farmer_user_id"`
+	ProductID    uuid.UUID  `gorm:"type:uuid;not null" json:"product_id"`
+	VariantID    *uuid.UUID `gorm:"type:uuid" json:"variant_id"`
+
+	// Current terms
+	UnitPrice    float64   `gorm:"type:decimal(10,2);not null" json:"unit_price"`
+	Quantity     float64   `gorm:"type:decimal(10,2);not null" json:"quantity"`
+	DeliveryDate time.Time `gorm:"not null" json:"delivery_date"`
+	Notes        string    `gorm:"type:text" json:"notes"`
+
+	Status      QuoteStatus `gorm:"type:varchar(20);not null;index" json:"status"`
+	LastOfferBy Party       `gorm:"type:varchar(10);not null" json:"last_offer_by"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Offers []QuoteOffer `gorm:"foreignKey:QuoteID" json:"offers,omitempty"`
+}
+
+// IsNegotiable reports whether the quote is still waiting on an answer.
+func (q *RFQQuote) IsNegotiable() bool {
+	return q.Status == QuoteStatusPending || q.Status == QuoteStatusCountered
+}
+
+// QuoteOffer is one round of negotiation on a quote.
+type QuoteOffer struct {
+	ID           uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	QuoteID      uuid.UUID `gorm:"type:uuid;not null;index" json:"quote_id"`
+	OfferedBy    Party     `gorm:"type:varchar(10);not null" json:"offered_by"`
+	UnitPrice    float64   `gorm:"type:decimal(10,2);not null" json:"unit_price"`
+	Quantity     float64   `gorm:"type:decimal(10,2);not null" json:"quantity"`
+	DeliveryDate time.Time `gorm:"not null" json:"delivery_date"`
+	Message      string    `gorm:"type:text" json:"message"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for RFQ
+func (RFQ) TableName() string {
+	return "rfqs"
+}
+
+// TableName specifies the table name for RFQQuote
+func (RFQQuote) TableName() string {
+	return "rfq_quotes"
+}
+
+// TableName specifies the table name for QuoteOffer
+func (QuoteOffer) TableName() string {
+	return "rfq_quote_offers"
+}
@@ -0,0 +1,316 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"agro_konnect/internal/common"
+	dto "agro_konnect/internal/rfq/dto"
+	model "agro_konnect/internal/rfq/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrQuoteChanged is returned when a quote being accepted was withdrawn,
+// rejected or re-offered after it was read.
+var ErrQuoteChanged = errors.New("quote changed while it was being accepted")
+
+// MatchingFarmer is a farmer with active products that fit an RFQ.
+type MatchingFarmer struct {
+	FarmerID  uuid.UUID
+	UserID    uuid.UUID
+	Latitude  float64
+	Longitude float64
+}
+
+type RFQRepository interface {
+	Create(ctx context.Context, rfq *model.RFQ) error
+	FindByID(ctx context.Context, id uuid.UUID) (*model.RFQ, error)
+	FindByBuyerID(ctx context.Context, buyerID uuid.UUID, filters dto.RFQFilterRequest) ([]*model.RFQ, int64, error)
+	FindOpen(ctx context.Context, filters dto.RFQFilterRequest, now time.Time) ([]*model.RFQ, int64, error)
+	Close(ctx context.Context, id uuid.UUID) (bool, error)
+	ClaimAward(ctx context.Context, rfqID uuid.UUID, quote *model.RFQQuote, now time.Time) (bool, error)
+	ReleaseAward(ctx context.Context, rfqID uuid.UUID, quote *model.RFQQuote) error
+	CompleteAward(ctx context.Context, rfqID, quoteID, orderID uuid.UUID) error
+	ExpireOpen(ctx context.Context, now time.Time) (int64, error)
+	FindMatchingFarmers(ctx context.Context, rfq *model.RFQ) ([]MatchingFarmer, error)
+
+	CreateQuote(ctx context.Context, quote *model.RFQQuote, offer *model.QuoteOffer) error
+	FindQuoteByID(ctx context.Context, id uuid.UUID) (*model.RFQQuote, error)
+	FindQuoteByFarmer(ctx context.Context, rfqID, farmerID uuid.UUID) (*model.RFQQuote, error)
+	FindQuotesByFarmerID(ctx context.Context, farmerID uuid.UUID, page, pageSize int) ([]*model.RFQQuote, int64, error)
+	SaveCounterOffer(ctx context.Context, quote *model.RFQQuote, from model.QuoteStatus, offer *model.QuoteOffer) (bool, error)
+	UpdateQuoteStatus(ctx context.Context, id uuid.UUID, from []model.QuoteStatus, to model.QuoteStatus) (bool, error)
+
+	CreateNotifications(ctx context.Context, notifications []*common.Notification) error
+}
+
+type rfqRepository struct {
+	db *gorm.DB
+}
+
+func NewRFQRepository(db *gorm.DB) RFQRepository {
+	return &rfqRepository{db: db}
+}
+
+func (r *rfqRepository) Create(ctx context.Context, rfq *model.RFQ) error {
+	return r.db.WithContext(ctx).Create(rfq).Error
+}
+
+// FindByID loads an RFQ with its quotes and their negotiation history.
+func (r *rfqRepository) FindByID(ctx context.Context, id uuid.UUID) (*model.RFQ, error) {
+	var rfq model.RFQ
+	err := r.db.WithContext(ctx).
+		Preload("Quotes", func(db *gorm.DB) *gorm.DB {
+			return db.Order("unit_price ASC")
+		}).
+		Preload("Quotes.Offers", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at ASC")
+		}).
+		Where("id = ?", id).
+		First(&rfq).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &rfq, err
+}
+
+func (r *rfqRepository) FindByBuyerID(ctx context.Context, buyerID uuid.UUID, filters dto.RFQFilterRequest) ([]*model.RFQ, int64, error) {
+	query := r.db.WithContext(ctx).Model(&model.RFQ{}).Where("buyer_id = ?", buyerID)
+	if filters.Status != "" {
+		query = query.Where("status = ?", filters.Status)
+	}
+	if filters.Category != "" {
+		query = query.Where("category = ?", filters.Category)
+	}
+	return r.paginate(query, filters.Page, filters.PageSize)
+}
+
+// FindOpen lists RFQs that still take quotes, those expiring soonest first.
+func (r *rfqRepository) FindOpen(ctx context.Context, filters dto.RFQFilterRequest, now time.Time) ([]*model.RFQ, int64, error) {
+	query := r.db.WithContext(ctx).Model(&model.RFQ{}).
+		Where("status = ? AND expires_at > ?", model.RFQStatusOpen, now)
+	if filters.Category != "" {
+		query = query.Where("category = ?", filters.Category)
+	}
+	return r.paginate(query.Order("expires_at ASC"), filters.Page, filters.PageSize)
+}
+
+func (r *rfqRepository) paginate(query *gorm.DB, page, pageSize int) ([]*model.RFQ, int64, error) {
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var rfqs []*model.RFQ
+	err := query.
+		Preload("Quotes").
+		Order("created_at DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&rfqs).Error
+	return rfqs, total, err
+}
+
+func (r *rfqRepository) Close(ctx context.Context, id uuid.UUID) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&model.RFQ{}).
+		Where("id = ? AND status = ?", id, model.RFQStatusOpen).
+		Updates(map[string]interface{}{"status": model.RFQStatusClosed, "updated_at": time.Now()})
+	return result.RowsAffected > 0, result.Error
+}
+
+// ClaimAward marks the RFQ awarded to a quote if it is still open, so that
+// two quotes can never both be accepted. It reports false if the RFQ is no
+// longer open. The quote is accepted in the same transaction, but only if it
+// is still the offer that was read: still under negotiation, last offered
+// by the same party and not re-offered since. Otherwise nothing is awarded
+// and ErrQuoteChanged is returned.
+func (r *rfqRepository) ClaimAward(ctx context.Context, rfqID uuid.UUID, quote *model.RFQQuote, now time.Time) (bool, error) {
+	claimed := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.RFQ{}).
+			Where("id = ? AND status = ? AND expires_at > ?", rfqID, model.RFQStatusOpen, now).
+			Updates(map[string]interface{}{
+				"status":           model.RFQStatusAwarded,
+				"awarded_quote_id": quote.ID,
+				"updated_at":       time.Now(),
+			})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		result = tx.Model(&model.RFQQuote{}).
+			Where("id = ? AND rfq_id = ? AND status IN ? AND last_offer_by = ? AND updated_at = ?",
+				quote.ID, rfqID, []model.QuoteStatus{model.QuoteStatusPending, model.QuoteStatusCountered},
+				quote.LastOfferBy, quote.UpdatedAt).
+			Updates(map[string]interface{}{"status": model.QuoteStatusAccepted, "updated_at": time.Now()})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrQuoteChanged
+		}
+		claimed = true
+		return nil
+	})
+	return claimed && err == nil, err
+}
+
+// ReleaseAward reopens an RFQ whose award could not be turned into an order
+// and puts the quote back in the status it was accepted from.
+func (r *rfqRepository) ReleaseAward(ctx context.Context, rfqID uuid.UUID, quote *model.RFQQuote) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.RFQ{}).
+			Where("id = ? AND status = ? AND order_id IS NULL", rfqID, model.RFQStatusAwarded).
+			Updates(map[string]interface{}{
+				"status":           model.RFQStatusOpen,
+				"awarded_quote_id": nil,
+				"updated_at":       time.Now(),
+			})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		return tx.Model(&model.RFQQuote{}).
+			Where("id = ? AND status = ?", quote.ID, model.QuoteStatusAccepted).
+			Updates(map[string]interface{}{"status": quote.Status, "updated_at": time.Now()}).Error
+	})
+}
+
+// CompleteAward records the order placed for the accepted quote and turns
+// down every other quote still under negotiation. The quote itself was
+// accepted when the award was claimed.
+func (r *rfqRepository) CompleteAward(ctx context.Context, rfqID, quoteID, orderID uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Model(&model.RFQ{}).
+			Where("id = ?", rfqID).
+			Updates(map[string]interface{}{"order_id": orderID, "updated_at": now}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.RFQQuote{}).
+			Where("rfq_id = ? AND id <> ? AND status IN ?", rfqID, quoteID,
+				[]model.QuoteStatus{model.QuoteStatusPending, model.QuoteStatusCountered}).
+			Updates(map[string]interface{}{"status": model.QuoteStatusRejected, "updated_at": now}).Error
+	})
+}
+
+// ExpireOpen closes RFQs whose quoting period has ended.
+func (r *rfqRepository) ExpireOpen(ctx context.Context, now time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&model.RFQ{}).
+		Where("status = ? AND expires_at <= ?", model.RFQStatusOpen, now).
+		Updates(map[string]interface{}{"status": model.RFQStatusExpired, "updated_at": now})
+	return result.RowsAffected, result.Error
+}
+
+// FindMatchingFarmers finds farmers with an active product in the RFQ's
+// category that meets its grade and organic requirements.
+func (r *rfqRepository) FindMatchingFarmers(ctx context.Context, rfq *model.RFQ) ([]MatchingFarmer, error) {
+	query := r.db.WithContext(ctx).
+		Table("farmers").
+		Select("DISTINCT farmers.id AS farmer_id, farmers.user_id, farmers.latitude, farmers.longitude").
+		Joins("JOIN products ON products.farmer_id = farmers.id").
+		Where("products.status = ? AND products.category = ?", "active", rfq.Category)
+	if rfq.QualityGrade != "" {
+		query = query.Where("products.quality_grade = ?", rfq.QualityGrade)
+	}
+	if rfq.OrganicOnly {
+		query = query.Where("products.organic = ?", true)
+	}
+
+	var farmers []MatchingFarmer
+	err := query.Scan(&farmers).Error
+	return farmers, err
+}
+
+func (r *rfqRepository) CreateQuote(ctx context.Context, quote *model.RFQQuote, offer *model.QuoteOffer) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Offers").Create(quote).Error; err != nil {
+			return err
+		}
+		return tx.Create(offer).Error
+	})
+}
+
+func (r *rfqRepository) FindQuoteByID(ctx context.Context, id uuid.UUID) (*model.RFQQuote, error) {
+	var quote model.RFQQuote
+	err := r.db.WithContext(ctx).
+		Preload("Offers", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at ASC")
+		}).
+		Where("id = ?", id).
+		First(&quote).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &quote, err
+}
+
+func (r *rfqRepository) FindQuoteByFarmer(ctx context.Context, rfqID, farmerID uuid.UUID) (*model.RFQQuote, error) {
+	var quote model.RFQQuote
+	err := r.db.WithContext(ctx).Where("rfq_id = ? AND farmer_id = ?", rfqID, farmerID).First(&quote).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &quote, err
+}
+
+func (r *rfqRepository) FindQuotesByFarmerID(ctx context.Context, farmerID uuid.UUID, page, pageSize int) ([]*model.RFQQuote, int64, error) {
+	query := r.db.WithContext(ctx).Model(&model.RFQQuote{}).Where("farmer_id = ?", farmerID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var quotes []*model.RFQQuote
+	err := query.
+		Preload("Offers", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at ASC")
+		}).
+		Order("updated_at DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&quotes).Error
+	return quotes, total, err
+}
+
+// SaveCounterOffer stores new terms on a quote along with the offer that
+// made them. It reports false if the quote changed status in the meantime.
+func (r *rfqRepository) SaveCounterOffer(ctx context.Context, quote *model.RFQQuote, from model.QuoteStatus, offer *model.QuoteOffer) (bool, error) {
+	saved := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.RFQQuote{}).
+			Where("id = ? AND status = ?", quote.ID, from).
+			Updates(map[string]interface{}{
+				"unit_price":    quote.UnitPrice,
+				"quantity":      quote.Quantity,
+				"delivery_date": quote.DeliveryDate,
+				"status":        quote.Status,
+				"last_offer_by": quote.LastOfferBy,
+				"updated_at":    quote.UpdatedAt,
+			})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		saved = true
+		return tx.Create(offer).Error
+	})
+	return saved && err == nil, err
+}
+
+func (r *rfqRepository) UpdateQuoteStatus(ctx context.Context, id uuid.UUID, from []model.QuoteStatus, to model.QuoteStatus) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&model.RFQQuote{}).
+		Where("id = ? AND status IN ?", id, from).
+		Updates(map[string]interface{}{"status": to, "updated_at": time.Now()})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *rfqRepository) CreateNotifications(ctx context.Context, notifications []*common.Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).CreateInBatches(notifications, 100).Error
+}
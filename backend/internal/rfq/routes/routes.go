@@ -0,0 +1,42 @@
+package routes
+
+import (
+	"agro_konnect/internal/auth/middleware"
+	"agro_konnect/internal/auth/model"
+	farmerRepo "agro_konnect/internal/farmer/repository"
+	orderservice "agro_konnect/internal/order/service"
+	productRepo "agro_konnect/internal/product/repository"
+	"agro_konnect/internal/rfq/handler"
+	"agro_konnect/internal/rfq/repository"
+	"agro_konnect/internal/rfq/service"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func SetupRFQRoutes(router *gin.RouterGroup, db *gorm.DB, authMiddleware *middleware.AuthMiddleware, orderService orderservice.OrderService) {
+	// Accepted quotes are placed as orders, through orderService
+	rfqRepo := repository.NewRFQRepository(db)
+	rfqService := service.NewRFQService(rfqRepo, farmerRepo.NewFarmerRepository(db), productRepo.NewProductRepository(db), orderService)
+	rfqHandler := handler.NewRFQHandler(rfqService)
+
+	rfqRoutes := router.Group("/rfqs")
+	rfqRoutes.Use(authMiddleware.Authenticate())
+	{
+		// Buyers
+		rfqRoutes.POST("", authMiddleware.RequireRole(model.RoleBuyer), rfqHandler.CreateRFQ)
+		rfqRoutes.GET("/me", authMiddleware.RequireRole(model.RoleBuyer), rfqHandler.GetMyRFQs)
+		rfqRoutes.POST("/:id/close", authMiddleware.RequireRole(model.RoleBuyer), rfqHandler.CloseRFQ)
+
+		// Farmers
+		rfqRoutes.GET("/open", authMiddleware.RequireRole(model.RoleFarmer), rfqHandler.GetOpenRFQs)
+		rfqRoutes.GET("/quotes/me", authMiddleware.RequireRole(model.RoleFarmer), rfqHandler.GetMyQuotes)
+		rfqRoutes.POST("/:id/quotes", authMiddleware.RequireRole(model.RoleFarmer), rfqHandler.SubmitQuote)
+
+		// Either side of a negotiation
+		rfqRoutes.GET("/:id", rfqHandler.GetRFQ)
+		rfqRoutes.POST("/:id/quotes/:quoteId/counter", rfqHandler.CounterOffer)
+		rfqRoutes.POST("/:id/quotes/:quoteId/accept", rfqHandler.AcceptQuote)
+		rfqRoutes.POST("/:id/quotes/:quoteId/reject", rfqHandler.RejectQuote)
+	}
+}
@@ -0,0 +1,705 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"agro_konnect/internal/common"
+	farmerRepo "agro_konnect/internal/farmer/repository"
+	orderDto "agro_konnect/internal/order/dto"
+	orderModel "agro_konnect/internal/order/model"
+	orderService "agro_konnect/internal/order/service"
+	productModel "agro_konnect/internal/product/model"
+	productRepo "agro_konnect/internal/product/repository"
+	dto "agro_konnect/internal/rfq/dto"
+	model "agro_konnect/internal/rfq/model"
+	"agro_konnect/internal/rfq/repository"
+	"agro_konnect/pkg/geo"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrRFQNotFound           = errors.New("rfq not found")
+	ErrQuoteNotFound         = errors.New("quote not found")
+	ErrFarmerNotFound        = errors.New("farmer not found")
+	ErrUnauthorizedAccess    = errors.New("unauthorized access to rfq")
+	ErrRFQNotOpen            = errors.New("rfq is no longer taking quotes")
+	ErrQuoteExists           = errors.New("you have already quoted on this rfq")
+	ErrQuoteNotNegotiable    = errors.New("quote is no longer under negotiation")
+	ErrNotYourTurn           = errors.New("waiting for the other party to respond to the last offer")
+	ErrInvalidExpiry         = errors.New("rfq must expire in the future and before the delivery window ends")
+	ErrInvalidDeliveryDate   = errors.New("delivery date must be within the rfq's delivery window")
+	ErrInvalidQuoteProduct   = errors.New("product does not match the rfq")
+	ErrVariantRequired       = errors.New("choose a packaging option for this product")
+	ErrOrderPlacementFailure = errors.New("quote could not be turned into an order")
+)
+
+type RFQService interface {
+	CreateRFQ(ctx context.Context, buyerID uuid.UUID, req *dto.CreateRFQRequest) (*dto.RFQResponse, error)
+	GetMyRFQs(ctx context.Context, buyerID uuid.UUID, filters dto.RFQFilterRequest) (*dto.RFQListResponse, error)
+	GetOpenRFQs(ctx context.Context, filters dto.RFQFilterRequest) (*dto.RFQListResponse, error)
+	GetRFQ(ctx context.Context, rfqID uuid.UUID, userID uuid.UUID, userRole string) (*dto.RFQResponse, error)
+	CloseRFQ(ctx context.Context, rfqID uuid.UUID, buyerID uuid.UUID) error
+
+	SubmitQuote(ctx context.Context, rfqID uuid.UUID, userID uuid.UUID, req *dto.SubmitQuoteRequest) (*dto.QuoteResponse, error)
+	GetMyQuotes(ctx context.Context, userID uuid.UUID, page, pageSize int) (*dto.QuoteListResponse, error)
+	CounterOffer(ctx context.Context, rfqID, quoteID uuid.UUID, userID uuid.UUID, req *dto.CounterOfferRequest) (*dto.QuoteResponse, error)
+	AcceptQuote(ctx context.Context, rfqID, quoteID uuid.UUID, userID uuid.UUID) (*dto.AcceptQuoteResponse, error)
+	RejectQuote(ctx context.Context, rfqID, quoteID uuid.UUID, userID uuid.UUID) error
+}
+
+type rfqService struct {
+	rfqRepo      repository.RFQRepository
+	farmerRepo   farmerRepo.FarmerRepository
+	productRepo  productRepo.ProductRepository
+	orderService orderService.OrderService
+}
+
+func NewRFQService(
+	rfqRepo repository.RFQRepository,
+	farmerRepo farmerRepo.FarmerRepository,
+	productRepo productRepo.ProductRepository,
+	orderService orderService.OrderService,
+) RFQService {
+	return &rfqService{
+		rfqRepo:      rfqRepo,
+		farmerRepo:   farmerRepo,
+		productRepo:  productRepo,
+		orderService: orderService,
+	}
+}
+
+// CreateRFQ posts a buyer's requirement and lets matching farmers know.
+func (s *rfqService) CreateRFQ(ctx context.Context, buyerID uuid.UUID, req *dto.CreateRFQRequest) (*dto.RFQResponse, error) {
+	now := time.Now()
+	expiresAt := req.DeliveryFrom
+	if req.ExpiresAt != nil {
+		expiresAt = *req.ExpiresAt
+	}
+	if !expiresAt.After(now) || expiresAt.After(req.DeliveryTo) {
+		return nil, ErrInvalidExpiry
+	}
+
+	rfq := &model.RFQ{
+		ID:              uuid.New(),
+		BuyerID:         buyerID,
+		Title:           strings.TrimSpace(req.Title),
+		Category:        req.Category,
+		ProductName:     strings.TrimSpace(req.ProductName),
+		Quantity:        req.Quantity,
+		Unit:            strings.TrimSpace(req.Unit),
+		QualityGrade:    req.QualityGrade,
+		OrganicOnly:     req.OrganicOnly,
+		TargetPrice:     req.TargetPrice,
+		Notes:           req.Notes,
+		DeliveryFrom:    req.DeliveryFrom,
+		DeliveryTo:      req.DeliveryTo,
+		DeliveryAddress: req.DeliveryAddress,
+		DeliveryCity:    req.DeliveryCity,
+		DeliveryState:   req.DeliveryState,
+		DeliveryZipCode: req.DeliveryZipCode,
+		Latitude:        req.Latitude,
+		Longitude:       req.Longitude,
+		RadiusKm:        req.RadiusKm,
+		PaymentMethod:   req.PaymentMethod,
+		Status:          model.RFQStatusOpen,
+		ExpiresAt:       expiresAt,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+
+	if err := s.rfqRepo.Create(ctx, rfq); err != nil {
+		return nil, fmt.Errorf("failed to create rfq: %w", err)
+	}
+
+	// Notify matching farmers; the RFQ stands even if this fails
+	if err := s.notifyMatchingFarmers(ctx, rfq); err != nil {
+		log.Printf("failed to notify farmers about rfq %s: %v", rfq.ID, err)
+	}
+
+	return toRFQResponse(rfq, nil), nil
+}
+
+func (s *rfqService) GetMyRFQs(ctx context.Context, buyerID uuid.UUID, filters dto.RFQFilterRequest) (*dto.RFQListResponse, error) {
+	setPaginationDefaults(&filters)
+	rfqs, total, err := s.rfqRepo.FindByBuyerID(ctx, buyerID, filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rfqs: %w", err)
+	}
+	return toRFQListResponse(rfqs, total, filters), nil
+}
+
+// GetOpenRFQs lists the RFQs farmers can still quote on. Quotes from other
+// farmers are not shown.
+func (s *rfqService) GetOpenRFQs(ctx context.Context, filters dto.RFQFilterRequest) (*dto.RFQListResponse, error) {
+	setPaginationDefaults(&filters)
+	rfqs, total, err := s.rfqRepo.FindOpen(ctx, filters, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rfqs: %w", err)
+	}
+	return toRFQListResponse(rfqs, total, filters), nil
+}
+
+// GetRFQ shows the buyer (and admins) every quote, and a farmer the RFQ
+// with their own quote if they made one.
+func (s *rfqService) GetRFQ(ctx context.Context, rfqID uuid.UUID, userID uuid.UUID, userRole string) (*dto.RFQResponse, error) {
+	rfq, err := s.rfqRepo.FindByID(ctx, rfqID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rfq: %w", err)
+	}
+	if rfq == nil {
+		return nil, ErrRFQNotFound
+	}
+
+	if rfq.BuyerID == userID || userRole == "admin" {
+		return toRFQResponse(rfq, rfq.Quotes), nil
+	}
+
+	var own []model.RFQQuote
+	for _, quote := range rfq.Quotes {
+		if quote.FarmerUserID == userID {
+			own = append(own, quote)
+		}
+	}
+	if len(own) == 0 && (userRole != "farmer" || !rfq.IsOpen(time.Now())) {
+		return nil, ErrUnauthorizedAccess
+	}
+
+	response := toRFQResponse(rfq, own)
+	response.QuoteCount = len(rfq.Quotes)
+	return response, nil
+}
+
+func (s *rfqService) CloseRFQ(ctx context.Context, rfqID uuid.UUID, buyerID uuid.UUID) error {
+	rfq, err := s.rfqRepo.FindByID(ctx, rfqID)
+	if err != nil {
+		return fmt.Errorf("failed to get rfq: %w", err)
+	}
+	if rfq == nil {
+		return ErrRFQNotFound
+	}
+	if rfq.BuyerID != buyerID {
+		return ErrUnauthorizedAccess
+	}
+
+	closed, err := s.rfqRepo.Close(ctx, rfqID)
+	if err != nil {
+		return fmt.Errorf("failed to close rfq: %w", err)
+	}
+	if !closed {
+		return ErrRFQNotOpen
+	}
+
+	var notifications []*common.Notification
+	for _, quote := range rfq.Quotes {
+		if quote.IsNegotiable() {
+			notifications = append(notifications, newNotification(quote.FarmerUserID, "RFQ closed",
+				fmt.Sprintf("The buyer closed \"%s\" without accepting a quote.", rfq.Title), rfq.ID))
+		}
+	}
+	s.notify(ctx, notifications...)
+	return nil
+}
+
+// SubmitQuote makes a farmer's opening offer on an RFQ, for one of their
+// products that meets its requirements.
+func (s *rfqService) SubmitQuote(ctx context.Context, rfqID uuid.UUID, userID uuid.UUID, req *dto.SubmitQuoteRequest) (*dto.QuoteResponse, error) {
+	farmer, err := s.farmerRepo.FindByUserID(ctx, userID)
+	if err != nil || farmer == nil {
+		return nil, ErrFarmerNotFound
+	}
+
+	rfq, err := s.rfqRepo.FindByID(ctx, rfqID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rfq: %w", err)
+	}
+	if rfq == nil {
+		return nil, ErrRFQNotFound
+	}
+	if !rfq.IsOpen(time.Now()) {
+		return nil, ErrRFQNotOpen
+	}
+	if rfq.BuyerID == userID {
+		return nil, ErrUnauthorizedAccess
+	}
+
+	existing, err := s.rfqRepo.FindQuoteByFarmer(ctx, rfqID, farmer.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check quotes: %w", err)
+	}
+	if existing != nil {
+		return nil, ErrQuoteExists
+	}
+
+	if err := s.checkQuoteProduct(ctx, rfq, farmer.ID, req.ProductID, req.VariantID); err != nil {
+		return nil, err
+	}
+	if !withinWindow(rfq, req.DeliveryDate) {
+		return nil, ErrInvalidDeliveryDate
+	}
+
+	now := time.Now()
+	quote := &model.RFQQuote{
+		ID:           uuid.New(),
+		RFQID:        rfqID,
+		FarmerID:     farmer.ID,
+		FarmerUserID: userID,
+		ProductID:    req.ProductID,
+		VariantID:    req.VariantID,
+		UnitPrice:    req.UnitPrice,
+		Quantity:     req.Quantity,
+		DeliveryDate: req.DeliveryDate,
+		Notes:        req.Notes,
+		Status:       model.QuoteStatusPending,
+		LastOfferBy:  model.PartyFarmer,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	offer := newOffer(quote, model.PartyFarmer, req.Notes, now)
+
+	if err := s.rfqRepo.CreateQuote(ctx, quote, offer); err != nil {
+		return nil, fmt.Errorf("failed to create quote: %w", err)
+	}
+	quote.Offers = []model.QuoteOffer{*offer}
+
+	s.notify(ctx, newNotification(rfq.BuyerID, "New quote",
+		fmt.Sprintf("%s quoted %.2f per %s for \"%s\".", farmer.FarmName, quote.UnitPrice, rfq.Unit, rfq.Title), rfq.ID))
+
+	return toQuoteResponse(quote), nil
+}
+
+func (s *rfqService) GetMyQuotes(ctx context.Context, userID uuid.UUID, page, pageSize int) (*dto.QuoteListResponse, error) {
+	farmer, err := s.farmerRepo.FindByUserID(ctx, userID)
+	if err != nil || farmer == nil {
+		return nil, ErrFarmerNotFound
+	}
+
+	filters := dto.RFQFilterRequest{Page: page, PageSize: pageSize}
+	setPaginationDefaults(&filters)
+	quotes, total, err := s.rfqRepo.FindQuotesByFarmerID(ctx, farmer.ID, filters.Page, filters.PageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get quotes: %w", err)
+	}
+
+	responses := make([]*dto.QuoteResponse, len(quotes))
+	for i, quote := range quotes {
+		responses[i] = toQuoteResponse(quote)
+	}
+
+	pages := int(math.Ceil(float64(total) / float64(filters.PageSize)))
+	return &dto.QuoteListResponse{
+		Quotes:  responses,
+		Total:   total,
+		Page:    filters.Page,
+		Pages:   pages,
+		HasMore: filters.Page < pages,
+	}, nil
+}
+
+// CounterOffer answers the other party's last offer with new terms. Buyer
+// and farmer take turns until one of them accepts or walks away.
+func (s *rfqService) CounterOffer(ctx context.Context, rfqID, quoteID uuid.UUID, userID uuid.UUID, req *dto.CounterOfferRequest) (*dto.QuoteResponse, error) {
+	rfq, quote, party, err := s.findNegotiation(ctx, rfqID, quoteID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.UnitPrice != nil {
+		quote.UnitPrice = *req.UnitPrice
+	}
+	if req.Quantity != nil {
+		quote.Quantity = *req.Quantity
+	}
+	if req.DeliveryDate != nil {
+		if !withinWindow(rfq, *req.DeliveryDate) {
+			return nil, ErrInvalidDeliveryDate
+		}
+		quote.DeliveryDate = *req.DeliveryDate
+	}
+
+	from := quote.Status
+	now := time.Now()
+	quote.LastOfferBy = party
+	quote.Status = model.QuoteStatusPending
+	if party == model.PartyBuyer {
+		quote.Status = model.QuoteStatusCountered
+	}
+	quote.UpdatedAt = now
+	offer := newOffer(quote, party, req.Message, now)
+
+	saved, err := s.rfqRepo.SaveCounterOffer(ctx, quote, from, offer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save counter-offer: %w", err)
+	}
+	if !saved {
+		return nil, ErrQuoteNotNegotiable
+	}
+	quote.Offers = append(quote.Offers, *offer)
+
+	recipient := quote.FarmerUserID
+	if party == model.PartyFarmer {
+		recipient = rfq.BuyerID
+	}
+	s.notify(ctx, newNotification(recipient, "Counter-offer",
+		fmt.Sprintf("New offer on \"%s\": %.2f per %s for %g %s.", rfq.Title, quote.UnitPrice, rfq.Unit, quote.Quantity, rfq.Unit), rfq.ID))
+
+	return toQuoteResponse(quote), nil
+}
+
+// AcceptQuote agrees to the other party's last offer and places the order at
+// the negotiated price. The RFQ is awarded first so that no other quote can
+// be accepted meanwhile, and reopened if the order cannot be placed.
+func (s *rfqService) AcceptQuote(ctx context.Context, rfqID, quoteID uuid.UUID, userID uuid.UUID) (*dto.AcceptQuoteResponse, error) {
+	rfq, quote, party, err := s.findNegotiation(ctx, rfqID, quoteID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	claimed, err := s.rfqRepo.ClaimAward(ctx, rfqID, quote, time.Now())
+	if errors.Is(err, repository.ErrQuoteChanged) {
+		return nil, ErrQuoteNotNegotiable
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to award rfq: %w", err)
+	}
+	if !claimed {
+		return nil, ErrRFQNotOpen
+	}
+
+	order, err := s.orderService.CreateNegotiatedOrder(ctx, rfq.BuyerID, &orderDto.CreateOrderRequest{
+		ShippingAddress:   rfq.DeliveryAddress,
+		ShippingCity:      rfq.DeliveryCity,
+		ShippingState:     rfq.DeliveryState,
+		ShippingZipCode:   rfq.DeliveryZipCode,
+		ShippingNotes:     fmt.Sprintf("RFQ: %s", rfq.Title),
+		ShippingLatitude:  rfq.Latitude,
+		ShippingLongitude: rfq.Longitude,
+		PaymentMethod:     orderModel.PaymentMethod(rfq.PaymentMethod),
+		Items: []orderDto.OrderItemRequest{{
+			ProductID: quote.ProductID,
+			VariantID: quote.VariantID,
+			Quantity:  quote.Quantity,
+		}},
+	}, orderService.NegotiatedTerms{
+		QuoteID:      &quote.ID,
+		UnitPrice:    quote.UnitPrice,
+		DeliveryDate: quote.DeliveryDate,
+	})
+	if err != nil {
+		if releaseErr := s.rfqRepo.ReleaseAward(ctx, rfqID, quote); releaseErr != nil {
+			log.Printf("failed to reopen rfq %s: %v", rfqID, releaseErr)
+		}
+		return nil, fmt.Errorf("%w: %w", ErrOrderPlacementFailure, err)
+	}
+
+	if err := s.rfqRepo.CompleteAward(ctx, rfqID, quoteID, order.ID); err != nil {
+		// The order stands; only the quote bookkeeping is behind
+		log.Printf("failed to complete award of rfq %s to order %s: %v", rfqID, order.OrderNumber, err)
+	}
+	quote.Status = model.QuoteStatusAccepted
+
+	recipient := quote.FarmerUserID
+	if party == model.PartyFarmer {
+		recipient = rfq.BuyerID
+	}
+	notifications := []*common.Notification{newNotification(recipient, "Quote accepted",
+		fmt.Sprintf("The offer on \"%s\" was accepted. Order %s has been placed.", rfq.Title, order.OrderNumber), rfq.ID)}
+	for _, other := range rfq.Quotes {
+		if other.ID != quote.ID && other.IsNegotiable() {
+			notifications = append(notifications, newNotification(other.FarmerUserID, "Quote not selected",
+				fmt.Sprintf("The buyer accepted another quote for \"%s\".", rfq.Title), rfq.ID))
+		}
+	}
+	s.notify(ctx, notifications...)
+
+	return &dto.AcceptQuoteResponse{
+		Quote:       toQuoteResponse(quote),
+		OrderID:     order.ID,
+		OrderNumber: order.OrderNumber,
+	}, nil
+}
+
+// RejectQuote ends a negotiation: the buyer rejects the quote, or the farmer
+// withdraws it. Either can do so whoever made the last offer.
+func (s *rfqService) RejectQuote(ctx context.Context, rfqID, quoteID uuid.UUID, userID uuid.UUID) error {
+	rfq, quote, err := s.findQuote(ctx, rfqID, quoteID)
+	if err != nil {
+		return err
+	}
+	party, err := partyOf(rfq, quote, userID)
+	if err != nil {
+		return err
+	}
+
+	status, recipient, title := model.QuoteStatusRejected, quote.FarmerUserID, "Quote rejected"
+	if party == model.PartyFarmer {
+		status, recipient, title = model.QuoteStatusWithdrawn, rfq.BuyerID, "Quote withdrawn"
+	}
+
+	updated, err := s.rfqRepo.UpdateQuoteStatus(ctx, quoteID,
+		[]model.QuoteStatus{model.QuoteStatusPending, model.QuoteStatusCountered}, status)
+	if err != nil {
+		return fmt.Errorf("failed to update quote: %w", err)
+	}
+	if !updated {
+		return ErrQuoteNotNegotiable
+	}
+
+	s.notify(ctx, newNotification(recipient, title,
+		fmt.Sprintf("The quote on \"%s\" is no longer under negotiation.", rfq.Title), rfq.ID))
+	return nil
+}
+
+// Helper methods
+func (s *rfqService) findQuote(ctx context.Context, rfqID, quoteID uuid.UUID) (*model.RFQ, *model.RFQQuote, error) {
+	rfq, err := s.rfqRepo.FindByID(ctx, rfqID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get rfq: %w", err)
+	}
+	if rfq == nil {
+		return nil, nil, ErrRFQNotFound
+	}
+
+	for i := range rfq.Quotes {
+		if rfq.Quotes[i].ID == quoteID {
+			return rfq, &rfq.Quotes[i], nil
+		}
+	}
+	return nil, nil, ErrQuoteNotFound
+}
+
+// findNegotiation loads a quote the user can answer: the RFQ is open, the
+// quote is still under negotiation and the last offer came from the other
+// party.
+func (s *rfqService) findNegotiation(ctx context.Context, rfqID, quoteID uuid.UUID, userID uuid.UUID) (*model.RFQ, *model.RFQQuote, model.Party, error) {
+	rfq, quote, err := s.findQuote(ctx, rfqID, quoteID)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	party, err := partyOf(rfq, quote, userID)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	if !rfq.IsOpen(time.Now()) {
+		return nil, nil, "", ErrRFQNotOpen
+	}
+	if !quote.IsNegotiable() {
+		return nil, nil, "", ErrQuoteNotNegotiable
+	}
+	if quote.LastOfferBy == party {
+		return nil, nil, "", ErrNotYourTurn
+	}
+	return rfq, quote, party, nil
+}
+
+// checkQuoteProduct makes sure the quoted product is the farmer's own, on
+// sale and of the kind the RFQ asks for.
+func (s *rfqService) checkQuoteProduct(ctx context.Context, rfq *model.RFQ, farmerID, productID uuid.UUID, variantID *uuid.UUID) error {
+	product, err := s.productRepo.FindByID(ctx, productID)
+	if err != nil {
+		return fmt.Errorf("failed to get product: %w", err)
+	}
+	if product == nil || product.FarmerID != farmerID || product.Status != productModel.StatusActive {
+		return ErrInvalidQuoteProduct
+	}
+	if string(product.Category) != rfq.Category ||
+		(rfq.QualityGrade != "" && string(product.QualityGrade) != rfq.QualityGrade) ||
+		(rfq.OrganicOnly && !product.Organic) {
+		return ErrInvalidQuoteProduct
+	}
+
+	variants, err := s.productRepo.FindVariantsByProductIDs(ctx, []uuid.UUID{productID}, true)
+	if err != nil {
+		return fmt.Errorf("failed to get variants: %w", err)
+	}
+	if variantID == nil {
+		if len(variants) > 0 {
+			return ErrVariantRequired
+		}
+		return nil
+	}
+	for _, variant := range variants {
+		if variant.ID == *variantID {
+			return nil
+		}
+	}
+	return ErrInvalidQuoteProduct
+}
+
+func (s *rfqService) notifyMatchingFarmers(ctx context.Context, rfq *model.RFQ) error {
+	farmers, err := s.rfqRepo.FindMatchingFarmers(ctx, rfq)
+	if err != nil {
+		return err
+	}
+
+	center := geo.Point{Lat: rfq.Latitude, Lng: rfq.Longitude}
+	limitByDistance := rfq.RadiusKm > 0 && (rfq.Latitude != 0 || rfq.Longitude != 0)
+
+	notifications := make([]*common.Notification, 0, len(farmers))
+	for _, farmer := range farmers {
+		if farmer.UserID == rfq.BuyerID {
+			continue
+		}
+		if limitByDistance && !geo.WithinRadius(center, rfq.RadiusKm, geo.Point{Lat: farmer.Latitude, Lng: farmer.Longitude}) {
+			continue
+		}
+		notifications = append(notifications, newNotification(farmer.UserID, "New request for quotation",
+			fmt.Sprintf("A buyer needs %g %s of %s by %s.", rfq.Quantity, rfq.Unit, rfqSubject(rfq), rfq.DeliveryTo.Format("02 Jan 2006")), rfq.ID))
+	}
+	return s.rfqRepo.CreateNotifications(ctx, notifications)
+}
+
+// notify stores notifications, logging rather than failing on errors.
+func (s *rfqService) notify(ctx context.Context, notifications ...*common.Notification) {
+	if err := s.rfqRepo.CreateNotifications(ctx, notifications); err != nil {
+		log.Printf("failed to create rfq notifications: %v", err)
+	}
+}
+
+func partyOf(rfq *model.RFQ, quote *model.RFQQuote, userID uuid.UUID) (model.Party, error) {
+	switch userID {
+	case rfq.BuyerID:
+		return model.PartyBuyer, nil
+	case quote.FarmerUserID:
+		return model.PartyFarmer, nil
+	default:
+		return "", ErrUnauthorizedAccess
+	}
+}
+
+func withinWindow(rfq *model.RFQ, date time.Time) bool {
+	return !date.Before(rfq.DeliveryFrom) && !date.After(rfq.DeliveryTo)
+}
+
+func rfqSubject(rfq *model.RFQ) string {
+	if rfq.ProductName != "" {
+		return rfq.ProductName
+	}
+	return rfq.Category
+}
+
+func newOffer(quote *model.RFQQuote, party model.Party, message string, at time.Time) *model.QuoteOffer {
+	return &model.QuoteOffer{
+		ID:           uuid.New(),
+		QuoteID:      quote.ID,
+		OfferedBy:    party,
+		UnitPrice:    quote.UnitPrice,
+		Quantity:     quote.Quantity,
+		DeliveryDate: quote.DeliveryDate,
+		Message:      message,
+		CreatedAt:    at,
+	}
+}
+
+func newNotification(userID uuid.UUID, title, message string, rfqID uuid.UUID) *common.Notification {
+	return &common.Notification{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Title:     title,
+		Message:   message,
+		Type:      "rfq",
+		ActionURL: "/rfqs/" + rfqID.String(),
+		CreatedAt: time.Now(),
+	}
+}
+
+func setPaginationDefaults(filters *dto.RFQFilterRequest) {
+	if filters.Page < 1 {
+		filters.Page = 1
+	}
+	if filters.PageSize < 1 || filters.PageSize > 100 {
+		filters.PageSize = 20
+	}
+}
+
+func toRFQListResponse(rfqs []*model.RFQ, total int64, filters dto.RFQFilterRequest) *dto.RFQListResponse {
+	responses := make([]*dto.RFQResponse, len(rfqs))
+	for i, rfq := range rfqs {
+		responses[i] = toRFQResponse(rfq, nil)
+		responses[i].QuoteCount = len(rfq.Quotes)
+	}
+
+	pages := int(math.Ceil(float64(total) / float64(filters.PageSize)))
+	return &dto.RFQListResponse{
+		RFQs:    responses,
+		Total:   total,
+		Page:    filters.Page,
+		Pages:   pages,
+		HasMore: filters.Page < pages,
+	}
+}
+
+func toRFQResponse(rfq *model.RFQ, quotes []model.RFQQuote) *dto.RFQResponse {
+	response := &dto.RFQResponse{
+		ID:              rfq.ID,
+		BuyerID:         rfq.BuyerID,
+		Title:           rfq.Title,
+		Category:        rfq.Category,
+		ProductName:     rfq.ProductName,
+		Quantity:        rfq.Quantity,
+		Unit:            rfq.Unit,
+		QualityGrade:    rfq.QualityGrade,
+		OrganicOnly:     rfq.OrganicOnly,
+		TargetPrice:     rfq.TargetPrice,
+		Notes:           rfq.Notes,
+		DeliveryFrom:    rfq.DeliveryFrom,
+		DeliveryTo:      rfq.DeliveryTo,
+		DeliveryAddress: rfq.DeliveryAddress,
+		DeliveryCity:    rfq.DeliveryCity,
+		DeliveryState:   rfq.DeliveryState,
+		DeliveryZipCode: rfq.DeliveryZipCode,
+		Latitude:        rfq.Latitude,
+		Longitude:       rfq.Longitude,
+		RadiusKm:        rfq.RadiusKm,
+		PaymentMethod:   rfq.PaymentMethod,
+		Status:          rfq.Status,
+		ExpiresAt:       rfq.ExpiresAt,
+		AwardedQuoteID:  rfq.AwardedQuoteID,
+		OrderID:         rfq.OrderID,
+		QuoteCount:      len(quotes),
+		CreatedAt:       rfq.CreatedAt,
+	}
+	for i := range quotes {
+		response.Quotes = append(response.Quotes, toQuoteResponse(&quotes[i]))
+	}
+	return response
+}
+
+func toQuoteResponse(quote *model.RFQQuote) *dto.QuoteResponse {
+	offers := make([]*dto.OfferResponse, len(quote.Offers))
+	for i, offer := range quote.Offers {
+		offers[i] = &dto.OfferResponse{
+			OfferedBy:    offer.OfferedBy,
+			UnitPrice:    offer.UnitPrice,
+			Quantity:     offer.Quantity,
+			DeliveryDate: offer.DeliveryDate,
+			Message:      offer.Message,
+			CreatedAt:    offer.CreatedAt,
+		}
+	}
+
+	return &dto.QuoteResponse{
+		ID:           quote.ID,
+		RFQID:        quote.RFQID,
+		FarmerID:     quote.FarmerID,
+		ProductID:    quote.ProductID,
+		VariantID:    quote.VariantID,
+		UnitPrice:    quote.UnitPrice,
+		Quantity:     quote.Quantity,
+		TotalPrice:   math.Round(quote.UnitPrice*quote.Quantity*100) / 100,
+		DeliveryDate: quote.DeliveryDate,
+		Notes:        quote.Notes,
+		Status:       quote.Status,
+		LastOfferBy:  quote.LastOfferBy,
+		Offers:       offers,
+		CreatedAt:    quote.CreatedAt,
+		UpdatedAt:    quote.UpdatedAt,
+	}
+}
@@ -0,0 +1,40 @@
+package dto
+
+import (
+	model "agro_konnect/internal/scheduler/model"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Response DTOs
+type JobResponse struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Schedule    string          `json:"schedule"`
+	NextRunAt   *time.Time      `json:"next_run_at"` // nil when the scheduler is not running
+	LastRun     *JobRunResponse `json:"last_run"`
+}
+
+type JobRunResponse struct {
+	ID           uuid.UUID          `json:"id"`
+	JobName      string             `json:"job_name"`
+	ScheduledFor *time.Time         `json:"scheduled_for"`
+	Trigger      model.JobTrigger   `json:"trigger"`
+	TriggeredBy  *uuid.UUID         `json:"triggered_by,omitempty"`
+	Status       model.JobRunStatus `json:"status"`
+	Instance     string             `json:"instance"`
+	Summary      string             `json:"summary"`
+	Error        string             `json:"error,omitempty"`
+	StartedAt    time.Time          `json:"started_at"`
+	FinishedAt   *time.Time         `json:"finished_at"`
+	DurationMs   int64              `json:"duration_ms"`
+}
+
+type JobRunListResponse struct {
+	Runs    []*JobRunResponse `json:"runs"`
+	Total   int64             `json:"total"`
+	Page    int               `json:"page"`
+	Pages   int               `json:"pages"`
+	HasMore bool              `json:"has_more"`
+}
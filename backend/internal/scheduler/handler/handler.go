@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"agro_konnect/internal/scheduler/service"
+	"agro_konnect/internal/scheduler/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type SchedulerHandler struct {
+	scheduler service.Scheduler
+}
+
+func NewSchedulerHandler(scheduler service.Scheduler) *SchedulerHandler {
+	return &SchedulerHandler{scheduler: scheduler}
+}
+
+// ListJobs lists the background jobs (admin only)
+// @Summary List background jobs
+// @Description List registered background jobs with their schedule, next activation and last run (admin only)
+// @Tags jobs
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.SuccessResponse{data=[]dto.JobResponse} "Jobs retrieved successfully"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 403 {object} utils.ErrorResponse "Forbidden"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /admin/jobs [get]
+func (h *SchedulerHandler) ListJobs(c *gin.Context) {
+	jobs, err := h.scheduler.ListJobs(c.Request.Context())
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to get jobs")
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, "Jobs retrieved successfully", jobs)
+}
+
+// GetJobRuns lists a job's run history (admin only)
+// @Summary Get job runs
+// @Description Get the run history of a background job, newest first (admin only)
+// @Tags jobs
+// @Produce json
+// @Security BearerAuth
+// @Param name path string true "Job name"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Success 200 {object} utils.SuccessResponse{data=dto.JobRunListResponse} "Job runs retrieved successfully"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 403 {object} utils.ErrorResponse "Forbidden"
+// @Failure 404 {object} utils.ErrorResponse "Job not found"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /admin/jobs/{name}/runs [get]
+func (h *SchedulerHandler) GetJobRuns(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	runs, err := h.scheduler.GetJobRuns(c.Request.Context(), c.Param("name"), page, pageSize)
+	if err != nil {
+		switch err {
+		case service.ErrJobNotFound:
+			utils.RespondWithError(c, http.StatusNotFound, err.Error())
+		default:
+			utils.RespondWithError(c, http.StatusInternalServerError, "Failed to get job runs")
+		}
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, "Job runs retrieved successfully", runs)
+}
+
+// TriggerJob starts a job outside its schedule (admin only)
+// @Summary Run job now
+// @Description Start a background job immediately. The run is skipped if the job is already running on any server (admin only)
+// @Tags jobs
+// @Produce json
+// @Security BearerAuth
+// @Param name path string true "Job name"
+// @Success 202 {object} utils.SuccessResponse{data=dto.JobRunResponse} "Job started"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 403 {object} utils.ErrorResponse "Forbidden"
+// @Failure 404 {object} utils.ErrorResponse "Job not found"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /admin/jobs/{name}/run [post]
+func (h *SchedulerHandler) TriggerJob(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	run, err := h.scheduler.TriggerJob(c.Request.Context(), c.Param("name"), userID)
+	if err != nil {
+		switch err {
+		case service.ErrJobNotFound:
+			utils.RespondWithError(c, http.StatusNotFound, err.Error())
+		default:
+			utils.RespondWithError(c, http.StatusInternalServerError, "Failed to start job")
+		}
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusAccepted, "Job started", run)
+}
+
+// Helper function to get user ID from context
+func GetUserIDFromContext(c *gin.Context) (uuid.UUID, error) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		return uuid.Nil, service.ErrUnauthorizedAccess
+	}
+
+	switch v := userID.(type) {
+	case uuid.UUID:
+		return v, nil
+	case string:
+		parsedUUID, err := uuid.Parse(v)
+		if err != nil {
+			return uuid.Nil, service.ErrUnauthorizedAccess
+		}
+		return parsedUUID, nil
+	default:
+		return uuid.Nil, service.ErrUnauthorizedAccess
+	}
+}
@@ -0,0 +1,400 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	authRepo "agro_konnect/internal/auth/repository"
+	"agro_konnect/internal/common"
+	emailRepo "agro_konnect/internal/email/repository"
+	farmerRepo "agro_konnect/internal/farmer/repository"
+	farmerService "agro_konnect/internal/farmer/service"
+	mediaRepo "agro_konnect/internal/media/repository"
+	mediaService "agro_konnect/internal/media/service"
+	orderService "agro_konnect/internal/order/service"
+	productRepo "agro_konnect/internal/product/repository"
+	productService "agro_konnect/internal/product/service"
+	rfqRepo "agro_konnect/internal/rfq/repository"
+	"agro_konnect/internal/scheduler/repository"
+	"agro_konnect/internal/scheduler/service"
+	transporterService "agro_konnect/internal/transporter/service"
+	"agro_konnect/pkg/storage"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultLowStockThreshold = 10
+	DefaultStaleOrderAfter   = 24 * time.Hour
+	DefaultOrphanGracePeriod = 24 * time.Hour
+
+	// ledgerRetryWindow is how far back failed ledger postings are retried
+	ledgerRetryWindow = 7 * 24 * time.Hour
+)
+
+// legacyUploadDirs are where product and vendor images were saved before
+// media storage.
+var legacyUploadDirs = []string{"./uploads/products", "./uploads/vendors"}
+
+// Config tunes the housekeeping jobs.
+type Config struct {
+	LowStockThreshold float64
+	StaleOrderAfter   time.Duration
+	OrphanGracePeriod time.Duration // how long an upload may stay unreferenced
+}
+
+// LoadConfig reads LOW_STOCK_THRESHOLD, STALE_ORDER_HOURS and
+// MEDIA_ORPHAN_GRACE_HOURS from the environment, falling back to the
+// defaults.
+func LoadConfig() Config {
+	config := Config{
+		LowStockThreshold: DefaultLowStockThreshold,
+		StaleOrderAfter:   DefaultStaleOrderAfter,
+		OrphanGracePeriod: DefaultOrphanGracePeriod,
+	}
+	if v, err := strconv.ParseFloat(os.Getenv("LOW_STOCK_THRESHOLD"), 64); err == nil && v >= 0 {
+		config.LowStockThreshold = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("STALE_ORDER_HOURS")); err == nil && v > 0 {
+		config.StaleOrderAfter = time.Duration(v) * time.Hour
+	}
+	if v, err := strconv.Atoi(os.Getenv("MEDIA_ORPHAN_GRACE_HOURS")); err == nil && v > 0 {
+		config.OrphanGracePeriod = time.Duration(v) * time.Hour
+	}
+	return config
+}
+
+// NewScheduler builds the scheduler with every background job registered.
+// The settlement and ledger jobs use the services in orderDeps, shared with
+// the routes that place orders.
+func NewScheduler(db *gorm.DB, orderDeps *orderService.Dependencies) (service.Scheduler, error) {
+	config := LoadConfig()
+	scheduler := service.NewScheduler(repository.NewJobRunRepository(db))
+	housekeepingRepo := repository.NewHousekeepingRepository(db)
+
+	products := productService.NewProductService(productRepo.NewProductRepository(db), farmerRepo.NewFarmerRepository(db))
+	verificationRepo := authRepo.NewVerificationRepository(db)
+	sessionRepo := authRepo.NewSessionRepository(db)
+	attemptRepo := authRepo.NewAttemptRepository(db)
+	settlements := orderDeps.Settlements
+	rfqs := rfqRepo.NewRFQRepository(db)
+	emails := emailRepo.NewEmailRepository(db)
+
+	ledgerConfig := farmerService.LoadLedgerConfig()
+	ledgerRepo := farmerRepo.NewLedgerRepository(db)
+	payoutRepo := farmerRepo.NewPayoutRepository(db)
+	payouts := farmerService.NewPayoutService(payoutRepo, ledgerRepo, ledgerConfig)
+	ledgerRetrier := orderService.NewLedgerRetrier(orderDeps.Orders, orderDeps.Ledger)
+
+	// The sweep only stores and deletes files, so it needs no URL secret
+	mediaConfig := storage.LoadConfig()
+	mediaStore, err := storage.New(mediaConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up media storage: %w", err)
+	}
+	sweeper := mediaService.NewOrphanSweeper(
+		mediaRepo.NewAttachmentRepository(db),
+		mediaRepo.NewRenditionRepository(db),
+		mediaRepo.NewReferenceRepository(db),
+		mediaStore,
+		sweepableLegacyDirs(mediaConfig),
+	)
+
+	jobs := []service.Job{
+		{
+			Name:        "product-expiry",
+			Description: "Mark products past their expiry date as expired",
+			Schedule:    "5 * * * *",
+			Run: func(ctx context.Context) (string, error) {
+				count, err := products.BulkUpdateExpiredProducts(ctx)
+				if err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("%d products expired", count), nil
+			},
+		},
+		{
+			Name:        "verification-code-cleanup",
+			Description: "Delete expired email and phone verification codes",
+			Schedule:    "30 3 * * *",
+			Run: func(ctx context.Context) (string, error) {
+				if err := verificationRepo.DeleteExpiredCodes(); err != nil {
+					return "", err
+				}
+				return "expired codes deleted", nil
+			},
+		},
+		{
+			Name:        "session-cleanup",
+			Description: "Delete sessions that expired or were ended over 30 days ago",
+			Schedule:    "40 3 * * *",
+			Run: func(ctx context.Context) (string, error) {
+				count, err := sessionRepo.DeleteEndedBefore(ctx, time.Now().AddDate(0, 0, -30))
+				if err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("%d sessions deleted", count), nil
+			},
+		},
+		{
+			Name:        "failed-login-cleanup",
+			Description: "Delete failed sign-in counters with no failures in the last day",
+			Schedule:    "45 3 * * *",
+			Run: func(ctx context.Context) (string, error) {
+				count, err := attemptRepo.DeleteStale(ctx, time.Now().Add(-24*time.Hour))
+				if err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("%d counters deleted", count), nil
+			},
+		},
+		{
+			Name:        "email-outbox-cleanup",
+			Description: "Delete emails delivered over 90 days ago; failed ones are kept",
+			Schedule:    "50 3 * * *",
+			Run: func(ctx context.Context) (string, error) {
+				count, err := emails.DeleteSentBefore(ctx, time.Now().AddDate(0, 0, -90))
+				if err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("%d emails deleted", count), nil
+			},
+		},
+		{
+			Name:        "low-stock-alerts",
+			Description: "Notify farmers about active products running low on stock",
+			Schedule:    "0 7 * * *",
+			Run: func(ctx context.Context) (string, error) {
+				return sendLowStockAlerts(ctx, housekeepingRepo, config.LowStockThreshold)
+			},
+		},
+		{
+			Name:        "stale-order-reminders",
+			Description: "Remind farmers about orders waiting on them",
+			Schedule:    "15 * * * *",
+			Run: func(ctx context.Context) (string, error) {
+				return sendStaleOrderReminders(ctx, housekeepingRepo, config.StaleOrderAfter)
+			},
+		},
+		{
+			Name:        "transporter-weekly-statements",
+			Description: "Close last week's transporter settlement statements",
+			Schedule:    "0 2 * * 1",
+			Timeout:     30 * time.Minute,
+			Run: func(ctx context.Context) (string, error) {
+				run, err := settlements.GenerateWeeklyStatements(ctx, transporterService.LastWeek())
+				if err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("%d statements, %d orders accrued", run.StatementsCount, run.AccruedOrders), nil
+			},
+		},
+		{
+			Name:        "farmer-payout-batches",
+			Description: "Pay out farmers whose payable balance has reached the minimum payout",
+			Schedule:    "0 4 * * 1",
+			Timeout:     30 * time.Minute,
+			Run: func(ctx context.Context) (string, error) {
+				batch, err := payouts.CreatePayoutBatch(ctx)
+				if err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("%d payouts totalling %.2f, %d farmers without a payout account",
+					batch.PayoutCount, batch.TotalAmount, len(batch.SkippedFarmers)), nil
+			},
+		},
+		{
+			Name:        "order-ledger-retry",
+			Description: "Post farmer ledger entries that failed when orders were paid or refunded",
+			Schedule:    "20 * * * *",
+			Run: func(ctx context.Context) (string, error) {
+				result, err := ledgerRetrier.PostMissing(ctx, time.Now().Add(-ledgerRetryWindow))
+				if err != nil {
+					return "", err
+				}
+				summary := fmt.Sprintf("%d payments and %d refunds posted", result.Payments, result.Refunds)
+				if result.Failed > 0 {
+					return "", fmt.Errorf("%s, %d failed", summary, result.Failed)
+				}
+				return summary, nil
+			},
+		},
+		{
+			Name:        "rfq-expiry",
+			Description: "Mark RFQs past their quoting deadline as expired",
+			Schedule:    "10 * * * *",
+			Run: func(ctx context.Context) (string, error) {
+				count, err := rfqs.ExpireOpen(ctx, time.Now())
+				if err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("%d rfqs expired", count), nil
+			},
+		},
+		{
+			Name:        "media-orphan-sweep",
+			Description: "Delete uploads no record references after a grace period and report the space reclaimed",
+			Schedule:    "45 3 * * *",
+			Timeout:     30 * time.Minute,
+			Run: func(ctx context.Context) (string, error) {
+				return sweepOrphanedUploads(ctx, sweeper, housekeepingRepo, config.OrphanGracePeriod)
+			},
+		},
+	}
+
+	for _, job := range jobs {
+		if err := scheduler.Register(job); err != nil {
+			return nil, err
+		}
+	}
+	return scheduler, nil
+}
+
+// sendLowStockAlerts sends each farmer one notification listing their low
+// products, rather than one per product.
+func sendLowStockAlerts(ctx context.Context, repo repository.HousekeepingRepository, threshold float64) (string, error) {
+	products, err := repo.FindLowStockProducts(ctx, threshold)
+	if err != nil {
+		return "", fmt.Errorf("failed to find low stock products: %w", err)
+	}
+
+	byFarmer := make(map[uuid.UUID][]repository.LowStockProduct)
+	var farmers []uuid.UUID
+	for _, product := range products {
+		if _, seen := byFarmer[product.FarmerUserID]; !seen {
+			farmers = append(farmers, product.FarmerUserID)
+		}
+		byFarmer[product.FarmerUserID] = append(byFarmer[product.FarmerUserID], product)
+	}
+
+	now := time.Now()
+	notifications := make([]*common.Notification, 0, len(farmers))
+	for _, userID := range farmers {
+		low := byFarmer[userID]
+		message := fmt.Sprintf("%s has %g %s left.", low[0].Name, low[0].AvailableStock, low[0].Unit)
+		if len(low) > 1 {
+			message = fmt.Sprintf("%s and %d other products are running low on stock.", low[0].Name, len(low)-1)
+		}
+		notifications = append(notifications, &common.Notification{
+			ID:        uuid.New(),
+			UserID:    userID,
+			Title:     "Low stock",
+			Message:   message,
+			Type:      "inventory",
+			ActionURL: "/products",
+			CreatedAt: now,
+		})
+	}
+
+	if err := repo.CreateNotifications(ctx, notifications); err != nil {
+		return "", fmt.Errorf("failed to create notifications: %w", err)
+	}
+	return fmt.Sprintf("%d products low, %d farmers notified", len(products), len(notifications)), nil
+}
+
+// sendStaleOrderReminders nudges farmers about orders that have not moved
+// for the configured time. Each order is reminded at most once per period.
+func sendStaleOrderReminders(ctx context.Context, repo repository.HousekeepingRepository, staleAfter time.Duration) (string, error) {
+	now := time.Now()
+	orders, err := repo.FindStaleOrders(ctx, []string{"pending", "confirmed"}, now.Add(-staleAfter))
+	if err != nil {
+		return "", fmt.Errorf("failed to find stale orders: %w", err)
+	}
+
+	notifications := make([]*common.Notification, len(orders))
+	orderIDs := make([]uuid.UUID, len(orders))
+	for i, order := range orders {
+		notifications[i] = &common.Notification{
+			ID:        uuid.New(),
+			UserID:    order.FarmerUserID,
+			Title:     "Order awaiting action",
+			Message:   fmt.Sprintf("Order %s has been %s since %s.", order.OrderNumber, order.Status, order.UpdatedAt.Format("02 Jan 15:04")),
+			Type:      "order",
+			ActionURL: "/orders/" + order.OrderID.String(),
+			CreatedAt: now,
+		}
+		orderIDs[i] = order.OrderID
+	}
+
+	if err := repo.CreateNotifications(ctx, notifications); err != nil {
+		return "", fmt.Errorf("failed to create notifications: %w", err)
+	}
+	if err := repo.MarkOrdersReminded(ctx, orderIDs, now); err != nil {
+		return "", fmt.Errorf("failed to mark orders reminded: %w", err)
+	}
+	return fmt.Sprintf("%d reminders sent", len(orders)), nil
+}
+
+// sweepOrphanedUploads deletes unreferenced uploads and tells admins how
+// much space came back.
+func sweepOrphanedUploads(ctx context.Context, sweeper mediaService.OrphanSweeper, repo repository.HousekeepingRepository, grace time.Duration) (string, error) {
+	result, err := sweeper.Sweep(ctx, time.Now().Add(-grace))
+	if err != nil {
+		return "", err
+	}
+
+	summary := fmt.Sprintf("%d uploads, %d renditions and %d legacy files deleted, %s reclaimed",
+		result.Uploads, result.Renditions, result.LegacyFiles, formatBytes(result.BytesFreed))
+	if result.Uploads+result.Renditions+result.LegacyFiles == 0 {
+		return summary, nil
+	}
+
+	admins, err := repo.FindUserIDsByRole(ctx, "admin")
+	if err != nil {
+		return "", fmt.Errorf("failed to find admins: %w", err)
+	}
+
+	now := time.Now()
+	notifications := make([]*common.Notification, len(admins))
+	for i, userID := range admins {
+		notifications[i] = &common.Notification{
+			ID:        uuid.New(),
+			UserID:    userID,
+			Title:     "Unused uploads cleaned up",
+			Message:   summary + ".",
+			Type:      "system",
+			ActionURL: "/admin/jobs",
+			CreatedAt: now,
+		}
+	}
+	if err := repo.CreateNotifications(ctx, notifications); err != nil {
+		return "", fmt.Errorf("failed to create notifications: %w", err)
+	}
+	return summary, nil
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// sweepableLegacyDirs leaves out any legacy directory that media storage has
+// been pointed into, since its files would all look unreferenced.
+func sweepableLegacyDirs(mediaConfig storage.Config) []string {
+	dirs := make([]string, 0, len(legacyUploadDirs))
+	for _, dir := range legacyUploadDirs {
+		if mediaConfig.Backend == "local" {
+			if rel, err := filepath.Rel(dir, mediaConfig.LocalDir); err == nil && !strings.HasPrefix(rel, "..") {
+				log.Printf("not sweeping %s: media storage is inside it", dir)
+				continue
+			}
+		}
+		dirs = append(dirs, dir)
+	}
+	return dirs
+}
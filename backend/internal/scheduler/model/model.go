@@ -0,0 +1,42 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type JobRunStatus string
+
+const (
+	JobRunRunning   JobRunStatus = "running"
+	JobRunSucceeded JobRunStatus = "succeeded"
+	JobRunFailed    JobRunStatus = "failed"
+	JobRunSkipped   JobRunStatus = "skipped" // another run held the job's lock
+)
+
+type JobTrigger string
+
+const (
+	JobTriggerSchedule JobTrigger = "schedule"
+	JobTriggerManual   JobTrigger = "manual"
+)
+
+// JobRun is one execution of a scheduled job. Scheduled runs record the slot
+// they were due for; the unique index lets exactly one replica claim a slot.
+// Manual runs leave ScheduledFor empty.
+type JobRun struct {
+	ID           uuid.UUID    `gorm:"type:uuid;primary_key" json:"id"`
+	JobName      string       `gorm:"type:varchar(100);not null;index;uniqueIndex:idx_job_run_slot" json:"job_name"`
+	ScheduledFor *time.Time   `gorm:"uniqueIndex:idx_job_run_slot" json:"scheduled_for"`
+	Trigger      JobTrigger   `gorm:"type:varchar(20);not null" json:"trigger"`
+	TriggeredBy  *uuid.UUID   `gorm:"type:uuid" json:"triggered_by"`
+	Status       JobRunStatus `gorm:"type:varchar(20);not null;index" json:"status"`
+	Instance     string       `json:"instance"` // host that ran the job
+	Summary      string       `json:"summary"`
+	Error        string       `json:"error"`
+	StartedAt    time.Time    `gorm:"index" json:"started_at"`
+	FinishedAt   *time.Time   `json:"finished_at"`
+	DurationMs   int64        `json:"duration_ms"`
+	CreatedAt    time.Time    `json:"created_at"`
+}
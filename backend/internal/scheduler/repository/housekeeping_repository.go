@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"agro_konnect/internal/common"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// LowStockProduct is an active product that can no longer fill an order of
+// a normal size, with the user account of the farmer selling it.
+type LowStockProduct struct {
+	ProductID      uuid.UUID
+	FarmerUserID   uuid.UUID
+	Name           string
+	AvailableStock float64
+	Unit           string
+}
+
+// StaleOrder is an order left waiting on the farmer.
+type StaleOrder struct {
+	OrderID      uuid.UUID
+	OrderNumber  string
+	Status       string
+	FarmerUserID uuid.UUID
+	UpdatedAt    time.Time
+}
+
+// HousekeepingRepository holds the cross-domain queries behind the
+// housekeeping jobs.
+type HousekeepingRepository interface {
+	FindLowStockProducts(ctx context.Context, threshold float64) ([]LowStockProduct, error)
+	FindStaleOrders(ctx context.Context, statuses []string, staleBefore time.Time) ([]StaleOrder, error)
+	MarkOrdersReminded(ctx context.Context, orderIDs []uuid.UUID, at time.Time) error
+	FindUserIDsByRole(ctx context.Context, role string) ([]uuid.UUID, error)
+	CreateNotifications(ctx context.Context, notifications []*common.Notification) error
+}
+
+type housekeepingRepository struct {
+	db *gorm.DB
+}
+
+func NewHousekeepingRepository(db *gorm.DB) HousekeepingRepository {
+	return &housekeepingRepository{db: db}
+}
+
+// FindLowStockProducts returns active products at or below the threshold or
+// below their own minimum order quantity.
+func (r *housekeepingRepository) FindLowStockProducts(ctx context.Context, threshold float64) ([]LowStockProduct, error) {
+	var products []LowStockProduct
+	err := r.db.WithContext(ctx).
+		Table("products p").
+		Select("p.id AS product_id, f.user_id AS farmer_user_id, p.name, p.available_stock, p.unit").
+		Joins("JOIN farmers f ON f.id = p.farmer_id").
+		Where("p.status = ?", "active").
+		Where("p.available_stock <= ? OR p.available_stock < p.min_order", threshold).
+		Order("f.user_id, p.available_stock ASC").
+		Scan(&products).Error
+	return products, err
+}
+
+// FindStaleOrders returns orders in the given statuses untouched since
+// staleBefore whose farmer has not been reminded since then either.
+func (r *housekeepingRepository) FindStaleOrders(ctx context.Context, statuses []string, staleBefore time.Time) ([]StaleOrder, error) {
+	var orders []StaleOrder
+	err := r.db.WithContext(ctx).
+		Table("orders o").
+		Select("o.id AS order_id, o.order_number, o.status, f.user_id AS farmer_user_id, o.updated_at").
+		Joins("JOIN farmers f ON f.id = o.farmer_id").
+		Where("o.status IN ? AND o.updated_at < ?", statuses, staleBefore).
+		Where("o.reminder_sent_at IS NULL OR o.reminder_sent_at < ?", staleBefore).
+		Order("o.updated_at ASC").
+		Scan(&orders).Error
+	return orders, err
+}
+
+// MarkOrdersReminded stamps the reminder time without touching updated_at,
+// which must keep reflecting the last real change to the order.
+func (r *housekeepingRepository) MarkOrdersReminded(ctx context.Context, orderIDs []uuid.UUID, at time.Time) error {
+	if len(orderIDs) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).
+		Table("orders").
+		Where("id IN ?", orderIDs).
+		UpdateColumn("reminder_sent_at", at).Error
+}
+
+func (r *housekeepingRepository) FindUserIDsByRole(ctx context.Context, role string) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.WithContext(ctx).
+		Table("users").
+		Where("role = ? AND is_active = ?", role, true).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+func (r *housekeepingRepository) CreateNotifications(ctx context.Context, notifications []*common.Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).CreateInBatches(notifications, 100).Error
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	model "agro_konnect/internal/scheduler/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type JobRunRepository interface {
+	Create(ctx context.Context, run *model.JobRun) error
+	ClaimSlot(ctx context.Context, run *model.JobRun) (bool, error)
+	Finish(ctx context.Context, id uuid.UUID, status model.JobRunStatus, summary, errMsg string, finishedAt time.Time, duration time.Duration) error
+	FindByJob(ctx context.Context, jobName string, page, pageSize int) ([]*model.JobRun, int64, error)
+	FindLatest(ctx context.Context) (map[string]*model.JobRun, error)
+	WithJobLock(ctx context.Context, jobName string, fn func() error) (bool, error)
+}
+
+type jobRunRepository struct {
+	db *gorm.DB
+}
+
+func NewJobRunRepository(db *gorm.DB) JobRunRepository {
+	return &jobRunRepository{db: db}
+}
+
+func (r *jobRunRepository) Create(ctx context.Context, run *model.JobRun) error {
+	return r.db.WithContext(ctx).Create(run).Error
+}
+
+// ClaimSlot inserts a scheduled run unless one already exists for the same
+// job and slot, reporting whether this caller got it.
+func (r *jobRunRepository) ClaimSlot(ctx context.Context, run *model.JobRun) (bool, error) {
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(run)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *jobRunRepository) Finish(ctx context.Context, id uuid.UUID, status model.JobRunStatus, summary, errMsg string, finishedAt time.Time, duration time.Duration) error {
+	return r.db.WithContext(ctx).Model(&model.JobRun{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":      status,
+			"summary":     summary,
+			"error":       errMsg,
+			"finished_at": &finishedAt,
+			"duration_ms": duration.Milliseconds(),
+		}).Error
+}
+
+func (r *jobRunRepository) FindByJob(ctx context.Context, jobName string, page, pageSize int) ([]*model.JobRun, int64, error) {
+	query := r.db.WithContext(ctx).Model(&model.JobRun{}).Where("job_name = ?", jobName)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var runs []*model.JobRun
+	offset := (page - 1) * pageSize
+	err := query.Order("started_at DESC").Offset(offset).Limit(pageSize).Find(&runs).Error
+	return runs, total, err
+}
+
+// FindLatest returns the most recent run of every job that has run.
+func (r *jobRunRepository) FindLatest(ctx context.Context) (map[string]*model.JobRun, error) {
+	var runs []*model.JobRun
+	err := r.db.WithContext(ctx).
+		Raw(`SELECT DISTINCT ON (job_name) * FROM job_runs ORDER BY job_name, started_at DESC`).
+		Scan(&runs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	latest := make(map[string]*model.JobRun, len(runs))
+	for _, run := range runs {
+		latest[run.JobName] = run
+	}
+	return latest, nil
+}
+
+// WithJobLock runs fn while holding a session-level advisory lock for the
+// job, so the same job never runs twice at once across replicas. The lock is
+// tried, not waited for; it reports false without calling fn when another
+// session holds it.
+func (r *jobRunRepository) WithJobLock(ctx context.Context, jobName string, fn func() error) (bool, error) {
+	acquired := false
+	key := "scheduler:" + jobName
+
+	// Advisory locks belong to a session, so lock and unlock on one connection
+	err := r.db.WithContext(ctx).Connection(func(conn *gorm.DB) error {
+		if err := conn.Raw("SELECT pg_try_advisory_lock(hashtext(?))", key).Scan(&acquired).Error; err != nil {
+			return err
+		}
+		if !acquired {
+			return nil
+		}
+		defer conn.WithContext(context.Background()).Exec("SELECT pg_advisory_unlock(hashtext(?))", key)
+
+		return fn()
+	})
+	return acquired, err
+}
@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"agro_konnect/internal/auth/middleware"
+	"agro_konnect/internal/auth/model"
+	"agro_konnect/internal/scheduler/handler"
+	"agro_konnect/internal/scheduler/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SetupSchedulerRoutes(router *gin.RouterGroup, scheduler service.Scheduler, authMiddleware *middleware.AuthMiddleware) {
+	schedulerHandler := handler.NewSchedulerHandler(scheduler)
+
+	// Admin routes
+	jobRoutes := router.Group("/admin/jobs")
+	jobRoutes.Use(authMiddleware.Authenticate(), authMiddleware.RequireRole(model.RoleAdmin))
+	{
+		jobRoutes.GET("", schedulerHandler.ListJobs)
+		jobRoutes.GET("/:name/runs", schedulerHandler.GetJobRuns)
+		jobRoutes.POST("/:name/run", schedulerHandler.TriggerJob)
+	}
+}
@@ -0,0 +1,342 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	dto "agro_konnect/internal/scheduler/dto"
+	model "agro_konnect/internal/scheduler/model"
+	"agro_konnect/internal/scheduler/repository"
+
+	"github.com/google/uuid"
+)
+
+// DefaultJobTimeout bounds a run when the job does not set its own timeout.
+const DefaultJobTimeout = 10 * time.Minute
+
+var (
+	ErrJobNotFound        = errors.New("job not found")
+	ErrJobAlreadyExists   = errors.New("a job with this name is already registered")
+	ErrUnauthorizedAccess = errors.New("unauthorized access")
+)
+
+// Job is a unit of background work. Run returns a short summary for the run
+// history, such as how many rows it touched.
+type Job struct {
+	Name        string
+	Description string
+	Schedule    string
+	Timeout     time.Duration
+	Run         func(ctx context.Context) (string, error)
+}
+
+type Scheduler interface {
+	Register(job Job) error
+	Start()
+	Stop()
+	ListJobs(ctx context.Context) ([]*dto.JobResponse, error)
+	GetJobRuns(ctx context.Context, name string, page, pageSize int) (*dto.JobRunListResponse, error)
+	TriggerJob(ctx context.Context, name string, userID uuid.UUID) (*dto.JobRunResponse, error)
+}
+
+type jobEntry struct {
+	job      Job
+	schedule Schedule
+
+	mu      sync.Mutex
+	nextRun *time.Time
+}
+
+type scheduler struct {
+	runRepo  repository.JobRunRepository
+	instance string
+
+	mu      sync.Mutex
+	jobs    map[string]*jobEntry
+	started bool
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewScheduler(runRepo repository.JobRunRepository) Scheduler {
+	instance, err := os.Hostname()
+	if err != nil {
+		instance = "unknown"
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &scheduler{
+		runRepo:  runRepo,
+		instance: fmt.Sprintf("%s/%d", instance, os.Getpid()),
+		jobs:     make(map[string]*jobEntry),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+func (s *scheduler) Register(job Job) error {
+	schedule, err := ParseSchedule(job.Schedule)
+	if err != nil {
+		return fmt.Errorf("job %s: %w", job.Name, err)
+	}
+	if job.Timeout <= 0 {
+		job.Timeout = DefaultJobTimeout
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.jobs[job.Name]; exists {
+		return ErrJobAlreadyExists
+	}
+	s.jobs[job.Name] = &jobEntry{job: job, schedule: schedule}
+	return nil
+}
+
+// Start launches one loop per registered job. Jobs registered afterwards can
+// still be triggered manually but are not scheduled.
+func (s *scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return
+	}
+	s.started = true
+
+	for _, entry := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(entry)
+	}
+	log.Printf("⏱️  Scheduler started with %d jobs", len(s.jobs))
+}
+
+// Stop cancels running jobs and waits for their loops to exit.
+func (s *scheduler) Stop() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+// loop runs the job at each activation. Runs are synchronous, so a run that
+// overlaps later slots on this replica skips them rather than queueing.
+func (s *scheduler) loop(entry *jobEntry) {
+	defer s.wg.Done()
+
+	for {
+		next := entry.schedule.Next(time.Now())
+		if next.IsZero() {
+			log.Printf("Scheduler: job %s has no future activation", entry.job.Name)
+			return
+		}
+		entry.setNextRun(&next)
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-s.ctx.Done():
+			timer.Stop()
+			entry.setNextRun(nil)
+			return
+		case <-timer.C:
+		}
+
+		slot := next
+		run := s.newRun(entry.job.Name, model.JobTriggerSchedule, &slot, nil)
+		if err := s.execute(entry, run); err != nil {
+			log.Printf("Scheduler: job %s failed to run: %v", entry.job.Name, err)
+		}
+	}
+}
+
+// execute runs the job under its advisory lock. A scheduled run is recorded
+// only if this replica claims the slot; a manual run is already recorded and
+// is marked skipped if the job is busy elsewhere.
+func (s *scheduler) execute(entry *jobEntry, run *model.JobRun) error {
+	acquired, err := s.runRepo.WithJobLock(s.ctx, entry.job.Name, func() error {
+		if run.Trigger == model.JobTriggerSchedule {
+			claimed, err := s.runRepo.ClaimSlot(s.ctx, run)
+			if err != nil || !claimed {
+				return err
+			}
+		}
+
+		summary, runErr := s.runJob(entry.job)
+
+		status, errMsg := model.JobRunSucceeded, ""
+		if runErr != nil {
+			status, errMsg = model.JobRunFailed, runErr.Error()
+			log.Printf("Scheduler: job %s failed: %v", entry.job.Name, runErr)
+		}
+
+		finishedAt := time.Now()
+		return s.runRepo.Finish(context.Background(), run.ID, status, summary, errMsg, finishedAt, finishedAt.Sub(run.StartedAt))
+	})
+	if err != nil {
+		return err
+	}
+
+	if !acquired && run.Trigger == model.JobTriggerManual {
+		return s.runRepo.Finish(context.Background(), run.ID, model.JobRunSkipped, "", "job is already running", time.Now(), 0)
+	}
+	return nil
+}
+
+// runJob calls the job with its timeout, turning a panic into an error so
+// one bad job cannot take the server down.
+func (s *scheduler) runJob(job Job) (summary string, err error) {
+	ctx, cancel := context.WithTimeout(s.ctx, job.Timeout)
+	defer cancel()
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	return job.Run(ctx)
+}
+
+func (s *scheduler) ListJobs(ctx context.Context) ([]*dto.JobResponse, error) {
+	latest, err := s.runRepo.FindLatest(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load job runs: %w", err)
+	}
+
+	s.mu.Lock()
+	entries := make([]*jobEntry, 0, len(s.jobs))
+	for _, entry := range s.jobs {
+		entries = append(entries, entry)
+	}
+	s.mu.Unlock()
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].job.Name < entries[j].job.Name })
+
+	responses := make([]*dto.JobResponse, len(entries))
+	for i, entry := range entries {
+		response := &dto.JobResponse{
+			Name:        entry.job.Name,
+			Description: entry.job.Description,
+			Schedule:    entry.job.Schedule,
+			NextRunAt:   entry.getNextRun(),
+		}
+		if run, ok := latest[entry.job.Name]; ok {
+			response.LastRun = toJobRunResponse(run)
+		}
+		responses[i] = response
+	}
+	return responses, nil
+}
+
+func (s *scheduler) GetJobRuns(ctx context.Context, name string, page, pageSize int) (*dto.JobRunListResponse, error) {
+	if s.lookup(name) == nil {
+		return nil, ErrJobNotFound
+	}
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	runs, total, err := s.runRepo.FindByJob(ctx, name, page, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job runs: %w", err)
+	}
+
+	responses := make([]*dto.JobRunResponse, len(runs))
+	for i, run := range runs {
+		responses[i] = toJobRunResponse(run)
+	}
+
+	pages := int(math.Ceil(float64(total) / float64(pageSize)))
+
+	return &dto.JobRunListResponse{
+		Runs:    responses,
+		Total:   total,
+		Page:    page,
+		Pages:   pages,
+		HasMore: page < pages,
+	}, nil
+}
+
+// TriggerJob records a manual run and starts it in the background. The
+// returned run is still in progress; poll the job's runs for the outcome.
+func (s *scheduler) TriggerJob(ctx context.Context, name string, userID uuid.UUID) (*dto.JobRunResponse, error) {
+	entry := s.lookup(name)
+	if entry == nil {
+		return nil, ErrJobNotFound
+	}
+
+	run := s.newRun(name, model.JobTriggerManual, nil, &userID)
+	if err := s.runRepo.Create(ctx, run); err != nil {
+		return nil, fmt.Errorf("failed to record job run: %w", err)
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		if err := s.execute(entry, run); err != nil {
+			log.Printf("Scheduler: manual run of %s failed: %v", name, err)
+		}
+	}()
+
+	return toJobRunResponse(run), nil
+}
+
+// Helper methods
+func (s *scheduler) lookup(name string) *jobEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.jobs[name]
+}
+
+func (s *scheduler) newRun(name string, trigger model.JobTrigger, scheduledFor *time.Time, triggeredBy *uuid.UUID) *model.JobRun {
+	return &model.JobRun{
+		ID:           uuid.New(),
+		JobName:      name,
+		ScheduledFor: scheduledFor,
+		Trigger:      trigger,
+		TriggeredBy:  triggeredBy,
+		Status:       model.JobRunRunning,
+		Instance:     s.instance,
+		StartedAt:    time.Now(),
+		CreatedAt:    time.Now(),
+	}
+}
+
+func (e *jobEntry) setNextRun(t *time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.nextRun = t
+}
+
+func (e *jobEntry) getNextRun() *time.Time {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.nextRun
+}
+
+func toJobRunResponse(run *model.JobRun) *dto.JobRunResponse {
+	return &dto.JobRunResponse{
+		ID:           run.ID,
+		JobName:      run.JobName,
+		ScheduledFor: run.ScheduledFor,
+		Trigger:      run.Trigger,
+		TriggeredBy:  run.TriggeredBy,
+		Status:       run.Status,
+		Instance:     run.Instance,
+		Summary:      run.Summary,
+		Error:        run.Error,
+		StartedAt:    run.StartedAt,
+		FinishedAt:   run.FinishedAt,
+		DurationMs:   run.DurationMs,
+	}
+}
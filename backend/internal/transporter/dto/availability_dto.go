@@ -0,0 +1,64 @@
+package transporter
+
+import (
+	model "agro_konnect/internal/transporter/model"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Availability Request DTOs
+type CreateSlotRequest struct {
+	StartTime string                   `json:"start_time" validate:"required"`
+	EndTime   string                   `json:"end_time" validate:"required"`
+	Capacity  *model.TransportCapacity `json:"capacity"` // defaults to the vehicle's capacity
+	Notes     string                   `json:"notes"`
+}
+
+type UpdateSlotStatusRequest struct {
+	Status model.SlotStatus `json:"status" validate:"required,oneof=open blocked"`
+}
+
+// BookVehicleRequest is used internally when an order is assigned to a vehicle.
+type BookVehicleRequest struct {
+	VehicleID     uuid.UUID
+	TransporterID uuid.UUID
+	OrderID       uuid.UUID
+	StartTime     time.Time
+	EndTime       time.Time
+	Load          model.TransportCapacity
+}
+
+// Availability Response DTOs
+type SlotResponse struct {
+	ID        uuid.UUID               `json:"id"`
+	VehicleID uuid.UUID               `json:"vehicle_id"`
+	StartTime time.Time               `json:"start_time"`
+	EndTime   time.Time               `json:"end_time"`
+	Capacity  model.TransportCapacity `json:"capacity"`
+	Booked    model.TransportCapacity `json:"booked"`
+	Remaining model.TransportCapacity `json:"remaining"`
+	Status    model.SlotStatus        `json:"status"`
+	Notes     string                  `json:"notes"`
+
+	Bookings []BookingResponse `json:"bookings,omitempty"`
+}
+
+type BookingResponse struct {
+	ID        uuid.UUID               `json:"id"`
+	SlotID    uuid.UUID               `json:"slot_id"`
+	VehicleID uuid.UUID               `json:"vehicle_id"`
+	OrderID   uuid.UUID               `json:"order_id"`
+	StartTime time.Time               `json:"start_time"`
+	EndTime   time.Time               `json:"end_time"`
+	Load      model.TransportCapacity `json:"load"`
+	Status    model.BookingStatus     `json:"status"`
+	CreatedAt time.Time               `json:"created_at"`
+}
+
+type VehicleCalendarResponse struct {
+	VehicleID uuid.UUID       `json:"vehicle_id"`
+	From      time.Time       `json:"from"`
+	To        time.Time       `json:"to"`
+	Slots     []*SlotResponse `json:"slots"`
+}
@@ -2,6 +2,7 @@ package transporter
 
 import (
 	model "agro_konnect/internal/transporter/model"
+	"agro_konnect/pkg/geo"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,11 +14,11 @@ type CreateTransporterRequest struct {
 	ContactPerson string `json:"contact_person" validate:"required"`
 	Description   string `json:"description"`
 
-	Address      string   `json:"address" validate:"required"`
-	City         string   `json:"city" validate:"required"`
-	State        string   `json:"state" validate:"required"`
-	Country      string   `json:"country" validate:"required"`
-	ServiceAreas []string `json:"service_areas"`
+	Address      string               `json:"address" validate:"required"`
+	City         string               `json:"city" validate:"required"`
+	State        string               `json:"state" validate:"required"`
+	Country      string               `json:"country" validate:"required"`
+	ServiceAreas []ServiceAreaRequest `json:"service_areas" validate:"omitempty,max=50,dive"` // nil when left out, so an update keeps the areas
 
 	AlternatePhone string `json:"alternate_phone"`
 	Website        string `json:"website"`
@@ -56,11 +57,11 @@ type TransporterResponse struct {
 	ContactPerson string    `json:"contact_person"`
 	Description   string    `json:"description"`
 
-	Address      string   `json:"address"`
-	City         string   `json:"city"`
-	State        string   `json:"state"`
-	Country      string   `json:"country"`
-	ServiceAreas []string `json:"service_areas"`
+	Address      string                `json:"address"`
+	City         string                `json:"city"`
+	State        string                `json:"state"`
+	Country      string                `json:"country"`
+	ServiceAreas []ServiceAreaResponse `json:"service_areas"`
 
 	IsVerified  bool    `json:"is_verified"`
 	IsPremium   bool    `json:"is_premium"`
@@ -98,6 +99,8 @@ type TransporterFilterRequest struct {
 	City           string            `query:"city"`
 	State          string            `query:"state"`
 	ServiceArea    string            `query:"service_area"`
+	Pickup         *geo.Point        `query:"-"` // from pickup_lat/pickup_lng
+	Drop           *geo.Point        `query:"-"` // from drop_lat/drop_lng
 	VehicleType    model.VehicleType `query:"vehicle_type"`
 	MinCapacity    float64           `query:"min_capacity"`
 	MaxCapacity    float64           `query:"max_capacity"`
@@ -114,8 +117,11 @@ type VehicleFilterRequest struct {
 	VehicleType   model.VehicleType `query:"vehicle_type"`
 	IsAvailable   *bool             `query:"is_available"`
 	MinCapacity   float64           `query:"min_capacity"`
+	MinVolume     float64           `query:"min_volume"`
 	MaxCapacity   float64           `query:"max_capacity"`
 	Location      string            `query:"location"`
+	AvailableFrom string            `query:"available_from"`
+	AvailableTo   string            `query:"available_to"`
 	Page          int               `query:"page" validate:"min=1"`
 	PageSize      int               `query:"page_size" validate:"min=1,max=100"`
 }
@@ -129,6 +135,13 @@ type TransporterStatsResponse struct {
 	TotalEarnings      float64 `json:"total_earnings"`
 	AverageRating      float64 `json:"average_rating"`
 	OnTimeDeliveryRate float64 `json:"on_time_delivery_rate"`
+
+	// Revenue
+	GrossRevenue   float64 `json:"gross_revenue"`
+	CommissionPaid float64 `json:"commission_paid"`
+	PaidOut        float64 `json:"paid_out"`
+	PendingPayout  float64 `json:"pending_payout"`
+	BilledOrders   int64   `json:"billed_orders"`
 }
 
 type TransporterListResponse struct {
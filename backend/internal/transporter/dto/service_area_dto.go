@@ -0,0 +1,55 @@
+package transporter
+
+import (
+	model "agro_konnect/internal/transporter/model"
+	"bytes"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Service Area Request DTOs
+type ServiceAreaRequest struct {
+	Name string                `json:"name" validate:"required,max=100"`
+	Type model.ServiceAreaType `json:"type" validate:"required,oneof=radius polygon named"`
+
+	// Required for radius areas
+	CenterLatitude  float64 `json:"center_latitude" validate:"omitempty,latitude"`
+	CenterLongitude float64 `json:"center_longitude" validate:"omitempty,longitude"`
+	RadiusKm        float64 `json:"radius_km" validate:"omitempty,gt=0,max=1000"`
+
+	// Required for polygon areas: a GeoJSON Polygon, MultiPolygon or Feature
+	Polygon json.RawMessage `json:"polygon" swaggertype:"object"`
+}
+
+// UnmarshalJSON also accepts a bare place name, the form service areas took
+// before they had a shape, and reads it as a named area.
+//
+// Deprecated: plain strings are accepted for one release only; send an
+// object with a type instead.
+func (r *ServiceAreaRequest) UnmarshalJSON(data []byte) error {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '"' {
+		var name string
+		if err := json.Unmarshal(trimmed, &name); err != nil {
+			return err
+		}
+		*r = ServiceAreaRequest{Name: name, Type: model.ServiceAreaTypeNamed}
+		return nil
+	}
+
+	type plain ServiceAreaRequest
+	return json.Unmarshal(data, (*plain)(r))
+}
+
+// Service Area Response DTOs
+type ServiceAreaResponse struct {
+	ID              uuid.UUID             `json:"id"`
+	Name            string                `json:"name"`
+	Type            model.ServiceAreaType `json:"type"`
+	CenterLatitude  float64               `json:"center_latitude,omitempty"`
+	CenterLongitude float64               `json:"center_longitude,omitempty"`
+	RadiusKm        float64               `json:"radius_km,omitempty"`
+	Polygon         json.RawMessage       `json:"polygon,omitempty" swaggertype:"object"`
+	CreatedAt       time.Time             `json:"created_at"`
+}
@@ -0,0 +1,33 @@
+package transporter
+
+import (
+	"encoding/json"
+	"testing"
+
+	model "agro_konnect/internal/transporter/model"
+)
+
+func TestServiceAreaRequestAcceptsLegacyNames(t *testing.T) {
+	var req CreateTransporterRequest
+	body := `{"service_areas":["Nairobi",{"name":"Thika","type":"radius","center_latitude":-1.03,"center_longitude":37.07,"radius_km":25}]}`
+	if err := json.Unmarshal([]byte(body), &req); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	if len(req.ServiceAreas) != 2 {
+		t.Fatalf("got %d areas, want 2", len(req.ServiceAreas))
+	}
+	if got := req.ServiceAreas[0]; got.Name != "Nairobi" || got.Type != model.ServiceAreaTypeNamed {
+		t.Errorf("legacy area = %+v, want named area Nairobi", got)
+	}
+	if got := req.ServiceAreas[1]; got.Name != "Thika" || got.Type != model.ServiceAreaTypeRadius || got.RadiusKm != 25 {
+		t.Errorf("radius area = %+v", got)
+	}
+}
+
+func TestServiceAreaRequestRejectsOtherValues(t *testing.T) {
+	var req ServiceAreaRequest
+	if err := json.Unmarshal([]byte(`42`), &req); err == nil {
+		t.Error("expected an error for a number")
+	}
+}
@@ -0,0 +1,88 @@
+package transporter
+
+import (
+	model "agro_konnect/internal/transporter/model"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Settlement Request DTOs
+
+// AccrueEarningRequest is used internally when an order is delivered.
+type AccrueEarningRequest struct {
+	OrderID        uuid.UUID
+	OrderNumber    string
+	TransporterID  uuid.UUID
+	ShippingCharge float64
+	DeliveredAt    time.Time
+}
+
+type GenerateStatementsRequest struct {
+	WeekOf string `json:"week_of"` // RFC3339; defaults to last week
+}
+
+type MarkStatementPaidRequest struct {
+	PaymentReference string `json:"payment_reference" validate:"required,max=100"`
+}
+
+// Settlement Response DTOs
+type EarningResponse struct {
+	ID               uuid.UUID  `json:"id"`
+	OrderID          uuid.UUID  `json:"order_id"`
+	OrderNumber      string     `json:"order_number"`
+	ShippingCharge   float64    `json:"shipping_charge"`
+	CommissionRate   float64    `json:"commission_rate"`
+	CommissionAmount float64    `json:"commission_amount"`
+	NetAmount        float64    `json:"net_amount"`
+	DeliveredAt      time.Time  `json:"delivered_at"`
+	StatementID      *uuid.UUID `json:"statement_id,omitempty"`
+}
+
+type EarningsSummaryResponse struct {
+	OrderCount       int64   `json:"order_count"`
+	GrossAmount      float64 `json:"gross_amount"`
+	CommissionAmount float64 `json:"commission_amount"`
+	NetAmount        float64 `json:"net_amount"`
+	PaidAmount       float64 `json:"paid_amount"`
+	PendingAmount    float64 `json:"pending_amount"`  // on statements awaiting payout
+	UnbilledAmount   float64 `json:"unbilled_amount"` // not yet on a statement
+}
+
+type EarningsResponse struct {
+	From     time.Time               `json:"from"`
+	To       time.Time               `json:"to"`
+	Summary  EarningsSummaryResponse `json:"summary"`
+	Earnings []EarningResponse       `json:"earnings"`
+}
+
+type StatementResponse struct {
+	ID               uuid.UUID             `json:"id"`
+	TransporterID    uuid.UUID             `json:"transporter_id"`
+	PeriodStart      time.Time             `json:"period_start"`
+	PeriodEnd        time.Time             `json:"period_end"`
+	OrderCount       int                   `json:"order_count"`
+	GrossAmount      float64               `json:"gross_amount"`
+	CommissionAmount float64               `json:"commission_amount"`
+	NetAmount        float64               `json:"net_amount"`
+	Status           model.StatementStatus `json:"status"`
+	PaidAt           *time.Time            `json:"paid_at,omitempty"`
+	PaymentReference string                `json:"payment_reference,omitempty"`
+	CreatedAt        time.Time             `json:"created_at"`
+}
+
+type StatementListResponse struct {
+	Statements []*StatementResponse `json:"statements"`
+	Total      int64                `json:"total"`
+	Page       int                  `json:"page"`
+	Pages      int                  `json:"pages"`
+	HasMore    bool                 `json:"has_more"`
+}
+
+type StatementRunResponse struct {
+	PeriodStart     time.Time            `json:"period_start"`
+	PeriodEnd       time.Time            `json:"period_end"`
+	AccruedOrders   int                  `json:"accrued_orders"`
+	StatementsCount int                  `json:"statements_count"`
+	Statements      []*StatementResponse `json:"statements"`
+}
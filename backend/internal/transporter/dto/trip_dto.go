@@ -0,0 +1,77 @@
+package transporter
+
+import (
+	model "agro_konnect/internal/transporter/model"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Trip Request DTOs
+type CreateTripRequest struct {
+	VehicleID    uuid.UUID   `json:"vehicle_id" validate:"required"`
+	OrderIDs     []uuid.UUID `json:"order_ids" validate:"required,min=1,max=50"`
+	PlannedStart string      `json:"planned_start"` // defaults to now
+
+	// Where the vehicle starts from; the first pickup is used when omitted
+	StartLatitude  float64 `json:"start_latitude" validate:"omitempty,latitude"`
+	StartLongitude float64 `json:"start_longitude" validate:"omitempty,longitude"`
+
+	Notes string `json:"notes"`
+}
+
+type UpdateTripStatusRequest struct {
+	Status model.TripStatus `json:"status" validate:"required,oneof=in_progress completed cancelled"`
+}
+
+type UpdateStopStatusRequest struct {
+	Status model.StopStatus `json:"status" validate:"required,oneof=completed skipped"`
+}
+
+type TripFilterRequest struct {
+	Status   string `query:"status"`
+	Page     int    `query:"page" validate:"min=1"`
+	PageSize int    `query:"page_size" validate:"min=1,max=100"`
+}
+
+// Trip Response DTOs
+type TripStopResponse struct {
+	ID            uuid.UUID               `json:"id"`
+	OrderID       uuid.UUID               `json:"order_id"`
+	Sequence      int                     `json:"sequence"`
+	Type          model.StopType          `json:"type"`
+	Address       string                  `json:"address"`
+	Latitude      float64                 `json:"latitude"`
+	Longitude     float64                 `json:"longitude"`
+	Load          model.TransportCapacity `json:"load"`
+	LegDistanceKm float64                 `json:"leg_distance_km"`
+	Status        model.StopStatus        `json:"status"`
+	CompletedAt   *time.Time              `json:"completed_at,omitempty"`
+}
+
+type TripResponse struct {
+	ID              uuid.UUID               `json:"id"`
+	TransporterID   uuid.UUID               `json:"transporter_id"`
+	VehicleID       uuid.UUID               `json:"vehicle_id"`
+	Status          model.TripStatus        `json:"status"`
+	StartLatitude   float64                 `json:"start_latitude"`
+	StartLongitude  float64                 `json:"start_longitude"`
+	TotalDistanceKm float64                 `json:"total_distance_km"`
+	TotalLoad       model.TransportCapacity `json:"total_load"`
+	VehicleCapacity model.TransportCapacity `json:"vehicle_capacity"`
+	PlannedStart    time.Time               `json:"planned_start"`
+	PlannedEnd      time.Time               `json:"planned_end"`
+	StartedAt       *time.Time              `json:"started_at,omitempty"`
+	CompletedAt     *time.Time              `json:"completed_at,omitempty"`
+	Notes           string                  `json:"notes"`
+	Stops           []TripStopResponse      `json:"stops"`
+	CreatedAt       time.Time               `json:"created_at"`
+}
+
+type TripListResponse struct {
+	Trips   []*TripResponse `json:"trips"`
+	Total   int64           `json:"total"`
+	Page    int             `json:"page"`
+	Pages   int             `json:"pages"`
+	HasMore bool            `json:"has_more"`
+}
@@ -0,0 +1,332 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"agro_konnect/internal/auth/middleware"
+	dto "agro_konnect/internal/transporter/dto"
+	"agro_konnect/internal/transporter/service"
+	"agro_konnect/internal/transporter/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type AvailabilityHandler struct {
+	transporterService  service.TransporterService
+	availabilityService service.AvailabilityService
+}
+
+func NewAvailabilityHandler(
+	transporterService service.TransporterService,
+	availabilityService service.AvailabilityService,
+) *AvailabilityHandler {
+	return &AvailabilityHandler{
+		transporterService:  transporterService,
+		availabilityService: availabilityService,
+	}
+}
+
+// CreateSlot adds an availability slot to a vehicle's calendar
+// @Summary Create availability slot
+// @Description Declare a time window during which a vehicle can carry consignments
+// @Tags vehicles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Vehicle ID"
+// @Param request body dto.CreateSlotRequest true "Slot data"
+// @Success 201 {object} utils.SuccessResponse{data=dto.SlotResponse} "Slot created successfully"
+// @Failure 400 {object} utils.ErrorResponse "Invalid input data"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 403 {object} utils.ErrorResponse "Forbidden"
+// @Failure 404 {object} utils.ErrorResponse "Vehicle not found"
+// @Failure 409 {object} utils.ErrorResponse "Slot overlaps an existing slot"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /transporters/vehicles/{id}/slots [post]
+func (h *AvailabilityHandler) CreateSlot(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	vehicleID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid vehicle ID")
+		return
+	}
+
+	// Get transporter ID from user ID
+	transporter, err := h.transporterService.GetTransporterByUserID(c.Request.Context(), userID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusNotFound, "Transporter profile not found")
+		return
+	}
+
+	var req dto.CreateSlotRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid input data: "+err.Error())
+		return
+	}
+
+	if err := utils.ValidateStruct(req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	slot, err := h.availabilityService.CreateSlot(c.Request.Context(), vehicleID, transporter.ID, &req)
+	if err != nil {
+		switch err {
+		case service.ErrVehicleNotFound:
+			utils.RespondWithError(c, http.StatusNotFound, err.Error())
+		case service.ErrUnauthorizedAccess:
+			utils.RespondWithError(c, http.StatusForbidden, err.Error())
+		case service.ErrSlotOverlap:
+			utils.RespondWithError(c, http.StatusConflict, err.Error())
+		case service.ErrInvalidSlotTime, service.ErrInvalidSlotWindow, service.ErrSlotExceedsVehicle, service.ErrInvalidCapacity:
+			utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		default:
+			utils.RespondWithError(c, http.StatusInternalServerError, "Failed to create slot")
+		}
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusCreated, "Slot created successfully", slot)
+}
+
+// GetVehicleCalendar gets a vehicle's availability calendar
+// @Summary Get vehicle calendar
+// @Description Get availability slots and their remaining capacity for a vehicle in a date range
+// @Tags vehicles
+// @Produce json
+// @Param id path string true "Vehicle ID"
+// @Param from query string false "Start of range (RFC3339)" default(now)
+// @Param to query string false "End of range (RFC3339)" default(now+7d)
+// @Success 200 {object} utils.SuccessResponse{data=dto.VehicleCalendarResponse} "Calendar retrieved successfully"
+// @Failure 400 {object} utils.ErrorResponse "Invalid input"
+// @Failure 404 {object} utils.ErrorResponse "Vehicle not found"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /transporters/vehicles/{id}/calendar [get]
+func (h *AvailabilityHandler) GetVehicleCalendar(c *gin.Context) {
+	vehicleID, from, to, ok := parseCalendarRequest(c)
+	if !ok {
+		return
+	}
+
+	calendar, err := h.availabilityService.GetVehicleCalendar(c.Request.Context(), vehicleID, from, to)
+	if err != nil {
+		switch err {
+		case service.ErrVehicleNotFound:
+			utils.RespondWithError(c, http.StatusNotFound, err.Error())
+		default:
+			utils.RespondWithError(c, http.StatusInternalServerError, "Failed to retrieve vehicle calendar")
+		}
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, "Calendar retrieved successfully", calendar)
+}
+
+// GetVehicleBookingCalendar gets a vehicle's calendar with its bookings
+// @Summary Get vehicle calendar with bookings
+// @Description Get availability slots and the bookings on them for one of your vehicles, or any vehicle as an admin
+// @Tags vehicles
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Vehicle ID"
+// @Param from query string false "Start of range (RFC3339)" default(now)
+// @Param to query string false "End of range (RFC3339)" default(now+7d)
+// @Success 200 {object} utils.SuccessResponse{data=dto.VehicleCalendarResponse} "Calendar retrieved successfully"
+// @Failure 400 {object} utils.ErrorResponse "Invalid input"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 403 {object} utils.ErrorResponse "Forbidden"
+// @Failure 404 {object} utils.ErrorResponse "Vehicle not found"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /transporters/vehicles/{id}/calendar/bookings [get]
+func (h *AvailabilityHandler) GetVehicleBookingCalendar(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	vehicleID, from, to, ok := parseCalendarRequest(c)
+	if !ok {
+		return
+	}
+
+	// Admins see every vehicle; transporters only their own
+	var transporterID *uuid.UUID
+	if fmt.Sprintf("%v", c.MustGet(middleware.UserRoleContextKey)) != "admin" {
+		transporter, err := h.transporterService.GetTransporterByUserID(c.Request.Context(), userID)
+		if err != nil {
+			utils.RespondWithError(c, http.StatusNotFound, "Transporter profile not found")
+			return
+		}
+		transporterID = &transporter.ID
+	}
+
+	calendar, err := h.availabilityService.GetVehicleBookingCalendar(c.Request.Context(), vehicleID, transporterID, from, to)
+	if err != nil {
+		switch err {
+		case service.ErrVehicleNotFound:
+			utils.RespondWithError(c, http.StatusNotFound, err.Error())
+		case service.ErrUnauthorizedAccess:
+			utils.RespondWithError(c, http.StatusForbidden, err.Error())
+		default:
+			utils.RespondWithError(c, http.StatusInternalServerError, "Failed to retrieve vehicle calendar")
+		}
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, "Calendar retrieved successfully", calendar)
+}
+
+// parseCalendarRequest reads the vehicle ID and date range of a calendar
+// request, responding with an error if they are invalid.
+func parseCalendarRequest(c *gin.Context) (uuid.UUID, time.Time, time.Time, bool) {
+	vehicleID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid vehicle ID")
+		return uuid.Nil, time.Time{}, time.Time{}, false
+	}
+
+	from := time.Now()
+	if c.Query("from") != "" {
+		from, err = time.Parse(time.RFC3339, c.Query("from"))
+		if err != nil {
+			utils.RespondWithError(c, http.StatusBadRequest, "Invalid from parameter")
+			return uuid.Nil, time.Time{}, time.Time{}, false
+		}
+	}
+
+	to := from.AddDate(0, 0, 7)
+	if c.Query("to") != "" {
+		to, err = time.Parse(time.RFC3339, c.Query("to"))
+		if err != nil {
+			utils.RespondWithError(c, http.StatusBadRequest, "Invalid to parameter")
+			return uuid.Nil, time.Time{}, time.Time{}, false
+		}
+	}
+	return vehicleID, from, to, true
+}
+
+// UpdateSlotStatus opens or blocks an availability slot
+// @Summary Update slot status
+// @Description Open or block an availability slot
+// @Tags vehicles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param slotId path string true "Slot ID"
+// @Param request body dto.UpdateSlotStatusRequest true "Slot status"
+// @Success 200 {object} utils.SuccessResponse "Slot status updated successfully"
+// @Failure 400 {object} utils.ErrorResponse "Invalid input"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 403 {object} utils.ErrorResponse "Forbidden"
+// @Failure 404 {object} utils.ErrorResponse "Slot not found"
+// @Failure 409 {object} utils.ErrorResponse "Slot has active bookings"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /transporters/vehicles/slots/{slotId}/status [put]
+func (h *AvailabilityHandler) UpdateSlotStatus(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	slotID, err := uuid.Parse(c.Param("slotId"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid slot ID")
+		return
+	}
+
+	// Get transporter ID from user ID
+	transporter, err := h.transporterService.GetTransporterByUserID(c.Request.Context(), userID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusNotFound, "Transporter profile not found")
+		return
+	}
+
+	var req dto.UpdateSlotStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid input data: "+err.Error())
+		return
+	}
+
+	if err := utils.ValidateStruct(req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.availabilityService.UpdateSlotStatus(c.Request.Context(), slotID, transporter.ID, req.Status); err != nil {
+		switch err {
+		case service.ErrSlotNotFound, service.ErrVehicleNotFound:
+			utils.RespondWithError(c, http.StatusNotFound, err.Error())
+		case service.ErrUnauthorizedAccess:
+			utils.RespondWithError(c, http.StatusForbidden, err.Error())
+		case service.ErrSlotHasBookings:
+			utils.RespondWithError(c, http.StatusConflict, err.Error())
+		default:
+			utils.RespondWithError(c, http.StatusInternalServerError, "Failed to update slot status")
+		}
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, "Slot status updated successfully", nil)
+}
+
+// DeleteSlot removes an availability slot
+// @Summary Delete availability slot
+// @Description Remove an availability slot that has no active bookings
+// @Tags vehicles
+// @Produce json
+// @Security BearerAuth
+// @Param slotId path string true "Slot ID"
+// @Success 200 {object} utils.SuccessResponse "Slot deleted successfully"
+// @Failure 400 {object} utils.ErrorResponse "Invalid slot ID"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 403 {object} utils.ErrorResponse "Forbidden"
+// @Failure 404 {object} utils.ErrorResponse "Slot not found"
+// @Failure 409 {object} utils.ErrorResponse "Slot has active bookings"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /transporters/vehicles/slots/{slotId} [delete]
+func (h *AvailabilityHandler) DeleteSlot(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	slotID, err := uuid.Parse(c.Param("slotId"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid slot ID")
+		return
+	}
+
+	// Get transporter ID from user ID
+	transporter, err := h.transporterService.GetTransporterByUserID(c.Request.Context(), userID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusNotFound, "Transporter profile not found")
+		return
+	}
+
+	if err := h.availabilityService.DeleteSlot(c.Request.Context(), slotID, transporter.ID); err != nil {
+		switch err {
+		case service.ErrSlotNotFound, service.ErrVehicleNotFound:
+			utils.RespondWithError(c, http.StatusNotFound, err.Error())
+		case service.ErrUnauthorizedAccess:
+			utils.RespondWithError(c, http.StatusForbidden, err.Error())
+		case service.ErrSlotHasBookings:
+			utils.RespondWithError(c, http.StatusConflict, err.Error())
+		default:
+			utils.RespondWithError(c, http.StatusInternalServerError, "Failed to delete slot")
+		}
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, "Slot deleted successfully", nil)
+}
@@ -0,0 +1,281 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	dto "agro_konnect/internal/transporter/dto"
+	"agro_konnect/internal/transporter/service"
+	"agro_konnect/internal/transporter/utils"
+	"agro_konnect/pkg/geo"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ServiceAreaHandler struct {
+	transporterService service.TransporterService
+	serviceAreaService service.ServiceAreaService
+}
+
+func NewServiceAreaHandler(
+	transporterService service.TransporterService,
+	serviceAreaService service.ServiceAreaService,
+) *ServiceAreaHandler {
+	return &ServiceAreaHandler{
+		transporterService: transporterService,
+		serviceAreaService: serviceAreaService,
+	}
+}
+
+// GetServiceAreas lists a transporter's service areas
+// @Summary Get service areas
+// @Description Get the radius and polygon areas a transporter serves
+// @Tags transporters
+// @Produce json
+// @Param id path string true "Transporter ID"
+// @Success 200 {object} utils.SuccessResponse{data=[]dto.ServiceAreaResponse} "Service areas retrieved successfully"
+// @Failure 400 {object} utils.ErrorResponse "Invalid transporter ID"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /transporters/{id}/service-areas [get]
+func (h *ServiceAreaHandler) GetServiceAreas(c *gin.Context) {
+	transporterID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid transporter ID")
+		return
+	}
+
+	areas, err := h.serviceAreaService.GetServiceAreas(c.Request.Context(), transporterID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to retrieve service areas")
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, "Service areas retrieved successfully", areas)
+}
+
+// FindCoveringTransporters finds transporters serving a pickup and/or drop point
+// @Summary Find transporters by coverage
+// @Description Get verified transporters whose service areas contain the pickup and/or drop coordinates
+// @Tags transporters
+// @Produce json
+// @Param pickup_lat query number false "Pickup latitude"
+// @Param pickup_lng query number false "Pickup longitude"
+// @Param drop_lat query number false "Drop latitude"
+// @Param drop_lng query number false "Drop longitude"
+// @Success 200 {object} utils.SuccessResponse{data=[]dto.TransporterResponse} "Transporters retrieved successfully"
+// @Failure 400 {object} utils.ErrorResponse "Invalid coordinates"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /transporters/coverage [get]
+func (h *ServiceAreaHandler) FindCoveringTransporters(c *gin.Context) {
+	pickup, err := ParsePointQuery(c, "pickup")
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	drop, err := ParsePointQuery(c, "drop")
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if pickup == nil && drop == nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "A pickup or drop coordinate is required")
+		return
+	}
+
+	transporters, err := h.transporterService.FindTransportersByServiceArea(c.Request.Context(), pickup, drop)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to find transporters")
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, "Transporters retrieved successfully", transporters)
+}
+
+// AddServiceArea adds a service area to the current transporter
+// @Summary Add service area
+// @Description Add a radius or GeoJSON polygon service area
+// @Tags transporters
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.ServiceAreaRequest true "Service area"
+// @Success 201 {object} utils.SuccessResponse{data=dto.ServiceAreaResponse} "Service area added successfully"
+// @Failure 400 {object} utils.ErrorResponse "Invalid input data"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 404 {object} utils.ErrorResponse "Transporter profile not found"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /transporters/me/service-areas [post]
+func (h *ServiceAreaHandler) AddServiceArea(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	// Get transporter ID from user ID
+	transporter, err := h.transporterService.GetTransporterByUserID(c.Request.Context(), userID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusNotFound, "Transporter profile not found")
+		return
+	}
+
+	var req dto.ServiceAreaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid input data: "+err.Error())
+		return
+	}
+
+	if err := utils.ValidateStruct(req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	area, err := h.serviceAreaService.AddServiceArea(c.Request.Context(), transporter.ID, &req)
+	if err != nil {
+		switch err {
+		case service.ErrInvalidServiceArea:
+			utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		default:
+			utils.RespondWithError(c, http.StatusInternalServerError, "Failed to add service area")
+		}
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusCreated, "Service area added successfully", area)
+}
+
+// UpdateServiceArea replaces one of the current transporter's service areas
+// @Summary Update service area
+// @Description Replace the shape or name of a service area
+// @Tags transporters
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param areaId path string true "Service area ID"
+// @Param request body dto.ServiceAreaRequest true "Service area"
+// @Success 200 {object} utils.SuccessResponse{data=dto.ServiceAreaResponse} "Service area updated successfully"
+// @Failure 400 {object} utils.ErrorResponse "Invalid input data"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 403 {object} utils.ErrorResponse "Forbidden"
+// @Failure 404 {object} utils.ErrorResponse "Service area not found"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /transporters/me/service-areas/{areaId} [put]
+func (h *ServiceAreaHandler) UpdateServiceArea(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	areaID, err := uuid.Parse(c.Param("areaId"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid service area ID")
+		return
+	}
+
+	// Get transporter ID from user ID
+	transporter, err := h.transporterService.GetTransporterByUserID(c.Request.Context(), userID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusNotFound, "Transporter profile not found")
+		return
+	}
+
+	var req dto.ServiceAreaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid input data: "+err.Error())
+		return
+	}
+
+	if err := utils.ValidateStruct(req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	area, err := h.serviceAreaService.UpdateServiceArea(c.Request.Context(), areaID, transporter.ID, &req)
+	if err != nil {
+		switch err {
+		case service.ErrServiceAreaNotFound:
+			utils.RespondWithError(c, http.StatusNotFound, err.Error())
+		case service.ErrUnauthorizedAccess:
+			utils.RespondWithError(c, http.StatusForbidden, err.Error())
+		case service.ErrInvalidServiceArea:
+			utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		default:
+			utils.RespondWithError(c, http.StatusInternalServerError, "Failed to update service area")
+		}
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, "Service area updated successfully", area)
+}
+
+// DeleteServiceArea removes one of the current transporter's service areas
+// @Summary Delete service area
+// @Description Remove a service area
+// @Tags transporters
+// @Produce json
+// @Security BearerAuth
+// @Param areaId path string true "Service area ID"
+// @Success 200 {object} utils.SuccessResponse "Service area deleted successfully"
+// @Failure 400 {object} utils.ErrorResponse "Invalid service area ID"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 403 {object} utils.ErrorResponse "Forbidden"
+// @Failure 404 {object} utils.ErrorResponse "Service area not found"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /transporters/me/service-areas/{areaId} [delete]
+func (h *ServiceAreaHandler) DeleteServiceArea(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	areaID, err := uuid.Parse(c.Param("areaId"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid service area ID")
+		return
+	}
+
+	// Get transporter ID from user ID
+	transporter, err := h.transporterService.GetTransporterByUserID(c.Request.Context(), userID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusNotFound, "Transporter profile not found")
+		return
+	}
+
+	if err := h.serviceAreaService.DeleteServiceArea(c.Request.Context(), areaID, transporter.ID); err != nil {
+		switch err {
+		case service.ErrServiceAreaNotFound:
+			utils.RespondWithError(c, http.StatusNotFound, err.Error())
+		case service.ErrUnauthorizedAccess:
+			utils.RespondWithError(c, http.StatusForbidden, err.Error())
+		default:
+			utils.RespondWithError(c, http.StatusInternalServerError, "Failed to delete service area")
+		}
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, "Service area deleted successfully", nil)
+}
+
+// ParsePointQuery reads <prefix>_lat and <prefix>_lng query parameters. It
+// returns nil when neither is present.
+func ParsePointQuery(c *gin.Context, prefix string) (*geo.Point, error) {
+	latStr, lngStr := c.Query(prefix+"_lat"), c.Query(prefix+"_lng")
+	if latStr == "" && lngStr == "" {
+		return nil, nil
+	}
+
+	lat, err := strconv.ParseFloat(latStr, 64)
+	if err != nil || lat < -90 || lat > 90 {
+		return nil, fmt.Errorf("invalid %s_lat", prefix)
+	}
+	lng, err := strconv.ParseFloat(lngStr, 64)
+	if err != nil || lng < -180 || lng > 180 {
+		return nil, fmt.Errorf("invalid %s_lng", prefix)
+	}
+
+	return &geo.Point{Lat: lat, Lng: lng}, nil
+}
@@ -0,0 +1,284 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	dto "agro_konnect/internal/transporter/dto"
+	model "agro_konnect/internal/transporter/model"
+	"agro_konnect/internal/transporter/service"
+	"agro_konnect/internal/transporter/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type SettlementHandler struct {
+	transporterService service.TransporterService
+	settlementService  service.SettlementService
+}
+
+func NewSettlementHandler(
+	transporterService service.TransporterService,
+	settlementService service.SettlementService,
+) *SettlementHandler {
+	return &SettlementHandler{
+		transporterService: transporterService,
+		settlementService:  settlementService,
+	}
+}
+
+// GetMyEarnings gets the current transporter's earnings
+// @Summary Get my earnings
+// @Description Get per-order earnings and payout totals for the current transporter, optionally as CSV
+// @Tags settlements
+// @Produce json
+// @Produce text/csv
+// @Security BearerAuth
+// @Param from query string false "Delivered on or after (RFC3339)"
+// @Param to query string false "Delivered before (RFC3339)"
+// @Param format query string false "Response format" Enums(json, csv)
+// @Success 200 {object} utils.SuccessResponse{data=dto.EarningsResponse} "Earnings retrieved successfully"
+// @Failure 400 {object} utils.ErrorResponse "Invalid input"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 404 {object} utils.ErrorResponse "Transporter profile not found"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /transporters/me/earnings [get]
+func (h *SettlementHandler) GetMyEarnings(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	// Get transporter ID from user ID
+	transporter, err := h.transporterService.GetTransporterByUserID(c.Request.Context(), userID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusNotFound, "Transporter profile not found")
+		return
+	}
+
+	var from, to time.Time
+	if c.Query("from") != "" {
+		from, err = time.Parse(time.RFC3339, c.Query("from"))
+		if err != nil {
+			utils.RespondWithError(c, http.StatusBadRequest, "Invalid from parameter")
+			return
+		}
+	}
+	if c.Query("to") != "" {
+		to, err = time.Parse(time.RFC3339, c.Query("to"))
+		if err != nil {
+			utils.RespondWithError(c, http.StatusBadRequest, "Invalid to parameter")
+			return
+		}
+	}
+
+	if c.Query("format") == "csv" {
+		data, err := h.settlementService.ExportEarningsCSV(c.Request.Context(), transporter.ID, from, to)
+		if err != nil {
+			utils.RespondWithError(c, http.StatusInternalServerError, "Failed to export earnings")
+			return
+		}
+
+		filename := fmt.Sprintf("earnings-%s.csv", time.Now().Format("20060102"))
+		c.Header("Content-Disposition", "attachment; filename="+filename)
+		c.Data(http.StatusOK, "text/csv", data)
+		return
+	}
+
+	earnings, err := h.settlementService.GetEarnings(c.Request.Context(), transporter.ID, from, to)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to retrieve earnings")
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, "Earnings retrieved successfully", earnings)
+}
+
+// GetMyStatements lists the current transporter's settlement statements
+// @Summary Get my statements
+// @Description Get weekly settlement statements for the current transporter
+// @Tags settlements
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "Statement status" Enums(pending, paid)
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(10)
+// @Success 200 {object} utils.SuccessResponse{data=dto.StatementListResponse} "Statements retrieved successfully"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 404 {object} utils.ErrorResponse "Transporter profile not found"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /transporters/me/statements [get]
+func (h *SettlementHandler) GetMyStatements(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	// Get transporter ID from user ID
+	transporter, err := h.transporterService.GetTransporterByUserID(c.Request.Context(), userID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusNotFound, "Transporter profile not found")
+		return
+	}
+
+	page, pageSize := parsePagination(c)
+	statements, err := h.settlementService.GetStatements(c.Request.Context(), transporter.ID, model.StatementStatus(c.Query("status")), page, pageSize)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to retrieve statements")
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, "Statements retrieved successfully", statements)
+}
+
+// ListStatements lists settlement statements for all transporters (admin only)
+// @Summary List statements
+// @Description Get settlement statements across transporters (admin only)
+// @Tags settlements
+// @Produce json
+// @Security BearerAuth
+// @Param transporter_id query string false "Transporter ID"
+// @Param status query string false "Statement status" Enums(pending, paid)
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(10)
+// @Success 200 {object} utils.SuccessResponse{data=dto.StatementListResponse} "Statements retrieved successfully"
+// @Failure 400 {object} utils.ErrorResponse "Invalid transporter ID"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 403 {object} utils.ErrorResponse "Forbidden"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /transporters/settlements/statements [get]
+func (h *SettlementHandler) ListStatements(c *gin.Context) {
+	transporterID := uuid.Nil
+	if c.Query("transporter_id") != "" {
+		id, err := uuid.Parse(c.Query("transporter_id"))
+		if err != nil {
+			utils.RespondWithError(c, http.StatusBadRequest, "Invalid transporter ID")
+			return
+		}
+		transporterID = id
+	}
+
+	page, pageSize := parsePagination(c)
+	statements, err := h.settlementService.GetStatements(c.Request.Context(), transporterID, model.StatementStatus(c.Query("status")), page, pageSize)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to retrieve statements")
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, "Statements retrieved successfully", statements)
+}
+
+// GenerateStatements produces weekly settlement statements (admin only)
+// @Summary Generate weekly statements
+// @Description Accrue any unrecorded deliveries and roll unsettled earnings into one statement per transporter for a finished week (admin only)
+// @Tags settlements
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.GenerateStatementsRequest false "Week to close"
+// @Success 201 {object} utils.SuccessResponse{data=dto.StatementRunResponse} "Statements generated successfully"
+// @Failure 400 {object} utils.ErrorResponse "Invalid input"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 403 {object} utils.ErrorResponse "Forbidden"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /transporters/settlements/statements [post]
+func (h *SettlementHandler) GenerateStatements(c *gin.Context) {
+	var req dto.GenerateStatementsRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.RespondWithError(c, http.StatusBadRequest, "Invalid input data: "+err.Error())
+			return
+		}
+	}
+
+	weekOf := service.LastWeek()
+	if req.WeekOf != "" {
+		var err error
+		weekOf, err = time.Parse(time.RFC3339, req.WeekOf)
+		if err != nil {
+			utils.RespondWithError(c, http.StatusBadRequest, service.ErrInvalidStatementWeek.Error())
+			return
+		}
+	}
+
+	run, err := h.settlementService.GenerateWeeklyStatements(c.Request.Context(), weekOf)
+	if err != nil {
+		switch err {
+		case service.ErrStatementWeekNotEnded:
+			utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		default:
+			utils.RespondWithError(c, http.StatusInternalServerError, "Failed to generate statements")
+		}
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusCreated, "Statements generated successfully", run)
+}
+
+// MarkStatementPaid records the payout of a statement (admin only)
+// @Summary Mark statement paid
+// @Description Mark a pending settlement statement as paid with a payment reference (admin only)
+// @Tags settlements
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param statementId path string true "Statement ID"
+// @Param request body dto.MarkStatementPaidRequest true "Payment reference"
+// @Success 200 {object} utils.SuccessResponse{data=dto.StatementResponse} "Statement marked as paid"
+// @Failure 400 {object} utils.ErrorResponse "Invalid input"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 403 {object} utils.ErrorResponse "Forbidden"
+// @Failure 404 {object} utils.ErrorResponse "Statement not found"
+// @Failure 409 {object} utils.ErrorResponse "Statement already paid"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /transporters/settlements/statements/{statementId}/paid [put]
+func (h *SettlementHandler) MarkStatementPaid(c *gin.Context) {
+	statementID, err := uuid.Parse(c.Param("statementId"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid statement ID")
+		return
+	}
+
+	var req dto.MarkStatementPaidRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid input data: "+err.Error())
+		return
+	}
+
+	if err := utils.ValidateStruct(req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	statement, err := h.settlementService.MarkStatementPaid(c.Request.Context(), statementID, req.PaymentReference)
+	if err != nil {
+		switch err {
+		case service.ErrStatementNotFound:
+			utils.RespondWithError(c, http.StatusNotFound, err.Error())
+		case service.ErrStatementAlreadyPaid:
+			utils.RespondWithError(c, http.StatusConflict, err.Error())
+		default:
+			utils.RespondWithError(c, http.StatusInternalServerError, "Failed to mark statement paid")
+		}
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, "Statement marked as paid", statement)
+}
+
+// parsePagination reads page and page_size, defaulting to 1 and 10.
+func parsePagination(c *gin.Context) (int, int) {
+	page, pageSize := 1, 10
+	if v, err := strconv.Atoi(c.Query("page")); err == nil && v > 0 {
+		page = v
+	}
+	if v, err := strconv.Atoi(c.Query("page_size")); err == nil && v > 0 && v <= 100 {
+		pageSize = v
+	}
+	return page, pageSize
+}
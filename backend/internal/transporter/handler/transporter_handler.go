@@ -2,6 +2,7 @@ package handler
 
 import (
 	"net/http"
+	"strconv"
 
 	dto "agro_konnect/internal/transporter/dto"
 	"agro_konnect/internal/transporter/service"
@@ -64,7 +65,7 @@ func (h *TransporterHandler) CreateTransporter(c *gin.Context) {
 		switch err {
 		case service.ErrTransporterAlreadyExists:
 			utils.RespondWithError(c, http.StatusConflict, err.Error())
-		case service.ErrInvalidCapacity:
+		case service.ErrInvalidCapacity, service.ErrInvalidServiceArea:
 			utils.RespondWithError(c, http.StatusBadRequest, err.Error())
 		default:
 			utils.RespondWithError(c, http.StatusInternalServerError, "Failed to create transporter profile")
@@ -141,7 +142,7 @@ func (h *TransporterHandler) GetMyTransporterProfile(c *gin.Context) {
 
 // UpdateTransporter updates a transporter profile
 // @Summary Update transporter profile
-// @Description Update a transporter profile for the authenticated user
+// @Description Update a transporter profile for the authenticated user. Service areas are kept unless service_areas is sent; an empty list clears them.
 // @Tags transporters
 // @Accept json
 // @Produce json
@@ -187,7 +188,7 @@ func (h *TransporterHandler) UpdateTransporter(c *gin.Context) {
 			utils.RespondWithError(c, http.StatusNotFound, err.Error())
 		case service.ErrUnauthorizedAccess:
 			utils.RespondWithError(c, http.StatusForbidden, err.Error())
-		case service.ErrInvalidCapacity:
+		case service.ErrInvalidCapacity, service.ErrInvalidServiceArea:
 			utils.RespondWithError(c, http.StatusBadRequest, err.Error())
 		default:
 			utils.RespondWithError(c, http.StatusInternalServerError, "Failed to update transporter profile")
@@ -247,7 +248,11 @@ func (h *TransporterHandler) DeleteTransporter(c *gin.Context) {
 // @Produce json
 // @Param city query string false "City filter"
 // @Param state query string false "State filter"
-// @Param service_area query string false "Service area filter"
+// @Param service_area query string false "Service area name filter"
+// @Param pickup_lat query number false "Only transporters whose service areas cover this pickup latitude"
+// @Param pickup_lng query number false "Pickup longitude"
+// @Param drop_lat query number false "Only transporters whose service areas cover this drop latitude"
+// @Param drop_lng query number false "Drop longitude"
 // @Param vehicle_type query string false "Vehicle type filter"
 // @Param min_capacity query number false "Minimum capacity filter"
 // @Param max_capacity query number false "Maximum capacity filter"
@@ -263,9 +268,10 @@ func (h *TransporterHandler) DeleteTransporter(c *gin.Context) {
 // @Router /transporters [get]
 func (h *TransporterHandler) GetAllTransporters(c *gin.Context) {
 	var filters dto.TransporterFilterRequest
+	var err error
 
 	// Bind query parameters
-	if err := c.ShouldBindQuery(&filters); err != nil {
+	if err = c.ShouldBindQuery(&filters); err != nil {
 		utils.RespondWithError(c, http.StatusBadRequest, "Invalid query parameters: "+err.Error())
 		return
 	}
@@ -278,6 +284,16 @@ func (h *TransporterHandler) GetAllTransporters(c *gin.Context) {
 		filters.PageSize = 10
 	}
 
+	filters.ServiceArea = c.Query("service_area")
+	if filters.Pickup, err = ParsePointQuery(c, "pickup"); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if filters.Drop, err = ParsePointQuery(c, "drop"); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	response, err := h.transporterService.GetAllTransporters(c.Request.Context(), filters)
 	if err != nil {
 		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to retrieve transporters")
@@ -735,9 +751,12 @@ func (h *TransporterHandler) DeleteVehicle(c *gin.Context) {
 // @Param transporter_id query string false "Transporter ID filter"
 // @Param vehicle_type query string false "Vehicle type filter"
 // @Param is_available query boolean false "Availability filter"
-// @Param min_capacity query number false "Minimum capacity filter"
+// @Param min_capacity query number false "Minimum capacity filter; within an availability window, the free weight needed in tons"
+// @Param min_volume query number false "Minimum volume filter; within an availability window, the free volume needed in cubic meters"
 // @Param max_capacity query number false "Maximum capacity filter"
 // @Param location query string false "Location filter"
+// @Param available_from query string false "Start of availability window (RFC3339)"
+// @Param available_to query string false "End of availability window (RFC3339)"
 // @Param page query integer false "Page number" default(1)
 // @Param page_size query integer false "Page size" default(10)
 // @Success 200 {object} utils.SuccessResponse{data=[]dto.VehicleResponse} "Vehicles retrieved successfully"
@@ -760,9 +779,29 @@ func (h *TransporterHandler) GetAvailableVehicles(c *gin.Context) {
 		filters.PageSize = 10
 	}
 
+	// Availability window and the free capacity needed in it
+	filters.AvailableFrom = c.Query("available_from")
+	filters.AvailableTo = c.Query("available_to")
+	for param, field := range map[string]*float64{"min_capacity": &filters.MinCapacity, "min_volume": &filters.MinVolume} {
+		if c.Query(param) == "" {
+			continue
+		}
+		value, err := strconv.ParseFloat(c.Query(param), 64)
+		if err != nil || value < 0 {
+			utils.RespondWithError(c, http.StatusBadRequest, "Invalid "+param+" parameter")
+			return
+		}
+		*field = value
+	}
+
 	vehicles, err := h.vehicleService.GetAvailableVehicles(c.Request.Context(), filters)
 	if err != nil {
-		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to retrieve available vehicles")
+		switch err {
+		case service.ErrInvalidAvailabilityWindow:
+			utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		default:
+			utils.RespondWithError(c, http.StatusInternalServerError, "Failed to retrieve available vehicles")
+		}
 		return
 	}
 
@@ -0,0 +1,331 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	dto "agro_konnect/internal/transporter/dto"
+	"agro_konnect/internal/transporter/service"
+	"agro_konnect/internal/transporter/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type TripHandler struct {
+	transporterService service.TransporterService
+	tripService        service.TripService
+}
+
+func NewTripHandler(
+	transporterService service.TransporterService,
+	tripService service.TripService,
+) *TripHandler {
+	return &TripHandler{
+		transporterService: transporterService,
+		tripService:        tripService,
+	}
+}
+
+// CreateTrip plans a multi-stop trip for assigned orders
+// @Summary Create trip
+// @Description Group orders assigned to the transporter onto one vehicle and sequence their pickup and drop stops. Orders not yet booked on the vehicle's calendar are booked for the trip.
+// @Tags trips
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.CreateTripRequest true "Trip data"
+// @Success 201 {object} utils.SuccessResponse{data=dto.TripResponse} "Trip created successfully"
+// @Failure 400 {object} utils.ErrorResponse "Invalid input data, or the vehicle is not free for the trip"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 403 {object} utils.ErrorResponse "Forbidden"
+// @Failure 404 {object} utils.ErrorResponse "Vehicle or order not found"
+// @Failure 409 {object} utils.ErrorResponse "Order already in an active trip or booked on another vehicle"
+// @Failure 422 {object} utils.ErrorResponse "Combined load exceeds vehicle capacity"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /transporters/trips [post]
+func (h *TripHandler) CreateTrip(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	// Get transporter ID from user ID
+	transporter, err := h.transporterService.GetTransporterByUserID(c.Request.Context(), userID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusNotFound, "Transporter profile not found")
+		return
+	}
+
+	var req dto.CreateTripRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid input data: "+err.Error())
+		return
+	}
+
+	if err := utils.ValidateStruct(req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	trip, err := h.tripService.CreateTrip(c.Request.Context(), transporter.ID, &req)
+	if err != nil {
+		switch err {
+		case service.ErrVehicleNotFound, service.ErrTripOrderNotFound:
+			utils.RespondWithError(c, http.StatusNotFound, err.Error())
+		case service.ErrUnauthorizedAccess, service.ErrTripOrderNotAssigned:
+			utils.RespondWithError(c, http.StatusForbidden, err.Error())
+		case service.ErrTripOrderInActiveTrip, service.ErrTripOrderOnOtherVehicle:
+			utils.RespondWithError(c, http.StatusConflict, err.Error())
+		case service.ErrTripOverCapacity:
+			utils.RespondWithError(c, http.StatusUnprocessableEntity, err.Error())
+		case service.ErrInvalidTripTime, service.ErrDuplicateTripOrder, service.ErrTripOrderNotShippable,
+			service.ErrTripMissingLocation, service.ErrVehicleUnavailable:
+			utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		default:
+			utils.RespondWithError(c, http.StatusInternalServerError, "Failed to create trip")
+		}
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusCreated, "Trip created successfully", trip)
+}
+
+// GetMyTrips lists the current transporter's trips
+// @Summary Get my trips
+// @Description Get trips planned by the current transporter
+// @Tags trips
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "Trip status" Enums(planned, in_progress, completed, cancelled)
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(10)
+// @Success 200 {object} utils.SuccessResponse{data=dto.TripListResponse} "Trips retrieved successfully"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 404 {object} utils.ErrorResponse "Transporter profile not found"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /transporters/trips [get]
+func (h *TripHandler) GetMyTrips(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	// Get transporter ID from user ID
+	transporter, err := h.transporterService.GetTransporterByUserID(c.Request.Context(), userID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusNotFound, "Transporter profile not found")
+		return
+	}
+
+	filters := dto.TripFilterRequest{
+		Status:   c.Query("status"),
+		Page:     1,
+		PageSize: 10,
+	}
+	if page, err := strconv.Atoi(c.Query("page")); err == nil && page > 0 {
+		filters.Page = page
+	}
+	if pageSize, err := strconv.Atoi(c.Query("page_size")); err == nil && pageSize > 0 && pageSize <= 100 {
+		filters.PageSize = pageSize
+	}
+
+	trips, err := h.tripService.GetMyTrips(c.Request.Context(), transporter.ID, filters)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to retrieve trips")
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, "Trips retrieved successfully", trips)
+}
+
+// GetTrip gets a trip with its ordered stops
+// @Summary Get trip
+// @Description Get a trip and its sequenced pickup and drop stops
+// @Tags trips
+// @Produce json
+// @Security BearerAuth
+// @Param tripId path string true "Trip ID"
+// @Success 200 {object} utils.SuccessResponse{data=dto.TripResponse} "Trip retrieved successfully"
+// @Failure 400 {object} utils.ErrorResponse "Invalid trip ID"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 403 {object} utils.ErrorResponse "Forbidden"
+// @Failure 404 {object} utils.ErrorResponse "Trip not found"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /transporters/trips/{tripId} [get]
+func (h *TripHandler) GetTrip(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	tripID, err := uuid.Parse(c.Param("tripId"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid trip ID")
+		return
+	}
+
+	// Get transporter ID from user ID
+	transporter, err := h.transporterService.GetTransporterByUserID(c.Request.Context(), userID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusNotFound, "Transporter profile not found")
+		return
+	}
+
+	trip, err := h.tripService.GetTrip(c.Request.Context(), tripID, transporter.ID)
+	if err != nil {
+		switch err {
+		case service.ErrTripNotFound:
+			utils.RespondWithError(c, http.StatusNotFound, err.Error())
+		case service.ErrUnauthorizedAccess:
+			utils.RespondWithError(c, http.StatusForbidden, err.Error())
+		default:
+			utils.RespondWithError(c, http.StatusInternalServerError, "Failed to retrieve trip")
+		}
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, "Trip retrieved successfully", trip)
+}
+
+// UpdateTripStatus starts, completes or cancels a trip
+// @Summary Update trip status
+// @Description Move a trip to in_progress, completed or cancelled
+// @Tags trips
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param tripId path string true "Trip ID"
+// @Param request body dto.UpdateTripStatusRequest true "Trip status"
+// @Success 200 {object} utils.SuccessResponse{data=dto.TripResponse} "Trip status updated successfully"
+// @Failure 400 {object} utils.ErrorResponse "Invalid input"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 403 {object} utils.ErrorResponse "Forbidden"
+// @Failure 404 {object} utils.ErrorResponse "Trip not found"
+// @Failure 409 {object} utils.ErrorResponse "Invalid status transition"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /transporters/trips/{tripId}/status [put]
+func (h *TripHandler) UpdateTripStatus(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	tripID, err := uuid.Parse(c.Param("tripId"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid trip ID")
+		return
+	}
+
+	// Get transporter ID from user ID
+	transporter, err := h.transporterService.GetTransporterByUserID(c.Request.Context(), userID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusNotFound, "Transporter profile not found")
+		return
+	}
+
+	var req dto.UpdateTripStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid input data: "+err.Error())
+		return
+	}
+
+	if err := utils.ValidateStruct(req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	trip, err := h.tripService.UpdateTripStatus(c.Request.Context(), tripID, transporter.ID, req.Status)
+	if err != nil {
+		switch err {
+		case service.ErrTripNotFound:
+			utils.RespondWithError(c, http.StatusNotFound, err.Error())
+		case service.ErrUnauthorizedAccess:
+			utils.RespondWithError(c, http.StatusForbidden, err.Error())
+		case service.ErrInvalidTripTransition:
+			utils.RespondWithError(c, http.StatusConflict, err.Error())
+		default:
+			utils.RespondWithError(c, http.StatusInternalServerError, "Failed to update trip status")
+		}
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, "Trip status updated successfully", trip)
+}
+
+// UpdateStopStatus marks a trip stop as completed or skipped
+// @Summary Update stop status
+// @Description Record that a pickup or drop stop was completed or skipped
+// @Tags trips
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param tripId path string true "Trip ID"
+// @Param stopId path string true "Stop ID"
+// @Param request body dto.UpdateStopStatusRequest true "Stop status"
+// @Success 200 {object} utils.SuccessResponse{data=dto.TripResponse} "Stop status updated successfully"
+// @Failure 400 {object} utils.ErrorResponse "Invalid input"
+// @Failure 401 {object} utils.ErrorResponse "Unauthorized"
+// @Failure 403 {object} utils.ErrorResponse "Forbidden"
+// @Failure 404 {object} utils.ErrorResponse "Trip or stop not found"
+// @Failure 409 {object} utils.ErrorResponse "Trip not in progress or stop out of order"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /transporters/trips/{tripId}/stops/{stopId}/status [put]
+func (h *TripHandler) UpdateStopStatus(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	tripID, err := uuid.Parse(c.Param("tripId"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid trip ID")
+		return
+	}
+
+	stopID, err := uuid.Parse(c.Param("stopId"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid stop ID")
+		return
+	}
+
+	// Get transporter ID from user ID
+	transporter, err := h.transporterService.GetTransporterByUserID(c.Request.Context(), userID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusNotFound, "Transporter profile not found")
+		return
+	}
+
+	var req dto.UpdateStopStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid input data: "+err.Error())
+		return
+	}
+
+	if err := utils.ValidateStruct(req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	trip, err := h.tripService.UpdateStopStatus(c.Request.Context(), tripID, stopID, transporter.ID, req.Status)
+	if err != nil {
+		switch err {
+		case service.ErrTripNotFound, service.ErrTripStopNotFound:
+			utils.RespondWithError(c, http.StatusNotFound, err.Error())
+		case service.ErrUnauthorizedAccess:
+			utils.RespondWithError(c, http.StatusForbidden, err.Error())
+		case service.ErrInvalidTripTransition, service.ErrStopOutOfOrder:
+			utils.RespondWithError(c, http.StatusConflict, err.Error())
+		default:
+			utils.RespondWithError(c, http.StatusInternalServerError, "Failed to update stop status")
+		}
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, "Stop status updated successfully", trip)
+}
@@ -3,6 +3,8 @@ package transporter
 import (
 	"time"
 
+	"agro_konnect/pkg/geo"
+
 	"github.com/google/uuid"
 	"gorm.io/datatypes"
 )
@@ -32,11 +34,11 @@ type Transporter struct {
 	Description   string `json:"description"`
 
 	// Location & Coverage
-	Address      string         `gorm:"not null" json:"address"`
-	City         string         `gorm:"not null" json:"city"`
-	State        string         `gorm:"not null" json:"state"`
-	Country      string         `gorm:"not null" json:"country"`
-	ServiceAreas datatypes.JSON `gorm:"type:jsonb" json:"service_areas"` // Store as JSON array
+	Address      string        `gorm:"not null" json:"address"`
+	City         string        `gorm:"not null" json:"city"`
+	State        string        `gorm:"not null" json:"state"`
+	Country      string        `gorm:"not null" json:"country"`
+	ServiceAreas []ServiceArea `gorm:"foreignKey:TransporterID" json:"service_areas"`
 
 	// Contact Information
 	AlternatePhone string `json:"alternate_phone"`
@@ -94,3 +96,255 @@ type Vehicle struct {
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
+
+type SlotStatus string
+
+const (
+	SlotStatusOpen    SlotStatus = "open"
+	SlotStatusFull    SlotStatus = "full"
+	SlotStatusBlocked SlotStatus = "blocked"
+)
+
+type BookingStatus string
+
+const (
+	BookingStatusBooked    BookingStatus = "booked"
+	BookingStatusCancelled BookingStatus = "cancelled"
+)
+
+// AvailabilitySlot is a window in a vehicle's calendar during which it can
+// carry consignments. Booked capacity is tracked so several small loads can
+// share the same slot.
+type AvailabilitySlot struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	VehicleID uuid.UUID `gorm:"type:uuid;not null;index" json:"vehicle_id"`
+
+	StartTime time.Time `gorm:"not null;index" json:"start_time"`
+	EndTime   time.Time `gorm:"not null;index" json:"end_time"`
+
+	// Capacity
+	Capacity TransportCapacity `gorm:"embedded;embeddedPrefix:capacity_" json:"capacity"`
+	Booked   TransportCapacity `gorm:"embedded;embeddedPrefix:booked_" json:"booked"`
+
+	Status SlotStatus `gorm:"type:varchar(20);default:'open'" json:"status"`
+	Notes  string     `json:"notes"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Remaining returns the capacity still free in the slot.
+func (s *AvailabilitySlot) Remaining() TransportCapacity {
+	return TransportCapacity{
+		Weight: s.Capacity.Weight - s.Booked.Weight,
+		Volume: s.Capacity.Volume - s.Booked.Volume,
+	}
+}
+
+// SlotBooking reserves part of a slot's capacity for an order.
+type SlotBooking struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	SlotID    uuid.UUID `gorm:"type:uuid;not null;index" json:"slot_id"`
+	VehicleID uuid.UUID `gorm:"type:uuid;not null;index" json:"vehicle_id"`
+	OrderID   uuid.UUID `gorm:"type:uuid;not null;index" json:"order_id"`
+	// TripID is set when the booking was made for a trip, so cancelling the
+	// trip can release it
+	TripID *uuid.UUID `gorm:"type:uuid;index" json:"trip_id,omitempty"`
+
+	StartTime time.Time         `gorm:"not null" json:"start_time"`
+	EndTime   time.Time         `gorm:"not null" json:"end_time"`
+	Load      TransportCapacity `gorm:"embedded;embeddedPrefix:load_" json:"load"`
+
+	Status BookingStatus `gorm:"type:varchar(20);default:'booked'" json:"status"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type TripStatus string
+
+const (
+	TripStatusPlanned    TripStatus = "planned"
+	TripStatusInProgress TripStatus = "in_progress"
+	TripStatusCompleted  TripStatus = "completed"
+	TripStatusCancelled  TripStatus = "cancelled"
+)
+
+type StopType string
+
+const (
+	StopTypePickup StopType = "pickup"
+	StopTypeDrop   StopType = "drop"
+)
+
+type StopStatus string
+
+const (
+	StopStatusPending   StopStatus = "pending"
+	StopStatusCompleted StopStatus = "completed"
+	StopStatusSkipped   StopStatus = "skipped"
+)
+
+// Trip groups several orders carried by one vehicle into a single run with
+// an ordered list of pickup and drop stops.
+type Trip struct {
+	ID            uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	TransporterID uuid.UUID `gorm:"type:uuid;not null;index" json:"transporter_id"`
+	VehicleID     uuid.UUID `gorm:"type:uuid;not null;index" json:"vehicle_id"`
+
+	Status TripStatus `gorm:"type:varchar(20);default:'planned'" json:"status"`
+
+	// Route
+	StartLatitude   float64           `json:"start_latitude"`
+	StartLongitude  float64           `json:"start_longitude"`
+	TotalDistanceKm float64           `gorm:"type:decimal(10,2)" json:"total_distance_km"`
+	TotalLoad       TransportCapacity `gorm:"embedded;embeddedPrefix:load_" json:"total_load"`
+
+	PlannedStart time.Time  `json:"planned_start"`
+	PlannedEnd   time.Time  `json:"planned_end"`
+	StartedAt    *time.Time `json:"started_at"`
+	CompletedAt  *time.Time `json:"completed_at"`
+	Notes        string     `json:"notes"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Relationships
+	Stops []TripStop `gorm:"foreignKey:TripID" json:"stops"`
+}
+
+// TripStop is a pickup or drop point of one order within a trip.
+type TripStop struct {
+	ID       uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	TripID   uuid.UUID `gorm:"type:uuid;not null;index" json:"trip_id"`
+	OrderID  uuid.UUID `gorm:"type:uuid;not null;index" json:"order_id"`
+	Sequence int       `gorm:"not null" json:"sequence"`
+
+	Type      StopType `gorm:"type:varchar(10);not null" json:"type"`
+	Address   string   `json:"address"`
+	Latitude  float64  `json:"latitude"`
+	Longitude float64  `json:"longitude"`
+
+	Load TransportCapacity `gorm:"embedded;embeddedPrefix:load_" json:"load"`
+
+	// Distance travelled from the previous stop
+	LegDistanceKm float64 `gorm:"type:decimal(10,2)" json:"leg_distance_km"`
+
+	Status      StopStatus `gorm:"type:varchar(20);default:'pending'" json:"status"`
+	CompletedAt *time.Time `json:"completed_at"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type ServiceAreaType string
+
+const (
+	ServiceAreaTypeRadius  ServiceAreaType = "radius"
+	ServiceAreaTypePolygon ServiceAreaType = "polygon"
+
+	// Named areas carry only a place name, as service areas did before they
+	// had a shape. They are kept for listing and search but never match a
+	// point.
+	ServiceAreaTypeNamed ServiceAreaType = "named"
+)
+
+// ServiceArea is a region a transporter covers, either a radius around a
+// point or a GeoJSON polygon. The bounding box is stored alongside the shape
+// so candidate areas can be narrowed in SQL without PostGIS.
+type ServiceArea struct {
+	ID            uuid.UUID       `gorm:"type:uuid;primary_key" json:"id"`
+	TransporterID uuid.UUID       `gorm:"type:uuid;not null;index" json:"transporter_id"`
+	Name          string          `gorm:"not null" json:"name"`
+	Type          ServiceAreaType `gorm:"type:varchar(10);not null" json:"type"`
+
+	// Radius areas
+	CenterLatitude  float64 `json:"center_latitude"`
+	CenterLongitude float64 `json:"center_longitude"`
+	RadiusKm        float64 `gorm:"type:decimal(10,2)" json:"radius_km"`
+
+	// Polygon areas, stored as a GeoJSON MultiPolygon geometry
+	Polygon datatypes.JSON `gorm:"type:jsonb" json:"polygon,omitempty"`
+
+	// Bounding box
+	MinLatitude  float64 `gorm:"index:idx_service_area_bounds" json:"-"`
+	MinLongitude float64 `gorm:"index:idx_service_area_bounds" json:"-"`
+	MaxLatitude  float64 `gorm:"index:idx_service_area_bounds" json:"-"`
+	MaxLongitude float64 `gorm:"index:idx_service_area_bounds" json:"-"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Covers reports whether the point lies inside the area.
+func (a *ServiceArea) Covers(p geo.Point) bool {
+	bounds := geo.BoundingBox{
+		MinLat: a.MinLatitude,
+		MinLng: a.MinLongitude,
+		MaxLat: a.MaxLatitude,
+		MaxLng: a.MaxLongitude,
+	}
+	if !bounds.Contains(p) {
+		return false
+	}
+
+	switch a.Type {
+	case ServiceAreaTypeRadius:
+		return geo.WithinRadius(geo.Point{Lat: a.CenterLatitude, Lng: a.CenterLongitude}, a.RadiusKm, p)
+	case ServiceAreaTypePolygon:
+		shape, err := geo.ParseGeoJSON(a.Polygon)
+		if err != nil {
+			return false
+		}
+		return shape.Contains(p)
+	}
+	return false
+}
+
+type StatementStatus string
+
+const (
+	StatementStatusPending StatementStatus = "pending"
+	StatementStatusPaid    StatementStatus = "paid"
+)
+
+// TransporterEarning is the amount owed to a transporter for one delivered
+// order: the order's shipping charge less the platform commission.
+type TransporterEarning struct {
+	ID            uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	TransporterID uuid.UUID `gorm:"type:uuid;not null;index" json:"transporter_id"`
+	OrderID       uuid.UUID `gorm:"type:uuid;not null;uniqueIndex" json:"order_id"`
+	OrderNumber   string    `json:"order_number"`
+
+	ShippingCharge   float64 `gorm:"type:decimal(10,2);not null" json:"shipping_charge"`
+	CommissionRate   float64 `gorm:"type:decimal(5,4);not null" json:"commission_rate"`
+	CommissionAmount float64 `gorm:"type:decimal(10,2);not null" json:"commission_amount"`
+	NetAmount        float64 `gorm:"type:decimal(10,2);not null" json:"net_amount"`
+
+	DeliveredAt time.Time  `gorm:"not null;index" json:"delivered_at"`
+	StatementID *uuid.UUID `gorm:"type:uuid;index" json:"statement_id"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SettlementStatement bundles a transporter's unsettled earnings for one week
+// into a single payout.
+type SettlementStatement struct {
+	ID            uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	TransporterID uuid.UUID `gorm:"type:uuid;not null;index" json:"transporter_id"`
+
+	PeriodStart time.Time `gorm:"not null;index" json:"period_start"`
+	PeriodEnd   time.Time `gorm:"not null" json:"period_end"`
+
+	OrderCount       int     `json:"order_count"`
+	GrossAmount      float64 `gorm:"type:decimal(12,2)" json:"gross_amount"`
+	CommissionAmount float64 `gorm:"type:decimal(12,2)" json:"commission_amount"`
+	NetAmount        float64 `gorm:"type:decimal(12,2)" json:"net_amount"`
+
+	Status           StatementStatus `gorm:"type:varchar(20);default:'pending'" json:"status"`
+	PaidAt           *time.Time      `json:"paid_at"`
+	PaymentReference string          `json:"payment_reference"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
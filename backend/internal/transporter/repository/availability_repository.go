@@ -0,0 +1,281 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	model "agro_konnect/internal/transporter/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrSlotCapacityExceeded = errors.New("slot does not have enough remaining capacity")
+	ErrSlotNotOpen          = errors.New("slot is not open for booking")
+)
+
+// capacityEpsilon absorbs decimal rounding when comparing capacities.
+const capacityEpsilon = 0.0001
+
+type AvailabilityRepository interface {
+	CreateSlot(ctx context.Context, slot *model.AvailabilitySlot) (bool, error)
+	FindSlotByID(ctx context.Context, id uuid.UUID) (*model.AvailabilitySlot, error)
+	FindSlotsByVehicle(ctx context.Context, vehicleID uuid.UUID, from, to time.Time) ([]*model.AvailabilitySlot, error)
+	FindOverlappingSlots(ctx context.Context, vehicleID uuid.UUID, start, end time.Time) ([]*model.AvailabilitySlot, error)
+	UpdateSlotStatus(ctx context.Context, slotID uuid.UUID, status model.SlotStatus) error
+	DeleteSlot(ctx context.Context, slotID uuid.UUID) error
+	CountActiveBookings(ctx context.Context, slotID uuid.UUID) (int64, error)
+	BookSlot(ctx context.Context, booking *model.SlotBooking) error
+	FindBookingsByOrder(ctx context.Context, orderID uuid.UUID) ([]*model.SlotBooking, error)
+	FindBookingsBySlot(ctx context.Context, slotID uuid.UUID) ([]*model.SlotBooking, error)
+	CancelBooking(ctx context.Context, bookingID uuid.UUID) error
+}
+
+type availabilityRepository struct {
+	db *gorm.DB
+}
+
+func NewAvailabilityRepository(db *gorm.DB) AvailabilityRepository {
+	return &availabilityRepository{db: db}
+}
+
+// CreateSlot saves a slot unless it overlaps another of the vehicle's
+// slots, reporting false if it does. The vehicle row is locked while it
+// checks, so two slots can't be created over each other at once.
+func (r *availabilityRepository) CreateSlot(ctx context.Context, slot *model.AvailabilitySlot) (bool, error) {
+	created := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var vehicle model.Vehicle
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", slot.VehicleID).
+			First(&vehicle).Error; err != nil {
+			return err
+		}
+
+		var overlapping int64
+		if err := tx.Model(&model.AvailabilitySlot{}).
+			Where("vehicle_id = ? AND start_time < ? AND end_time > ?", slot.VehicleID, slot.EndTime, slot.StartTime).
+			Count(&overlapping).Error; err != nil {
+			return err
+		}
+		if overlapping > 0 {
+			return nil
+		}
+
+		created = true
+		return tx.Create(slot).Error
+	})
+	return created && err == nil, err
+}
+
+func (r *availabilityRepository) FindSlotByID(ctx context.Context, id uuid.UUID) (*model.AvailabilitySlot, error) {
+	var slot model.AvailabilitySlot
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&slot).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &slot, err
+}
+
+func (r *availabilityRepository) FindSlotsByVehicle(ctx context.Context, vehicleID uuid.UUID, from, to time.Time) ([]*model.AvailabilitySlot, error) {
+	query := r.db.WithContext(ctx).Where("vehicle_id = ?", vehicleID)
+
+	if !from.IsZero() {
+		query = query.Where("end_time > ?", from)
+	}
+	if !to.IsZero() {
+		query = query.Where("start_time < ?", to)
+	}
+
+	var slots []*model.AvailabilitySlot
+	err := query.Order("start_time ASC").Find(&slots).Error
+	return slots, err
+}
+
+func (r *availabilityRepository) FindOverlappingSlots(ctx context.Context, vehicleID uuid.UUID, start, end time.Time) ([]*model.AvailabilitySlot, error) {
+	var slots []*model.AvailabilitySlot
+	err := r.db.WithContext(ctx).
+		Where("vehicle_id = ? AND start_time < ? AND end_time > ?", vehicleID, end, start).
+		Order("start_time ASC").
+		Find(&slots).Error
+	return slots, err
+}
+
+func (r *availabilityRepository) UpdateSlotStatus(ctx context.Context, slotID uuid.UUID, status model.SlotStatus) error {
+	return r.db.WithContext(ctx).Model(&model.AvailabilitySlot{}).
+		Where("id = ?", slotID).
+		Updates(map[string]interface{}{
+			"status":     status,
+			"updated_at": time.Now(),
+		}).Error
+}
+
+func (r *availabilityRepository) DeleteSlot(ctx context.Context, slotID uuid.UUID) error {
+	return r.db.WithContext(ctx).Where("id = ?", slotID).Delete(&model.AvailabilitySlot{}).Error
+}
+
+func (r *availabilityRepository) CountActiveBookings(ctx context.Context, slotID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.SlotBooking{}).
+		Where("slot_id = ? AND status = ?", slotID, model.BookingStatusBooked).
+		Count(&count).Error
+	return count, err
+}
+
+// BookSlot reserves the booking's load against its slot. The slot row is
+// locked for the duration of the transaction so concurrent assignments
+// cannot oversell the same vehicle.
+func (r *availabilityRepository) BookSlot(ctx context.Context, booking *model.SlotBooking) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return bookSlot(tx, booking)
+	})
+}
+
+// bookSlot books within the caller's transaction, locking the slot until it
+// ends. Nothing is written unless the booking fits.
+func bookSlot(tx *gorm.DB, booking *model.SlotBooking) error {
+	var slot model.AvailabilitySlot
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", booking.SlotID).
+		First(&slot).Error; err != nil {
+		return err
+	}
+
+	if slot.Status != model.SlotStatusOpen {
+		return ErrSlotNotOpen
+	}
+
+	if !fits(&slot, booking.Load) {
+		return ErrSlotCapacityExceeded
+	}
+
+	slot.Booked.Weight += booking.Load.Weight
+	slot.Booked.Volume += booking.Load.Volume
+	if isFull(&slot) {
+		slot.Status = model.SlotStatusFull
+	}
+
+	if err := tx.Model(&model.AvailabilitySlot{}).
+		Where("id = ?", slot.ID).
+		Updates(map[string]interface{}{
+			"booked_weight": slot.Booked.Weight,
+			"booked_volume": slot.Booked.Volume,
+			"status":        slot.Status,
+			"updated_at":    time.Now(),
+		}).Error; err != nil {
+		return err
+	}
+
+	return tx.Create(booking).Error
+}
+
+func (r *availabilityRepository) FindBookingsByOrder(ctx context.Context, orderID uuid.UUID) ([]*model.SlotBooking, error) {
+	var bookings []*model.SlotBooking
+	err := r.db.WithContext(ctx).
+		Where("order_id = ? AND status = ?", orderID, model.BookingStatusBooked).
+		Find(&bookings).Error
+	return bookings, err
+}
+
+func (r *availabilityRepository) FindBookingsBySlot(ctx context.Context, slotID uuid.UUID) ([]*model.SlotBooking, error) {
+	var bookings []*model.SlotBooking
+	err := r.db.WithContext(ctx).
+		Where("slot_id = ? AND status = ?", slotID, model.BookingStatusBooked).
+		Order("created_at ASC").
+		Find(&bookings).Error
+	return bookings, err
+}
+
+// CancelBooking releases the booked load back to its slot and reopens the
+// slot if it had been marked full.
+func (r *availabilityRepository) CancelBooking(ctx context.Context, bookingID uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return cancelBookings(tx, tx.Where("id = ?", bookingID))
+	})
+}
+
+// cancelBookings cancels the bookings scope selects within the caller's
+// transaction. Locking the bookings keeps two cancels from both releasing
+// their load.
+func cancelBookings(tx *gorm.DB, scope *gorm.DB) error {
+	var bookings []*model.SlotBooking
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where(scope).
+		Where("status = ?", model.BookingStatusBooked).
+		Order("id ASC").
+		Find(&bookings).Error; err != nil {
+		return err
+	}
+
+	for _, booking := range bookings {
+		var slot model.AvailabilitySlot
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", booking.SlotID).
+			First(&slot).Error; err != nil {
+			return err
+		}
+
+		release(&slot, booking.Load)
+		if err := tx.Model(&model.AvailabilitySlot{}).
+			Where("id = ?", slot.ID).
+			Updates(map[string]interface{}{
+				"booked_weight": slot.Booked.Weight,
+				"booked_volume": slot.Booked.Volume,
+				"status":        slot.Status,
+				"updated_at":    time.Now(),
+			}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&model.SlotBooking{}).
+			Where("id = ?", booking.ID).
+			Updates(map[string]interface{}{
+				"status":     model.BookingStatusCancelled,
+				"updated_at": time.Now(),
+			}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// release gives load back to the slot, reopening it if it had been marked
+// full.
+func release(slot *model.AvailabilitySlot, load model.TransportCapacity) {
+	slot.Booked.Weight -= load.Weight
+	slot.Booked.Volume -= load.Volume
+	if slot.Booked.Weight < 0 {
+		slot.Booked.Weight = 0
+	}
+	if slot.Booked.Volume < 0 {
+		slot.Booked.Volume = 0
+	}
+	if slot.Status == model.SlotStatusFull && !isFull(slot) {
+		slot.Status = model.SlotStatusOpen
+	}
+}
+
+// fits reports whether load can be added to the slot. A dimension with zero
+// declared capacity is not tracked.
+func fits(slot *model.AvailabilitySlot, load model.TransportCapacity) bool {
+	remaining := slot.Remaining()
+	if slot.Capacity.Weight > 0 && load.Weight > remaining.Weight+capacityEpsilon {
+		return false
+	}
+	if slot.Capacity.Volume > 0 && load.Volume > remaining.Volume+capacityEpsilon {
+		return false
+	}
+	return true
+}
+
+// isFull reports whether either capacity dimension of the slot is exhausted.
+// A dimension with zero declared capacity is not tracked.
+func isFull(slot *model.AvailabilitySlot) bool {
+	remaining := slot.Remaining()
+	weightFull := slot.Capacity.Weight > 0 && remaining.Weight <= capacityEpsilon
+	volumeFull := slot.Capacity.Volume > 0 && remaining.Volume <= capacityEpsilon
+	return weightFull || volumeFull
+}
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"testing"
+
+	model "agro_konnect/internal/transporter/model"
+)
+
+func TestRelease(t *testing.T) {
+	capacity := model.TransportCapacity{Weight: 1000, Volume: 10}
+
+	tests := []struct {
+		name       string
+		booked     model.TransportCapacity
+		status     model.SlotStatus
+		load       model.TransportCapacity
+		wantBooked model.TransportCapacity
+		wantStatus model.SlotStatus
+	}{
+		{"partial", model.TransportCapacity{Weight: 600, Volume: 6}, model.SlotStatusOpen, model.TransportCapacity{Weight: 200, Volume: 2}, model.TransportCapacity{Weight: 400, Volume: 4}, model.SlotStatusOpen},
+		{"full slot reopens", capacity, model.SlotStatusFull, model.TransportCapacity{Weight: 300, Volume: 3}, model.TransportCapacity{Weight: 700, Volume: 7}, model.SlotStatusOpen},
+		{"still full on one dimension", capacity, model.SlotStatusFull, model.TransportCapacity{Weight: 300}, model.TransportCapacity{Weight: 700, Volume: 10}, model.SlotStatusFull},
+		{"never below zero", model.TransportCapacity{Weight: 100, Volume: 1}, model.SlotStatusOpen, model.TransportCapacity{Weight: 250, Volume: 3}, model.TransportCapacity{}, model.SlotStatusOpen},
+		{"blocked slot stays blocked", model.TransportCapacity{Weight: 500}, model.SlotStatusBlocked, model.TransportCapacity{Weight: 500}, model.TransportCapacity{}, model.SlotStatusBlocked},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slot := &model.AvailabilitySlot{Capacity: capacity, Booked: tt.booked, Status: tt.status}
+			release(slot, tt.load)
+			if slot.Booked != tt.wantBooked || slot.Status != tt.wantStatus {
+				t.Errorf("got %+v %s, want %+v %s", slot.Booked, slot.Status, tt.wantBooked, tt.wantStatus)
+			}
+		})
+	}
+}
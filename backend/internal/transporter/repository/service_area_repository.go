@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	model "agro_konnect/internal/transporter/model"
+	"agro_konnect/pkg/geo"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// GeoBackend selects how point-in-area matching is evaluated.
+type GeoBackend string
+
+const (
+	// GeoBackendAuto uses PostGIS when the extension is installed and falls
+	// back to matching in Go otherwise.
+	GeoBackendAuto    GeoBackend = ""
+	GeoBackendPostGIS GeoBackend = "postgis"
+	GeoBackendGo      GeoBackend = "go"
+)
+
+type ServiceAreaRepository interface {
+	Create(ctx context.Context, area *model.ServiceArea) error
+	FindByID(ctx context.Context, id uuid.UUID) (*model.ServiceArea, error)
+	FindByTransporter(ctx context.Context, transporterID uuid.UUID) ([]*model.ServiceArea, error)
+	Update(ctx context.Context, area *model.ServiceArea) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	ReplaceForTransporter(ctx context.Context, transporterID uuid.UUID, areas []model.ServiceArea) error
+	FindTransporterIDsCovering(ctx context.Context, point geo.Point) ([]uuid.UUID, error)
+}
+
+type serviceAreaRepository struct {
+	db      *gorm.DB
+	backend GeoBackend
+
+	detectOnce sync.Once
+	usePostGIS bool
+}
+
+func NewServiceAreaRepository(db *gorm.DB, backend GeoBackend) ServiceAreaRepository {
+	return &serviceAreaRepository{db: db, backend: backend}
+}
+
+func (r *serviceAreaRepository) Create(ctx context.Context, area *model.ServiceArea) error {
+	return r.db.WithContext(ctx).Create(area).Error
+}
+
+func (r *serviceAreaRepository) FindByID(ctx context.Context, id uuid.UUID) (*model.ServiceArea, error) {
+	var area model.ServiceArea
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&area).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &area, err
+}
+
+func (r *serviceAreaRepository) FindByTransporter(ctx context.Context, transporterID uuid.UUID) ([]*model.ServiceArea, error) {
+	var areas []*model.ServiceArea
+	err := r.db.WithContext(ctx).
+		Where("transporter_id = ?", transporterID).
+		Order("created_at ASC").
+		Find(&areas).Error
+	return areas, err
+}
+
+func (r *serviceAreaRepository) Update(ctx context.Context, area *model.ServiceArea) error {
+	area.UpdatedAt = time.Now()
+	return r.db.WithContext(ctx).Save(area).Error
+}
+
+func (r *serviceAreaRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Where("id = ?", id).Delete(&model.ServiceArea{}).Error
+}
+
+// ReplaceForTransporter swaps the transporter's whole set of areas.
+func (r *serviceAreaRepository) ReplaceForTransporter(ctx context.Context, transporterID uuid.UUID, areas []model.ServiceArea) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("transporter_id = ?", transporterID).Delete(&model.ServiceArea{}).Error; err != nil {
+			return err
+		}
+		if len(areas) == 0 {
+			return nil
+		}
+		return tx.Create(&areas).Error
+	})
+}
+
+// FindTransporterIDsCovering returns the transporters with at least one area
+// containing the point.
+func (r *serviceAreaRepository) FindTransporterIDsCovering(ctx context.Context, point geo.Point) ([]uuid.UUID, error) {
+	// Narrow candidates by bounding box; both backends use the index
+	query := r.db.WithContext(ctx).Model(&model.ServiceArea{}).
+		Where("min_latitude <= ? AND max_latitude >= ?", point.Lat, point.Lat).
+		Where("min_longitude <= ? AND max_longitude >= ?", point.Lng, point.Lng)
+
+	if r.postGISEnabled(ctx) {
+		var ids []uuid.UUID
+		err := query.
+			Where(`(type = ? AND ST_DWithin(
+				ST_SetSRID(ST_MakePoint(center_longitude, center_latitude), 4326)::geography,
+				ST_SetSRID(ST_MakePoint(?, ?), 4326)::geography,
+				radius_km * 1000))
+			OR (type = ? AND ST_Covers(
+				ST_SetSRID(ST_GeomFromGeoJSON(polygon::text), 4326),
+				ST_SetSRID(ST_MakePoint(?, ?), 4326)))`,
+				model.ServiceAreaTypeRadius, point.Lng, point.Lat,
+				model.ServiceAreaTypePolygon, point.Lng, point.Lat).
+			Distinct("transporter_id").
+			Pluck("transporter_id", &ids).Error
+		return ids, err
+	}
+
+	var candidates []*model.ServiceArea
+	if err := query.Find(&candidates).Error; err != nil {
+		return nil, err
+	}
+
+	return transporterIDsCovering(candidates, point), nil
+}
+
+// transporterIDsCovering is the matching rule without PostGIS: an area
+// matches when its shape contains the point, so named areas, which have no
+// shape, never do and a transporter with only those is never matched.
+func transporterIDsCovering(areas []*model.ServiceArea, point geo.Point) []uuid.UUID {
+	seen := make(map[uuid.UUID]bool)
+	ids := make([]uuid.UUID, 0)
+	for _, area := range areas {
+		if seen[area.TransporterID] || !area.Covers(point) {
+			continue
+		}
+		seen[area.TransporterID] = true
+		ids = append(ids, area.TransporterID)
+	}
+	return ids
+}
+
+// postGISEnabled resolves the backend on first use so constructing the
+// repository never touches the database.
+func (r *serviceAreaRepository) postGISEnabled(ctx context.Context) bool {
+	r.detectOnce.Do(func() {
+		switch r.backend {
+		case GeoBackendPostGIS:
+			r.usePostGIS = true
+		case GeoBackendGo:
+			r.usePostGIS = false
+		default:
+			var installed bool
+			err := r.db.WithContext(ctx).
+				Raw("SELECT EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'postgis')").
+				Scan(&installed).Error
+			if err != nil {
+				log.Printf("service areas: could not detect PostGIS, matching in Go: %v", err)
+			}
+			r.usePostGIS = err == nil && installed
+		}
+	})
+	return r.usePostGIS
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"testing"
+
+	model "agro_konnect/internal/transporter/model"
+	"agro_konnect/pkg/geo"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+func radiusArea(transporterID uuid.UUID, center geo.Point, radiusKm float64) *model.ServiceArea {
+	bounds := geo.RadiusBounds(center, radiusKm)
+	return &model.ServiceArea{
+		TransporterID:   transporterID,
+		Type:            model.ServiceAreaTypeRadius,
+		CenterLatitude:  center.Lat,
+		CenterLongitude: center.Lng,
+		RadiusKm:        radiusKm,
+		MinLatitude:     bounds.MinLat,
+		MinLongitude:    bounds.MinLng,
+		MaxLatitude:     bounds.MaxLat,
+		MaxLongitude:    bounds.MaxLng,
+	}
+}
+
+func polygonArea(t *testing.T, transporterID uuid.UUID, geometry string) *model.ServiceArea {
+	t.Helper()
+	shape, err := geo.ParseGeoJSON([]byte(geometry))
+	if err != nil {
+		t.Fatalf("ParseGeoJSON: %v", err)
+	}
+	bounds := shape.Bounds()
+	return &model.ServiceArea{
+		TransporterID: transporterID,
+		Type:          model.ServiceAreaTypePolygon,
+		Polygon:       datatypes.JSON(geometry),
+		MinLatitude:   bounds.MinLat,
+		MinLongitude:  bounds.MinLng,
+		MaxLatitude:   bounds.MaxLat,
+		MaxLongitude:  bounds.MaxLng,
+	}
+}
+
+func TestTransporterIDsCovering(t *testing.T) {
+	nairobi := geo.Point{Lat: -1.2921, Lng: 36.8219}
+	mombasa := geo.Point{Lat: -4.0435, Lng: 39.6682}
+
+	radius, polygon, named, both := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	areas := []*model.ServiceArea{
+		radiusArea(radius, nairobi, 30),
+		// A box around Mombasa
+		polygonArea(t, polygon, `{"type":"Polygon","coordinates":[[[39.5,-4.2],[39.8,-4.2],[39.8,-3.9],[39.5,-3.9],[39.5,-4.2]]]}`),
+		// Named areas have no shape or bounds, whatever place they name
+		{TransporterID: named, Type: model.ServiceAreaTypeNamed, Name: "Nairobi"},
+		radiusArea(both, nairobi, 50),
+		radiusArea(both, nairobi, 10),
+	}
+
+	tests := []struct {
+		name  string
+		point geo.Point
+		want  []uuid.UUID
+	}{
+		{"inside radius areas", geo.Point{Lat: -1.30, Lng: 36.80}, []uuid.UUID{radius, both}},
+		{"inside polygon", mombasa, []uuid.UUID{polygon}},
+		{"outside every shape", geo.Point{Lat: 0.5143, Lng: 35.2698}, nil},
+		{"named areas' zero bounds", geo.Point{}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := transporterIDsCovering(areas, tt.point)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("got %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
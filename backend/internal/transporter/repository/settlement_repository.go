@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	model "agro_konnect/internal/transporter/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrStatementNotPending = errors.New("statement is not pending")
+
+// UnaccruedOrder is a delivered order that has no earning recorded yet.
+type UnaccruedOrder struct {
+	OrderID       uuid.UUID
+	OrderNumber   string
+	TransporterID uuid.UUID
+	ShippingCost  float64
+	DeliveredAt   time.Time
+}
+
+type EarningsSummary struct {
+	OrderCount       int64
+	GrossAmount      float64
+	CommissionAmount float64
+	NetAmount        float64
+	PaidAmount       float64
+	PendingAmount    float64
+	UnbilledAmount   float64
+}
+
+type SettlementRepository interface {
+	CreateEarning(ctx context.Context, earning *model.TransporterEarning) error
+	FindUnaccruedDeliveredOrders(ctx context.Context) ([]UnaccruedOrder, error)
+	FindEarnings(ctx context.Context, transporterID uuid.UUID, from, to time.Time) ([]*model.TransporterEarning, error)
+	FindUnsettledEarnings(ctx context.Context, before time.Time) ([]*model.TransporterEarning, error)
+	GetEarningsSummary(ctx context.Context, transporterID uuid.UUID) (*EarningsSummary, error)
+	CreateStatement(ctx context.Context, statement *model.SettlementStatement, earningIDs []uuid.UUID) error
+	FindStatementByID(ctx context.Context, id uuid.UUID) (*model.SettlementStatement, error)
+	FindStatements(ctx context.Context, transporterID uuid.UUID, status model.StatementStatus, page, pageSize int) ([]*model.SettlementStatement, int64, error)
+	MarkStatementPaid(ctx context.Context, id uuid.UUID, reference string, paidAt time.Time) error
+}
+
+type settlementRepository struct {
+	db *gorm.DB
+}
+
+func NewSettlementRepository(db *gorm.DB) SettlementRepository {
+	return &settlementRepository{db: db}
+}
+
+// CreateEarning records an earning once per order; repeated accruals for the
+// same order are ignored.
+func (r *settlementRepository) CreateEarning(ctx context.Context, earning *model.TransporterEarning) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "order_id"}}, DoNothing: true}).
+		Create(earning).Error
+}
+
+func (r *settlementRepository) FindUnaccruedDeliveredOrders(ctx context.Context) ([]UnaccruedOrder, error) {
+	var orders []UnaccruedOrder
+	err := r.db.WithContext(ctx).
+		Table("orders o").
+		Select("o.id AS order_id, o.order_number, o.transporter_id, o.shipping_cost, COALESCE(o.actual_delivery, o.updated_at) AS delivered_at").
+		Joins("LEFT JOIN transporter_earnings e ON e.order_id = o.id").
+		Where("o.status = ? AND o.transporter_id <> ? AND e.id IS NULL", "delivered", uuid.Nil).
+		Scan(&orders).Error
+	return orders, err
+}
+
+func (r *settlementRepository) FindEarnings(ctx context.Context, transporterID uuid.UUID, from, to time.Time) ([]*model.TransporterEarning, error) {
+	query := r.db.WithContext(ctx).Where("transporter_id = ?", transporterID)
+
+	if !from.IsZero() {
+		query = query.Where("delivered_at >= ?", from)
+	}
+	if !to.IsZero() {
+		query = query.Where("delivered_at < ?", to)
+	}
+
+	var earnings []*model.TransporterEarning
+	err := query.Order("delivered_at DESC").Find(&earnings).Error
+	return earnings, err
+}
+
+func (r *settlementRepository) FindUnsettledEarnings(ctx context.Context, before time.Time) ([]*model.TransporterEarning, error) {
+	var earnings []*model.TransporterEarning
+	err := r.db.WithContext(ctx).
+		Where("statement_id IS NULL AND delivered_at < ?", before).
+		Order("transporter_id, delivered_at ASC").
+		Find(&earnings).Error
+	return earnings, err
+}
+
+func (r *settlementRepository) GetEarningsSummary(ctx context.Context, transporterID uuid.UUID) (*EarningsSummary, error) {
+	var summary EarningsSummary
+	err := r.db.WithContext(ctx).
+		Table("transporter_earnings e").
+		Select(
+			"COUNT(*) AS order_count",
+			"COALESCE(SUM(e.shipping_charge), 0) AS gross_amount",
+			"COALESCE(SUM(e.commission_amount), 0) AS commission_amount",
+			"COALESCE(SUM(e.net_amount), 0) AS net_amount",
+			"COALESCE(SUM(CASE WHEN s.status = 'paid' THEN e.net_amount END), 0) AS paid_amount",
+			"COALESCE(SUM(CASE WHEN s.status = 'pending' THEN e.net_amount END), 0) AS pending_amount",
+			"COALESCE(SUM(CASE WHEN e.statement_id IS NULL THEN e.net_amount END), 0) AS unbilled_amount",
+		).
+		Joins("LEFT JOIN settlement_statements s ON s.id = e.statement_id").
+		Where("e.transporter_id = ?", transporterID).
+		Scan(&summary).Error
+	return &summary, err
+}
+
+// CreateStatement saves the statement and attaches the earnings to it. Only
+// earnings not yet on a statement are attached.
+func (r *settlementRepository) CreateStatement(ctx context.Context, statement *model.SettlementStatement, earningIDs []uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(statement).Error; err != nil {
+			return err
+		}
+
+		result := tx.Model(&model.TransporterEarning{}).
+			Where("id IN ? AND statement_id IS NULL", earningIDs).
+			Update("statement_id", statement.ID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected != int64(len(earningIDs)) {
+			return errors.New("earnings were settled concurrently")
+		}
+		return nil
+	})
+}
+
+func (r *settlementRepository) FindStatementByID(ctx context.Context, id uuid.UUID) (*model.SettlementStatement, error) {
+	var statement model.SettlementStatement
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&statement).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &statement, err
+}
+
+func (r *settlementRepository) FindStatements(ctx context.Context, transporterID uuid.UUID, status model.StatementStatus, page, pageSize int) ([]*model.SettlementStatement, int64, error) {
+	query := r.db.WithContext(ctx).Model(&model.SettlementStatement{})
+	if transporterID != uuid.Nil {
+		query = query.Where("transporter_id = ?", transporterID)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var statements []*model.SettlementStatement
+	offset := (page - 1) * pageSize
+	err := query.Order("period_start DESC, created_at DESC").Offset(offset).Limit(pageSize).Find(&statements).Error
+	return statements, total, err
+}
+
+func (r *settlementRepository) MarkStatementPaid(ctx context.Context, id uuid.UUID, reference string, paidAt time.Time) error {
+	result := r.db.WithContext(ctx).Model(&model.SettlementStatement{}).
+		Where("id = ? AND status = ?", id, model.StatementStatusPending).
+		Updates(map[string]interface{}{
+			"status":            model.StatementStatusPaid,
+			"paid_at":           &paidAt,
+			"payment_reference": reference,
+			"updated_at":        time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrStatementNotPending
+	}
+	return nil
+}
@@ -23,7 +23,7 @@ type TransporterRepository interface {
 	UpdateVerificationStatus(ctx context.Context, transporterID uuid.UUID, verified bool) error
 	UpdatePremiumStatus(ctx context.Context, transporterID uuid.UUID, premium bool) error
 	UpdateRating(ctx context.Context, transporterID uuid.UUID, rating float64, reviewCount int) error
-	FindByServiceArea(ctx context.Context, location string) ([]*model.Transporter, error)
+	FindVerifiedByIDs(ctx context.Context, ids []uuid.UUID) ([]*model.Transporter, error)
 	GetTransporterStats(ctx context.Context, transporterID uuid.UUID) (*TransporterStats, error)
 }
 
@@ -52,6 +52,10 @@ type TransporterFilter struct {
 	Specialization string
 	Page           int
 	PageSize       int
+
+	// When set, only these transporters are considered (service area coverage)
+	CoveringIDs []uuid.UUID
+	FilterByIDs bool
 }
 
 type VehicleFilter struct {
@@ -59,8 +63,11 @@ type VehicleFilter struct {
 	VehicleType   model.VehicleType
 	IsAvailable   *bool
 	MinCapacity   float64
+	MinVolume     float64 // free volume needed, in cubic meters
 	MaxCapacity   float64
 	Location      string
+	AvailableFrom time.Time
+	AvailableTo   time.Time
 	Page          int
 	PageSize      int
 }
@@ -94,9 +101,6 @@ func NewVehicleRepository(db *gorm.DB) VehicleRepository {
 
 func (r *transporterRepository) Create(ctx context.Context, transporter *model.Transporter) error {
 	// Convert arrays to JSON
-	if transporter.ServiceAreas == nil {
-		transporter.ServiceAreas = []byte("[]")
-	}
 	if transporter.VehicleTypes == nil {
 		transporter.VehicleTypes = []byte("[]")
 	}
@@ -109,7 +113,7 @@ func (r *transporterRepository) Create(ctx context.Context, transporter *model.T
 
 func (r *transporterRepository) FindByID(ctx context.Context, id uuid.UUID) (*model.Transporter, error) {
 	var transporter model.Transporter
-	err := r.db.WithContext(ctx).Where("id = ?", id).First(&transporter).Error
+	err := r.db.WithContext(ctx).Preload("ServiceAreas").Where("id = ?", id).First(&transporter).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, nil
 	}
@@ -118,7 +122,7 @@ func (r *transporterRepository) FindByID(ctx context.Context, id uuid.UUID) (*mo
 
 func (r *transporterRepository) FindByUserID(ctx context.Context, userID uuid.UUID) (*model.Transporter, error) {
 	var transporter model.Transporter
-	err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&transporter).Error
+	err := r.db.WithContext(ctx).Preload("ServiceAreas").Where("user_id = ?", userID).First(&transporter).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, nil
 	}
@@ -147,7 +151,17 @@ func (r *transporterRepository) FindAllWithFilters(ctx context.Context, filters
 	}
 
 	if filters.ServiceArea != "" {
-		query = query.Where("service_areas::text LIKE ?", fmt.Sprintf("%%%s%%", filters.ServiceArea))
+		query = query.Where(
+			"EXISTS (SELECT 1 FROM service_areas WHERE service_areas.transporter_id = transporters.id AND LOWER(service_areas.name) LIKE ?)",
+			"%"+escapeLike(strings.ToLower(filters.ServiceArea))+"%",
+		)
+	}
+
+	if filters.FilterByIDs {
+		if len(filters.CoveringIDs) == 0 {
+			return []*model.Transporter{}, 0, nil
+		}
+		query = query.Where("id IN ?", filters.CoveringIDs)
 	}
 
 	if filters.VehicleType != "" {
@@ -193,7 +207,7 @@ func (r *transporterRepository) FindAllWithFilters(ctx context.Context, filters
 	}
 
 	offset := (filters.Page - 1) * filters.PageSize
-	query = query.Offset(offset).Limit(filters.PageSize).Order("created_at DESC")
+	query = query.Preload("ServiceAreas").Offset(offset).Limit(filters.PageSize).Order("created_at DESC")
 
 	var transporters []*model.Transporter
 	err := query.Find(&transporters).Error
@@ -232,11 +246,17 @@ func (r *transporterRepository) UpdateRating(ctx context.Context, transporterID
 		}).Error
 }
 
-func (r *transporterRepository) FindByServiceArea(ctx context.Context, location string) ([]*model.Transporter, error) {
+func (r *transporterRepository) FindVerifiedByIDs(ctx context.Context, ids []uuid.UUID) ([]*model.Transporter, error) {
 	var transporters []*model.Transporter
+	if len(ids) == 0 {
+		return transporters, nil
+	}
+
 	err := r.db.WithContext(ctx).
-		Where("service_areas @> ?", fmt.Sprintf(`{"%s"}`, location)).
+		Preload("ServiceAreas").
+		Where("id IN ?", ids).
 		Where("is_verified = ?", true).
+		Order("rating DESC").
 		Find(&transporters).Error
 	return transporters, err
 }
@@ -333,6 +353,10 @@ func (r *vehicleRepository) FindAvailableVehicles(ctx context.Context, filters V
 		query = query.Where("capacity_weight >= ? OR capacity_volume >= ?", filters.MinCapacity, filters.MinCapacity)
 	}
 
+	if filters.MinVolume > 0 {
+		query = query.Where("capacity_volume >= ?", filters.MinVolume)
+	}
+
 	if filters.MaxCapacity > 0 {
 		query = query.Where("capacity_weight <= ? AND capacity_volume <= ?", filters.MaxCapacity, filters.MaxCapacity)
 	}
@@ -341,6 +365,29 @@ func (r *vehicleRepository) FindAvailableVehicles(ctx context.Context, filters V
 		query = query.Where("LOWER(current_location) LIKE ?", fmt.Sprintf("%%%s%%", strings.ToLower(filters.Location)))
 	}
 
+	// A vehicle is free in the window if an open slot covers it with enough
+	// remaining weight (MinCapacity) and volume, or if its calendar has
+	// nothing declared for it. As when booking, a slot dimension with zero
+	// declared capacity is not tracked.
+	if !filters.AvailableFrom.IsZero() && !filters.AvailableTo.IsZero() {
+		query = query.Where(
+			`EXISTS (
+				SELECT 1 FROM availability_slots s
+				WHERE s.vehicle_id = vehicles.id
+				AND s.status = ?
+				AND s.start_time <= ? AND s.end_time >= ?
+				AND (s.capacity_weight = 0 OR s.capacity_weight - s.booked_weight >= ?)
+				AND (s.capacity_volume = 0 OR s.capacity_volume - s.booked_volume >= ?)
+			) OR NOT EXISTS (
+				SELECT 1 FROM availability_slots s
+				WHERE s.vehicle_id = vehicles.id
+				AND s.start_time < ? AND s.end_time > ?
+			)`,
+			model.SlotStatusOpen, filters.AvailableFrom, filters.AvailableTo, filters.MinCapacity, filters.MinVolume,
+			filters.AvailableTo, filters.AvailableFrom,
+		)
+	}
+
 	// Apply pagination
 	if filters.PageSize > 0 {
 		offset := (filters.Page - 1) * filters.PageSize
@@ -353,3 +400,8 @@ func (r *vehicleRepository) FindAvailableVehicles(ctx context.Context, filters V
 	err := query.Find(&vehicles).Error
 	return vehicles, err
 }
+
+// escapeLike escapes LIKE wildcards in user input.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
@@ -0,0 +1,267 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	model "agro_konnect/internal/transporter/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrOrderInActiveTrip     = errors.New("order already belongs to an active trip")
+	ErrOrderOnAnotherVehicle = errors.New("order is booked on another vehicle")
+)
+
+type TripRepository interface {
+	Create(ctx context.Context, trip *model.Trip, bookings []*model.SlotBooking) error
+	FindByID(ctx context.Context, id uuid.UUID) (*model.Trip, error)
+	FindByTransporter(ctx context.Context, transporterID uuid.UUID, status model.TripStatus, page, pageSize int) ([]*model.Trip, int64, error)
+	FindOrdersInActiveTrips(ctx context.Context, orderIDs []uuid.UUID) ([]uuid.UUID, error)
+	UpdateStatus(ctx context.Context, tripID uuid.UUID, updates map[string]interface{}) error
+	Cancel(ctx context.Context, tripID uuid.UUID, from []model.TripStatus) (bool, error)
+	FindStopByID(ctx context.Context, stopID uuid.UUID) (*model.TripStop, error)
+	UpdateStopStatus(ctx context.Context, stopID uuid.UUID, status model.StopStatus) error
+	CountPendingStops(ctx context.Context, tripID uuid.UUID) (int64, error)
+}
+
+type tripRepository struct {
+	db *gorm.DB
+}
+
+func NewTripRepository(db *gorm.DB) TripRepository {
+	return &tripRepository{db: db}
+}
+
+// Create saves the trip together with its stops, and books the trip's
+// vehicle for the orders in bookings, in one transaction. The trip's orders
+// are locked while it checks that none is on another active trip or booked
+// on another vehicle, so two trips can't take the same order at once. Each booking goes into the first
+// open slot covering it; if the vehicle's calendar has nothing declared for
+// the trip, a slot is created as BookVehicle would.
+func (r *tripRepository) Create(ctx context.Context, trip *model.Trip, bookings []*model.SlotBooking) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		orderIDs := make([]string, 0, len(trip.Stops))
+		seen := make(map[uuid.UUID]bool, len(trip.Stops))
+		for _, stop := range trip.Stops {
+			if !seen[stop.OrderID] {
+				seen[stop.OrderID] = true
+				orderIDs = append(orderIDs, stop.OrderID.String())
+			}
+		}
+		// Always lock in the same order so overlapping trips can't deadlock
+		sort.Strings(orderIDs)
+		for _, id := range orderIDs {
+			if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "trip-order:"+id).Error; err != nil {
+				return err
+			}
+		}
+
+		var active int64
+		if err := tx.Model(&model.TripStop{}).
+			Joins("JOIN trips ON trips.id = trip_stops.trip_id").
+			Where("trip_stops.order_id IN ?", orderIDs).
+			Where("trips.status IN ?", []model.TripStatus{model.TripStatusPlanned, model.TripStatusInProgress}).
+			Count(&active).Error; err != nil {
+			return err
+		}
+		if active > 0 {
+			return ErrOrderInActiveTrip
+		}
+
+		// Booking the order here as well would leave it holding capacity on
+		// two vehicles
+		var elsewhere int64
+		if err := tx.Model(&model.SlotBooking{}).
+			Where("order_id IN ? AND vehicle_id <> ? AND status = ?", orderIDs, trip.VehicleID, model.BookingStatusBooked).
+			Count(&elsewhere).Error; err != nil {
+			return err
+		}
+		if elsewhere > 0 {
+			return ErrOrderOnAnotherVehicle
+		}
+
+		if len(bookings) > 0 {
+			if err := bookTrip(tx, trip, bookings); err != nil {
+				return err
+			}
+		}
+
+		return tx.Create(trip).Error
+	})
+}
+
+// bookTrip books each of bookings on the trip's vehicle. The vehicle row is
+// locked first, as CreateSlot does, so a slot created here can't overlap
+// one created concurrently.
+func bookTrip(tx *gorm.DB, trip *model.Trip, bookings []*model.SlotBooking) error {
+	var vehicle model.Vehicle
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", trip.VehicleID).
+		First(&vehicle).Error; err != nil {
+		return err
+	}
+
+	var slots []*model.AvailabilitySlot
+	if err := tx.Where("vehicle_id = ? AND start_time < ? AND end_time > ?", trip.VehicleID, trip.PlannedEnd, trip.PlannedStart).
+		Order("start_time ASC").
+		Find(&slots).Error; err != nil {
+		return err
+	}
+
+	if len(slots) == 0 {
+		now := time.Now()
+		slot := &model.AvailabilitySlot{
+			ID:        uuid.New(),
+			VehicleID: vehicle.ID,
+			StartTime: trip.PlannedStart,
+			EndTime:   trip.PlannedEnd,
+			Capacity:  vehicle.Capacity,
+			Status:    model.SlotStatusOpen,
+			Notes:     "Created automatically for trip",
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		if err := tx.Create(slot).Error; err != nil {
+			return err
+		}
+		slots = append(slots, slot)
+	}
+
+	for _, booking := range bookings {
+		err := ErrSlotNotOpen
+		for _, slot := range slots {
+			// The trip must fit entirely inside one slot
+			if slot.StartTime.After(booking.StartTime) || slot.EndTime.Before(booking.EndTime) {
+				continue
+			}
+			booking.SlotID = slot.ID
+			booking.TripID = &trip.ID
+			err = bookSlot(tx, booking)
+			if !errors.Is(err, ErrSlotCapacityExceeded) && !errors.Is(err, ErrSlotNotOpen) {
+				break
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *tripRepository) FindByID(ctx context.Context, id uuid.UUID) (*model.Trip, error) {
+	var trip model.Trip
+	err := r.db.WithContext(ctx).
+		Preload("Stops", func(db *gorm.DB) *gorm.DB {
+			return db.Order("sequence ASC")
+		}).
+		Where("id = ?", id).
+		First(&trip).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &trip, err
+}
+
+func (r *tripRepository) FindByTransporter(ctx context.Context, transporterID uuid.UUID, status model.TripStatus, page, pageSize int) ([]*model.Trip, int64, error) {
+	var trips []*model.Trip
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&model.Trip{}).Where("transporter_id = ?", transporterID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	err := query.
+		Preload("Stops", func(db *gorm.DB) *gorm.DB {
+			return db.Order("sequence ASC")
+		}).
+		Order("planned_start DESC").
+		Offset(offset).
+		Limit(pageSize).
+		Find(&trips).Error
+
+	return trips, total, err
+}
+
+// FindOrdersInActiveTrips returns which of the given orders already belong to
+// a trip that is planned or under way.
+func (r *tripRepository) FindOrdersInActiveTrips(ctx context.Context, orderIDs []uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.WithContext(ctx).Model(&model.TripStop{}).
+		Distinct("trip_stops.order_id").
+		Joins("JOIN trips ON trips.id = trip_stops.trip_id").
+		Where("trip_stops.order_id IN ?", orderIDs).
+		Where("trips.status IN ?", []model.TripStatus{model.TripStatusPlanned, model.TripStatusInProgress}).
+		Pluck("trip_stops.order_id", &ids).Error
+	return ids, err
+}
+
+func (r *tripRepository) UpdateStatus(ctx context.Context, tripID uuid.UUID, updates map[string]interface{}) error {
+	updates["updated_at"] = time.Now()
+	return r.db.WithContext(ctx).Model(&model.Trip{}).
+		Where("id = ?", tripID).
+		Updates(updates).Error
+}
+
+// Cancel cancels the trip if it is still in one of the from statuses, and
+// releases the calendar bookings made for it in the same transaction. It
+// reports false if the trip had already moved on.
+func (r *tripRepository) Cancel(ctx context.Context, tripID uuid.UUID, from []model.TripStatus) (bool, error) {
+	cancelled := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Trip{}).
+			Where("id = ? AND status IN ?", tripID, from).
+			Updates(map[string]interface{}{
+				"status":     model.TripStatusCancelled,
+				"updated_at": time.Now(),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		cancelled = true
+		return cancelBookings(tx, tx.Where("trip_id = ?", tripID))
+	})
+	return cancelled, err
+}
+
+func (r *tripRepository) FindStopByID(ctx context.Context, stopID uuid.UUID) (*model.TripStop, error) {
+	var stop model.TripStop
+	err := r.db.WithContext(ctx).Where("id = ?", stopID).First(&stop).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &stop, err
+}
+
+func (r *tripRepository) UpdateStopStatus(ctx context.Context, stopID uuid.UUID, status model.StopStatus) error {
+	now := time.Now()
+	return r.db.WithContext(ctx).Model(&model.TripStop{}).
+		Where("id = ?", stopID).
+		Updates(map[string]interface{}{
+			"status":       status,
+			"completed_at": &now,
+			"updated_at":   now,
+		}).Error
+}
+
+func (r *tripRepository) CountPendingStops(ctx context.Context, tripID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.TripStop{}).
+		Where("trip_id = ? AND status = ?", tripID, model.StopStatusPending).
+		Count(&count).Error
+	return count, err
+}
@@ -1,7 +1,13 @@
 package routes
 
 import (
+	auditservice "agro_konnect/internal/audit/service"
+	emailservice "agro_konnect/internal/email/service"
+	"os"
+
 	"agro_konnect/internal/auth/middleware"
+	farmerRepo "agro_konnect/internal/farmer/repository"
+	orderRepo "agro_konnect/internal/order/repository"
 	"agro_konnect/internal/transporter/handler"
 	"agro_konnect/internal/transporter/repository"
 	"agro_konnect/internal/transporter/service"
@@ -10,20 +16,43 @@ import (
 	"gorm.io/gorm"
 )
 
-func SetupTransporterRoutes(router *gin.RouterGroup, db *gorm.DB, authMiddleware *middleware.AuthMiddleware) {
+func SetupTransporterRoutes(router *gin.RouterGroup, db *gorm.DB, authMiddleware *middleware.AuthMiddleware, auditRecorder auditservice.Recorder, mailer emailservice.Mailer) {
 	// Initialize transporter dependencies
 	transporterRepo := repository.NewTransporterRepository(db)
 	vehicleRepo := repository.NewVehicleRepository(db)
-	transporterService := service.NewTransporterService(transporterRepo, vehicleRepo)
+	serviceAreaRepo := repository.NewServiceAreaRepository(db, repository.GeoBackend(os.Getenv("GEO_BACKEND")))
+	settlementRepo := repository.NewSettlementRepository(db)
+	transporterService := service.NewTransporterService(transporterRepo, vehicleRepo, serviceAreaRepo, settlementRepo, auditRecorder, mailer)
 	vehicleService := service.NewVehicleService(vehicleRepo, transporterRepo)
 	transporterHandler := handler.NewTransporterHandler(transporterService, vehicleService)
 
+	// Availability calendar dependencies
+	availabilityRepo := repository.NewAvailabilityRepository(db)
+	availabilityService := service.NewAvailabilityService(availabilityRepo, vehicleRepo)
+	availabilityHandler := handler.NewAvailabilityHandler(transporterService, availabilityService)
+
+	// Service area dependencies
+	serviceAreaService := service.NewServiceAreaService(serviceAreaRepo)
+	serviceAreaHandler := handler.NewServiceAreaHandler(transporterService, serviceAreaService)
+
+	// Trip planning dependencies
+	tripRepo := repository.NewTripRepository(db)
+	tripService := service.NewTripService(tripRepo, vehicleRepo, availabilityRepo, orderRepo.NewOrderRepository(db), farmerRepo.NewFarmerRepository(db))
+	tripHandler := handler.NewTripHandler(transporterService, tripService)
+
+	// Settlement dependencies
+	settlementService := service.NewSettlementService(settlementRepo, service.LoadSettlementConfig())
+	settlementHandler := handler.NewSettlementHandler(transporterService, settlementService)
+
 	// Public routes
 	transporterRoutes := router.Group("/transporters")
 	{
 		transporterRoutes.GET("", transporterHandler.GetAllTransporters)
+		transporterRoutes.GET("/coverage", serviceAreaHandler.FindCoveringTransporters)
 		transporterRoutes.GET("/vehicles/available", transporterHandler.GetAvailableVehicles)
+		transporterRoutes.GET("/vehicles/:id/calendar", availabilityHandler.GetVehicleCalendar)
 		transporterRoutes.GET("/:id/vehicles", transporterHandler.GetVehiclesByTransporter)
+		transporterRoutes.GET("/:id/service-areas", serviceAreaHandler.GetServiceAreas)
 		transporterRoutes.GET("/:id", transporterHandler.GetTransporterByID)
 	}
 
@@ -33,6 +62,11 @@ func SetupTransporterRoutes(router *gin.RouterGroup, db *gorm.DB, authMiddleware
 	{
 		protected.POST("", transporterHandler.CreateTransporter)
 		protected.GET("/me", transporterHandler.GetMyTransporterProfile)
+		protected.GET("/me/earnings", settlementHandler.GetMyEarnings)
+		protected.GET("/me/statements", settlementHandler.GetMyStatements)
+		protected.POST("/me/service-areas", serviceAreaHandler.AddServiceArea)
+		protected.PUT("/me/service-areas/:areaId", serviceAreaHandler.UpdateServiceArea)
+		protected.DELETE("/me/service-areas/:areaId", serviceAreaHandler.DeleteServiceArea)
 		protected.PUT("/:id", transporterHandler.UpdateTransporter)
 		protected.DELETE("/:id", transporterHandler.DeleteTransporter)
 		protected.GET("/:id/stats", transporterHandler.GetTransporterStats)
@@ -44,6 +78,19 @@ func SetupTransporterRoutes(router *gin.RouterGroup, db *gorm.DB, authMiddleware
 		protected.PUT("/vehicles/:id/availability", transporterHandler.UpdateVehicleAvailability)
 		protected.PUT("/vehicles/:id/location", transporterHandler.UpdateVehicleLocation)
 		protected.DELETE("/vehicles/:id", transporterHandler.DeleteVehicle)
+
+		// Availability calendar routes
+		protected.GET("/vehicles/:id/calendar/bookings", availabilityHandler.GetVehicleBookingCalendar)
+		protected.POST("/vehicles/:id/slots", availabilityHandler.CreateSlot)
+		protected.PUT("/vehicles/slots/:slotId/status", availabilityHandler.UpdateSlotStatus)
+		protected.DELETE("/vehicles/slots/:slotId", availabilityHandler.DeleteSlot)
+
+		// Trip routes
+		protected.POST("/trips", tripHandler.CreateTrip)
+		protected.GET("/trips", tripHandler.GetMyTrips)
+		protected.GET("/trips/:tripId", tripHandler.GetTrip)
+		protected.PUT("/trips/:tripId/status", tripHandler.UpdateTripStatus)
+		protected.PUT("/trips/:tripId/stops/:stopId/status", tripHandler.UpdateStopStatus)
 	}
 
 	// Admin only routes
@@ -52,5 +99,10 @@ func SetupTransporterRoutes(router *gin.RouterGroup, db *gorm.DB, authMiddleware
 	{
 		admin.PUT("/:id/verify", transporterHandler.VerifyTransporter)
 		admin.PUT("/:id/premium", transporterHandler.UpdatePremiumStatus)
+
+		// Settlement routes
+		admin.GET("/settlements/statements", settlementHandler.ListStatements)
+		admin.POST("/settlements/statements", settlementHandler.GenerateStatements)
+		admin.PUT("/settlements/statements/:statementId/paid", settlementHandler.MarkStatementPaid)
 	}
 }
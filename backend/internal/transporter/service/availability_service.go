@@ -0,0 +1,369 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	dto "agro_konnect/internal/transporter/dto"
+	model "agro_konnect/internal/transporter/model"
+	"agro_konnect/internal/transporter/repository"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrSlotNotFound       = errors.New("availability slot not found")
+	ErrInvalidSlotTime    = errors.New("slot times must be in RFC3339 format")
+	ErrInvalidSlotWindow  = errors.New("slot end time must be after start time")
+	ErrSlotOverlap        = errors.New("slot overlaps an existing slot for this vehicle")
+	ErrSlotHasBookings    = errors.New("slot has active bookings")
+	ErrSlotExceedsVehicle = errors.New("slot capacity exceeds vehicle capacity")
+	ErrVehicleUnavailable = errors.New("vehicle is not available for the requested window and load")
+)
+
+type AvailabilityService interface {
+	CreateSlot(ctx context.Context, vehicleID uuid.UUID, transporterID uuid.UUID, req *dto.CreateSlotRequest) (*dto.SlotResponse, error)
+	GetVehicleCalendar(ctx context.Context, vehicleID uuid.UUID, from, to time.Time) (*dto.VehicleCalendarResponse, error)
+	GetVehicleBookingCalendar(ctx context.Context, vehicleID uuid.UUID, transporterID *uuid.UUID, from, to time.Time) (*dto.VehicleCalendarResponse, error)
+	UpdateSlotStatus(ctx context.Context, slotID uuid.UUID, transporterID uuid.UUID, status model.SlotStatus) error
+	DeleteSlot(ctx context.Context, slotID uuid.UUID, transporterID uuid.UUID) error
+	BookVehicle(ctx context.Context, req *dto.BookVehicleRequest) (*dto.BookingResponse, error)
+	CancelBooking(ctx context.Context, bookingID uuid.UUID) error
+	ReleaseOrderBookings(ctx context.Context, orderID uuid.UUID, keep ...uuid.UUID) error
+}
+
+type availabilityService struct {
+	availabilityRepo repository.AvailabilityRepository
+	vehicleRepo      repository.VehicleRepository
+}
+
+func NewAvailabilityService(
+	availabilityRepo repository.AvailabilityRepository,
+	vehicleRepo repository.VehicleRepository,
+) AvailabilityService {
+	return &availabilityService{
+		availabilityRepo: availabilityRepo,
+		vehicleRepo:      vehicleRepo,
+	}
+}
+
+func (s *availabilityService) CreateSlot(ctx context.Context, vehicleID uuid.UUID, transporterID uuid.UUID, req *dto.CreateSlotRequest) (*dto.SlotResponse, error) {
+	vehicle, err := s.findOwnedVehicle(ctx, vehicleID, transporterID)
+	if err != nil {
+		return nil, err
+	}
+
+	startTime, err := time.Parse(time.RFC3339, req.StartTime)
+	if err != nil {
+		return nil, ErrInvalidSlotTime
+	}
+	endTime, err := time.Parse(time.RFC3339, req.EndTime)
+	if err != nil {
+		return nil, ErrInvalidSlotTime
+	}
+	if !endTime.After(startTime) {
+		return nil, ErrInvalidSlotWindow
+	}
+
+	capacity := vehicle.Capacity
+	if req.Capacity != nil {
+		if req.Capacity.Weight < 0 || req.Capacity.Volume < 0 {
+			return nil, ErrInvalidCapacity
+		}
+		if req.Capacity.Weight > vehicle.Capacity.Weight || req.Capacity.Volume > vehicle.Capacity.Volume {
+			return nil, ErrSlotExceedsVehicle
+		}
+		capacity = *req.Capacity
+	}
+
+	slot := &model.AvailabilitySlot{
+		ID:        uuid.New(),
+		VehicleID: vehicleID,
+		StartTime: startTime,
+		EndTime:   endTime,
+		Capacity:  capacity,
+		Status:    model.SlotStatusOpen,
+		Notes:     req.Notes,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	created, err := s.availabilityRepo.CreateSlot(ctx, slot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create slot: %w", err)
+	}
+	if !created {
+		return nil, ErrSlotOverlap
+	}
+
+	return s.toSlotResponse(slot, nil), nil
+}
+
+// GetVehicleCalendar is the public view of a vehicle's calendar: its slots
+// and how much of each is left, without the bookings behind them.
+func (s *availabilityService) GetVehicleCalendar(ctx context.Context, vehicleID uuid.UUID, from, to time.Time) (*dto.VehicleCalendarResponse, error) {
+	vehicle, err := s.vehicleRepo.FindByID(ctx, vehicleID)
+	if err != nil {
+		return nil, err
+	}
+	if vehicle == nil {
+		return nil, ErrVehicleNotFound
+	}
+	return s.calendar(ctx, vehicleID, from, to, false)
+}
+
+// GetVehicleBookingCalendar is the calendar with each slot's bookings, for
+// the vehicle's transporter, or for an admin when transporterID is nil.
+func (s *availabilityService) GetVehicleBookingCalendar(ctx context.Context, vehicleID uuid.UUID, transporterID *uuid.UUID, from, to time.Time) (*dto.VehicleCalendarResponse, error) {
+	if transporterID != nil {
+		if _, err := s.findOwnedVehicle(ctx, vehicleID, *transporterID); err != nil {
+			return nil, err
+		}
+	} else {
+		vehicle, err := s.vehicleRepo.FindByID(ctx, vehicleID)
+		if err != nil {
+			return nil, err
+		}
+		if vehicle == nil {
+			return nil, ErrVehicleNotFound
+		}
+	}
+	return s.calendar(ctx, vehicleID, from, to, true)
+}
+
+func (s *availabilityService) calendar(ctx context.Context, vehicleID uuid.UUID, from, to time.Time, withBookings bool) (*dto.VehicleCalendarResponse, error) {
+	slots, err := s.availabilityRepo.FindSlotsByVehicle(ctx, vehicleID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*dto.SlotResponse, len(slots))
+	for i, slot := range slots {
+		var bookings []*model.SlotBooking
+		if withBookings {
+			if bookings, err = s.availabilityRepo.FindBookingsBySlot(ctx, slot.ID); err != nil {
+				return nil, err
+			}
+		}
+		responses[i] = s.toSlotResponse(slot, bookings)
+	}
+
+	return &dto.VehicleCalendarResponse{
+		VehicleID: vehicleID,
+		From:      from,
+		To:        to,
+		Slots:     responses,
+	}, nil
+}
+
+func (s *availabilityService) UpdateSlotStatus(ctx context.Context, slotID uuid.UUID, transporterID uuid.UUID, status model.SlotStatus) error {
+	slot, err := s.findOwnedSlot(ctx, slotID, transporterID)
+	if err != nil {
+		return err
+	}
+
+	// Blocking a slot that already carries consignments would strand them
+	if status == model.SlotStatusBlocked {
+		count, err := s.availabilityRepo.CountActiveBookings(ctx, slot.ID)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrSlotHasBookings
+		}
+	}
+
+	return s.availabilityRepo.UpdateSlotStatus(ctx, slot.ID, status)
+}
+
+func (s *availabilityService) DeleteSlot(ctx context.Context, slotID uuid.UUID, transporterID uuid.UUID) error {
+	slot, err := s.findOwnedSlot(ctx, slotID, transporterID)
+	if err != nil {
+		return err
+	}
+
+	count, err := s.availabilityRepo.CountActiveBookings(ctx, slot.ID)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrSlotHasBookings
+	}
+
+	return s.availabilityRepo.DeleteSlot(ctx, slot.ID)
+}
+
+// BookVehicle reserves capacity on the vehicle for an order. If the vehicle's
+// calendar has nothing declared for the window, a slot covering it is created
+// on the fly so fleets that don't maintain a calendar keep working.
+func (s *availabilityService) BookVehicle(ctx context.Context, req *dto.BookVehicleRequest) (*dto.BookingResponse, error) {
+	vehicle, err := s.findOwnedVehicle(ctx, req.VehicleID, req.TransporterID)
+	if err != nil {
+		return nil, err
+	}
+	if !vehicle.IsActive || !vehicle.IsAvailable {
+		return nil, ErrVehicleUnavailable
+	}
+	if !req.EndTime.After(req.StartTime) {
+		return nil, ErrInvalidSlotWindow
+	}
+	if req.Load.Weight < 0 || req.Load.Volume < 0 {
+		return nil, ErrInvalidCapacity
+	}
+
+	overlapping, err := s.availabilityRepo.FindOverlappingSlots(ctx, vehicle.ID, req.StartTime, req.EndTime)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(overlapping) == 0 {
+		slot := &model.AvailabilitySlot{
+			ID:        uuid.New(),
+			VehicleID: vehicle.ID,
+			StartTime: req.StartTime,
+			EndTime:   req.EndTime,
+			Capacity:  vehicle.Capacity,
+			Status:    model.SlotStatusOpen,
+			Notes:     "Created automatically for order assignment",
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		}
+		created, err := s.availabilityRepo.CreateSlot(ctx, slot)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create slot: %w", err)
+		}
+		if created {
+			overlapping = []*model.AvailabilitySlot{slot}
+		} else {
+			// Another booking declared a slot for the window first
+			overlapping, err = s.availabilityRepo.FindOverlappingSlots(ctx, vehicle.ID, req.StartTime, req.EndTime)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, slot := range overlapping {
+		// The consignment must fit entirely inside one slot
+		if slot.StartTime.After(req.StartTime) || slot.EndTime.Before(req.EndTime) {
+			continue
+		}
+
+		booking := &model.SlotBooking{
+			ID:        uuid.New(),
+			SlotID:    slot.ID,
+			VehicleID: vehicle.ID,
+			OrderID:   req.OrderID,
+			StartTime: req.StartTime,
+			EndTime:   req.EndTime,
+			Load:      req.Load,
+			Status:    model.BookingStatusBooked,
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		}
+
+		err := s.availabilityRepo.BookSlot(ctx, booking)
+		if errors.Is(err, repository.ErrSlotCapacityExceeded) || errors.Is(err, repository.ErrSlotNotOpen) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to book slot: %w", err)
+		}
+
+		return s.toBookingResponse(booking), nil
+	}
+
+	return nil, ErrVehicleUnavailable
+}
+
+func (s *availabilityService) CancelBooking(ctx context.Context, bookingID uuid.UUID) error {
+	return s.availabilityRepo.CancelBooking(ctx, bookingID)
+}
+
+// ReleaseOrderBookings frees the capacity booked for an order, except for
+// the bookings in keep.
+func (s *availabilityService) ReleaseOrderBookings(ctx context.Context, orderID uuid.UUID, keep ...uuid.UUID) error {
+	bookings, err := s.availabilityRepo.FindBookingsByOrder(ctx, orderID)
+	if err != nil {
+		return err
+	}
+
+bookings:
+	for _, booking := range bookings {
+		for _, id := range keep {
+			if booking.ID == id {
+				continue bookings
+			}
+		}
+		if err := s.availabilityRepo.CancelBooking(ctx, booking.ID); err != nil {
+			return fmt.Errorf("failed to release booking %s: %w", booking.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// Helper methods
+func (s *availabilityService) findOwnedVehicle(ctx context.Context, vehicleID uuid.UUID, transporterID uuid.UUID) (*model.Vehicle, error) {
+	vehicle, err := s.vehicleRepo.FindByID(ctx, vehicleID)
+	if err != nil {
+		return nil, err
+	}
+	if vehicle == nil {
+		return nil, ErrVehicleNotFound
+	}
+	if vehicle.TransporterID != transporterID {
+		return nil, ErrUnauthorizedAccess
+	}
+	return vehicle, nil
+}
+
+func (s *availabilityService) findOwnedSlot(ctx context.Context, slotID uuid.UUID, transporterID uuid.UUID) (*model.AvailabilitySlot, error) {
+	slot, err := s.availabilityRepo.FindSlotByID(ctx, slotID)
+	if err != nil {
+		return nil, err
+	}
+	if slot == nil {
+		return nil, ErrSlotNotFound
+	}
+	if _, err := s.findOwnedVehicle(ctx, slot.VehicleID, transporterID); err != nil {
+		return nil, err
+	}
+	return slot, nil
+}
+
+func (s *availabilityService) toSlotResponse(slot *model.AvailabilitySlot, bookings []*model.SlotBooking) *dto.SlotResponse {
+	bookingResponses := make([]dto.BookingResponse, len(bookings))
+	for i, booking := range bookings {
+		bookingResponses[i] = *s.toBookingResponse(booking)
+	}
+
+	return &dto.SlotResponse{
+		ID:        slot.ID,
+		VehicleID: slot.VehicleID,
+		StartTime: slot.StartTime,
+		EndTime:   slot.EndTime,
+		Capacity:  slot.Capacity,
+		Booked:    slot.Booked,
+		Remaining: slot.Remaining(),
+		Status:    slot.Status,
+		Notes:     slot.Notes,
+		Bookings:  bookingResponses,
+	}
+}
+
+func (s *availabilityService) toBookingResponse(booking *model.SlotBooking) *dto.BookingResponse {
+	return &dto.BookingResponse{
+		ID:        booking.ID,
+		SlotID:    booking.SlotID,
+		VehicleID: booking.VehicleID,
+		OrderID:   booking.OrderID,
+		StartTime: booking.StartTime,
+		EndTime:   booking.EndTime,
+		Load:      booking.Load,
+		Status:    booking.Status,
+		CreatedAt: booking.CreatedAt,
+	}
+}
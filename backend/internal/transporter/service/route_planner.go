@@ -0,0 +1,120 @@
+package service
+
+import (
+	"agro_konnect/pkg/geo"
+)
+
+// maxTwoOptPasses bounds the improvement loop for large trips.
+const maxTwoOptPasses = 50
+
+// routeStop is a point the planner has to visit. A drop stop names the index
+// of its pickup so the planner never delivers an order before collecting it.
+type routeStop struct {
+	point  geo.Point
+	pickup int // index of the matching pickup for drops, -1 for pickups
+}
+
+// planRoute orders stops starting from start using a nearest-neighbour tour
+// refined with 2-opt. The route is open: the vehicle does not return to the
+// start. It returns the visiting order as indexes into stops.
+func planRoute(start geo.Point, stops []routeStop) []int {
+	route := nearestNeighbourRoute(start, stops)
+	return twoOpt(start, stops, route)
+}
+
+// nearestNeighbourRoute greedily visits the closest stop whose pickup, if
+// any, has already been visited.
+func nearestNeighbourRoute(start geo.Point, stops []routeStop) []int {
+	visited := make([]bool, len(stops))
+	route := make([]int, 0, len(stops))
+	current := start
+
+	for len(route) < len(stops) {
+		next := -1
+		best := 0.0
+		for i, stop := range stops {
+			if visited[i] || (stop.pickup >= 0 && !visited[stop.pickup]) {
+				continue
+			}
+			d := geo.DistanceKm(current, stop.point)
+			if next == -1 || d < best {
+				next, best = i, d
+			}
+		}
+
+		visited[next] = true
+		route = append(route, next)
+		current = stops[next].point
+	}
+
+	return route
+}
+
+// twoOpt repeatedly reverses route segments while doing so shortens the
+// route and keeps every pickup ahead of its drop.
+func twoOpt(start geo.Point, stops []routeStop, route []int) []int {
+	pointAt := func(pos int) geo.Point {
+		if pos < 0 {
+			return start
+		}
+		return stops[route[pos]].point
+	}
+
+	for pass := 0; pass < maxTwoOptPasses; pass++ {
+		improved := false
+
+		for i := 0; i < len(route)-1; i++ {
+			for j := i + 1; j < len(route); j++ {
+				before := geo.DistanceKm(pointAt(i-1), pointAt(i))
+				after := geo.DistanceKm(pointAt(i-1), pointAt(j))
+				if j+1 < len(route) {
+					before += geo.DistanceKm(pointAt(j), pointAt(j+1))
+					after += geo.DistanceKm(pointAt(i), pointAt(j+1))
+				}
+
+				if after >= before-1e-9 || !canReverse(stops, route, i, j) {
+					continue
+				}
+
+				for l, r := i, j; l < r; l, r = l+1, r-1 {
+					route[l], route[r] = route[r], route[l]
+				}
+				improved = true
+			}
+		}
+
+		if !improved {
+			break
+		}
+	}
+
+	return route
+}
+
+// canReverse reports whether reversing route[i..j] keeps precedence intact,
+// i.e. the segment does not contain both the pickup and drop of an order.
+func canReverse(stops []routeStop, route []int, i, j int) bool {
+	inSegment := make(map[int]bool, j-i+1)
+	for k := i; k <= j; k++ {
+		inSegment[route[k]] = true
+	}
+	for k := i; k <= j; k++ {
+		if p := stops[route[k]].pickup; p >= 0 && inSegment[p] {
+			return false
+		}
+	}
+	return true
+}
+
+// routeDistance returns the leg distances along route, starting at start.
+func routeDistance(start geo.Point, stops []routeStop, route []int) ([]float64, float64) {
+	legs := make([]float64, len(route))
+	total := 0.0
+	current := start
+	for k, idx := range route {
+		legs[k] = geo.DistanceKm(current, stops[idx].point)
+		total += legs[k]
+		current = stops[idx].point
+	}
+	return legs, total
+}
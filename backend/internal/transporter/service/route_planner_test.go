@@ -0,0 +1,138 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"agro_konnect/pkg/geo"
+)
+
+// east returns a point on the equator km kilometres east of the origin,
+// roughly, so distances along a test route are easy to follow.
+func east(km float64) geo.Point {
+	return geo.Point{Lat: 0, Lng: km / 111.32}
+}
+
+// pickupsFirst reports whether route visits every pickup before its drop.
+func pickupsFirst(stops []routeStop, route []int) bool {
+	position := make(map[int]int, len(route))
+	for pos, idx := range route {
+		position[idx] = pos
+	}
+	for idx, stop := range stops {
+		if stop.pickup >= 0 && position[stop.pickup] > position[idx] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestPlanRoute(t *testing.T) {
+	tests := []struct {
+		name  string
+		stops []routeStop
+		want  []int
+	}{
+		{
+			name: "single order",
+			stops: []routeStop{
+				{point: east(20), pickup: -1},
+				{point: east(5), pickup: 0},
+			},
+			want: []int{0, 1},
+		},
+		{
+			// The drop is nearer the start but can't come first
+			name: "drop nearer than its pickup",
+			stops: []routeStop{
+				{point: east(10), pickup: -1},
+				{point: east(1), pickup: 0},
+			},
+			want: []int{0, 1},
+		},
+		{
+			name: "orders sharing a pickup",
+			stops: []routeStop{
+				{point: east(10), pickup: -1},
+				{point: east(30), pickup: 0},
+				{point: east(10), pickup: -1},
+				{point: east(20), pickup: 2},
+			},
+			want: []int{0, 2, 3, 1},
+		},
+		{
+			name: "two farms along the way",
+			stops: []routeStop{
+				{point: east(10), pickup: -1},
+				{point: east(40), pickup: 0},
+				{point: east(20), pickup: -1},
+				{point: east(30), pickup: 2},
+			},
+			want: []int{0, 2, 3, 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			route := planRoute(east(0), tt.stops)
+			if !pickupsFirst(tt.stops, route) {
+				t.Fatalf("route %v drops an order before its pickup", route)
+			}
+			if !reflect.DeepEqual(route, tt.want) {
+				t.Errorf("route = %v, want %v", route, tt.want)
+			}
+		})
+	}
+}
+
+func TestTwoOptKeepsPickupsFirst(t *testing.T) {
+	// Reversing the route would cut it from 19 km to 10 km, but would
+	// deliver the order before collecting it.
+	stops := []routeStop{
+		{point: east(10), pickup: -1},
+		{point: east(1), pickup: 0},
+	}
+	if route := twoOpt(east(0), stops, []int{0, 1}); !reflect.DeepEqual(route, []int{0, 1}) {
+		t.Errorf("twoOpt = %v, want [0 1]", route)
+	}
+}
+
+func TestTwoOptShortensRoute(t *testing.T) {
+	// Two orders from one farm, delivered out and back
+	stops := []routeStop{
+		{point: east(10), pickup: -1},
+		{point: east(30), pickup: 0},
+		{point: east(20), pickup: 0},
+	}
+	route := twoOpt(east(0), stops, []int{0, 1, 2})
+	if !reflect.DeepEqual(route, []int{0, 2, 1}) {
+		t.Errorf("twoOpt = %v, want [0 2 1]", route)
+	}
+}
+
+func TestCanReverse(t *testing.T) {
+	stops := []routeStop{
+		{point: east(10), pickup: -1},
+		{point: east(20), pickup: 0},
+		{point: east(30), pickup: -1},
+		{point: east(40), pickup: 2},
+	}
+	route := []int{0, 2, 1, 3}
+
+	tests := []struct {
+		name string
+		i, j int
+		want bool
+	}{
+		{"pickups only", 0, 1, true},
+		{"drop and another order's pickup", 1, 2, true},
+		{"pickup and its drop", 0, 2, false},
+		{"whole route", 0, 3, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := canReverse(stops, route, tt.i, tt.j); got != tt.want {
+				t.Errorf("canReverse(%d, %d) = %v, want %v", tt.i, tt.j, got, tt.want)
+			}
+		})
+	}
+}
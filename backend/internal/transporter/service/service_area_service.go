@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	dto "agro_konnect/internal/transporter/dto"
+	model "agro_konnect/internal/transporter/model"
+	"agro_konnect/internal/transporter/repository"
+	"agro_konnect/pkg/geo"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+var (
+	ErrServiceAreaNotFound = errors.New("service area not found")
+	ErrInvalidServiceArea  = errors.New("radius areas need a center and radius_km; polygon areas need a GeoJSON Polygon or MultiPolygon")
+)
+
+type ServiceAreaService interface {
+	GetServiceAreas(ctx context.Context, transporterID uuid.UUID) ([]dto.ServiceAreaResponse, error)
+	AddServiceArea(ctx context.Context, transporterID uuid.UUID, req *dto.ServiceAreaRequest) (*dto.ServiceAreaResponse, error)
+	UpdateServiceArea(ctx context.Context, areaID uuid.UUID, transporterID uuid.UUID, req *dto.ServiceAreaRequest) (*dto.ServiceAreaResponse, error)
+	DeleteServiceArea(ctx context.Context, areaID uuid.UUID, transporterID uuid.UUID) error
+}
+
+type serviceAreaService struct {
+	serviceAreaRepo repository.ServiceAreaRepository
+}
+
+func NewServiceAreaService(serviceAreaRepo repository.ServiceAreaRepository) ServiceAreaService {
+	return &serviceAreaService{serviceAreaRepo: serviceAreaRepo}
+}
+
+func (s *serviceAreaService) GetServiceAreas(ctx context.Context, transporterID uuid.UUID) ([]dto.ServiceAreaResponse, error) {
+	areas, err := s.serviceAreaRepo.FindByTransporter(ctx, transporterID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.ServiceAreaResponse, len(areas))
+	for i, area := range areas {
+		responses[i] = toServiceAreaResponse(area)
+	}
+	return responses, nil
+}
+
+func (s *serviceAreaService) AddServiceArea(ctx context.Context, transporterID uuid.UUID, req *dto.ServiceAreaRequest) (*dto.ServiceAreaResponse, error) {
+	area, err := buildServiceArea(transporterID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.serviceAreaRepo.Create(ctx, area); err != nil {
+		return nil, fmt.Errorf("failed to create service area: %w", err)
+	}
+
+	response := toServiceAreaResponse(area)
+	return &response, nil
+}
+
+func (s *serviceAreaService) UpdateServiceArea(ctx context.Context, areaID uuid.UUID, transporterID uuid.UUID, req *dto.ServiceAreaRequest) (*dto.ServiceAreaResponse, error) {
+	existing, err := s.findOwnedArea(ctx, areaID, transporterID)
+	if err != nil {
+		return nil, err
+	}
+
+	area, err := buildServiceArea(transporterID, req)
+	if err != nil {
+		return nil, err
+	}
+	area.ID = existing.ID
+	area.CreatedAt = existing.CreatedAt
+
+	if err := s.serviceAreaRepo.Update(ctx, area); err != nil {
+		return nil, fmt.Errorf("failed to update service area: %w", err)
+	}
+
+	response := toServiceAreaResponse(area)
+	return &response, nil
+}
+
+func (s *serviceAreaService) DeleteServiceArea(ctx context.Context, areaID uuid.UUID, transporterID uuid.UUID) error {
+	if _, err := s.findOwnedArea(ctx, areaID, transporterID); err != nil {
+		return err
+	}
+	return s.serviceAreaRepo.Delete(ctx, areaID)
+}
+
+// Helper methods
+func (s *serviceAreaService) findOwnedArea(ctx context.Context, areaID uuid.UUID, transporterID uuid.UUID) (*model.ServiceArea, error) {
+	area, err := s.serviceAreaRepo.FindByID(ctx, areaID)
+	if err != nil {
+		return nil, err
+	}
+	if area == nil {
+		return nil, ErrServiceAreaNotFound
+	}
+	if area.TransporterID != transporterID {
+		return nil, ErrUnauthorizedAccess
+	}
+	return area, nil
+}
+
+// buildServiceArea validates the requested shape and precomputes its
+// bounding box.
+func buildServiceArea(transporterID uuid.UUID, req *dto.ServiceAreaRequest) (*model.ServiceArea, error) {
+	area := &model.ServiceArea{
+		ID:            uuid.New(),
+		TransporterID: transporterID,
+		Name:          req.Name,
+		Type:          req.Type,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+
+	var bounds geo.BoundingBox
+	switch req.Type {
+	case model.ServiceAreaTypeRadius:
+		if req.RadiusKm <= 0 || (req.CenterLatitude == 0 && req.CenterLongitude == 0) {
+			return nil, ErrInvalidServiceArea
+		}
+		area.CenterLatitude = req.CenterLatitude
+		area.CenterLongitude = req.CenterLongitude
+		area.RadiusKm = req.RadiusKm
+		bounds = geo.RadiusBounds(geo.Point{Lat: req.CenterLatitude, Lng: req.CenterLongitude}, req.RadiusKm)
+	case model.ServiceAreaTypePolygon:
+		shape, err := geo.ParseGeoJSON(req.Polygon)
+		if err != nil {
+			return nil, ErrInvalidServiceArea
+		}
+		geometry, err := shape.GeoJSON()
+		if err != nil {
+			return nil, ErrInvalidServiceArea
+		}
+		area.Polygon = datatypes.JSON(geometry)
+		bounds = shape.Bounds()
+	case model.ServiceAreaTypeNamed:
+		// No shape, so no bounds
+	default:
+		return nil, ErrInvalidServiceArea
+	}
+
+	area.MinLatitude = bounds.MinLat
+	area.MinLongitude = bounds.MinLng
+	area.MaxLatitude = bounds.MaxLat
+	area.MaxLongitude = bounds.MaxLng
+
+	return area, nil
+}
+
+func buildServiceAreas(transporterID uuid.UUID, reqs []dto.ServiceAreaRequest) ([]model.ServiceArea, error) {
+	areas := make([]model.ServiceArea, len(reqs))
+	for i := range reqs {
+		area, err := buildServiceArea(transporterID, &reqs[i])
+		if err != nil {
+			return nil, err
+		}
+		areas[i] = *area
+	}
+	return areas, nil
+}
+
+func toServiceAreaResponse(area *model.ServiceArea) dto.ServiceAreaResponse {
+	response := dto.ServiceAreaResponse{
+		ID:              area.ID,
+		Name:            area.Name,
+		Type:            area.Type,
+		CenterLatitude:  area.CenterLatitude,
+		CenterLongitude: area.CenterLongitude,
+		RadiusKm:        area.RadiusKm,
+		CreatedAt:       area.CreatedAt,
+	}
+	if len(area.Polygon) > 0 {
+		response.Polygon = json.RawMessage(area.Polygon)
+	}
+	return response
+}
@@ -0,0 +1,336 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"time"
+
+	dto "agro_konnect/internal/transporter/dto"
+	model "agro_konnect/internal/transporter/model"
+	"agro_konnect/internal/transporter/repository"
+
+	"github.com/google/uuid"
+)
+
+// DefaultCommissionRate is the platform's share of each shipping charge when
+// TRANSPORTER_COMMISSION_RATE is not set.
+const DefaultCommissionRate = 0.10
+
+var (
+	ErrStatementNotFound     = errors.New("settlement statement not found")
+	ErrStatementAlreadyPaid  = errors.New("settlement statement has already been paid")
+	ErrInvalidStatementWeek  = errors.New("week_of must be in RFC3339 format")
+	ErrStatementWeekNotEnded = errors.New("statements can only be generated for weeks that have ended")
+)
+
+type SettlementConfig struct {
+	CommissionRate float64
+}
+
+// LoadSettlementConfig reads the commission rate from the environment.
+func LoadSettlementConfig() SettlementConfig {
+	rate := DefaultCommissionRate
+	if v, err := strconv.ParseFloat(os.Getenv("TRANSPORTER_COMMISSION_RATE"), 64); err == nil && v >= 0 && v < 1 {
+		rate = v
+	}
+	return SettlementConfig{CommissionRate: rate}
+}
+
+type SettlementService interface {
+	AccrueDelivery(ctx context.Context, req *dto.AccrueEarningRequest) error
+	GetEarnings(ctx context.Context, transporterID uuid.UUID, from, to time.Time) (*dto.EarningsResponse, error)
+	ExportEarningsCSV(ctx context.Context, transporterID uuid.UUID, from, to time.Time) ([]byte, error)
+	GetStatements(ctx context.Context, transporterID uuid.UUID, status model.StatementStatus, page, pageSize int) (*dto.StatementListResponse, error)
+	GenerateWeeklyStatements(ctx context.Context, weekOf time.Time) (*dto.StatementRunResponse, error)
+	MarkStatementPaid(ctx context.Context, statementID uuid.UUID, reference string) (*dto.StatementResponse, error)
+}
+
+type settlementService struct {
+	settlementRepo repository.SettlementRepository
+	config         SettlementConfig
+}
+
+func NewSettlementService(settlementRepo repository.SettlementRepository, config SettlementConfig) SettlementService {
+	return &settlementService{
+		settlementRepo: settlementRepo,
+		config:         config,
+	}
+}
+
+// AccrueDelivery records what the transporter earns for a delivered order.
+// Calling it again for the same order has no effect.
+func (s *settlementService) AccrueDelivery(ctx context.Context, req *dto.AccrueEarningRequest) error {
+	if req.TransporterID == uuid.Nil {
+		return nil
+	}
+
+	commission := roundAmount(req.ShippingCharge * s.config.CommissionRate)
+	earning := &model.TransporterEarning{
+		ID:               uuid.New(),
+		TransporterID:    req.TransporterID,
+		OrderID:          req.OrderID,
+		OrderNumber:      req.OrderNumber,
+		ShippingCharge:   roundAmount(req.ShippingCharge),
+		CommissionRate:   s.config.CommissionRate,
+		CommissionAmount: commission,
+		NetAmount:        roundAmount(req.ShippingCharge - commission),
+		DeliveredAt:      req.DeliveredAt,
+		CreatedAt:        time.Now(),
+	}
+
+	if err := s.settlementRepo.CreateEarning(ctx, earning); err != nil {
+		return fmt.Errorf("failed to accrue earning: %w", err)
+	}
+	return nil
+}
+
+func (s *settlementService) GetEarnings(ctx context.Context, transporterID uuid.UUID, from, to time.Time) (*dto.EarningsResponse, error) {
+	earnings, err := s.settlementRepo.FindEarnings(ctx, transporterID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	summary, err := s.settlementRepo.GetEarningsSummary(ctx, transporterID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.EarningResponse, len(earnings))
+	for i, earning := range earnings {
+		responses[i] = toEarningResponse(earning)
+	}
+
+	return &dto.EarningsResponse{
+		From: from,
+		To:   to,
+		Summary: dto.EarningsSummaryResponse{
+			OrderCount:       summary.OrderCount,
+			GrossAmount:      roundAmount(summary.GrossAmount),
+			CommissionAmount: roundAmount(summary.CommissionAmount),
+			NetAmount:        roundAmount(summary.NetAmount),
+			PaidAmount:       roundAmount(summary.PaidAmount),
+			PendingAmount:    roundAmount(summary.PendingAmount),
+			UnbilledAmount:   roundAmount(summary.UnbilledAmount),
+		},
+		Earnings: responses,
+	}, nil
+}
+
+func (s *settlementService) ExportEarningsCSV(ctx context.Context, transporterID uuid.UUID, from, to time.Time) ([]byte, error) {
+	earnings, err := s.settlementRepo.FindEarnings(ctx, transporterID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write([]string{"order_number", "delivered_at", "shipping_charge", "commission_rate", "commission_amount", "net_amount", "statement_id"})
+
+	for _, earning := range earnings {
+		statementID := ""
+		if earning.StatementID != nil {
+			statementID = earning.StatementID.String()
+		}
+		w.Write([]string{
+			earning.OrderNumber,
+			earning.DeliveredAt.Format(time.RFC3339),
+			formatAmount(earning.ShippingCharge),
+			strconv.FormatFloat(earning.CommissionRate, 'f', 4, 64),
+			formatAmount(earning.CommissionAmount),
+			formatAmount(earning.NetAmount),
+			statementID,
+		})
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, fmt.Errorf("failed to write earnings csv: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func (s *settlementService) GetStatements(ctx context.Context, transporterID uuid.UUID, status model.StatementStatus, page, pageSize int) (*dto.StatementListResponse, error) {
+	statements, total, err := s.settlementRepo.FindStatements(ctx, transporterID, status, page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*dto.StatementResponse, len(statements))
+	for i, statement := range statements {
+		responses[i] = toStatementResponse(statement)
+	}
+
+	pages := int((total + int64(pageSize) - 1) / int64(pageSize))
+
+	return &dto.StatementListResponse{
+		Statements: responses,
+		Total:      total,
+		Page:       page,
+		Pages:      pages,
+		HasMore:    page < pages,
+	}, nil
+}
+
+// GenerateWeeklyStatements closes the Monday-to-Monday week containing weekOf.
+// Delivered orders that were never accrued are picked up first, then every
+// transporter's unsettled earnings up to the end of the week are rolled into
+// one statement each.
+func (s *settlementService) GenerateWeeklyStatements(ctx context.Context, weekOf time.Time) (*dto.StatementRunResponse, error) {
+	periodStart := startOfWeek(weekOf)
+	periodEnd := periodStart.AddDate(0, 0, 7)
+	if periodEnd.After(time.Now()) {
+		return nil, ErrStatementWeekNotEnded
+	}
+
+	unaccrued, err := s.settlementRepo.FindUnaccruedDeliveredOrders(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, order := range unaccrued {
+		if err := s.AccrueDelivery(ctx, &dto.AccrueEarningRequest{
+			OrderID:        order.OrderID,
+			OrderNumber:    order.OrderNumber,
+			TransporterID:  order.TransporterID,
+			ShippingCharge: order.ShippingCost,
+			DeliveredAt:    order.DeliveredAt,
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	earnings, err := s.settlementRepo.FindUnsettledEarnings(ctx, periodEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	byTransporter := make(map[uuid.UUID][]*model.TransporterEarning)
+	var transporterIDs []uuid.UUID
+	for _, earning := range earnings {
+		if _, ok := byTransporter[earning.TransporterID]; !ok {
+			transporterIDs = append(transporterIDs, earning.TransporterID)
+		}
+		byTransporter[earning.TransporterID] = append(byTransporter[earning.TransporterID], earning)
+	}
+
+	run := &dto.StatementRunResponse{
+		PeriodStart:   periodStart,
+		PeriodEnd:     periodEnd,
+		AccruedOrders: len(unaccrued),
+		Statements:    make([]*dto.StatementResponse, 0, len(transporterIDs)),
+	}
+
+	for _, transporterID := range transporterIDs {
+		items := byTransporter[transporterID]
+		statement := &model.SettlementStatement{
+			ID:            uuid.New(),
+			TransporterID: transporterID,
+			PeriodStart:   periodStart,
+			PeriodEnd:     periodEnd,
+			Status:        model.StatementStatusPending,
+			CreatedAt:     time.Now(),
+			UpdatedAt:     time.Now(),
+		}
+
+		ids := make([]uuid.UUID, len(items))
+		for i, earning := range items {
+			ids[i] = earning.ID
+			statement.OrderCount++
+			statement.GrossAmount += earning.ShippingCharge
+			statement.CommissionAmount += earning.CommissionAmount
+			statement.NetAmount += earning.NetAmount
+		}
+		statement.GrossAmount = roundAmount(statement.GrossAmount)
+		statement.CommissionAmount = roundAmount(statement.CommissionAmount)
+		statement.NetAmount = roundAmount(statement.NetAmount)
+
+		if err := s.settlementRepo.CreateStatement(ctx, statement, ids); err != nil {
+			return nil, fmt.Errorf("failed to create statement for transporter %s: %w", transporterID, err)
+		}
+		run.Statements = append(run.Statements, toStatementResponse(statement))
+	}
+
+	run.StatementsCount = len(run.Statements)
+	return run, nil
+}
+
+func (s *settlementService) MarkStatementPaid(ctx context.Context, statementID uuid.UUID, reference string) (*dto.StatementResponse, error) {
+	statement, err := s.settlementRepo.FindStatementByID(ctx, statementID)
+	if err != nil {
+		return nil, err
+	}
+	if statement == nil {
+		return nil, ErrStatementNotFound
+	}
+
+	paidAt := time.Now()
+	err = s.settlementRepo.MarkStatementPaid(ctx, statementID, reference, paidAt)
+	if errors.Is(err, repository.ErrStatementNotPending) {
+		return nil, ErrStatementAlreadyPaid
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to mark statement paid: %w", err)
+	}
+
+	statement.Status = model.StatementStatusPaid
+	statement.PaidAt = &paidAt
+	statement.PaymentReference = reference
+
+	return toStatementResponse(statement), nil
+}
+
+// Helper functions
+func startOfWeek(t time.Time) time.Time {
+	t = t.UTC()
+	offset := (int(t.Weekday()) + 6) % 7 // Monday is the first day
+	return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, time.UTC)
+}
+
+// LastWeek returns a time inside the most recent week that has ended.
+func LastWeek() time.Time {
+	return startOfWeek(time.Now()).AddDate(0, 0, -7)
+}
+
+func roundAmount(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+func formatAmount(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+func toEarningResponse(earning *model.TransporterEarning) dto.EarningResponse {
+	return dto.EarningResponse{
+		ID:               earning.ID,
+		OrderID:          earning.OrderID,
+		OrderNumber:      earning.OrderNumber,
+		ShippingCharge:   earning.ShippingCharge,
+		CommissionRate:   earning.CommissionRate,
+		CommissionAmount: earning.CommissionAmount,
+		NetAmount:        earning.NetAmount,
+		DeliveredAt:      earning.DeliveredAt,
+		StatementID:      earning.StatementID,
+	}
+}
+
+func toStatementResponse(statement *model.SettlementStatement) *dto.StatementResponse {
+	return &dto.StatementResponse{
+		ID:               statement.ID,
+		TransporterID:    statement.TransporterID,
+		PeriodStart:      statement.PeriodStart,
+		PeriodEnd:        statement.PeriodEnd,
+		OrderCount:       statement.OrderCount,
+		GrossAmount:      statement.GrossAmount,
+		CommissionAmount: statement.CommissionAmount,
+		NetAmount:        statement.NetAmount,
+		Status:           statement.Status,
+		PaidAt:           statement.PaidAt,
+		PaymentReference: statement.PaymentReference,
+		CreatedAt:        statement.CreatedAt,
+	}
+}
@@ -4,11 +4,15 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log"
 	"time"
 
+	auditservice "agro_konnect/internal/audit/service"
+	emailservice "agro_konnect/internal/email/service"
 	dto "agro_konnect/internal/transporter/dto"
 	model "agro_konnect/internal/transporter/model"
 	"agro_konnect/internal/transporter/repository"
+	"agro_konnect/pkg/geo"
 	"errors"
 
 	"github.com/google/uuid"
@@ -23,6 +27,8 @@ var (
 	ErrInvalidVehicleData       = errors.New("invalid vehicle data")
 	ErrUnauthorizedAccess       = errors.New("unauthorized access to transporter profile")
 	ErrInvalidCapacity          = errors.New("invalid capacity data")
+
+	ErrInvalidAvailabilityWindow = errors.New("available_from and available_to must both be RFC3339 times with available_to after available_from")
 )
 
 type TransporterService interface {
@@ -36,7 +42,7 @@ type TransporterService interface {
 	UpdatePremiumStatus(ctx context.Context, transporterID uuid.UUID, premium bool) error
 	UpdateTransporterRating(ctx context.Context, transporterID uuid.UUID, rating float64, reviewCount int) error
 	GetTransporterStats(ctx context.Context, transporterID uuid.UUID) (*dto.TransporterStatsResponse, error)
-	FindTransportersByServiceArea(ctx context.Context, location string) ([]*dto.TransporterResponse, error)
+	FindTransportersByServiceArea(ctx context.Context, pickup, drop *geo.Point) ([]*dto.TransporterResponse, error)
 }
 
 type VehicleService interface {
@@ -53,6 +59,10 @@ type VehicleService interface {
 type transporterService struct {
 	transporterRepo repository.TransporterRepository
 	vehicleRepo     repository.VehicleRepository
+	serviceAreaRepo repository.ServiceAreaRepository
+	settlementRepo  repository.SettlementRepository
+	audit           auditservice.Recorder
+	mailer          emailservice.Mailer
 }
 
 type vehicleService struct {
@@ -63,10 +73,18 @@ type vehicleService struct {
 func NewTransporterService(
 	transporterRepo repository.TransporterRepository,
 	vehicleRepo repository.VehicleRepository,
+	serviceAreaRepo repository.ServiceAreaRepository,
+	settlementRepo repository.SettlementRepository,
+	audit auditservice.Recorder,
+	mailer emailservice.Mailer,
 ) TransporterService {
 	return &transporterService{
 		transporterRepo: transporterRepo,
 		vehicleRepo:     vehicleRepo,
+		serviceAreaRepo: serviceAreaRepo,
+		settlementRepo:  settlementRepo,
+		audit:           audit,
+		mailer:          mailer,
 	}
 }
 
@@ -96,13 +114,18 @@ func (s *transporterService) CreateTransporter(ctx context.Context, userID uuid.
 		return nil, err
 	}
 
+	transporterID := uuid.New()
+	serviceAreas, err := buildServiceAreas(transporterID, req.ServiceAreas)
+	if err != nil {
+		return nil, err
+	}
+
 	// Convert arrays to JSON
-	serviceAreasJSON, _ := json.Marshal(req.ServiceAreas)
 	vehicleTypesJSON, _ := json.Marshal(req.VehicleTypes)
 	specializationsJSON, _ := json.Marshal(req.Specializations)
 
 	transporter := &model.Transporter{
-		ID:            uuid.New(),
+		ID:            transporterID,
 		UserID:        userID,
 		CompanyName:   req.CompanyName,
 		ContactPerson: req.ContactPerson,
@@ -112,7 +135,7 @@ func (s *transporterService) CreateTransporter(ctx context.Context, userID uuid.
 		City:         req.City,
 		State:        req.State,
 		Country:      req.Country,
-		ServiceAreas: datatypes.JSON(serviceAreasJSON),
+		ServiceAreas: serviceAreas,
 
 		AlternatePhone: req.AlternatePhone,
 		Website:        req.Website,
@@ -185,8 +208,17 @@ func (s *transporterService) UpdateTransporter(ctx context.Context, transporterI
 		return nil, err
 	}
 
+	// Areas are only replaced when the request sends them; an empty list
+	// clears them
+	serviceAreas := transporter.ServiceAreas
+	if req.ServiceAreas != nil {
+		serviceAreas, err = buildServiceAreas(transporter.ID, req.ServiceAreas)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Convert arrays to JSON for update
-	serviceAreasJSON, _ := json.Marshal(req.ServiceAreas)
 	vehicleTypesJSON, _ := json.Marshal(req.VehicleTypes)
 	specializationsJSON, _ := json.Marshal(req.Specializations)
 
@@ -199,7 +231,8 @@ func (s *transporterService) UpdateTransporter(ctx context.Context, transporterI
 	transporter.City = req.City
 	transporter.State = req.State
 	transporter.Country = req.Country
-	transporter.ServiceAreas = datatypes.JSON(serviceAreasJSON)
+	// Areas are replaced separately so Save doesn't upsert the old set
+	transporter.ServiceAreas = nil
 
 	transporter.AlternatePhone = req.AlternatePhone
 	transporter.Website = req.Website
@@ -219,6 +252,13 @@ func (s *transporterService) UpdateTransporter(ctx context.Context, transporterI
 		return nil, err
 	}
 
+	if req.ServiceAreas != nil {
+		if err := s.serviceAreaRepo.ReplaceForTransporter(ctx, transporter.ID, serviceAreas); err != nil {
+			return nil, fmt.Errorf("failed to update service areas: %w", err)
+		}
+	}
+	transporter.ServiceAreas = serviceAreas
+
 	return s.toTransporterResponse(transporter), nil
 }
 
@@ -255,6 +295,15 @@ func (s *transporterService) GetAllTransporters(ctx context.Context, filters dto
 		PageSize:       filters.PageSize,
 	}
 
+	if filters.Pickup != nil || filters.Drop != nil {
+		ids, err := s.coveringTransporterIDs(ctx, filters.Pickup, filters.Drop)
+		if err != nil {
+			return nil, err
+		}
+		repoFilters.CoveringIDs = ids
+		repoFilters.FilterByIDs = true
+	}
+
 	transporters, total, err := s.transporterRepo.FindAllWithFilters(ctx, repoFilters)
 	if err != nil {
 		return nil, err
@@ -278,7 +327,32 @@ func (s *transporterService) GetAllTransporters(ctx context.Context, filters dto
 }
 
 func (s *transporterService) VerifyTransporter(ctx context.Context, transporterID uuid.UUID) error {
-	return s.transporterRepo.UpdateVerificationStatus(ctx, transporterID, true)
+	transporter, err := s.transporterRepo.FindByID(ctx, transporterID)
+	if err != nil {
+		return err
+	}
+	if transporter == nil {
+		return ErrTransporterNotFound
+	}
+
+	if err := s.transporterRepo.UpdateVerificationStatus(ctx, transporterID, true); err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, auditservice.Event{
+		Action:     "transporter.verify",
+		EntityType: "transporter",
+		EntityID:   transporterID.String(),
+		Before:     map[string]interface{}{"is_verified": transporter.IsVerified},
+		After:      map[string]interface{}{"is_verified": true},
+	})
+
+	if !transporter.IsVerified {
+		if err := s.mailer.SendProfileVerified(ctx, transporter.UserID, emailservice.ProfileTransporter, transporter.ContactPerson); err != nil {
+			log.Printf("failed to send verification email for transporter %s: %v", transporterID, err)
+		}
+	}
+	return nil
 }
 
 func (s *transporterService) UpdatePremiumStatus(ctx context.Context, transporterID uuid.UUID, premium bool) error {
@@ -291,7 +365,18 @@ func (s *transporterService) UpdatePremiumStatus(ctx context.Context, transporte
 		return ErrTransporterNotFound
 	}
 
-	return s.transporterRepo.UpdatePremiumStatus(ctx, transporterID, premium)
+	if err := s.transporterRepo.UpdatePremiumStatus(ctx, transporterID, premium); err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, auditservice.Event{
+		Action:     "transporter.premium",
+		EntityType: "transporter",
+		EntityID:   transporterID.String(),
+		Before:     map[string]interface{}{"is_premium": transporter.IsPremium},
+		After:      map[string]interface{}{"is_premium": premium},
+	})
+	return nil
 }
 
 func (s *transporterService) UpdateTransporterRating(ctx context.Context, transporterID uuid.UUID, rating float64, reviewCount int) error {
@@ -307,20 +392,38 @@ func (s *transporterService) GetTransporterStats(ctx context.Context, transporte
 		return nil, err
 	}
 
+	revenue, err := s.settlementRepo.GetEarningsSummary(ctx, transporterID)
+	if err != nil {
+		return nil, err
+	}
+
 	return &dto.TransporterStatsResponse{
 		TotalVehicles:      stats.TotalVehicles,
 		AvailableVehicles:  stats.AvailableVehicles,
 		TotalOrders:        stats.TotalOrders,
 		CompletedOrders:    stats.CompletedOrders,
 		ActiveOrders:       stats.ActiveOrders,
-		TotalEarnings:      stats.TotalEarnings,
+		TotalEarnings:      roundAmount(revenue.NetAmount),
 		AverageRating:      stats.AverageRating,
 		OnTimeDeliveryRate: stats.OnTimeDeliveryRate,
+
+		GrossRevenue:   roundAmount(revenue.GrossAmount),
+		CommissionPaid: roundAmount(revenue.CommissionAmount),
+		PaidOut:        roundAmount(revenue.PaidAmount),
+		PendingPayout:  roundAmount(revenue.PendingAmount + revenue.UnbilledAmount),
+		BilledOrders:   revenue.OrderCount,
 	}, nil
 }
 
-func (s *transporterService) FindTransportersByServiceArea(ctx context.Context, location string) ([]*dto.TransporterResponse, error) {
-	transporters, err := s.transporterRepo.FindByServiceArea(ctx, location)
+// FindTransportersByServiceArea returns verified transporters whose service
+// areas cover every point given.
+func (s *transporterService) FindTransportersByServiceArea(ctx context.Context, pickup, drop *geo.Point) ([]*dto.TransporterResponse, error) {
+	ids, err := s.coveringTransporterIDs(ctx, pickup, drop)
+	if err != nil {
+		return nil, err
+	}
+
+	transporters, err := s.transporterRepo.FindVerifiedByIDs(ctx, ids)
 	if err != nil {
 		return nil, err
 	}
@@ -547,12 +650,33 @@ func (s *vehicleService) GetAvailableVehicles(ctx context.Context, filters dto.V
 		VehicleType:   filters.VehicleType,
 		IsAvailable:   filters.IsAvailable,
 		MinCapacity:   filters.MinCapacity,
+		MinVolume:     filters.MinVolume,
 		MaxCapacity:   filters.MaxCapacity,
 		Location:      filters.Location,
 		Page:          filters.Page,
 		PageSize:      filters.PageSize,
 	}
 
+	// Parse availability window
+	if filters.AvailableFrom != "" || filters.AvailableTo != "" {
+		if filters.AvailableFrom == "" || filters.AvailableTo == "" {
+			return nil, ErrInvalidAvailabilityWindow
+		}
+		from, err := time.Parse(time.RFC3339, filters.AvailableFrom)
+		if err != nil {
+			return nil, ErrInvalidAvailabilityWindow
+		}
+		to, err := time.Parse(time.RFC3339, filters.AvailableTo)
+		if err != nil {
+			return nil, ErrInvalidAvailabilityWindow
+		}
+		if !to.After(from) {
+			return nil, ErrInvalidAvailabilityWindow
+		}
+		repoFilters.AvailableFrom = from
+		repoFilters.AvailableTo = to
+	}
+
 	vehicles, err := s.vehicleRepo.FindAvailableVehicles(ctx, repoFilters)
 	if err != nil {
 		return nil, err
@@ -567,15 +691,48 @@ func (s *vehicleService) GetAvailableVehicles(ctx context.Context, filters dto.V
 }
 
 // Helper methods
+func (s *transporterService) coveringTransporterIDs(ctx context.Context, pickup, drop *geo.Point) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	for i, point := range []*geo.Point{pickup, drop} {
+		if point == nil {
+			continue
+		}
+
+		covering, err := s.serviceAreaRepo.FindTransporterIDsCovering(ctx, *point)
+		if err != nil {
+			return nil, err
+		}
+
+		// Both points given: keep transporters covering pickup and drop
+		if i == 0 || pickup == nil {
+			ids = covering
+			continue
+		}
+		inDrop := make(map[uuid.UUID]bool, len(covering))
+		for _, id := range covering {
+			inDrop[id] = true
+		}
+		both := make([]uuid.UUID, 0, len(ids))
+		for _, id := range ids {
+			if inDrop[id] {
+				both = append(both, id)
+			}
+		}
+		ids = both
+	}
+	return ids, nil
+}
+
 func (s *transporterService) toTransporterResponse(transporter *model.Transporter) *dto.TransporterResponse {
-	var serviceAreas []string
 	var vehicleTypes []model.VehicleType
 	var specializations []string
 
-	// Parse JSON arrays back to slices
-	if transporter.ServiceAreas != nil {
-		json.Unmarshal(transporter.ServiceAreas, &serviceAreas)
+	serviceAreas := make([]dto.ServiceAreaResponse, len(transporter.ServiceAreas))
+	for i := range transporter.ServiceAreas {
+		serviceAreas[i] = toServiceAreaResponse(&transporter.ServiceAreas[i])
 	}
+
+	// Parse JSON arrays back to slices
 	if transporter.VehicleTypes != nil {
 		json.Unmarshal(transporter.VehicleTypes, &vehicleTypes)
 	}
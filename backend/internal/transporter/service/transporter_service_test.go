@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	dto "agro_konnect/internal/transporter/dto"
+	model "agro_konnect/internal/transporter/model"
+	"agro_konnect/internal/transporter/repository"
+
+	"github.com/google/uuid"
+)
+
+type fakeTransporters struct {
+	repository.TransporterRepository
+	transporters map[uuid.UUID]*model.Transporter
+}
+
+func (r *fakeTransporters) FindByID(ctx context.Context, id uuid.UUID) (*model.Transporter, error) {
+	return r.transporters[id], nil
+}
+
+func (r *fakeTransporters) Update(ctx context.Context, transporter *model.Transporter) error {
+	r.transporters[transporter.ID] = transporter
+	return nil
+}
+
+type fakeServiceAreas struct {
+	repository.ServiceAreaRepository
+	replaced [][]model.ServiceArea
+}
+
+func (r *fakeServiceAreas) ReplaceForTransporter(ctx context.Context, transporterID uuid.UUID, areas []model.ServiceArea) error {
+	r.replaced = append(r.replaced, areas)
+	return nil
+}
+
+func TestUpdateTransporterServiceAreas(t *testing.T) {
+	const profile = `"company_name":"Haulers","contact_person":"Wanjiru","address":"Moi Avenue","city":"Nairobi","state":"Nairobi","country":"Kenya","license_number":"L-1","insurance_number":"I-1","year_established":2010,"vehicle_types":["truck"],"max_capacity":{"weight":10}`
+
+	tests := []struct {
+		name         string
+		body         string
+		wantReplaced bool
+		wantAreas    []string
+	}{
+		{"left out", `{` + profile + `}`, false, []string{"Nairobi"}},
+		{"null", `{` + profile + `,"service_areas":null}`, false, []string{"Nairobi"}},
+		{"empty list", `{` + profile + `,"service_areas":[]}`, true, []string{}},
+		{"new list", `{` + profile + `,"service_areas":["Thika","Kiambu"]}`, true, []string{"Thika", "Kiambu"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userID := uuid.New()
+			transporter := &model.Transporter{
+				ID:     uuid.New(),
+				UserID: userID,
+				ServiceAreas: []model.ServiceArea{
+					{ID: uuid.New(), Type: model.ServiceAreaTypeNamed, Name: "Nairobi"},
+				},
+			}
+			areas := &fakeServiceAreas{}
+			s := NewTransporterService(&fakeTransporters{transporters: map[uuid.UUID]*model.Transporter{transporter.ID: transporter}}, nil, areas, nil, nil, nil)
+
+			var req dto.CreateTransporterRequest
+			if err := json.Unmarshal([]byte(tt.body), &req); err != nil {
+				t.Fatal(err)
+			}
+			resp, err := s.UpdateTransporter(context.Background(), transporter.ID, userID, &req)
+			if err != nil {
+				t.Fatalf("UpdateTransporter: %v", err)
+			}
+
+			if replaced := len(areas.replaced) > 0; replaced != tt.wantReplaced {
+				t.Errorf("replaced = %v, want %v", replaced, tt.wantReplaced)
+			}
+			names := make([]string, len(resp.ServiceAreas))
+			for i, area := range resp.ServiceAreas {
+				names[i] = area.Name
+			}
+			if len(names) != len(tt.wantAreas) {
+				t.Fatalf("areas = %v, want %v", names, tt.wantAreas)
+			}
+			for i := range names {
+				if names[i] != tt.wantAreas[i] {
+					t.Errorf("areas = %v, want %v", names, tt.wantAreas)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,495 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	farmerModel "agro_konnect/internal/farmer/model"
+	farmerRepo "agro_konnect/internal/farmer/repository"
+	orderModel "agro_konnect/internal/order/model"
+	orderRepo "agro_konnect/internal/order/repository"
+	dto "agro_konnect/internal/transporter/dto"
+	model "agro_konnect/internal/transporter/model"
+	"agro_konnect/internal/transporter/repository"
+	"agro_konnect/pkg/geo"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrTripNotFound            = errors.New("trip not found")
+	ErrTripStopNotFound        = errors.New("trip stop not found")
+	ErrInvalidTripTime         = errors.New("planned_start must be in RFC3339 format")
+	ErrDuplicateTripOrder      = errors.New("an order can only appear once in a trip")
+	ErrTripOrderNotFound       = errors.New("one or more orders were not found")
+	ErrTripOrderNotAssigned    = errors.New("all orders must be assigned to this transporter")
+	ErrTripOrderNotShippable   = errors.New("all orders must be confirmed or processing and not yet shipped")
+	ErrTripOrderInActiveTrip   = errors.New("one or more orders already belong to an active trip")
+	ErrTripOrderOnOtherVehicle = errors.New("one or more orders are booked on another vehicle; release that booking first")
+	ErrTripMissingLocation     = errors.New("pickup or drop coordinates are missing for one or more orders")
+	ErrTripOverCapacity        = errors.New("combined load exceeds vehicle capacity")
+	ErrInvalidTripTransition   = errors.New("invalid trip status transition")
+	ErrStopOutOfOrder          = errors.New("an order must be picked up before it is dropped")
+)
+
+type TripService interface {
+	CreateTrip(ctx context.Context, transporterID uuid.UUID, req *dto.CreateTripRequest) (*dto.TripResponse, error)
+	GetTrip(ctx context.Context, tripID uuid.UUID, transporterID uuid.UUID) (*dto.TripResponse, error)
+	GetMyTrips(ctx context.Context, transporterID uuid.UUID, filters dto.TripFilterRequest) (*dto.TripListResponse, error)
+	UpdateTripStatus(ctx context.Context, tripID uuid.UUID, transporterID uuid.UUID, status model.TripStatus) (*dto.TripResponse, error)
+	UpdateStopStatus(ctx context.Context, tripID uuid.UUID, stopID uuid.UUID, transporterID uuid.UUID, status model.StopStatus) (*dto.TripResponse, error)
+}
+
+type tripService struct {
+	tripRepo         repository.TripRepository
+	vehicleRepo      repository.VehicleRepository
+	availabilityRepo repository.AvailabilityRepository
+	orderRepo        orderRepo.OrderRepository
+	farmerRepo       farmerRepo.FarmerRepository
+}
+
+func NewTripService(
+	tripRepo repository.TripRepository,
+	vehicleRepo repository.VehicleRepository,
+	availabilityRepo repository.AvailabilityRepository,
+	orderRepo orderRepo.OrderRepository,
+	farmerRepo farmerRepo.FarmerRepository,
+) TripService {
+	return &tripService{
+		tripRepo:         tripRepo,
+		vehicleRepo:      vehicleRepo,
+		availabilityRepo: availabilityRepo,
+		orderRepo:        orderRepo,
+		farmerRepo:       farmerRepo,
+	}
+}
+
+// defaultTripDuration is how long a trip is taken to need on the vehicle's
+// calendar when none of its orders has an estimated delivery after the start.
+const defaultTripDuration = 8 * time.Hour
+
+// CreateTrip groups assigned orders onto one vehicle. Each order contributes a
+// pickup at the farm and a drop at its shipping address; the stops are
+// sequenced by the route planner so every pickup precedes its drop. Orders
+// without capacity already booked on the vehicle are booked on its calendar
+// for the trip when it is saved.
+func (s *tripService) CreateTrip(ctx context.Context, transporterID uuid.UUID, req *dto.CreateTripRequest) (*dto.TripResponse, error) {
+	vehicle, err := s.vehicleRepo.FindByID(ctx, req.VehicleID)
+	if err != nil {
+		return nil, err
+	}
+	if vehicle == nil {
+		return nil, ErrVehicleNotFound
+	}
+	if vehicle.TransporterID != transporterID {
+		return nil, ErrUnauthorizedAccess
+	}
+	if !vehicle.IsActive {
+		return nil, ErrVehicleUnavailable
+	}
+
+	plannedStart := time.Now()
+	if req.PlannedStart != "" {
+		plannedStart, err = time.Parse(time.RFC3339, req.PlannedStart)
+		if err != nil {
+			return nil, ErrInvalidTripTime
+		}
+	}
+
+	seen := make(map[uuid.UUID]bool, len(req.OrderIDs))
+	for _, id := range req.OrderIDs {
+		if seen[id] {
+			return nil, ErrDuplicateTripOrder
+		}
+		seen[id] = true
+	}
+
+	// Checked again under lock when the trip is saved
+	inTrips, err := s.tripRepo.FindOrdersInActiveTrips(ctx, req.OrderIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(inTrips) > 0 {
+		return nil, ErrTripOrderInActiveTrip
+	}
+
+	tripID := uuid.New()
+	farmers := make(map[uuid.UUID]*farmerModel.Farmer)
+	stops := make([]model.TripStop, 0, len(req.OrderIDs)*2)
+	routeStops := make([]routeStop, 0, len(req.OrderIDs)*2)
+	var totalLoad model.TransportCapacity
+	var unbooked []*model.SlotBooking
+	plannedEnd := plannedStart
+
+	for _, orderID := range req.OrderIDs {
+		order, err := s.orderRepo.FindByID(ctx, orderID)
+		if err != nil {
+			return nil, err
+		}
+		if order == nil {
+			return nil, ErrTripOrderNotFound
+		}
+		if order.TransporterID != transporterID {
+			return nil, ErrTripOrderNotAssigned
+		}
+		if order.Status != orderModel.OrderStatusConfirmed && order.Status != orderModel.OrderStatusProcessing {
+			return nil, ErrTripOrderNotShippable
+		}
+
+		farmer, ok := farmers[order.FarmerID]
+		if !ok {
+			farmer, err = s.farmerRepo.FindByID(ctx, order.FarmerID)
+			if err != nil {
+				return nil, err
+			}
+			farmers[order.FarmerID] = farmer
+		}
+		if farmer == nil || (farmer.Latitude == 0 && farmer.Longitude == 0) || !order.HasDropLocation() {
+			return nil, ErrTripMissingLocation
+		}
+
+		load, booked, err := s.orderLoad(ctx, order, vehicle.ID)
+		if err != nil {
+			return nil, err
+		}
+		totalLoad.Weight += load.Weight
+		totalLoad.Volume += load.Volume
+		if !booked {
+			unbooked = append(unbooked, &model.SlotBooking{
+				ID:        uuid.New(),
+				VehicleID: vehicle.ID,
+				OrderID:   order.ID,
+				Load:      load,
+				Status:    model.BookingStatusBooked,
+			})
+		}
+		if order.EstimatedDelivery.After(plannedEnd) {
+			plannedEnd = order.EstimatedDelivery
+		}
+
+		routeStops = append(routeStops,
+			routeStop{point: geo.Point{Lat: farmer.Latitude, Lng: farmer.Longitude}, pickup: -1},
+			routeStop{point: geo.Point{Lat: order.ShippingLatitude, Lng: order.ShippingLongitude}, pickup: len(routeStops)},
+		)
+		stops = append(stops,
+			model.TripStop{
+				OrderID:   order.ID,
+				Type:      model.StopTypePickup,
+				Address:   joinAddress(farmer.FarmName, farmer.Address, farmer.City, farmer.State),
+				Latitude:  farmer.Latitude,
+				Longitude: farmer.Longitude,
+				Load:      load,
+			},
+			model.TripStop{
+				OrderID:   order.ID,
+				Type:      model.StopTypeDrop,
+				Address:   joinAddress(order.ShippingAddress, order.ShippingCity, order.ShippingState, order.ShippingZipCode),
+				Latitude:  order.ShippingLatitude,
+				Longitude: order.ShippingLongitude,
+				Load:      load,
+			},
+		)
+	}
+
+	// A dimension with zero declared capacity is not tracked
+	if (vehicle.Capacity.Weight > 0 && totalLoad.Weight > vehicle.Capacity.Weight) ||
+		(vehicle.Capacity.Volume > 0 && totalLoad.Volume > vehicle.Capacity.Volume) {
+		return nil, ErrTripOverCapacity
+	}
+
+	start := routeStops[0].point
+	if req.StartLatitude != 0 || req.StartLongitude != 0 {
+		start = geo.Point{Lat: req.StartLatitude, Lng: req.StartLongitude}
+	}
+
+	route := planRoute(start, routeStops)
+	legs, totalDistance := routeDistance(start, routeStops, route)
+
+	if !plannedEnd.After(plannedStart) {
+		plannedEnd = plannedStart.Add(defaultTripDuration)
+	}
+
+	now := time.Now()
+	for _, booking := range unbooked {
+		booking.StartTime = plannedStart
+		booking.EndTime = plannedEnd
+		booking.CreatedAt = now
+		booking.UpdatedAt = now
+	}
+
+	ordered := make([]model.TripStop, len(route))
+	for seq, idx := range route {
+		stop := stops[idx]
+		stop.ID = uuid.New()
+		stop.TripID = tripID
+		stop.Sequence = seq + 1
+		stop.LegDistanceKm = legs[seq]
+		stop.Status = model.StopStatusPending
+		stop.CreatedAt = now
+		stop.UpdatedAt = now
+		ordered[seq] = stop
+	}
+
+	trip := &model.Trip{
+		ID:              tripID,
+		TransporterID:   transporterID,
+		VehicleID:       vehicle.ID,
+		Status:          model.TripStatusPlanned,
+		StartLatitude:   start.Lat,
+		StartLongitude:  start.Lng,
+		TotalDistanceKm: totalDistance,
+		TotalLoad:       totalLoad,
+		PlannedStart:    plannedStart,
+		PlannedEnd:      plannedEnd,
+		Notes:           req.Notes,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+		Stops:           ordered,
+	}
+
+	err = s.tripRepo.Create(ctx, trip, unbooked)
+	switch {
+	case errors.Is(err, repository.ErrOrderInActiveTrip):
+		return nil, ErrTripOrderInActiveTrip
+	case errors.Is(err, repository.ErrOrderOnAnotherVehicle):
+		return nil, ErrTripOrderOnOtherVehicle
+	case errors.Is(err, repository.ErrSlotCapacityExceeded), errors.Is(err, repository.ErrSlotNotOpen):
+		return nil, ErrVehicleUnavailable
+	case err != nil:
+		return nil, fmt.Errorf("failed to create trip: %w", err)
+	}
+
+	return s.toTripResponse(trip, vehicle), nil
+}
+
+func (s *tripService) GetTrip(ctx context.Context, tripID uuid.UUID, transporterID uuid.UUID) (*dto.TripResponse, error) {
+	trip, err := s.findOwnedTrip(ctx, tripID, transporterID)
+	if err != nil {
+		return nil, err
+	}
+
+	vehicle, err := s.vehicleRepo.FindByID(ctx, trip.VehicleID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.toTripResponse(trip, vehicle), nil
+}
+
+func (s *tripService) GetMyTrips(ctx context.Context, transporterID uuid.UUID, filters dto.TripFilterRequest) (*dto.TripListResponse, error) {
+	trips, total, err := s.tripRepo.FindByTransporter(ctx, transporterID, model.TripStatus(filters.Status), filters.Page, filters.PageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	vehicles := make(map[uuid.UUID]*model.Vehicle)
+	responses := make([]*dto.TripResponse, len(trips))
+	for i, trip := range trips {
+		vehicle, ok := vehicles[trip.VehicleID]
+		if !ok {
+			vehicle, err = s.vehicleRepo.FindByID(ctx, trip.VehicleID)
+			if err != nil {
+				return nil, err
+			}
+			vehicles[trip.VehicleID] = vehicle
+		}
+		responses[i] = s.toTripResponse(trip, vehicle)
+	}
+
+	pages := int((total + int64(filters.PageSize) - 1) / int64(filters.PageSize))
+
+	return &dto.TripListResponse{
+		Trips:   responses,
+		Total:   total,
+		Page:    filters.Page,
+		Pages:   pages,
+		HasMore: filters.Page < pages,
+	}, nil
+}
+
+func (s *tripService) UpdateTripStatus(ctx context.Context, tripID uuid.UUID, transporterID uuid.UUID, status model.TripStatus) (*dto.TripResponse, error) {
+	trip, err := s.findOwnedTrip(ctx, tripID, transporterID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{"status": status}
+
+	switch {
+	case status == model.TripStatusInProgress && trip.Status == model.TripStatusPlanned:
+		updates["started_at"] = &now
+	case status == model.TripStatusCompleted && trip.Status == model.TripStatusInProgress:
+		updates["completed_at"] = &now
+	case status == model.TripStatusCancelled &&
+		(trip.Status == model.TripStatusPlanned || trip.Status == model.TripStatusInProgress):
+		// The vehicle's calendar gets back what was booked for the trip
+		cancelled, err := s.tripRepo.Cancel(ctx, trip.ID, []model.TripStatus{trip.Status})
+		if err != nil {
+			return nil, fmt.Errorf("failed to cancel trip: %w", err)
+		}
+		if !cancelled {
+			return nil, ErrInvalidTripTransition
+		}
+		return s.GetTrip(ctx, trip.ID, transporterID)
+	default:
+		return nil, ErrInvalidTripTransition
+	}
+
+	if err := s.tripRepo.UpdateStatus(ctx, trip.ID, updates); err != nil {
+		return nil, fmt.Errorf("failed to update trip status: %w", err)
+	}
+
+	return s.GetTrip(ctx, trip.ID, transporterID)
+}
+
+// UpdateStopStatus records progress at a stop. Once no stops are pending the
+// trip is completed automatically.
+func (s *tripService) UpdateStopStatus(ctx context.Context, tripID uuid.UUID, stopID uuid.UUID, transporterID uuid.UUID, status model.StopStatus) (*dto.TripResponse, error) {
+	trip, err := s.findOwnedTrip(ctx, tripID, transporterID)
+	if err != nil {
+		return nil, err
+	}
+	if trip.Status != model.TripStatusInProgress {
+		return nil, ErrInvalidTripTransition
+	}
+
+	var stop *model.TripStop
+	for i := range trip.Stops {
+		if trip.Stops[i].ID == stopID {
+			stop = &trip.Stops[i]
+			break
+		}
+	}
+	if stop == nil {
+		return nil, ErrTripStopNotFound
+	}
+	if stop.Status != model.StopStatusPending {
+		return nil, ErrInvalidTripTransition
+	}
+
+	if stop.Type == model.StopTypeDrop && status == model.StopStatusCompleted {
+		for _, other := range trip.Stops {
+			if other.OrderID == stop.OrderID && other.Type == model.StopTypePickup && other.Status != model.StopStatusCompleted {
+				return nil, ErrStopOutOfOrder
+			}
+		}
+	}
+
+	if err := s.tripRepo.UpdateStopStatus(ctx, stop.ID, status); err != nil {
+		return nil, fmt.Errorf("failed to update stop status: %w", err)
+	}
+
+	pending, err := s.tripRepo.CountPendingStops(ctx, trip.ID)
+	if err != nil {
+		return nil, err
+	}
+	if pending == 0 {
+		now := time.Now()
+		if err := s.tripRepo.UpdateStatus(ctx, trip.ID, map[string]interface{}{
+			"status":       model.TripStatusCompleted,
+			"completed_at": &now,
+		}); err != nil {
+			return nil, fmt.Errorf("failed to complete trip: %w", err)
+		}
+	}
+
+	return s.GetTrip(ctx, trip.ID, transporterID)
+}
+
+// Helper methods
+func (s *tripService) findOwnedTrip(ctx context.Context, tripID uuid.UUID, transporterID uuid.UUID) (*model.Trip, error) {
+	trip, err := s.tripRepo.FindByID(ctx, tripID)
+	if err != nil {
+		return nil, err
+	}
+	if trip == nil {
+		return nil, ErrTripNotFound
+	}
+	if trip.TransporterID != transporterID {
+		return nil, ErrUnauthorizedAccess
+	}
+	return trip, nil
+}
+
+// orderLoad uses the load reserved for the order on the vehicle's calendar,
+// falling back to an estimate from the order items. It reports whether the
+// load was reserved. An order booked on another vehicle can't join the trip,
+// since it would then hold capacity on both.
+func (s *tripService) orderLoad(ctx context.Context, order *orderModel.Order, vehicleID uuid.UUID) (model.TransportCapacity, bool, error) {
+	bookings, err := s.availabilityRepo.FindBookingsByOrder(ctx, order.ID)
+	if err != nil {
+		return model.TransportCapacity{}, false, err
+	}
+
+	var load model.TransportCapacity
+	booked := false
+	for _, booking := range bookings {
+		if booking.VehicleID != vehicleID {
+			return model.TransportCapacity{}, false, ErrTripOrderOnOtherVehicle
+		}
+		load.Weight += booking.Load.Weight
+		load.Volume += booking.Load.Volume
+		booked = true
+	}
+
+	if !booked {
+		load.Weight = order.EstimatedWeight()
+	}
+	return load, booked, nil
+}
+
+func joinAddress(parts ...string) string {
+	nonEmpty := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			nonEmpty = append(nonEmpty, part)
+		}
+	}
+	return strings.Join(nonEmpty, ", ")
+}
+
+func (s *tripService) toTripResponse(trip *model.Trip, vehicle *model.Vehicle) *dto.TripResponse {
+	stops := make([]dto.TripStopResponse, len(trip.Stops))
+	for i, stop := range trip.Stops {
+		stops[i] = dto.TripStopResponse{
+			ID:            stop.ID,
+			OrderID:       stop.OrderID,
+			Sequence:      stop.Sequence,
+			Type:          stop.Type,
+			Address:       stop.Address,
+			Latitude:      stop.Latitude,
+			Longitude:     stop.Longitude,
+			Load:          stop.Load,
+			LegDistanceKm: stop.LegDistanceKm,
+			Status:        stop.Status,
+			CompletedAt:   stop.CompletedAt,
+		}
+	}
+
+	response := &dto.TripResponse{
+		ID:              trip.ID,
+		TransporterID:   trip.TransporterID,
+		VehicleID:       trip.VehicleID,
+		Status:          trip.Status,
+		StartLatitude:   trip.StartLatitude,
+		StartLongitude:  trip.StartLongitude,
+		TotalDistanceKm: trip.TotalDistanceKm,
+		TotalLoad:       trip.TotalLoad,
+		PlannedStart:    trip.PlannedStart,
+		PlannedEnd:      trip.PlannedEnd,
+		StartedAt:       trip.StartedAt,
+		CompletedAt:     trip.CompletedAt,
+		Notes:           trip.Notes,
+		Stops:           stops,
+		CreatedAt:       trip.CreatedAt,
+	}
+	if vehicle != nil {
+		response.VehicleCapacity = vehicle.Capacity
+	}
+
+	return response
+}
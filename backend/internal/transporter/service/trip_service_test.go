@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	orderModel "agro_konnect/internal/order/model"
+	model "agro_konnect/internal/transporter/model"
+	"agro_konnect/internal/transporter/repository"
+
+	"github.com/google/uuid"
+)
+
+// The fakes embed the repository interfaces, so a test that reaches a
+// method they don't implement panics instead of passing by accident.
+
+type fakeTrips struct {
+	repository.TripRepository
+	trips    map[uuid.UUID]*model.Trip
+	bookings []*model.SlotBooking
+}
+
+func (r *fakeTrips) FindByID(ctx context.Context, id uuid.UUID) (*model.Trip, error) {
+	return r.trips[id], nil
+}
+
+func (r *fakeTrips) Cancel(ctx context.Context, tripID uuid.UUID, from []model.TripStatus) (bool, error) {
+	trip := r.trips[tripID]
+	moved := true
+	for _, status := range from {
+		if trip.Status == status {
+			moved = false
+		}
+	}
+	if moved {
+		return false, nil
+	}
+	trip.Status = model.TripStatusCancelled
+	for _, booking := range r.bookings {
+		if booking.TripID != nil && *booking.TripID == tripID {
+			booking.Status = model.BookingStatusCancelled
+		}
+	}
+	return true, nil
+}
+
+type fakeBookings struct {
+	repository.AvailabilityRepository
+	bookings []*model.SlotBooking
+}
+
+func (r *fakeBookings) FindBookingsByOrder(ctx context.Context, orderID uuid.UUID) ([]*model.SlotBooking, error) {
+	var found []*model.SlotBooking
+	for _, booking := range r.bookings {
+		if booking.OrderID == orderID && booking.Status == model.BookingStatusBooked {
+			found = append(found, booking)
+		}
+	}
+	return found, nil
+}
+
+type fakeVehicles struct {
+	repository.VehicleRepository
+	vehicles map[uuid.UUID]*model.Vehicle
+}
+
+func (r *fakeVehicles) FindByID(ctx context.Context, id uuid.UUID) (*model.Vehicle, error) {
+	return r.vehicles[id], nil
+}
+
+func TestCancelTripReleasesItsBookings(t *testing.T) {
+	transporterID := uuid.New()
+	vehicle := &model.Vehicle{ID: uuid.New(), TransporterID: transporterID}
+
+	tests := []struct {
+		name    string
+		status  model.TripStatus
+		wantErr error
+	}{
+		{"planned", model.TripStatusPlanned, nil},
+		{"in progress", model.TripStatusInProgress, nil},
+		{"completed", model.TripStatusCompleted, ErrInvalidTripTransition},
+		{"already cancelled", model.TripStatusCancelled, ErrInvalidTripTransition},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trip := &model.Trip{ID: uuid.New(), TransporterID: transporterID, VehicleID: vehicle.ID, Status: tt.status}
+			forTrip := &model.SlotBooking{ID: uuid.New(), TripID: &trip.ID, Status: model.BookingStatusBooked}
+			// Booked when the order was assigned, not by the trip
+			forOrder := &model.SlotBooking{ID: uuid.New(), Status: model.BookingStatusBooked}
+
+			trips := &fakeTrips{trips: map[uuid.UUID]*model.Trip{trip.ID: trip}, bookings: []*model.SlotBooking{forTrip, forOrder}}
+			s := NewTripService(trips, &fakeVehicles{vehicles: map[uuid.UUID]*model.Vehicle{vehicle.ID: vehicle}}, nil, nil, nil)
+
+			resp, err := s.UpdateTripStatus(context.Background(), trip.ID, transporterID, model.TripStatusCancelled)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("UpdateTripStatus = %v, want %v", err, tt.wantErr)
+			}
+
+			wantBooking := model.BookingStatusBooked
+			if tt.wantErr == nil {
+				if resp.Status != model.TripStatusCancelled {
+					t.Errorf("status = %s, want cancelled", resp.Status)
+				}
+				wantBooking = model.BookingStatusCancelled
+			}
+			if forTrip.Status != wantBooking {
+				t.Errorf("trip booking = %s, want %s", forTrip.Status, wantBooking)
+			}
+			if forOrder.Status != model.BookingStatusBooked {
+				t.Errorf("order booking = %s, want it kept", forOrder.Status)
+			}
+		})
+	}
+}
+
+func TestOrderLoad(t *testing.T) {
+	vehicleID, otherVehicleID := uuid.New(), uuid.New()
+	order := &orderModel.Order{
+		ID:         uuid.New(),
+		OrderItems: []orderModel.OrderItem{{Quantity: 500, Unit: "kg"}},
+	}
+	booking := func(vehicleID uuid.UUID, weight float64) *model.SlotBooking {
+		return &model.SlotBooking{
+			ID:        uuid.New(),
+			VehicleID: vehicleID,
+			OrderID:   order.ID,
+			Load:      model.TransportCapacity{Weight: weight},
+			Status:    model.BookingStatusBooked,
+		}
+	}
+
+	tests := []struct {
+		name       string
+		bookings   []*model.SlotBooking
+		wantLoad   float64
+		wantBooked bool
+		wantErr    error
+	}{
+		{"not booked", nil, 0.5, false, nil},
+		{"booked on the vehicle", []*model.SlotBooking{booking(vehicleID, 0.3), booking(vehicleID, 0.4)}, 0.7, true, nil},
+		{"booked on another vehicle", []*model.SlotBooking{booking(otherVehicleID, 0.5)}, 0, false, ErrTripOrderOnOtherVehicle},
+		{"booked on both", []*model.SlotBooking{booking(vehicleID, 0.5), booking(otherVehicleID, 0.5)}, 0, false, ErrTripOrderOnOtherVehicle},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &tripService{availabilityRepo: &fakeBookings{bookings: tt.bookings}}
+			load, booked, err := s.orderLoad(context.Background(), order, vehicleID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("orderLoad = %v, want %v", err, tt.wantErr)
+			}
+			if load.Weight != tt.wantLoad || booked != tt.wantBooked {
+				t.Errorf("orderLoad = %v, %v, want %v, %v", load.Weight, booked, tt.wantLoad, tt.wantBooked)
+			}
+		})
+	}
+}
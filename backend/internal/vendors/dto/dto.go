@@ -15,6 +15,7 @@ type CreateVendorRequest struct {
 	Description  string                 `json:"description"`
 	VendorType   vendorModel.VendorType `json:"vendor_type" validate:"required,oneof=seed_supplier fertilizer_supplier equipment_supplier pesticide_supplier irrigation_supplier"`
 	BusinessType string                 `json:"business_type" validate:"required"`
+	LogoURL      string                 `json:"logo_url"` // from the logo upload
 
 	Address string `json:"address" validate:"required"`
 	City    string `json:"city" validate:"required"`
@@ -54,6 +55,7 @@ type VendorResponse struct {
 	Description  string                 `json:"description"`
 	VendorType   vendorModel.VendorType `json:"vendor_type"`
 	BusinessType string                 `json:"business_type"`
+	LogoURL      string                 `json:"logo_url"`
 
 	Address string `json:"address"`
 	City    string `json:"city"`
@@ -95,6 +97,7 @@ type UpdateVendorRequest struct {
 	CompanyName    string `json:"company_name" validate:"omitempty,min=2,max=200"`
 	BrandName      string `json:"brand_name" validate:"omitempty,max=100"`
 	Description    string `json:"description" validate:"omitempty,max=1000"`
+	LogoURL        string `json:"logo_url" validate:"omitempty,max=500"`
 	Address        string `json:"address" validate:"omitempty,min=5,max=255"`
 	City           string `json:"city" validate:"omitempty,min=2,max=100"`
 	State          string `json:"state" validate:"omitempty,min=2,max=100"`
@@ -1,12 +1,16 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
+	"mime/multipart"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
 
+	"agro_konnect/internal/auth/middleware"
+	mediaService "agro_konnect/internal/media/service"
 	vendorUtils "agro_konnect/internal/vendors/utils"
 
 	"github.com/gin-gonic/gin"
@@ -14,108 +18,36 @@ import (
 )
 
 type VendorImageHandler struct {
-	uploadDir string
+	mediaService mediaService.MediaService
+	legacyDir    string // images and logos uploaded before media storage
 }
 
-func NewVendorImageHandler(uploadDir string) *VendorImageHandler {
-	// Create upload directory if it doesn't exist
-	if err := os.MkdirAll(uploadDir, 0755); err != nil {
-		panic(fmt.Sprintf("Failed to create upload directory: %v", err))
-	}
-
+func NewVendorImageHandler(mediaService mediaService.MediaService, legacyDir string) *VendorImageHandler {
 	return &VendorImageHandler{
-		uploadDir: uploadDir,
+		mediaService: mediaService,
+		legacyDir:    legacyDir,
 	}
 }
 
 // UploadVendorProductImage handles vendor product image upload
 // @Summary Upload vendor product image
-// @Description Upload an image for a vendor product
+// @Description Upload an image for a vendor product. Metadata such as GPS location is stripped. Returns the URL to put in the product's images; add ?size=thumb, medium or large to fetch it resized
 // @Tags vendor-products
 // @Accept multipart/form-data
 // @Produce json
 // @Security BearerAuth
-// @Param file formData file true "Image file"
+// @Param file formData file true "Image file (JPEG, PNG or WebP, max 5MB)"
 // @Success 200 {object} vendorUtils.SuccessResponse{data=string} "Image uploaded successfully"
 // @Failure 400 {object} vendorUtils.ErrorResponse "Invalid file"
 // @Failure 500 {object} vendorUtils.ErrorResponse "Failed to upload image"
 // @Router /vendors/products/images/upload [post]
 func (h *VendorImageHandler) UploadVendorProductImage(c *gin.Context) {
-	// Get the file from the form data
-	file, err := c.FormFile("file")
-	if err != nil {
-		vendorUtils.RespondWithError(c, http.StatusBadRequest, "Failed to get file from form data")
-		return
-	}
-
-	// Validate file size (max 5MB)
-	if file.Size > 5<<20 {
-		vendorUtils.RespondWithError(c, http.StatusBadRequest, "File size too large. Maximum size is 5MB")
-		return
-	}
-
-	// Validate file type
-	allowedTypes := map[string]bool{
-		"image/jpeg": true,
-		"image/jpg":  true,
-		"image/png":  true,
-		"image/webp": true,
-	}
-
-	fileHeader, err := file.Open()
-	if err != nil {
-		vendorUtils.RespondWithError(c, http.StatusBadRequest, "Failed to open file")
-		return
-	}
-	defer fileHeader.Close()
-
-	// Read first 512 bytes to detect content type
-	buffer := make([]byte, 512)
-	_, err = fileHeader.Read(buffer)
-	if err != nil {
-		vendorUtils.RespondWithError(c, http.StatusBadRequest, "Failed to read file")
-		return
-	}
-
-	contentType := http.DetectContentType(buffer)
-	if !allowedTypes[contentType] {
-		vendorUtils.RespondWithError(c, http.StatusBadRequest, "Invalid file type. Only JPEG, PNG, and WebP are allowed")
-		return
-	}
-
-	// Generate unique filename
-	fileExt := filepath.Ext(file.Filename)
-	if fileExt == "" {
-		// Determine extension from content type
-		switch contentType {
-		case "image/jpeg", "image/jpg":
-			fileExt = ".jpg"
-		case "image/png":
-			fileExt = ".png"
-		case "image/webp":
-			fileExt = ".webp"
-		default:
-			fileExt = ".jpg"
-		}
-	}
-
-	filename := fmt.Sprintf("%s%s", uuid.New().String(), strings.ToLower(fileExt))
-	filePath := filepath.Join(h.uploadDir, filename)
-
-	// Save the file
-	if err := c.SaveUploadedFile(file, filePath); err != nil {
-		vendorUtils.RespondWithError(c, http.StatusInternalServerError, "Failed to save file")
-		return
-	}
-
-	// Return the file path or URL
-	imageURL := fmt.Sprintf("/api/vendors/products/images/%s", filename)
-	vendorUtils.RespondWithSuccess(c, http.StatusOK, "Image uploaded successfully", imageURL)
+	h.uploadOne(c, mediaService.VendorProductImagePolicy, "Image uploaded successfully")
 }
 
-// ServeVendorProductImage serves uploaded vendor product images
+// ServeVendorProductImage serves vendor product images uploaded before media storage
 // @Summary Serve vendor product image
-// @Description Serve uploaded vendor product images
+// @Description Serve a vendor product image uploaded before media storage. Newer images are served from /media
 // @Tags vendor-products
 // @Produce image/*
 // @Param filename path string true "Image filename"
@@ -123,78 +55,45 @@ func (h *VendorImageHandler) UploadVendorProductImage(c *gin.Context) {
 // @Failure 404 {object} vendorUtils.ErrorResponse "Image not found"
 // @Router /vendors/products/images/{filename} [get]
 func (h *VendorImageHandler) ServeVendorProductImage(c *gin.Context) {
-	filename := c.Param("filename")
-	if filename == "" {
-		vendorUtils.RespondWithError(c, http.StatusBadRequest, "Filename is required")
-		return
-	}
-
-	// Security check: prevent directory traversal
-	if strings.Contains(filename, "..") || strings.Contains(filename, "/") {
-		vendorUtils.RespondWithError(c, http.StatusBadRequest, "Invalid filename")
-		return
-	}
-
-	filePath := filepath.Join(h.uploadDir, filename)
-
-	// Check if file exists
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		vendorUtils.RespondWithError(c, http.StatusNotFound, "Image not found")
-		return
-	}
-
-	// Set appropriate content type based on file extension
-	ext := strings.ToLower(filepath.Ext(filename))
-	switch ext {
-	case ".jpg", ".jpeg":
-		c.Header("Content-Type", "image/jpeg")
-	case ".png":
-		c.Header("Content-Type", "image/png")
-	case ".webp":
-		c.Header("Content-Type", "image/webp")
-	default:
-		c.Header("Content-Type", "application/octet-stream")
-	}
-
-	c.File(filePath)
+	h.serveLegacy(c, h.legacyDir, "Image not found")
 }
 
 // DeleteVendorProductImage deletes a vendor product image
 // @Summary Delete vendor product image
-// @Description Delete an uploaded vendor product image
+// @Description Delete an image the user uploaded, by the ID at the end of its URL
 // @Tags vendor-products
 // @Produce json
 // @Security BearerAuth
-// @Param filename path string true "Image filename"
+// @Param id path string true "Image ID"
 // @Success 200 {object} vendorUtils.SuccessResponse "Image deleted successfully"
-// @Failure 400 {object} vendorUtils.ErrorResponse "Invalid filename"
+// @Failure 400 {object} vendorUtils.ErrorResponse "Invalid image ID"
+// @Failure 403 {object} vendorUtils.ErrorResponse "Forbidden"
 // @Failure 404 {object} vendorUtils.ErrorResponse "Image not found"
 // @Failure 500 {object} vendorUtils.ErrorResponse "Failed to delete image"
-// @Router /vendors/products/images/{filename} [delete]
+// @Router /vendors/products/images/{id} [delete]
 func (h *VendorImageHandler) DeleteVendorProductImage(c *gin.Context) {
-	filename := c.Param("filename")
-	if filename == "" {
-		vendorUtils.RespondWithError(c, http.StatusBadRequest, "Filename is required")
-		return
-	}
-
-	// Security check: prevent directory traversal
-	if strings.Contains(filename, "..") || strings.Contains(filename, "/") {
-		vendorUtils.RespondWithError(c, http.StatusBadRequest, "Invalid filename")
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		vendorUtils.RespondWithError(c, http.StatusUnauthorized, err.Error())
 		return
 	}
+	userRole := fmt.Sprintf("%v", c.MustGet(middleware.UserRoleContextKey))
 
-	filePath := filepath.Join(h.uploadDir, filename)
-
-	// Check if file exists
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		vendorUtils.RespondWithError(c, http.StatusNotFound, "Image not found")
+	imageID, err := uuid.Parse(c.Param("filename"))
+	if err != nil {
+		vendorUtils.RespondWithError(c, http.StatusBadRequest, "Invalid image ID")
 		return
 	}
 
-	// Delete the file
-	if err := os.Remove(filePath); err != nil {
-		vendorUtils.RespondWithError(c, http.StatusInternalServerError, "Failed to delete image")
+	if err := h.mediaService.Delete(c.Request.Context(), imageID, userID, userRole); err != nil {
+		switch {
+		case errors.Is(err, mediaService.ErrAttachmentNotFound):
+			vendorUtils.RespondWithError(c, http.StatusNotFound, "Image not found")
+		case errors.Is(err, mediaService.ErrUnauthorizedAccess):
+			vendorUtils.RespondWithError(c, http.StatusForbidden, err.Error())
+		default:
+			vendorUtils.RespondWithError(c, http.StatusInternalServerError, "Failed to delete image")
+		}
 		return
 	}
 
@@ -203,17 +102,23 @@ func (h *VendorImageHandler) DeleteVendorProductImage(c *gin.Context) {
 
 // UploadMultipleVendorProductImages handles multiple vendor product image uploads
 // @Summary Upload multiple vendor product images
-// @Description Upload multiple images for a vendor product
+// @Description Upload up to 5 images for a vendor product. Returns their URLs in order
 // @Tags vendor-products
 // @Accept multipart/form-data
 // @Produce json
 // @Security BearerAuth
-// @Param files formData file true "Image files" collectionFormat(multi)
+// @Param files formData file true "Image files (JPEG, PNG or WebP, max 5MB each)" collectionFormat(multi)
 // @Success 200 {object} vendorUtils.SuccessResponse{data=[]string} "Images uploaded successfully"
 // @Failure 400 {object} vendorUtils.ErrorResponse "Invalid files"
 // @Failure 500 {object} vendorUtils.ErrorResponse "Failed to upload images"
 // @Router /vendors/products/images/upload-multiple [post]
 func (h *VendorImageHandler) UploadMultipleVendorProductImages(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		vendorUtils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
 	form, err := c.MultipartForm()
 	if err != nil {
 		vendorUtils.RespondWithError(c, http.StatusBadRequest, "Failed to parse form data")
@@ -233,31 +138,13 @@ func (h *VendorImageHandler) UploadMultipleVendorProductImages(c *gin.Context) {
 	}
 
 	var uploadedURLs []string
-
 	for _, file := range files {
-		// Validate file size (max 5MB)
-		if file.Size > 5<<20 {
-			vendorUtils.RespondWithError(c, http.StatusBadRequest, fmt.Sprintf("File %s is too large. Maximum size is 5MB", file.Filename))
-			return
-		}
-
-		// Generate unique filename
-		fileExt := filepath.Ext(file.Filename)
-		if fileExt == "" {
-			fileExt = ".jpg"
-		}
-
-		filename := fmt.Sprintf("%s%s", uuid.New().String(), strings.ToLower(fileExt))
-		filePath := filepath.Join(h.uploadDir, filename)
-
-		// Save the file
-		if err := c.SaveUploadedFile(file, filePath); err != nil {
-			vendorUtils.RespondWithError(c, http.StatusInternalServerError, "Failed to save files")
+		url, err := h.upload(c, mediaService.VendorProductImagePolicy, userID, file)
+		if err != nil {
+			respondWithUploadError(c, fmt.Errorf("%s: %w", file.Filename, err), "Failed to upload images")
 			return
 		}
-
-		imageURL := fmt.Sprintf("/api/vendors/products/images/%s", filename)
-		uploadedURLs = append(uploadedURLs, imageURL)
+		uploadedURLs = append(uploadedURLs, url)
 	}
 
 	vendorUtils.RespondWithSuccess(c, http.StatusOK, "Images uploaded successfully", uploadedURLs)
@@ -265,142 +152,91 @@ func (h *VendorImageHandler) UploadMultipleVendorProductImages(c *gin.Context) {
 
 // UploadVendorLogo handles vendor logo upload
 // @Summary Upload vendor logo
-// @Description Upload a logo for a vendor profile
+// @Description Upload a logo for a vendor profile. Returns the URL to put in the profile's logo_url
 // @Tags vendors
 // @Accept multipart/form-data
 // @Produce json
 // @Security BearerAuth
-// @Param file formData file true "Logo file"
+// @Param file formData file true "Logo file (JPEG, PNG or WebP, max 2MB)"
 // @Success 200 {object} vendorUtils.SuccessResponse{data=string} "Logo uploaded successfully"
 // @Failure 400 {object} vendorUtils.ErrorResponse "Invalid file"
 // @Failure 500 {object} vendorUtils.ErrorResponse "Failed to upload logo"
 // @Router /vendors/logo/upload [post]
 func (h *VendorImageHandler) UploadVendorLogo(c *gin.Context) {
-	// Get the file from the form data
-	file, err := c.FormFile("file")
-	if err != nil {
-		vendorUtils.RespondWithError(c, http.StatusBadRequest, "Failed to get file from form data")
-		return
-	}
-
-	// Validate file size (max 2MB for logos)
-	if file.Size > 2<<20 {
-		vendorUtils.RespondWithError(c, http.StatusBadRequest, "File size too large. Maximum size is 2MB")
-		return
-	}
+	h.uploadOne(c, mediaService.VendorLogoPolicy, "Logo uploaded successfully")
+}
 
-	// Validate file type
-	allowedTypes := map[string]bool{
-		"image/jpeg":    true,
-		"image/jpg":     true,
-		"image/png":     true,
-		"image/webp":    true,
-		"image/svg+xml": true,
-	}
+// ServeVendorLogo serves vendor logos uploaded before media storage
+// @Summary Serve vendor logo
+// @Description Serve a vendor logo uploaded before media storage. Newer logos are served from /media
+// @Tags vendors
+// @Produce image/*
+// @Param filename path string true "Logo filename"
+// @Success 200 {file} file "Logo file"
+// @Failure 404 {object} vendorUtils.ErrorResponse "Logo not found"
+// @Router /vendors/logos/{filename} [get]
+func (h *VendorImageHandler) ServeVendorLogo(c *gin.Context) {
+	h.serveLegacy(c, filepath.Join(h.legacyDir, "logos"), "Logo not found")
+}
 
-	fileHeader, err := file.Open()
+func (h *VendorImageHandler) uploadOne(c *gin.Context, policy mediaService.Policy, message string) {
+	userID, err := GetUserIDFromContext(c)
 	if err != nil {
-		vendorUtils.RespondWithError(c, http.StatusBadRequest, "Failed to open file")
+		vendorUtils.RespondWithError(c, http.StatusUnauthorized, err.Error())
 		return
 	}
-	defer fileHeader.Close()
 
-	// Read first 512 bytes to detect content type
-	buffer := make([]byte, 512)
-	_, err = fileHeader.Read(buffer)
+	file, err := c.FormFile("file")
 	if err != nil {
-		vendorUtils.RespondWithError(c, http.StatusBadRequest, "Failed to read file")
+		vendorUtils.RespondWithError(c, http.StatusBadRequest, "Failed to get file from form data")
 		return
 	}
 
-	contentType := http.DetectContentType(buffer)
-	if !allowedTypes[contentType] {
-		vendorUtils.RespondWithError(c, http.StatusBadRequest, "Invalid file type. Only JPEG, PNG, WebP, and SVG are allowed")
+	url, err := h.upload(c, policy, userID, file)
+	if err != nil {
+		respondWithUploadError(c, err, "Failed to upload file")
 		return
 	}
 
-	// Generate unique filename
-	fileExt := filepath.Ext(file.Filename)
-	if fileExt == "" {
-		// Determine extension from content type
-		switch contentType {
-		case "image/jpeg", "image/jpg":
-			fileExt = ".jpg"
-		case "image/png":
-			fileExt = ".png"
-		case "image/webp":
-			fileExt = ".webp"
-		case "image/svg+xml":
-			fileExt = ".svg"
-		default:
-			fileExt = ".png"
-		}
-	}
-
-	filename := fmt.Sprintf("logo_%s%s", uuid.New().String(), strings.ToLower(fileExt))
-	filePath := filepath.Join(h.uploadDir, "logos", filename)
-
-	// Create logos subdirectory if it doesn't exist
-	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
-		vendorUtils.RespondWithError(c, http.StatusInternalServerError, "Failed to create directory")
-		return
-	}
+	vendorUtils.RespondWithSuccess(c, http.StatusOK, message, url)
+}
 
-	// Save the file
-	if err := c.SaveUploadedFile(file, filePath); err != nil {
-		vendorUtils.RespondWithError(c, http.StatusInternalServerError, "Failed to save file")
-		return
+func (h *VendorImageHandler) upload(c *gin.Context, policy mediaService.Policy, userID uuid.UUID, file *multipart.FileHeader) (string, error) {
+	attachment, err := h.mediaService.Upload(c.Request.Context(), mediaService.UploadRequest{
+		Policy:     policy,
+		UploadedBy: userID,
+		File:       file,
+	})
+	if err != nil {
+		return "", err
 	}
-
-	// Return the file path or URL
-	logoURL := fmt.Sprintf("/api/vendors/logos/%s", filename)
-	vendorUtils.RespondWithSuccess(c, http.StatusOK, "Logo uploaded successfully", logoURL)
+	return attachment.FileURL, nil
 }
 
-// ServeVendorLogo serves uploaded vendor logos
-// @Summary Serve vendor logo
-// @Description Serve uploaded vendor logos
-// @Tags vendors
-// @Produce image/*
-// @Param filename path string true "Logo filename"
-// @Success 200 {file} file "Logo file"
-// @Failure 404 {object} vendorUtils.ErrorResponse "Logo not found"
-// @Router /vendors/logos/{filename} [get]
-func (h *VendorImageHandler) ServeVendorLogo(c *gin.Context) {
+func (h *VendorImageHandler) serveLegacy(c *gin.Context, dir, notFound string) {
 	filename := c.Param("filename")
-	if filename == "" {
-		vendorUtils.RespondWithError(c, http.StatusBadRequest, "Filename is required")
-		return
-	}
 
 	// Security check: prevent directory traversal
-	if strings.Contains(filename, "..") || strings.Contains(filename, "/") {
+	if filename == "" || strings.Contains(filename, "..") || strings.Contains(filename, "/") {
 		vendorUtils.RespondWithError(c, http.StatusBadRequest, "Invalid filename")
 		return
 	}
 
-	filePath := filepath.Join(h.uploadDir, "logos", filename)
-
-	// Check if file exists
+	filePath := filepath.Join(dir, filename)
 	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		vendorUtils.RespondWithError(c, http.StatusNotFound, "Logo not found")
+		vendorUtils.RespondWithError(c, http.StatusNotFound, notFound)
 		return
 	}
 
-	// Set appropriate content type based on file extension
-	ext := strings.ToLower(filepath.Ext(filename))
-	switch ext {
-	case ".jpg", ".jpeg":
-		c.Header("Content-Type", "image/jpeg")
-	case ".png":
-		c.Header("Content-Type", "image/png")
-	case ".webp":
-		c.Header("Content-Type", "image/webp")
-	case ".svg":
-		c.Header("Content-Type", "image/svg+xml")
+	c.File(filePath)
+}
+
+func respondWithUploadError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, mediaService.ErrFileTooLarge), errors.Is(err, mediaService.ErrUnsupportedFileType),
+		errors.Is(err, mediaService.ErrInvalidImage), errors.Is(err, mediaService.ErrImageTooLarge):
+		vendorUtils.RespondWithError(c, http.StatusBadRequest, err.Error())
 	default:
-		c.Header("Content-Type", "application/octet-stream")
+		vendorUtils.RespondWithError(c, http.StatusInternalServerError, fallback)
 	}
-
-	c.File(filePath)
 }
@@ -26,6 +26,7 @@ type Vendor struct {
 	Description  string     `json:"description"`
 	VendorType   VendorType `gorm:"type:varchar(50)" json:"vendor_type"`
 	BusinessType string     `json:"business_type"` // wholesale, retail, manufacturer
+	LogoURL      string     `json:"logo_url"`
 
 	// Location
 	Address   string  `gorm:"not null" json:"address"`
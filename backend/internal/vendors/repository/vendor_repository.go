@@ -7,6 +7,7 @@ import (
 
 	dto "agro_konnect/internal/vendors/dto"
 	model "agro_konnect/internal/vendors/model"
+	"agro_konnect/pkg/geo"
 	"errors"
 
 	"github.com/google/uuid"
@@ -159,25 +160,23 @@ func (r *vendorRepository) UpdateStats(ctx context.Context, vendorID uuid.UUID,
 	return r.db.WithContext(ctx).Model(&model.Vendor{}).Where("id = ?", vendorID).Updates(stats).Error
 }
 
+// FindNearby returns the vendors within radius km of the point, nearest first.
+// The bounding box narrows the scan before the exact haversine check.
 func (r *vendorRepository) FindNearby(ctx context.Context, lat, lng, radius float64) ([]*model.Vendor, error) {
-	// Simple bounding box query for compatibility
-	latRange := radius / 111.0              // approx km per degree latitude
-	lngRange := radius / (111.0 * cos(lat)) // approx km per degree longitude
+	center := geo.Point{Lat: lat, Lng: lng}
+	bounds := geo.RadiusBounds(center, radius)
+	distance := geo.DistanceSQL(center, "latitude", "longitude")
 
 	var vendors []*model.Vendor
 	err := r.db.WithContext(ctx).
-		Where("latitude BETWEEN ? AND ?", lat-latRange, lat+latRange).
-		Where("longitude BETWEEN ? AND ?", lng-lngRange, lng+lngRange).
+		Where("latitude BETWEEN ? AND ?", bounds.MinLat, bounds.MaxLat).
+		Where("longitude BETWEEN ? AND ?", bounds.MinLng, bounds.MaxLng).
+		Where(distance+" <= ?", radius).
+		Order(distance).
 		Find(&vendors).Error
 	return vendors, err
 }
 
-// cos calculates cosine (simplified for the example)
-func cos(deg float64) float64 {
-	rad := deg * 0.017453292519943295
-	return (1 - rad*rad/2 + rad*rad*rad*rad/24 - rad*rad*rad*rad*rad*rad/720)
-}
-
 func (r *vendorRepository) GetProductCount(ctx context.Context, vendorID uuid.UUID) (int, error) {
 	var count int64
 	err := r.db.WithContext(ctx).Model(&model.VendorProduct{}).
@@ -1,7 +1,10 @@
 package routes
 
 import (
+	auditservice "agro_konnect/internal/audit/service"
 	"agro_konnect/internal/auth/middleware"
+	emailservice "agro_konnect/internal/email/service"
+	mediaService "agro_konnect/internal/media/service"
 	"agro_konnect/internal/vendors/handler"
 	"agro_konnect/internal/vendors/repository"
 	"agro_konnect/internal/vendors/service"
@@ -10,19 +13,19 @@ import (
 	"gorm.io/gorm"
 )
 
-func SetupVendorRoutes(router *gin.RouterGroup, db *gorm.DB, authMiddleware *middleware.AuthMiddleware) {
+func SetupVendorRoutes(router *gin.RouterGroup, db *gorm.DB, authMiddleware *middleware.AuthMiddleware, mediaService mediaService.MediaService, auditRecorder auditservice.Recorder, mailer emailservice.Mailer) {
 	// Initialize vendor dependencies
 	vendorRepo := repository.NewVendorRepository(db)
 	vendorProductRepo := repository.NewVendorProductRepository(db)
-	vendorService := service.NewVendorService(vendorRepo, vendorProductRepo)
+	vendorService := service.NewVendorService(vendorRepo, vendorProductRepo, auditRecorder, mailer)
 	vendorProductService := service.NewVendorProductService(vendorRepo, vendorProductRepo)
 
 	vendorHandler := handler.NewVendorHandler(vendorService)
 	vendorProductHandler := handler.NewVendorProductHandler(vendorProductService)
 
-	// Initialize image handler
-	vendorUploadDir := "./uploads/vendors"
-	imageHandler := handler.NewVendorImageHandler(vendorUploadDir)
+	// Initialize image handler; uploads go to media storage
+	legacyUploadDir := "./uploads/vendors"
+	imageHandler := handler.NewVendorImageHandler(mediaService, legacyUploadDir)
 
 	vendorRoutes := router.Group("/vendors")
 	{
@@ -33,7 +36,7 @@ func SetupVendorRoutes(router *gin.RouterGroup, db *gorm.DB, authMiddleware *mid
 		vendorRoutes.GET("/:id", vendorHandler.GetVendorByID)
 		vendorRoutes.GET("/:id/products", vendorProductHandler.GetVendorProducts)
 
-		// Image serving (public)
+		// Images uploaded before media storage (public)
 		vendorRoutes.GET("/products/images/:filename", imageHandler.ServeVendorProductImage)
 		vendorRoutes.GET("/logos/:filename", imageHandler.ServeVendorLogo)
 
@@ -8,6 +8,8 @@ import (
 	"strings"
 	"time"
 
+	auditservice "agro_konnect/internal/audit/service"
+	emailservice "agro_konnect/internal/email/service"
 	dto "agro_konnect/internal/vendors/dto"
 	model "agro_konnect/internal/vendors/model"
 	"agro_konnect/internal/vendors/repository"
@@ -40,12 +42,16 @@ type VendorService interface {
 type vendorService struct {
 	vendorRepo        repository.VendorRepository
 	vendorProductRepo repository.VendorProductRepository
+	audit             auditservice.Recorder
+	mailer            emailservice.Mailer
 }
 
-func NewVendorService(vendorRepo repository.VendorRepository, vendorProductRepo repository.VendorProductRepository) VendorService {
+func NewVendorService(vendorRepo repository.VendorRepository, vendorProductRepo repository.VendorProductRepository, audit auditservice.Recorder, mailer emailservice.Mailer) VendorService {
 	return &vendorService{
 		vendorRepo:        vendorRepo,
 		vendorProductRepo: vendorProductRepo,
+		audit:             audit,
+		mailer:            mailer,
 	}
 }
 
@@ -74,6 +80,7 @@ func (s *vendorService) CreateVendor(ctx context.Context, userID uuid.UUID, req
 		Description:     strings.TrimSpace(req.Description),
 		VendorType:      req.VendorType,
 		BusinessType:    strings.TrimSpace(req.BusinessType),
+		LogoURL:         strings.TrimSpace(req.LogoURL),
 		Address:         strings.TrimSpace(req.Address),
 		City:            strings.TrimSpace(req.City),
 		State:           strings.TrimSpace(req.State),
@@ -149,6 +156,9 @@ func (s *vendorService) UpdateVendor(ctx context.Context, userID uuid.UUID, req
 	if req.Description != "" {
 		vendor.Description = strings.TrimSpace(req.Description)
 	}
+	if req.LogoURL != "" {
+		vendor.LogoURL = strings.TrimSpace(req.LogoURL)
+	}
 	if req.Address != "" {
 		vendor.Address = strings.TrimSpace(req.Address)
 	}
@@ -267,10 +277,28 @@ func (s *vendorService) VerifyVendor(ctx context.Context, vendorID uuid.UUID) er
 		return ErrVendorNotFound
 	}
 
+	wasVerified := vendor.IsVerified
 	vendor.IsVerified = true
 	vendor.UpdatedAt = time.Now()
 
-	return s.vendorRepo.Update(ctx, vendor)
+	if err := s.vendorRepo.Update(ctx, vendor); err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, auditservice.Event{
+		Action:     "vendor.verify",
+		EntityType: "vendor",
+		EntityID:   vendorID.String(),
+		Before:     map[string]interface{}{"is_verified": wasVerified},
+		After:      map[string]interface{}{"is_verified": true},
+	})
+
+	if !wasVerified {
+		if err := s.mailer.SendProfileVerified(ctx, vendor.UserID, emailservice.ProfileVendor, vendor.ContactPerson); err != nil {
+			log.Printf("failed to send verification email for vendor %s: %v", vendorID, err)
+		}
+	}
+	return nil
 }
 
 func (s *vendorService) GetNearbyVendors(ctx context.Context, lat, lng, radius float64) ([]*dto.VendorResponse, error) {
@@ -321,6 +349,7 @@ func (s *vendorService) toVendorResponse(ctx context.Context, vendor *model.Vend
 		Description:     vendor.Description,
 		VendorType:      vendor.VendorType,
 		BusinessType:    vendor.BusinessType,
+		LogoURL:         vendor.LogoURL,
 		Address:         vendor.Address,
 		City:            vendor.City,
 		State:           vendor.State,
@@ -0,0 +1,232 @@
+package geo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+)
+
+var ErrInvalidGeoJSON = errors.New("geometry must be a GeoJSON Polygon or MultiPolygon with closed rings of at least four positions")
+
+// Ring is a closed linear ring. The first and last points are equal.
+type Ring []Point
+
+// Polygon is an outer ring followed by zero or more holes.
+type Polygon []Ring
+
+// MultiPolygon is a set of polygons; a point inside any of them is covered.
+type MultiPolygon []Polygon
+
+// BoundingBox is an axis-aligned box in decimal degrees.
+type BoundingBox struct {
+	MinLat float64 `json:"min_lat"`
+	MinLng float64 `json:"min_lng"`
+	MaxLat float64 `json:"max_lat"`
+	MaxLng float64 `json:"max_lng"`
+}
+
+// Contains reports whether p lies inside the box, edges included.
+func (b BoundingBox) Contains(p Point) bool {
+	return p.Lat >= b.MinLat && p.Lat <= b.MaxLat && p.Lng >= b.MinLng && p.Lng <= b.MaxLng
+}
+
+// RadiusBounds returns a box enclosing the circle of radiusKm around center.
+func RadiusBounds(center Point, radiusKm float64) BoundingBox {
+	latDelta := radiusKm / EarthRadiusKm * 180 / math.Pi
+	lngDelta := 180.0
+	if cosLat := math.Cos(toRadians(center.Lat)); cosLat > 1e-9 {
+		lngDelta = math.Min(180, latDelta/cosLat)
+	}
+
+	return BoundingBox{
+		MinLat: math.Max(-90, center.Lat-latDelta),
+		MinLng: math.Max(-180, center.Lng-lngDelta),
+		MaxLat: math.Min(90, center.Lat+latDelta),
+		MaxLng: math.Min(180, center.Lng+lngDelta),
+	}
+}
+
+// WithinRadius reports whether p is no further than radiusKm from center.
+func WithinRadius(center Point, radiusKm float64, p Point) bool {
+	return DistanceKm(center, p) <= radiusKm
+}
+
+// edgeEpsilon absorbs floating point error when testing whether a point lies
+// on a ring's edge.
+const edgeEpsilon = 1e-12
+
+// Contains reports whether p lies inside the ring using the even-odd rule.
+// Points on the ring itself are inside, matching PostGIS ST_Covers.
+func (r Ring) Contains(p Point) bool {
+	if r.onEdge(p) {
+		return true
+	}
+
+	inside := false
+	for i, j := 0, len(r)-1; i < len(r); j, i = i, i+1 {
+		a, b := r[i], r[j]
+		if (a.Lat > p.Lat) != (b.Lat > p.Lat) &&
+			p.Lng < (b.Lng-a.Lng)*(p.Lat-a.Lat)/(b.Lat-a.Lat)+a.Lng {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// onEdge reports whether p lies on one of the ring's segments.
+func (r Ring) onEdge(p Point) bool {
+	for i := 1; i < len(r); i++ {
+		a, b := r[i-1], r[i]
+		cross := (b.Lng-a.Lng)*(p.Lat-a.Lat) - (b.Lat-a.Lat)*(p.Lng-a.Lng)
+		if math.Abs(cross) > edgeEpsilon {
+			continue
+		}
+		if p.Lng >= math.Min(a.Lng, b.Lng) && p.Lng <= math.Max(a.Lng, b.Lng) &&
+			p.Lat >= math.Min(a.Lat, b.Lat) && p.Lat <= math.Max(a.Lat, b.Lat) {
+			return true
+		}
+	}
+	return false
+}
+
+// Contains reports whether p lies inside the outer ring and outside every
+// hole. The edge of a hole still belongs to the polygon.
+func (poly Polygon) Contains(p Point) bool {
+	if len(poly) == 0 || !poly[0].Contains(p) {
+		return false
+	}
+	for _, hole := range poly[1:] {
+		if hole.Contains(p) && !hole.onEdge(p) {
+			return false
+		}
+	}
+	return true
+}
+
+// Contains reports whether p lies inside any of the polygons.
+func (mp MultiPolygon) Contains(p Point) bool {
+	for _, poly := range mp {
+		if poly.Contains(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// Bounds returns the bounding box of all outer rings.
+func (mp MultiPolygon) Bounds() BoundingBox {
+	box := BoundingBox{MinLat: 90, MinLng: 180, MaxLat: -90, MaxLng: -180}
+	for _, poly := range mp {
+		if len(poly) == 0 {
+			continue
+		}
+		for _, pt := range poly[0] {
+			box.MinLat = math.Min(box.MinLat, pt.Lat)
+			box.MinLng = math.Min(box.MinLng, pt.Lng)
+			box.MaxLat = math.Max(box.MaxLat, pt.Lat)
+			box.MaxLng = math.Max(box.MaxLng, pt.Lng)
+		}
+	}
+	return box
+}
+
+type geoJSONObject struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates"`
+	Geometry    json.RawMessage `json:"geometry"`
+}
+
+// ParseGeoJSON reads a Polygon, MultiPolygon or a Feature wrapping one.
+// Positions are [longitude, latitude] as required by RFC 7946.
+func ParseGeoJSON(data []byte) (MultiPolygon, error) {
+	var obj geoJSONObject
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, ErrInvalidGeoJSON
+	}
+
+	var mp MultiPolygon
+	switch obj.Type {
+	case "Feature":
+		if len(obj.Geometry) == 0 {
+			return nil, ErrInvalidGeoJSON
+		}
+		return ParseGeoJSON(obj.Geometry)
+	case "Polygon":
+		var coords [][][]float64
+		if err := json.Unmarshal(obj.Coordinates, &coords); err != nil {
+			return nil, ErrInvalidGeoJSON
+		}
+		poly, err := toPolygon(coords)
+		if err != nil {
+			return nil, err
+		}
+		mp = MultiPolygon{poly}
+	case "MultiPolygon":
+		var coords [][][][]float64
+		if err := json.Unmarshal(obj.Coordinates, &coords); err != nil {
+			return nil, ErrInvalidGeoJSON
+		}
+		for _, c := range coords {
+			poly, err := toPolygon(c)
+			if err != nil {
+				return nil, err
+			}
+			mp = append(mp, poly)
+		}
+	default:
+		return nil, ErrInvalidGeoJSON
+	}
+
+	if len(mp) == 0 {
+		return nil, ErrInvalidGeoJSON
+	}
+	return mp, nil
+}
+
+// GeoJSON encodes the shape as a GeoJSON MultiPolygon geometry.
+func (mp MultiPolygon) GeoJSON() ([]byte, error) {
+	coords := make([][][][]float64, len(mp))
+	for i, poly := range mp {
+		coords[i] = make([][][]float64, len(poly))
+		for j, ring := range poly {
+			coords[i][j] = make([][]float64, len(ring))
+			for k, pt := range ring {
+				coords[i][j][k] = []float64{pt.Lng, pt.Lat}
+			}
+		}
+	}
+
+	return json.Marshal(map[string]interface{}{
+		"type":        "MultiPolygon",
+		"coordinates": coords,
+	})
+}
+
+func toPolygon(coords [][][]float64) (Polygon, error) {
+	if len(coords) == 0 {
+		return nil, ErrInvalidGeoJSON
+	}
+
+	poly := make(Polygon, len(coords))
+	for i, ringCoords := range coords {
+		if len(ringCoords) < 4 {
+			return nil, ErrInvalidGeoJSON
+		}
+		ring := make(Ring, len(ringCoords))
+		for j, pos := range ringCoords {
+			if len(pos) < 2 {
+				return nil, ErrInvalidGeoJSON
+			}
+			ring[j] = Point{Lat: pos[1], Lng: pos[0]}
+			if ring[j].Lat < -90 || ring[j].Lat > 90 || ring[j].Lng < -180 || ring[j].Lng > 180 {
+				return nil, fmt.Errorf("%w: position out of range", ErrInvalidGeoJSON)
+			}
+		}
+		if ring[0] != ring[len(ring)-1] {
+			return nil, ErrInvalidGeoJSON
+		}
+		poly[i] = ring
+	}
+	return poly, nil
+}
@@ -0,0 +1,218 @@
+package geo
+
+import (
+	"errors"
+	"testing"
+)
+
+// square returns the closed ring of the axis-aligned square between min and
+// max on both axes.
+func square(min, max float64) Ring {
+	return Ring{
+		{Lat: min, Lng: min},
+		{Lat: min, Lng: max},
+		{Lat: max, Lng: max},
+		{Lat: max, Lng: min},
+		{Lat: min, Lng: min},
+	}
+}
+
+func TestRingContains(t *testing.T) {
+	ring := square(0, 10)
+	triangle := Ring{{Lat: 0, Lng: 0}, {Lat: 0, Lng: 10}, {Lat: 10, Lng: 0}, {Lat: 0, Lng: 0}}
+
+	tests := []struct {
+		name string
+		ring Ring
+		p    Point
+		want bool
+	}{
+		{"inside", ring, Point{Lat: 5, Lng: 5}, true},
+		{"outside", ring, Point{Lat: 15, Lng: 5}, false},
+		{"left edge", ring, Point{Lat: 5, Lng: 0}, true},
+		{"right edge", ring, Point{Lat: 5, Lng: 10}, true},
+		{"bottom edge", ring, Point{Lat: 0, Lng: 5}, true},
+		{"top edge", ring, Point{Lat: 10, Lng: 5}, true},
+		{"vertex", ring, Point{Lat: 10, Lng: 10}, true},
+		{"in line with an edge but past it", ring, Point{Lat: 10, Lng: 12}, false},
+		{"on hypotenuse", triangle, Point{Lat: 5, Lng: 5}, true},
+		{"past hypotenuse", triangle, Point{Lat: 5.1, Lng: 5}, false},
+		{"empty ring", Ring{}, Point{Lat: 0, Lng: 0}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.ring.Contains(tt.p); got != tt.want {
+				t.Errorf("Contains(%v) = %v, want %v", tt.p, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolygonContains(t *testing.T) {
+	withHole := Polygon{square(0, 10), square(4, 6)}
+	twoHoles := Polygon{square(0, 10), square(1, 2), square(7, 8)}
+
+	tests := []struct {
+		name string
+		poly Polygon
+		p    Point
+		want bool
+	}{
+		{"inside outer ring", withHole, Point{Lat: 2, Lng: 2}, true},
+		{"inside hole", withHole, Point{Lat: 5, Lng: 5}, false},
+		{"on hole edge", withHole, Point{Lat: 4, Lng: 5}, true},
+		{"on outer edge", withHole, Point{Lat: 0, Lng: 5}, true},
+		{"outside", withHole, Point{Lat: -1, Lng: 5}, false},
+		{"inside second hole", twoHoles, Point{Lat: 7.5, Lng: 7.5}, false},
+		{"between holes", twoHoles, Point{Lat: 5, Lng: 5}, true},
+		{"no rings", Polygon{}, Point{Lat: 5, Lng: 5}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.poly.Contains(tt.p); got != tt.want {
+				t.Errorf("Contains(%v) = %v, want %v", tt.p, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMultiPolygonContains(t *testing.T) {
+	mp := MultiPolygon{
+		{square(0, 10), square(4, 6)},
+		{square(20, 30)},
+	}
+
+	tests := []struct {
+		name string
+		p    Point
+		want bool
+	}{
+		{"in first polygon", Point{Lat: 2, Lng: 2}, true},
+		{"in hole of first polygon", Point{Lat: 5, Lng: 5}, false},
+		{"in second polygon", Point{Lat: 25, Lng: 25}, true},
+		{"on second polygon edge", Point{Lat: 20, Lng: 25}, true},
+		{"between polygons", Point{Lat: 15, Lng: 15}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mp.Contains(tt.p); got != tt.want {
+				t.Errorf("Contains(%v) = %v, want %v", tt.p, got, tt.want)
+			}
+		})
+	}
+
+	if got := (MultiPolygon{}).Contains(Point{}); got {
+		t.Error("empty MultiPolygon contains a point")
+	}
+}
+
+func TestParseGeoJSON(t *testing.T) {
+	const polygon = `{"type":"Polygon","coordinates":[[[0,0],[10,0],[10,10],[0,10],[0,0]],[[4,4],[6,4],[6,6],[4,6],[4,4]]]}`
+
+	tests := []struct {
+		name     string
+		input    string
+		polygons int
+		inside   Point
+		outside  Point
+	}{
+		{
+			name:     "polygon with hole",
+			input:    polygon,
+			polygons: 1,
+			inside:   Point{Lat: 2, Lng: 2},
+			outside:  Point{Lat: 5, Lng: 5},
+		},
+		{
+			name:     "multipolygon",
+			input:    `{"type":"MultiPolygon","coordinates":[[[[0,0],[1,0],[1,1],[0,1],[0,0]]],[[[20,20],[21,20],[21,21],[20,21],[20,20]]]]}`,
+			polygons: 2,
+			inside:   Point{Lat: 20.5, Lng: 20.5},
+			outside:  Point{Lat: 10, Lng: 10},
+		},
+		{
+			name:     "feature",
+			input:    `{"type":"Feature","properties":{},"geometry":` + polygon + `}`,
+			polygons: 1,
+			inside:   Point{Lat: 8, Lng: 8},
+			outside:  Point{Lat: 11, Lng: 8},
+		},
+		{
+			name:     "longitude comes first",
+			input:    `{"type":"Polygon","coordinates":[[[70,10],[80,10],[80,20],[70,20],[70,10]]]}`,
+			polygons: 1,
+			inside:   Point{Lat: 15, Lng: 75},
+			outside:  Point{Lat: 75, Lng: 15},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mp, err := ParseGeoJSON([]byte(tt.input))
+			if err != nil {
+				t.Fatalf("ParseGeoJSON: %v", err)
+			}
+			if len(mp) != tt.polygons {
+				t.Fatalf("got %d polygons, want %d", len(mp), tt.polygons)
+			}
+			if !mp.Contains(tt.inside) {
+				t.Errorf("shape does not contain %v", tt.inside)
+			}
+			if mp.Contains(tt.outside) {
+				t.Errorf("shape contains %v", tt.outside)
+			}
+		})
+	}
+}
+
+func TestParseGeoJSONInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"not json", `{"type":`},
+		{"not an object", `[1,2]`},
+		{"unsupported type", `{"type":"Point","coordinates":[0,0]}`},
+		{"feature without geometry", `{"type":"Feature","properties":{}}`},
+		{"feature wrapping a line", `{"type":"Feature","geometry":{"type":"LineString","coordinates":[[0,0],[1,1]]}}`},
+		{"no rings", `{"type":"Polygon","coordinates":[]}`},
+		{"empty multipolygon", `{"type":"MultiPolygon","coordinates":[]}`},
+		{"too few positions", `{"type":"Polygon","coordinates":[[[0,0],[1,0],[0,0]]]}`},
+		{"ring not closed", `{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,1]]]}`},
+		{"position missing latitude", `{"type":"Polygon","coordinates":[[[0,0],[1],[1,1],[0,0]]]}`},
+		{"latitude out of range", `{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,91],[0,0]]]}`},
+		{"longitude out of range", `{"type":"Polygon","coordinates":[[[0,0],[181,0],[1,1],[0,0]]]}`},
+		{"coordinates of the wrong depth", `{"type":"Polygon","coordinates":[[0,0],[1,0],[1,1],[0,0]]}`},
+		{"bad hole", `{"type":"Polygon","coordinates":[[[0,0],[10,0],[10,10],[0,0]],[[1,1],[2,1]]]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseGeoJSON([]byte(tt.input)); !errors.Is(err, ErrInvalidGeoJSON) {
+				t.Errorf("ParseGeoJSON error = %v, want ErrInvalidGeoJSON", err)
+			}
+		})
+	}
+}
+
+func TestGeoJSONRoundTrip(t *testing.T) {
+	mp := MultiPolygon{{square(0, 10), square(4, 6)}}
+
+	data, err := mp.GeoJSON()
+	if err != nil {
+		t.Fatalf("GeoJSON: %v", err)
+	}
+	parsed, err := ParseGeoJSON(data)
+	if err != nil {
+		t.Fatalf("ParseGeoJSON: %v", err)
+	}
+	if parsed.Bounds() != mp.Bounds() {
+		t.Errorf("bounds = %+v, want %+v", parsed.Bounds(), mp.Bounds())
+	}
+	if parsed.Contains(Point{Lat: 5, Lng: 5}) {
+		t.Error("hole lost in round trip")
+	}
+}
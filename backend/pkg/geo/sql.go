@@ -0,0 +1,25 @@
+package geo
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// DistanceSQL returns a SQL expression for the haversine distance in
+// kilometers from the point to the coordinates held by latExpr and lngExpr,
+// which may be column names or expressions. The point is written into the
+// SQL as numeric literals, so the expression takes no bind parameters and
+// can be used in SELECT, WHERE and ORDER BY alike.
+func DistanceSQL(from Point, latExpr, lngExpr string) string {
+	lat := strconv.FormatFloat(from.Lat, 'f', -1, 64)
+	lng := strconv.FormatFloat(from.Lng, 'f', -1, 64)
+
+	return fmt.Sprintf(
+		"(2 * %s * ASIN(LEAST(1, SQRT("+
+			"POWER(SIN(RADIANS((%s) - %s) / 2), 2) + "+
+			"COS(RADIANS(%s)) * COS(RADIANS(%s)) * POWER(SIN(RADIANS((%s) - %s) / 2), 2)))))",
+		strconv.FormatFloat(EarthRadiusKm, 'f', -1, 64),
+		latExpr, lat,
+		lat, latExpr, lngExpr, lng,
+	)
+}
@@ -0,0 +1,155 @@
+// Package imaging decodes, resizes and re-encodes uploaded images using only
+// the standard library. Re-encoding drops every metadata block the upload
+// carried, including EXIF GPS coordinates.
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+)
+
+// MaxPixels bounds the decoded size of an image so a small, highly
+// compressed file can't exhaust memory.
+const MaxPixels = 40_000_000
+
+// JPEGQuality is used for every JPEG the pipeline writes.
+const JPEGQuality = 85
+
+var (
+	ErrInvalidImage  = errors.New("image could not be read")
+	ErrTooManyPixels = errors.New("image dimensions are too large")
+)
+
+// Decode reads a JPEG or PNG into an RGBA image, turned upright according to
+// its EXIF orientation, and reports the format it was in.
+func Decode(data []byte) (*image.RGBA, string, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	if format != "jpeg" && format != "png" {
+		return nil, "", fmt.Errorf("%w: unsupported format %s", ErrInvalidImage, format)
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > MaxPixels {
+		return nil, "", fmt.Errorf("%w. Maximum is %d megapixels", ErrTooManyPixels, MaxPixels/1_000_000)
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+
+	bounds := src.Bounds()
+	img := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(img, img.Bounds(), src, bounds.Min, draw.Src)
+
+	if format == "jpeg" {
+		img = orient(img, jpegOrientation(data))
+	}
+	return img, format, nil
+}
+
+// Encode writes an image as JPEG or PNG and returns the content type.
+func Encode(img *image.RGBA, format string) ([]byte, string, error) {
+	var buf bytes.Buffer
+	switch format {
+	case "jpeg":
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: JPEGQuality}); err != nil {
+			return nil, "", err
+		}
+		return buf.Bytes(), "image/jpeg", nil
+	case "png":
+		encoder := png.Encoder{CompressionLevel: png.BestCompression}
+		if err := encoder.Encode(&buf, img); err != nil {
+			return nil, "", err
+		}
+		return buf.Bytes(), "image/png", nil
+	default:
+		return nil, "", fmt.Errorf("cannot encode format %s", format)
+	}
+}
+
+// Fit scales an image down to fit inside a square of the given side,
+// keeping its aspect ratio. Images that already fit are returned as they
+// are; nothing is ever scaled up.
+func Fit(img *image.RGBA, side int) *image.RGBA {
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	if width <= side && height <= side {
+		return img
+	}
+
+	if width >= height {
+		height = max(1, height*side/width)
+		width = side
+	} else {
+		width = max(1, width*side/height)
+		height = side
+	}
+	return resize(img, width, height)
+}
+
+// resize shrinks an image with a box filter: every output pixel is the
+// average of the source pixels it covers. Pixels are premultiplied, so
+// averaging them blends transparent edges correctly.
+func resize(src *image.RGBA, width, height int) *image.RGBA {
+	srcWidth, srcHeight := src.Bounds().Dx(), src.Bounds().Dy()
+
+	// Horizontal pass into a width x srcHeight buffer
+	tmp := make([]uint8, width*srcHeight*4)
+	for x := 0; x < width; x++ {
+		x0, x1 := span(x, width, srcWidth)
+		for y := 0; y < srcHeight; y++ {
+			var sum [4]int
+			row := src.Pix[y*src.Stride:]
+			for sx := x0; sx < x1; sx++ {
+				p := row[sx*4 : sx*4+4]
+				sum[0] += int(p[0])
+				sum[1] += int(p[1])
+				sum[2] += int(p[2])
+				sum[3] += int(p[3])
+			}
+			n := x1 - x0
+			o := (y*width + x) * 4
+			for c := 0; c < 4; c++ {
+				tmp[o+c] = uint8((sum[c] + n/2) / n)
+			}
+		}
+	}
+
+	// Vertical pass into the result
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0, y1 := span(y, height, srcHeight)
+		n := y1 - y0
+		for x := 0; x < width; x++ {
+			var sum [4]int
+			for sy := y0; sy < y1; sy++ {
+				o := (sy*width + x) * 4
+				sum[0] += int(tmp[o])
+				sum[1] += int(tmp[o+1])
+				sum[2] += int(tmp[o+2])
+				sum[3] += int(tmp[o+3])
+			}
+			o := y*dst.Stride + x*4
+			for c := 0; c < 4; c++ {
+				dst.Pix[o+c] = uint8((sum[c] + n/2) / n)
+			}
+		}
+	}
+	return dst
+}
+
+// span returns the source pixels [start, end) covered by output pixel i.
+func span(i, size, srcSize int) (int, int) {
+	start := i * srcSize / size
+	end := (i + 1) * srcSize / size
+	if end <= start {
+		end = start + 1
+	}
+	return start, end
+}
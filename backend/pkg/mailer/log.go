@@ -0,0 +1,20 @@
+package mailer
+
+import (
+	"context"
+	"log"
+	"net/mail"
+)
+
+// logSender stands in for SMTP in development and prints each message.
+type logSender struct {
+	from *mail.Address
+}
+
+func (s *logSender) Send(ctx context.Context, msg *Message) error {
+	if _, err := mail.ParseAddress(msg.To); err != nil {
+		return err
+	}
+	log.Printf("email (SMTP_HOST not set, not sent) to %s: %s\n%s", msg.To, msg.Subject, msg.Text)
+	return nil
+}
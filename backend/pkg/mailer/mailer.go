@@ -0,0 +1,97 @@
+// Package mailer sends email over SMTP. Without an SMTP host configured it
+// logs messages instead, so development needs no mail server; a local sink
+// such as MailHog or Mailpit works with SMTP_TLS=none.
+package mailer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/textproto"
+	"os"
+	"strings"
+)
+
+// Message is one email. Text is required; HTML is sent alongside it when set.
+type Message struct {
+	MessageID string // without angle brackets; generated when empty
+	To        string
+	Subject   string
+	Text      string
+	HTML      string
+}
+
+type Sender interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// Config configures the SMTP server to send through.
+type Config struct {
+	Host     string
+	Port     string
+	Username string // no authentication when empty
+	Password string
+	From     string // e.g. "Agro Konnect <no-reply@example.com>"
+	TLS      string // "starttls", "tls" (implicit, usually port 465) or "none"
+}
+
+const (
+	TLSStartTLS = "starttls"
+	TLSImplicit = "tls"
+	TLSNone     = "none"
+)
+
+// LoadConfig reads the SMTP settings from SMTP_* environment variables.
+func LoadConfig() Config {
+	config := Config{
+		Host:     os.Getenv("SMTP_HOST"),
+		Port:     os.Getenv("SMTP_PORT"),
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		From:     os.Getenv("SMTP_FROM"),
+		TLS:      strings.ToLower(os.Getenv("SMTP_TLS")),
+	}
+	if config.From == "" {
+		config.From = "Agro Konnect <no-reply@agrokonnect.local>"
+	}
+	if config.TLS == "" {
+		config.TLS = TLSStartTLS
+	}
+	if config.Port == "" {
+		switch config.TLS {
+		case TLSImplicit:
+			config.Port = "465"
+		case TLSNone:
+			config.Port = "25"
+		default:
+			config.Port = "587"
+		}
+	}
+	return config
+}
+
+// New returns an SMTP sender, or one that only logs when no host is set.
+func New(config Config) (Sender, error) {
+	from, err := mail.ParseAddress(config.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sender address %q: %w", config.From, err)
+	}
+	if config.Host == "" {
+		return &logSender{from: from}, nil
+	}
+
+	switch config.TLS {
+	case TLSStartTLS, TLSImplicit, TLSNone:
+	default:
+		return nil, fmt.Errorf("unknown SMTP TLS mode %q", config.TLS)
+	}
+	return &smtpSender{config: config, from: from}, nil
+}
+
+// IsPermanent reports whether the server rejected a message for good, e.g.
+// because the mailbox does not exist, so that retrying is pointless.
+func IsPermanent(err error) bool {
+	var smtpErr *textproto.Error
+	return errors.As(err, &smtpErr) && smtpErr.Code >= 500
+}
@@ -0,0 +1,199 @@
+package mailer
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"testing"
+)
+
+// smtpSink is a minimal in-process SMTP server that accepts every message,
+// or rejects recipients with rejectRcpt when it is set.
+type smtpSink struct {
+	listener   net.Listener
+	rejectRcpt string
+	received   chan sinkMessage
+}
+
+type sinkMessage struct {
+	from, to string
+	data     string
+}
+
+func newSMTPSink(t *testing.T) *smtpSink {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	sink := &smtpSink{listener: listener, received: make(chan sinkMessage, 10)}
+	t.Cleanup(func() { listener.Close() })
+	go sink.serve()
+	return sink
+}
+
+func (s *smtpSink) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.session(conn)
+	}
+}
+
+func (s *smtpSink) session(conn net.Conn) {
+	defer conn.Close()
+	tp := textproto.NewConn(conn)
+	tp.PrintfLine("220 sink ready")
+
+	var msg sinkMessage
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch verb {
+		case "EHLO", "HELO":
+			tp.PrintfLine("250 sink")
+		case "MAIL":
+			msg.from = line
+			tp.PrintfLine("250 OK")
+		case "RCPT":
+			if s.rejectRcpt != "" {
+				tp.PrintfLine("%s", s.rejectRcpt)
+				continue
+			}
+			msg.to = line
+			tp.PrintfLine("250 OK")
+		case "DATA":
+			tp.PrintfLine("354 go ahead")
+			data, err := io.ReadAll(tp.DotReader())
+			if err != nil {
+				return
+			}
+			msg.data = string(data)
+			s.received <- msg
+			tp.PrintfLine("250 queued")
+		case "QUIT":
+			tp.PrintfLine("221 bye")
+			return
+		default:
+			tp.PrintfLine("502 not implemented")
+		}
+	}
+}
+
+func (s *smtpSink) sender(t *testing.T) Sender {
+	t.Helper()
+	host, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	sender, err := New(Config{Host: host, Port: port, From: "Agro Konnect <no-reply@agrokonnect.test>", TLS: TLSNone})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return sender
+}
+
+func readMessage(t *testing.T, data string) *mail.Message {
+	t.Helper()
+	msg, err := mail.ReadMessage(bufio.NewReader(strings.NewReader(data)))
+	if err != nil {
+		t.Fatalf("read message: %v", err)
+	}
+	return msg
+}
+
+func TestSMTPSenderSendsTextMessage(t *testing.T) {
+	sink := newSMTPSink(t)
+	text := "Your code is 123456.\nIt expires in 10 minutes. Ça va?"
+
+	err := sink.sender(t).Send(context.Background(), &Message{
+		MessageID: "abc@agrokonnect.test",
+		To:        "Farmer <farmer@example.com>",
+		Subject:   "Verify your email",
+		Text:      text,
+	})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	got := <-sink.received
+	if got.from != "MAIL FROM:<no-reply@agrokonnect.test>" || got.to != "RCPT TO:<farmer@example.com>" {
+		t.Errorf("envelope = %q, %q", got.from, got.to)
+	}
+
+	msg := readMessage(t, got.data)
+	if id := msg.Header.Get("Message-Id"); id != "<abc@agrokonnect.test>" {
+		t.Errorf("Message-ID = %q", id)
+	}
+	if subject := msg.Header.Get("Subject"); subject != "Verify your email" {
+		t.Errorf("Subject = %q", subject)
+	}
+	body, err := io.ReadAll(quotedprintable.NewReader(msg.Body))
+	if err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	// The DATA reader hands back lines ending in \n, with a final one
+	if got := strings.TrimSuffix(strings.ReplaceAll(string(body), "\r\n", "\n"), "\n"); got != text {
+		t.Errorf("body = %q, want %q", got, text)
+	}
+}
+
+func TestSMTPSenderSendsAlternatives(t *testing.T) {
+	sink := newSMTPSink(t)
+
+	err := sink.sender(t).Send(context.Background(), &Message{
+		To:      "buyer@example.com",
+		Subject: "Order placed",
+		Text:    "Order AK-1 placed.",
+		HTML:    "<p>Order <b>AK-1</b> placed.</p>",
+	})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	msg := readMessage(t, (<-sink.received).data)
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("Content-Type = %q, %v", msg.Header.Get("Content-Type"), err)
+	}
+
+	var bodies []string
+	parts := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := parts.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("next part: %v", err)
+		}
+		// multipart decodes quoted-printable parts itself
+		body, err := io.ReadAll(part)
+		if err != nil {
+			t.Fatalf("read part: %v", err)
+		}
+		bodies = append(bodies, string(body))
+	}
+	if len(bodies) != 2 || bodies[0] != "Order AK-1 placed." || bodies[1] != "<p>Order <b>AK-1</b> placed.</p>" {
+		t.Errorf("parts = %q", bodies)
+	}
+}
+
+func TestSMTPSenderPermanentRejection(t *testing.T) {
+	sink := newSMTPSink(t)
+	sink.rejectRcpt = "550 no such user"
+
+	err := sink.sender(t).Send(context.Background(), &Message{To: "nobody@example.com", Subject: "Hi", Text: "Hi"})
+	if err == nil || !IsPermanent(err) {
+		t.Errorf("Send = %v, want a permanent error", err)
+	}
+}
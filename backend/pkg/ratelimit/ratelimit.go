@@ -0,0 +1,151 @@
+// Package ratelimit limits how often a client can call a group of routes.
+//
+// The only Limiter here keeps its counts in memory, so behind a load
+// balancer each instance allows the full limit on its own and a restart
+// starts counting afresh. That is enough to blunt floods of requests; limits
+// that must hold across instances, such as the failed sign-in throttle in
+// the auth service, are kept in the database instead.
+package ratelimit
+
+import (
+	"context"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Result is the outcome of asking to make a request.
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	RetryAfter time.Duration // until the next request is allowed, when it isn't
+}
+
+// Limiter counts requests per key, e.g. per client IP.
+type Limiter interface {
+	Allow(ctx context.Context, key string) (Result, error)
+}
+
+// KeyFunc picks what requests are counted against. An empty key is not
+// limited.
+type KeyFunc func(c *gin.Context) string
+
+// ByIP counts requests per client IP.
+func ByIP(c *gin.Context) string {
+	return c.ClientIP()
+}
+
+// Middleware refuses requests over the limiter's limit with 429 and a
+// Retry-After header. prefix keeps the counts of separately limited route
+// groups apart when they share a limiter.
+func Middleware(limiter Limiter, prefix string, key KeyFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		k := key(c)
+		if k == "" {
+			c.Next()
+			return
+		}
+
+		result, err := limiter.Allow(c.Request.Context(), prefix+":"+k)
+		if err != nil {
+			// Limiting protects the routes; it shouldn't take them down
+			log.Printf("rate limit check failed: %v", err)
+			c.Next()
+			return
+		}
+
+		c.Header("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		if !result.Allowed {
+			c.Header("Retry-After", strconv.Itoa(RetryAfterSeconds(result.RetryAfter)))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "Too many requests, please try again later"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// RetryAfterSeconds rounds a wait up to whole seconds for a Retry-After
+// header.
+func RetryAfterSeconds(d time.Duration) int {
+	if d <= 0 {
+		return 1
+	}
+	return int(math.Ceil(d.Seconds()))
+}
+
+// memoryLimiter allows limit requests per key in each fixed window. Counts
+// are kept in memory, so each server instance limits on its own.
+type memoryLimiter struct {
+	limit     int
+	window    time.Duration
+	now       func() time.Time
+	mu        sync.Mutex
+	windows   map[string]*window
+	lastSweep time.Time
+}
+
+type window struct {
+	start time.Time
+	count int
+}
+
+// NewMemoryLimiter allows limit requests per key every window, counted by
+// this server instance alone.
+func NewMemoryLimiter(limit int, per time.Duration) Limiter {
+	return newMemoryLimiter(limit, per, time.Now)
+}
+
+func newMemoryLimiter(limit int, per time.Duration, now func() time.Time) *memoryLimiter {
+	return &memoryLimiter{
+		limit:     limit,
+		window:    per,
+		now:       now,
+		windows:   make(map[string]*window),
+		lastSweep: now(),
+	}
+}
+
+func (l *memoryLimiter) Allow(ctx context.Context, key string) (Result, error) {
+	now := l.now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	w, ok := l.windows[key]
+	if !ok || now.Sub(w.start) >= l.window {
+		w = &window{start: now}
+		l.windows[key] = w
+	}
+
+	if w.count >= l.limit {
+		return Result{
+			Limit:      l.limit,
+			RetryAfter: w.start.Add(l.window).Sub(now),
+		}, nil
+	}
+	w.count++
+	return Result{Allowed: true, Limit: l.limit, Remaining: l.limit - w.count}, nil
+}
+
+// sweep drops finished windows now and then, so keys that stop making
+// requests don't stay in memory.
+func (l *memoryLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.window {
+		return
+	}
+	for key, w := range l.windows {
+		if now.Sub(w.start) >= l.window {
+			delete(l.windows, key)
+		}
+	}
+	l.lastSweep = now
+}
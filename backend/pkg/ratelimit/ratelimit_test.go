@@ -0,0 +1,118 @@
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func TestMemoryLimiter(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)}
+	limiter := newMemoryLimiter(3, time.Minute, clock.Now)
+
+	// Each step runs after the previous one, on the same limiter
+	steps := []struct {
+		name          string
+		advance       time.Duration
+		key           string
+		wantAllowed   bool
+		wantRemaining int
+		wantRetry     time.Duration
+	}{
+		{"first request", 0, "a", true, 2, 0},
+		{"second request", 10 * time.Second, "a", true, 1, 0},
+		{"last request in the window", 10 * time.Second, "a", true, 0, 0},
+		{"over the limit", 10 * time.Second, "a", false, 0, 30 * time.Second},
+		{"other keys count apart", 0, "b", true, 2, 0},
+		{"still over just before the window ends", 29 * time.Second, "a", false, 0, time.Second},
+		{"new window", time.Second, "a", true, 2, 0},
+	}
+	for _, step := range steps {
+		clock.now = clock.now.Add(step.advance)
+		result, err := limiter.Allow(context.Background(), step.key)
+		if err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		if result.Allowed != step.wantAllowed || result.Remaining != step.wantRemaining ||
+			result.RetryAfter != step.wantRetry || result.Limit != 3 {
+			t.Errorf("%s: got %+v, want allowed %v, remaining %d, retry after %s",
+				step.name, result, step.wantAllowed, step.wantRemaining, step.wantRetry)
+		}
+	}
+}
+
+func TestMemoryLimiterSweeps(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)}
+	limiter := newMemoryLimiter(3, time.Minute, clock.Now)
+	ctx := context.Background()
+
+	limiter.Allow(ctx, "gone")
+	clock.now = clock.now.Add(30 * time.Second)
+	limiter.Allow(ctx, "recent")
+	clock.now = clock.now.Add(30 * time.Second)
+	limiter.Allow(ctx, "new")
+
+	if _, ok := limiter.windows["gone"]; ok {
+		t.Error("finished window was kept")
+	}
+	if _, ok := limiter.windows["recent"]; !ok {
+		t.Error("running window was dropped")
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	clock := &fakeClock{now: time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)}
+	router := gin.New()
+	router.Use(Middleware(newMemoryLimiter(1, time.Minute, clock.Now), "test", ByIP))
+	router.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	request := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = "203.0.113.7:1234"
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := request(); w.Code != http.StatusOK || w.Header().Get("X-RateLimit-Remaining") != "0" {
+		t.Fatalf("first request = %d, remaining %q", w.Code, w.Header().Get("X-RateLimit-Remaining"))
+	}
+	clock.now = clock.now.Add(20500 * time.Millisecond)
+	w := request()
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("second request = %d, want 429", w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "40" {
+		t.Errorf("Retry-After = %q, want 40", got)
+	}
+}
+
+func TestRetryAfterSeconds(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want int
+	}{
+		{-time.Second, 1},
+		{0, 1},
+		{time.Millisecond, 1},
+		{time.Second, 1},
+		{1500 * time.Millisecond, 2},
+		{time.Minute, 60},
+	}
+	for _, tt := range tests {
+		if got := RetryAfterSeconds(tt.d); got != tt.want {
+			t.Errorf("RetryAfterSeconds(%s) = %d, want %d", tt.d, got, tt.want)
+		}
+	}
+}
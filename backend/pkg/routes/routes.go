@@ -1,23 +1,42 @@
 package routes
 
 import (
+	auditrepository "agro_konnect/internal/audit/repository"
+	auditroutes "agro_konnect/internal/audit/routes"
+	auditservice "agro_konnect/internal/audit/service"
 	"agro_konnect/internal/auth/middleware"
+	authrepository "agro_konnect/internal/auth/repository"
 	authroutes "agro_konnect/internal/auth/routes"
 	"agro_konnect/internal/auth/utils"
 	buyerroutes "agro_konnect/internal/buyer/routes"
+	emailrepository "agro_konnect/internal/email/repository"
+	emailroutes "agro_konnect/internal/email/routes"
+	emailservice "agro_konnect/internal/email/service"
 	farmerroutes "agro_konnect/internal/farmer/routes"
+	harvestroutes "agro_konnect/internal/harvest/routes"
+	mediarepository "agro_konnect/internal/media/repository"
+	mediaroutes "agro_konnect/internal/media/routes"
+	mediaservice "agro_konnect/internal/media/service"
 	orderroutes "agro_konnect/internal/order/routes"
+	orderservice "agro_konnect/internal/order/service"
 	productroutes "agro_konnect/internal/product/routes"
+	rfqroutes "agro_konnect/internal/rfq/routes"
+	schedulerroutes "agro_konnect/internal/scheduler/routes"
+	schedulerservice "agro_konnect/internal/scheduler/service"
 	transporterroutes "agro_konnect/internal/transporter/routes"
 	vendorroutes "agro_konnect/internal/vendors/routes"
+	"agro_konnect/pkg/mailer"
+	"agro_konnect/pkg/storage"
+	"log"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
-func RegisterRoutes(r *gin.Engine, db *gorm.DB) {
+func RegisterRoutes(r *gin.Engine, db *gorm.DB, jobScheduler schedulerservice.Scheduler, orderDeps *orderservice.Dependencies) {
 	// Initialize JWT manager for middleware
 	jwtSecret := os.Getenv("JWT_SECRET")
 	if jwtSecret == "" {
@@ -27,24 +46,81 @@ func RegisterRoutes(r *gin.Engine, db *gorm.DB) {
 	jwtManager := utils.NewJWTManager(jwtSecret, tokenDuration)
 
 	// Initialize auth middleware
-	authMiddleware := middleware.NewAuthMiddleware(jwtManager)
+	authMiddleware := middleware.NewAuthMiddleware(jwtManager, authrepository.NewSessionRepository(db), authrepository.NewImpersonationRepository(db))
+
+	// Uploads from every domain go through one media store
+	mediaConfig := storage.LoadConfig()
+	if mediaConfig.URLSecret == "" {
+		mediaConfig.URLSecret = jwtSecret
+	}
+	mediaStorage, err := storage.New(mediaConfig)
+	if err != nil {
+		log.Fatalf("❌ Failed to set up media storage: %v", err)
+	}
+	attachmentRepo := mediarepository.NewAttachmentRepository(db)
+	renditionRepo := mediarepository.NewRenditionRepository(db)
+
+	// Thumbnails and other image sizes are made off the request path
+	imageWorkers := 2
+	if v, err := strconv.Atoi(os.Getenv("MEDIA_IMAGE_WORKERS")); err == nil && v > 0 {
+		imageWorkers = v
+	}
+	imageWorkerPool := mediaservice.NewImageWorkerPool(attachmentRepo, renditionRepo, mediaStorage, imageWorkers)
+	imageWorkerPool.Start()
+
+	mediaService := mediaservice.NewMediaService(attachmentRepo, renditionRepo, mediaStorage, mediaConfig.URLExpiry, imageWorkerPool)
+
+	// Changes made by admins and other users are recorded from the services
+	auditService := auditservice.NewAuditService(auditrepository.NewAuditRepository(db))
+
+	// Emails are rendered into an outbox and delivered in the background
+	mailConfig := mailer.LoadConfig()
+	mailSender, err := mailer.New(mailConfig)
+	if err != nil {
+		log.Fatalf("❌ Failed to set up email: %v", err)
+	}
+	if mailConfig.Host == "" {
+		log.Println("⚠️ SMTP_HOST is not set; emails will be logged instead of sent")
+	}
+	emailRenderer, err := emailservice.NewRenderer()
+	if err != nil {
+		log.Fatalf("❌ Failed to load email templates: %v", err)
+	}
+	emailRepo := emailrepository.NewEmailRepository(db)
+	emailDispatcher := emailservice.NewDispatcher(emailRepo, mailSender, emailservice.LoadDispatcherConfig())
+	emailDispatcher.Start()
+	emailService := emailservice.NewEmailService(emailRepo, emailRenderer, emailDispatcher, mailConfig.From)
+
+	// Orders are placed from the order, RFQ and harvest routes alike
+	orderService := orderDeps.NewOrderService(emailService)
 
 	// Create API router group
 	api := r.Group("/api")
 
 	// Register routes
-	authroutes.UserRoutes(r, db)                            // This already sets up its own routes
-	farmerroutes.SetupFarmerRoutes(api, db, authMiddleware) // Fixed: added missing parameters
+	authroutes.UserRoutes(r, db, auditService, emailService)                                          // This already sets up its own routes
+	farmerroutes.SetupFarmerRoutes(api, db, authMiddleware, mediaService, auditService, emailService) // Fixed: added missing parameters
+
+	vendorroutes.SetupVendorRoutes(api, db, authMiddleware, mediaService, auditService, emailService) // Fixed: added missing parameters
 
-	vendorroutes.SetupVendorRoutes(api, db, authMiddleware) // Fixed: added missing parameters
+	buyerroutes.SetupBuyerRoutes(api, db, authMiddleware, auditService, emailService)
 
-	buyerroutes.SetupBuyerRoutes(api, db, authMiddleware)
+	transporterroutes.SetupTransporterRoutes(api, db, authMiddleware, auditService, emailService)
 
-	transporterroutes.SetupTransporterRoutes(api, db, authMiddleware)
+	orderroutes.SetupOrderRoutes(api, db, authMiddleware, orderService)
 
-	orderroutes.SetupOrderRoutes(api, db, authMiddleware)
+	rfqroutes.SetupRFQRoutes(api, db, authMiddleware, orderService)
+	harvestroutes.SetupHarvestRoutes(api, db, authMiddleware, orderService)
 
-	// Register product routes with upload directory
+	// Register product routes with the directory of images uploaded before media storage
 	productUploadDir := "./uploads/products"
-	productroutes.SetupProductRoutes(api, db, authMiddleware, productUploadDir)
+	productroutes.SetupProductRoutes(api, db, authMiddleware, mediaService, auditService, productUploadDir)
+
+	mediaroutes.SetupMediaRoutes(api, mediaService, authMiddleware)
+
+	schedulerroutes.SetupSchedulerRoutes(api, jobScheduler, authMiddleware)
+
+	auditroutes.SetupAuditRoutes(api, auditService, authMiddleware)
+
+	emailroutes.SetupEmailRoutes(api, emailService, authMiddleware)
 }
@@ -0,0 +1,109 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLocalStoragePutGetExistsDelete(t *testing.T) {
+	s, err := NewLocalStorage(t.TempDir(), "/api/media/files", "secret")
+	if err != nil {
+		t.Fatalf("NewLocalStorage: %v", err)
+	}
+	ctx := context.Background()
+	data := []byte("\x89PNG image bytes")
+	key := ContentKey(data, ".png")
+
+	if _, err := s.Get(ctx, key); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get before Put = %v, want ErrNotFound", err)
+	}
+	if err := s.Put(ctx, key, data, "image/png"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if exists, err := s.Exists(ctx, key); err != nil || !exists {
+		t.Fatalf("Exists after Put = %v, %v", exists, err)
+	}
+
+	object, err := s.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	got, _ := io.ReadAll(object.Body)
+	object.Body.Close()
+	if !bytes.Equal(got, data) || object.ContentType != "image/png" || object.Size != int64(len(data)) {
+		t.Errorf("Get = %q (%s, %d bytes)", got, object.ContentType, object.Size)
+	}
+
+	if err := s.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if exists, err := s.Exists(ctx, key); err != nil || exists {
+		t.Fatalf("Exists after Delete = %v, %v", exists, err)
+	}
+	if err := s.Delete(ctx, key); err != nil {
+		t.Errorf("Delete of a missing object = %v", err)
+	}
+}
+
+func TestLocalStorageSignedURL(t *testing.T) {
+	s, err := NewLocalStorage(t.TempDir(), "/api/media/files", "secret")
+	if err != nil {
+		t.Fatalf("NewLocalStorage: %v", err)
+	}
+	ctx := context.Background()
+	key := ContentKey([]byte("report"), ".pdf")
+
+	signed, err := s.SignedURL(ctx, key, time.Minute)
+	if err != nil {
+		t.Fatalf("SignedURL: %v", err)
+	}
+	u, err := url.Parse(signed)
+	if err != nil || u.Path != "/api/media/files/"+key {
+		t.Fatalf("SignedURL = %q, %v", signed, err)
+	}
+	if !s.VerifySignedURL(key, u.Query()) {
+		t.Error("VerifySignedURL refused its own URL")
+	}
+
+	// The signature covers both the key and the expiry
+	if s.VerifySignedURL(ContentKey([]byte("other"), ".pdf"), u.Query()) {
+		t.Error("VerifySignedURL accepted the signature for another key")
+	}
+	query := u.Query()
+	query.Set("expires", query.Get("expires")+"0")
+	if s.VerifySignedURL(key, query) {
+		t.Error("VerifySignedURL accepted an extended expiry")
+	}
+
+	other, _ := NewLocalStorage(t.TempDir(), "/api/media/files", "other secret")
+	if other.VerifySignedURL(key, u.Query()) {
+		t.Error("VerifySignedURL accepted a URL signed with another secret")
+	}
+
+	expired, _ := s.SignedURL(ctx, key, -time.Second)
+	u, _ = url.Parse(expired)
+	if s.VerifySignedURL(key, u.Query()) {
+		t.Error("VerifySignedURL accepted an expired URL")
+	}
+}
+
+func TestLocalStorageWithoutSecret(t *testing.T) {
+	s, err := NewLocalStorage(t.TempDir(), "/api/media/files", "")
+	if err != nil {
+		t.Fatalf("NewLocalStorage: %v", err)
+	}
+	key := ContentKey([]byte("x"), ".txt")
+
+	if _, err := s.SignedURL(context.Background(), key, time.Minute); err == nil {
+		t.Error("SignedURL without a secret succeeded")
+	}
+	if s.VerifySignedURL(key, url.Values{"expires": {"9999999999"}, "signature": {strings.Repeat("0", 64)}}) {
+		t.Error("VerifySignedURL without a secret accepted a URL")
+	}
+}
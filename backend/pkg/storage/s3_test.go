@@ -0,0 +1,277 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	testAccessKey = "AKIDEXAMPLE"
+	testSecretKey = "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"
+	testRegion    = "eu-west-1"
+	testBucket    = "media"
+)
+
+// fakeS3 stands in for MinIO: it keeps objects in memory under path-style
+// URLs and, like S3, refuses requests whose SigV4 signature, payload hash or
+// presigned expiry does not check out.
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string]fakeObject
+	puts    int
+}
+
+type fakeObject struct {
+	data        []byte
+	contentType string
+}
+
+func newFakeS3(t *testing.T) (*fakeS3, *S3Storage) {
+	t.Helper()
+	fake := &fakeS3{objects: make(map[string]fakeObject)}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	s, err := NewS3Storage(server.URL, testRegion, testBucket, testAccessKey, testSecretKey)
+	if err != nil {
+		t.Fatalf("NewS3Storage: %v", err)
+	}
+	return fake, s
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	if err := verifySigV4(r, body); err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	prefix := "/" + testBucket + "/"
+	if !strings.HasPrefix(r.URL.Path, prefix) {
+		http.Error(w, "NoSuchBucket", http.StatusNotFound)
+		return
+	}
+	key := strings.TrimPrefix(r.URL.Path, prefix)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	object, ok := f.objects[key]
+
+	switch r.Method {
+	case http.MethodPut:
+		f.objects[key] = fakeObject{data: body, contentType: r.Header.Get("Content-Type")}
+		f.puts++
+	case http.MethodGet, http.MethodHead:
+		if !ok {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", object.contentType)
+		w.Header().Set("Content-Length", strconv.Itoa(len(object.data)))
+		w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+		if r.Method == http.MethodGet {
+			w.Write(object.data)
+		}
+	case http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// verifySigV4 checks a request the way S3 does, from what arrived on the
+// wire: either an Authorization header or presigned query parameters.
+func verifySigV4(r *http.Request, body []byte) error {
+	query := r.URL.Query()
+	var credential, signedHeaders, signature, date, payloadHash string
+
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		fields := map[string]string{}
+		for _, field := range strings.Split(strings.TrimPrefix(auth, "AWS4-HMAC-SHA256 "), ", ") {
+			if name, value, ok := strings.Cut(field, "="); ok {
+				fields[name] = value
+			}
+		}
+		credential, signedHeaders, signature = fields["Credential"], fields["SignedHeaders"], fields["Signature"]
+		date = r.Header.Get("X-Amz-Date")
+		payloadHash = r.Header.Get("X-Amz-Content-Sha256")
+		sum := sha256.Sum256(body)
+		if payloadHash != hex.EncodeToString(sum[:]) {
+			return errors.New("XAmzContentSHA256Mismatch")
+		}
+	} else {
+		credential, signedHeaders, signature = query.Get("X-Amz-Credential"), query.Get("X-Amz-SignedHeaders"), query.Get("X-Amz-Signature")
+		date = query.Get("X-Amz-Date")
+		payloadHash = s3UnsignedPayload
+		signedAt, err := time.Parse(s3DateFormat, date)
+		expires, _ := strconv.Atoi(query.Get("X-Amz-Expires"))
+		if err != nil || time.Now().After(signedAt.Add(time.Duration(expires)*time.Second)) {
+			return errors.New("AccessDenied: Request has expired")
+		}
+		query.Del("X-Amz-Signature")
+	}
+
+	parts := strings.SplitN(credential, "/", 2)
+	if len(parts) != 2 || parts[0] != testAccessKey || signature == "" {
+		return errors.New("InvalidAccessKeyId")
+	}
+
+	var headers strings.Builder
+	for _, name := range strings.Split(signedHeaders, ";") {
+		value := r.Header.Get(name)
+		if name == "host" {
+			value = r.Host
+		}
+		headers.WriteString(name + ":" + strings.TrimSpace(value) + "\n")
+	}
+
+	var pairs []string
+	for name, values := range query {
+		for _, value := range values {
+			pairs = append(pairs, awsEscape(name)+"="+awsEscape(value))
+		}
+	}
+	sort.Strings(pairs)
+
+	canonical := strings.Join([]string{r.Method, r.URL.EscapedPath(), strings.Join(pairs, "&"), headers.String(), signedHeaders, payloadHash}, "\n")
+	hash := sha256.Sum256([]byte(canonical))
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", date, parts[1], hex.EncodeToString(hash[:])}, "\n")
+
+	key := []byte("AWS4" + testSecretKey)
+	for _, part := range strings.Split(parts[1], "/") {
+		key = hmacSHA256(key, part)
+	}
+	if hex.EncodeToString(hmacSHA256(key, stringToSign)) != signature {
+		return errors.New("SignatureDoesNotMatch")
+	}
+	return nil
+}
+
+func TestS3StoragePutGetExistsDelete(t *testing.T) {
+	fake, s := newFakeS3(t)
+	ctx := context.Background()
+	data := []byte("\xff\xd8\xffimage bytes")
+	key := ContentKey(data, ".jpg")
+
+	if exists, err := s.Exists(ctx, key); err != nil || exists {
+		t.Fatalf("Exists before Put = %v, %v", exists, err)
+	}
+	if _, err := s.Get(ctx, key); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get before Put = %v, want ErrNotFound", err)
+	}
+
+	if err := s.Put(ctx, key, data, "image/jpeg"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	// Content-addressed keys are written once
+	if err := s.Put(ctx, key, data, "image/jpeg"); err != nil {
+		t.Fatalf("second Put: %v", err)
+	}
+	fake.mu.Lock()
+	puts := fake.puts
+	fake.mu.Unlock()
+	if puts != 1 {
+		t.Errorf("object uploaded %d times, want 1", puts)
+	}
+
+	if exists, err := s.Exists(ctx, key); err != nil || !exists {
+		t.Fatalf("Exists after Put = %v, %v", exists, err)
+	}
+
+	object, err := s.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	got, _ := io.ReadAll(object.Body)
+	object.Body.Close()
+	if !bytes.Equal(got, data) || object.ContentType != "image/jpeg" || object.Size != int64(len(data)) {
+		t.Errorf("Get = %q (%s, %d bytes)", got, object.ContentType, object.Size)
+	}
+
+	if err := s.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if exists, err := s.Exists(ctx, key); err != nil || exists {
+		t.Fatalf("Exists after Delete = %v, %v", exists, err)
+	}
+	if err := s.Delete(ctx, key); err != nil {
+		t.Errorf("Delete of a missing object = %v", err)
+	}
+}
+
+func TestS3StorageRejectedCredentials(t *testing.T) {
+	_, s := newFakeS3(t)
+	s.secretKey = "wrong"
+
+	if err := s.Put(context.Background(), "ab/cd/object.txt", []byte("x"), "text/plain"); err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("Put with a bad secret = %v, want a 403", err)
+	}
+}
+
+func TestS3StorageSignedURL(t *testing.T) {
+	_, s := newFakeS3(t)
+	ctx := context.Background()
+	data := []byte("report")
+	key := ContentKey(data, ".pdf")
+	if err := s.Put(ctx, key, data, "application/pdf"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	signed, err := s.SignedURL(ctx, key, time.Minute)
+	if err != nil {
+		t.Fatalf("SignedURL: %v", err)
+	}
+	resp, err := http.Get(signed)
+	if err != nil {
+		t.Fatalf("GET signed URL: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !bytes.Equal(body, data) {
+		t.Fatalf("signed URL = %d %q", resp.StatusCode, body)
+	}
+
+	// The signature covers the key
+	u, _ := url.Parse(signed)
+	u.Path = strings.Replace(u.Path, key, ContentKey([]byte("other"), ".pdf"), 1)
+	u.RawPath = ""
+	if status := getStatus(t, u.String()); status != http.StatusForbidden {
+		t.Errorf("tampered URL status = %d, want 403", status)
+	}
+
+	// Expired URLs are refused
+	expired, _ := s.SignedURL(ctx, key, -time.Second)
+	if status := getStatus(t, expired); status != http.StatusForbidden {
+		t.Errorf("expired URL status = %d, want 403", status)
+	}
+
+	// S3 caps presigned URLs at a week
+	long, _ := s.SignedURL(ctx, key, 30*24*time.Hour)
+	if u, _ := url.Parse(long); u.Query().Get("X-Amz-Expires") != "604800" {
+		t.Errorf("X-Amz-Expires = %s, want 604800", u.Query().Get("X-Amz-Expires"))
+	}
+}
+
+func getStatus(t *testing.T, url string) int {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("GET %s: %v", url, err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
@@ -0,0 +1,95 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// sheetWith builds a workbook whose only part is a worksheet with the given
+// sheetData contents.
+func sheetWith(t *testing.T, sheetData string) *bytes.Reader {
+	t.Helper()
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	f, err := archive.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write([]byte(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` + sheetData + `</sheetData></worksheet>`))
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return bytes.NewReader(buf.Bytes())
+}
+
+func TestWriteReadRowsRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	err := Write(&buf, "Products & prices", [][]interface{}{
+		{"sku", "name", "price", "stock", "organic"},
+		{"TOM-1", "Tomatoes <Roma>", 120.5, 40, true},
+		{"KAL-2", nil, 60.0, 0, false},
+	})
+	if err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	rows, err := ReadRows(bytes.NewReader(buf.Bytes()), int64(buf.Len()), MaxRows)
+	if err != nil {
+		t.Fatalf("ReadRows: %v", err)
+	}
+	want := [][]string{
+		{"sku", "name", "price", "stock", "organic"},
+		{"TOM-1", "Tomatoes <Roma>", "120.5", "40", "1"},
+		{"KAL-2", "", "60", "0", "0"},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("rows = %q, want %q", rows, want)
+	}
+}
+
+func TestReadRowsGaps(t *testing.T) {
+	r := sheetWith(t, `<row r="2"><c r="C2" t="inlineStr"><is><t>x</t></is></c></row>`)
+	rows, err := ReadRows(r, r.Size(), MaxRows)
+	if err != nil {
+		t.Fatalf("ReadRows: %v", err)
+	}
+	want := [][]string{nil, {"", "", "x"}}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("rows = %q, want %q", rows, want)
+	}
+}
+
+func TestReadRowsMalformed(t *testing.T) {
+	tests := []struct {
+		name      string
+		sheetData string
+		maxRows   int
+		want      error
+	}{
+		{"overflowing reference", `<row r="1"><c r="ZZZZZZZZZZZZZZ1"><v>1</v></c></row>`, MaxRows, ErrInvalidWorkbook},
+		{"column past the last", `<row r="1"><c r="XFE1"><v>1</v></c></row>`, MaxRows, ErrInvalidWorkbook},
+		{"reference without letters", `<row r="1"><c r="12"><v>1</v></c></row>`, MaxRows, ErrInvalidWorkbook},
+		{"row past the last", `<row r="1048577"><c r="A1048577"><v>1</v></c></row>`, MaxRows, ErrInvalidWorkbook},
+		{"row past the caller's limit", `<row r="1000"><c r="A1000"><v>1</v></c></row>`, 100, ErrTooManyRows},
+		{"bad shared string", `<row r="1"><c r="A1" t="s"><v>3</v></c></row>`, MaxRows, ErrInvalidWorkbook},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := sheetWith(t, tt.sheetData)
+			if _, err := ReadRows(r, r.Size(), tt.maxRows); !errors.Is(err, tt.want) {
+				t.Errorf("ReadRows = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestColumnIndex(t *testing.T) {
+	for ref, want := range map[string]int{"A1": 0, "Z9": 25, "AA3": 26, "XFD1048576": MaxColumns - 1} {
+		if got, err := columnIndex(ref); err != nil || got != want {
+			t.Errorf("columnIndex(%q) = %d, %v, want %d", ref, got, err, want)
+		}
+	}
+}